@@ -30,11 +30,33 @@ type DomainManagerManagerConfig struct {
 }
 
 type DomainConfig struct {
-	Init            DomainInitConfig `json:"init"`
-	Plugin          PluginConfig     `json:"plugin"`
-	Config          map[string]any   `json:"config"`
-	RegistryAddress string           `json:"registryAddress"`
-	AllowSigning    bool             `json:"allowSigning"`
+	Init                  DomainInitConfig      `json:"init"`
+	Plugin                PluginConfig          `json:"plugin"`
+	Config                map[string]any        `json:"config"`
+	RegistryAddress       string                `json:"registryAddress"`
+	AllowSigning          bool                  `json:"allowSigning"`
+	AllowedCallFunctions  []string              `json:"allowedCallFunctions,omitempty"`  // if set, only these functions (by name or signature) can be invoked via ptx_call
+	SequentialEndorsement bool                  `json:"sequentialEndorsement,omitempty"` // if set, endorsement requests are sent one at a time, passing prior endorsements to each subsequent endorser
+	AttestationPlan       AttestationPlanLimits `json:"attestationPlan"`
+	StateDistribution     string                `json:"stateDistribution,omitempty"` // when output states are sent to their recipients, relative to the public transaction (default beforeDispatch)
+}
+
+type StateDistributionOrder string
+
+const (
+	StateDistributionBeforeDispatch StateDistributionOrder = "beforeDispatch" // states are sent as the public transaction is handed over for submission, so can arrive before it confirms
+	StateDistributionAfterConfirm   StateDistributionOrder = "afterConfirm"   // states are held back until the public transaction has been confirmed on the base ledger
+)
+
+// Protects the node from excessive fan-out of signing and endorsement requests from a malicious or buggy domain
+type AttestationPlanLimits struct {
+	MaxRequests *int `json:"maxRequests"` // maximum number of attestation requests in the plan of a single assembled transaction
+	MaxParties  *int `json:"maxParties"`  // maximum number of parties across all the attestation requests of a single assembled transaction
+}
+
+var AttestationPlanLimitsDefaults = &AttestationPlanLimits{
+	MaxRequests: confutil.P(100),
+	MaxParties:  confutil.P(1000),
 }
 
 var ContractCacheDefaults = &CacheConfig{
@@ -20,11 +20,27 @@ import (
 )
 
 type EthClientConfig struct {
-	WS                WSClientConfig   `json:"ws"`
-	HTTP              HTTPClientConfig `json:"http"`
-	EstimateGasFactor *float64         `json:"gasEstimateFactor"`
+	WS                WSClientConfig    `json:"ws"`
+	HTTP              HTTPClientConfig  `json:"http"`
+	EstimateGasFactor *float64          `json:"gasEstimateFactor"`
+	Timeouts          EthClientTimeouts `json:"timeouts"`
+}
+
+// Timeouts applied to individual JSON/RPC operations, in addition to any request timeout on the HTTP/WS connection.
+// Set any of them to 0 to disable the timeout for that operation.
+type EthClientTimeouts struct {
+	EstimateGas         *string `json:"estimateGas"`
+	Call                *string `json:"call"`
+	GetTransactionCount *string `json:"getTransactionCount"`
+	SendRawTransaction  *string `json:"sendRawTransaction"`
 }
 
 var EthClientDefaults = &EthClientConfig{
 	EstimateGasFactor: confutil.P(2.0),
+	Timeouts: EthClientTimeouts{
+		EstimateGas:         confutil.P("10s"),
+		Call:                confutil.P("30s"),
+		GetTransactionCount: confutil.P("10s"),
+		SendRawTransaction:  confutil.P("30s"),
+	},
 }
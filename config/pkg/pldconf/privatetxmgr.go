@@ -22,8 +22,27 @@ type PrivateTxManagerConfig struct {
 	StateDistributer               DistributerConfig               `json:"stateDistributer"`
 	PreparedTransactionDistributer DistributerConfig               `json:"preparedTransactionDistributer"`
 	RequestTimeout                 *string                         `json:"requestTimeout"`
+	RequireEndorsementKeyMatch     *bool                           `json:"requireEndorsementKeyMatch,omitempty"` // reject endorsement requests the endorser's key cannot sign, before invoking the domain
+	MaxCallDepth                   *int                            `json:"maxCallDepth"`                         // limit on nested private contract calls within a single call
+	DomainResolutionGracePeriod    *string                         `json:"domainResolutionGracePeriod"`          // how long a new transaction waits for the domain of its contract to become available (such as during startup) before failing
+	SubscriberBuffer               SubscriberBufferConfig          `json:"subscriberBuffer"`
 }
 
+// Events are buffered in memory for each subscriber, and delivered to it on a separate routine,
+// so the overflow policy determines what happens when a slow subscriber falls behind
+type SubscriberBufferConfig struct {
+	Size           *int    `json:"size"`
+	OverflowPolicy *string `json:"overflowPolicy"`
+}
+
+type SubscriberOverflowPolicy string
+
+const (
+	SubscriberOverflowPolicyBlock      SubscriberOverflowPolicy = "block"      // the publisher waits for space in the buffer
+	SubscriberOverflowPolicyDropOldest SubscriberOverflowPolicy = "dropOldest" // the oldest undelivered event is discarded to make space
+	SubscriberOverflowPolicyDisconnect SubscriberOverflowPolicy = "disconnect" // the subscriber is removed, and receives no further events
+)
+
 type DistributerConfig struct {
 	AcknowledgementWriter FlushWriterConfig `json:"acknowledgementWriter"`
 	ReceivedObjectWriter  FlushWriterConfig `json:"receivedStateWriter"`
@@ -41,15 +60,94 @@ var PrivateTxManagerDefaults = &PrivateTxManagerConfig{
 		EvaluationInterval:      confutil.P("5m"),
 		PersistenceRetryTimeout: confutil.P("5s"),
 		StaleTimeout:            confutil.P("10m"),
+		SigningTimeout:          confutil.P("30s"),
 		MaxPendingEvents:        confutil.P(500),
+		DispatchConcurrency:     confutil.P(10),
+		ContentionRetry: RetryConfigWithMax{
+			RetryConfig: RetryConfig{
+				InitialDelay: confutil.P("250ms"),
+				MaxDelay:     confutil.P("10s"),
+				Factor:       confutil.P(2.0),
+			},
+			MaxAttempts: confutil.P(5),
+		},
+		EndorsementRevertPolicy: confutil.P(string(EndorsementRevertPolicyReassemble)),
+		EndorsementMaxRetries:   confutil.P(5),
+		HandoffTimeout:          confutil.P("10s"),
+		HandoffMaxRetries:       confutil.P(3),
+		DependencyExpiry:        confutil.P("0"),
+		FlushFailurePolicy:      confutil.P(string(FlushFailurePolicyFail)),
+		SubmitSigners: SubmitSignerPoolConfig{
+			PoolSize:  confutil.P(1),
+			Selection: confutil.P(string(SubmitSignerSelectionRoundRobin)),
+		},
+	},
+	RequestTimeout:              confutil.P("15s"),
+	MaxCallDepth:                confutil.P(10),
+	DomainResolutionGracePeriod: confutil.P("5s"),
+	SubscriberBuffer: SubscriberBufferConfig{
+		Size:           confutil.P(1000),
+		OverflowPolicy: confutil.P(string(SubscriberOverflowPolicyBlock)),
 	},
-	RequestTimeout: confutil.P("15s"),
 }
 
 type PrivateTxManagerSequencerConfig struct {
-	MaxConcurrentProcess    *int    `json:"maxConcurrentProcess,omitempty"`
-	MaxPendingEvents        *int    `json:"maxPendingEvents,omitempty"`
-	EvaluationInterval      *string `json:"evalInterval,omitempty"`
-	PersistenceRetryTimeout *string `json:"persistenceRetryTimeout,omitempty"`
-	StaleTimeout            *string `json:"staleTimeout,omitempty"`
+	MaxConcurrentProcess      *int                         `json:"maxConcurrentProcess,omitempty"`
+	MaxPendingEvents          *int                         `json:"maxPendingEvents,omitempty"`
+	EvaluationInterval        *string                      `json:"evalInterval,omitempty"`
+	PersistenceRetryTimeout   *string                      `json:"persistenceRetryTimeout,omitempty"`
+	StaleTimeout              *string                      `json:"staleTimeout,omitempty"`
+	SigningTimeout            *string                      `json:"signingTimeout,omitempty"`
+	DispatchConcurrency       *int                         `json:"dispatchConcurrency,omitempty"`  // number of signing addresses whose transactions are prepared for dispatch in parallel
+	SigningHashThreshold      *int                         `json:"signingHashThreshold,omitempty"` // payloads larger than this number of bytes are hashed before signing, where the payload type allows (disabled if unset)
+	ContentionRetry           RetryConfigWithMax           `json:"contentionRetry"`
+	HandoffNodes              []string                     `json:"handoffNodes,omitempty"`      // peers that coordination of new transactions is handed off to when maxConcurrentProcess is reached (disabled if empty)
+	HandoffTimeout            *string                      `json:"handoffTimeout,omitempty"`    // how long to wait for a peer to acknowledge a handoff before re-sending it
+	HandoffMaxRetries         *int                         `json:"handoffMaxRetries,omitempty"` // times an unacknowledged handoff is re-sent before the transaction is coordinated locally
+	RetryBudget               TransactionRetryBudgetConfig `json:"retryBudget"`
+	EndorsementRevertPolicy   *string                      `json:"endorsementRevertPolicy,omitempty"`   // what happens to a transaction when an endorser rejects it with a revert reason
+	EndorsementRequestTimeout *string                      `json:"endorsementRequestTimeout,omitempty"` // how long to wait for a response to an endorsement request before re-sending it (defaults to requestTimeout)
+	EndorsementMaxRetries     *int                         `json:"endorsementMaxRetries,omitempty"`     // times an unanswered endorsement request is re-sent before the transaction is reverted (unlimited if unset or zero)
+	FlushFailurePolicy        *string                      `json:"flushFailurePolicy,omitempty"`        // what happens to the transactions of a dispatch batch when persisting it (including the flush of the domain context) fails
+	DependencyExpiry          *string                      `json:"dependencyExpiry,omitempty"`          // how long a dependency restored after a restart holds back its dependants, if the transaction it is on does not come back into the sequencer (disabled by default, or if 0)
+	SubmitSigners             SubmitSignerPoolConfig       `json:"submitSigners"`
+}
+
+type EndorsementRevertPolicy string
+
+const (
+	EndorsementRevertPolicyFail       EndorsementRevertPolicy = "fail"       // the transaction is failed with the revert reason of the endorser
+	EndorsementRevertPolicyReassemble EndorsementRevertPolicy = "reassemble" // the transaction is re-assembled, in case the endorser rejected it due to a change of state since it was assembled
+)
+
+// A failed dispatch persists none of the states flushed from the domain context with it, and the states of the
+// transactions in the batch (and of any that spend them) are discarded from the domain context, so those
+// transactions can no longer be dispatched as they were assembled
+type FlushFailurePolicy string
+
+const (
+	FlushFailurePolicyFail       FlushFailurePolicy = "fail"       // the transactions are failed with the error that caused the dispatch to fail
+	FlushFailurePolicyReassemble FlushFailurePolicy = "reassemble" // the transactions are re-assembled, consuming their retry budget
+)
+
+// Transactions that the domain does not require to be submitted by a particular identity are submitted using
+// anonymous signing addresses allocated by the coordinator. Each contract has its own pool of them, so that a
+// busy contract is not bottlenecked on the nonces of a single address.
+type SubmitSignerPoolConfig struct {
+	PoolSize  *int    `json:"poolSize,omitempty"`  // number of signing addresses that dispatches for each contract are spread across
+	Selection *string `json:"selection,omitempty"` // how the signing address is chosen from the pool for each transaction
+}
+
+type SubmitSignerSelection string
+
+const (
+	SubmitSignerSelectionRoundRobin  SubmitSignerSelection = "roundRobin"  // each signing address in the pool is used in turn
+	SubmitSignerSelectionLeastLoaded SubmitSignerSelection = "leastLoaded" // the signing address with the fewest dispatched transactions awaiting confirmation is used
+)
+
+// Bounds the cumulative retry effort across all stages (resolve, assemble, sign, endorse, dispatch) of a
+// single transaction, so that it is failed rather than retrying indefinitely as it moves between stages
+type TransactionRetryBudgetConfig struct {
+	MaxRetries  *int    `json:"maxRetries,omitempty"`  // total retries across all stages before the transaction is failed (unlimited if zero)
+	MaxDuration *string `json:"maxDuration,omitempty"` // time since the transaction was first sequenced after which it is failed if it is still retrying (disabled if unset)
 }
@@ -34,6 +34,12 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 		OrchestratorStaleTimeout: confutil.P("5m"),
 		OrchestratorSwapTimeout:  confutil.P("10m"),
 		NonceCacheTimeout:        confutil.P("1h"),
+		ConfirmationConcurrency:  confutil.P(10),
+		PersistRejected:          confutil.P(false),
+		RejectedRetention: PublicTxManagerRejectedRetentionConfig{
+			MaxRetention:  confutil.P("168h"),
+			CheckInterval: confutil.P("1m"),
+		},
 		Retry: RetryConfig{
 			InitialDelay: confutil.P("250ms"),
 			MaxDelay:     confutil.P("30s"),
@@ -44,6 +50,19 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 			BatchTimeout: confutil.P("75ms"),
 			BatchMaxSize: confutil.P(50),
 		},
+		SubmissionFailurePause: PublicTxManagerFailurePauseConfig{
+			ConsecutiveFailures: confutil.P(0),
+			Backoff: RetryConfig{
+				InitialDelay: confutil.P("30s"),
+				MaxDelay:     confutil.P("10m"),
+				Factor:       confutil.P(2.0),
+			},
+		},
+		OutOfGasRetry: PublicTxManagerOutOfGasRetryConfig{
+			Enabled:            confutil.P(false),
+			IncreasePercentage: confutil.P(50),
+			MaxAttempts:        confutil.P(3),
+		},
 		ActivityRecords: PublicTxManagerActivityRecordsConfig{
 			CacheConfig: CacheConfig{
 				// Status cache can be is shared across orchestrators, allowing status to live beyond TX completion
@@ -54,12 +73,13 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 		},
 	},
 	Orchestrator: PublicTxManagerOrchestratorConfig{
-		MaxInFlight:          confutil.P(500),
-		Interval:             confutil.P("5s"),
-		ResubmitInterval:     confutil.P("5m"),
-		StaleTimeout:         confutil.P("5m"),
-		StageRetryTime:       confutil.P("10s"),
-		PersistenceRetryTime: confutil.P("5s"),
+		MaxInFlight:                confutil.P(500),
+		MaxInFlightPerOrchestrator: confutil.P(100),
+		Interval:                   confutil.P("5s"),
+		ResubmitInterval:           confutil.P("5m"),
+		StaleTimeout:               confutil.P("5m"),
+		StageRetryTime:             confutil.P("10s"),
+		PersistenceRetryTime:       confutil.P("5s"),
 		SubmissionRetry: RetryConfigWithMax{
 			RetryConfig: RetryConfig{
 				InitialDelay: confutil.P("250ms"),
@@ -68,11 +88,16 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 			},
 			MaxAttempts: confutil.P(3),
 		},
+		GapFill: PublicTxManagerGapFillConfig{
+			Enabled: confutil.P(false),
+		},
 	},
 	GasPrice: GasPriceConfig{
-		IncreaseMax:        nil,
-		IncreasePercentage: confutil.P(0),
-		FixedGasPrice:      nil,
+		IncreaseMax:           nil,
+		IncreasePercentage:    confutil.P(0),
+		MinIncreasePercentage: confutil.P(10),
+		FixedGasPrice:         nil,
+		FallbackGasPrice:      nil,
 		Cache: CacheConfig{
 			Capacity: confutil.P(100),
 			// TODO: Enable a KB based cache with TTL in Paladin
@@ -83,6 +108,8 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 		},
 	},
 	BalanceManager: BalanceManagerConfig{
+		MinGasReserve:   nil,
+		GasReservePause: confutil.P("1m"),
 		Cache: CacheConfig{
 			Capacity: confutil.P(100),
 			// TODO: Enable a KB based cache with TTL in Paladin
@@ -103,15 +130,36 @@ var PublicTxManagerDefaults = &PublicTxManagerConfig{
 }
 
 type PublicTxManagerManagerConfig struct {
-	MaxInFlightOrchestrators *int                                 `json:"maxInFlightOrchestrators"`
-	Interval                 *string                              `json:"interval"`
-	OrchestratorIdleTimeout  *string                              `json:"orchestratorIdleTimeout"`  // idle orchestrators exit after this time
-	OrchestratorStaleTimeout *string                              `json:"orchestratorStaleTimeout"` // stale orchestrators exit after this time - TODO: Define stale
-	OrchestratorSwapTimeout  *string                              `json:"orchestratorSwapTimeout"`  // orchestrators are cycled out after this time, when all slots are full
-	NonceCacheTimeout        *string                              `json:"nonceCacheTimeout"`
-	ActivityRecords          PublicTxManagerActivityRecordsConfig `json:"activityRecords"`
-	SubmissionWriter         FlushWriterConfig                    `json:"submissionWriter"`
-	Retry                    RetryConfig                          `json:"retry"`
+	MaxInFlightOrchestrators *int                                   `json:"maxInFlightOrchestrators"`
+	Interval                 *string                                `json:"interval"`
+	OrchestratorIdleTimeout  *string                                `json:"orchestratorIdleTimeout"`  // idle orchestrators exit after this time
+	OrchestratorStaleTimeout *string                                `json:"orchestratorStaleTimeout"` // stale orchestrators exit after this time - TODO: Define stale
+	OrchestratorSwapTimeout  *string                                `json:"orchestratorSwapTimeout"`  // orchestrators are cycled out after this time, when all slots are full
+	NonceCacheTimeout        *string                                `json:"nonceCacheTimeout"`
+	ConfirmationConcurrency  *int                                   `json:"confirmationConcurrency"` // max signing addresses processed in parallel when notifying confirmations
+	PersistRejected          *bool                                  `json:"persistRejected"`         // store a record of transactions rejected by gas estimation, for audit and debugging
+	RejectedRetention        PublicTxManagerRejectedRetentionConfig `json:"rejectedRetention"`
+	ActivityRecords          PublicTxManagerActivityRecordsConfig   `json:"activityRecords"`
+	SubmissionWriter         FlushWriterConfig                      `json:"submissionWriter"`
+	SubmissionFailurePause   PublicTxManagerFailurePauseConfig      `json:"submissionFailurePause"`
+	OutOfGasRetry            PublicTxManagerOutOfGasRetryConfig     `json:"outOfGasRetry"`
+	Retry                    RetryConfig                            `json:"retry"`
+}
+
+type PublicTxManagerRejectedRetentionConfig struct {
+	MaxRetention  *string `json:"maxRetention"` // stored records of rejected transactions are deleted after this time - kept forever if 0
+	CheckInterval *string `json:"checkInterval"`
+}
+
+type PublicTxManagerOutOfGasRetryConfig struct {
+	Enabled            *bool `json:"enabled"`            // transactions that fail on-chain by running out of gas are resubmitted with a higher gas limit
+	IncreasePercentage *int  `json:"increasePercentage"` // the gas limit is increased by this percentage over the one that ran out, on each resubmission
+	MaxAttempts        *int  `json:"maxAttempts"`        // resubmissions after which the out of gas failure is reported as the result of the transaction
+}
+
+type PublicTxManagerFailurePauseConfig struct {
+	ConsecutiveFailures *int        `json:"consecutiveFailures"` // signing addresses are paused after this many submission failures in a row (disabled by default, or if 0)
+	Backoff             RetryConfig `json:"backoff"`             // the pause increases for each consecutive pause that is not followed by a successful submission
 }
 
 type PublicTxManagerActivityRecordsConfig struct {
@@ -128,8 +176,12 @@ const (
 )
 
 type BalanceManagerConfig struct {
-	Cache       CacheConfig       `json:"cache"`
-	AutoFueling AutoFuelingConfig `json:"autoFueling"`
+	// When set, a signing address is paused rather than submitting a transaction if its balance cannot cover
+	// the cost of the transaction while keeping this much in reserve
+	MinGasReserve   *string           `json:"minGasReserve"`
+	GasReservePause *string           `json:"gasReservePause"` // the longest an address is paused for before its balance is refreshed, if not topped up sooner
+	Cache           CacheConfig       `json:"cache"`
+	AutoFueling     AutoFuelingConfig `json:"autoFueling"`
 }
 
 type AutoFuelingConfig struct {
@@ -143,11 +195,15 @@ type AutoFuelingConfig struct {
 }
 
 type GasPriceConfig struct {
-	IncreaseMax        *string            `json:"increaseMax"`
-	IncreasePercentage *int               `json:"increasePercentage"`
-	FixedGasPrice      any                `json:"fixedGasPrice"` // number or object
-	GasOracleAPI       GasOracleAPIConfig `json:"gasOracleAPI"`
-	Cache              CacheConfig        `json:"cache"`
+	IncreaseMax        *string `json:"increaseMax"`
+	IncreasePercentage *int    `json:"increasePercentage"`
+	// Nodes reject a replacement transaction unless its gas price is bumped by at least this much (10% by default in geth/besu),
+	// so a lower increasePercentage is raised to this minimum
+	MinIncreasePercentage *int               `json:"minIncreasePercentage"`
+	FixedGasPrice         any                `json:"fixedGasPrice"`    // number or object
+	FallbackGasPrice      any                `json:"fallbackGasPrice"` // number or object, used only when the gas price cannot be retrieved
+	GasOracleAPI          GasOracleAPIConfig `json:"gasOracleAPI"`
+	Cache                 CacheConfig        `json:"cache"`
 }
 
 type GasOracleAPIConfig struct {
@@ -156,12 +212,18 @@ type GasOracleAPIConfig struct {
 }
 
 type PublicTxManagerOrchestratorConfig struct {
-	MaxInFlight               *int               `json:"maxInFlight"`
-	Interval                  *string            `json:"interval"`
-	ResubmitInterval          *string            `json:"resubmitInterval"`
-	StaleTimeout              *string            `json:"staleTimeout"`
-	StageRetryTime            *string            `json:"stageRetryTime"`
-	PersistenceRetryTime      *string            `json:"persistenceRetryTime"`
-	UnavailableBalanceHandler *string            `json:"unavailableBalanceHandler"`
-	SubmissionRetry           RetryConfigWithMax `json:"submissionRetry"`
+	MaxInFlight                *int                         `json:"maxInFlight"`
+	MaxInFlightPerOrchestrator *int                         `json:"maxInFlightPerOrchestrator"` // of those in flight, how many are actively signed and submitted - the rest wait in nonce order
+	Interval                   *string                      `json:"interval"`
+	ResubmitInterval           *string                      `json:"resubmitInterval"`
+	StaleTimeout               *string                      `json:"staleTimeout"`
+	StageRetryTime             *string                      `json:"stageRetryTime"`
+	PersistenceRetryTime       *string                      `json:"persistenceRetryTime"`
+	UnavailableBalanceHandler  *string                      `json:"unavailableBalanceHandler"`
+	SubmissionRetry            RetryConfigWithMax           `json:"submissionRetry"`
+	GapFill                    PublicTxManagerGapFillConfig `json:"gapFill"`
+}
+
+type PublicTxManagerGapFillConfig struct {
+	Enabled *bool `json:"enabled"` // when a stale queue is blocked by a suspended transaction holding the next nonce, that nonce is used by a zero-value transaction to self instead
 }
@@ -25,12 +25,26 @@ type RegistryManagerConfig struct {
 
 type RegistryManagerManagerConfig struct {
 	RegistryCache CacheConfig `json:"registryCache"`
+
+	// If set, only the named registries are trusted to resolve the transport details
+	// of other nodes. Any other configured registry is still loaded, but its entries
+	// are never used to connect to a node. If unset, all registries are trusted.
+	TrustedRegistries []string `json:"trustedRegistries,omitempty"`
+
+	// Entries published with a validUntil time are excluded from queries for active entries
+	// as soon as that time passes. They are also marked inactive in the DB on this interval,
+	// so that they are returned by queries for inactive entries. Set to 0 to disable.
+	ExpiryCheckInterval *string `json:"expiryCheckInterval"`
 }
 
 var RegistryCacheDefaults = &CacheConfig{
 	Capacity: confutil.P(100),
 }
 
+var RegistryManagerDefaults = &RegistryManagerManagerConfig{
+	ExpiryCheckInterval: confutil.P("30s"),
+}
+
 type RegistryInitConfig struct {
 	Retry RetryConfig `json:"retry"`
 }
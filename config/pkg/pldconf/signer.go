@@ -22,6 +22,7 @@ import (
 const (
 	KeyStoreTypeFilesystem = "filesystem" // keystorev3 based filesystem storage
 	KeyStoreTypeStatic     = "static"     // unencrypted keys in-line in the config
+	KeyStoreTypeRemote     = "remote"     // keys held by a remote key store service, accessed over gRPC
 )
 
 // Config can be directly embedded to provide ExtensibleConfig implementation
@@ -40,6 +41,7 @@ type KeyStoreConfig struct {
 	KeyStoreSigning   bool                     `json:"keyStoreSigning"` // if HD Wallet or ZKP based signing is required, in-memory keys are required (so this needs to be false)
 	FileSystem        FileSystemKeyStoreConfig `json:"filesystem"`
 	Static            StaticKeyStoreConfig     `json:"static"`
+	Remote            RemoteKeyStoreConfig     `json:"remote"`
 }
 
 type KeyDerivationType string
@@ -98,10 +100,19 @@ type StaticKeyStoreConfig struct {
 }
 
 type FileSystemKeyStoreConfig struct {
-	Path     *string     `json:"path"`
-	Cache    CacheConfig `json:"cache"`
-	FileMode *string     `json:"fileMode"`
-	DirMode  *string     `json:"dirMode"`
+	Path               *string                            `json:"path"`
+	Cache              CacheConfig                        `json:"cache"`
+	FileMode           *string                            `json:"fileMode"`
+	DirMode            *string                            `json:"dirMode"`
+	PasswordEncryption FileSystemPasswordEncryptionConfig `json:"passwordEncryption"`
+}
+
+// When a master key is supplied, the password of each key file is encrypted with it (AES-256-GCM)
+// rather than being stored in plaintext alongside the key file
+type FileSystemPasswordEncryptionConfig struct {
+	MasterKeyEnv     *string `json:"masterKeyEnv"`     // the environment variable containing the hex encoded 32 byte master key
+	MasterKeyFile    *string `json:"masterKeyFile"`    // a file containing the hex encoded 32 byte master key, such as one unsealed from a KMS onto a memory volume
+	MigratePlaintext *bool   `json:"migratePlaintext"` // existing plaintext password files are encrypted when they are first loaded (otherwise they are read as-is)
 }
 
 var FileSystemDefaults = &FileSystemKeyStoreConfig{
@@ -111,4 +122,21 @@ var FileSystemDefaults = &FileSystemKeyStoreConfig{
 	Cache: CacheConfig{
 		Capacity: confutil.P(100),
 	},
+	PasswordEncryption: FileSystemPasswordEncryptionConfig{
+		MigratePlaintext: confutil.P(false),
+	},
+}
+
+type RemoteKeyStoreConfig struct {
+	Endpoint       string      `json:"endpoint"` // gRPC target of the remote key store service
+	TLS            TLSConfig   `json:"tls"`
+	RequestTimeout *string     `json:"requestTimeout"`
+	Cache          CacheConfig `json:"cache"` // key material loaded from the remote store is cached in memory by key handle
+}
+
+var RemoteKeyStoreDefaults = &RemoteKeyStoreConfig{
+	RequestTimeout: confutil.P("10s"),
+	Cache: CacheConfig{
+		Capacity: confutil.P(100),
+	},
 }
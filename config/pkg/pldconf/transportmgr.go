@@ -14,9 +14,24 @@
  */
 package pldconf
 
+import "github.com/kaleido-io/paladin/config/pkg/confutil"
+
 type TransportManagerConfig struct {
 	NodeName   string                      `json:"nodeName"`
 	Transports map[string]*TransportConfig `json:"transports"`
+	Inbound    TransportInboundConfig      `json:"inbound"`
+}
+
+type TransportInboundConfig struct {
+	PeerConcurrency *int    `json:"peerConcurrency"` // how many messages from each peer node are processed at once (disabled by default, or if 0)
+	QueueTimeout    *string `json:"queueTimeout"`    // how long a message waits for processing before it is moved to the dead letters
+}
+
+var TransportManagerDefaults = &TransportManagerConfig{
+	Inbound: TransportInboundConfig{
+		PeerConcurrency: confutil.P(0),
+		QueueTimeout:    confutil.P("30s"),
+	},
 }
 
 type TransportInitConfig struct {
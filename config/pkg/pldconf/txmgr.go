@@ -20,11 +20,36 @@ import (
 )
 
 type TxManagerConfig struct {
-	ABI ABIConfig `json:"abi"`
+	ABI                     ABIConfig                  `json:"abi"`
+	MaxDependencies         *int                       `json:"maxDependencies"`
+	AllowedKeyPathOverrides *string                    `json:"allowedKeyPathOverrides"` // regular expression that key path overrides must match in full - overrides are rejected if unset
+	PreparedTransactions    PreparedTransactionsConfig `json:"preparedTransactions"`
+	ReorgPolicy             *string                    `json:"reorgPolicy"` // how to handle a re-org that changes the outcome of a public transaction that already has a receipt
+	ReceiptSubscriptions    ReceiptSubscriptionsConfig `json:"receiptSubscriptions"`
+}
+
+type ReorgPolicy string
+
+const (
+	ReorgPolicyCorrect ReorgPolicy = "correct" // the receipt is re-written with the new outcome, and marked as corrected
+	ReorgPolicyIgnore  ReorgPolicy = "ignore"  // the first receipt written is kept, and the new outcome is only logged
+)
+
+type PreparedTransactionsConfig struct {
+	MaxRetention  *string `json:"maxRetention"` // prepared transactions not submitted externally within this time are expired - no expiry if unset
+	CheckInterval *string `json:"checkInterval"`
+}
+
+type ReceiptSubscriptionsConfig struct {
+	BatchSize             *int    `json:"batchSize"`             // receipts read from the database in each query, while a subscription catches up
+	RecheckInterval       *string `json:"recheckInterval"`       // subscriptions check for new receipts at this interval, even if they have not been notified of any
+	CommitTimeout         *string `json:"commitTimeout"`         // how long to keep checking quickly for written receipts to be committed, so they can be given a sequence
+	CommitRecheckInterval *string `json:"commitRecheckInterval"` // how often to check while waiting for a commit
 }
 
 type ABIConfig struct {
-	Cache CacheConfig `json:"cache"`
+	Cache     CacheConfig `json:"cache"`
+	AutoStore *bool       `json:"autoStore"` // store ABIs supplied inline on calls - transactions always store their ABI, as it is referenced by the transaction record
 }
 
 var TxManagerDefaults = &TxManagerConfig{
@@ -32,5 +57,17 @@ var TxManagerDefaults = &TxManagerConfig{
 		Cache: CacheConfig{
 			Capacity: confutil.P(100),
 		},
+		AutoStore: confutil.P(true),
+	},
+	MaxDependencies: confutil.P(100),
+	PreparedTransactions: PreparedTransactionsConfig{
+		CheckInterval: confutil.P("1m"),
+	},
+	ReorgPolicy: confutil.P(string(ReorgPolicyCorrect)),
+	ReceiptSubscriptions: ReceiptSubscriptionsConfig{
+		BatchSize:             confutil.P(100),
+		RecheckInterval:       confutil.P("5s"),
+		CommitTimeout:         confutil.P("1s"),
+		CommitRecheckInterval: confutil.P("25ms"),
 	},
 }
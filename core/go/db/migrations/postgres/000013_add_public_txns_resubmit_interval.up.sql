@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public_txns ADD COLUMN "resubmit_interval" TEXT;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE public_txn_rejections (
+  "id"                        UUID            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "from"                      TEXT            NOT NULL,
+  "to"                        TEXT,
+  "gas"                       BIGINT,
+  "fixed_gas_pricing"         TEXT,
+  "value"                     TEXT,
+  "data"                      TEXT,
+  "reject_reason"             TEXT            NOT NULL,
+  "revert_data"               TEXT,
+  "transaction"               UUID,           -- one row per binding, or a single unbound row
+  "tx_type"                   TEXT,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX public_txn_rejections_from ON public_txn_rejections("from");
+CREATE INDEX public_txn_rejections_transaction ON public_txn_rejections("transaction");
+CREATE INDEX public_txn_rejections_created ON public_txn_rejections("created");
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE transaction_receipts DROP COLUMN "corrected";
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE transaction_receipts ADD COLUMN "corrected" BIGINT;
+
+COMMIT;
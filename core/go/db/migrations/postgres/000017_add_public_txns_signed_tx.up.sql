@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public_txns ADD COLUMN "signed_tx" TEXT;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE transactions ADD COLUMN "label" TEXT;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX transaction_receipts_sequence;
+ALTER TABLE transaction_receipts DROP COLUMN "sequence";
+
+COMMIT;
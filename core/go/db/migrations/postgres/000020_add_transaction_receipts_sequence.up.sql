@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE transaction_receipts ADD COLUMN "sequence" BIGSERIAL;
+CREATE UNIQUE INDEX transaction_receipts_sequence ON transaction_receipts ("sequence");
+
+COMMIT;
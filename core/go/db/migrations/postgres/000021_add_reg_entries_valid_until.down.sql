@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX reg_entries_valid_until;
+ALTER TABLE reg_entries DROP COLUMN "valid_until";
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE reg_entries ADD COLUMN "valid_until" BIGINT;
+CREATE INDEX reg_entries_valid_until ON reg_entries ("valid_until");
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX state_distributions_held_for_transaction;
+ALTER TABLE state_distributions DROP COLUMN "held_for_transaction";
+
+COMMIT;
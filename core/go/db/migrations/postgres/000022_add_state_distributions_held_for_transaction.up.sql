@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Set for domains that only distribute states once the public transaction is confirmed, until it is
+ALTER TABLE state_distributions ADD COLUMN "held_for_transaction" UUID;
+CREATE INDEX state_distributions_held_for_transaction ON state_distributions ("held_for_transaction");
+
+COMMIT;
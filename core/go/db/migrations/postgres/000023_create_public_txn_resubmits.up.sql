@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Written with the out of gas completion of a public transaction, until its resubmission is committed
+CREATE TABLE public_txn_resubmits (
+  "signer_nonce"              TEXT            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  PRIMARY KEY ("signer_nonce"),
+  FOREIGN KEY ("signer_nonce") REFERENCES public_txns ("signer_nonce") ON DELETE CASCADE
+);
+CREATE INDEX public_txn_resubmits_created ON public_txn_resubmits("created");
+
+COMMIT;
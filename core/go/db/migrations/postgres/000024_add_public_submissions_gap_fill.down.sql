@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public_submissions DROP COLUMN "gap_fill";
+
+COMMIT;
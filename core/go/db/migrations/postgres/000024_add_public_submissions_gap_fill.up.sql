@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE public_submissions ADD COLUMN "gap_fill" BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX transaction_receipts_unsequenced;
+CREATE SEQUENCE transaction_receipts_sequence_seq OWNED BY transaction_receipts."sequence";
+SELECT setval('transaction_receipts_sequence_seq', COALESCE((SELECT MAX("sequence") FROM transaction_receipts), 0) + 1, false);
+UPDATE transaction_receipts SET "sequence" = nextval('transaction_receipts_sequence_seq') WHERE "sequence" IS NULL;
+ALTER TABLE transaction_receipts ALTER COLUMN "sequence" SET DEFAULT nextval('transaction_receipts_sequence_seq');
+ALTER TABLE transaction_receipts ALTER COLUMN "sequence" SET NOT NULL;
+
+COMMIT;
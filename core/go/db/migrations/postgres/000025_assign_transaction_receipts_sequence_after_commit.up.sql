@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE transaction_receipts ALTER COLUMN "sequence" DROP DEFAULT;
+ALTER TABLE transaction_receipts ALTER COLUMN "sequence" DROP NOT NULL;
+DROP SEQUENCE transaction_receipts_sequence_seq;
+CREATE INDEX transaction_receipts_unsequenced ON transaction_receipts ("indexed") WHERE "sequence" IS NULL;
+
+COMMIT;
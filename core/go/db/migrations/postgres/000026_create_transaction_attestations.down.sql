@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE transaction_attestations;
+
+COMMIT;
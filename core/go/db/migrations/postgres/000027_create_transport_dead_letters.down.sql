@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE transport_dead_letters;
+
+COMMIT;
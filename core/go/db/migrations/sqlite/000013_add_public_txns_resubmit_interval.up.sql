@@ -0,0 +1 @@
+ALTER TABLE public_txns ADD COLUMN "resubmit_interval" VARCHAR;
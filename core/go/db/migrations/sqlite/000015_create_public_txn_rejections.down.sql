@@ -0,0 +1 @@
+DROP TABLE public_txn_rejections;
@@ -0,0 +1,18 @@
+CREATE TABLE public_txn_rejections (
+  "id"                        UUID            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "from"                      VARCHAR         NOT NULL,
+  "to"                        VARCHAR,
+  "gas"                       BIGINT,
+  "fixed_gas_pricing"         VARCHAR,
+  "value"                     VARCHAR,
+  "data"                      VARCHAR,
+  "reject_reason"             VARCHAR         NOT NULL,
+  "revert_data"               VARCHAR,
+  "transaction"               UUID,           -- one row per binding, or a single unbound row
+  "tx_type"                   VARCHAR,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX public_txn_rejections_from ON public_txn_rejections("from");
+CREATE INDEX public_txn_rejections_transaction ON public_txn_rejections("transaction");
+CREATE INDEX public_txn_rejections_created ON public_txn_rejections("created");
@@ -0,0 +1 @@
+ALTER TABLE transaction_receipts DROP COLUMN "corrected";
@@ -0,0 +1 @@
+ALTER TABLE transaction_receipts ADD COLUMN "corrected" BIGINT;
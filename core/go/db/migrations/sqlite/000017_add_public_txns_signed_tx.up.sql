@@ -0,0 +1 @@
+ALTER TABLE public_txns ADD COLUMN "signed_tx" VARCHAR;
@@ -0,0 +1 @@
+DROP TABLE sequencer_dependencies;
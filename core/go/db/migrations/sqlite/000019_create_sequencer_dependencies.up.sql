@@ -0,0 +1,12 @@
+-- The dependency edges between assembled transactions in each sequencer, so the
+-- dispatch ordering can be rebuilt after a restart
+CREATE TABLE sequencer_dependencies (
+    "contract_address"  VARCHAR NOT NULL,
+    "dependant"         UUID    NOT NULL,
+    "dependency"        UUID    NOT NULL,
+    "state_id"          VARCHAR NOT NULL,
+    "created"           BIGINT  NOT NULL,
+    PRIMARY KEY ("dependant", "dependency", "state_id")
+);
+CREATE INDEX sequencer_dependencies_contract_address ON sequencer_dependencies("contract_address");
+CREATE INDEX sequencer_dependencies_dependency ON sequencer_dependencies("dependency");
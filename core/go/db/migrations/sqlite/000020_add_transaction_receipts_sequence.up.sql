@@ -0,0 +1,24 @@
+CREATE TABLE transaction_receipts_seq (
+  "sequence"                  INTEGER         PRIMARY KEY AUTOINCREMENT,
+  "transaction"               UUID            NOT NULL,
+  "domain"                    TEXT            NOT NULL,
+  "indexed"                   BIGINT          NOT NULL,
+  "success"                   BOOLEAN         NOT NULL,
+  "failure_message"           TEXT,
+  "revert_data"               TEXT,
+  "tx_hash"                   TEXT,
+  "tx_index"                  INT,
+  "log_index"                 INT,
+  "source"                    TEXT,
+  "block_number"              BIGINT,
+  "contract_address"          TEXT,
+  "corrected"                 BIGINT
+);
+INSERT INTO transaction_receipts_seq ("transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address", "corrected")
+  SELECT "transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address", "corrected"
+  FROM transaction_receipts ORDER BY "indexed";
+DROP TABLE transaction_receipts;
+ALTER TABLE transaction_receipts_seq RENAME TO transaction_receipts;
+CREATE UNIQUE INDEX transaction_receipts_transaction ON transaction_receipts("transaction");
+CREATE INDEX transaction_receipts_tx_hash ON transaction_receipts("tx_hash");
+CREATE INDEX transaction_receipts_source ON transaction_receipts ("source");
@@ -0,0 +1,2 @@
+DROP INDEX reg_entries_valid_until;
+ALTER TABLE reg_entries DROP COLUMN "valid_until";
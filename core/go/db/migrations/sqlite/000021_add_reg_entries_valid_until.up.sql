@@ -0,0 +1,2 @@
+ALTER TABLE reg_entries ADD COLUMN "valid_until" BIGINT;
+CREATE INDEX reg_entries_valid_until ON reg_entries ("valid_until");
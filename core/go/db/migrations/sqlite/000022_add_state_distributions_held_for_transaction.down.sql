@@ -0,0 +1,2 @@
+DROP INDEX state_distributions_held_for_transaction;
+ALTER TABLE state_distributions DROP COLUMN "held_for_transaction";
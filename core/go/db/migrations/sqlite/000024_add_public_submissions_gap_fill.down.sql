@@ -0,0 +1 @@
+ALTER TABLE public_submissions DROP COLUMN "gap_fill";
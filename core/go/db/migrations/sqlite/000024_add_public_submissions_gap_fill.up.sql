@@ -0,0 +1 @@
+ALTER TABLE public_submissions ADD COLUMN "gap_fill" BOOLEAN NOT NULL DEFAULT false;
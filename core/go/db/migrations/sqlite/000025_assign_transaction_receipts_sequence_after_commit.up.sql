@@ -0,0 +1,26 @@
+CREATE TABLE transaction_receipts_aftercommit (
+  "transaction"               UUID            NOT NULL,
+  "domain"                    TEXT            NOT NULL,
+  "indexed"                   BIGINT          NOT NULL,
+  "success"                   BOOLEAN         NOT NULL,
+  "failure_message"           TEXT,
+  "revert_data"               TEXT,
+  "tx_hash"                   TEXT,
+  "tx_index"                  INT,
+  "log_index"                 INT,
+  "source"                    TEXT,
+  "block_number"              BIGINT,
+  "contract_address"          TEXT,
+  "corrected"                 BIGINT,
+  "sequence"                  BIGINT,
+  PRIMARY KEY ("transaction")
+);
+INSERT INTO transaction_receipts_aftercommit ("transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address", "corrected", "sequence")
+  SELECT "transaction", "domain", "indexed", "success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "contract_address", "corrected", "sequence"
+  FROM transaction_receipts;
+DROP TABLE transaction_receipts;
+ALTER TABLE transaction_receipts_aftercommit RENAME TO transaction_receipts;
+CREATE UNIQUE INDEX transaction_receipts_sequence ON transaction_receipts ("sequence");
+CREATE INDEX transaction_receipts_unsequenced ON transaction_receipts ("indexed") WHERE "sequence" IS NULL;
+CREATE INDEX transaction_receipts_tx_hash ON transaction_receipts("tx_hash");
+CREATE INDEX transaction_receipts_source ON transaction_receipts ("source");
@@ -0,0 +1,9 @@
+-- The attestation plan of each dispatched private transaction, with the progress of each party,
+-- so it can be inspected after the transaction has left the memory of the sequencer
+CREATE TABLE transaction_attestations (
+    "transaction"       UUID    NOT NULL,
+    "contract_address"  VARCHAR NOT NULL,
+    "attestations"      VARCHAR NOT NULL,
+    "created"           BIGINT  NOT NULL,
+    PRIMARY KEY ("transaction")
+);
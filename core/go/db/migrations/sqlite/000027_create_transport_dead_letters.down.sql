@@ -0,0 +1 @@
+DROP TABLE transport_dead_letters;
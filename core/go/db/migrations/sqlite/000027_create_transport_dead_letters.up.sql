@@ -0,0 +1,16 @@
+-- Inbound messages that could not be processed in time, kept so they are not lost when they are
+-- moved aside to let the other messages from the sending node through
+CREATE TABLE transport_dead_letters (
+  "id"                        UUID            NOT NULL,
+  "created"                   BIGINT          NOT NULL,
+  "transport"                 VARCHAR         NOT NULL,
+  "node"                      VARCHAR         NOT NULL,
+  "component"                 VARCHAR         NOT NULL,
+  "message_type"              VARCHAR         NOT NULL,
+  "correlation_id"            UUID,
+  "payload"                   VARCHAR         NOT NULL,
+  "reason"                    VARCHAR         NOT NULL,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX transport_dead_letters_node ON transport_dead_letters("node");
+CREATE INDEX transport_dead_letters_created ON transport_dead_letters("created");
@@ -54,6 +54,8 @@ type Domain interface {
 	RegistryAddress() *tktypes.EthAddress
 	Configuration() *prototk.DomainConfig
 	CustomHashFunction() bool
+	SequentialEndorsement() bool
+	StateDistributionOrder() pldconf.StateDistributionOrder
 
 	InitDeploy(ctx context.Context, tx *PrivateContractDeploy) error
 	PrepareDeploy(ctx context.Context, tx *PrivateContractDeploy) error
@@ -75,6 +77,8 @@ type DomainSmartContract interface {
 	InitTransaction(ctx context.Context, tx *PrivateTransaction) error
 	AssembleTransaction(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
 	WritePotentialStates(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
+	ValidateAssembled(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
+	BuildAttestationPayloads(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
 	LockStates(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
 	EndorseTransaction(dCtx DomainContext, readTX *gorm.DB, req *PrivateTransactionEndorseRequest) (*EndorsementResult, error)
 	PrepareTransaction(dCtx DomainContext, readTX *gorm.DB, tx *PrivateTransaction) error
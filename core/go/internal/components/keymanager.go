@@ -69,4 +69,7 @@ type KeyManager interface {
 	ReverseKeyLookup(ctx context.Context, dbTX *gorm.DB, algorithm, verifierType, verifier string) (mapping *pldapi.KeyMappingAndVerifier, err error)
 
 	Sign(ctx context.Context, mapping *pldapi.KeyMappingAndVerifier, payloadType string, payload []byte) ([]byte, error)
+
+	// Replaces the key material of an existing identifier, after which its verifiers are resolved again from the new key material
+	RotateKey(ctx context.Context, identifier string) (*signerapi.RotateKeyResponse, error)
 }
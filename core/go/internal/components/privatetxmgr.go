@@ -17,8 +17,12 @@ package components
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"gorm.io/gorm"
 )
 
@@ -34,11 +38,108 @@ type TransactionDispatchedEvent struct {
 	SigningAddress  string `json:"signingAddress"`
 }
 
+// Delivered when the base ledger transaction for a previously dispatched transaction has been confirmed on-chain
+type TransactionConfirmedEvent struct {
+	TransactionID   string `json:"transactionId"`
+	ContractAddress string `json:"contractAddress"`
+	Nonce           uint64 `json:"nonce"`
+	SigningAddress  string `json:"signingAddress"`
+	BlockNumber     int64  `json:"blockNumber"`
+	Success         bool   `json:"success"`
+	Corrected       bool   `json:"corrected,omitempty"` // a re-org changed the outcome after it was first confirmed
+}
+
 type PrivateTxStatus struct {
-	TxID        string `json:"transactionId"`
-	Status      string `json:"status"`
-	LatestEvent string `json:"latestEvent"`
-	LatestError string `json:"latestError"`
+	TxID         string                  `json:"transactionId"`
+	Status       string                  `json:"status"`
+	LatestEvent  string                  `json:"latestEvent"`
+	LatestError  string                  `json:"latestError"`
+	Label        string                  `json:"label,omitempty"`
+	StageTimings []*PrivateTxStageTiming `json:"stageTimings,omitempty"`
+}
+
+type PrivateTxStage string
+
+const (
+	PrivateTxStageResolving   PrivateTxStage = "resolving"
+	PrivateTxStageAssembling  PrivateTxStage = "assembling"
+	PrivateTxStageEndorsing   PrivateTxStage = "endorsing"
+	PrivateTxStageDispatching PrivateTxStage = "dispatching"
+)
+
+// A stage can be entered more than once (for example on re-assembly), in which case there is a timing for each visit.
+// The duration of the current stage is measured up to the time the status was queried.
+type PrivateTxStageTiming struct {
+	Stage    PrivateTxStage     `json:"stage"`
+	Entered  tktypes.Timestamp  `json:"entered"`
+	Exited   *tktypes.Timestamp `json:"exited,omitempty"`
+	Duration string             `json:"duration"`
+}
+
+// The attestation plan of a private transaction, with the progress of each party that has been asked to attest.
+// The plan is empty until the transaction has been assembled.
+type PrivateTxAttestations struct {
+	TxID         string                  `json:"transactionId"`
+	Attestations []*PrivateTxAttestation `json:"attestations"`
+}
+
+type PrivateTxAttestation struct {
+	Name            string                       `json:"name"`
+	AttestationType string                       `json:"attestationType"`
+	Algorithm       string                       `json:"algorithm"`
+	VerifierType    string                       `json:"verifierType"`
+	Parties         []*PrivateTxAttestationParty `json:"parties"`
+}
+
+type PrivateTxAttestationParty struct {
+	Party    string `json:"party"`
+	Verifier string `json:"verifier,omitempty"` // empty until the verifier of the party has been resolved
+	Received bool   `json:"received"`
+	Result   string `json:"result,omitempty"` // for endorsements, whether the endorser signed (SIGN) or must submit the transaction itself (ENDORSER_SUBMIT)
+}
+
+// Aggregated across all of the sequencers on this node
+type PrivateTxStats struct {
+	ByStatus     map[string]int                            // transactions in flight, by their current status
+	StageLatency map[PrivateTxStage]*PrivateTxStageLatency // visits to each stage exited since the requested time, by transactions in flight and those completed
+	DelegatedIn  int                                       // transactions delegated to this node for coordination since startup
+}
+
+type PrivateTxStageLatency struct {
+	Count int
+	Total time.Duration
+}
+
+func (l *PrivateTxStageLatency) Average() time.Duration {
+	if l == nil || l.Count == 0 {
+		return 0
+	}
+	return l.Total / time.Duration(l.Count)
+}
+
+type PrivateTxBlockedReason string
+
+const (
+	// The transaction spends a state minted by a transaction that has not yet been endorsed
+	PrivateTxBlockedDependency PrivateTxBlockedReason = "dependency"
+	// The transaction spends a state minted by an endorsed transaction that is itself blocked,
+	// so must be dispatched after it to preserve nonce ordering on the base ledger
+	PrivateTxBlockedNonceOrdering PrivateTxBlockedReason = "nonce_ordering"
+	// The transaction spends a state minted by a transaction that is being dispatched with a different signing
+	// address, so must wait for that to be dispatched first as there is no ordering across signing addresses
+	PrivateTxBlockedSigningAddress PrivateTxBlockedReason = "signing_address"
+)
+
+type PrivateTxBlocker struct {
+	TxID   string                 `json:"transactionId"`
+	Reason PrivateTxBlockedReason `json:"reason"`
+	States []string               `json:"states"`
+}
+
+// An endorsed transaction that is ready for dispatch, but is held back by one or more other in-flight transactions
+type BlockedPrivateTransaction struct {
+	TxID      string              `json:"transactionId"`
+	BlockedBy []*PrivateTxBlocker `json:"blockedBy"`
 }
 
 type StateDistributionSet struct {
@@ -60,6 +161,7 @@ type StateDistribution struct {
 	NullifierAlgorithm    *string
 	NullifierVerifierType *string
 	NullifierPayloadType  *string
+	HeldForTransaction    *uuid.UUID // not sent until this private transaction is confirmed, for domains that distribute states after confirmation
 }
 
 type PrivateTxManager interface {
@@ -69,15 +171,29 @@ type PrivateTxManager interface {
 	//Synchronous functions to submit a new private transaction
 	HandleNewTx(ctx context.Context, tx *ValidatedTransaction) error
 	GetTxStatus(ctx context.Context, domainAddress string, txID string) (status PrivateTxStatus, err error)
+	GetTxAttestations(ctx context.Context, domainAddress string, txID string) (*PrivateTxAttestations, error)
+	GetBlockedTransactions(ctx context.Context, domainAddress string) ([]*BlockedPrivateTransaction, error)
+	GetTxStats(ctx context.Context, since tktypes.Timestamp) *PrivateTxStats
+	CancelTransaction(ctx context.Context, contractAddr string, txID uuid.UUID) error
 
 	// Synchronous function to call an existing deployed smart contract
 	CallPrivateSmartContract(ctx context.Context, call *TransactionInputs) (*abi.ComponentValue, error)
 
+	// Synchronous dry run of a transaction, to estimate the gas of the public transaction it would result in
+	EstimatePrivateTransactionGas(ctx context.Context, txi *TransactionInputs, publicTxOptions pldapi.PublicTxOptions) (tktypes.HexUint64, error)
+
 	//TODO this is just a placeholder until we figure out the external interface for events
 	// in the meantime, this is handy for some blackish box testing
 	Subscribe(ctx context.Context, subscriber PrivateTxEventSubscriber)
 
-	NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, confirms []*PublicTxMatch) error
+	// Called within the DB transaction that records the failures, returning a function to call once it has committed
+	NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, confirms []*PublicTxMatch) (postCommit func(), err error)
+
+	// Called within the DB transaction in which domains record the confirmation of private transactions, returning a function to call once it has committed
+	NotifyConfirmedPrivateTx(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) (postCommit func(), err error)
+
+	// Called once receipts corrected after a re-org changed the outcome of the base ledger transaction have committed
+	NotifyCorrectedPublicTx(ctx context.Context, corrections []*PublicTxMatch)
 
 	PrivateTransactionConfirmed(ctx context.Context, receipt *TxCompletion)
 
@@ -39,7 +39,8 @@ type PublicTxRejected interface {
 }
 
 type PublicTxBatch interface {
-	Submit(ctx context.Context, dbTX *gorm.DB) error
+	Submit(ctx context.Context, dbTX *gorm.DB) error          // writes the accepted transactions, along with any record of the rejected transactions
+	PersistRejected(ctx context.Context, dbTX *gorm.DB) error // writes only the record of the rejected transactions, for callers that do not submit a batch with rejections
 	Accepted() []PublicTxAccepted
 	Rejected() []PublicTxRejected
 	Completed(ctx context.Context, committed bool) // caller must ensure this is called on all code paths, and only with true after DB TX has committed
@@ -53,11 +54,24 @@ var PublicTxFilterFields filters.FieldSet = filters.FieldMap{
 	"transactionHash": filters.Int64Field(`"Completed"."tx_hash"`),
 	"success":         filters.BooleanField(`"Completed"."success"`),
 	"revertData":      filters.HexBytesField(`"Completed"."revert_data"`),
+	"transaction":     filters.UUIDField(`"Binding"."transaction"`),
+}
+
+var PublicTxRejectionFilterFields filters.FieldSet = filters.FieldMap{
+	"id":           filters.UUIDField("id"),
+	"created":      filters.Int64Field("created"),
+	"from":         filters.HexBytesField(`"from"`),
+	"to":           filters.HexBytesField(`"to"`),
+	"rejectReason": filters.StringField("reject_reason"),
+	"transaction":  filters.UUIDField(`"transaction"`),
 }
 
 type PublicTxSubmission struct {
 	Bindings             []*PaladinTXReference
 	pldapi.PublicTxInput // the request to create the transaction
+	// A fully signed raw transaction, for callers that sign externally. Everything else about the transaction
+	// (including the signer, nonce and gas pricing) comes from the signed payload, which is submitted exactly as supplied.
+	SignedTransaction tktypes.HexBytes
 }
 
 type PaladinTXReference struct {
@@ -68,6 +82,15 @@ type PaladinTXReference struct {
 type PublicTxMatch struct {
 	PaladinTXReference
 	*blockindexer.IndexedTransactionNotify
+	// Set when a completion was already recorded for this public transaction with a different hash or result,
+	// meaning a re-org has changed the outcome after the transaction was previously confirmed
+	Corrected bool
+	// Set when the transaction failed by running out of gas, and is being resubmitted with a higher gas limit,
+	// so the failure is not the final outcome of the Paladin transaction
+	OutOfGasResubmit bool
+	// Set when the nonce was used by a gap-fill transaction while the transaction was suspended, so the
+	// transaction failed without being mined - the Result is always a failure for these
+	GapFilled bool
 }
 
 type PublicTxManager interface {
@@ -76,8 +99,13 @@ type PublicTxManager interface {
 	// Synchronous functions that are executed on the callers thread
 	QueryPublicTxForTransactions(ctx context.Context, dbTX *gorm.DB, boundToTxns []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error)
 	QueryPublicTxWithBindings(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error)
+	QueryPublicTxRejections(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.PublicTxRejection, error)
 	GetPublicTransactionForHash(ctx context.Context, dbTX *gorm.DB, hash tktypes.Bytes32) (*pldapi.PublicTxWithBinding, error)
+	GetPublicTransactionSubmissions(ctx context.Context, dbTX *gorm.DB, from tktypes.EthAddress, nonce uint64) ([]*pldapi.PublicTxSubmissionData, error)
 	PrepareSubmissionBatch(ctx context.Context, transactions []*PublicTxSubmission) (batch PublicTxBatch, err error)
-	MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify) ([]*PublicTxMatch, error)
+	MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify, applyCorrections bool) ([]*PublicTxMatch, error)
 	NotifyConfirmPersisted(ctx context.Context, confirms []*PublicTxMatch)
+	ResubmitAllForAddress(ctx context.Context, from tktypes.EthAddress) ([]uint64, error)
+	// EstimateGas estimates the gas limit of a transaction, without submitting it or assigning a nonce
+	EstimateGas(ctx context.Context, txi *pldapi.PublicTxInput) (tktypes.HexUint64, error)
 }
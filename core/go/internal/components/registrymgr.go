@@ -55,4 +55,6 @@ type Registry interface {
 	QueryEntries(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, jq *query.QueryJSON) ([]*pldapi.RegistryEntry, error)
 	QueryEntriesWithProps(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, jq *query.QueryJSON) ([]*pldapi.RegistryEntryWithProperties, error)
 	GetEntryProperties(ctx context.Context, dbTX *gorm.DB, fActive pldapi.ActiveFilter, entityIDs ...tktypes.HexBytes) ([]*pldapi.RegistryProperty, error)
+	ExportSnapshot(ctx context.Context, dbTX *gorm.DB) (*pldapi.RegistrySnapshot, error)
+	ImportSnapshot(ctx context.Context, dbTX *gorm.DB, snapshot *pldapi.RegistrySnapshot) (postCommit func(), err error)
 }
@@ -42,6 +42,13 @@ type StateManager interface {
 	// Ensure ABI schemas upserts all the specified schemas, using the given DB transaction
 	EnsureABISchemas(ctx context.Context, dbTX *gorm.DB, domainName string, defs []*abi.Parameter) ([]Schema, error)
 
+	// Register the migrations a domain provides from superseded schemas to their replacements.
+	// States stored against a superseded schema are migrated lazily when read with GetState, or eagerly with MigrateStates
+	RegisterSchemaMigrations(ctx context.Context, domainName string, migrations []*SchemaMigration) error
+
+	// Migrate all states for the domain that are stored against a superseded schema, returning the number of states migrated
+	MigrateStates(ctx context.Context, dbTX *gorm.DB, domainName string) (int, error)
+
 	// State finalizations are written on the DB context of the block indexer, by the domain manager.
 	WriteStateFinalizations(ctx context.Context, dbTX *gorm.DB, spends []*pldapi.StateSpendRecord, reads []*pldapi.StateReadRecord, confirms []*pldapi.StateConfirmRecord, infoRecords []*pldapi.StateInfoRecord) (err error)
 
@@ -137,6 +144,13 @@ type DomainContext interface {
 	// No dependency analysis is done by this function call - that is the responsibility of the caller.
 	ResetTransactions(transactionID ...uuid.UUID)
 
+	// Discards everything that the given transactions have added to the context, including the states they created
+	// that have not yet been written to the database. Unlike Reset(), this also clears a failed flush while keeping
+	// the writes of all other transactions in it, which are written by the next flush instead.
+	//
+	// No dependency analysis is done by this function call - that is the responsibility of the caller.
+	DiscardTransactions(transactionID ...uuid.UUID)
+
 	// Return a complete copy of the current set of locks being managed in this context
 	// Mainly for debugging (lots of memory is copied) so any case this function is used on a critical path
 	// should be considered as a requirement for a new function on this interface that can be performed
@@ -158,6 +172,12 @@ type DomainContext interface {
 	// of the database transaction.
 	//
 	// If an error is returned by this function, then the postDBTx callback will be nil
+	//
+	// A flush is all-or-nothing. The states and nullifiers are only written within the supplied DB transaction,
+	// which must be rolled back if this function or anything else in the DB transaction fails, so a partial
+	// flush is never committed. After a failure the context returns an error from every call until Reset() is
+	// called, which discards all in-memory states so none that failed to flush remain visible, or DiscardTransactions()
+	// is called for the transactions whose states must no longer be visible.
 	Flush(dbTX *gorm.DB) (postDBTx func(error), err error)
 
 	// Removes the domain context from the state manager, and prevents any further use
@@ -188,6 +208,14 @@ func (s *StateWithLabels) ValueSet() filters.ValueSet {
 	return s.LabelValues
 }
 
+// A SchemaMigration converts the data of a state from a superseded schema to the schema that replaces it.
+// The state ID is retained through the migration.
+type SchemaMigration struct {
+	FromSchemaID tktypes.Bytes32
+	ToSchemaID   tktypes.Bytes32
+	Migrate      func(ctx context.Context, contractAddress tktypes.EthAddress, stateID tktypes.HexBytes, data tktypes.RawJSON) (tktypes.RawJSON, error)
+}
+
 type NullifierUpsert struct {
 	ID    tktypes.HexBytes `json:"id"              gorm:"primaryKey"`
 	State tktypes.HexBytes `json:"-"`
@@ -53,6 +53,7 @@ type TransactionPreAssembly struct {
 	TransactionSpecification *prototk.TransactionSpecification `json:"transaction_specification"`
 	RequiredVerifiers        []*prototk.ResolveVerifierRequest `json:"required_verifiers"`
 	Verifiers                []*prototk.ResolvedVerifier       `json:"verifiers"`
+	Label                    string                            `json:"label,omitempty"` // human-readable label supplied by the domain on init, and refined on assembly
 }
 
 type FullState struct {
@@ -82,6 +83,7 @@ type TransactionPostAssembly struct {
 	OutputStates          []*FullState                               `json:"output_states"`
 	InfoStates            []*FullState                               `json:"info_states"`
 	AttestationPlan       []*prototk.AttestationRequest              `json:"attestation_plan"`
+	RequiredVerifiers     []*prototk.ResolveVerifierRequest          `json:"required_verifiers"` // in addition to those in the PreAssembly
 	Signatures            []*prototk.AttestationResult               `json:"signatures"`
 	Endorsements          []*prototk.AttestationResult               `json:"endorsements"`
 	ExtraData             *string                                    `json:"extra_data"`
@@ -110,6 +112,9 @@ type PrivateTransaction struct {
 	PreparedMetadata           tktypes.RawJSON          `json:"-"`
 
 	PublicTxOptions pldapi.PublicTxOptions `json:"-"`
+
+	// Dependencies declared by the submitter, which are only known on the submitting node
+	DependsOn []uuid.UUID `json:"-"`
 }
 
 // PrivateContractDeploy is a simpler transaction type that constructs new private smart contract instances
@@ -137,6 +142,7 @@ type PrivateTransactionEndorseRequest struct {
 	TransactionSpecification *prototk.TransactionSpecification
 	Verifiers                []*prototk.ResolvedVerifier
 	Signatures               []*prototk.AttestationResult
+	Endorsements             []*prototk.AttestationResult
 	InputStates              []*prototk.EndorsableState
 	ReadStates               []*prototk.EndorsableState
 	OutputStates             []*prototk.EndorsableState
@@ -73,6 +73,10 @@ type TransportClient interface {
 	ReceiveTransportMessage(context.Context, *TransportMessage)
 }
 
+// The version of the protocol for messages exchanged between nodes over transports.
+// Must be incremented whenever an incompatible change is made to those messages.
+const TransportProtocolVersion = 1
+
 type TransportManager interface {
 	ManagerLifecycle
 	ConfiguredTransports() map[string]*pldconf.PluginConfig
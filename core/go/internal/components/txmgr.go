@@ -87,6 +87,8 @@ type TXManager interface {
 	GetTransactionByID(ctx context.Context, id uuid.UUID) (*pldapi.Transaction, error)
 	GetTransactionByIDFull(ctx context.Context, id uuid.UUID) (result *pldapi.TransactionFull, err error)
 	GetTransactionDependencies(ctx context.Context, id uuid.UUID) (*pldapi.TransactionDependencies, error)
+	ExportTransactionTrace(ctx context.Context, id uuid.UUID) (*pldapi.TransactionTrace, error)
+	GetNodeInfo(ctx context.Context) (*pldapi.NodeInfo, error)
 	GetPublicTransactionByNonce(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64) (*pldapi.PublicTxWithBinding, error)
 	GetPublicTransactionByHash(ctx context.Context, hash tktypes.Bytes32) (*pldapi.PublicTxWithBinding, error)
 	QueryTransactions(ctx context.Context, jq *query.QueryJSON, pending bool) ([]*pldapi.Transaction, error)
@@ -103,5 +105,7 @@ type TXManager interface {
 
 	PrepareInternalPrivateTransaction(ctx context.Context, dbTX *gorm.DB, tx *pldapi.TransactionInput, submitMode pldapi.SubmitMode) (*ValidatedTransaction, error)
 	UpsertInternalPrivateTxsFinalizeIDs(ctx context.Context, dbTX *gorm.DB, txis []*ValidatedTransaction) error
+	SetTransactionCoordinator(ctx context.Context, dbTX *gorm.DB, coordinator string, txIDs []uuid.UUID) error
+	SetTransactionLabel(ctx context.Context, dbTX *gorm.DB, txID uuid.UUID, label string) error
 	WritePreparedTransactions(ctx context.Context, dbTX *gorm.DB, prepared []*PrepareTransactionWithRefs) (err error)
 }
@@ -61,8 +61,8 @@ type domain struct {
 	initialized        atomic.Bool
 	initRetry          *retry.Retry
 	config             *prototk.DomainConfig
-	schemasBySignature map[string]components.Schema
-	schemasByID        map[string]components.Schema
+	schemasBySignature map[string]*domainSchema
+	schemasByID        map[string]*domainSchema
 	eventStream        *blockindexer.EventStream
 
 	initError atomic.Pointer[error]
@@ -72,6 +72,13 @@ type domain struct {
 	inFlightLock sync.Mutex
 }
 
+// Superseded versions of a schema are retained in the schema lookups alongside the current
+// versions, with a reference to the schema that replaced them
+type domainSchema struct {
+	components.Schema
+	supersededBy *domainSchema
+}
+
 type inFlightDomainRequest struct {
 	d    *domain
 	id   string                   // each request gets a unique ID
@@ -89,8 +96,8 @@ func (dm *domainManager) newDomain(name string, conf *pldconf.DomainConfig, toDo
 		initDone:        make(chan struct{}),
 		registryAddress: tktypes.MustEthAddress(conf.RegistryAddress), // check earlier in startup
 
-		schemasByID:        make(map[string]components.Schema),
-		schemasBySignature: make(map[string]components.Schema),
+		schemasByID:        make(map[string]*domainSchema),
+		schemasBySignature: make(map[string]*domainSchema),
 
 		inFlight: make(map[string]*inFlightDomainRequest),
 	}
@@ -124,16 +131,22 @@ func (d *domain) processDomainConfig(confRes *prototk.ConfigureDomainResponse) (
 
 	// Build the schema IDs to send back in the init
 	schemasProto := make([]*prototk.StateSchema, len(schemas))
+	currentSchemas := make([]*domainSchema, len(schemas))
 	for i, s := range schemas {
 		schemaID := s.ID()
-		d.schemasByID[schemaID.String()] = s
-		d.schemasBySignature[s.Signature()] = s
+		currentSchemas[i] = &domainSchema{Schema: s}
+		d.schemasByID[schemaID.String()] = currentSchemas[i]
+		d.schemasBySignature[s.Signature()] = currentSchemas[i]
 		schemasProto[i] = &prototk.StateSchema{
 			Id:        schemaID.String(),
 			Signature: s.Signature(),
 		}
 	}
 
+	if err := d.processSchemaMigrations(currentSchemas); err != nil {
+		return nil, err
+	}
+
 	stream := &blockindexer.EventStream{
 		Type: blockindexer.EventStreamTypeInternal.Enum(),
 		Sources: []blockindexer.EventStreamSource{
@@ -176,6 +189,46 @@ func (d *domain) processDomainConfig(confRes *prototk.ConfigureDomainResponse) (
 	}, nil
 }
 
+// Superseded schemas are recorded to the DB, so that we can register the migrations
+// the domain provides for any existing states to the schemas that replace them
+func (d *domain) processSchemaMigrations(currentSchemas []*domainSchema) error {
+	if len(d.config.StateSchemaMigrations) == 0 {
+		return nil
+	}
+
+	fromABISchemas := make([]*abi.Parameter, len(d.config.StateSchemaMigrations))
+	for i, m := range d.config.StateSchemaMigrations {
+		if m.ToSchemaIndex < 0 || int(m.ToSchemaIndex) >= len(currentSchemas) {
+			return i18n.NewError(d.ctx, msgs.MsgDomainInvalidSchemaMigration, i)
+		}
+		if err := json.Unmarshal([]byte(m.FromAbiStateSchemaJson), &fromABISchemas[i]); err != nil {
+			return i18n.WrapError(d.ctx, err, msgs.MsgDomainInvalidSchemaMigration, i)
+		}
+	}
+	fromSchemas, err := d.dm.stateStore.EnsureABISchemas(d.ctx, d.dm.persistence.DB(), d.name, fromABISchemas)
+	if err != nil {
+		return err
+	}
+
+	migrations := make([]*components.SchemaMigration, len(fromSchemas))
+	for i, fromSchema := range fromSchemas {
+		toSchema := currentSchemas[d.config.StateSchemaMigrations[i].ToSchemaIndex]
+		if d.schemasByID[fromSchema.ID().String()] != nil {
+			// A schema cannot be both current, and superseded
+			return i18n.NewError(d.ctx, msgs.MsgDomainInvalidSchemaMigration, i)
+		}
+		superseded := &domainSchema{Schema: fromSchema, supersededBy: toSchema}
+		d.schemasByID[fromSchema.ID().String()] = superseded
+		d.schemasBySignature[fromSchema.Signature()] = superseded
+		migrations[i] = &components.SchemaMigration{
+			FromSchemaID: fromSchema.ID(),
+			ToSchemaID:   toSchema.ID(),
+			Migrate:      d.schemaMigrator(fromSchema.ID(), toSchema.ID()),
+		}
+	}
+	return d.dm.stateStore.RegisterSchemaMigrations(d.ctx, d.name, migrations)
+}
+
 func (d *domain) init() {
 	defer close(d.initDone)
 
@@ -701,6 +754,17 @@ func (d *domain) CustomHashFunction() bool {
 	return d.config.CustomHashFunction
 }
 
+func (d *domain) SequentialEndorsement() bool {
+	return d.conf.SequentialEndorsement
+}
+
+func (d *domain) StateDistributionOrder() pldconf.StateDistributionOrder {
+	if d.conf.StateDistribution == "" {
+		return pldconf.StateDistributionBeforeDispatch
+	}
+	return pldconf.StateDistributionOrder(d.conf.StateDistribution)
+}
+
 func (d *domain) ValidateStateHashes(ctx context.Context, states []*components.FullState) ([]tktypes.HexBytes, error) {
 	if len(states) == 0 {
 		return []tktypes.HexBytes{}, nil
@@ -728,6 +792,22 @@ func (d *domain) ValidateStateHashes(ctx context.Context, states []*components.F
 	return hexIDs, nil
 }
 
+func (d *domain) schemaMigrator(fromSchemaID, toSchemaID tktypes.Bytes32) func(context.Context, tktypes.EthAddress, tktypes.HexBytes, tktypes.RawJSON) (tktypes.RawJSON, error) {
+	return func(ctx context.Context, contractAddress tktypes.EthAddress, stateID tktypes.HexBytes, data tktypes.RawJSON) (tktypes.RawJSON, error) {
+		res, err := d.api.MigrateState(ctx, &prototk.MigrateStateRequest{
+			FromSchemaId:    fromSchemaID.String(),
+			ToSchemaId:      toSchemaID.String(),
+			StateId:         stateID.String(),
+			ContractAddress: contractAddress.String(),
+			StateDataJson:   data.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return tktypes.RawJSON(res.StateDataJson), nil
+	}
+}
+
 func (d *domain) GetDomainReceipt(ctx context.Context, dbTX *gorm.DB, txID uuid.UUID) (tktypes.RawJSON, error) {
 
 	// Load up the currently available set of states
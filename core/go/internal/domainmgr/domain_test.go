@@ -282,6 +282,8 @@ func TestDomainInitStates(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, td.d, byAddr)
 	assert.True(t, td.d.Initialized())
+	assert.False(t, td.d.SequentialEndorsement())
+	assert.Equal(t, pldconf.StateDistributionBeforeDispatch, td.d.StateDistributionOrder())
 
 }
 func mockUpsertABIOk(mc *mockComponents) {
@@ -331,6 +333,141 @@ func TestDoubleRegisterReplaces(t *testing.T) {
 
 }
 
+const fakeCoinStateSchemaV2 = `{
+	"type": "tuple",
+	"internalType": "struct FakeCoin",
+	"components": [
+		{
+			"name": "salt",
+			"type": "bytes32"
+		},
+		{
+			"name": "owner",
+			"type": "address",
+			"indexed": true
+		},
+		{
+			"name": "amount",
+			"type": "uint256",
+			"indexed": true
+		},
+		{
+			"name": "locked",
+			"type": "bool",
+			"indexed": true
+		}
+	]
+}`
+
+func TestDomainSchemaMigration(t *testing.T) {
+	td, done := newTestDomain(t, true, &prototk.DomainConfig{
+		AbiStateSchemasJson: []string{fakeCoinStateSchemaV2},
+		StateSchemaMigrations: []*prototk.StateSchemaMigration{
+			{FromAbiStateSchemaJson: fakeCoinStateSchema, ToSchemaIndex: 0},
+		},
+	})
+	defer done()
+	require.Nil(t, td.d.initError.Load())
+	require.Len(t, td.tp.stateSchemas, 1)
+
+	// Both versions are known to the domain, but only the new version is passed to the domain on init
+	require.Len(t, td.d.schemasByID, 2)
+	newSchema := td.d.schemasByID[td.tp.stateSchemas[0].Id]
+	require.Nil(t, newSchema.supersededBy)
+	var oldSchema *domainSchema
+	for _, s := range td.d.schemasByID {
+		if s != newSchema {
+			oldSchema = s
+		}
+	}
+	assert.Same(t, newSchema, oldSchema.supersededBy)
+	assert.Same(t, oldSchema, td.d.schemasBySignature[oldSchema.Signature()])
+
+	td.tp.Functions.MigrateState = func(ctx context.Context, req *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+		assert.Equal(t, oldSchema.ID().String(), req.FromSchemaId)
+		assert.Equal(t, newSchema.ID().String(), req.ToSchemaId)
+		assert.Equal(t, td.contractAddress.String(), req.ContractAddress)
+		var coin map[string]any
+		err := json.Unmarshal([]byte(req.StateDataJson), &coin)
+		require.NoError(t, err)
+		coin["locked"] = true
+		return &prototk.MigrateStateResponse{
+			StateDataJson: tktypes.JSONString(coin).String(),
+		}, nil
+	}
+
+	states, err := td.dm.stateStore.WritePreVerifiedStates(td.ctx, td.dm.persistence.DB(), "test1", []*components.StateUpsertOutsideContext{
+		{
+			SchemaID:        oldSchema.ID(),
+			ContractAddress: td.contractAddress,
+			Data: tktypes.JSONString(&fakeState{
+				Salt:   tktypes.Bytes32(tktypes.RandBytes(32)),
+				Owner:  *tktypes.RandAddress(),
+				Amount: ethtypes.NewHexInteger64(100),
+			}),
+		},
+	})
+	require.NoError(t, err)
+
+	// The old state is migrated by the domain when it is read
+	state, err := td.dm.stateStore.GetState(td.ctx, td.dm.persistence.DB(), "test1", td.contractAddress, states[0].ID, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, newSchema.ID(), state.Schema)
+	var coin map[string]any
+	err = json.Unmarshal(state.Data, &coin)
+	require.NoError(t, err)
+	assert.Equal(t, true, coin["locked"])
+
+	// New states cannot be written against the superseded schema
+	psc := &domainContract{dm: td.dm, d: td.d, api: td.d.api}
+	_, err = psc.upsertPotentialStates(td.c.dCtx, td.c.dbTX, &components.PrivateTransaction{}, []*prototk.NewState{
+		{SchemaId: oldSchema.ID().String()},
+	}, true)
+	assert.Regexp(t, "PD011669", err)
+}
+
+func TestDomainSchemaMigrationBadIndex(t *testing.T) {
+	td, done := newTestDomain(t, false, &prototk.DomainConfig{
+		AbiStateSchemasJson: []string{},
+		StateSchemaMigrations: []*prototk.StateSchemaMigration{
+			{FromAbiStateSchemaJson: fakeCoinStateSchema, ToSchemaIndex: 0},
+		},
+	})
+	defer done()
+	assert.Regexp(t, "PD011668", *td.d.initError.Load())
+	assert.False(t, td.tp.initialized.Load())
+}
+
+func TestDomainSchemaMigrationBadSchema(t *testing.T) {
+	schema := componentmocks.NewSchema(t)
+	schema.On("ID").Return(tktypes.Bytes32(tktypes.RandBytes(32)))
+	schema.On("Signature").Return("type=FakeCoin(bytes32 salt,address owner,uint256 amount,bool locked),labels=[owner,amount,locked]")
+	td, done := newTestDomain(t, false, &prototk.DomainConfig{
+		AbiStateSchemasJson: []string{fakeCoinStateSchemaV2},
+		StateSchemaMigrations: []*prototk.StateSchemaMigration{
+			{FromAbiStateSchemaJson: `!!! Wrong`, ToSchemaIndex: 0},
+		},
+	}, mockSchemas(schema))
+	defer done()
+	assert.Regexp(t, "PD011668", *td.d.initError.Load())
+	assert.False(t, td.tp.initialized.Load())
+}
+
+func TestDomainSchemaMigrationFromCurrentSchema(t *testing.T) {
+	schema := componentmocks.NewSchema(t)
+	schema.On("ID").Return(tktypes.Bytes32(tktypes.RandBytes(32)))
+	schema.On("Signature").Return("type=FakeCoin(bytes32 salt,address owner,uint256 amount,bool locked),labels=[owner,amount,locked]")
+	td, done := newTestDomain(t, false, &prototk.DomainConfig{
+		AbiStateSchemasJson: []string{fakeCoinStateSchemaV2},
+		StateSchemaMigrations: []*prototk.StateSchemaMigration{
+			{FromAbiStateSchemaJson: fakeCoinStateSchemaV2, ToSchemaIndex: 0},
+		},
+	}, mockSchemas(schema))
+	defer done()
+	assert.Regexp(t, "PD011668", *td.d.initError.Load())
+	assert.False(t, td.tp.initialized.Load())
+}
+
 func TestDomainInitBadSchemas(t *testing.T) {
 	td, done := newTestDomain(t, false, &prototk.DomainConfig{
 		AbiStateSchemasJson: []string{
@@ -103,6 +103,14 @@ func (dm *domainManager) registrationIndexer(ctx context.Context, dbTX *gorm.DB,
 		}
 	}
 
+	// Registration events can be delivered more than once (such as after a re-org). The registration
+	// of a contract is immutable, so we discard any we have already indexed to avoid firing duplicate
+	// notifications for the deployment transaction.
+	contracts, txCompletions, err := dm.filterNewRegistrations(ctx, dbTX, contracts, txCompletions)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Insert the batch of new contracts in this DB transaction (we do this before we call the domain to process the events)
 	if len(contracts) > 0 {
 		err := dbTX.
@@ -122,6 +130,44 @@ func (dm *domainManager) registrationIndexer(ctx context.Context, dbTX *gorm.DB,
 	return nonRegisterEvents, txCompletions, nil
 }
 
+func (dm *domainManager) filterNewRegistrations(ctx context.Context, dbTX *gorm.DB, contracts []*PrivateSmartContract, txCompletions txCompletionsOrdered) ([]*PrivateSmartContract, txCompletionsOrdered, error) {
+	if len(contracts) == 0 {
+		return contracts, txCompletions, nil
+	}
+
+	addresses := make([]tktypes.EthAddress, len(contracts))
+	for i, c := range contracts {
+		addresses[i] = c.Address
+	}
+	var existing []*PrivateSmartContract
+	err := dbTX.
+		Table("private_smart_contracts").
+		WithContext(ctx).
+		Where("address IN ?", addresses).
+		Find(&existing).
+		Error
+	if err != nil {
+		return nil, nil, err
+	}
+	skip := make(map[tktypes.EthAddress]bool, len(existing))
+	for _, c := range existing {
+		skip[c.Address] = true
+	}
+
+	newContracts := make([]*PrivateSmartContract, 0, len(contracts))
+	newTxCompletions := make(txCompletionsOrdered, 0, len(txCompletions))
+	for i, c := range contracts {
+		if skip[c.Address] {
+			log.L(ctx).Infof("Ignoring duplicate registration of smart contract %s (deployTX=%s)", c.Address, c.DeployTX)
+			continue
+		}
+		skip[c.Address] = true // only the first registration in a batch counts
+		newContracts = append(newContracts, c)
+		newTxCompletions = append(newTxCompletions, txCompletions[i])
+	}
+	return newContracts, newTxCompletions, nil
+}
+
 func (dm *domainManager) notifyTransactions(txCompletions txCompletionsOrdered) {
 	for _, completion := range txCompletions {
 		// Private transaction manager needs to know about these to update its in-memory state
@@ -196,6 +242,7 @@ func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blo
 	if err != nil {
 		return nil, err
 	}
+	var confirmedNotifications []*prototk.TransactionConfirmedRequest
 	for addr, batch := range batchesByAddress {
 		res, err := d.handleEventBatchForContract(ctx, dbTX, addr, batch)
 		if err != nil {
@@ -228,6 +275,14 @@ func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blo
 				},
 			}
 			txCompletions = append(txCompletions, completion)
+
+			if d.config.NotifyTransactionConfirmed {
+				notification, err := d.buildTransactionConfirmed(ctx, dbTX, *txID, batch.ContractInfo, txCompletionEvent)
+				if err != nil {
+					return nil, err
+				}
+				confirmedNotifications = append(confirmedNotifications, notification)
+			}
 		}
 	}
 
@@ -255,11 +310,54 @@ func (d *domain) handleEventBatch(ctx context.Context, dbTX *gorm.DB, batch *blo
 		}
 	}
 
+	// Any states held back until confirmation are released in the same DB transaction
+	var privateConfirmsCommitted func()
+	if len(txCompletions) > 0 {
+		txIDs := make([]uuid.UUID, len(txCompletions))
+		for i, txc := range txCompletions {
+			txIDs[i] = txc.TransactionID
+		}
+		if privateConfirmsCommitted, err = d.dm.privateTxManager.NotifyConfirmedPrivateTx(ctx, dbTX, txIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	return func() {
+		if privateConfirmsCommitted != nil {
+			privateConfirmsCommitted()
+		}
 		d.dm.notifyTransactions(txCompletions)
+		d.notifyTransactionsConfirmed(confirmedNotifications)
 	}, nil
 }
 
+// The states are read inside the DB transaction that records the confirmation, so the notification
+// includes the states written by the domain in this same event batch.
+func (d *domain) buildTransactionConfirmed(ctx context.Context, dbTX *gorm.DB, txID uuid.UUID, contractInfo *prototk.ContractInfo, txCompletionEvent *prototk.CompletedTransaction) (*prototk.TransactionConfirmedRequest, error) {
+	txStates, err := d.dm.stateStore.GetTransactionStates(ctx, dbTX, txID)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.TransactionConfirmedRequest{
+		TransactionId: txCompletionEvent.TransactionId,
+		ContractInfo:  contractInfo,
+		Location:      txCompletionEvent.Location,
+		InputStates:   d.toEndorsableListBase(txStates.Spent),
+		ReadStates:    d.toEndorsableListBase(txStates.Read),
+		OutputStates:  d.toEndorsableListBase(txStates.Confirmed),
+		InfoStates:    d.toEndorsableListBase(txStates.Info),
+	}, nil
+}
+
+func (d *domain) notifyTransactionsConfirmed(notifications []*prototk.TransactionConfirmedRequest) {
+	for _, notification := range notifications {
+		// The confirmation is already committed, so a failure in the domain cannot affect it
+		if _, err := d.api.TransactionConfirmed(d.ctx, notification); err != nil {
+			log.L(d.ctx).Errorf("Domain %s failed to process confirmation of transaction %s: %s", d.name, notification.TransactionId, err)
+		}
+	}
+}
+
 func (d *domain) recoverTransactionID(ctx context.Context, txIDString string) (*uuid.UUID, error) {
 	txIDBytes, err := tktypes.ParseBytes32Ctx(ctx, txIDString)
 	if err != nil {
@@ -122,6 +122,91 @@ func TestEventIndexingWithDB(t *testing.T) {
 	assert.Equal(t, psc, psc2)
 }
 
+func TestEventIndexingDuplicateRegistration(t *testing.T) {
+
+	td, done := newTestDomain(t, true /* real DB */, goodDomainConf())
+	defer done()
+	ctx := td.ctx
+	dm := td.dm
+
+	deployTX := uuid.New()
+	contractAddr := tktypes.EthAddress(tktypes.RandBytes(20))
+	registrationEvent := &pldapi.EventWithData{
+		SoliditySignature: eventSolSig_PaladinRegisterSmartContract_V0,
+		Address:           (tktypes.EthAddress)(*td.tp.d.RegistryAddress()),
+		IndexedEvent: &pldapi.IndexedEvent{
+			BlockNumber:      12345,
+			TransactionIndex: 0,
+			LogIndex:         0,
+			TransactionHash:  tktypes.NewBytes32FromSlice(tktypes.RandBytes(32)),
+			Signature:        eventSig_PaladinRegisterSmartContract_V0,
+		},
+		Data: tktypes.RawJSON(`{
+			"txId": "` + tktypes.Bytes32UUIDFirst16(deployTX).String() + `",
+			"instance": "` + contractAddr.String() + `",
+			"config": "0xfeedbeef"
+		}`),
+	}
+	indexEvents := func(events ...*pldapi.EventWithData) (batchTxs txCompletionsOrdered) {
+		err := dm.persistence.DB().Transaction(func(tx *gorm.DB) (err error) {
+			_, batchTxs, err = dm.registrationIndexer(ctx, tx, &blockindexer.EventDeliveryBatch{
+				StreamID:   uuid.New(),
+				StreamName: "name_given_by_component_mgr",
+				BatchID:    uuid.New(),
+				Events:     events,
+			})
+			return err
+		})
+		require.NoError(t, err)
+		return batchTxs
+	}
+
+	// The same event twice in one batch only results in one notification
+	batchTxs := indexEvents(registrationEvent, registrationEvent)
+	require.Len(t, batchTxs, 1)
+	assert.Equal(t, deployTX, batchTxs[0].TransactionID)
+
+	// Redelivery in a later batch (such as after a re-org) results in no notification
+	batchTxs = indexEvents(registrationEvent)
+	assert.Empty(t, batchTxs)
+
+	// And there is still only the one contract instance
+	var contracts []*PrivateSmartContract
+	err := dm.persistence.DB().Table("private_smart_contracts").Where("address = ?", contractAddr).Find(&contracts).Error
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	assert.Equal(t, deployTX, contracts[0].DeployTX)
+
+}
+
+func TestEventIndexingDuplicateCheckError(t *testing.T) {
+
+	td, done := newTestDomain(t, false, goodDomainConf(), func(mc *mockComponents) {
+		mc.stateStore.On("EnsureABISchemas", mock.Anything, mock.Anything, "test1", mock.Anything).Return(nil, nil)
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*private_smart_contracts").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	err := td.dm.persistence.DB().Transaction(func(tx *gorm.DB) error {
+		_, _, err := td.dm.registrationIndexer(td.ctx, tx, &blockindexer.EventDeliveryBatch{
+			BatchID: uuid.New(),
+			Events: []*pldapi.EventWithData{
+				{
+					SoliditySignature: eventSolSig_PaladinRegisterSmartContract_V0,
+					Address:           *td.tp.d.RegistryAddress(),
+					IndexedEvent:      &pldapi.IndexedEvent{},
+					Data:              tktypes.RawJSON(`{}`),
+				},
+			},
+		})
+		return err
+	})
+	assert.Regexp(t, "pop", err)
+
+}
+
 func TestEventIndexingBadEvent(t *testing.T) {
 
 	td, done := newTestDomain(t, false, goodDomainConf(), func(mc *mockComponents) {
@@ -157,6 +242,7 @@ func TestEventIndexingInsertError(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), func(mc *mockComponents) {
 		mc.stateStore.On("EnsureABISchemas", mock.Anything, mock.Anything, "test1", mock.Anything).Return(nil, nil)
 		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*private_smart_contracts").WillReturnRows(sqlmock.NewRows([]string{}))
 		mc.db.ExpectExec("INSERT").WillReturnError(fmt.Errorf("pop"))
 		mc.db.ExpectRollback()
 	})
@@ -231,6 +317,7 @@ func TestHandleEventBatch(t *testing.T) {
 		Data:              tktypes.RawJSON(`{"result": "success"}`),
 	}
 
+	var heldReleased bool
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
 
 		mc.stateStore.On("WriteStateFinalizations", mock.Anything, mock.Anything, []*pldapi.StateSpendRecord{
@@ -267,6 +354,9 @@ func TestHandleEventBatch(t *testing.T) {
 			return true
 		})).Return(nil)
 
+		mc.privateTxManager.On("NotifyConfirmedPrivateTx", mock.Anything, mock.Anything, []uuid.UUID{txID}).Return(func() {
+			heldReleased = true
+		}, nil)
 		mc.privateTxManager.On("PrivateTransactionConfirmed", mock.Anything, mock.Anything).Return()
 	})
 	defer done()
@@ -339,16 +429,174 @@ func TestHandleEventBatch(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
+	// held states are only released once the DB transaction commits
+	assert.False(t, heldReleased)
 	req := d.dm.privateTxWaiter.AddInflight(ctx, txID)
 	cb()
 	_, err = req.Wait()
 	assert.NoError(t, err)
+	assert.True(t, heldReleased)
+}
+
+func TestHandleEventBatchNotifyTransactionConfirmed(t *testing.T) {
+	batchID := uuid.New()
+	txID := uuid.New()
+	txIDBytes32 := tktypes.Bytes32UUIDFirst16(txID)
+	contract1 := tktypes.RandAddress()
+	stateSpent := tktypes.HexBytes(tktypes.RandBytes(32))
+	stateConfirmed := tktypes.HexBytes(tktypes.RandBytes(32))
+	fakeSchema := tktypes.Bytes32(tktypes.RandBytes(32))
+	event1 := &pldapi.EventWithData{
+		Address: *contract1,
+		IndexedEvent: &pldapi.IndexedEvent{
+			BlockNumber:      1000,
+			TransactionIndex: 20,
+			LogIndex:         30,
+			TransactionHash:  tktypes.MustParseBytes32(tktypes.RandHex(32)),
+			Signature:        tktypes.MustParseBytes32(tktypes.RandHex(32)),
+		},
+		SoliditySignature: "some event signature 1",
+		Data:              tktypes.RawJSON(`{"result": "success"}`),
+	}
+
+	domainConf := goodDomainConf()
+	domainConf.NotifyTransactionConfirmed = true
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), func(mc *mockComponents) {
+		mc.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mc.stateStore.On("GetTransactionStates", mock.Anything, mock.Anything, txID).Return(&pldapi.TransactionStates{
+			Spent:     []*pldapi.StateBase{{ID: stateSpent, Schema: fakeSchema, Data: tktypes.RawJSON(`{"color":"red"}`)}},
+			Confirmed: []*pldapi.StateBase{{ID: stateConfirmed, Schema: fakeSchema, Data: tktypes.RawJSON(`{"color":"blue"}`)}},
+		}, nil)
+		mc.privateTxManager.On("NotifyConfirmedPrivateTx", mock.Anything, mock.Anything, []uuid.UUID{txID}).Return(func() {}, nil)
+		mc.privateTxManager.On("PrivateTransactionConfirmed", mock.Anything, mock.Anything).Return()
+	})
+	defer done()
+	d := td.d
+	ctx := td.ctx
+
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	mp.Mock.ExpectQuery("SELECT.*private_smart_contracts").WillReturnRows(sqlmock.NewRows(
+		[]string{"address", "domain_address"},
+	).AddRow(contract1, d.registryAddress))
+
+	td.tp.Functions.HandleEventBatch = func(ctx context.Context, req *prototk.HandleEventBatchRequest) (*prototk.HandleEventBatchResponse, error) {
+		return &prototk.HandleEventBatchResponse{
+			TransactionsComplete: []*prototk.CompletedTransaction{
+				{
+					TransactionId: txIDBytes32.String(),
+					Location:      req.Events[0].Location,
+				},
+			},
+		}, nil
+	}
+	td.tp.Functions.InitContract = func(ctx context.Context, icr *prototk.InitContractRequest) (*prototk.InitContractResponse, error) {
+		return &prototk.InitContractResponse{Valid: true, ContractConfig: &prototk.ContractConfig{}}, nil
+	}
+	confirmed := make(chan *prototk.TransactionConfirmedRequest, 1)
+	td.tp.Functions.TransactionConfirmed = func(ctx context.Context, req *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+		confirmed <- req
+		return &prototk.TransactionConfirmedResponse{}, nil
+	}
+
+	cb, err := d.handleEventBatch(ctx, mp.P.DB(), &blockindexer.EventDeliveryBatch{
+		BatchID: batchID,
+		Events:  []*pldapi.EventWithData{event1},
+	})
+	require.NoError(t, err)
+
+	// Nothing is sent to the domain until after the DB transaction commits
+	assert.Empty(t, confirmed)
+	cb()
+
+	req := <-confirmed
+	assert.Equal(t, txIDBytes32.String(), req.TransactionId)
+	assert.Equal(t, contract1.String(), req.ContractInfo.ContractAddress)
+	assert.Equal(t, event1.TransactionHash.String(), req.Location.TransactionHash)
+	assert.Equal(t, event1.BlockNumber, req.Location.BlockNumber)
+	assert.Equal(t, event1.LogIndex, req.Location.LogIndex)
+	require.Len(t, req.InputStates, 1)
+	assert.Equal(t, stateSpent.String(), req.InputStates[0].Id)
+	assert.Equal(t, `{"color":"red"}`, req.InputStates[0].StateDataJson)
+	require.Len(t, req.OutputStates, 1)
+	assert.Equal(t, stateConfirmed.String(), req.OutputStates[0].Id)
+	assert.Equal(t, fakeSchema.String(), req.OutputStates[0].SchemaId)
+	assert.Empty(t, req.ReadStates)
+	assert.Empty(t, req.InfoStates)
+}
+
+func TestHandleEventBatchNotifyTransactionConfirmedStatesFail(t *testing.T) {
+	txID := uuid.New()
+	contract1 := tktypes.RandAddress()
+
+	domainConf := goodDomainConf()
+	domainConf.NotifyTransactionConfirmed = true
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), func(mc *mockComponents) {
+		mc.stateStore.On("GetTransactionStates", mock.Anything, mock.Anything, txID).Return(nil, fmt.Errorf("pop"))
+	})
+	defer done()
+
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	mp.Mock.ExpectQuery("SELECT.*private_smart_contracts").WillReturnRows(sqlmock.NewRows(
+		[]string{"address", "domain_address"},
+	).AddRow(contract1, td.d.registryAddress))
+
+	td.tp.Functions.HandleEventBatch = func(ctx context.Context, req *prototk.HandleEventBatchRequest) (*prototk.HandleEventBatchResponse, error) {
+		return &prototk.HandleEventBatchResponse{
+			TransactionsComplete: []*prototk.CompletedTransaction{
+				{
+					TransactionId: tktypes.Bytes32UUIDFirst16(txID).String(),
+					Location:      req.Events[0].Location,
+				},
+			},
+		}, nil
+	}
+	td.tp.Functions.InitContract = func(ctx context.Context, icr *prototk.InitContractRequest) (*prototk.InitContractResponse, error) {
+		return &prototk.InitContractResponse{Valid: true, ContractConfig: &prototk.ContractConfig{}}, nil
+	}
+
+	_, err = td.d.handleEventBatch(td.ctx, mp.P.DB(), &blockindexer.EventDeliveryBatch{
+		BatchID: uuid.New(),
+		Events: []*pldapi.EventWithData{
+			{
+				Address: *contract1,
+				IndexedEvent: &pldapi.IndexedEvent{
+					BlockNumber:     1000,
+					TransactionHash: tktypes.MustParseBytes32(tktypes.RandHex(32)),
+					Signature:       tktypes.MustParseBytes32(tktypes.RandHex(32)),
+				},
+				SoliditySignature: "some event signature 1",
+				Data:              tktypes.RawJSON(`{"result": "success"}`),
+			},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestNotifyTransactionsConfirmedDomainError(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
+	defer done()
+
+	calls := 0
+	td.tp.Functions.TransactionConfirmed = func(ctx context.Context, req *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+		calls++
+		return nil, fmt.Errorf("pop")
+	}
+
+	// Errors are logged, and do not stop the remaining notifications
+	td.d.notifyTransactionsConfirmed([]*prototk.TransactionConfirmedRequest{
+		{TransactionId: "tx1"},
+		{TransactionId: "tx2"},
+	})
+	assert.Equal(t, 2, calls)
 }
 
 func TestHandleEventBatchFinalizeFail(t *testing.T) {
 	batchID := uuid.New()
 
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), func(mc *mockComponents) {
+		mc.db.ExpectQuery(`SELECT.*private_smart_contracts`).WillReturnRows(sqlmock.NewRows([]string{}))
 		mc.db.ExpectExec(`INSERT.*private_smart_contracts`).WillReturnResult(driver.ResultNoRows)
 
 		mc.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
@@ -436,6 +684,7 @@ func TestHandleEventBatchRegistrationError(t *testing.T) {
 	mp, err := mockpersistence.NewSQLMockProvider()
 	require.NoError(t, err)
 
+	mp.Mock.ExpectQuery("SELECT.*private_smart_contracts").WillReturnRows(sqlmock.NewRows([]string{}))
 	mp.Mock.ExpectExec("INSERT.*private_smart_contracts").WillReturnError(fmt.Errorf("pop"))
 
 	registrationData := &event_PaladinRegisterSmartContract_V0{
@@ -118,6 +118,11 @@ func (dm *domainManager) PostInit(c components.AllComponents) error {
 		if _, err := tktypes.ParseEthAddress(d.RegistryAddress); err != nil {
 			return i18n.WrapError(dm.bgCtx, err, msgs.MsgDomainRegistryAddressInvalid, d.RegistryAddress, name)
 		}
+		switch pldconf.StateDistributionOrder(d.StateDistribution) {
+		case "", pldconf.StateDistributionBeforeDispatch, pldconf.StateDistributionAfterConfirm:
+		default:
+			return i18n.NewError(dm.bgCtx, msgs.MsgDomainInvalidStateDistribution, d.StateDistribution, name)
+		}
 	}
 	return nil
 }
@@ -207,6 +207,52 @@ func TestDomainMissingRegistryAddress(t *testing.T) {
 	assert.Regexp(t, "PD011606", err)
 }
 
+func TestDomainInvalidStateDistribution(t *testing.T) {
+	config := &pldconf.DomainManagerConfig{
+		Domains: map[string]*pldconf.DomainConfig{
+			"domain1": {
+				Plugin: pldconf.PluginConfig{
+					Type:    string(tktypes.LibraryTypeCShared),
+					Library: "some/where",
+				},
+				RegistryAddress:   tktypes.RandHex(20),
+				StateDistribution: "whenever",
+			},
+		},
+	}
+
+	mc := &mockComponents{
+		blockIndexer:     componentmocks.NewBlockIndexer(t),
+		stateStore:       componentmocks.NewStateManager(t),
+		ethClientFactory: ethclientmocks.NewEthClientFactory(t),
+		keyManager:       componentmocks.NewKeyManager(t),
+		txManager:        componentmocks.NewTXManager(t),
+		privateTxManager: componentmocks.NewPrivateTxManager(t),
+		transportMgr:     componentmocks.NewTransportManager(t),
+	}
+	componentMocks := componentmocks.NewAllComponents(t)
+	componentMocks.On("EthClientFactory").Return(mc.ethClientFactory)
+	mc.ethClientFactory.On("ChainID").Return(int64(12345)).Maybe()
+	mc.ethClientFactory.On("HTTPClient").Return(mc.ethClient).Maybe()
+	mc.ethClientFactory.On("WSClient").Return(mc.ethClient).Maybe()
+	componentMocks.On("BlockIndexer").Return(mc.blockIndexer)
+	mc.keyManager.On("AddInMemorySigner", "domain", mock.Anything).Return().Maybe()
+	componentMocks.On("KeyManager").Return(mc.keyManager)
+	componentMocks.On("TxManager").Return(mc.txManager)
+	componentMocks.On("PrivateTxManager").Return(mc.privateTxManager)
+	componentMocks.On("TransportManager").Return(mc.transportMgr)
+
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	componentMocks.On("StateManager").Return(mc.stateStore)
+	componentMocks.On("Persistence").Return(mp.P)
+	dm := NewDomainManager(context.Background(), config)
+	_, err = dm.PreInit(componentMocks)
+	require.NoError(t, err)
+	err = dm.PostInit(componentMocks)
+	assert.Regexp(t, "PD011671.*whenever", err)
+}
+
 func TestGetDomainNotFound(t *testing.T) {
 	ctx, dm, _, done := newTestDomainManager(t, false, &pldconf.DomainManagerConfig{
 		Domains: map[string]*pldconf.DomainConfig{
@@ -19,11 +19,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"gorm.io/gorm"
@@ -151,6 +154,7 @@ func (dc *domainContract) InitTransaction(ctx context.Context, tx *components.Pr
 	preAssembly := &components.TransactionPreAssembly{
 		TransactionSpecification: txSpec,
 		RequiredVerifiers:        res.RequiredVerifiers,
+		Label:                    res.GetLabel(),
 	}
 	tx.PreAssembly = preAssembly
 	return nil
@@ -211,6 +215,10 @@ func (dc *domainContract) AssembleTransaction(dCtx components.DomainContext, rea
 	if err != nil {
 		return err
 	}
+	if res.Label != nil {
+		// The label is retained across re-assembly, unless the domain supplies a new one
+		preAssembly.Label = *res.Label
+	}
 
 	postAssembly := &components.TransactionPostAssembly{}
 	// If the result is not OK (e.g. there is a REVERT) then we return the situation to the private TX manager to handle
@@ -239,6 +247,14 @@ func (dc *domainContract) AssembleTransaction(dCtx components.DomainContext, rea
 		postAssembly.OutputStatesPotential = res.AssembledTransaction.OutputStates
 		postAssembly.InfoStatesPotential = res.AssembledTransaction.InfoStates
 		postAssembly.ExtraData = res.AssembledTransaction.ExtraData
+		postAssembly.RequiredVerifiers = res.RequiredVerifiers
+	}
+
+	if err := dc.checkAttestationPlanLimits(dCtx.Ctx(), tx, res.AttestationPlan); err != nil {
+		return err
+	}
+	if err := checkAttestationPayloadBuilders(dCtx.Ctx(), res.AttestationPlan); err != nil {
+		return err
 	}
 
 	// We need to pass the assembly result back - it needs to be assigned to a sequence
@@ -271,6 +287,94 @@ func (dc *domainContract) WritePotentialStates(dCtx components.DomainContext, re
 
 }
 
+// Happens only on the sequencing node, after the potential states have been written and before
+// any endorsements are requested. Only called through to domains that opt-in via their config.
+func (dc *domainContract) ValidateAssembled(dCtx components.DomainContext, readTX *gorm.DB, tx *components.PrivateTransaction) error {
+	if !dc.d.config.ValidateAssembled {
+		return nil
+	}
+	if tx.Inputs == nil || tx.PreAssembly == nil || tx.PreAssembly.TransactionSpecification == nil ||
+		tx.PostAssembly == nil || tx.PostAssembly.OutputStates == nil {
+		return i18n.NewError(dCtx.Ctx(), msgs.MsgDomainTXIncompleteValidateAssembled)
+	}
+
+	preAssembly := tx.PreAssembly
+	postAssembly := tx.PostAssembly
+
+	c := dc.d.newInFlightDomainRequest(readTX, dCtx)
+	defer c.close()
+
+	log.L(dCtx.Ctx()).Infof("Validating assembled transaction=%s domain=%s contract-address=%s", tx.ID, dc.d.name, tx.Inputs.To)
+	_, err := dc.api.ValidateAssembled(dCtx.Ctx(), &prototk.ValidateAssembledRequest{
+		StateQueryContext: c.id,
+		Transaction:       preAssembly.TransactionSpecification,
+		ResolvedVerifiers: preAssembly.Verifiers,
+		Inputs:            dc.d.toEndorsableList(postAssembly.InputStates),
+		Reads:             dc.d.toEndorsableList(postAssembly.ReadStates),
+		Outputs:           dc.d.toEndorsableList(postAssembly.OutputStates),
+		Info:              dc.d.toEndorsableList(postAssembly.InfoStates),
+		AttestationPlan:   postAssembly.AttestationPlan,
+	})
+	return err
+}
+
+// Happens only on the sequencing node, after the potential states have been written and before
+// any signatures or endorsements are requested. Any attestation request in the plan that names
+// one of the payload builders registered by the domain has its payload constructed by the domain.
+func (dc *domainContract) BuildAttestationPayloads(dCtx components.DomainContext, readTX *gorm.DB, tx *components.PrivateTransaction) error {
+	if tx.PostAssembly == nil {
+		return nil
+	}
+
+	var c *inFlightDomainRequest
+	defer func() {
+		if c != nil {
+			c.close()
+		}
+	}()
+
+	postAssembly := tx.PostAssembly
+	for _, ar := range postAssembly.AttestationPlan {
+		if ar.PayloadBuilder == nil {
+			continue
+		}
+		builder := *ar.PayloadBuilder
+		if ar.AttestationType != prototk.AttestationType_ENDORSE {
+			return i18n.NewError(dCtx.Ctx(), msgs.MsgDomainPayloadBuilderNotEndorse, ar.Name, ar.AttestationType, builder)
+		}
+		if !slices.Contains(dc.d.config.PayloadBuilders, builder) {
+			return i18n.NewError(dCtx.Ctx(), msgs.MsgDomainPayloadBuilderNotRegistered, builder, dc.d.name)
+		}
+		if len(ar.Payload) > 0 {
+			return i18n.NewError(dCtx.Ctx(), msgs.MsgDomainPayloadBuilderWithPayload, ar.Name, builder)
+		}
+		if tx.PreAssembly == nil || tx.PreAssembly.TransactionSpecification == nil || postAssembly.OutputStates == nil {
+			return i18n.NewError(dCtx.Ctx(), msgs.MsgDomainTXIncompleteBuildPayloads)
+		}
+		if c == nil {
+			c = dc.d.newInFlightDomainRequest(readTX, dCtx)
+		}
+
+		log.L(dCtx.Ctx()).Infof("Building attestation payload transaction=%s domain=%s attestation=%s builder=%s", tx.ID, dc.d.name, ar.Name, builder)
+		res, err := dc.api.BuildAttestationPayload(dCtx.Ctx(), &prototk.BuildAttestationPayloadRequest{
+			StateQueryContext:  c.id,
+			PayloadBuilder:     builder,
+			AttestationRequest: ar,
+			Transaction:        tx.PreAssembly.TransactionSpecification,
+			ResolvedVerifiers:  tx.PreAssembly.Verifiers,
+			Inputs:             dc.d.toEndorsableList(postAssembly.InputStates),
+			Reads:              dc.d.toEndorsableList(postAssembly.ReadStates),
+			Outputs:            dc.d.toEndorsableList(postAssembly.OutputStates),
+			Info:               dc.d.toEndorsableList(postAssembly.InfoStates),
+		})
+		if err != nil {
+			return err
+		}
+		ar.Payload = res.Payload
+	}
+	return nil
+}
+
 func (dc *domainContract) upsertPotentialStates(dCtx components.DomainContext, readTX *gorm.DB, tx *components.PrivateTransaction, potentialStates []*prototk.NewState, isOutput bool) (writtenStates []*components.FullState, err error) {
 	newStatesToWrite := make([]*components.StateUpsert, len(potentialStates))
 	domain := dc.d
@@ -282,6 +386,10 @@ func (dc *domainContract) upsertPotentialStates(dCtx components.DomainContext, r
 		if schema == nil {
 			return nil, i18n.NewError(dCtx.Ctx(), msgs.MsgDomainUnknownSchema, s.SchemaId)
 		}
+		if schema.supersededBy != nil {
+			// New states must always be written against the current version of a schema
+			return nil, i18n.NewError(dCtx.Ctx(), msgs.MsgDomainSchemaSuperseded, schema.ID(), schema.supersededBy.ID())
+		}
 		var id tktypes.HexBytes
 		if s.Id != nil {
 			id, err = tktypes.ParseHexBytes(dCtx.Ctx(), *s.Id)
@@ -446,6 +554,7 @@ func (dc *domainContract) EndorseTransaction(dCtx components.DomainContext, read
 		Outputs:             req.OutputStates,
 		Info:                req.InfoStates,
 		Signatures:          req.Signatures,
+		Endorsements:        req.Endorsements,
 		EndorsementRequest:  req.Endorsement,
 		EndorsementVerifier: req.Endorser,
 	})
@@ -551,6 +660,10 @@ func (dc *domainContract) InitCall(ctx context.Context, txi *components.Transact
 		return nil, err
 	}
 
+	if err := dc.checkCallAllowed(ctx, txi.Function); err != nil {
+		return nil, err
+	}
+
 	// Call the domain
 	res, err := dc.api.InitCall(ctx, &prototk.InitCallRequest{
 		Transaction: txSpec,
@@ -559,10 +672,72 @@ func (dc *domainContract) InitCall(ctx context.Context, txi *components.Transact
 		return nil, err
 	}
 
+	if !isReadOnlyCall(txi.Function, res.ReadOnly) {
+		return nil, i18n.NewError(ctx, msgs.MsgDomainCallFunctionNotReadOnly, txi.Function.String(), dc.d.name)
+	}
+
 	return res.RequiredVerifiers, nil
 
 }
 
+func (dc *domainContract) checkAttestationPlanLimits(ctx context.Context, tx *components.PrivateTransaction, attestationPlan []*prototk.AttestationRequest) error {
+	limits := &dc.d.conf.AttestationPlan
+	maxRequests := confutil.Int(limits.MaxRequests, *pldconf.AttestationPlanLimitsDefaults.MaxRequests)
+	maxParties := confutil.Int(limits.MaxParties, *pldconf.AttestationPlanLimitsDefaults.MaxParties)
+	if len(attestationPlan) > maxRequests {
+		return i18n.NewError(ctx, msgs.MsgDomainAttestationPlanTooLarge, tx.ID, dc.d.name, "requests", len(attestationPlan), maxRequests)
+	}
+	parties := 0
+	for _, ar := range attestationPlan {
+		parties += len(ar.Parties)
+	}
+	if parties > maxParties {
+		return i18n.NewError(ctx, msgs.MsgDomainAttestationPlanTooLarge, tx.ID, dc.d.name, "parties", parties, maxParties)
+	}
+	return nil
+}
+
+// Payloads are built once the transaction is ready to be endorsed, which is after signatures have been
+// gathered on the assembling node, so only endorsements can have their payloads built by the domain
+func checkAttestationPayloadBuilders(ctx context.Context, attestationPlan []*prototk.AttestationRequest) error {
+	for _, ar := range attestationPlan {
+		if ar.PayloadBuilder != nil && ar.AttestationType != prototk.AttestationType_ENDORSE {
+			return i18n.NewError(ctx, msgs.MsgDomainPayloadBuilderNotEndorse, ar.Name, ar.AttestationType, *ar.PayloadBuilder)
+		}
+	}
+	return nil
+}
+
+// The node administrator can restrict which functions are callable via ptx_call,
+// matching either the function name or the full signature
+func (dc *domainContract) checkCallAllowed(ctx context.Context, fn *abi.Entry) error {
+	allowList := dc.d.conf.AllowedCallFunctions
+	if allowList == nil {
+		return nil
+	}
+	signature := fn.String()
+	for _, allowed := range allowList {
+		if allowed == fn.Name || allowed == signature {
+			return nil
+		}
+	}
+	return i18n.NewError(ctx, msgs.MsgDomainCallFunctionNotAllowed, signature, dc.d.name)
+}
+
+// Calls must not be used to invoke state-changing functions, which must be submitted as transactions.
+// The domain's declaration takes precedence, falling back to the stateMutability in the ABI.
+func isReadOnlyCall(fn *abi.Entry, domainReadOnly *bool) bool {
+	if domainReadOnly != nil {
+		return *domainReadOnly
+	}
+	switch fn.StateMutability {
+	case abi.Payable, abi.NonPayable:
+		return false
+	default:
+		return true
+	}
+}
+
 func (dc *domainContract) ExecCall(dCtx components.DomainContext, readTX *gorm.DB, txi *components.TransactionInputs, verifiers []*prototk.ResolvedVerifier) (*abi.ComponentValue, error) {
 
 	txSpec, err := dc.processTxInputs(dCtx.Ctx(), txi)
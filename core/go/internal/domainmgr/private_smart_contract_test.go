@@ -214,6 +214,33 @@ func TestDomainInitTransactionOK(t *testing.T) {
 	_, _ = doDomainInitTransactionOK(t, td)
 }
 
+func TestDomainTransactionLabel(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitTransactionOK(t, td, func(res *prototk.InitTransactionResponse) {
+		res.Label = confutil.P("Transfer FT1 to org2")
+	})
+	assert.Equal(t, "Transfer FT1 to org2", tx.PreAssembly.Label)
+
+	// The label is retained on assembly unless the domain supplies a new one
+	label := (*string)(nil)
+	td.tp.Functions.AssembleTransaction = func(ctx context.Context, atr *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+		return &prototk.AssembleTransactionResponse{
+			AssemblyResult: prototk.AssembleTransactionResponse_PARK,
+			Label:          label,
+		}, nil
+	}
+	err := psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	require.NoError(t, err)
+	assert.Equal(t, "Transfer FT1 to org2", tx.PreAssembly.Label)
+
+	label = confutil.P("Transfer 23 FT1 to org2")
+	err = psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	require.NoError(t, err)
+	assert.Equal(t, "Transfer 23 FT1 to org2", tx.PreAssembly.Label)
+}
+
 func TestEncodeDecodeABIData(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas())
 	defer done()
@@ -716,6 +743,7 @@ func TestFullTransactionRealDBOK(t *testing.T) {
 		assert.Same(t, tx.PostAssembly.AttestationPlan[0], req.EndorsementRequest)
 		assert.Equal(t, "endorser1", req.EndorsementVerifier.Lookup)
 		assert.Same(t, tx.PreAssembly.TransactionSpecification, req.Transaction)
+		assert.Equal(t, tx.PostAssembly.Endorsements, req.Endorsements)
 		assert.Len(t, tx.PostAssembly.InputStates, 2)
 		assert.Contains(t, string(tx.PostAssembly.InputStates[0].Data), state1.Salt.String())
 		assert.Contains(t, string(tx.PostAssembly.InputStates[1].Data), state3.Salt.String())
@@ -742,6 +770,7 @@ func TestFullTransactionRealDBOK(t *testing.T) {
 		TransactionSpecification: tx.PreAssembly.TransactionSpecification,
 		Verifiers:                tx.PreAssembly.Verifiers,
 		Signatures:               tx.PostAssembly.Signatures,
+		Endorsements:             tx.PostAssembly.Endorsements,
 		InputStates:              psc.d.toEndorsableList(tx.PostAssembly.InputStates),
 		ReadStates:               psc.d.toEndorsableList(tx.PostAssembly.ReadStates),
 		OutputStates:             psc.d.toEndorsableList(tx.PostAssembly.OutputStates),
@@ -837,6 +866,72 @@ func TestDomainAssembleTransactionError(t *testing.T) {
 	assert.Nil(t, tx.PostAssembly)
 }
 
+func TestDomainAssembleTransactionAttestationPlanTooLarge(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitTransactionOK(t, td)
+	attestationPlan := make([]*prototk.AttestationRequest, 3)
+	for i := range attestationPlan {
+		attestationPlan[i] = &prototk.AttestationRequest{
+			Name:            fmt.Sprintf("endorse%d", i),
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Algorithm:       algorithms.ECDSA_SECP256K1,
+			VerifierType:    verifiers.ETH_ADDRESS,
+			Parties:         []string{"party1@node1", "party2@node2"},
+		}
+	}
+	td.tp.Functions.AssembleTransaction = func(ctx context.Context, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+		return &prototk.AssembleTransactionResponse{
+			AssemblyResult:       prototk.AssembleTransactionResponse_OK,
+			AssembledTransaction: &prototk.AssembledTransaction{},
+			AttestationPlan:      attestationPlan,
+		}, nil
+	}
+
+	td.d.conf.AttestationPlan.MaxRequests = confutil.P(2)
+	err := psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011670.*requests=3 limit=2", err)
+	assert.Nil(t, tx.PostAssembly)
+
+	td.d.conf.AttestationPlan.MaxRequests = nil
+	td.d.conf.AttestationPlan.MaxParties = confutil.P(5)
+	err = psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011670.*parties=6 limit=5", err)
+	assert.Nil(t, tx.PostAssembly)
+
+	td.d.conf.AttestationPlan.MaxParties = confutil.P(6)
+	err = psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	require.NoError(t, err)
+	assert.Len(t, tx.PostAssembly.AttestationPlan, 3)
+}
+
+func TestDomainAssembleTransactionPayloadBuilderNotEndorse(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitTransactionOK(t, td)
+	td.tp.Functions.AssembleTransaction = func(ctx context.Context, req *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error) {
+		return &prototk.AssembleTransactionResponse{
+			AssemblyResult:       prototk.AssembleTransactionResponse_OK,
+			AssembledTransaction: &prototk.AssembledTransaction{},
+			AttestationPlan: []*prototk.AttestationRequest{{
+				Name:            "sign1",
+				AttestationType: prototk.AttestationType_SIGN,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				Parties:         []string{"party1@node1"},
+				PayloadBuilder:  confutil.P("builder1"),
+			}},
+		}, nil
+	}
+
+	// Signatures are gathered before payloads are built, so would be over an empty payload
+	err := psc.AssembleTransaction(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011672.*sign1", err)
+	assert.Nil(t, tx.PostAssembly)
+}
+
 func TestDomainAssembleTransactionLoadInputError(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
 	defer done()
@@ -942,6 +1037,157 @@ func TestDomainWritePotentialStatesBadID(t *testing.T) {
 	assert.Regexp(t, "PD020007", err)
 }
 
+func TestValidateAssembledNotEnabled(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+
+	// Not called through to the domain, as it has not opted in
+	err := psc.ValidateAssembled(td.mdc, td.c.dbTX, tx)
+	assert.NoError(t, err)
+}
+
+func TestValidateAssembledOK(t *testing.T) {
+	domainConf := goodDomainConf()
+	domainConf.ValidateAssembled = true
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+	tx.PostAssembly.OutputStates = []*components.FullState{
+		{ID: tktypes.RandBytes(32), Schema: tktypes.Bytes32(tktypes.RandBytes(32)), Data: tktypes.RawJSON(`{"some":"data"}`)},
+	}
+
+	td.tp.Functions.ValidateAssembled = func(ctx context.Context, vr *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+		assert.Len(t, vr.Outputs, 1)
+		assert.JSONEq(t, `{"some":"data"}`, vr.Outputs[0].StateDataJson)
+		assert.Equal(t, "ensorsement1", vr.AttestationPlan[0].Name)
+		return &prototk.ValidateAssembledResponse{}, nil
+	}
+
+	err := psc.ValidateAssembled(td.mdc, td.c.dbTX, tx)
+	assert.NoError(t, err)
+}
+
+func TestValidateAssembledFail(t *testing.T) {
+	domainConf := goodDomainConf()
+	domainConf.ValidateAssembled = true
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+
+	err := psc.ValidateAssembled(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011662", err)
+
+	tx.PostAssembly.OutputStates = []*components.FullState{}
+	td.tp.Functions.ValidateAssembled = func(ctx context.Context, vr *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	err = psc.ValidateAssembled(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestBuildAttestationPayloadsEndorsed(t *testing.T) {
+	domainConf := goodDomainConf()
+	domainConf.PayloadBuilders = []string{"builder1"}
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+	tx.PostAssembly.OutputStates = []*components.FullState{
+		{ID: tktypes.RandBytes(32), Schema: tktypes.Bytes32(tktypes.RandBytes(32)), Data: tktypes.RawJSON(`{"some":"data"}`)},
+	}
+	tx.PostAssembly.AttestationPlan[0].PayloadBuilder = confutil.P("builder1")
+
+	td.tp.Functions.BuildAttestationPayload = func(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+		assert.Equal(t, "builder1", req.PayloadBuilder)
+		assert.Equal(t, "ensorsement1", req.AttestationRequest.Name)
+		assert.Same(t, tx.PreAssembly.TransactionSpecification, req.Transaction)
+		assert.Len(t, req.Outputs, 1)
+		assert.JSONEq(t, `{"some":"data"}`, req.Outputs[0].StateDataJson)
+		return &prototk.BuildAttestationPayloadResponse{
+			Payload: []byte("built payload"),
+		}, nil
+	}
+
+	err := psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("built payload"), tx.PostAssembly.AttestationPlan[0].Payload)
+
+	td.tp.Functions.EndorseTransaction = func(ctx context.Context, req *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error) {
+		assert.Equal(t, []byte("built payload"), req.EndorsementRequest.Payload)
+		return &prototk.EndorseTransactionResponse{
+			EndorsementResult: prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
+		}, nil
+	}
+
+	_, err = psc.EndorseTransaction(td.mdc, td.c.dbTX, &components.PrivateTransactionEndorseRequest{
+		TransactionSpecification: tx.PreAssembly.TransactionSpecification,
+		Verifiers:                tx.PreAssembly.Verifiers,
+		Signatures:               tx.PostAssembly.Signatures,
+		InputStates:              psc.d.toEndorsableList(tx.PostAssembly.InputStates),
+		ReadStates:               psc.d.toEndorsableList(tx.PostAssembly.ReadStates),
+		OutputStates:             psc.d.toEndorsableList(tx.PostAssembly.OutputStates),
+		InfoStates:               psc.d.toEndorsableList(tx.PostAssembly.InfoStates),
+		Endorsement:              tx.PostAssembly.AttestationPlan[0],
+		Endorser:                 &prototk.ResolvedVerifier{},
+	})
+	require.NoError(t, err)
+}
+
+func TestBuildAttestationPayloadsNoBuilders(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+
+	// Not called through to the domain, as no attestation names a builder
+	err := psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.NoError(t, err)
+	assert.Empty(t, tx.PostAssembly.AttestationPlan[0].Payload)
+
+	err = psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, &components.PrivateTransaction{})
+	assert.NoError(t, err)
+}
+
+func TestBuildAttestationPayloadsFail(t *testing.T) {
+	domainConf := goodDomainConf()
+	domainConf.PayloadBuilders = []string{"builder1"}
+	td, done := newTestDomain(t, false, domainConf, mockSchemas(), mockBlockHeight)
+	defer done()
+
+	psc, tx := doDomainInitAssembleTransactionOK(t, td)
+	ar := tx.PostAssembly.AttestationPlan[0]
+
+	ar.PayloadBuilder = confutil.P("unknown")
+	err := psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011665.*unknown", err)
+
+	ar.PayloadBuilder = confutil.P("builder1")
+	ar.Payload = []byte("supplied")
+	err = psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011666", err)
+
+	ar.Payload = nil
+	ar.AttestationType = prototk.AttestationType_SIGN
+	err = psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011672", err)
+
+	ar.AttestationType = prototk.AttestationType_ENDORSE
+	err = psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "PD011667", err)
+
+	tx.PostAssembly.OutputStates = []*components.FullState{}
+	td.tp.Functions.BuildAttestationPayload = func(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+	err = psc.BuildAttestationPayloads(td.mdc, td.c.dbTX, tx)
+	assert.Regexp(t, "pop", err)
+}
+
 func TestEndorseTransactionFail(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
 	defer done()
@@ -1212,6 +1458,58 @@ func TestInitCallOk(t *testing.T) {
 	assert.Equal(t, verifiers.ETH_ADDRESS, requiredVerifiers[0].VerifierType)
 }
 
+func TestInitCallFunctionNotAllowed(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+	assert.Nil(t, td.d.initError.Load())
+
+	psc := goodPSC(t, td)
+	td.d.conf.AllowedCallFunctions = []string{"balanceOf"}
+
+	td.tp.Functions.InitCall = func(ctx context.Context, icr *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+		return &prototk.InitCallResponse{}, nil
+	}
+
+	txi := goodPrivateCallWithInputsAndOutputs(psc)
+	_, err := psc.InitCall(td.ctx, txi)
+	assert.Regexp(t, "PD011663.*getBalance\\(address\\).*test1", err)
+
+	// Allowed by full signature
+	td.d.conf.AllowedCallFunctions = []string{"balanceOf", "getBalance(address)"}
+	_, err = psc.InitCall(td.ctx, txi)
+	require.NoError(t, err)
+}
+
+func TestInitCallStateChangingFunctionRejected(t *testing.T) {
+	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
+	defer done()
+	assert.Nil(t, td.d.initError.Load())
+
+	psc := goodPSC(t, td)
+
+	var readOnly *bool
+	td.tp.Functions.InitCall = func(ctx context.Context, icr *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+		return &prototk.InitCallResponse{ReadOnly: readOnly}, nil
+	}
+
+	// Declared state-changing in the ABI
+	txi := goodPrivateCallWithInputsAndOutputs(psc)
+	txi.Function.StateMutability = abi.NonPayable
+	_, err := psc.InitCall(td.ctx, txi)
+	assert.Regexp(t, "PD011664.*getBalance\\(address\\).*test1", err)
+
+	// Declared read-only by the domain, which takes precedence
+	readOnly = confutil.P(true)
+	_, err = psc.InitCall(td.ctx, txi)
+	require.NoError(t, err)
+
+	// Declared state-changing by the domain, even though the ABI says view
+	readOnly = confutil.P(false)
+	txi.Function.StateMutability = abi.View
+	_, err = psc.InitCall(td.ctx, txi)
+	assert.Regexp(t, "PD011664", err)
+}
+
 func TestInitCallBadInput(t *testing.T) {
 	td, done := newTestDomain(t, false, goodDomainConf(), mockSchemas(), mockBlockHeight)
 	defer done()
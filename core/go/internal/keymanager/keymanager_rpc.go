@@ -21,6 +21,7 @@ import (
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
@@ -33,7 +34,8 @@ func (km *keyManager) initRPC() {
 		Add("keymgr_wallets", km.rpcWallets()).
 		Add("keymgr_resolveKey", km.rpcResolveKey()).
 		Add("keymgr_resolveEthAddress", km.rpcResolveEthAddress()).
-		Add("keymgr_reverseKeyLookup", km.rpcReverseKeyLookup())
+		Add("keymgr_reverseKeyLookup", km.rpcReverseKeyLookup()).
+		Add("keymgr_rotateKey", km.rpcRotateKey())
 }
 
 func (km *keyManager) rpcWallets() rpcserver.RPCHandler {
@@ -70,3 +72,11 @@ func (km *keyManager) rpcReverseKeyLookup() rpcserver.RPCHandler {
 		return km.ReverseKeyLookup(ctx, km.p.DB(), algorithm, verifierType, verifier)
 	})
 }
+
+func (km *keyManager) rpcRotateKey() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		identifier string,
+	) (*signerapi.RotateKeyResponse, error) {
+		return km.RotateKey(ctx, identifier)
+	})
+}
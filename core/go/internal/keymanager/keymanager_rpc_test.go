@@ -27,6 +27,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +61,11 @@ func TestRPCLocalDetails(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, resolvedKey, reverseLookedUp)
 
+	// Keys of an HD wallet cannot be rotated
+	var rotated *signerapi.RotateKeyResponse
+	err = rpc.CallRPC(ctx, &rotated, "keymgr_rotateKey", "my.key.1")
+	assert.Regexp(t, "PD020836", err)
+
 }
 
 func newTestRPCServer(t *testing.T, ctx context.Context, km *keyManager) (rpcclient.Client, func()) {
@@ -433,7 +433,7 @@ func (kr *keyResolver) resolveKey(identifier, algorithm, verifierType string, re
 			log.L(kr.ctx).Infof("Resolved key (cached): identifier=%s algorithm=%s verifierType=%s keyHandle=%s verifier=%s",
 				identifier, algorithm, verifierType, mapping.KeyHandle, v.Verifier)
 			// populate the reverse lookup cache
-			kr.km.verifierReverseCache.Set(verifierReverseCacheKey(v.Type, v.Algorithm, v.Verifier), &pldapi.KeyMappingAndVerifier{
+			kr.km.verifierReverseCache.Set(verifierReverseCacheKey(v.Algorithm, v.Type, v.Verifier), &pldapi.KeyMappingAndVerifier{
 				KeyMappingWithPath: mapping,
 				Verifier:           v,
 			})
@@ -535,7 +535,7 @@ func (kr *keyResolver) postCommit() {
 		for _, v := range kr.newVerifiers {
 			if v.KeyIdentifier == m.Identifier {
 				// populate the reverse lookup cache
-				kr.km.verifierReverseCache.Set(verifierReverseCacheKey(v.Type, v.Algorithm, v.Verifier), &pldapi.KeyMappingAndVerifier{
+				kr.km.verifierReverseCache.Set(verifierReverseCacheKey(v.Algorithm, v.Type, v.Verifier), &pldapi.KeyMappingAndVerifier{
 					KeyMappingWithPath: m,
 					Verifier:           v.KeyVerifier,
 				})
@@ -242,3 +242,65 @@ func (km *keyManager) ReverseKeyLookup(ctx context.Context, dbTX *gorm.DB, algor
 	km.verifierReverseCache.Set(vKey, mapping)
 	return mapping, nil
 }
+
+// RotateKey replaces the key material behind the key handle of an existing identifier, in the wallet that holds it.
+// The stored verifiers of the identifier are deleted both before and after the rotation, and dropped from the caches,
+// so they are resolved again from the new key material. Deleting them first means a rotation that fails part way
+// through can only leave verifiers to be resolved again, and never verifiers that no longer match the key material.
+func (km *keyManager) RotateKey(ctx context.Context, identifier string) (*signerapi.RotateKeyResponse, error) {
+	var dbMappings []*DBKeyMapping
+	err := km.p.DB().WithContext(ctx).
+		Where(`"identifier" = ?`, identifier).
+		Limit(1).
+		Find(&dbMappings).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(dbMappings) == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgKeyManagerExistingIdentifierNotFound, identifier)
+	}
+	w, err := km.getWalletByName(ctx, dbMappings[0].Wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := km.deleteVerifiers(ctx, identifier); err != nil {
+		return nil, err
+	}
+	res, err := w.signingModule.RotateKey(ctx, dbMappings[0].KeyHandle)
+	if err == nil {
+		err = km.deleteVerifiers(ctx, identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Rotated key identifier=%s wallet=%s keyHandle=%s previousKeyHandle=%s", identifier, w.name, res.KeyHandle, res.PreviousKeyHandle)
+	return res, nil
+}
+
+func (km *keyManager) deleteVerifiers(ctx context.Context, identifier string) error {
+	var dbVerifiers []*DBKeyVerifier
+	err := km.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		err := dbTX.WithContext(ctx).
+			Where(`"identifier" = ?`, identifier).
+			Find(&dbVerifiers).
+			Error
+		if err == nil && len(dbVerifiers) > 0 {
+			err = dbTX.WithContext(ctx).
+				Where(`"identifier" = ?`, identifier).
+				Delete(&DBKeyVerifier{}).
+				Error
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// The caches are only updated once we know the delete is committed
+	for _, v := range dbVerifiers {
+		km.verifierByIdentityCache.Delete(verifierForwardCacheKey(v.Identifier, v.Algorithm, v.Type))
+		km.verifierReverseCache.Delete(verifierReverseCacheKey(v.Algorithm, v.Type, v.Verifier))
+	}
+	return nil
+}
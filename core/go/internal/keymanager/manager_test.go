@@ -24,6 +24,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
@@ -559,3 +560,92 @@ func TestReverseKeyLookupFailMapping(t *testing.T) {
 	_, err := km.ReverseKeyLookup(ctx, mc.c.Persistence().DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, verifier)
 	assert.Regexp(t, "PD010500", err)
 }
+
+func TestRotateKeyReResolvesVerifiers(t *testing.T) {
+	ctx, km, mc, done := newTestDBKeyManagerWithWallets(t, &pldconf.WalletConfig{
+		Name: "fswallet1",
+		Signer: &pldconf.SignerConfig{
+			KeyStore: pldconf.KeyStoreConfig{
+				Type: pldconf.KeyStoreTypeFilesystem,
+				FileSystem: pldconf.FileSystemKeyStoreConfig{
+					Path: confutil.P(t.TempDir()),
+				},
+			},
+		},
+	})
+	defer done()
+
+	addr1, err := km.ResolveEthAddressNewDatabaseTX(ctx, "key1")
+	require.NoError(t, err)
+	mapping, err := km.ReverseKeyLookup(ctx, mc.c.Persistence().DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, addr1.String())
+	require.NoError(t, err)
+	assert.Equal(t, "key1", mapping.Identifier)
+
+	res, err := km.RotateKey(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, "key1", res.KeyHandle)
+	assert.Equal(t, "key1#1", res.PreviousKeyHandle)
+
+	// The verifier is resolved again from the new key material
+	addr2, err := km.ResolveEthAddressNewDatabaseTX(ctx, "key1")
+	require.NoError(t, err)
+	assert.NotEqual(t, addr1, addr2)
+	mapping, err = km.ReverseKeyLookup(ctx, mc.c.Persistence().DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, addr2.String())
+	require.NoError(t, err)
+	assert.Equal(t, "key1", mapping.Identifier)
+	_, err = km.ReverseKeyLookup(ctx, mc.c.Persistence().DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, addr1.String())
+	assert.Regexp(t, "PD010511", err)
+}
+
+func TestRotateKeyNotSupportedKeepsVerifiers(t *testing.T) {
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t, hdWalletConfig("hdwallet1", ""))
+	defer done()
+
+	addr1, err := km.ResolveEthAddressNewDatabaseTX(ctx, "key1")
+	require.NoError(t, err)
+
+	_, err = km.RotateKey(ctx, "key1")
+	assert.Regexp(t, "PD020836", err)
+
+	addr2, err := km.ResolveEthAddressNewDatabaseTX(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, addr1, addr2)
+}
+
+func TestRotateKeyUnknownIdentifier(t *testing.T) {
+	ctx, km, _, done := newTestDBKeyManagerWithWallets(t, hdWalletConfig("hdwallet1", ""))
+	defer done()
+
+	_, err := km.RotateKey(ctx, "key1")
+	assert.Regexp(t, "PD010513", err)
+}
+
+func TestRotateKeyFail(t *testing.T) {
+	ctx, km, mc, done := newTestKeyManager(t, false, &pldconf.KeyManagerConfig{
+		Wallets: []*pldconf.WalletConfig{hdWalletConfig("hdwallet1", "")},
+	})
+	defer done()
+
+	mc.db.ExpectQuery("SELECT.*key_mappings").WillReturnError(fmt.Errorf("pop"))
+	_, err := km.RotateKey(ctx, "key1")
+	assert.Regexp(t, "pop", err)
+
+	mc.db.ExpectQuery("SELECT.*key_mappings").WillReturnRows(
+		sqlmock.NewRows([]string{"identifier", "wallet", "key_handle"}).AddRow("key1", "unknown", "key1"),
+	)
+	_, err = km.RotateKey(ctx, "key1")
+	assert.Regexp(t, "PD010503", err)
+
+	mc.db.ExpectQuery("SELECT.*key_mappings").WillReturnRows(
+		sqlmock.NewRows([]string{"identifier", "wallet", "key_handle"}).AddRow("key1", "hdwallet1", "key1"),
+	)
+	mc.db.ExpectBegin()
+	mc.db.ExpectQuery("SELECT.*key_verifiers").WillReturnRows(
+		sqlmock.NewRows([]string{"identifier", "algorithm", "type", "verifier"}).
+			AddRow("key1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, tktypes.RandAddress().String()),
+	)
+	mc.db.ExpectExec("DELETE.*key_verifiers").WillReturnError(fmt.Errorf("pop"))
+	mc.db.ExpectRollback()
+	_, err = km.RotateKey(ctx, "key1")
+	assert.Regexp(t, "pop", err)
+}
@@ -104,6 +104,8 @@ var (
 	MsgStateHashMismatch              = ffe("PD010129", "The supplied state ID '%s' does not match the state hash '%s'")
 	MsgStateIDMissing                 = ffe("PD010130", "The state id must be supplied for this domain")
 	MsgStateFlushInProgress           = ffe("PD010131", "A flush is already in progress for this domain context")
+	MsgStateMigrationInvalid          = ffe("PD010132", "Invalid migration from schema %s to schema %s for domain %s")
+	MsgStateMigrationCycle            = ffe("PD010133", "Schema migrations for domain %s form a cycle at schema %s")
 
 	// Persistence PD0102XX
 	MsgPersistenceInvalidType         = ffe("PD010200", "Invalid persistence type: %s")
@@ -215,6 +217,7 @@ var (
 	MsgEthClientReturnValueNotDecoded   = ffe("PD011515", "Error return value for custom error: %s")
 	MsgEthClientReturnValueNotAvailable = ffe("PD011516", "Error return value unavailable")
 	MsgEthClientNoConnection            = ffe("PD011517", "No JSON/RPC connection is available to this client")
+	MsgEthClientRequestTimeout          = ffe("PD011518", "JSON/RPC %s request timed out after %s")
 
 	// DomainManager module PD0116XX
 	MsgDomainNotFound                         = ffe("PD011600", "Domain %q not found")
@@ -275,45 +278,86 @@ var (
 	MsgDomainSingingKeyMustBeLocalEthSign     = ffe("PD011659", "Singing key must be local for ethereum transaction signing")
 	MsgDomainNullifierForPartyOutsideDistro   = ffe("PD011660", "A nullifier was requested for a party that is not in the distribution list")
 	MsgDomainInvalidFromAddress               = ffe("PD011661", "Invalid from identity in transaction")
+	MsgDomainTXIncompleteValidateAssembled    = ffe("PD011662", "Transaction is incomplete for phase ValidateAssembled")
+	MsgDomainCallFunctionNotAllowed           = ffe("PD011663", "Function '%s' is not in the list of functions allowed to be called on domain '%s'")
+	MsgDomainCallFunctionNotReadOnly          = ffe("PD011664", "Function '%s' is not read-only on domain '%s' and must be submitted as a transaction")
+	MsgDomainPayloadBuilderNotRegistered      = ffe("PD011665", "Payload builder '%s' is not registered by domain '%s'")
+	MsgDomainPayloadBuilderWithPayload        = ffe("PD011666", "Attestation request '%s' must not supply a payload when payload builder '%s' is set")
+	MsgDomainTXIncompleteBuildPayloads        = ffe("PD011667", "Transaction is incomplete for phase BuildAttestationPayloads")
+	MsgDomainPayloadBuilderNotEndorse         = ffe("PD011672", "Attestation request '%s' of type %s cannot use payload builder '%s', as payload builders are only supported for endorsements")
+	MsgDomainInvalidSchemaMigration           = ffe("PD011668", "Schema migration %d is invalid")
+	MsgDomainSchemaSuperseded                 = ffe("PD011669", "Schema %s has been superseded by schema %s")
+	MsgDomainAttestationPlanTooLarge          = ffe("PD011670", "Attestation plan for transaction %s from domain '%s' exceeds the configured limit: %s=%d limit=%d")
+	MsgDomainInvalidStateDistribution         = ffe("PD011671", "Invalid state distribution order '%s' for domain '%s'")
 
 	// Entrypoint PD0117XX
 	MsgEntrypointUnknownRunMode = ffe("PD011700", "Unknown run mode '%s'")
 
 	// PrivTxMgr PD0118XX
-	MsgDomainNotProvided                         = ffe("PD011800", "Domain not found in the transaction input")
-	MsgPrivateTxManagerInternalError             = ffe("PD011801", "Unexpected error in engine %s")
-	MsgPrivateTxManagerAssembleError             = ffe("PD011802", "Error assembling transaction: %s")
-	MsgPrivateTxManagerParseFailed               = ffe("PD011803", "Failed to parse message")
-	MsgPrivateTxManagerInvalidMessage            = ffe("PD011804", "Invalid message received from transport")
-	MsgSequencerInternalError                    = ffe("PD011805", "Sequencer internal error")
-	MsgKeyResolutionFailed                       = ffe("PD011806", "Key resolution failed for key %s, algorithm %s, verifierType %s")
-	MsgDeployInitFailed                          = ffe("PD011807", "Failed to initialise a deploy transaction")
-	MsgDeployPrepareFailed                       = ffe("PD011808", "Failed to prepare a deploy transaction")
-	MsgDeployPrepareIncomplete                   = ffe("PD011809", "Prepare step did not return a transaction to invoke, or a transaction to deploy")
-	MsgBaseLedgerTransactionFailed               = ffe("PD011810", "Failed to submit base ledger transaction")
-	MsgContractAddressNotProvided                = ffe("PD011811", "Contract address (To) not found in the transaction input")
-	MsgPrivTxMgrPublicTxFail                     = ffe("PD011812", "Public transaction rejected")
-	MsgResolveVerifierRemoteFailed               = ffe("PD011813", "Failed to resolve verifier on remote node with lookup %s algorithm %s: Error %s")
-	MsgPrivateTxManagerAssembleRevert            = ffe("PD011814", "Domain reverted transaction on assemble")
-	MsgPrivateTxManagerResolveError              = ffe("PD011815", "Failed to resolve local signer for party %s (verifier=%s,algorithm=%s): %s")
-	MsgPrivateTxManagerSignError                 = ffe("PD011816", "Failed to sign for party %s (verifier=%s,algorithm=%s): %s")
-	MsgPrivateTxManagerEndorsementRequestError   = ffe("PD011817", "Failed to request endorsement from %s: %s")
-	MsgPrivateTxManagerResolveDispatchError      = ffe("PD011818", "Failed to resolve dispatcher: %s")
-	MsgPrivateTxManagerPrepareError              = ffe("PD011819", "Failed to prepare transaction dispatch to base ledger: %s")
-	MsgPrivateTxManagerDeployError               = ffe("PD011820", "Failed to deploy private contract")
-	MsgPrivateTxMgrEncodeCallDataFailed          = ffe("PD011821", "Failed to encode call data '%s' for private contract deploy")
-	MsgPrivateTxManagerNonLocalSigningAddr       = ffe("PD011822", "Attempt do dispatch a blockchain transaction using a signing identity for a different node: %s")
-	MsgPrivateTxManagerStateHashContention       = ffe("PD011823", "Contention detected attempting to spend hash %s in multiple transactions")
-	MsgPrivateReDelegationRequired               = ffe("PD011824", "Re-delegation is required for this transaction to progress")
-	MsgPrivateTxMgrDomainMismatch                = ffe("PD011825", "Domain '%s' specified does not match domain '%s' of deployed private smart contract %s")
-	MsgPrivateTxMgrInvalidPrepareOutcome         = ffe("PD011826", "Prepare outcome unexpected for transaction %s intent=%s public-submission=%t private-submission=%t")
-	MsgPrivateTxMgrPrepareNotSupportedDeploy     = ffe("PD011827", "Preparing transactions for external submission is not supported for deploy")
-	MsgPrivateTxManagerInvalidEventMissingField  = ffe("PD011828", "Invalid event: missing field %s")
-	MsgPrivateTxManagerSignRemoteError           = ffe("PD011829", "Attempt to sign a transaction with an identity from a remote node: %s")
-	MsgPrivateTxMgrFromNotResolvedDistroTime     = ffe("PD011830", "Failed to extract node from fully qualified from address for state distribution")
-	MsgPrivateTxMgrInvalidTxStateStateDistro     = ffe("PD011831", "Invalid transaction state for state distribution")
-	MsgPrivateTxMgrDistributionNotFullyQualified = ffe("PD011832", "State distribution from domain is not fully qualified: %s")
-	MsgPrivateTxMgrInvalidNullifierSpecInDistro  = ffe("PD011833", "Invalid nullifier specification in new state instruction from domain")
+	MsgDomainNotProvided                              = ffe("PD011800", "Domain not found in the transaction input")
+	MsgPrivateTxManagerInternalError                  = ffe("PD011801", "Unexpected error in engine %s")
+	MsgPrivateTxManagerAssembleError                  = ffe("PD011802", "Error assembling transaction: %s")
+	MsgPrivateTxManagerParseFailed                    = ffe("PD011803", "Failed to parse message")
+	MsgPrivateTxManagerInvalidMessage                 = ffe("PD011804", "Invalid message received from transport")
+	MsgSequencerInternalError                         = ffe("PD011805", "Sequencer internal error")
+	MsgKeyResolutionFailed                            = ffe("PD011806", "Key resolution failed for key %s, algorithm %s, verifierType %s")
+	MsgDeployInitFailed                               = ffe("PD011807", "Failed to initialise a deploy transaction")
+	MsgDeployPrepareFailed                            = ffe("PD011808", "Failed to prepare a deploy transaction")
+	MsgDeployPrepareIncomplete                        = ffe("PD011809", "Prepare step did not return a transaction to invoke, or a transaction to deploy")
+	MsgBaseLedgerTransactionFailed                    = ffe("PD011810", "Failed to submit base ledger transaction")
+	MsgContractAddressNotProvided                     = ffe("PD011811", "Contract address (To) not found in the transaction input")
+	MsgPrivTxMgrPublicTxFail                          = ffe("PD011812", "Public transaction rejected")
+	MsgResolveVerifierRemoteFailed                    = ffe("PD011813", "Failed to resolve verifier on remote node with lookup %s algorithm %s: Error %s")
+	MsgPrivateTxManagerAssembleRevert                 = ffe("PD011814", "Domain reverted transaction on assemble")
+	MsgPrivateTxManagerResolveError                   = ffe("PD011815", "Failed to resolve local signer for party %s (verifier=%s,algorithm=%s): %s")
+	MsgPrivateTxManagerSignError                      = ffe("PD011816", "Failed to sign for party %s (verifier=%s,algorithm=%s): %s")
+	MsgPrivateTxManagerEndorsementRequestError        = ffe("PD011817", "Failed to request endorsement from %s: %s")
+	MsgPrivateTxManagerResolveDispatchError           = ffe("PD011818", "Failed to resolve dispatcher: %s")
+	MsgPrivateTxManagerPrepareError                   = ffe("PD011819", "Failed to prepare transaction dispatch to base ledger: %s")
+	MsgPrivateTxManagerDeployError                    = ffe("PD011820", "Failed to deploy private contract")
+	MsgPrivateTxMgrEncodeCallDataFailed               = ffe("PD011821", "Failed to encode call data '%s' for private contract deploy")
+	MsgPrivateTxManagerNonLocalSigningAddr            = ffe("PD011822", "Attempt do dispatch a blockchain transaction using a signing identity for a different node: %s")
+	MsgPrivateTxManagerStateHashContention            = ffe("PD011823", "Contention detected attempting to spend hash %s in multiple transactions")
+	MsgPrivateReDelegationRequired                    = ffe("PD011824", "Re-delegation is required for this transaction to progress")
+	MsgPrivateTxMgrDomainMismatch                     = ffe("PD011825", "Domain '%s' specified does not match domain '%s' of deployed private smart contract %s")
+	MsgPrivateTxMgrInvalidPrepareOutcome              = ffe("PD011826", "Prepare outcome unexpected for transaction %s intent=%s public-submission=%t private-submission=%t")
+	MsgPrivateTxMgrPrepareNotSupportedDeploy          = ffe("PD011827", "Preparing transactions for external submission is not supported for deploy")
+	MsgPrivateTxManagerInvalidEventMissingField       = ffe("PD011828", "Invalid event: missing field %s")
+	MsgPrivateTxManagerSignRemoteError                = ffe("PD011829", "Attempt to sign a transaction with an identity from a remote node: %s")
+	MsgPrivateTxMgrFromNotResolvedDistroTime          = ffe("PD011830", "Failed to extract node from fully qualified from address for state distribution")
+	MsgPrivateTxMgrInvalidTxStateStateDistro          = ffe("PD011831", "Invalid transaction state for state distribution")
+	MsgPrivateTxMgrDistributionNotFullyQualified      = ffe("PD011832", "State distribution from domain is not fully qualified: %s")
+	MsgPrivateTxMgrInvalidNullifierSpecInDistro       = ffe("PD011833", "Invalid nullifier specification in new state instruction from domain")
+	MsgPrivateTxMgrContentionRetryExhausted           = ffe("PD011834", "Failed to delegate transaction to node '%s' after losing contention: %s")
+	MsgPrivateTxMgrDelegationNotAcknowledged          = ffe("PD011863", "Delegation was not acknowledged after %d attempts")
+	MsgPrivateTxManagerValidateAssembledError         = ffe("PD011835", "Domain rejected assembled transaction: %s")
+	MsgPrivateTxManagerSignTimeout                    = ffe("PD011836", "Timed out after %s waiting to sign for party %s (verifier=%s,algorithm=%s)")
+	MsgPrivateTxManagerNotaryEndorsementParties       = ffe("PD011862", "Endorsement '%s' must name exactly one party under the notary endorsement policy of the contract, but names %d")
+	MsgPrivateTxManagerDependencyFailed               = ffe("PD011837", "Dependency %s failed: %s")
+	MsgPrivateTxManagerDependencyNotFound             = ffe("PD011838", "Dependency %s does not exist")
+	MsgPrivateTxManagerEndorserAlgorithmMismatch      = ffe("PD011839", "Key for endorser %s has algorithm '%s' which does not match the requested algorithm '%s'")
+	MsgPrivateTxManagerEndorserPayloadType            = ffe("PD011840", "Key for endorser %s (algorithm=%s) does not support payload type '%s'")
+	MsgPrivateTxManagerMaxCallDepthExceeded           = ffe("PD011841", "Private contract call to %s exceeded the maximum call depth of %d")
+	MsgPrivateTxManagerBuildPayloadError              = ffe("PD011842", "Failed to build attestation payloads: %s")
+	MsgPrivateTxManagerRetryBudgetExhausted           = ffe("PD011843", "Retry budget exhausted retrying %s after %d retries over %s: %s")
+	MsgPrivateTxManagerInvalidOverflowPolicy          = ffe("PD011844", "Invalid subscriber buffer overflow policy '%s'")
+	MsgPrivateTxManagerUnrequestedEndorsement         = ffe("PD011845", "Endorsement '%s' (type=%s,algorithm=%s) from party '%s' does not match any attestation requested for transaction %s")
+	MsgPrivateTxManagerInvalidEndorsement             = ffe("PD011846", "Invalid endorsement response for transaction %s: missing %s")
+	MsgPrivateTxManagerInvalidEndorsementRevertPolicy = ffe("PD011847", "Invalid endorsement revert policy '%s'")
+	MsgPrivateTxManagerEndorsementReverted            = ffe("PD011848", "Endorsement '%s' was rejected by party '%s': %s")
+	MsgPrivateTxManagerCancelDispatched               = ffe("PD011849", "Transaction %s has already been dispatched to the base ledger and cannot be cancelled")
+	MsgPrivateTxManagerCancelDelegated                = ffe("PD011850", "Transaction %s is being coordinated by another node and cannot be cancelled on this node")
+	MsgPrivateTxManagerCancelFinalizing               = ffe("PD011851", "Transaction %s is already being finalized and cannot be cancelled: %s")
+	MsgPrivateTxManagerCancelNotInFlight              = ffe("PD011852", "Transaction %s is not in flight for contract %s")
+	MsgPrivateTxManagerTransactionCancelled           = ffe("PD011853", "Transaction cancelled")
+	MsgPrivateTxManagerInputStateConflict             = ffe("PD011854", "Input state %s is already claimed by in-flight transaction %s")
+	MsgPrivateTxManagerNoContentionBidders            = ffe("PD011855", "No bidders supplied to resolve contention for state %s")
+	MsgPrivateTxManagerEndorsementTimeout             = ffe("PD011856", "Endorsement request '%s' to %s was not answered after %d attempts with a timeout of %s")
+	MsgPrivateTxManagerInvalidFlushFailurePolicy      = ffe("PD011857", "Invalid flush failure policy '%s'")
+	MsgPrivateTxManagerDispatchPersistFailed          = ffe("PD011858", "Dispatch failed to persist, and none of the states of the transaction were written: %s")
+	MsgPrivateTxManagerInvalidSubmitSignerPool        = ffe("PD011859", "Invalid submit signer pool size %d with selection '%s'")
+	MsgPrivateTxManagerEstimateRemoteEndorser         = ffe("PD011860", "Endorsement '%s' from remote party '%s' cannot be gathered to estimate gas")
+	MsgPrivateTxManagerEstimateNoPublicTx             = ffe("PD011861", "Transaction does not result in a public transaction whose gas can be estimated")
 
 	// Public Transaction Manager PD0119XX
 	MsgInsufficientBalance             = ffe("PD011900", "Balance %s of fueling source address %s is below the required amount %s")
@@ -344,6 +388,18 @@ var (
 	MsgInvalidAutoFuelSource           = ffe("PD011934", "Invalid auto-fueling source '%s'")
 	MsgInvalidStateMissingTXHash       = ffe("PD011935", "Invalid state - missing transaction hash from previous sign stage")
 	MsgInvalidTXMissingFromAddr        = ffe("PD011936", "From address missing for transaction")
+	MsgInvalidResubmitInterval         = ffe("PD011937", "Invalid resubmit interval '%s' - must be a positive duration such as '30s'")
+	MsgInvalidSignedTransaction        = ffe("PD011938", "Invalid signed transaction")
+	MsgSignedTransactionFromMismatch   = ffe("PD011939", "Signed transaction is signed by %s, but the submission is from %s")
+	MsgSignedTransactionNonceMismatch  = ffe("PD011940", "Signed transaction from %s has nonce %d, but the next nonce for the signer is %d")
+	MsgSignedTransactionMismatch       = ffe("PD011945", "Signed transaction does not match the target and data of the submission (to=%s signed to=%s)")
+	MsgOutOfGasAlreadyResubmitted      = ffe("PD011946", "Out of gas transaction %s has already been resubmitted")
+	MsgNonceOverrideInUse              = ffe("PD011941", "Nonce %d for %s is already assigned to a public transaction")
+	MsgNonceOverrideUsedOnChain        = ffe("PD011942", "Nonce %d for %s has already been used on chain, where the next nonce is %d")
+	MsgNonceOverrideSignedTransaction  = ffe("PD011943", "A nonce cannot be supplied with a signed transaction, as the nonce is fixed by the signature")
+	MsgNonceOverrideNotAssigned        = ffe("PD011947", "Nonce %d for %s is not below the next nonce %d of the nonce cache, so would be assigned again")
+	MsgPublicTxNonceGapFilled          = ffe("PD011944", "PubTx[INFO] from=%s nonce=%d was used by gap-fill transaction %s, as this transaction is suspended and was blocking later nonces")
+	MsgPublicTxNonceUsedByGapFill      = ffe("PD011948", "Nonce %d for %s was used by gap-fill transaction %s, as this transaction was suspended and was blocking later nonces")
 
 	// TransportManager module PD0120XX
 	MsgTransportInvalidMessage                = ffe("PD012000", "Invalid message")
@@ -359,6 +415,9 @@ var (
 	MsgTransportClientAlreadyRegistered       = ffe("PD012010", "Client '%s' already registered")
 	MsgTransportDestinationNotFound           = ffe("PD012011", "Destination '%s' not found")
 	MsgTransportClientRegisterAfterStartup    = ffe("PD012012", "Client '%s' attempted registration after startup")
+	MsgTransportInboundPeerBusy               = ffe("PD012013", "Inbound message from '%s' not processed after waiting %s for one of %d processing slots")
+	MsgTransportQueryLimitRequired            = ffe("PD012015", "limit is required on all queries")
+	MsgTransportProtocolVersionMismatch       = ffe("PD012014", "Message from '%s' uses transport protocol version %d, but this node supports version %d")
 
 	// RegistryManager module PD0121XX
 	MsgRegistryNodeEntiresNotFound     = ffe("PD012100", "No entries found for node '%s'")
@@ -371,6 +430,9 @@ var (
 	MsgRegistryQueryLimitRequired      = ffe("PD012107", "Limit is required on all queries")
 	MsgRegistryTransportPropertyRegexp = ffe("PD012108", "transports.propertyRegexp for registry '%s' is invalid")
 	MsgRegistryDollarPrefixReserved    = ffe("PD012109", "Name '%s' is invalid. Dollar ('$') prefix is allowed only for reserved properties, and then is required (pluginReserved=%t)")
+	MsgRegistrySnapshotMismatch        = ffe("PD012110", "Snapshot of registry '%s' cannot be imported into registry '%s'")
+	MsgRegistryTransportsNotEnabled    = ffe("PD012111", "Transport lookups are not enabled for registry '%s'")
+	MsgRegistryTrustedNotConfigured    = ffe("PD012112", "Trusted registry '%s' is not configured")
 
 	// TxMgr module PD0122XX
 	MsgTxMgrQueryLimitRequired           = ffe("PD012200", "limit is required on all queries")
@@ -401,6 +463,19 @@ var (
 	MsgTxMgrDecodeEventAnonymous         = ffe("PD012228", "Unable to decode event with no topics (anonymous events cannot be decoded)")
 	MsgTxMgrDecodeEventNoABI             = ffe("PD012229", "Unable to decode event data using stored ABIs (%d matched signature)")
 	MsgTxMgrPublicSenderNotValidLocal    = ffe("PD012230", "The from identity '%s' must be a valid identity local to the node")
+	MsgTxMgrTooManyDependencies          = ffe("PD012231", "Transaction declares %d dependencies, which exceeds the maximum of %d")
+	MsgTxMgrKeyPathOverridePolicyInvalid = ffe("PD012232", "Invalid allowedKeyPathOverrides regular expression '%s'")
+	MsgTxMgrKeyPathOverrideNotAllowed    = ffe("PD012233", "Key path override '%s' is not allowed by the policy of this node")
+	MsgTxMgrKeyPathOverridePublicOnly    = ffe("PD012234", "Key path override is only supported for public transactions")
+	MsgTxMgrPreparedTransactionExpired   = ffe("PD012235", "Prepared transaction expired, never submitted (maxRetention=%s)")
+	MsgTxMgrInvalidReorgPolicy           = ffe("PD012236", "Invalid reorgPolicy '%s'")
+	MsgTxMgrSignedTransactionPublicOnly  = ffe("PD012243", "A signed transaction can only be supplied when sending a public transaction")
+	MsgTxMgrInvalidStatsWindow           = ffe("PD012237", "Invalid stats window '%s': must be a positive duration")
+	MsgTxMgrCancelNotFound               = ffe("PD012238", "Transaction %s not found")
+	MsgTxMgrCancelNotPrivate             = ffe("PD012239", "Transaction %s is not a private transaction, so cannot be cancelled")
+	MsgTxMgrCancelAlreadyFinalized       = ffe("PD012240", "Transaction %s has already been finalized and cannot be cancelled")
+	MsgTxMgrEstimateNotPrivateInvoke     = ffe("PD012241", "Gas can only be estimated for the invocation of a deployed private smart contract")
+	MsgTxMgrNonceOverridePublicOnly      = ffe("PD012242", "Nonce override is only supported for public transactions")
 
 	// FlushWriter module PD0123XX
 	MsgFlushWriterQuiescing      = ffe("PD012300", "Writer shutting down")
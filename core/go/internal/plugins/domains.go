@@ -342,3 +342,63 @@ func (br *domainBridge) BuildReceipt(ctx context.Context, req *prototk.BuildRece
 	)
 	return
 }
+
+func (br *domainBridge) ValidateAssembled(ctx context.Context, req *prototk.ValidateAssembledRequest) (res *prototk.ValidateAssembledResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) {
+			dm.Message().RequestToDomain = &prototk.DomainMessage_ValidateAssembled{ValidateAssembled: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) bool {
+			if r, ok := dm.Message().ResponseFromDomain.(*prototk.DomainMessage_ValidateAssembledRes); ok {
+				res = r.ValidateAssembledRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
+
+func (br *domainBridge) BuildAttestationPayload(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (res *prototk.BuildAttestationPayloadResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) {
+			dm.Message().RequestToDomain = &prototk.DomainMessage_BuildAttestationPayload{BuildAttestationPayload: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) bool {
+			if r, ok := dm.Message().ResponseFromDomain.(*prototk.DomainMessage_BuildAttestationPayloadRes); ok {
+				res = r.BuildAttestationPayloadRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
+
+func (br *domainBridge) MigrateState(ctx context.Context, req *prototk.MigrateStateRequest) (res *prototk.MigrateStateResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) {
+			dm.Message().RequestToDomain = &prototk.DomainMessage_MigrateState{MigrateState: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) bool {
+			if r, ok := dm.Message().ResponseFromDomain.(*prototk.DomainMessage_MigrateStateRes); ok {
+				res = r.MigrateStateRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
+
+func (br *domainBridge) TransactionConfirmed(ctx context.Context, req *prototk.TransactionConfirmedRequest) (res *prototk.TransactionConfirmedResponse, err error) {
+	err = br.toPlugin.RequestReply(ctx,
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) {
+			dm.Message().RequestToDomain = &prototk.DomainMessage_TransactionConfirmed{TransactionConfirmed: req}
+		},
+		func(dm plugintk.PluginMessage[prototk.DomainMessage]) bool {
+			if r, ok := dm.Message().ResponseFromDomain.(*prototk.DomainMessage_TransactionConfirmedRes); ok {
+				res = r.TransactionConfirmedRes
+			}
+			return res != nil
+		},
+	)
+	return
+}
@@ -229,6 +229,26 @@ func TestDomainRequestsOK(t *testing.T) {
 				ReceiptJson: `{"receipt":"data"}`,
 			}, nil
 		},
+		ValidateAssembled: func(ctx context.Context, vr *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+			assert.Equal(t, "tx1", vr.Transaction.TransactionId)
+			return &prototk.ValidateAssembledResponse{}, nil
+		},
+		BuildAttestationPayload: func(ctx context.Context, bapr *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+			assert.Equal(t, "builder1", bapr.PayloadBuilder)
+			return &prototk.BuildAttestationPayloadResponse{
+				Payload: []byte("payload1"),
+			}, nil
+		},
+		MigrateState: func(ctx context.Context, msr *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+			assert.Equal(t, `{"old":true}`, msr.StateDataJson)
+			return &prototk.MigrateStateResponse{
+				StateDataJson: `{"new":true}`,
+			}, nil
+		},
+		TransactionConfirmed: func(ctx context.Context, tcr *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+			assert.Equal(t, "tx1", tcr.TransactionId)
+			return &prototk.TransactionConfirmedResponse{}, nil
+		},
 	}
 
 	tdm := &testDomainManager{
@@ -392,6 +412,29 @@ func TestDomainRequestsOK(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, `{"receipt":"data"}`, brr.ReceiptJson)
 
+	_, err = domainAPI.ValidateAssembled(ctx, &prototk.ValidateAssembledRequest{
+		Transaction: &prototk.TransactionSpecification{TransactionId: "tx1"},
+	})
+	require.NoError(t, err)
+
+	bapr, err := domainAPI.BuildAttestationPayload(ctx, &prototk.BuildAttestationPayloadRequest{
+		PayloadBuilder: "builder1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload1"), bapr.Payload)
+
+	msr, err := domainAPI.MigrateState(ctx, &prototk.MigrateStateRequest{
+		StateDataJson: `{"old":true}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"new":true}`, msr.StateDataJson)
+
+	tcr, err := domainAPI.TransactionConfirmed(ctx, &prototk.TransactionConfirmedRequest{
+		TransactionId: "tx1",
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, tcr)
+
 	callbacks := <-waitForCallbacks
 
 	fas, err := callbacks.FindAvailableStates(ctx, &prototk.FindAvailableStatesRequest{
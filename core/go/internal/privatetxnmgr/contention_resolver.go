@@ -17,12 +17,19 @@
 package privatetxnmgr
 
 import (
+	"context"
+	"slices"
 	"strconv"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/serialx/hashring"
 )
 
+// Number of virtual nodes each bidder is given on the hashring, so that winners are fairly distributed
+const contentionResolverVirtualNodes = 500
+
 func NewContentionResolver() ptmgrtypes.ContentionResolver {
 	return &contentionResolver{}
 }
@@ -30,17 +37,33 @@ func NewContentionResolver() ptmgrtypes.ContentionResolver {
 type contentionResolver struct {
 }
 
-func (c *contentionResolver) Resolve(stateID, bidder1, bidder2 string) (string, error) {
+// The winner depends only on the state and the set of bidders, so every node resolving the same contention
+// reaches the same result regardless of the order in which it learned of the bids
+func (c *contentionResolver) Resolve(stateID string, bidders []string) (string, error) {
+	bidders = slices.Clone(bidders)
+	slices.Sort(bidders)
+	bidders = slices.Compact(bidders)
+	if len(bidders) == 0 {
+		return "", i18n.NewError(context.Background(), msgs.MsgPrivateTxManagerNoContentionBidders, stateID)
+	}
 
-	bidders := make([]string, 0, 1000)
-	// create 500 virtual nodes for each bidding ptmgrtypes.ContentionResolver
-	for i := 0; i < 500; i++ {
-		bidders = append(bidders, bidder1+strconv.Itoa(i))
-		bidders = append(bidders, bidder2+strconv.Itoa(i))
+	// create virtual nodes for each bidder, remembering which bidder each one belongs to
+	virtualNodes := make([]string, 0, len(bidders)*contentionResolverVirtualNodes)
+	virtualNodeBidders := make(map[string]string, len(bidders)*contentionResolverVirtualNodes)
+	for i := 0; i < contentionResolverVirtualNodes; i++ {
+		for _, bidder := range bidders {
+			virtualNode := bidder + strconv.Itoa(i)
+			if _, exists := virtualNodeBidders[virtualNode]; !exists {
+				virtualNodes = append(virtualNodes, virtualNode)
+				virtualNodeBidders[virtualNode] = bidder
+			}
+		}
 	}
-	ring := hashring.New(bidders)
+	ring := hashring.New(virtualNodes)
 	winnerVirtual, _ := ring.GetNode(stateID)
-	winner := winnerVirtual[:36]
-	return winner, nil
+	return virtualNodeBidders[winnerVirtual], nil
+}
 
+func (c *contentionResolver) ResolvePair(stateID, bidder1, bidder2 string) (string, error) {
+	return c.Resolve(stateID, []string{bidder1, bidder2})
 }
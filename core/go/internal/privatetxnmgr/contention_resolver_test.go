@@ -17,6 +17,8 @@
 package privatetxnmgr
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/google/uuid"
@@ -39,7 +41,7 @@ func TestContentionResolver_2TransactionsDeterministicResults(t *testing.T) {
 
 	for i := 0; i < 1000; i++ {
 		stateID := uuid.New().String()
-		winner, err := resolver.Resolve(stateID, biddingTransaction1, biddingTransaction2)
+		winner, err := resolver.ResolvePair(stateID, biddingTransaction1, biddingTransaction2)
 		require.NoError(t, err)
 		assert.Contains(t, []string{biddingTransaction1, biddingTransaction2}, winner)
 		if winner == biddingTransaction1 {
@@ -62,9 +64,9 @@ func TestContentionResolver_CommutativeProperty(t *testing.T) {
 
 	for i := 0; i < 100; i++ {
 		stateID := uuid.New().String()
-		winner1, err := resolver.Resolve(stateID, biddingTransaction1, biddingTransaction2)
+		winner1, err := resolver.ResolvePair(stateID, biddingTransaction1, biddingTransaction2)
 		require.NoError(t, err)
-		winner2, err := resolver.Resolve(stateID, biddingTransaction2, biddingTransaction1)
+		winner2, err := resolver.ResolvePair(stateID, biddingTransaction2, biddingTransaction1)
 		require.NoError(t, err)
 		assert.Equal(t, winner1, winner2)
 	}
@@ -113,24 +115,24 @@ func TestContentionResolver_AssociativeProperty(t *testing.T) {
 		{bidders[3], bidders[2], bidders[1], bidders[0]},
 	}
 	runWinnerStaysOn := func(draw []string, stateID string) string {
-		winner1, err := resolver.Resolve(stateID, draw[0], draw[1])
+		winner1, err := resolver.ResolvePair(stateID, draw[0], draw[1])
 		require.NoError(t, err)
 
-		winner2, err := resolver.Resolve(stateID, winner1, draw[2])
+		winner2, err := resolver.ResolvePair(stateID, winner1, draw[2])
 		require.NoError(t, err)
 
-		finalWinner, err := resolver.Resolve(stateID, winner2, draw[3])
+		finalWinner, err := resolver.ResolvePair(stateID, winner2, draw[3])
 		require.NoError(t, err)
 
 		return finalWinner
 	}
 
 	runKnockout := func(draw []string, stateID string) string {
-		winnerSF1, err := resolver.Resolve(stateID, draw[0], draw[1])
+		winnerSF1, err := resolver.ResolvePair(stateID, draw[0], draw[1])
 		require.NoError(t, err)
-		winnerSF2, err := resolver.Resolve(stateID, draw[2], draw[3])
+		winnerSF2, err := resolver.ResolvePair(stateID, draw[2], draw[3])
 		require.NoError(t, err)
-		finalWinner, err := resolver.Resolve(stateID, winnerSF1, winnerSF2)
+		finalWinner, err := resolver.ResolvePair(stateID, winnerSF1, winnerSF2)
 		require.NoError(t, err)
 		return finalWinner
 	}
@@ -150,3 +152,66 @@ func TestContentionResolver_AssociativeProperty(t *testing.T) {
 		}
 	}
 }
+
+func TestContentionResolver_MultipleBiddersOrderIndependent(t *testing.T) {
+	// create 5 ids at random (representing bidding transactions),
+	// then check that the winner for each state is the same however the bidders are ordered, and
+	// is consistent with resolving the contention as a sequence of pairs
+	resolver := NewContentionResolver()
+
+	bidders := make([]string, 5)
+	for i := range bidders {
+		bidders[i] = uuid.New().String()
+	}
+
+	for i := 0; i < 20; i++ {
+		stateID := uuid.New().String()
+		winner, err := resolver.Resolve(stateID, bidders)
+		require.NoError(t, err)
+		assert.Contains(t, bidders, winner)
+
+		for j := 0; j < 10; j++ {
+			shuffled := slices.Clone(bidders)
+			rand.Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+			shuffledWinner, err := resolver.Resolve(stateID, shuffled)
+			require.NoError(t, err)
+			assert.Equal(t, winner, shuffledWinner)
+		}
+
+		pairwiseWinner := bidders[0]
+		for _, bidder := range bidders[1:] {
+			pairwiseWinner, err = resolver.ResolvePair(stateID, pairwiseWinner, bidder)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, winner, pairwiseWinner)
+
+		// Duplicate bids do not change the outcome
+		duplicatedWinner, err := resolver.Resolve(stateID, append(slices.Clone(bidders), bidders[0], bidders[3]))
+		require.NoError(t, err)
+		assert.Equal(t, winner, duplicatedWinner)
+	}
+}
+
+func TestContentionResolver_PairMatchesResolve(t *testing.T) {
+	resolver := NewContentionResolver()
+	bidder1 := uuid.New().String()
+	bidder2 := uuid.New().String()
+	for i := 0; i < 20; i++ {
+		stateID := uuid.New().String()
+		pairWinner, err := resolver.ResolvePair(stateID, bidder1, bidder2)
+		require.NoError(t, err)
+		winner, err := resolver.Resolve(stateID, []string{bidder2, bidder1})
+		require.NoError(t, err)
+		assert.Equal(t, pairWinner, winner)
+	}
+
+	// A single bidder always wins
+	winner, err := resolver.Resolve(uuid.New().String(), []string{bidder1})
+	require.NoError(t, err)
+	assert.Equal(t, bidder1, winner)
+}
+
+func TestContentionResolver_NoBidders(t *testing.T) {
+	_, err := NewContentionResolver().Resolve(uuid.New().String(), nil)
+	assert.Regexp(t, "PD011855", err)
+}
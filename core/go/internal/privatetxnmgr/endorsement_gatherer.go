@@ -18,6 +18,8 @@ package privatetxnmgr
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -25,32 +27,43 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
-func NewEndorsementGatherer(p persistence.Persistence, psc components.DomainSmartContract, dCtx components.DomainContext, keyMgr components.KeyManager) ptmgrtypes.EndorsementGatherer {
+// The payload types each signing algorithm prefix is known to support. Algorithms with a prefix
+// not in this list are not checked, as their capabilities are only known to the signing module.
+var knownAlgorithmPayloadTypes = map[string][]string{
+	algorithms.Prefix_ECDSA: {signpayloads.OPAQUE_TO_RSV, signpayloads.OPAQUE_KECCAK256_TO_RSV},
+}
+
+func NewEndorsementGatherer(p persistence.Persistence, psc components.DomainSmartContract, dCtx components.DomainContext, keyMgr components.KeyManager, requireKeyMatch bool) ptmgrtypes.EndorsementGatherer {
 	return &endorsementGatherer{
-		p:      p,
-		psc:    psc,
-		dCtx:   dCtx,
-		keyMgr: keyMgr,
+		p:               p,
+		psc:             psc,
+		dCtx:            dCtx,
+		keyMgr:          keyMgr,
+		requireKeyMatch: requireKeyMatch,
 	}
 }
 
 type endorsementGatherer struct {
-	p      persistence.Persistence
-	psc    components.DomainSmartContract
-	dCtx   components.DomainContext
-	keyMgr components.KeyManager
+	p               persistence.Persistence
+	psc             components.DomainSmartContract
+	dCtx            components.DomainContext
+	keyMgr          components.KeyManager
+	requireKeyMatch bool
 }
 
 func (e *endorsementGatherer) DomainContext() components.DomainContext {
 	return e.dCtx
 }
 
-func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, inputStates []*prototk.EndorsableState, readStates []*prototk.EndorsableState, outputStates []*prototk.EndorsableState, infoStates []*prototk.EndorsableState, partyName string, endorsementRequest *prototk.AttestationRequest) (*prototk.AttestationResult, *string, error) {
+func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, endorsements []*prototk.AttestationResult, inputStates []*prototk.EndorsableState, readStates []*prototk.EndorsableState, outputStates []*prototk.EndorsableState, infoStates []*prototk.EndorsableState, partyName string, endorsementRequest *prototk.AttestationRequest) (*prototk.AttestationResult, *string, error) {
 
 	unqualifiedLookup, err := tktypes.PrivateIdentityLocator(partyName).Identity(ctx)
 	if err != nil {
@@ -65,11 +78,19 @@ func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transaction
 		log.L(ctx).Error(errorMessage)
 		return nil, nil, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerInternalError, errorMessage)
 	}
+	if e.requireKeyMatch {
+		// Reject up front, rather than discovering after the domain has endorsed that we cannot sign
+		if err := e.checkKeyCapabilities(ctx, partyName, resolvedSigner, endorsementRequest); err != nil {
+			log.L(ctx).Error(err.Error())
+			return nil, nil, err
+		}
+	}
 	// Invoke the domain
 	endorseRes, err := e.psc.EndorseTransaction(e.dCtx, e.p.DB(), &components.PrivateTransactionEndorseRequest{
 		TransactionSpecification: transactionSpecification,
 		Verifiers:                verifiers,
 		Signatures:               signatures,
+		Endorsements:             endorsements,
 		InputStates:              inputStates,
 		ReadStates:               readStates,
 		OutputStates:             outputStates,
@@ -99,7 +120,8 @@ func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transaction
 		if endorseRes.RevertReason != nil {
 			revertReason = *endorseRes.RevertReason
 		}
-		return nil, confutil.P(revertReason), nil
+		// the result is returned with the reason, so that the rejection can be matched to the attestation request
+		return result, confutil.P(revertReason), nil
 	case prototk.EndorseTransactionResponse_SIGN:
 		// Build the signature
 		signaturePayload, err := e.keyMgr.Sign(ctx, resolvedSigner, endorsementRequest.PayloadType, endorseRes.Payload)
@@ -115,3 +137,19 @@ func (e *endorsementGatherer) GatherEndorsement(ctx context.Context, transaction
 
 	return result, nil, nil
 }
+
+func (e *endorsementGatherer) checkKeyCapabilities(ctx context.Context, partyName string, resolvedSigner *pldapi.KeyMappingAndVerifier, endorsementRequest *prototk.AttestationRequest) error {
+	keyAlgorithm := resolvedSigner.Verifier.Algorithm
+	if !strings.EqualFold(keyAlgorithm, endorsementRequest.Algorithm) {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerEndorserAlgorithmMismatch, partyName, keyAlgorithm, endorsementRequest.Algorithm)
+	}
+	if endorsementRequest.PayloadType == "" {
+		return nil
+	}
+	prefix, _, _ := strings.Cut(strings.ToLower(keyAlgorithm), ":")
+	payloadTypes, known := knownAlgorithmPayloadTypes[prefix]
+	if known && !slices.Contains(payloadTypes, endorsementRequest.PayloadType) {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerEndorserPayloadType, partyName, keyAlgorithm, endorsementRequest.PayloadType)
+	}
+	return nil
+}
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -43,12 +47,12 @@ func TestGatherEndorsementFailResolveKey(t *testing.T) {
 
 	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).Return(nil, fmt.Errorf("test error"))
 
-	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager)
+	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager, false)
 	endorsementReq := &prototk.AttestationRequest{
 		Algorithm:    algorithms.ECDSA_SECP256K1,
 		VerifierType: verifiers.ETH_ADDRESS,
 	}
-	_, _, err = eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
+	_, _, err = eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
 	require.ErrorContains(t, err, "PD011801: Unexpected error in engine failed to resolve key for party alice")
 }
 
@@ -71,7 +75,83 @@ func TestGatherEndorsementFailEndorseTransaction(t *testing.T) {
 			Verifier:           &pldapi.KeyVerifier{Verifier: "something"},
 		}, nil)
 	mocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("test error"))
-	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager)
-	_, _, err = eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
+	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager, false)
+	_, _, err = eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
 	require.ErrorContains(t, err, "PD011801: Unexpected error in engine failed to endorse for party alice")
 }
+
+func TestGatherEndorsementKeyCannotSignRejectedEarly(t *testing.T) {
+	ctx := context.Background()
+	mocks := &dependencyMocks{
+		domainSmartContract: componentmocks.NewDomainSmartContract(t),
+		keyManager:          componentmocks.NewKeyManager(t),
+	}
+	var err error
+	mocks.db, err = mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+
+	resolvedKey := &pldapi.KeyMappingAndVerifier{
+		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{Identifier: "alice"}},
+		Verifier:           &pldapi.KeyVerifier{Algorithm: algorithms.ECDSA_SECP256K1, Verifier: "something"},
+	}
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", mock.Anything, verifiers.ETH_ADDRESS).Return(resolvedKey, nil)
+
+	// The domain is never asked to endorse, so no EndorseTransaction expectation is registered on the mock
+	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager, true)
+	gather := func(endorsementReq *prototk.AttestationRequest) error {
+		_, _, err := eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
+		return err
+	}
+
+	err = gather(&prototk.AttestationRequest{
+		Algorithm:    algorithms.ECDSA_SECP256K1,
+		VerifierType: verifiers.ETH_ADDRESS,
+		PayloadType:  "domain:zeto:snark",
+	})
+	require.ErrorContains(t, err, "PD011840")
+
+	err = gather(&prototk.AttestationRequest{
+		Algorithm:    "domain:zeto:snark",
+		VerifierType: verifiers.ETH_ADDRESS,
+		PayloadType:  signpayloads.OPAQUE_TO_RSV,
+	})
+	require.ErrorContains(t, err, "PD011839")
+}
+
+func TestGatherEndorsementRevert(t *testing.T) {
+	ctx := context.Background()
+	mocks := &dependencyMocks{
+		domainSmartContract: componentmocks.NewDomainSmartContract(t),
+		keyManager:          componentmocks.NewKeyManager(t),
+	}
+	var err error
+	mocks.db, err = mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	endorsementReq := &prototk.AttestationRequest{
+		Name:            "notary",
+		AttestationType: prototk.AttestationType_ENDORSE,
+		Algorithm:       algorithms.ECDSA_SECP256K1,
+		VerifierType:    verifiers.ETH_ADDRESS,
+	}
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{
+			KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{Identifier: "alice"}},
+			Verifier:           &pldapi.KeyVerifier{Verifier: "something"},
+		}, nil)
+	endorser := &prototk.ResolvedVerifier{Lookup: "alice", Algorithm: algorithms.ECDSA_SECP256K1, Verifier: "something"}
+	mocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
+		Result:       prototk.EndorseTransactionResponse_REVERT,
+		RevertReason: confutil.P("insufficient funds"),
+		Endorser:     endorser,
+	}, nil)
+	eg := NewEndorsementGatherer(mocks.db.P, mocks.domainSmartContract, mocks.domainContext, mocks.keyManager, false)
+	result, revertReason, err := eg.GatherEndorsement(ctx, &prototk.TransactionSpecification{}, []*prototk.ResolvedVerifier{}, []*prototk.AttestationResult{}, []*prototk.AttestationResult{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, []*prototk.EndorsableState{}, "alice", endorsementReq)
+	require.NoError(t, err)
+	require.NotNil(t, revertReason)
+	assert.Equal(t, "insufficient funds", *revertReason)
+	// the rejection identifies the attestation request it is for, and the endorser
+	assert.Equal(t, "notary", result.Name)
+	assert.Equal(t, prototk.AttestationType_ENDORSE, result.AttestationType)
+	assert.Equal(t, endorser, result.Verifier)
+	assert.Nil(t, result.Payload)
+}
@@ -18,26 +18,50 @@ package privatetxnmgr
 
 import (
 	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 )
 
 type Graph interface {
-	AddTransaction(ctx context.Context, transaction ptmgrtypes.TransactionFlow)
+	// AddTransaction returns the dependencies between the transaction and the other transactions in the graph,
+	// if it was not already in the graph, so that they can be persisted
+	AddTransaction(ctx context.Context, transaction ptmgrtypes.TransactionFlow) []*syncpoints.SequencerDependency
 	GetDispatchableTransactions(ctx context.Context) (ptmgrtypes.DispatchableTransactions, error)
-	RemoveTransaction(ctx context.Context, txID string)
+	// RemoveTransaction returns true if there were any dependencies recorded for the transaction
+	RemoveTransaction(ctx context.Context, txID string) bool
 	RemoveTransactions(ctx context.Context, transactionsToRemove ptmgrtypes.DispatchableTransactions)
 	IncludesTransaction(txID string) bool
+	GetBlockedTransactions(ctx context.Context) []*components.BlockedPrivateTransaction
+	// RestoreDependencies reinstates dependencies that were persisted before a restart. Until the dependency of a restored
+	// dependency is back in the graph and dispatched (or removed) the dependant cannot be dispatched.
+	RestoreDependencies(ctx context.Context, dependencies []*syncpoints.SequencerDependency)
+	// ExpireRestoredDependencies removes all the dependencies on transactions that were restored before the given time,
+	// and have not come back into the graph since, returning the IDs of those transactions
+	ExpireRestoredDependencies(ctx context.Context, restoredBefore time.Time) []uuid.UUID
 }
 
 type graph struct {
 	// This is the source of truth for all transaction
 	allTransactions map[string]ptmgrtypes.TransactionFlow
 
+	// The dependencies recorded as each transaction was added to the graph, or restored after a restart.
+	// Map of dependant to dependency to the state IDs that connect them. These outlive the dependency leaving
+	// the graph only if they were restored before the dependency has been added back in.
+	dependencies map[string]map[string][]string
+	// When each transaction that restored dependencies are on was restored, until it is added back to the graph
+	restored map[string]time.Time
+
 	// all of the following are ephemeral and derived from allTransactions
 
 	// implement graph of transactions as an adjacency matrix where the values in the matrix is an array of state hashes that connect those transactions
@@ -48,18 +72,104 @@ type graph struct {
 	transactions       []ptmgrtypes.TransactionFlow
 	//map of transaction id to index in the transactions array
 	transactionIndex map[string]int
+	// for each transaction, the restored dependencies that are not (yet) in the graph
+	missingDependencies [][]*components.PrivateTxBlocker
+
+	// snapshot of the endorsed transactions that could not be dispatched on the most recent call to GetDispatchableTransactions.
+	// Protected by blockedMux as it is read from outside of the sequencer event loop
+	blockedMux sync.Mutex
+	blocked    []*components.BlockedPrivateTransaction
 }
 
 func NewGraph() Graph {
 	return &graph{
 		allTransactions: make(map[string]ptmgrtypes.TransactionFlow),
+		dependencies:    make(map[string]map[string][]string),
+		restored:        make(map[string]time.Time),
 	}
 }
 
-func (g *graph) AddTransaction(ctx context.Context, transaction ptmgrtypes.TransactionFlow) {
-	log.L(ctx).Debugf("Adding transaction %s to graph", transaction.ID().String())
-	g.allTransactions[transaction.ID().String()] = transaction
+func (g *graph) AddTransaction(ctx context.Context, transaction ptmgrtypes.TransactionFlow) []*syncpoints.SequencerDependency {
+	txID := transaction.ID().String()
+	delete(g.restored, txID)
+	if g.allTransactions[txID] != nil {
+		g.allTransactions[txID] = transaction
+		return nil
+	}
+	log.L(ctx).Debugf("Adding transaction %s to graph", txID)
+
+	// record the states that connect this transaction to each of the transactions already in the graph, in either direction
+	newDependencies := []*syncpoints.SequencerDependency{}
+	for _, other := range g.allTransactions {
+		for _, stateID := range transaction.InputStateIDs() {
+			if slices.Contains(other.OutputStateIDs(), stateID) {
+				newDependencies = append(newDependencies, g.recordDependency(transaction.ID(), other.ID(), stateID))
+			}
+		}
+		for _, stateID := range transaction.OutputStateIDs() {
+			if slices.Contains(other.InputStateIDs(), stateID) {
+				newDependencies = append(newDependencies, g.recordDependency(other.ID(), transaction.ID(), stateID))
+			}
+		}
+	}
+	g.allTransactions[txID] = transaction
+	return newDependencies
+}
 
+func (g *graph) recordDependency(dependant, dependency uuid.UUID, stateID string) *syncpoints.SequencerDependency {
+	dependencies := g.dependencies[dependant.String()]
+	if dependencies == nil {
+		dependencies = make(map[string][]string)
+		g.dependencies[dependant.String()] = dependencies
+	}
+	if !slices.Contains(dependencies[dependency.String()], stateID) {
+		dependencies[dependency.String()] = append(dependencies[dependency.String()], stateID)
+	}
+	return &syncpoints.SequencerDependency{
+		Dependant:  dependant,
+		Dependency: dependency,
+		StateID:    stateID,
+	}
+}
+
+func (g *graph) RestoreDependencies(ctx context.Context, dependencies []*syncpoints.SequencerDependency) {
+	log.L(ctx).Infof("Graph.RestoreDependencies Restoring %d dependencies", len(dependencies))
+	now := time.Now()
+	for _, d := range dependencies {
+		g.recordDependency(d.Dependant, d.Dependency, d.StateID)
+		if _, ok := g.restored[d.Dependency.String()]; !ok && g.allTransactions[d.Dependency.String()] == nil {
+			g.restored[d.Dependency.String()] = now
+		}
+	}
+}
+
+func (g *graph) ExpireRestoredDependencies(ctx context.Context, restoredBefore time.Time) []uuid.UUID {
+	var expired []uuid.UUID
+	for txID, restoredAt := range g.restored {
+		if restoredAt.Before(restoredBefore) {
+			log.L(ctx).Warnf("Graph.ExpireRestoredDependencies Transaction %s restored at %s has not returned to the graph, removing the dependencies on it", txID, restoredAt)
+			g.forgetDependencies(txID)
+			expired = append(expired, uuid.MustParse(txID))
+		}
+	}
+	return expired
+}
+
+// forgetDependencies removes all the recorded dependencies that involve the given transaction, returning true if there were any
+func (g *graph) forgetDependencies(txID string) bool {
+	delete(g.restored, txID)
+	found := len(g.dependencies[txID]) > 0
+	delete(g.dependencies, txID)
+	for dependant, dependencies := range g.dependencies {
+		if _, ok := dependencies[txID]; ok {
+			found = true
+			delete(dependencies, txID)
+			if len(dependencies) == 0 {
+				delete(g.dependencies, dependant)
+			}
+		}
+	}
+	return found
 }
 
 func (g *graph) IncludesTransaction(txID string) bool {
@@ -112,6 +222,32 @@ func (g *graph) buildMatrix(ctx context.Context) error {
 		}
 	}
 
+	// add in any recorded dependencies that are not visible from the states of the transactions in the graph, which is
+	// the case for dependencies restored after a restart where the dependency has not been added back to the graph yet
+	g.missingDependencies = make([][]*components.PrivateTxBlocker, len(g.transactions))
+	for dependantID, dependencies := range g.dependencies {
+		dependantIndex, ok := g.transactionIndex[dependantID]
+		if !ok {
+			continue
+		}
+		for dependencyID, states := range dependencies {
+			dependencyIndex, ok := g.transactionIndex[dependencyID]
+			if !ok {
+				g.missingDependencies[dependantIndex] = append(g.missingDependencies[dependantIndex], &components.PrivateTxBlocker{
+					TxID:   dependencyID,
+					Reason: components.PrivateTxBlockedDependency,
+					States: states,
+				})
+				continue
+			}
+			for _, stateID := range states {
+				if !slices.Contains(g.transactionsMatrix[dependencyIndex][dependantIndex], stateID) {
+					g.transactionsMatrix[dependencyIndex][dependantIndex] = append(g.transactionsMatrix[dependencyIndex][dependantIndex], stateID)
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -143,6 +279,7 @@ func (g *graph) GetDispatchableTransactions(ctx context.Context) (ptmgrtypes.Dis
 
 	// calcaulate the number of dependencies of each transaction
 	indegrees := make([]int, len(g.transactionsMatrix))
+	crossSigner := make([]bool, len(g.transactionsMatrix))
 	for _, dependants := range g.transactionsMatrix {
 		for dependant, states := range dependants {
 			if len(states) > 0 {
@@ -150,6 +287,10 @@ func (g *graph) GetDispatchableTransactions(ctx context.Context) (ptmgrtypes.Dis
 			}
 		}
 	}
+	// a dependency that is not in the graph will never be dispatched from the graph, so holds its dependant back until it is removed
+	for dependant, missing := range g.missingDependencies {
+		indegrees[dependant] += len(missing)
+	}
 
 	//find all independent transactions and add them to the queue
 	for txnIndex, indegree := range indegrees {
@@ -190,7 +331,12 @@ func (g *graph) GetDispatchableTransactions(ctx context.Context) (ptmgrtypes.Dis
 		for dependant, states := range dependencies {
 			if len(states) > 0 {
 				indegrees[dependant]--
-				if indegrees[dependant] == 0 {
+				if g.transactions[dependant].Signer() != g.transactions[nextTransaction].Signer() {
+					// there is no way to guarantee ordering on the base ledger across signing keys, so a dependant
+					// with a different signer waits until its dependencies have been dispatched in an earlier round
+					crossSigner[dependant] = true
+				}
+				if indegrees[dependant] == 0 && !crossSigner[dependant] {
 					if log.IsTraceEnabled() {
 						log.L(ctx).Tracef("Graph.GetDispatchableTransactions Transaction %s dependencies are being dispatched", g.transactions[dependant].ID().String())
 					}
@@ -201,24 +347,82 @@ func (g *graph) GetDispatchableTransactions(ctx context.Context) (ptmgrtypes.Dis
 		}
 	}
 
-	//TODO for now, we assume that all dispatchable transactions are to be dispatched by the same signing key
-	// in reality, we need to maintain subgraphs per signing key because there is no way to guarantee ordering
-	// across signing keys
+	g.recordBlockedTransactions(ctx, dispatchable)
 
+	// group by signing address, retaining the topological order within each group so that
+	// the nonces assigned for each signing address follow the dependency order
+	dispatchableTransactions := make(ptmgrtypes.DispatchableTransactions)
+	for _, txID := range dispatchable {
+		signingAddress := g.allTransactions[txID].Signer()
+		dispatchableTransactions[signingAddress] = append(dispatchableTransactions[signingAddress], txID)
+	}
 	if len(dispatchable) > 0 {
-		signingAddress := g.allTransactions[dispatchable[0]].Signer()
-		log.L(ctx).Debugf("Graph.GetDispatchableTransactions %d dispatchable transactions", len(dispatchable))
-		return map[string][]string{
-			signingAddress: dispatchable,
-		}, nil
+		log.L(ctx).Debugf("Graph.GetDispatchableTransactions %d dispatchable transactions for %d signing addresses", len(dispatchable), len(dispatchableTransactions))
+	} else {
+		log.L(ctx).Debug("Graph.GetDispatchableTransactions No dispatchable transactions")
+	}
+	return dispatchableTransactions, nil
+}
+
+// recordBlockedTransactions finds every endorsed transaction that was not dispatchable, and records
+// which of its dependencies are holding it back
+func (g *graph) recordBlockedTransactions(ctx context.Context, dispatchable []string) {
+	isDispatchable := make(map[string]bool, len(dispatchable))
+	for _, txID := range dispatchable {
+		isDispatchable[txID] = true
+	}
+	blocked := []*components.BlockedPrivateTransaction{}
+	for dependantIndex, dependant := range g.transactions {
+		dependantID := dependant.ID().String()
+		if isDispatchable[dependantID] || !dependant.IsEndorsed(ctx) {
+			continue
+		}
+		blockedTx := &components.BlockedPrivateTransaction{TxID: dependantID}
+		blockedTx.BlockedBy = append(blockedTx.BlockedBy, g.missingDependencies[dependantIndex]...)
+		for minterIndex, minter := range g.transactions {
+			states := g.transactionsMatrix[minterIndex][dependantIndex]
+			if len(states) == 0 {
+				continue
+			}
+			reason := components.PrivateTxBlockedDependency
+			switch {
+			case isDispatchable[minter.ID().String()]:
+				if minter.Signer() == dependant.Signer() {
+					continue
+				}
+				reason = components.PrivateTxBlockedSigningAddress
+			case minter.IsEndorsed(ctx):
+				reason = components.PrivateTxBlockedNonceOrdering
+			}
+			blockedTx.BlockedBy = append(blockedTx.BlockedBy, &components.PrivateTxBlocker{
+				TxID:   minter.ID().String(),
+				Reason: reason,
+				States: states,
+			})
+		}
+		sort.Slice(blockedTx.BlockedBy, func(i, j int) bool { return blockedTx.BlockedBy[i].TxID < blockedTx.BlockedBy[j].TxID })
+		log.L(ctx).Debugf("Graph.GetDispatchableTransactions Transaction %s is endorsed but blocked by %d transactions", dependantID, len(blockedTx.BlockedBy))
+		blocked = append(blocked, blockedTx)
 	}
-	log.L(ctx).Debug("Graph.GetDispatchableTransactions No dispatchable transactions")
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].TxID < blocked[j].TxID })
 
-	return map[string][]string{}, nil
+	g.blockedMux.Lock()
+	defer g.blockedMux.Unlock()
+	g.blocked = blocked
 }
-func (g *graph) RemoveTransaction(ctx context.Context, txID string) {
+
+func (g *graph) GetBlockedTransactions(ctx context.Context) []*components.BlockedPrivateTransaction {
+	g.blockedMux.Lock()
+	defer g.blockedMux.Unlock()
+	blocked := make([]*components.BlockedPrivateTransaction, len(g.blocked))
+	copy(blocked, g.blocked)
+	return blocked
+}
+
+func (g *graph) RemoveTransaction(ctx context.Context, txID string) bool {
 	log.L(ctx).Debugf("Graph.RemoveTransaction Removing transaction %s from graph", txID)
 	delete(g.allTransactions, txID)
+	return g.forgetDependencies(txID)
 }
 
 func (g *graph) RemoveTransactions(ctx context.Context, transactionsToRemove ptmgrtypes.DispatchableTransactions) {
@@ -237,6 +441,8 @@ func (g *graph) RemoveTransactions(ctx context.Context, transactionsToRemove ptm
 			} else {
 				delete(g.allTransactions, txID)
 			}
+			// the persisted dependencies are removed with the dispatch
+			g.forgetDependencies(txID)
 		}
 	}
 }
@@ -19,8 +19,12 @@ package privatetxnmgr
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
 	"github.com/kaleido-io/paladin/core/mocks/privatetxnmgrmocks"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
@@ -250,3 +254,244 @@ func TestScenario2(t *testing.T) {
 	assert.True(t, isBefore(TxID3.String(), TxID5.String()))
 
 }
+
+func TestGetBlockedTransactions(t *testing.T) {
+	// 0 is not endorsed
+	// 1 is endorsed and depends on 0, so is blocked by the dependency
+	// 2 is endorsed and depends on 1, so is blocked behind 1 to preserve nonce ordering
+	// 3 is endorsed and independent, so is dispatched
+	// 4 is not endorsed and depends on 3, so is not reported as it is not ready
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signer := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	mockTransactionProcessor0 := NewMockTransactionProcessorForTesting(t, TxID0, []string{}, []string{"S0"}, false, signer)
+
+	TxID1 := uuid.New()
+	mockTransactionProcessor1 := NewMockTransactionProcessorForTesting(t, TxID1, []string{"S0"}, []string{"S1"}, true, signer)
+
+	TxID2 := uuid.New()
+	mockTransactionProcessor2 := NewMockTransactionProcessorForTesting(t, TxID2, []string{"S1"}, []string{"S2"}, true, signer)
+
+	TxID3 := uuid.New()
+	mockTransactionProcessor3 := NewMockTransactionProcessorForTesting(t, TxID3, []string{}, []string{"S3"}, true, signer)
+
+	TxID4 := uuid.New()
+	mockTransactionProcessor4 := NewMockTransactionProcessorForTesting(t, TxID4, []string{"S3"}, []string{"S4"}, false, signer)
+
+	assert.Empty(t, testGraph.GetBlockedTransactions(ctx))
+
+	testGraph.AddTransaction(ctx, mockTransactionProcessor0)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor1)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor2)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor3)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor4)
+
+	dispatchable, err := testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID3.String()}, dispatchable[signer])
+
+	blocked := map[string]*components.BlockedPrivateTransaction{}
+	for _, b := range testGraph.GetBlockedTransactions(ctx) {
+		blocked[b.TxID] = b
+	}
+	require.Len(t, blocked, 2)
+	assert.Equal(t, []*components.PrivateTxBlocker{
+		{TxID: TxID0.String(), Reason: components.PrivateTxBlockedDependency, States: []string{"S0"}},
+	}, blocked[TxID1.String()].BlockedBy)
+	assert.Equal(t, []*components.PrivateTxBlocker{
+		{TxID: TxID1.String(), Reason: components.PrivateTxBlockedNonceOrdering, States: []string{"S1"}},
+	}, blocked[TxID2.String()].BlockedBy)
+
+	// once the blocker is removed from the graph, everything can be dispatched
+	testGraph.RemoveTransaction(ctx, TxID0.String())
+	testGraph.RemoveTransaction(ctx, TxID3.String())
+	dispatchable, err = testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID1.String(), TxID2.String()}, dispatchable[signer])
+	assert.Empty(t, testGraph.GetBlockedTransactions(ctx))
+
+}
+
+func TestGetDispatchableTransactionsMultipleSigners(t *testing.T) {
+	// 0 and 1 are independent with signer A, 2 depends on 0 with signer A
+	// 3 is independent with signer B
+	// 4 depends on 1 with signer B, so must wait for 1 to be dispatched first
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signerA := tktypes.RandHex(32)
+	signerB := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	testGraph.AddTransaction(ctx, NewMockTransactionProcessorForTesting(t, TxID0, []string{}, []string{"S0"}, true, signerA))
+	TxID1 := uuid.New()
+	testGraph.AddTransaction(ctx, NewMockTransactionProcessorForTesting(t, TxID1, []string{}, []string{"S1"}, true, signerA))
+	TxID2 := uuid.New()
+	testGraph.AddTransaction(ctx, NewMockTransactionProcessorForTesting(t, TxID2, []string{"S0"}, []string{"S2"}, true, signerA))
+	TxID3 := uuid.New()
+	testGraph.AddTransaction(ctx, NewMockTransactionProcessorForTesting(t, TxID3, []string{}, []string{"S3"}, true, signerB))
+	TxID4 := uuid.New()
+	testGraph.AddTransaction(ctx, NewMockTransactionProcessorForTesting(t, TxID4, []string{"S1"}, []string{"S4"}, true, signerB))
+
+	dispatchable, err := testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	require.Len(t, dispatchable, 2)
+	require.Len(t, dispatchable[signerA], 3)
+	assert.Equal(t, TxID2.String(), dispatchable[signerA][2])
+	assert.Equal(t, []string{TxID3.String()}, dispatchable[signerB])
+
+	blocked := testGraph.GetBlockedTransactions(ctx)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, TxID4.String(), blocked[0].TxID)
+	assert.Equal(t, []*components.PrivateTxBlocker{
+		{TxID: TxID1.String(), Reason: components.PrivateTxBlockedSigningAddress, States: []string{"S1"}},
+	}, blocked[0].BlockedBy)
+
+	// once the dependency has been dispatched, the dependant is dispatched with its own signer
+	testGraph.RemoveTransactions(ctx, dispatchable)
+	dispatchable, err = testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ptmgrtypes.DispatchableTransactions{signerB: {TxID4.String()}}, dispatchable)
+	assert.Empty(t, testGraph.GetBlockedTransactions(ctx))
+}
+
+func TestAddTransactionRecordsDependencies(t *testing.T) {
+	// 1 depends on 0, and is added first
+	// 2 depends on 1 through two states
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signer := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	mockTransactionProcessor0 := NewMockTransactionProcessorForTesting(t, TxID0, []string{}, []string{"S0"}, false, signer)
+
+	TxID1 := uuid.New()
+	mockTransactionProcessor1 := NewMockTransactionProcessorForTesting(t, TxID1, []string{"S0"}, []string{"S1A", "S1B"}, false, signer)
+
+	TxID2 := uuid.New()
+	mockTransactionProcessor2 := NewMockTransactionProcessorForTesting(t, TxID2, []string{"S1A", "S1B"}, []string{}, false, signer)
+
+	assert.Empty(t, testGraph.AddTransaction(ctx, mockTransactionProcessor1))
+	assert.Equal(t, []*syncpoints.SequencerDependency{
+		{Dependant: TxID1, Dependency: TxID0, StateID: "S0"},
+	}, testGraph.AddTransaction(ctx, mockTransactionProcessor0))
+	assert.Equal(t, []*syncpoints.SequencerDependency{
+		{Dependant: TxID2, Dependency: TxID1, StateID: "S1A"},
+		{Dependant: TxID2, Dependency: TxID1, StateID: "S1B"},
+	}, testGraph.AddTransaction(ctx, mockTransactionProcessor2))
+
+	// adding again is a no-op
+	assert.Nil(t, testGraph.AddTransaction(ctx, mockTransactionProcessor2))
+
+	// removing 1 forgets all of its dependencies, leaving 0 with none
+	assert.True(t, testGraph.RemoveTransaction(ctx, TxID1.String()))
+	assert.False(t, testGraph.RemoveTransaction(ctx, TxID0.String()))
+	assert.False(t, testGraph.RemoveTransaction(ctx, TxID1.String()))
+}
+
+func TestRestoredDependencies(t *testing.T) {
+	// after a restart, 1 is restored as depending on 0, and 2 on 1
+	// 1 and 2 are endorsed and added back before 0, so must be held back until 0 is added back and dispatched
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signer := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	mockTransactionProcessor0 := NewMockTransactionProcessorForTesting(t, TxID0, []string{}, []string{"S0"}, true, signer)
+
+	TxID1 := uuid.New()
+	mockTransactionProcessor1 := NewMockTransactionProcessorForTesting(t, TxID1, []string{"S0"}, []string{"S1"}, true, signer)
+
+	TxID2 := uuid.New()
+	mockTransactionProcessor2 := NewMockTransactionProcessorForTesting(t, TxID2, []string{"S1"}, []string{}, true, signer)
+
+	testGraph.RestoreDependencies(ctx, []*syncpoints.SequencerDependency{
+		{Dependant: TxID1, Dependency: TxID0, StateID: "S0"},
+		{Dependant: TxID2, Dependency: TxID1, StateID: "S1"},
+	})
+
+	testGraph.AddTransaction(ctx, mockTransactionProcessor2)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor1)
+	dispatchable, err := testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dispatchable)
+	blocked := testGraph.GetBlockedTransactions(ctx)
+	require.Len(t, blocked, 2)
+	blockedBy := map[string][]*components.PrivateTxBlocker{}
+	for _, b := range blocked {
+		blockedBy[b.TxID] = b.BlockedBy
+	}
+	assert.Equal(t, []*components.PrivateTxBlocker{
+		{TxID: TxID0.String(), Reason: components.PrivateTxBlockedDependency, States: []string{"S0"}},
+	}, blockedBy[TxID1.String()])
+	assert.Equal(t, []*components.PrivateTxBlocker{
+		{TxID: TxID1.String(), Reason: components.PrivateTxBlockedNonceOrdering, States: []string{"S1"}},
+	}, blockedBy[TxID2.String()])
+
+	testGraph.AddTransaction(ctx, mockTransactionProcessor0)
+	dispatchable, err = testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID0.String(), TxID1.String(), TxID2.String()}, dispatchable[signer])
+
+	// once dispatched, the dependencies are forgotten
+	testGraph.RemoveTransactions(ctx, dispatchable)
+	assert.False(t, testGraph.RemoveTransaction(ctx, TxID1.String()))
+}
+
+func TestRestoredDependencyRemoved(t *testing.T) {
+	// 1 is restored as depending on 0, which completes without being added back to the graph
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signer := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	TxID1 := uuid.New()
+	mockTransactionProcessor1 := NewMockTransactionProcessorForTesting(t, TxID1, []string{"S0"}, []string{"S1"}, true, signer)
+
+	testGraph.RestoreDependencies(ctx, []*syncpoints.SequencerDependency{
+		{Dependant: TxID1, Dependency: TxID0, StateID: "S0"},
+	})
+	testGraph.AddTransaction(ctx, mockTransactionProcessor1)
+	dispatchable, err := testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dispatchable)
+
+	assert.True(t, testGraph.RemoveTransaction(ctx, TxID0.String()))
+	dispatchable, err = testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID1.String()}, dispatchable[signer])
+}
+
+func TestRestoredDependencyExpired(t *testing.T) {
+	// 2 is restored as depending on 0 and 1, but only 1 comes back after the restart
+	ctx := context.Background()
+	testGraph := NewGraph()
+	signer := tktypes.RandHex(32)
+
+	TxID0 := uuid.New()
+	TxID1 := uuid.New()
+	TxID2 := uuid.New()
+	mockTransactionProcessor1 := NewMockTransactionProcessorForTesting(t, TxID1, []string{}, []string{"S1"}, true, signer)
+	mockTransactionProcessor2 := NewMockTransactionProcessorForTesting(t, TxID2, []string{"S0", "S1"}, []string{}, true, signer)
+
+	testGraph.RestoreDependencies(ctx, []*syncpoints.SequencerDependency{
+		{Dependant: TxID2, Dependency: TxID0, StateID: "S0"},
+		{Dependant: TxID2, Dependency: TxID1, StateID: "S1"},
+	})
+	testGraph.AddTransaction(ctx, mockTransactionProcessor1)
+	testGraph.AddTransaction(ctx, mockTransactionProcessor2)
+
+	// nothing was restored before this
+	assert.Empty(t, testGraph.ExpireRestoredDependencies(ctx, time.Now().Add(-1*time.Hour)))
+	dispatchable, err := testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID1.String()}, dispatchable[signer])
+
+	// only the dependency on the transaction that did not come back expires
+	assert.Equal(t, []uuid.UUID{TxID0}, testGraph.ExpireRestoredDependencies(ctx, time.Now().Add(1*time.Hour)))
+	assert.Empty(t, testGraph.(*graph).restored)
+	dispatchable, err = testGraph.GetDispatchableTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{TxID1.String(), TxID2.String()}, dispatchable[signer])
+}
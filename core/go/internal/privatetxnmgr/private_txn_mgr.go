@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/core/internal/components"
@@ -54,11 +56,27 @@ type privateTxManager struct {
 	endorsementGatherers           map[string]ptmgrtypes.EndorsementGatherer
 	components                     components.AllComponents
 	nodeName                       string
-	subscribers                    []components.PrivateTxEventSubscriber
+	subscribers                    []*eventSubscriber
 	subscribersLock                sync.Mutex
+	subscriberBufferSize           int
+	subscriberOverflowPolicy       pldconf.SubscriberOverflowPolicy
+	awaitingConfirmation           map[string]*components.TransactionDispatchedEvent // dispatched transactions by ID, protected by subscribersLock
 	syncPoints                     syncpoints.SyncPoints
 	stateDistributer               statedistribution.StateDistributer
 	preparedTransactionDistributer preparedtxdistribution.PreparedTransactionDistributer
+	maxCallDepth                   int
+	domainResolutionGracePeriod    time.Duration
+}
+
+// Interval between attempts to resolve the domain of a new transaction, within the grace period
+const domainResolutionRetryInterval = 100 * time.Millisecond
+
+// The depth of nested CallPrivateSmartContract calls is tracked on the context
+type callDepthKey struct{}
+
+func callDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(callDepthKey{}).(int)
+	return depth
 }
 
 // Init implements Engine.
@@ -67,6 +85,33 @@ func (p *privateTxManager) PreInit(c components.PreInitComponents) (*components.
 }
 
 func (p *privateTxManager) PostInit(c components.AllComponents) error {
+	switch p.subscriberOverflowPolicy {
+	case pldconf.SubscriberOverflowPolicyBlock, pldconf.SubscriberOverflowPolicyDropOldest, pldconf.SubscriberOverflowPolicyDisconnect:
+	default:
+		return i18n.NewError(p.ctx, msgs.MsgPrivateTxManagerInvalidOverflowPolicy, p.subscriberOverflowPolicy)
+	}
+	endorsementRevertPolicy := pldconf.EndorsementRevertPolicy(confutil.StringNotEmpty(p.config.Sequencer.EndorsementRevertPolicy, *pldconf.PrivateTxManagerDefaults.Sequencer.EndorsementRevertPolicy))
+	switch endorsementRevertPolicy {
+	case pldconf.EndorsementRevertPolicyFail, pldconf.EndorsementRevertPolicyReassemble:
+	default:
+		return i18n.NewError(p.ctx, msgs.MsgPrivateTxManagerInvalidEndorsementRevertPolicy, endorsementRevertPolicy)
+	}
+	flushFailurePolicy := pldconf.FlushFailurePolicy(confutil.StringNotEmpty(p.config.Sequencer.FlushFailurePolicy, *pldconf.PrivateTxManagerDefaults.Sequencer.FlushFailurePolicy))
+	switch flushFailurePolicy {
+	case pldconf.FlushFailurePolicyFail, pldconf.FlushFailurePolicyReassemble:
+	default:
+		return i18n.NewError(p.ctx, msgs.MsgPrivateTxManagerInvalidFlushFailurePolicy, flushFailurePolicy)
+	}
+	submitSignerPoolSize := confutil.Int(p.config.Sequencer.SubmitSigners.PoolSize, *pldconf.PrivateTxManagerDefaults.Sequencer.SubmitSigners.PoolSize)
+	submitSignerSelection := pldconf.SubmitSignerSelection(confutil.StringNotEmpty(p.config.Sequencer.SubmitSigners.Selection, *pldconf.PrivateTxManagerDefaults.Sequencer.SubmitSigners.Selection))
+	switch submitSignerSelection {
+	case pldconf.SubmitSignerSelectionRoundRobin, pldconf.SubmitSignerSelectionLeastLoaded:
+	default:
+		return i18n.NewError(p.ctx, msgs.MsgPrivateTxManagerInvalidSubmitSignerPool, submitSignerPoolSize, submitSignerSelection)
+	}
+	if submitSignerPoolSize < 1 {
+		return i18n.NewError(p.ctx, msgs.MsgPrivateTxManagerInvalidSubmitSignerPool, submitSignerPoolSize, submitSignerSelection)
+	}
 	p.components = c
 	p.nodeName = p.components.TransportManager().LocalNodeName()
 	p.syncPoints = syncpoints.NewSyncPoints(p.ctx, &p.config.Writer, c.Persistence(), c.TxManager())
@@ -104,14 +149,25 @@ func (p *privateTxManager) Start() error {
 func (p *privateTxManager) Stop() {
 	p.stateDistributer.Stop(p.ctx)
 
+	p.subscribersLock.Lock()
+	defer p.subscribersLock.Unlock()
+	for _, subscriber := range p.subscribers {
+		subscriber.close()
+	}
+	p.subscribers = nil
 }
 
 func NewPrivateTransactionMgr(ctx context.Context, config *pldconf.PrivateTxManagerConfig) components.PrivateTxManager {
 	p := &privateTxManager{
-		config:               config,
-		sequencers:           make(map[string]*Sequencer),
-		endorsementGatherers: make(map[string]ptmgrtypes.EndorsementGatherer),
-		subscribers:          make([]components.PrivateTxEventSubscriber, 0),
+		config:                      config,
+		sequencers:                  make(map[string]*Sequencer),
+		endorsementGatherers:        make(map[string]ptmgrtypes.EndorsementGatherer),
+		subscribers:                 make([]*eventSubscriber, 0),
+		subscriberBufferSize:        confutil.IntMin(config.SubscriberBuffer.Size, 1, *pldconf.PrivateTxManagerDefaults.SubscriberBuffer.Size),
+		subscriberOverflowPolicy:    pldconf.SubscriberOverflowPolicy(confutil.StringNotEmpty(config.SubscriberBuffer.OverflowPolicy, *pldconf.PrivateTxManagerDefaults.SubscriberBuffer.OverflowPolicy)),
+		awaitingConfirmation:        make(map[string]*components.TransactionDispatchedEvent),
+		maxCallDepth:                confutil.IntMin(config.MaxCallDepth, 1, *pldconf.PrivateTxManagerDefaults.MaxCallDepth),
+		domainResolutionGracePeriod: confutil.DurationMin(config.DomainResolutionGracePeriod, 0, *pldconf.PrivateTxManagerDefaults.DomainResolutionGracePeriod),
 	}
 	p.ctx, p.ctxCancel = context.WithCancel(ctx)
 	return p
@@ -189,7 +245,7 @@ func (p *privateTxManager) getEndorsementGathererForContract(ctx context.Context
 	if p.endorsementGatherers[contractAddr.String()] == nil {
 		// TODO: Consider scope of state in privateTxManager threading model
 		dCtx := p.components.StateManager().NewDomainContext(p.ctx /* background context */, domainSmartContract.Domain(), contractAddr)
-		endorsementGatherer := NewEndorsementGatherer(p.components.Persistence(), domainSmartContract, dCtx, p.components.KeyManager())
+		endorsementGatherer := NewEndorsementGatherer(p.components.Persistence(), domainSmartContract, dCtx, p.components.KeyManager(), confutil.Bool(p.config.RequireEndorsementKeyMatch, false))
 		p.endorsementGatherers[contractAddr.String()] = endorsementGatherer
 	}
 	return p.endorsementGatherers[contractAddr.String()], nil
@@ -223,6 +279,7 @@ func (p *privateTxManager) HandleNewTx(ctx context.Context, txi *components.Vali
 			Intent:   intent,
 		},
 		PublicTxOptions: tx.PublicTxOptions,
+		DependsOn:       txi.DependsOn,
 	})
 }
 
@@ -246,7 +303,7 @@ func (p *privateTxManager) handleNewTx(ctx context.Context, tx *components.Priva
 	}
 
 	contractAddr := tx.Inputs.To
-	domainAPI, err := p.components.DomainManager().GetSmartContractByAddress(ctx, contractAddr)
+	domainAPI, err := p.resolveNewTxSmartContract(ctx, contractAddr)
 	if err != nil {
 		return err
 	}
@@ -265,6 +322,9 @@ func (p *privateTxManager) handleNewTx(ctx context.Context, tx *components.Priva
 	if tx.PreAssembly == nil {
 		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerInternalError, "PreAssembly is nil")
 	}
+	if tx.PreAssembly.Label != "" {
+		p.recordTransactionLabel(ctx, tx.ID, tx.PreAssembly.Label)
+	}
 
 	oc, err := p.getSequencerForContract(ctx, contractAddr, domainAPI)
 	if err != nil {
@@ -277,6 +337,47 @@ func (p *privateTxManager) handleNewTx(ctx context.Context, tx *components.Priva
 	return nil
 }
 
+// The label is only for display, so failing to record it does not fail the transaction
+func (p *privateTxManager) recordTransactionLabel(ctx context.Context, txID uuid.UUID, label string) {
+	if err := p.components.TxManager().SetTransactionLabel(ctx, p.components.Persistence().DB(), txID, label); err != nil {
+		log.L(ctx).Warnf("Failed to record label for transaction %s: %s", txID, err)
+	}
+}
+
+// resolveNewTxSmartContract retries resolution of the contract for a new transaction until the grace period
+// expires, as the domain might still be loading when the transaction is submitted during startup
+func (p *privateTxManager) resolveNewTxSmartContract(ctx context.Context, contractAddr tktypes.EthAddress) (components.DomainSmartContract, error) {
+	deadline := time.Now().Add(p.domainResolutionGracePeriod)
+	for {
+		domainAPI, err := p.components.DomainManager().GetSmartContractByAddress(ctx, contractAddr)
+		if err == nil || !isRetryableContractResolutionError(err) || time.Now().Add(domainResolutionRetryInterval).After(deadline) {
+			return domainAPI, err
+		}
+		log.L(ctx).Warnf("Waiting for domain of contract %s to become available: %s", contractAddr, err)
+		select {
+		case <-ctx.Done():
+			return nil, i18n.NewError(ctx, msgs.MsgContextCanceled)
+		case <-time.After(domainResolutionRetryInterval):
+		}
+	}
+}
+
+// Only a contract that is not (yet) known, or an error without a message key (such as a failure to reach
+// the database) might resolve itself in the grace period. Anything else, such as a contract the domain has
+// rejected as invalid, is returned straight away.
+func isRetryableContractResolutionError(err error) bool {
+	ffErr, ok := err.(i18n.FFError)
+	if !ok {
+		return true
+	}
+	switch ffErr.MessageKey() {
+	case msgs.MsgDomainContractNotFoundByAddr, msgs.MsgDomainNotConfiguredForPSC:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *privateTxManager) validateDelegatedTransaction(ctx context.Context, tx *components.PrivateTransaction) error {
 	log.L(ctx).Debugf("Validating delegated transaction: %v", tx)
 	if tx.Inputs == nil || tx.Inputs.Domain == "" {
@@ -454,6 +555,11 @@ func (p *privateTxManager) evaluateDeployment(ctx context.Context, domain compon
 	}()
 	if len(pubBatch.Rejected()) > 0 {
 		// We do not handle partial success - roll everything back
+		if err := p.components.Persistence().DB().Transaction(func(dbTX *gorm.DB) error {
+			return pubBatch.PersistRejected(ctx, dbTX)
+		}); err != nil {
+			return p.revertDeploy(ctx, tx, err)
+		}
 		return p.revertDeploy(ctx, tx, i18n.WrapError(ctx, pubBatch.Rejected()[0].RejectedError(), msgs.MsgPrivateTxManagerInternalError, "Submission batch rejected "))
 	}
 
@@ -481,11 +587,11 @@ func (p *privateTxManager) evaluateDeployment(ctx context.Context, domain compon
 
 	completed = true
 
-	p.publishToSubscribers(ctx, &components.TransactionDispatchedEvent{
+	p.publishDispatched(ctx, &components.TransactionDispatchedEvent{
 		TransactionID:  tx.ID.String(),
 		Nonce:          uint64(0), /*TODO*/
 		SigningAddress: tx.Signer,
-	})
+	}, true)
 
 	return nil
 
@@ -507,6 +613,71 @@ func (p *privateTxManager) GetTxStatus(ctx context.Context, domainAddress string
 
 }
 
+// Attestations are reported from memory for transactions in flight, and those that have been dispatched until their
+// base ledger transaction is confirmed. After that, or a restart, they are read from the copy persisted with the dispatch.
+func (p *privateTxManager) GetTxAttestations(ctx context.Context, domainAddress string, txID string) (*components.PrivateTxAttestations, error) {
+	p.sequencersLock.RLock()
+	targetSequencer := p.sequencers[domainAddress]
+	p.sequencersLock.RUnlock()
+	if targetSequencer != nil {
+		if attestations := targetSequencer.GetTxAttestations(ctx, txID); attestations != nil {
+			return attestations, nil
+		}
+	}
+	contractAddress, err := tktypes.ParseEthAddress(domainAddress)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := uuid.Parse(txID)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerInternalError, "Invalid transaction ID")
+	}
+	attestations, err := p.syncPoints.GetTransactionAttestations(ctx, *contractAddress, transactionID)
+	if err == nil && attestations == nil {
+		err = i18n.NewError(ctx, msgs.MsgPrivateTxManagerInternalError, "Transaction not found")
+	}
+	return attestations, err
+}
+
+func (p *privateTxManager) GetBlockedTransactions(ctx context.Context, domainAddress string) ([]*components.BlockedPrivateTransaction, error) {
+	p.sequencersLock.RLock()
+	defer p.sequencersLock.RUnlock()
+	targetSequencer := p.sequencers[domainAddress]
+	if targetSequencer == nil {
+		// nothing is in flight for this contract, so nothing can be blocked
+		return []*components.BlockedPrivateTransaction{}, nil
+	}
+	return targetSequencer.GetBlockedTransactions(ctx), nil
+}
+
+// GetTxStats aggregates what we hold in memory across all the sequencers, so is reset on restart
+func (p *privateTxManager) GetTxStats(ctx context.Context, since tktypes.Timestamp) *components.PrivateTxStats {
+	stats := &components.PrivateTxStats{
+		ByStatus:     make(map[string]int),
+		StageLatency: make(map[components.PrivateTxStage]*components.PrivateTxStageLatency),
+	}
+	p.sequencersLock.RLock()
+	defer p.sequencersLock.RUnlock()
+	for _, sequencer := range p.sequencers {
+		sequencer.GetTxStats(ctx, since, stats)
+	}
+	return stats
+}
+
+// CancelTransaction stops a transaction that is in flight on this node before it is dispatched to the base ledger,
+// finalizing it with a failure receipt. Nothing has been submitted to the public transaction manager at that point,
+// so no nonce is held for it. Cancelling a transaction that is already being cancelled succeeds.
+func (p *privateTxManager) CancelTransaction(ctx context.Context, contractAddr string, txID uuid.UUID) error {
+	p.sequencersLock.RLock()
+	targetSequencer := p.sequencers[contractAddr]
+	p.sequencersLock.RUnlock()
+	if targetSequencer == nil {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelNotInFlight, txID, contractAddr)
+	}
+	// we must not hold the lock while we wait for the sequencer loop
+	return targetSequencer.CancelTransaction(ctx, txID.String())
+}
+
 func (p *privateTxManager) HandleNewEvent(ctx context.Context, event ptmgrtypes.PrivateTransactionEvent) {
 	p.sequencersLock.RLock()
 	defer p.sequencersLock.RUnlock()
@@ -550,12 +721,22 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 		return
 	}
 
-	attestationRequestAny := endorsementRequest.GetAttestationRequest()
-	attestationRequest := &prototk.AttestationRequest{}
-	err = attestationRequestAny.UnmarshalTo(attestationRequest)
-	if err != nil {
-		log.L(ctx).Errorf("Failed to unmarshal attestation request: %s", err)
-		return
+	// Requests for several parties on this node can arrive in a single message
+	partyRequests := endorsementRequest.GetRequests()
+	if len(partyRequests) == 0 {
+		partyRequests = []*pbEngine.EndorsementRequestParty{{
+			AttestationRequest: endorsementRequest.GetAttestationRequest(),
+			Party:              endorsementRequest.GetParty(),
+		}}
+	}
+	attestationRequests := make([]*prototk.AttestationRequest, len(partyRequests))
+	for i, r := range partyRequests {
+		attestationRequests[i] = &prototk.AttestationRequest{}
+		err = r.GetAttestationRequest().UnmarshalTo(attestationRequests[i])
+		if err != nil {
+			log.L(ctx).Errorf("Failed to unmarshal attestation request: %s", err)
+			return
+		}
 	}
 
 	verifiersAny := endorsementRequest.GetVerifiers()
@@ -579,6 +760,16 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 		}
 	}
 
+	endorsements := make([]*prototk.AttestationResult, len(endorsementRequest.GetEndorsements()))
+	for i, e := range endorsementRequest.GetEndorsements() {
+		endorsements[i] = &prototk.AttestationResult{}
+		err = e.UnmarshalTo(endorsements[i])
+		if err != nil {
+			log.L(ctx).Errorf("Failed to unmarshal prior endorsement: %s", err)
+			return
+		}
+	}
+
 	inputStates := make([]*prototk.EndorsableState, len(endorsementRequest.GetInputStates()))
 	for i, s := range endorsementRequest.GetInputStates() {
 		inputStates[i] = &prototk.EndorsableState{}
@@ -619,21 +810,46 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 		}
 	}
 
-	endorsement, revertReason, err := endorsementGatherer.GatherEndorsement(ctx,
-		transactionSpecification,
-		verifiers,
-		signatures,
-		inputStates,
-		readStates,
-		outputStates,
-		infoStates,
-		endorsementRequest.GetParty(),
-		attestationRequest)
-	if err != nil {
-		log.L(ctx).Errorf("Failed to gather endorsement: %s", err)
+	p.resolveEndorsementContention(ctx, *contractAddress, replyTo, endorsementRequest.TransactionId, inputStates)
+
+	for i, r := range partyRequests {
+		endorsement, revertReason, err := endorsementGatherer.GatherEndorsement(ctx,
+			transactionSpecification,
+			verifiers,
+			signatures,
+			endorsements,
+			inputStates,
+			readStates,
+			outputStates,
+			infoStates,
+			r.GetParty(),
+			attestationRequests[i])
+		if err != nil {
+			// the other parties can still respond, and the coordinator will re-send the request for this one
+			log.L(ctx).Errorf("Failed to gather endorsement for party %s: %s", r.GetParty(), err)
+			continue
+		}
+		p.sendEndorsementResponse(ctx, contractAddressString, endorsementRequest.TransactionId, endorsement, revertReason, replyTo)
+	}
+}
+
+// A request to endorse a transaction is how we learn that another coordinator is spending states,
+// so we check whether it is bidding for any of the states our own transactions are spending
+func (p *privateTxManager) resolveEndorsementContention(ctx context.Context, contractAddr tktypes.EthAddress, coordinatorNode, transactionID string, inputStates []*prototk.EndorsableState) {
+	p.sequencersLock.RLock()
+	sequencer := p.sequencers[contractAddr.String()]
+	p.sequencersLock.RUnlock()
+	if sequencer == nil {
 		return
 	}
+	inputStateIDs := make([]string, len(inputStates))
+	for i, s := range inputStates {
+		inputStateIDs[i] = s.Id
+	}
+	sequencer.ResolveEndorsementContention(ctx, coordinatorNode, transactionID, inputStateIDs)
+}
 
+func (p *privateTxManager) sendEndorsementResponse(ctx context.Context, contractAddressString, transactionID string, endorsement *prototk.AttestationResult, revertReason *string, replyTo string) {
 	endorsementAny, err := anypb.New(endorsement)
 	if err != nil {
 		log.L(ctx).Errorf("Failed marshal endorsement: %s", err)
@@ -642,7 +858,7 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 
 	endorsementResponse := &pbEngine.EndorsementResponse{
 		ContractAddress: contractAddressString,
-		TransactionId:   endorsementRequest.TransactionId,
+		TransactionId:   transactionID,
 		Endorsement:     endorsementAny,
 		RevertReason:    revertReason,
 	}
@@ -665,7 +881,7 @@ func (p *privateTxManager) handleEndorsementRequest(ctx context.Context, message
 	}
 }
 
-func (p *privateTxManager) handleDelegationRequest(ctx context.Context, messagePayload []byte) {
+func (p *privateTxManager) handleDelegationRequest(ctx context.Context, messagePayload []byte, replyTo string) {
 	delegationRequest := &pbEngine.DelegationRequest{}
 	err := proto.Unmarshal(messagePayload, delegationRequest)
 	if err != nil {
@@ -695,7 +911,58 @@ func (p *privateTxManager) handleDelegationRequest(ctx context.Context, messageP
 		return
 	}
 
-	//TODO send an ack
+	p.sendDelegationRequestAcknowledgment(ctx, delegationRequest, replyTo)
+}
+
+func (p *privateTxManager) sendDelegationRequestAcknowledgment(ctx context.Context, delegationRequest *pbEngine.DelegationRequest, replyTo string) {
+	delegationRequestAcknowledgment := &pbEngine.DelegationRequestAcknowledgment{
+		TransactionId:  delegationRequest.TransactionId,
+		DelegateNodeId: delegationRequest.DelegateNodeId,
+		DelegationId:   delegationRequest.DelegationId,
+	}
+	delegationRequestAcknowledgmentBytes, err := proto.Marshal(delegationRequestAcknowledgment)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to marshal delegation request acknowledgment: %s", err)
+		return
+	}
+
+	err = p.components.TransportManager().Send(ctx, &components.TransportMessage{
+		MessageType: "DelegationRequestAcknowledgment",
+		ReplyTo:     p.nodeName,
+		Payload:     delegationRequestAcknowledgmentBytes,
+		Node:        replyTo,
+		Component:   PRIVATE_TX_MANAGER_DESTINATION,
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to send delegation request acknowledgment: %s", err)
+		return
+	}
+}
+
+func (p *privateTxManager) handleDelegationRequestAcknowledgment(ctx context.Context, messagePayload []byte) {
+	delegationRequestAcknowledgment := &pbEngine.DelegationRequestAcknowledgment{}
+	err := proto.Unmarshal(messagePayload, delegationRequestAcknowledgment)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to unmarshal delegation request acknowledgment: %s", err)
+		return
+	}
+
+	// The acknowledgment does not name the contract, so it is offered to each sequencer in turn. Only the
+	// one with a pending handoff, or the flow, of the transaction accepts it.
+	p.sequencersLock.RLock()
+	sequencers := make([]*Sequencer, 0, len(p.sequencers))
+	for _, sequencer := range p.sequencers {
+		sequencers = append(sequencers, sequencer)
+	}
+	p.sequencersLock.RUnlock()
+
+	for _, sequencer := range sequencers {
+		if sequencer.HandoffAcknowledged(ctx, delegationRequestAcknowledgment.TransactionId, delegationRequestAcknowledgment.DelegationId) ||
+			sequencer.DelegationAcknowledged(ctx, delegationRequestAcknowledgment.TransactionId, delegationRequestAcknowledgment.DelegationId) {
+			return
+		}
+	}
+	log.L(ctx).Debugf("No pending handoff or flow for acknowledgment of delegation %s of transaction %s", delegationRequestAcknowledgment.DelegationId, delegationRequestAcknowledgment.TransactionId)
 }
 
 func (p *privateTxManager) handleEndorsementResponse(ctx context.Context, messagePayload []byte) {
@@ -719,6 +986,13 @@ func (p *privateTxManager) handleEndorsementResponse(ctx context.Context, messag
 		log.L(ctx).Errorf("Wrong type received in EndorsementResponse")
 		return
 	}
+	// The sequencer validates the endorsement against the attestation plan of the transaction before applying it,
+	// but we can reject a response that could never match here
+	if endorsement.Name == "" || (endorsement.Verifier == nil && revertReason == nil) {
+		err = i18n.NewError(ctx, msgs.MsgPrivateTxManagerInvalidEndorsement, endorsementResponse.TransactionId, "attestation name or verifier")
+		log.L(ctx).Errorf("Rejected endorsement response: %s", err)
+		return
+	}
 
 	p.HandleNewEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
 		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
@@ -738,20 +1012,55 @@ func (p *privateTxManager) handleEndorsementResponse(ctx context.Context, messag
 func (p *privateTxManager) Subscribe(ctx context.Context, subscriber components.PrivateTxEventSubscriber) {
 	p.subscribersLock.Lock()
 	defer p.subscribersLock.Unlock()
-	//TODO implement this
-	p.subscribers = append(p.subscribers, subscriber)
+	p.subscribers = append(p.subscribers, newEventSubscriber(subscriber, p.subscriberBufferSize))
 }
 
 func (p *privateTxManager) publishToSubscribers(ctx context.Context, event components.PrivateTxEvent) {
 	log.L(ctx).Debugf("Publishing event to subscribers")
 	p.subscribersLock.Lock()
 	defer p.subscribersLock.Unlock()
+	connected := p.subscribers[:0]
 	for _, subscriber := range p.subscribers {
-		subscriber(event)
+		if subscriber.publish(ctx, event, p.subscriberOverflowPolicy) {
+			subscriber.close()
+		} else {
+			connected = append(connected, subscriber)
+		}
 	}
+	p.subscribers = connected
 }
 
-func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, failures []*components.PublicTxMatch) error {
+// Dispatched transactions that were submitted to the base ledger are remembered until they are confirmed,
+// so that the confirmation event can tell subscribers which nonce (and signing address) has been confirmed.
+// Transactions that were dispatched without a public transaction (prepared, or chained to another private
+// transaction) are never confirmed, so are not remembered.
+func (p *privateTxManager) publishDispatched(ctx context.Context, event *components.TransactionDispatchedEvent, awaitConfirmation bool) {
+	if awaitConfirmation {
+		p.subscribersLock.Lock()
+		p.awaitingConfirmation[event.TransactionID] = event
+		p.subscribersLock.Unlock()
+	}
+	p.publishToSubscribers(ctx, event)
+}
+
+func (p *privateTxManager) publishConfirmed(ctx context.Context, txID uuid.UUID, blockNumber int64, success bool) {
+	event := &components.TransactionConfirmedEvent{
+		TransactionID: txID.String(),
+		BlockNumber:   blockNumber,
+		Success:       success,
+	}
+	p.subscribersLock.Lock()
+	if dispatched := p.awaitingConfirmation[event.TransactionID]; dispatched != nil {
+		event.ContractAddress = dispatched.ContractAddress
+		event.Nonce = dispatched.Nonce
+		event.SigningAddress = dispatched.SigningAddress
+		delete(p.awaitingConfirmation, event.TransactionID)
+	}
+	p.subscribersLock.Unlock()
+	p.publishToSubscribers(ctx, event)
+}
+
+func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.DB, failures []*components.PublicTxMatch) (func(), error) {
 	// TODO: We have processing we need to do here to resubmit
 	// For now, we directly raise a failure receipt for them back with the main transaction manager
 	privateFailureReceipts := make([]*components.ReceiptInput, len(failures))
@@ -767,8 +1076,47 @@ func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.
 			},
 			RevertData: tx.RevertReason,
 		}
+		if tx.GapFilled {
+			privateFailureReceipts[i].ReceiptType = components.RT_FailedWithMessage
+			privateFailureReceipts[i].FailureMessage = i18n.NewError(ctx, msgs.MsgPublicTxNonceUsedByGapFill, tx.Nonce, tx.From, tx.Hash).Error()
+		}
+	}
+	if err := p.components.TxManager().FinalizeTransactions(ctx, dbTX, privateFailureReceipts); err != nil {
+		return nil, err
+	}
+	// the states of a transaction that reverted must not be sent if they were held until confirmation
+	failedTxIDs := make([]uuid.UUID, len(failures))
+	for i, tx := range failures {
+		failedTxIDs[i] = tx.TransactionID
+	}
+	if err := p.stateDistributer.DiscardHeldDistributions(ctx, dbTX, failedTxIDs); err != nil {
+		return nil, err
+	}
+	// subscribers must not hear about the failures until the receipts are committed
+	return func() {
+		for _, tx := range failures {
+			p.publishConfirmed(ctx, tx.TransactionID, tx.BlockNumber, false)
+		}
+	}, nil
+}
+
+// States that the domain only distributes after confirmation are released in the same DB transaction as
+// the confirmation, so that they are sent exactly when the confirmation is committed (even across a restart)
+func (p *privateTxManager) NotifyConfirmedPrivateTx(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) (func(), error) {
+	return p.stateDistributer.ReleaseHeldDistributions(ctx, dbTX, txIDs)
+}
+
+func (p *privateTxManager) NotifyCorrectedPublicTx(ctx context.Context, corrections []*components.PublicTxMatch) {
+	for _, tx := range corrections {
+		log.L(ctx).Warnf("Outcome of private transaction %s corrected after re-org hash=%s block=%d result=%s",
+			tx.TransactionID, tx.Hash, tx.BlockNumber, tx.Result)
+		p.publishToSubscribers(ctx, &components.TransactionConfirmedEvent{
+			TransactionID: tx.TransactionID.String(),
+			BlockNumber:   tx.BlockNumber,
+			Success:       tx.Result.V() == pldapi.TXResult_SUCCESS,
+			Corrected:     true,
+		})
 	}
-	return p.components.TxManager().FinalizeTransactions(ctx, dbTX, privateFailureReceipts)
 }
 
 // We get called post-commit by the indexer in the domain when transaction confirmations have been recorded,
@@ -777,6 +1125,7 @@ func (p *privateTxManager) NotifyFailedPublicTx(ctx context.Context, dbTX *gorm.
 func (p *privateTxManager) PrivateTransactionConfirmed(ctx context.Context, receipt *components.TxCompletion) {
 	log.L(ctx).Infof("private TX manager notified of transaction confirmation %s deploy=%t",
 		receipt.TransactionID, receipt.PSC == nil)
+	p.publishConfirmed(ctx, receipt.TransactionID, receipt.OnChain.BlockNumber, receipt.ReceiptType == components.RT_Success)
 	if receipt.PSC != nil {
 		seq, err := p.getSequencerForContract(ctx, receipt.PSC.Address(), receipt.PSC)
 		if err != nil {
@@ -789,6 +1138,13 @@ func (p *privateTxManager) PrivateTransactionConfirmed(ctx context.Context, rece
 
 func (p *privateTxManager) CallPrivateSmartContract(ctx context.Context, call *components.TransactionInputs) (*abi.ComponentValue, error) {
 
+	// Guard against domains whose calls nest further private calls without bound
+	depth := callDepth(ctx) + 1
+	if depth > p.maxCallDepth {
+		return nil, i18n.NewError(ctx, msgs.MsgPrivateTxManagerMaxCallDepthExceeded, call.To, p.maxCallDepth)
+	}
+	ctx = context.WithValue(ctx, callDepthKey{}, depth)
+
 	psc, err := p.components.DomainManager().GetSmartContractByAddress(ctx, call.To)
 	if err != nil {
 		return nil, err
@@ -807,6 +1163,20 @@ func (p *privateTxManager) CallPrivateSmartContract(ctx context.Context, call *c
 	}
 
 	// Do the verification in-line and synchronously for call (there is caching in the identity resolver)
+	verifiers, err := p.resolveVerifiers(ctx, requiredVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a throwaway domain context for this call
+	dCtx := p.components.StateManager().NewDomainContext(ctx, psc.Domain(), psc.Address())
+	defer dCtx.Close()
+
+	// Do the actual call
+	return psc.ExecCall(dCtx, p.components.Persistence().DB(), call, verifiers)
+}
+
+func (p *privateTxManager) resolveVerifiers(ctx context.Context, requiredVerifiers []*prototk.ResolveVerifierRequest) ([]*prototk.ResolvedVerifier, error) {
 	identityResolver := p.components.IdentityResolver()
 	verifiers := make([]*prototk.ResolvedVerifier, len(requiredVerifiers))
 	for i, r := range requiredVerifiers {
@@ -821,13 +1191,179 @@ func (p *privateTxManager) CallPrivateSmartContract(ctx context.Context, call *c
 			Verifier:     verifier,
 		}
 	}
+	return verifiers, nil
+}
+
+// EstimatePrivateTransactionGas takes a transaction through init, assembly, attestation and prepare synchronously,
+// without sequencing or dispatching it, and estimates the gas of the public transaction that results.
+// Everything is done in a throwaway domain context that is never flushed, so none of the states are written,
+// and the estimate is made without assigning a nonce. Only attestations from local parties can be gathered.
+func (p *privateTxManager) EstimatePrivateTransactionGas(ctx context.Context, txi *components.TransactionInputs, publicTxOptions pldapi.PublicTxOptions) (tktypes.HexUint64, error) {
+	psc, err := p.components.DomainManager().GetSmartContractByAddress(ctx, txi.To)
+	if err != nil {
+		return 0, err
+	}
+
+	domainName := psc.Domain().Name()
+	if txi.Domain != "" && domainName != txi.Domain {
+		return 0, i18n.NewError(ctx, msgs.MsgPrivateTxMgrDomainMismatch, txi.Domain, domainName, psc.Address())
+	}
+	txi.Domain = domainName
+	txi.Intent = prototk.TransactionSpecification_SEND_TRANSACTION
+
+	tx := &components.PrivateTransaction{
+		ID:              uuid.New(),
+		Inputs:          txi,
+		PublicTxOptions: publicTxOptions,
+	}
+	if err := psc.InitTransaction(ctx, tx); err != nil {
+		return 0, err
+	}
+	if tx.PreAssembly == nil {
+		return 0, i18n.NewError(ctx, msgs.MsgPrivateTxManagerInternalError, "PreAssembly is nil")
+	}
+	if tx.PreAssembly.Verifiers, err = p.resolveVerifiers(ctx, tx.PreAssembly.RequiredVerifiers); err != nil {
+		return 0, err
+	}
 
-	// Create a throwaway domain context for this call
 	dCtx := p.components.StateManager().NewDomainContext(ctx, psc.Domain(), psc.Address())
 	defer dCtx.Close()
+	readTX := p.components.Persistence().DB()
 
-	// Do the actual call
-	return psc.ExecCall(dCtx, p.components.Persistence().DB(), call, verifiers)
+	if err := psc.AssembleTransaction(dCtx, readTX, tx); err != nil {
+		return 0, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerAssembleError, err.Error())
+	}
+	if tx.PostAssembly == nil {
+		return 0, i18n.NewError(ctx, msgs.MsgPrivateTxManagerInternalError, "AssembleTransaction returned nil PostAssembly")
+	}
+	if tx.PostAssembly.AssemblyResult == prototk.AssembleTransactionResponse_REVERT {
+		return 0, i18n.NewError(ctx, msgs.MsgPrivateTxManagerAssembleRevert)
+	}
+	assemblyVerifiers, err := p.resolveVerifiers(ctx, tx.PostAssembly.RequiredVerifiers)
+	if err != nil {
+		return 0, err
+	}
+	tx.PreAssembly.Verifiers = append(tx.PreAssembly.Verifiers, assemblyVerifiers...)
+	if err := psc.ValidateAssembled(dCtx, readTX, tx); err != nil {
+		return 0, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerValidateAssembledError, err.Error())
+	}
+	if err := psc.BuildAttestationPayloads(dCtx, readTX, tx); err != nil {
+		return 0, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerBuildPayloadError, err.Error())
+	}
+	if err := p.gatherLocalAttestations(ctx, psc, dCtx, tx); err != nil {
+		return 0, err
+	}
+
+	if err := psc.PrepareTransaction(dCtx, readTX, tx); err != nil {
+		return 0, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerPrepareError, err.Error())
+	}
+	if tx.PreparedPublicTransaction == nil || tx.PreparedPrivateTransaction != nil {
+		return 0, i18n.NewError(ctx, msgs.MsgPrivateTxManagerEstimateNoPublicTx)
+	}
+	data, err := tx.PreparedPublicTransaction.ABI[0].EncodeCallDataJSONCtx(ctx, tx.PreparedPublicTransaction.Data)
+	if err != nil {
+		return 0, err
+	}
+
+	// The anonymous signing addresses of the sequencer are only allocated when the transaction is dispatched, so unless
+	// the transaction must be submitted by a particular identity we estimate from a random address in the same way
+	from := tktypes.RandAddress()
+	if tx.Signer != "" {
+		unqualifiedSigner, err := tktypes.PrivateIdentityLocator(tx.Signer).Identity(ctx)
+		if err == nil {
+			from, err = p.components.KeyManager().ResolveEthAddressNewDatabaseTX(ctx, unqualifiedSigner)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	contractAddr := psc.Address()
+	return p.components.PublicTxManager().EstimateGas(ctx, &pldapi.PublicTxInput{
+		From:            from,
+		To:              &contractAddr,
+		Data:            data,
+		PublicTxOptions: tx.PublicTxOptions,
+	})
+}
+
+// gatherLocalAttestations signs and endorses the transaction with the local parties of its attestation plan, in the
+// given domain context. A remote endorser would have to be sent a request, so cannot be part of a synchronous dry run.
+func (p *privateTxManager) gatherLocalAttestations(ctx context.Context, psc components.DomainSmartContract, dCtx components.DomainContext, tx *components.PrivateTransaction) error {
+	keyMgr := p.components.KeyManager()
+	for _, attRequest := range tx.PostAssembly.AttestationPlan {
+		if attRequest.AttestationType != prototk.AttestationType_SIGN {
+			continue
+		}
+		for _, partyName := range attRequest.Parties {
+			identity, node, err := tktypes.PrivateIdentityLocator(partyName).Validate(ctx, p.nodeName, true)
+			if err == nil && node != p.nodeName {
+				err = i18n.NewError(ctx, msgs.MsgPrivateTxManagerSignRemoteError, partyName)
+			}
+			var resolvedKey *pldapi.KeyMappingAndVerifier
+			if err == nil {
+				resolvedKey, err = keyMgr.ResolveKeyNewDatabaseTX(ctx, identity, attRequest.Algorithm, attRequest.VerifierType)
+			}
+			var signature []byte
+			if err == nil {
+				signature, err = keyMgr.Sign(ctx, resolvedKey, attRequest.PayloadType, attRequest.Payload)
+			}
+			if err != nil {
+				return err
+			}
+			tx.PostAssembly.Signatures = append(tx.PostAssembly.Signatures, &prototk.AttestationResult{
+				Name:            attRequest.Name,
+				AttestationType: attRequest.AttestationType,
+				Verifier: &prototk.ResolvedVerifier{
+					Lookup:       partyName,
+					Algorithm:    attRequest.Algorithm,
+					Verifier:     resolvedKey.Verifier.Verifier,
+					VerifierType: attRequest.VerifierType,
+				},
+				Payload:     signature,
+				PayloadType: &attRequest.PayloadType,
+			})
+		}
+	}
+
+	endorsementGatherer := NewEndorsementGatherer(p.components.Persistence(), psc, dCtx, keyMgr, confutil.Bool(p.config.RequireEndorsementKeyMatch, false))
+	for _, attRequest := range tx.PostAssembly.AttestationPlan {
+		if attRequest.AttestationType != prototk.AttestationType_ENDORSE {
+			continue
+		}
+		for _, partyName := range attRequest.Parties {
+			_, node, err := tktypes.PrivateIdentityLocator(partyName).Validate(ctx, p.nodeName, true)
+			if err != nil {
+				return err
+			}
+			if node != p.nodeName {
+				return i18n.NewError(ctx, msgs.MsgPrivateTxManagerEstimateRemoteEndorser, attRequest.Name, partyName)
+			}
+			endorsement, revertReason, err := endorsementGatherer.GatherEndorsement(ctx,
+				tx.PreAssembly.TransactionSpecification,
+				tx.PreAssembly.Verifiers,
+				tx.PostAssembly.Signatures,
+				tx.PostAssembly.Endorsements,
+				toEndorsableList(tx.PostAssembly.InputStates),
+				toEndorsableList(tx.PostAssembly.ReadStates),
+				toEndorsableList(tx.PostAssembly.OutputStates),
+				toEndorsableList(tx.PostAssembly.InfoStates),
+				partyName,
+				attRequest)
+			if err != nil {
+				return err
+			}
+			if revertReason != nil {
+				return i18n.NewError(ctx, msgs.MsgPrivateTxManagerEndorsementReverted, attRequest.Name, partyName, *revertReason)
+			}
+			tx.PostAssembly.Endorsements = append(tx.PostAssembly.Endorsements, endorsement)
+			for _, c := range endorsement.Constraints {
+				if c == prototk.AttestationResult_ENDORSER_MUST_SUBMIT {
+					tx.Signer = endorsement.Verifier.Lookup
+				}
+			}
+		}
+	}
+	return nil
 }
 
 func (p *privateTxManager) BuildStateDistributions(ctx context.Context, tx *components.PrivateTransaction) (*components.StateDistributionSet, error) {
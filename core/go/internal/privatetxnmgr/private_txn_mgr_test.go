@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
@@ -47,6 +48,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
@@ -100,6 +103,90 @@ func TestPrivateTxManagerInvalidTransaction(t *testing.T) {
 	assert.Regexp(t, "PD011800", err)
 }
 
+func TestPrivateTxManagerDomainAvailableAfterDelay(t *testing.T) {
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	privateTxManager, mocks := NewPrivateTransactionMgrForTesting(t, "node1")
+	// The contract cannot be resolved until its domain has finished loading, and the DB is briefly unavailable
+	mocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(nil, i18n.NewError(ctx, msgs.MsgDomainNotConfiguredForPSC, domainAddress)).Once()
+	mocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(nil, i18n.NewError(ctx, msgs.MsgDomainContractNotFoundByAddr, domainAddress)).Once()
+	mocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(nil, fmt.Errorf("db unavailable")).Once()
+	mocks.mockDomain(domainAddress)
+
+	aliceIdentity := "alice@node1"
+	mocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       aliceIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+	}).Return(nil)
+
+	resolving := make(chan struct{}, 1)
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, aliceIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		select {
+		case resolving <- struct{}{}:
+		default:
+		}
+	}).Return(nil)
+
+	err := privateTxManager.Start()
+	require.NoError(t, err)
+
+	err = privateTxManager.handleNewTx(ctx, &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   aliceIdentity,
+		},
+	})
+	require.NoError(t, err)
+
+	// The transaction has been accepted by the sequencer for the contract
+	<-resolving
+}
+
+func TestPrivateTxManagerDomainNotAvailable(t *testing.T) {
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	privateTxManager, mocks := NewPrivateTransactionMgrForTesting(t, "node1")
+	mocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(nil, fmt.Errorf("pop"))
+
+	tx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			To: *domainAddress,
+		},
+	}
+
+	// No grace period
+	privateTxManager.domainResolutionGracePeriod = 0
+	err := privateTxManager.handleNewTx(ctx, tx)
+	assert.Regexp(t, "pop", err)
+
+	// Context canceled while waiting for the domain
+	privateTxManager.domainResolutionGracePeriod = 1 * time.Hour
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = privateTxManager.handleNewTx(cancelCtx, tx)
+	assert.Regexp(t, "PD010301", err)
+
+	// A contract the domain has rejected is not retried
+	invalidAddress := tktypes.RandAddress()
+	mocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *invalidAddress).Return(nil, i18n.NewError(ctx, msgs.MsgDomainContractNotValid, invalidAddress)).Once()
+	tx.Inputs.To = *invalidAddress
+	err = privateTxManager.handleNewTx(ctx, tx)
+	assert.Regexp(t, "PD011610", err)
+}
+
 func TestPrivateTxManagerSimpleTransaction(t *testing.T) {
 	//Submit a transaction that gets assembled with an attestation plan for a local endorser to sign the transaction
 	ctx := context.Background()
@@ -122,6 +209,7 @@ func TestPrivateTxManagerSimpleTransaction(t *testing.T) {
 	mocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 		tx := args.Get(1).(*components.PrivateTransaction)
 		tx.PreAssembly = &components.TransactionPreAssembly{
+			Label: "Transfer 23 FT1 to org2",
 			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
 				{
 					Lookup:       aliceIdentity,
@@ -275,188 +363,941 @@ func TestPrivateTxManagerSimpleTransaction(t *testing.T) {
 	assert.Equal(t, "dispatched", status)
 
 	require.NoError(t, <-dcFlushed)
-}
 
-func TestPrivateTxManagerLocalEndorserSubmits(t *testing.T) {
+	// Every stage the transaction passed through has been timed, and none is still open
+	s, err := privateTxManager.GetTxStatus(ctx, domainAddressString, tx.ID.String())
+	require.NoError(t, err)
+	stages := make([]components.PrivateTxStage, len(s.StageTimings))
+	for i, st := range s.StageTimings {
+		stages[i] = st.Stage
+		require.NotNil(t, st.Exited)
+		assert.GreaterOrEqual(t, st.Exited.UnixNano(), st.Entered.UnixNano())
+		assert.NotEmpty(t, st.Duration)
+	}
+	assert.Equal(t, []components.PrivateTxStage{
+		components.PrivateTxStageResolving,
+		components.PrivateTxStageAssembling,
+		components.PrivateTxStageEndorsing,
+		components.PrivateTxStageDispatching,
+	}, stages)
+
+	// The label supplied by the domain on init is recorded against the transaction, and reported in the status
+	assert.Equal(t, "Transfer 23 FT1 to org2", s.Label)
+	label, _ := mocks.labels.Load(tx.ID)
+	assert.Equal(t, "Transfer 23 FT1 to org2", label)
+
+	// The endorsement gathered is still reported once the transaction has been dispatched
+	attestations, err := privateTxManager.GetTxAttestations(ctx, domainAddressString, tx.ID.String())
+	require.NoError(t, err)
+	require.Len(t, attestations.Attestations, 1)
+	assert.Equal(t, "notary", attestations.Attestations[0].Name)
+	assert.Equal(t, "ENDORSE", attestations.Attestations[0].AttestationType)
+	assert.Equal(t, []*components.PrivateTxAttestationParty{
+		{Party: notaryIdentity, Verifier: notaryVerifier, Received: true, Result: "SIGN"},
+	}, attestations.Attestations[0].Parties)
+
+	// A copy is persisted with the dispatch, for once the transaction has left memory
+	persisted, err := privateTxManager.syncPoints.GetTransactionAttestations(ctx, *domainAddress, tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, attestations, persisted)
+
+	_, err = privateTxManager.GetTxAttestations(ctx, domainAddressString, uuid.NewString())
+	assert.Regexp(t, "PD011801", err)
+	_, err = privateTxManager.GetTxAttestations(ctx, tktypes.RandAddress().String(), tx.ID.String())
+	assert.Regexp(t, "PD011801", err)
+	_, err = privateTxManager.GetTxAttestations(ctx, domainAddressString, "wrong")
+	assert.Regexp(t, "PD011801", err)
+	_, err = privateTxManager.GetTxAttestations(ctx, "wrong", tx.ID.String())
+	assert.Error(t, err)
 }
 
-func TestPrivateTxManagerRevertFromLocalEndorsement(t *testing.T) {
-}
+func TestPrivateTxManagerNonEthAddressVerifierType(t *testing.T) {
+	// Submit a transaction where the sender signs, and the notary endorses, with keys of a ZKP verifier type rather
+	// than an ETH address. The notary has also been resolved as an ETH address before assembly, so the ZKP verifier
+	// must be resolved separately for the same identity.
+	ctx := context.Background()
 
-type identityForTesting struct {
-	identity        string
-	identityLocator string
-	verifier        string
-	keyHandle       string
-	mocks           *dependencyMocks
-	mockSign        func(signature []byte)
-}
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	privateTxManager, mocks := NewPrivateTransactionMgrForTesting(t, "node1")
+	mocks.mockDomain(domainAddress)
 
-func (i *identityForTesting) mockResolve(ctx context.Context, other identityForTesting) {
-	// in addition to the default mocks set up in newPartyForTesting, we can set up mocks to resolve remote identitys
-	// we could have used a real IdentityResolver here but we are testing the private transaction manager in isolation and so we mock the IdentityResolver as we do with all other tests in this file
-	i.mocks.identityResolver.On(
-		"ResolveVerifierAsync",
-		mock.Anything,
-		other.identityLocator,
-		algorithms.ECDSA_SECP256K1,
-		verifiers.ETH_ADDRESS,
-		mock.Anything,
-		mock.Anything).
-		Run(func(args mock.Arguments) {
-			resolveFn := args.Get(4).(func(context.Context, string))
-			resolveFn(ctx, other.verifier)
-		}).Return(nil).Maybe()
-}
+	domainAddressString := domainAddress.String()
 
-func newPartyForTesting(ctx context.Context, name, node string, mocks *dependencyMocks) identityForTesting {
-	party := identityForTesting{
-		identity:        name,
-		identityLocator: name + "@" + node,
-		verifier:        tktypes.RandAddress().String(),
-		keyHandle:       name + "KeyHandle",
-		mocks:           mocks,
-	}
+	const zkpAlgorithm = "domain:domain1:snark:babyjubjub"
+	const zkpVerifierType = "iden3_pubkey_babyjubjub_compressed_0x"
+	const zkpPayloadType = "domain:domain1:snark"
 
-	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, party.identity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
-		resolveFn := args.Get(4).(func(context.Context, string))
-		resolveFn(ctx, party.verifier)
-	}).Return(nil).Maybe()
+	aliceIdentityLocal := "alice"
+	aliceIdentity := aliceIdentityLocal + "@node1"
+	aliceVerifier := tktypes.RandAddress().String()
+	aliceZKPVerifier := tktypes.RandHex(32)
+	notaryIdentityLocal := "domain1.contract1.notary"
+	notaryIdentity := notaryIdentityLocal + "@node1"
+	notaryVerifier := tktypes.RandAddress().String()
+	notaryZKPVerifier := tktypes.RandHex(32)
 
-	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, party.identityLocator, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
-		resolveFn := args.Get(4).(func(context.Context, string))
-		resolveFn(ctx, party.verifier)
-	}).Return(nil).Maybe()
+	mocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       aliceIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+				{
+					Lookup:       notaryIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+	}).Return(nil)
 
-	mocks.identityResolver.On("ResolveVerifier", mock.Anything, party.identityLocator, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
-		Return(party.verifier, nil).Maybe()
+	resolveTo := func(lookup, algorithm, verifierType, verifier string) {
+		mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, lookup, algorithm, verifierType, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			resovleFn := args.Get(4).(func(context.Context, string))
+			resovleFn(ctx, verifier)
+		}).Return(nil)
+	}
+	resolveTo(aliceIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, aliceVerifier)
+	resolveTo(notaryIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, notaryVerifier)
+	resolveTo(notaryIdentity, zkpAlgorithm, zkpVerifierType, notaryZKPVerifier)
 
-	keyMapping := &pldapi.KeyMappingAndVerifier{
+	mocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+	mocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			InputStates: []*components.FullState{
+				{
+					ID:     tktypes.RandBytes(32),
+					Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
+					Data:   tktypes.JSONString("foo"),
+				},
+			},
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       notaryIdentity,
+					Algorithm:    zkpAlgorithm,
+					VerifierType: zkpVerifierType,
+				},
+			},
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "sender",
+					AttestationType: prototk.AttestationType_SIGN,
+					Algorithm:       zkpAlgorithm,
+					VerifierType:    zkpVerifierType,
+					PayloadType:     zkpPayloadType,
+					Payload:         []byte("some-proof-input"),
+					Parties: []string{
+						aliceIdentity,
+					},
+				},
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       zkpAlgorithm,
+					VerifierType:    zkpVerifierType,
+					PayloadType:     zkpPayloadType,
+					Parties: []string{
+						notaryIdentity,
+					},
+				},
+			},
+		}
+	}).Return(nil)
+
+	aliceKeyMapping := &pldapi.KeyMappingAndVerifier{
 		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{
-			Identifier: party.identity,
-			KeyHandle:  party.keyHandle,
+			Identifier: aliceIdentityLocal,
+			KeyHandle:  "aliceKeyHandle",
 		}},
-		Verifier: &pldapi.KeyVerifier{Verifier: party.verifier},
+		Verifier: &pldapi.KeyVerifier{Verifier: aliceZKPVerifier, Type: zkpVerifierType, Algorithm: zkpAlgorithm},
 	}
-	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, party.identity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).Return(keyMapping, nil).Maybe()
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, aliceIdentityLocal, zkpAlgorithm, zkpVerifierType).Return(aliceKeyMapping, nil)
+	mocks.keyManager.On("Sign", mock.Anything, aliceKeyMapping, zkpPayloadType, []byte("some-proof-input")).
+		Return([]byte("alice-proof-bytes"), nil)
+
+	notaryKeyMapping := &pldapi.KeyMappingAndVerifier{
+		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{
+			Identifier: notaryIdentityLocal,
+			KeyHandle:  "notaryKeyHandle",
+		}},
+		Verifier: &pldapi.KeyVerifier{Verifier: notaryZKPVerifier, Type: zkpVerifierType, Algorithm: zkpAlgorithm},
+	}
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, notaryIdentityLocal, zkpAlgorithm, zkpVerifierType).Return(notaryKeyMapping, nil)
+	mocks.keyManager.On("Sign", mock.Anything, notaryKeyMapping, zkpPayloadType, []byte("some-endorsement-bytes")).
+		Return([]byte("notary-proof-bytes"), nil)
+
+	// The domain is given the ZKP verifiers of both the notary and the sender
+	mocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.MatchedBy(func(req *components.PrivateTransactionEndorseRequest) bool {
+		return req.Endorser.Verifier == notaryZKPVerifier &&
+			req.Endorser.VerifierType == zkpVerifierType &&
+			len(req.Verifiers) == 3 && req.Verifiers[2].Verifier == notaryZKPVerifier &&
+			len(req.Signatures) == 1 && req.Signatures[0].Verifier.Verifier == aliceZKPVerifier
+	})).Return(&components.EndorsementResult{
+		Result:  prototk.EndorseTransactionResponse_SIGN,
+		Payload: []byte("some-endorsement-bytes"),
+		Endorser: &prototk.ResolvedVerifier{
+			Lookup:       notaryIdentity,
+			Verifier:     notaryZKPVerifier,
+			Algorithm:    zkpAlgorithm,
+			VerifierType: zkpVerifierType,
+		},
+	}, nil)
+
+	var prepared *components.PrivateTransaction
+	mocks.domainSmartContract.On("PrepareTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			cv, err := testABI[0].Inputs.ParseExternalData(map[string]any{
+				"inputs":  []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"outputs": []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"data":    "0xfeedbeef",
+			})
+			require.NoError(t, err)
+			prepared = args[2].(*components.PrivateTransaction)
+			prepared.Signer = "signer1"
+			jsonData, _ := cv.JSON()
+			prepared.PreparedPublicTransaction = &pldapi.TransactionInput{
+				ABI: abi.ABI{testABI[0]},
+				TransactionBase: pldapi.TransactionBase{
+					To:   domainAddress,
+					Data: tktypes.RawJSON(jsonData),
+				},
+			}
+		},
+	)
+
+	tx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   aliceIdentity,
+		},
+	}
+
+	mockPublicTxBatch := componentmocks.NewPublicTxBatch(t)
+	mockPublicTxBatch.On("Finalize", mock.Anything).Return().Maybe()
+	mockPublicTxBatch.On("CleanUp", mock.Anything).Return().Maybe()
+
+	mockPublicTxManager := mocks.publicTxManager.(*componentmocks.PublicTxManager)
+	mockPublicTxManager.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockPublicTxBatch, nil)
+
+	signingAddr := tktypes.RandAddress()
+	mocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).
+		Return([]*tktypes.EthAddress{signingAddr}, nil)
+
+	publicTransactions := []components.PublicTxAccepted{
+		newFakePublicTx(&components.PublicTxSubmission{
+			Bindings: []*components.PaladinTXReference{{TransactionID: tx.ID, TransactionType: pldapi.TransactionTypePrivate.Enum()}},
+			PublicTxInput: pldapi.PublicTxInput{
+				From: signingAddr,
+			},
+		}, nil),
+	}
+	mockPublicTxBatch.On("Submit", mock.Anything, mock.Anything).Return(nil)
+	mockPublicTxBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	mockPublicTxBatch.On("Accepted").Return(publicTransactions)
+	mockPublicTxBatch.On("Completed", mock.Anything, true).Return()
+
+	dcFlushed := make(chan error, 1)
+	mocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
+		dcFlushed <- err
+	}, nil)
+
+	err := privateTxManager.Start()
+	require.NoError(t, err)
+	err = privateTxManager.handleNewTx(ctx, tx)
+	require.NoError(t, err)
+
+	status := pollForStatus(ctx, t, "dispatched", privateTxManager, domainAddressString, tx.ID.String(), 2*time.Second)
+	assert.Equal(t, "dispatched", status)
+	require.NoError(t, <-dcFlushed)
+
+	// The attestation results carry the ZKP verifiers through to the prepare
+	require.NotNil(t, prepared)
+	require.Len(t, prepared.PostAssembly.Signatures, 1)
+	assert.Equal(t, aliceIdentity, prepared.PostAssembly.Signatures[0].Verifier.Lookup)
+	assert.Equal(t, zkpAlgorithm, prepared.PostAssembly.Signatures[0].Verifier.Algorithm)
+	assert.Equal(t, zkpVerifierType, prepared.PostAssembly.Signatures[0].Verifier.VerifierType)
+	assert.Equal(t, aliceZKPVerifier, prepared.PostAssembly.Signatures[0].Verifier.Verifier)
+	assert.Equal(t, []byte("alice-proof-bytes"), prepared.PostAssembly.Signatures[0].Payload)
+	require.Len(t, prepared.PostAssembly.Endorsements, 1)
+	assert.Equal(t, zkpVerifierType, prepared.PostAssembly.Endorsements[0].Verifier.VerifierType)
+	assert.Equal(t, notaryZKPVerifier, prepared.PostAssembly.Endorsements[0].Verifier.Verifier)
+	assert.Equal(t, []byte("notary-proof-bytes"), prepared.PostAssembly.Endorsements[0].Payload)
+}
+
+func TestPrivateTxManagerInputStateConflictReassembled(t *testing.T) {
+	// Two transactions are assembled to spend the same state. The second to reach endorsement must be
+	// re-assembled before any endorsement is gathered for it, rather than the conflict being found on flush.
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	privateTxManager, mocks := NewPrivateTransactionMgrForTestingWithFakePublicTxManager(t, newFakePublicTxManager(t), "node1")
+	mocks.mockDomain(domainAddress)
+
+	domainAddressString := domainAddress.String()
+
+	aliceIdentity := "alice@node1"
+	aliceVerifier := tktypes.RandAddress().String()
+	notaryIdentityLocal := "domain1.contract1.notary"
+	notaryIdentity := notaryIdentityLocal + "@node1"
+	notaryVerifier := tktypes.RandAddress().String()
+
+	mocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{
+				TransactionId: tx.ID.String(),
+			},
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       aliceIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+	}).Return(nil)
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, aliceIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resovleFn := args.Get(4).(func(context.Context, string))
+		resovleFn(ctx, aliceVerifier)
+	}).Return(nil)
+
+	mocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+
+	// The first two assemblies both spend the same state, and any re-assembly spends a different one
+	conflictState := &components.FullState{
+		ID:     tktypes.RandBytes(32),
+		Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
+		Data:   tktypes.JSONString("foo"),
+	}
+	otherState := &components.FullState{
+		ID:     tktypes.RandBytes(32),
+		Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
+		Data:   tktypes.JSONString("bar"),
+	}
+	var assembled []uuid.UUID
+	mocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+		inputState := conflictState
+		if len(assembled) >= 2 {
+			inputState = otherState
+		}
+		assembled = append(assembled, tx.ID)
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			InputStates:    []*components.FullState{inputState},
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties: []string{
+						notaryIdentity,
+					},
+				},
+			},
+		}
+	}).Return(nil)
+
+	notaryKeyMapping := &pldapi.KeyMappingAndVerifier{
+		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{
+			Identifier: notaryIdentityLocal,
+			KeyHandle:  "notaryKeyHandle",
+		}},
+		Verifier: &pldapi.KeyVerifier{Verifier: notaryVerifier},
+	}
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, notaryIdentityLocal, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).Return(notaryKeyMapping, nil)
+	mocks.keyManager.On("Sign", mock.Anything, notaryKeyMapping, signpayloads.OPAQUE_TO_RSV, mock.Anything).
+		Return([]byte("notary-signature-bytes"), nil)
+
+	endorsedInputs := make(map[string][]string)
+	var endorsedLock sync.Mutex
+	mocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		req := args.Get(2).(*components.PrivateTransactionEndorseRequest)
+		endorsedLock.Lock()
+		defer endorsedLock.Unlock()
+		txID := req.TransactionSpecification.TransactionId
+		for _, s := range req.InputStates {
+			endorsedInputs[txID] = append(endorsedInputs[txID], s.Id)
+		}
+	}).Return(&components.EndorsementResult{
+		Result:  prototk.EndorseTransactionResponse_SIGN,
+		Payload: []byte("some-endorsement-bytes"),
+		Endorser: &prototk.ResolvedVerifier{
+			Lookup:       notaryIdentity,
+			Verifier:     notaryVerifier,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		},
+	}, nil)
+
+	mocks.domainSmartContract.On("PrepareTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			cv, err := testABI[0].Inputs.ParseExternalData(map[string]any{
+				"inputs":  []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"outputs": []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"data":    "0xfeedbeef",
+			})
+			require.NoError(t, err)
+			tx := args[2].(*components.PrivateTransaction)
+			tx.Signer = "signer1"
+			jsonData, _ := cv.JSON()
+			tx.PreparedPublicTransaction = &pldapi.TransactionInput{
+				ABI: abi.ABI{testABI[0]},
+				TransactionBase: pldapi.TransactionBase{
+					To:   domainAddress,
+					Data: tktypes.RawJSON(jsonData),
+				},
+			}
+		},
+	)
+	mocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).
+		Return([]*tktypes.EthAddress{tktypes.RandAddress()}, nil)
+	mocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {}, nil)
+
+	tx1 := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   aliceIdentity,
+		},
+	}
+	tx2 := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   aliceIdentity,
+		},
+	}
+
+	err := privateTxManager.Start()
+	require.NoError(t, err)
+	err = privateTxManager.handleNewTx(ctx, tx1)
+	require.NoError(t, err)
+	err = privateTxManager.handleNewTx(ctx, tx2)
+	require.NoError(t, err)
+
+	for _, tx := range []*components.PrivateTransaction{tx1, tx2} {
+		status := pollForStatus(ctx, t, "dispatched", privateTxManager, domainAddressString, tx.ID.String(), timeTillDeadline(t))
+		assert.Equal(t, "dispatched", status)
+	}
+
+	// Whichever transaction was second to be ready for endorsement was re-assembled, and was only ever
+	// endorsed spending the other state
+	require.Len(t, assembled, 3)
+	winner, loser := tx1, tx2
+	if assembled[2] == tx1.ID {
+		winner, loser = tx2, tx1
+	}
+	endorsedLock.Lock()
+	defer endorsedLock.Unlock()
+	assert.Equal(t, []string{conflictState.ID.String()}, endorsedInputs[winner.ID.String()])
+	assert.Equal(t, []string{otherState.ID.String()}, endorsedInputs[loser.ID.String()])
+}
+
+func TestPrivateTxManagerValidateAssembledRejected(t *testing.T) {
+	//Submit a transaction that the domain rejects after assembly, and check we never ask for an endorsement
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	privateTxManager, mocks := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	validated := make(chan struct{}, 1)
+	mocks.domainSmartContract.On("ValidateAssembled", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		validated <- struct{}{}
+	}).Return(errors.New("unbalanced amounts"))
+	mocks.mockDomain(domainAddress)
+
+	aliceIdentity := "alice@node1"
+	notaryIdentity := "domain1.contract1.notary@node1"
+
+	mocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       aliceIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+				{
+					Lookup:       notaryIdentity,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+	}).Return(nil)
+
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, aliceIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resovleFn := args.Get(4).(func(context.Context, string))
+		resovleFn(ctx, tktypes.RandAddress().String())
+	}).Return(nil)
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, notaryIdentity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resovleFn := args.Get(4).(func(context.Context, string))
+		resovleFn(ctx, tktypes.RandAddress().String())
+	}).Return(nil)
+
+	mocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+	mocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			InputStates: []*components.FullState{
+				{
+					ID:     tktypes.RandBytes(32),
+					Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
+					Data:   tktypes.JSONString("foo"),
+				},
+			},
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties: []string{
+						notaryIdentity,
+					},
+				},
+			},
+		}
+	}).Return(nil)
+
+	tx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   aliceIdentity,
+		},
+	}
+
+	reverted := make(chan []*components.ReceiptInput, 1)
+	mocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).
+		Run(
+			func(args mock.Arguments) {
+				reverted <- args.Get(2).([]*components.ReceiptInput)
+			},
+		).
+		Return(nil)
+	mocks.domainSmartContract.On("Address").Return(*domainAddress)
+	mocks.domainContext.On("ResetTransactions", tx.ID).Return().Maybe()
+
+	err := privateTxManager.Start()
+	require.NoError(t, err)
+	err = privateTxManager.handleNewTx(ctx, tx)
+	require.NoError(t, err)
+
+	deadlineTimer := time.NewTimer(timeTillDeadline(t))
+	select {
+	case <-validated:
+	case <-deadlineTimer.C:
+		assert.Fail(t, "timed out waiting for validation")
+	}
+	select {
+	case receipts := <-reverted:
+		assert.Len(t, receipts, 1)
+		assert.Equal(t, tx.ID, receipts[0].TransactionID)
+		assert.Regexp(t, "PD011835.*unbalanced amounts", receipts[0].FailureMessage)
+	case <-deadlineTimer.C:
+		assert.Fail(t, "timed out waiting for revert")
+	}
+
+	mocks.domainSmartContract.AssertNotCalled(t, "EndorseTransaction", mock.Anything, mock.Anything, mock.Anything)
+	mocks.domainSmartContract.AssertNumberOfCalls(t, "ValidateAssembled", 1)
+}
+
+func TestPrivateTxManagerLocalEndorserSubmits(t *testing.T) {
+}
+
+func TestPrivateTxManagerRevertFromLocalEndorsement(t *testing.T) {
+}
+
+type identityForTesting struct {
+	identity        string
+	identityLocator string
+	verifier        string
+	keyHandle       string
+	mocks           *dependencyMocks
+	mockSign        func(signature []byte)
+}
+
+func (i *identityForTesting) mockResolve(ctx context.Context, other identityForTesting) {
+	// in addition to the default mocks set up in newPartyForTesting, we can set up mocks to resolve remote identitys
+	// we could have used a real IdentityResolver here but we are testing the private transaction manager in isolation and so we mock the IdentityResolver as we do with all other tests in this file
+	i.mocks.identityResolver.On(
+		"ResolveVerifierAsync",
+		mock.Anything,
+		other.identityLocator,
+		algorithms.ECDSA_SECP256K1,
+		verifiers.ETH_ADDRESS,
+		mock.Anything,
+		mock.Anything).
+		Run(func(args mock.Arguments) {
+			resolveFn := args.Get(4).(func(context.Context, string))
+			resolveFn(ctx, other.verifier)
+		}).Return(nil).Maybe()
+}
+
+func newPartyForTesting(ctx context.Context, name, node string, mocks *dependencyMocks) identityForTesting {
+	party := identityForTesting{
+		identity:        name,
+		identityLocator: name + "@" + node,
+		verifier:        tktypes.RandAddress().String(),
+		keyHandle:       name + "KeyHandle",
+		mocks:           mocks,
+	}
+
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, party.identity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resolveFn := args.Get(4).(func(context.Context, string))
+		resolveFn(ctx, party.verifier)
+	}).Return(nil).Maybe()
+
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, party.identityLocator, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resolveFn := args.Get(4).(func(context.Context, string))
+		resolveFn(ctx, party.verifier)
+	}).Return(nil).Maybe()
+
+	mocks.identityResolver.On("ResolveVerifier", mock.Anything, party.identityLocator, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(party.verifier, nil).Maybe()
+
+	keyMapping := &pldapi.KeyMappingAndVerifier{
+		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{
+			Identifier: party.identity,
+			KeyHandle:  party.keyHandle,
+		}},
+		Verifier: &pldapi.KeyVerifier{Verifier: party.verifier},
+	}
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, party.identity, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).Return(keyMapping, nil).Maybe()
+
+	party.mockSign = func(signature []byte) {
+		mocks.keyManager.On("Sign", mock.Anything, keyMapping, signpayloads.OPAQUE_TO_RSV, mock.Anything).
+			Return(signature, nil)
+	}
+
+	return party
+}
+
+func TestPrivateTxManagerRemoteNotaryEndorser(t *testing.T) {
+	ctx := context.Background()
+	// A transaction that requires exactly one endorsement from a notary (as per noto) and therefore delegates coordination of the transaction to that node
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	localNodeName := "localNode"
+	remoteNodeName := "remoteNode"
+	privateTxManager, localNodeMocks := NewPrivateTransactionMgrForTesting(t, localNodeName)
+	localNodeMocks.mockDomain(domainAddress)
+
+	domainAddressString := domainAddress.String()
+
+	remoteEngine, remoteEngineMocks := NewPrivateTransactionMgrForTesting(t, remoteNodeName)
+	remoteEngineMocks.mockDomain(domainAddress)
+
+	alice := newPartyForTesting(ctx, "alice", localNodeName, localNodeMocks)
+	notary := newPartyForTesting(ctx, "notary", remoteNodeName, remoteEngineMocks)
+
+	alice.mockResolve(ctx, notary)
+
+	initialised := make(chan struct{}, 1)
+	localNodeMocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+	localNodeMocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       alice.identityLocator,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+				{
+					Lookup:       notary.identityLocator,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+		initialised <- struct{}{}
+	}).Return(nil)
+
+	assembled := make(chan struct{}, 1)
+
+	localNodeMocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			InputStates: []*components.FullState{
+				{
+					ID:     tktypes.RandBytes(32),
+					Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
+					Data:   tktypes.JSONString("foo"),
+				},
+			},
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties: []string{
+						notary.identityLocator,
+					},
+				},
+			},
+		}
+		assembled <- struct{}{}
+
+	}).Return(nil)
+
+	localNodeMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		go func() {
+			assert.Equal(t, remoteNodeName, args.Get(1).(*components.TransportMessage).Node)
+			transportMessage := args.Get(1).(*components.TransportMessage)
+			remoteEngine.ReceiveTransportMessage(ctx, transportMessage)
+		}()
+	}).Return(nil).Maybe()
+
+	remoteEngineMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		go func() {
+			transportMessage := args.Get(1).(*components.TransportMessage)
+			privateTxManager.ReceiveTransportMessage(ctx, transportMessage)
+		}()
+	}).Return(nil).Maybe()
+
+	remoteEngineMocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(remoteEngineMocks.domainSmartContract, nil)
+
+	//TODO match endorsement request and verifier args
+	remoteEngineMocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+	remoteEngineMocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
+		Result:  prototk.EndorseTransactionResponse_SIGN,
+		Payload: []byte("some-endorsement-bytes"),
+		Endorser: &prototk.ResolvedVerifier{
+			Lookup:       notary.identityLocator, //matches whatever was specified in PreAssembly.RequiredVerifiers
+			Verifier:     notary.verifier,
+			Algorithm:    algorithms.ECDSA_SECP256K1,
+			VerifierType: verifiers.ETH_ADDRESS,
+		},
+	}, nil)
+
+	notary.mockSign([]byte("some-signature-bytes"))
+
+	remoteEngineMocks.domainSmartContract.On("PrepareTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(
+		func(args mock.Arguments) {
+			cv, err := testABI[0].Inputs.ParseExternalData(map[string]any{
+				"inputs":  []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"outputs": []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"data":    "0xfeedbeef",
+			})
+			require.NoError(t, err)
+			tx := args[2].(*components.PrivateTransaction)
+			tx.Signer = "signer1"
+			jsonData, _ := cv.JSON()
+			tx.PreparedPublicTransaction = &pldapi.TransactionInput{
+				ABI: abi.ABI{testABI[0]},
+				TransactionBase: pldapi.TransactionBase{
+					To:   domainAddress,
+					Data: tktypes.RawJSON(jsonData),
+				},
+			}
+		},
+	)
+
+	tx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   alice.identityLocator, // domain manager would insert the local node name for us, but we've mocked that
+		},
+	}
+
+	mockPublicTxBatch := componentmocks.NewPublicTxBatch(t)
+	mockPublicTxBatch.On("Finalize", mock.Anything).Return().Maybe()
+	mockPublicTxBatch.On("CleanUp", mock.Anything).Return().Maybe()
+
+	mockPublicTxManager := remoteEngineMocks.publicTxManager.(*componentmocks.PublicTxManager)
+	mockPublicTxManager.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockPublicTxBatch, nil)
+
+	signingAddr := tktypes.RandAddress()
+	remoteEngineMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).
+		Return([]*tktypes.EthAddress{signingAddr}, nil)
+
+	publicTransactions := []components.PublicTxAccepted{
+		newFakePublicTx(&components.PublicTxSubmission{
+			Bindings: []*components.PaladinTXReference{{TransactionID: tx.ID, TransactionType: pldapi.TransactionTypePrivate.Enum()}},
+			PublicTxInput: pldapi.PublicTxInput{
+				From: signingAddr,
+			},
+		}, nil),
+	}
+	mockPublicTxBatch.On("Submit", mock.Anything, mock.Anything).Return(nil)
+	mockPublicTxBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	mockPublicTxBatch.On("Accepted").Return(publicTransactions)
+	mockPublicTxBatch.On("Completed", mock.Anything, true).Return()
+
+	// Flush of domain context happens on the remote node (the notary)
+	dcFlushed := make(chan error, 1)
+	remoteEngineMocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
+		dcFlushed <- err
+	}, nil)
+
+	err := privateTxManager.Start()
+	assert.NoError(t, err)
+
+	err = privateTxManager.handleNewTx(ctx, tx)
+	assert.NoError(t, err)
+
+	status := pollForStatus(ctx, t, "delegating", privateTxManager, domainAddressString, tx.ID.String(), 200*time.Second)
+	assert.Equal(t, "delegating", status)
 
-	party.mockSign = func(signature []byte) {
-		mocks.keyManager.On("Sign", mock.Anything, keyMapping, signpayloads.OPAQUE_TO_RSV, mock.Anything).
-			Return(signature, nil)
-	}
+	status = pollForStatus(ctx, t, "dispatched", remoteEngine, domainAddressString, tx.ID.String(), 200*time.Second)
+	assert.Equal(t, "dispatched", status)
+
+	require.NoError(t, <-dcFlushed)
+
+	// The originating node records the notary's node as the coordinator of the transaction
+	assert.Eventually(t, func() bool {
+		coordinator, _ := localNodeMocks.coordinators.Load(tx.ID)
+		return coordinator == remoteNodeName
+	}, timeTillDeadline(t), 10*time.Millisecond)
 
-	return party
 }
 
-func TestPrivateTxManagerRemoteNotaryEndorser(t *testing.T) {
+func TestPrivateTxManagerContentionLostDelegatesToWinner(t *testing.T) {
 	ctx := context.Background()
-	// A transaction that requires exactly one endorsement from a notary (as per noto) and therefore delegates coordination of the transaction to that node
+	// A transaction that is being coordinated on node A when node A loses a contention bid to node B
+	// so the transaction must be re-submitted to node B, which then coordinates it through to dispatch
 
 	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
-	localNodeName := "localNode"
-	remoteNodeName := "remoteNode"
-	privateTxManager, localNodeMocks := NewPrivateTransactionMgrForTesting(t, localNodeName)
-	localNodeMocks.mockDomain(domainAddress)
-
 	domainAddressString := domainAddress.String()
+	nodeAName := "nodeA"
+	nodeBName := "nodeB"
+	nodeA, nodeAMocks := NewPrivateTransactionMgrForTesting(t, nodeAName)
+	nodeAMocks.mockDomain(domainAddress)
+	// the failed delegation is retried from the evaluation ticker
+	nodeA.config.Sequencer.EvaluationInterval = confutil.P("10ms")
+	nodeA.config.Sequencer.ContentionRetry.InitialDelay = confutil.P("1ms")
+	nodeB, nodeBMocks := NewPrivateTransactionMgrForTesting(t, nodeBName)
+	nodeBMocks.mockDomain(domainAddress)
+
+	alice := newPartyForTesting(ctx, "alice", nodeAName, nodeAMocks)
+	bob := newPartyForTesting(ctx, "bob", nodeBName, nodeBMocks)
+	alice.mockResolve(ctx, bob)
 
-	remoteEngine, remoteEngineMocks := NewPrivateTransactionMgrForTesting(t, remoteNodeName)
-	remoteEngineMocks.mockDomain(domainAddress)
-
-	alice := newPartyForTesting(ctx, "alice", localNodeName, localNodeMocks)
-	notary := newPartyForTesting(ctx, "notary", remoteNodeName, remoteEngineMocks)
-
-	alice.mockResolve(ctx, notary)
+	contractConfig := &prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_SENDER,
+	}
+	nodeAMocks.domainSmartContract.On("ContractConfig").Return(contractConfig)
+	nodeBMocks.domainSmartContract.On("ContractConfig").Return(contractConfig)
 
-	initialised := make(chan struct{}, 1)
-	localNodeMocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
-		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
-	})
-	localNodeMocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+	nodeAMocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 		tx := args.Get(1).(*components.PrivateTransaction)
 		tx.PreAssembly = &components.TransactionPreAssembly{
 			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
 				{
-					Lookup:       alice.identityLocator,
-					Algorithm:    algorithms.ECDSA_SECP256K1,
-					VerifierType: verifiers.ETH_ADDRESS,
-				},
-				{
-					Lookup:       notary.identityLocator,
+					Lookup:       bob.identityLocator,
 					Algorithm:    algorithms.ECDSA_SECP256K1,
 					VerifierType: verifiers.ETH_ADDRESS,
 				},
 			},
 		}
-		initialised <- struct{}{}
 	}).Return(nil)
 
-	assembled := make(chan struct{}, 1)
+	// Pick a state that node B wins the bid for
+	resolver := NewContentionResolver()
+	var contendedStateID tktypes.HexBytes
+	for contendedStateID == nil {
+		stateID := tktypes.HexBytes(tktypes.RandBytes(32))
+		if winner, err := resolver.ResolvePair(stateID.String(), nodeAName, nodeBName); err == nil && winner == nodeBName {
+			contendedStateID = stateID
+		}
+	}
 
-	localNodeMocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+	nodeAMocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 		tx := args.Get(2).(*components.PrivateTransaction)
-
 		tx.PostAssembly = &components.TransactionPostAssembly{
 			AssemblyResult: prototk.AssembleTransactionResponse_OK,
 			InputStates: []*components.FullState{
 				{
-					ID:     tktypes.RandBytes(32),
+					ID:     contendedStateID,
 					Schema: tktypes.Bytes32(tktypes.RandBytes(32)),
 					Data:   tktypes.JSONString("foo"),
 				},
 			},
 			AttestationPlan: []*prototk.AttestationRequest{
 				{
-					Name:            "notary",
+					Name:            "bob",
 					AttestationType: prototk.AttestationType_ENDORSE,
 					Algorithm:       algorithms.ECDSA_SECP256K1,
 					VerifierType:    verifiers.ETH_ADDRESS,
 					PayloadType:     signpayloads.OPAQUE_TO_RSV,
 					Parties: []string{
-						notary.identityLocator,
+						bob.identityLocator,
 					},
 				},
 			},
 		}
-		assembled <- struct{}{}
-
 	}).Return(nil)
 
-	localNodeMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
-		go func() {
-			assert.Equal(t, remoteNodeName, args.Get(1).(*components.TransportMessage).Node)
-			transportMessage := args.Get(1).(*components.TransportMessage)
-			remoteEngine.ReceiveTransportMessage(ctx, transportMessage)
-		}()
-	}).Return(nil).Maybe()
-
-	remoteEngineMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+	isMessageType := func(messageType string) any {
+		return mock.MatchedBy(func(msg *components.TransportMessage) bool {
+			return msg.MessageType == messageType
+		})
+	}
+	// Node A never gets a response to its own endorsement request, as node B is the winner of the contention
+	nodeAMocks.transportManager.On("Send", mock.Anything, isMessageType("EndorsementRequest")).Return(nil).Maybe()
+	// The first attempt to hand over to node B fails, and must be retried
+	nodeAMocks.transportManager.On("Send", mock.Anything, isMessageType("DelegationRequest")).Return(errors.New("pop")).Once()
+	nodeAMocks.transportManager.On("Send", mock.Anything, isMessageType("DelegationRequest")).Run(func(args mock.Arguments) {
 		go func() {
 			transportMessage := args.Get(1).(*components.TransportMessage)
-			privateTxManager.ReceiveTransportMessage(ctx, transportMessage)
+			assert.Equal(t, nodeBName, transportMessage.Node)
+			nodeB.ReceiveTransportMessage(ctx, transportMessage)
 		}()
-	}).Return(nil).Maybe()
-
-	remoteEngineMocks.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Return(remoteEngineMocks.domainSmartContract, nil)
-
-	//TODO match endorsement request and verifier args
-	remoteEngineMocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
-		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
-	})
-	remoteEngineMocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
+	}).Return(nil).Once()
+	// Node B acknowledges the delegation once it has taken over coordination
+	nodeBMocks.transportManager.On("Send", mock.Anything, isMessageType("DelegationRequestAcknowledgment")).Run(func(args mock.Arguments) {
+		transportMessage := args.Get(1).(*components.TransportMessage)
+		assert.Equal(t, nodeAName, transportMessage.Node)
+		nodeA.ReceiveTransportMessage(ctx, transportMessage)
+	}).Return(nil).Once()
+
+	nodeBMocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
 		Result:  prototk.EndorseTransactionResponse_SIGN,
 		Payload: []byte("some-endorsement-bytes"),
 		Endorser: &prototk.ResolvedVerifier{
-			Lookup:       notary.identityLocator, //matches whatever was specified in PreAssembly.RequiredVerifiers
-			Verifier:     notary.verifier,
+			Lookup:       bob.identityLocator,
+			Verifier:     bob.verifier,
 			Algorithm:    algorithms.ECDSA_SECP256K1,
 			VerifierType: verifiers.ETH_ADDRESS,
 		},
 	}, nil)
+	bob.mockSign([]byte("some-signature-bytes"))
 
-	notary.mockSign([]byte("some-signature-bytes"))
-
-	remoteEngineMocks.domainSmartContract.On("PrepareTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(
+	nodeBMocks.domainSmartContract.On("PrepareTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(
 		func(args mock.Arguments) {
 			cv, err := testABI[0].Inputs.ParseExternalData(map[string]any{
 				"inputs":  []any{tktypes.Bytes32(tktypes.RandBytes(32))},
@@ -482,54 +1323,82 @@ func TestPrivateTxManagerRemoteNotaryEndorser(t *testing.T) {
 		Inputs: &components.TransactionInputs{
 			Domain: "domain1",
 			To:     *domainAddress,
-			From:   alice.identityLocator, // domain manager would insert the local node name for us, but we've mocked that
+			From:   alice.identityLocator,
 		},
 	}
 
-	mockPublicTxBatch := componentmocks.NewPublicTxBatch(t)
-	mockPublicTxBatch.On("Finalize", mock.Anything).Return().Maybe()
-	mockPublicTxBatch.On("CleanUp", mock.Anything).Return().Maybe()
-
-	mockPublicTxManager := remoteEngineMocks.publicTxManager.(*componentmocks.PublicTxManager)
-	mockPublicTxManager.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockPublicTxBatch, nil)
-
 	signingAddr := tktypes.RandAddress()
-	remoteEngineMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).
+	nodeBMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).
 		Return([]*tktypes.EthAddress{signingAddr}, nil)
 
-	publicTransactions := []components.PublicTxAccepted{
+	mockPublicTxBatch := componentmocks.NewPublicTxBatch(t)
+	mockPublicTxManager := nodeBMocks.publicTxManager.(*componentmocks.PublicTxManager)
+	mockPublicTxManager.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockPublicTxBatch, nil)
+	mockPublicTxBatch.On("Submit", mock.Anything, mock.Anything).Return(nil)
+	mockPublicTxBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	mockPublicTxBatch.On("Accepted").Return([]components.PublicTxAccepted{
 		newFakePublicTx(&components.PublicTxSubmission{
 			Bindings: []*components.PaladinTXReference{{TransactionID: tx.ID, TransactionType: pldapi.TransactionTypePrivate.Enum()}},
 			PublicTxInput: pldapi.PublicTxInput{
 				From: signingAddr,
 			},
 		}, nil),
-	}
-	mockPublicTxBatch.On("Submit", mock.Anything, mock.Anything).Return(nil)
-	mockPublicTxBatch.On("Rejected").Return([]components.PublicTxRejected{})
-	mockPublicTxBatch.On("Accepted").Return(publicTransactions)
+	})
 	mockPublicTxBatch.On("Completed", mock.Anything, true).Return()
 
-	// Flush of domain context happens on the remote node (the notary)
 	dcFlushed := make(chan error, 1)
-	remoteEngineMocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
+	nodeBMocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
 		dcFlushed <- err
 	}, nil)
 
-	err := privateTxManager.Start()
-	assert.NoError(t, err)
+	err := nodeA.Start()
+	require.NoError(t, err)
 
-	err = privateTxManager.handleNewTx(ctx, tx)
-	assert.NoError(t, err)
+	err = nodeA.handleNewTx(ctx, tx)
+	require.NoError(t, err)
 
-	status := pollForStatus(ctx, t, "delegating", privateTxManager, domainAddressString, tx.ID.String(), 200*time.Second)
-	assert.Equal(t, "delegating", status)
+	// Node A is coordinating, and is waiting on the endorsement from bob
+	status := pollForStatus(ctx, t, "signed", nodeA, domainAddressString, tx.ID.String(), timeTillDeadline(t))
+	assert.Equal(t, "signed", status)
+
+	// Now node A is asked by node B to endorse a transaction that spends the same state, and learns
+	// that it has lost the contention for that state to node B
+	var endorsementRequest *components.TransportMessage
+	nodeBTransport := componentmocks.NewTransportManager(t)
+	nodeBTransport.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		endorsementRequest = args.Get(1).(*components.TransportMessage)
+	}).Return(nil).Once()
+	err = NewTransportWriter("domain1", domainAddress, nodeBName, nodeBTransport).SendEndorsementRequest(ctx, nodeAName, domainAddressString, uuid.New().String(),
+		[]*ptmgrtypes.EndorsementRequestParty{{
+			Party: alice.identityLocator,
+			AttestationRequest: &prototk.AttestationRequest{
+				Name:            "alice",
+				AttestationType: prototk.AttestationType_ENDORSE,
+				Algorithm:       algorithms.ECDSA_SECP256K1,
+				VerifierType:    verifiers.ETH_ADDRESS,
+				PayloadType:     signpayloads.OPAQUE_TO_RSV,
+				Parties:         []string{alice.identityLocator},
+			},
+		}},
+		&prototk.TransactionSpecification{}, nil, nil, nil,
+		[]*components.FullState{{ID: contendedStateID, Schema: tktypes.Bytes32(tktypes.RandBytes(32)), Data: tktypes.JSONString("foo")}},
+		nil, nil)
+	require.NoError(t, err)
 
-	status = pollForStatus(ctx, t, "dispatched", remoteEngine, domainAddressString, tx.ID.String(), 200*time.Second)
+	nodeAMocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
+		Result:       prototk.EndorseTransactionResponse_REVERT,
+		RevertReason: confutil.P("state already spent"),
+	}, nil).Maybe()
+	nodeAMocks.transportManager.On("Send", mock.Anything, isMessageType("EndorsementResponse")).Return(nil).Maybe()
+	nodeA.ReceiveTransportMessage(ctx, endorsementRequest)
+
+	status = pollForStatus(ctx, t, "delegated", nodeA, domainAddressString, tx.ID.String(), timeTillDeadline(t))
+	assert.Equal(t, "delegated", status)
+
+	status = pollForStatus(ctx, t, "dispatched", nodeB, domainAddressString, tx.ID.String(), timeTillDeadline(t))
 	assert.Equal(t, "dispatched", status)
 
 	require.NoError(t, <-dcFlushed)
-
 }
 
 func TestPrivateTxManagerEndorsementGroup(t *testing.T) {
@@ -792,6 +1661,72 @@ func TestPrivateTxManagerEndorsementGroup(t *testing.T) {
 	require.NoError(t, <-dcFlushed)
 }
 
+func TestPrivateTxManagerEndorsementRequestForMultipleParties(t *testing.T) {
+	// a single endorsement request message can carry the requests for several parties on the receiving node,
+	// each of which is endorsed and responded to separately
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	bobEngine, bobEngineMocks := NewPrivateTransactionMgrForTesting(t, "bobNode")
+	bobEngineMocks.mockDomain(domainAddress)
+
+	bob := newPartyForTesting(ctx, "bob", "bobNode", bobEngineMocks)
+	dave := newPartyForTesting(ctx, "dave", "bobNode", bobEngineMocks)
+	bob.mockSign([]byte("bob-signature-bytes"))
+	dave.mockSign([]byte("dave-signature-bytes"))
+
+	bobEngineMocks.domainSmartContract.On("EndorseTransaction", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ components.DomainContext, _ *gorm.DB, req *components.PrivateTransactionEndorseRequest) (*components.EndorsementResult, error) {
+			return &components.EndorsementResult{
+				Result:   prototk.EndorseTransactionResponse_SIGN,
+				Payload:  []byte("some-endorsement-bytes"),
+				Endorser: req.Endorser,
+			}, nil
+		})
+
+	endorsers := make(chan string, 2)
+	bobEngineMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		transportMessage := args.Get(1).(*components.TransportMessage)
+		assert.Equal(t, "EndorsementResponse", transportMessage.MessageType)
+		assert.Equal(t, "aliceNode", transportMessage.Node)
+		endorsementResponse := &pbEngine.EndorsementResponse{}
+		require.NoError(t, proto.Unmarshal(transportMessage.Payload, endorsementResponse))
+		endorsement := &prototk.AttestationResult{}
+		require.NoError(t, endorsementResponse.Endorsement.UnmarshalTo(endorsement))
+		endorsers <- endorsement.Verifier.Lookup
+	}).Return(nil).Twice()
+
+	transactionSpecification, err := anypb.New(&prototk.TransactionSpecification{TransactionId: uuid.New().String()})
+	require.NoError(t, err)
+	requests := make([]*pbEngine.EndorsementRequestParty, 0, 2)
+	for _, party := range []string{bob.identityLocator, dave.identityLocator} {
+		attestationRequest, err := anypb.New(&prototk.AttestationRequest{
+			Name:            "endorsers",
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Algorithm:       algorithms.ECDSA_SECP256K1,
+			VerifierType:    verifiers.ETH_ADDRESS,
+			PayloadType:     signpayloads.OPAQUE_TO_RSV,
+			Parties:         []string{bob.identityLocator, dave.identityLocator},
+		})
+		require.NoError(t, err)
+		requests = append(requests, &pbEngine.EndorsementRequestParty{
+			AttestationRequest: attestationRequest,
+			Party:              party,
+		})
+	}
+	payload, err := proto.Marshal(&pbEngine.EndorsementRequest{
+		ContractAddress:          domainAddress.String(),
+		TransactionId:            uuid.New().String(),
+		TransactionSpecification: transactionSpecification,
+		Requests:                 requests,
+	})
+	require.NoError(t, err)
+
+	bobEngine.handleEndorsementRequest(ctx, payload, "aliceNode")
+
+	assert.ElementsMatch(t, []string{bob.identityLocator, dave.identityLocator}, []string{<-endorsers, <-endorsers})
+}
+
 func TestPrivateTxManagerDependantTransactionEndorsedOutOfOrder(t *testing.T) {
 	// extension to the TestPrivateTxManagerEndorsementGroup test
 	// 2 transactions, one dependant on the other
@@ -1021,71 +1956,295 @@ func TestPrivateTxManagerDependantTransactionEndorsedOutOfOrder(t *testing.T) {
 		},
 	}
 
-	attestationResultAny, err := anypb.New(&attestationResult)
+	attestationResultAny, err := anypb.New(&attestationResult)
+	require.NoError(t, err)
+
+	//wait for both transactions to send an endorsement request each
+	<-sentEndorsementRequest
+	<-sentEndorsementRequest
+
+	// endorse transaction 2 before 1 and check that 2 is not dispatched before 1
+	endorsementResponse2 := &pbEngine.EndorsementResponse{
+		ContractAddress: domainAddressString,
+		TransactionId:   tx2.ID.String(),
+		Endorsement:     attestationResultAny,
+	}
+	endorsementResponse2bytes, err := proto.Marshal(endorsementResponse2)
+	require.NoError(t, err)
+
+	//now send the endorsements back
+	aliceEngine.ReceiveTransportMessage(ctx, &components.TransportMessage{
+		MessageType: "EndorsementResponse",
+		Payload:     endorsementResponse2bytes,
+	})
+
+	//unless the tests are running in short mode, wait a second to ensure that the transaction is not dispatched
+	if !testing.Short() {
+		time.Sleep(1 * time.Second)
+	}
+	s, err = aliceEngine.GetTxStatus(ctx, domainAddressString, tx1.ID.String())
+	require.NoError(t, err)
+	assert.NotEqual(t, "dispatch", s.Status)
+
+	s, err = aliceEngine.GetTxStatus(ctx, domainAddressString, tx2.ID.String())
+	require.NoError(t, err)
+	assert.NotEqual(t, "dispatch", s.Status)
+
+	// transaction 2 is reported as blocked, waiting for the endorsement of transaction 1
+	var blocked []*components.BlockedPrivateTransaction
+	require.Eventually(t, func() bool {
+		blocked, err = aliceEngine.GetBlockedTransactions(ctx, domainAddressString)
+		require.NoError(t, err)
+		return len(blocked) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Len(t, blocked, 1)
+	assert.Equal(t, tx2.ID.String(), blocked[0].TxID)
+	require.Len(t, blocked[0].BlockedBy, 1)
+	assert.Equal(t, tx1.ID.String(), blocked[0].BlockedBy[0].TxID)
+	assert.Equal(t, components.PrivateTxBlockedDependency, blocked[0].BlockedBy[0].Reason)
+	assert.NotEmpty(t, blocked[0].BlockedBy[0].States)
+
+	// endorse transaction 1 and check that both it and 2 are dispatched
+	endorsementResponse1 := &pbEngine.EndorsementResponse{
+		ContractAddress: domainAddressString,
+		TransactionId:   tx1.ID.String(),
+		Endorsement:     attestationResultAny,
+	}
+	endorsementResponse1Bytes, err := proto.Marshal(endorsementResponse1)
+	require.NoError(t, err)
+
+	//now send the final endorsement back
+	aliceEngine.ReceiveTransportMessage(ctx, &components.TransportMessage{
+		MessageType: "EndorsementResponse",
+		Payload:     endorsementResponse1Bytes,
+	})
+
+	// at this point we should get a flush of the states
+	dcFlushed := make(chan error, 1)
+	aliceEngineMocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
+		dcFlushed <- err
+	}, nil)
+
+	status := pollForStatus(ctx, t, "dispatched", aliceEngine, domainAddressString, tx1.ID.String(), 200*time.Second)
+	assert.Equal(t, "dispatched", status)
+
+	status = pollForStatus(ctx, t, "dispatched", aliceEngine, domainAddressString, tx2.ID.String(), 200*time.Second)
+	assert.Equal(t, "dispatched", status)
+
+	require.NoError(t, <-dcFlushed)
+
+	blocked, err = aliceEngine.GetBlockedTransactions(ctx, domainAddressString)
+	require.NoError(t, err)
+	assert.Empty(t, blocked)
+
+	//TODO assert that transaction 1 got dispatched before 2
+
+}
+
+func TestPrivateTxManagerRejectsUnrequestedEndorsementResponse(t *testing.T) {
+	ctx := context.Background()
+
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	domainAddressString := domainAddress.String()
+
+	aliceEngine, aliceEngineMocks := NewPrivateTransactionMgrForTesting(t, "aliceNode")
+	aliceEngineMocks.mockDomain(domainAddress)
+
+	_, bobEngineMocks := NewPrivateTransactionMgrForTesting(t, "bobNode")
+	bobEngineMocks.mockDomain(domainAddress)
+
+	alice := newPartyForTesting(ctx, "alice", "aliceNode", aliceEngineMocks)
+	bob := newPartyForTesting(ctx, "bob", "bobNode", bobEngineMocks)
+	alice.mockResolve(ctx, bob)
+
+	aliceEngineMocks.domainSmartContract.On("ContractConfig").Return(&prototk.ContractConfig{
+		CoordinatorSelection: prototk.ContractConfig_COORDINATOR_ENDORSER,
+	})
+	aliceEngineMocks.domainSmartContract.On("InitTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(1).(*components.PrivateTransaction)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       bob.identityLocator,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+		}
+	}).Return(nil)
+	aliceEngineMocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties:         []string{bob.identityLocator},
+				},
+			},
+		}
+	}).Return(nil)
+
+	sentEndorsementRequest := make(chan struct{}, 1)
+	aliceEngineMocks.transportManager.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sentEndorsementRequest <- struct{}{}
+	}).Return(nil).Maybe()
+
+	tx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *domainAddress,
+			From:   alice.identityLocator,
+		},
+	}
+
+	err := aliceEngine.Start()
+	require.NoError(t, err)
+	err = aliceEngine.handleNewTx(ctx, tx)
 	require.NoError(t, err)
-
-	//wait for both transactions to send an endorsement request each
-	<-sentEndorsementRequest
 	<-sentEndorsementRequest
 
-	// endorse transaction 2 before 1 and check that 2 is not dispatched before 1
-	endorsementResponse2 := &pbEngine.EndorsementResponse{
-		ContractAddress: domainAddressString,
-		TransactionId:   tx2.ID.String(),
-		Endorsement:     attestationResultAny,
+	sendEndorsementResponse := func(name string) {
+		endorsementAny, err := anypb.New(&prototk.AttestationResult{
+			Name:            name,
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Payload:         tktypes.RandBytes(32),
+			Verifier: &prototk.ResolvedVerifier{
+				Lookup:       bob.identityLocator,
+				Verifier:     bob.verifier,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		})
+		require.NoError(t, err)
+		endorsementResponseBytes, err := proto.Marshal(&pbEngine.EndorsementResponse{
+			ContractAddress: domainAddressString,
+			TransactionId:   tx.ID.String(),
+			Endorsement:     endorsementAny,
+		})
+		require.NoError(t, err)
+		aliceEngine.ReceiveTransportMessage(ctx, &components.TransportMessage{
+			MessageType: "EndorsementResponse",
+			Payload:     endorsementResponseBytes,
+		})
 	}
-	endorsementResponse2bytes, err := proto.Marshal(endorsementResponse2)
-	require.NoError(t, err)
-
-	//now send the endorsements back
-	aliceEngine.ReceiveTransportMessage(ctx, &components.TransportMessage{
-		MessageType: "EndorsementResponse",
-		Payload:     endorsementResponse2bytes,
-	})
 
-	//unless the tests are running in short mode, wait a second to ensure that the transaction is not dispatched
-	if !testing.Short() {
-		time.Sleep(1 * time.Second)
-	}
-	s, err = aliceEngine.GetTxStatus(ctx, domainAddressString, tx1.ID.String())
-	require.NoError(t, err)
-	assert.NotEqual(t, "dispatch", s.Status)
+	// A response without an attestation name is rejected before it reaches the sequencer,
+	// and one for an attestation that was never requested is rejected by the sequencer
+	sendEndorsementResponse("")
+	sendEndorsementResponse("unrequested")
+
+	var s components.PrivateTxStatus
+	require.Eventually(t, func() bool {
+		s, err = aliceEngine.GetTxStatus(ctx, domainAddressString, tx.ID.String())
+		require.NoError(t, err)
+		return s.LatestError != ""
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Regexp(t, "PD011845.*unrequested", s.LatestError)
+	assert.NotEqual(t, "dispatched", s.Status)
+}
 
-	s, err = aliceEngine.GetTxStatus(ctx, domainAddressString, tx2.ID.String())
+func TestPrivateTxManagerGetBlockedTransactionsNoSequencer(t *testing.T) {
+	ctx := context.Background()
+	privateTxManager, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	blocked, err := privateTxManager.GetBlockedTransactions(ctx, tktypes.RandAddress().String())
 	require.NoError(t, err)
-	assert.NotEqual(t, "dispatch", s.Status)
+	assert.Empty(t, blocked)
+}
 
-	// endorse transaction 1 and check that both it and 2 are dispatched
-	endorsementResponse1 := &pbEngine.EndorsementResponse{
-		ContractAddress: domainAddressString,
-		TransactionId:   tx1.ID.String(),
-		Endorsement:     attestationResultAny,
-	}
-	endorsementResponse1Bytes, err := proto.Marshal(endorsementResponse1)
-	require.NoError(t, err)
+func TestPrivateTxManagerConfirmationEvents(t *testing.T) {
+	ctx := context.Background()
+	privateTxManager, mocks := NewPrivateTransactionMgrForTesting(t, "node1")
+	contractAddress := tktypes.RandAddress().String()
+	signingAddress := tktypes.RandAddress().String()
 
-	//now send the final endorsement back
-	aliceEngine.ReceiveTransportMessage(ctx, &components.TransportMessage{
-		MessageType: "EndorsementResponse",
-		Payload:     endorsementResponse1Bytes,
+	events := make(chan components.PrivateTxEvent, 10)
+	privateTxManager.Subscribe(ctx, func(event components.PrivateTxEvent) {
+		events <- event
 	})
 
-	// at this point we should get a flush of the states
-	dcFlushed := make(chan error, 1)
-	aliceEngineMocks.domainContext.On("Flush", mock.Anything).Return(func(err error) {
-		dcFlushed <- err
-	}, nil)
-
-	status := pollForStatus(ctx, t, "dispatched", aliceEngine, domainAddressString, tx1.ID.String(), 200*time.Second)
-	assert.Equal(t, "dispatched", status)
-
-	status = pollForStatus(ctx, t, "dispatched", aliceEngine, domainAddressString, tx2.ID.String(), 200*time.Second)
-	assert.Equal(t, "dispatched", status)
-
-	require.NoError(t, <-dcFlushed)
-
-	//TODO assert that transaction 1 got dispatched before 2
-
+	// a transaction that is dispatched, and then confirmed successfully
+	succeeded := uuid.New()
+	NewPublisher(privateTxManager, contractAddress).PublishTransactionDispatchedEvent(ctx, succeeded.String(), 42, signingAddress, true)
+	assert.Equal(t, &components.TransactionDispatchedEvent{
+		TransactionID:   succeeded.String(),
+		ContractAddress: contractAddress,
+		Nonce:           42,
+		SigningAddress:  signingAddress,
+	}, <-events)
+
+	privateTxManager.PrivateTransactionConfirmed(ctx, &components.TxCompletion{
+		ReceiptInput: components.ReceiptInput{
+			ReceiptType:   components.RT_Success,
+			TransactionID: succeeded,
+			OnChain:       tktypes.OnChainLocation{Type: tktypes.OnChainTransaction, BlockNumber: 1000},
+		},
+	})
+	assert.Equal(t, &components.TransactionConfirmedEvent{
+		TransactionID:   succeeded.String(),
+		ContractAddress: contractAddress,
+		Nonce:           42,
+		SigningAddress:  signingAddress,
+		BlockNumber:     1000,
+		Success:         true,
+	}, <-events)
+
+	// a transaction that is dispatched, and then fails on the base ledger
+	failed := uuid.New()
+	NewPublisher(privateTxManager, contractAddress).PublishTransactionDispatchedEvent(ctx, failed.String(), 43, signingAddress, true)
+	<-events
+
+	// a transaction that is dispatched without a public transaction is never confirmed, so is not tracked
+	prepared := uuid.New()
+	NewPublisher(privateTxManager, contractAddress).PublishTransactionDispatchedEvent(ctx, prepared.String(), 0, signingAddress, false)
+	<-events
+	assert.NotContains(t, privateTxManager.awaitingConfirmation, prepared.String())
+
+	mocks.txManager.On("FinalizeTransactions", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	postCommit, err := privateTxManager.NotifyFailedPublicTx(ctx, privateTxManager.components.Persistence().DB(), []*components.PublicTxMatch{
+		{
+			PaladinTXReference: components.PaladinTXReference{TransactionID: failed, TransactionType: pldapi.TransactionTypePrivate.Enum()},
+			IndexedTransactionNotify: &blockindexer.IndexedTransactionNotify{
+				IndexedTransaction: pldapi.IndexedTransaction{BlockNumber: 1001, Nonce: 43},
+			},
+		},
+	})
+	require.NoError(t, err)
+	// nothing is published until the failure receipts are committed
+	assert.Empty(t, events)
+	postCommit()
+	assert.Equal(t, &components.TransactionConfirmedEvent{
+		TransactionID:   failed.String(),
+		ContractAddress: contractAddress,
+		Nonce:           43,
+		SigningAddress:  signingAddress,
+		BlockNumber:     1001,
+		Success:         false,
+	}, <-events)
+
+	// once confirmed, the dispatch is no longer tracked
+	assert.Empty(t, privateTxManager.awaitingConfirmation)
+
+	// a re-org then changes the outcome of the failed transaction
+	privateTxManager.NotifyCorrectedPublicTx(ctx, []*components.PublicTxMatch{
+		{
+			PaladinTXReference: components.PaladinTXReference{TransactionID: failed, TransactionType: pldapi.TransactionTypePrivate.Enum()},
+			IndexedTransactionNotify: &blockindexer.IndexedTransactionNotify{
+				IndexedTransaction: pldapi.IndexedTransaction{BlockNumber: 1002, Nonce: 43, Result: pldapi.TXResult_SUCCESS.Enum()},
+			},
+		},
+	})
+	assert.Equal(t, &components.TransactionConfirmedEvent{
+		TransactionID: failed.String(),
+		BlockNumber:   1002,
+		Success:       true,
+		Corrected:     true,
+	}, <-events)
 }
 
 func TestPrivateTxManagerLocalBlockedTransaction(t *testing.T) {
@@ -1395,15 +2554,14 @@ func TestPrivateTxManagerMiniLoad(t *testing.T) {
 		numTransactions int
 	}{
 		{"no-latency", func() time.Duration { return 0 }, 5},
-		{"low-latency", func() time.Duration { return 10 * time.Millisecond }, 500},
-		{"medium-latency", func() time.Duration { return 50 * time.Millisecond }, 500},
-		{"high-latency", func() time.Duration { return 100 * time.Millisecond }, 500},
-		{"random-none-to-low-latency", func() time.Duration { return time.Duration(r.Intn(10)) * time.Millisecond }, 500},
-		{"random-none-to-high-latency", func() time.Duration { return time.Duration(r.Intn(100)) * time.Millisecond }, 500},
+		{"low-latency", func() time.Duration { return 10 * time.Millisecond }, 1000},
+		{"medium-latency", func() time.Duration { return 50 * time.Millisecond }, 1000},
+		{"high-latency", func() time.Duration { return 100 * time.Millisecond }, 1000},
+		{"random-none-to-low-latency", func() time.Duration { return time.Duration(r.Intn(10)) * time.Millisecond }, 1000},
+		{"random-none-to-high-latency", func() time.Duration { return time.Duration(r.Intn(100)) * time.Millisecond }, 1000},
 	}
-	//500 is the maximum we can do in this test for now until either
-	//a) implement config to allow us to define MaxConcurrentTransactions
-	//b) implement ( or mock) transaction dispatch processing all the way to confirmation
+	// Dispatched transactions are evicted from the sequencer's in-flight map, so the number of transactions
+	// is no longer limited by MaxConcurrentProcess - only those that are yet to be dispatched count towards it
 
 	for _, test := range loadTests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1667,6 +2825,8 @@ type dependencyMocks struct {
 	publicTxManager     components.PublicTxManager /* could be fake or mock */
 	identityResolver    *componentmocks.IdentityResolver
 	txManager           *componentmocks.TXManager
+	coordinators        sync.Map // transaction ID to the coordinator recorded against it
+	labels              sync.Map // transaction ID to the label recorded against it
 }
 
 // For Black box testing we return components.PrivateTxManager
@@ -1687,6 +2847,11 @@ func (f *fakePublicTxManager) GetPublicTransactionForHash(ctx context.Context, d
 	panic("unimplemented")
 }
 
+// GetPublicTransactionSubmissions implements components.PublicTxManager.
+func (f *fakePublicTxManager) GetPublicTransactionSubmissions(ctx context.Context, dbTX *gorm.DB, from tktypes.EthAddress, nonce uint64) ([]*pldapi.PublicTxSubmissionData, error) {
+	panic("unimplemented")
+}
+
 // QueryPublicTxForTransactions implements components.PublicTxManager.
 func (f *fakePublicTxManager) QueryPublicTxForTransactions(ctx context.Context, dbTX *gorm.DB, boundToTxns []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error) {
 	panic("unimplemented")
@@ -1697,8 +2862,13 @@ func (f *fakePublicTxManager) QueryPublicTxWithBindings(ctx context.Context, dbT
 	panic("unimplemented")
 }
 
+// QueryPublicTxRejections implements components.PublicTxManager.
+func (f *fakePublicTxManager) QueryPublicTxRejections(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.PublicTxRejection, error) {
+	panic("unimplemented")
+}
+
 // MatchUpdateConfirmedTransactions implements components.PublicTxManager.
-func (f *fakePublicTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify) ([]*components.PublicTxMatch, error) {
+func (f *fakePublicTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify, applyCorrections bool) ([]*components.PublicTxMatch, error) {
 	panic("unimplemented")
 }
 
@@ -1707,6 +2877,16 @@ func (f *fakePublicTxManager) NotifyConfirmPersisted(ctx context.Context, confir
 	panic("unimplemented")
 }
 
+// EstimateGas implements components.PublicTxManager.
+func (f *fakePublicTxManager) EstimateGas(ctx context.Context, txi *pldapi.PublicTxInput) (tktypes.HexUint64, error) {
+	panic("unimplemented")
+}
+
+// ResubmitAllForAddress implements components.PublicTxManager.
+func (f *fakePublicTxManager) ResubmitAllForAddress(ctx context.Context, from tktypes.EthAddress) ([]uint64, error) {
+	panic("unimplemented")
+}
+
 // PostInit implements components.PublicTxManager.
 func (f *fakePublicTxManager) PostInit(components.AllComponents) error {
 	panic("unimplemented")
@@ -1750,6 +2930,10 @@ func (f *fakePublicTxBatch) Rejected() []components.PublicTxRejected {
 	return f.rejected
 }
 
+func (f *fakePublicTxBatch) PersistRejected(ctx context.Context, dbTX *gorm.DB) error {
+	return nil
+}
+
 type fakePublicTx struct {
 	t         *components.PublicTxSubmission
 	rejectErr error
@@ -1841,6 +3025,14 @@ func NewPrivateTransactionMgrForTestingWithFakePublicTxManager(t *testing.T, pub
 	mocks.transportManager.On("LocalNodeName").Return(nodeName)
 	mocks.allComponents.On("KeyManager").Return(mocks.keyManager).Maybe()
 	mocks.allComponents.On("TxManager").Return(mocks.txManager).Maybe()
+	mocks.txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, txID := range args[3].([]uuid.UUID) {
+			mocks.coordinators.Store(txID, args[2].(string))
+		}
+	}).Return(nil).Maybe()
+	mocks.txManager.On("SetTransactionLabel", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mocks.labels.Store(args[2].(uuid.UUID), args[3].(string))
+	}).Return(nil).Maybe()
 	mocks.allComponents.On("PublicTxManager").Return(publicTxMgr).Maybe()
 	mocks.allComponents.On("Persistence").Return(persistence.NewUnitTestPersistence(ctx, "privatetxmgr")).Maybe()
 	mocks.domainSmartContract.On("Domain").Return(mocks.domain).Maybe()
@@ -1879,6 +3071,10 @@ func (m *dependencyMocks) mockDomain(domainAddress *tktypes.EthAddress) {
 	m.stateStore.On("NewDomainContext", mock.Anything, m.domain, *domainAddress, mock.Anything).Return(m.domainContext).Maybe()
 	m.domainMgr.On("GetSmartContractByAddress", mock.Anything, *domainAddress).Maybe().Return(m.domainSmartContract, nil)
 	m.domain.On("Configuration").Return(&prototk.DomainConfig{}).Maybe()
+	m.domain.On("SequentialEndorsement").Return(false).Maybe()
+	m.domain.On("StateDistributionOrder").Return(pldconf.StateDistributionBeforeDispatch).Maybe()
+	m.domainSmartContract.On("ValidateAssembled", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.domainSmartContract.On("BuildAttestationPayloads", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
 }
 
 func timeTillDeadline(t *testing.T) time.Duration {
@@ -1901,6 +3097,8 @@ func mockDomainSmartContractAndCtx(t *testing.T, m *dependencyMocks) (*component
 
 	mDomain := componentmocks.NewDomain(t)
 	mDomain.On("Name").Return("domain1").Maybe()
+	mDomain.On("SequentialEndorsement").Return(false).Maybe()
+	mDomain.On("StateDistributionOrder").Return(pldconf.StateDistributionBeforeDispatch).Maybe()
 
 	mPSC := componentmocks.NewDomainSmartContract(t)
 	mPSC.On("Address").Return(contractAddr).Maybe()
@@ -1955,6 +3153,33 @@ func TestCallPrivateSmartContractOk(t *testing.T) {
 
 }
 
+func TestCallPrivateSmartContractMaxDepthExceeded(t *testing.T) {
+
+	ctx := context.Background()
+	ptx, m := NewPrivateTransactionMgrForTesting(t, "node1")
+	ptx.maxCallDepth = 3
+
+	_, mPSC := mockDomainSmartContractAndCtx(t, m)
+
+	// Each call to the contract makes a nested call back to the same contract
+	calls := 0
+	mPSC.On("InitCall", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, call *components.TransactionInputs) ([]*prototk.ResolveVerifierRequest, error) {
+			calls++
+			_, err := ptx.CallPrivateSmartContract(ctx, call)
+			return nil, err
+		},
+	)
+
+	_, err := ptx.CallPrivateSmartContract(ctx, &components.TransactionInputs{
+		To:     mPSC.Address(),
+		Inputs: tktypes.RawJSON(`{}`),
+	})
+	require.Regexp(t, "PD011841", err)
+	assert.Equal(t, 3, calls)
+
+}
+
 func TestCallPrivateSmartContractBadContract(t *testing.T) {
 
 	ctx := context.Background()
@@ -2053,3 +3278,352 @@ func TestCallPrivateSmartContractExecCallFail(t *testing.T) {
 	require.Regexp(t, "pop", err)
 
 }
+
+func mockEstimatePrivateTransactionGas(t *testing.T, m *dependencyMocks, endorser string) (*componentmocks.DomainSmartContract, *componentmocks.DomainContext) {
+	contractAddr := *tktypes.RandAddress()
+
+	mDomain := componentmocks.NewDomain(t)
+	mDomain.On("Name").Return("domain1").Maybe()
+
+	mPSC := componentmocks.NewDomainSmartContract(t)
+	mPSC.On("Address").Return(contractAddr).Maybe()
+	mPSC.On("Domain").Return(mDomain).Maybe()
+	m.domainMgr.On("GetSmartContractByAddress", mock.Anything, contractAddr).Return(mPSC, nil)
+
+	// The domain context must be closed, and is never flushed
+	mDC := componentmocks.NewDomainContext(t)
+	m.stateStore.On("NewDomainContext", mock.Anything, mDomain, contractAddr).Return(mDC)
+	mDC.On("Close").Return().Once()
+
+	m.identityResolver.On("ResolveVerifier", mock.Anything, "alice@node1", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(tktypes.RandAddress().String(), nil)
+	mPSC.On("InitTransaction", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		tx := args[1].(*components.PrivateTransaction)
+		assert.Equal(t, prototk.TransactionSpecification_SEND_TRANSACTION, tx.Inputs.Intent)
+		tx.PreAssembly = &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{},
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{Lookup: "alice@node1", Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS},
+			},
+		}
+	})
+	mPSC.On("AssembleTransaction", mDC, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		tx := args[2].(*components.PrivateTransaction)
+		tx.PostAssembly = &components.TransactionPostAssembly{
+			AssemblyResult: prototk.AssembleTransactionResponse_OK,
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "sign",
+					AttestationType: prototk.AttestationType_SIGN,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Payload:         []byte("payload"),
+					Parties:         []string{"alice@node1"},
+				},
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties:         []string{endorser},
+				},
+			},
+		}
+	})
+	mPSC.On("ValidateAssembled", mDC, mock.Anything, mock.Anything).Return(nil)
+	mPSC.On("BuildAttestationPayloads", mDC, mock.Anything, mock.Anything).Return(nil)
+	m.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{Verifier: &pldapi.KeyVerifier{Verifier: tktypes.RandAddress().String()}}, nil)
+	m.keyManager.On("Sign", mock.Anything, mock.Anything, signpayloads.OPAQUE_TO_RSV, []byte("payload")).
+		Return([]byte("signature"), nil)
+
+	return mPSC, mDC
+}
+
+func TestEstimatePrivateTransactionGasOk(t *testing.T) {
+
+	ctx := context.Background()
+	ptx, m := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	mPSC, mDC := mockEstimatePrivateTransactionGas(t, m, "notary@node1")
+
+	notaryAddr := tktypes.RandAddress()
+	m.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "notary", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{Verifier: &pldapi.KeyVerifier{Verifier: notaryAddr.String()}}, nil)
+	mPSC.On("EndorseTransaction", mDC, mock.Anything, mock.MatchedBy(func(req *components.PrivateTransactionEndorseRequest) bool {
+		return len(req.Signatures) == 1 && string(req.Signatures[0].Payload) == "signature"
+	})).Return(&components.EndorsementResult{
+		Result:   prototk.EndorseTransactionResponse_ENDORSER_SUBMIT,
+		Endorser: &prototk.ResolvedVerifier{Lookup: "notary@node1", Verifier: notaryAddr.String()},
+	}, nil)
+	m.keyManager.On("ResolveEthAddressNewDatabaseTX", mock.Anything, "notary").Return(notaryAddr, nil)
+
+	fnDef := &abi.Entry{Name: "transfer", Type: abi.Function, Inputs: abi.ParameterArray{
+		{Name: "amount", Type: "uint256"},
+	}}
+	mPSC.On("PrepareTransaction", mDC, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		tx := args[2].(*components.PrivateTransaction)
+		assert.Equal(t, "notary@node1", tx.Signer)
+		tx.PreparedPublicTransaction = &pldapi.TransactionInput{
+			ABI: abi.ABI{fnDef},
+			TransactionBase: pldapi.TransactionBase{
+				Data: tktypes.RawJSON(`{"amount": 100}`),
+			},
+		}
+	})
+
+	expectedData, err := fnDef.EncodeCallDataJSON([]byte(`{"amount": 100}`))
+	require.NoError(t, err)
+	contractAddr := mPSC.Address()
+	m.publicTxManager.(*componentmocks.PublicTxManager).On("EstimateGas", mock.Anything, &pldapi.PublicTxInput{
+		From: notaryAddr,
+		To:   &contractAddr,
+		Data: expectedData,
+		PublicTxOptions: pldapi.PublicTxOptions{
+			Gas: confutil.P(tktypes.HexUint64(100000)),
+		},
+	}).Return(tktypes.HexUint64(12345), nil)
+
+	gas, err := ptx.EstimatePrivateTransactionGas(ctx, &components.TransactionInputs{
+		From:     "alice@node1",
+		To:       contractAddr,
+		Function: fnDef,
+		Inputs:   tktypes.RawJSON(`{"amount": 100}`),
+	}, pldapi.PublicTxOptions{
+		Gas: confutil.P(tktypes.HexUint64(100000)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tktypes.HexUint64(12345), gas)
+
+}
+
+func TestEstimatePrivateTransactionGasRemoteEndorser(t *testing.T) {
+
+	ctx := context.Background()
+	ptx, m := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	mPSC, _ := mockEstimatePrivateTransactionGas(t, m, "notary@node2")
+
+	_, err := ptx.EstimatePrivateTransactionGas(ctx, &components.TransactionInputs{
+		From:   "alice@node1",
+		To:     mPSC.Address(),
+		Inputs: tktypes.RawJSON(`{}`),
+	}, pldapi.PublicTxOptions{})
+	assert.Regexp(t, "PD011860.*notary@node2", err)
+
+}
+
+func TestEstimatePrivateTransactionGasNoPublicTx(t *testing.T) {
+
+	ctx := context.Background()
+	ptx, m := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	mPSC, mDC := mockEstimatePrivateTransactionGas(t, m, "notary@node1")
+
+	m.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "notary", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{Verifier: &pldapi.KeyVerifier{Verifier: tktypes.RandAddress().String()}}, nil)
+	mPSC.On("EndorseTransaction", mDC, mock.Anything, mock.Anything).Return(&components.EndorsementResult{
+		Result:   prototk.EndorseTransactionResponse_SIGN,
+		Endorser: &prototk.ResolvedVerifier{Lookup: "notary@node1"},
+		Payload:  []byte("endorse"),
+	}, nil)
+	m.keyManager.On("Sign", mock.Anything, mock.Anything, signpayloads.OPAQUE_TO_RSV, []byte("endorse")).
+		Return([]byte("endorsement"), nil)
+	mPSC.On("PrepareTransaction", mDC, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		tx := args[2].(*components.PrivateTransaction)
+		tx.PreparedPrivateTransaction = &pldapi.TransactionInput{}
+	})
+
+	_, err := ptx.EstimatePrivateTransactionGas(ctx, &components.TransactionInputs{
+		From:   "alice@node1",
+		To:     mPSC.Address(),
+		Inputs: tktypes.RawJSON(`{}`),
+	}, pldapi.PublicTxOptions{})
+	assert.Regexp(t, "PD011861", err)
+
+}
+
+func TestEstimatePrivateTransactionGasBadDomainName(t *testing.T) {
+
+	ctx := context.Background()
+	ptx, m := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	_, mPSC := mockDomainSmartContractAndCtx(t, m)
+
+	_, err := ptx.EstimatePrivateTransactionGas(ctx, &components.TransactionInputs{
+		Domain: "does-not-match",
+		To:     mPSC.Address(),
+		Inputs: tktypes.RawJSON(`{}`),
+	}, pldapi.PublicTxOptions{})
+	assert.Regexp(t, "PD011825", err)
+
+}
+
+func TestHandleDelegationRequestAcknowledgment(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.maxConcurrentProcess = 0
+	testOc.handoffNodes = []string{"node2"}
+	p := &privateTxManager{sequencers: map[string]*Sequencer{testOc.contractAddress.String(): testOc}}
+
+	testTx := &components.PrivateTransaction{ID: uuid.New()}
+	var delegationID string
+	dependencyMocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Run(func(args mock.Arguments) {
+		delegationID = args.String(1)
+	}).Return(nil).Once()
+	assert.False(t, testOc.ProcessNewTransaction(ctx, testTx))
+
+	// Garbage and acknowledgments of unknown delegations are ignored
+	p.handleDelegationRequestAcknowledgment(ctx, []byte("!!! not a proto"))
+	unknownAck, err := proto.Marshal(&pbEngine.DelegationRequestAcknowledgment{TransactionId: testTx.ID.String(), DelegationId: uuid.New().String()})
+	require.NoError(t, err)
+	p.handleDelegationRequestAcknowledgment(ctx, unknownAck)
+	assert.Len(t, testOc.pendingHandoffs, 1)
+
+	dependencyMocks.txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, "node2", []uuid.UUID{testTx.ID}).Return(nil).Once()
+	ack, err := proto.Marshal(&pbEngine.DelegationRequestAcknowledgment{TransactionId: testTx.ID.String(), DelegateNodeId: "node2", DelegationId: delegationID})
+	require.NoError(t, err)
+	p.handleDelegationRequestAcknowledgment(ctx, ack)
+	assert.Empty(t, testOc.pendingHandoffs)
+}
+
+func TestInvalidEndorsementRevertPolicy(t *testing.T) {
+	p := NewPrivateTransactionMgr(context.Background(), &pldconf.PrivateTxManagerConfig{
+		Sequencer: pldconf.PrivateTxManagerSequencerConfig{
+			EndorsementRevertPolicy: confutil.P("wrong"),
+		},
+	})
+	err := p.PostInit(nil)
+	assert.Regexp(t, "PD011847.*wrong", err)
+}
+
+func TestInvalidFlushFailurePolicy(t *testing.T) {
+	p := NewPrivateTransactionMgr(context.Background(), &pldconf.PrivateTxManagerConfig{
+		Sequencer: pldconf.PrivateTxManagerSequencerConfig{
+			FlushFailurePolicy: confutil.P("wrong"),
+		},
+	})
+	err := p.PostInit(nil)
+	assert.Regexp(t, "PD011857.*wrong", err)
+}
+
+func TestInvalidSubmitSignerPool(t *testing.T) {
+	p := NewPrivateTransactionMgr(context.Background(), &pldconf.PrivateTxManagerConfig{
+		Sequencer: pldconf.PrivateTxManagerSequencerConfig{
+			SubmitSigners: pldconf.SubmitSignerPoolConfig{
+				Selection: confutil.P("wrong"),
+			},
+		},
+	})
+	err := p.PostInit(nil)
+	assert.Regexp(t, "PD011859.*wrong", err)
+
+	p = NewPrivateTransactionMgr(context.Background(), &pldconf.PrivateTxManagerConfig{
+		Sequencer: pldconf.PrivateTxManagerSequencerConfig{
+			SubmitSigners: pldconf.SubmitSignerPoolConfig{
+				PoolSize: confutil.P(0),
+			},
+		},
+	})
+	err = p.PostInit(nil)
+	assert.Regexp(t, "PD011859.*0", err)
+}
+
+func TestGetTxStats(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	now := time.Now()
+	stageAt := func(entered time.Time, stage components.PrivateTxStage, duration time.Duration) *stageTiming {
+		st := &stageTiming{stage: stage, entered: entered}
+		if duration > 0 {
+			st.exited = confutil.P(entered.Add(duration))
+		}
+		return st
+	}
+	stage := func(stage components.PrivateTxStage, duration time.Duration) *stageTiming {
+		return stageAt(now.Add(-1*time.Minute), stage, duration)
+	}
+	newFlow := func(status string, stageTimings ...*stageTiming) *transactionFlow {
+		return &transactionFlow{
+			transaction:  &components.PrivateTransaction{ID: uuid.New()},
+			status:       status,
+			clock:        ptmgrtypes.RealClock(),
+			stageTimings: stageTimings,
+		}
+	}
+	newStatsSequencer := func(flows ...*transactionFlow) *Sequencer {
+		s := &Sequencer{
+			ctx:                     ctx,
+			maxConcurrentProcess:    10,
+			pendingEvents:           make(chan ptmgrtypes.PrivateTransactionEvent, 10),
+			incompleteTxSProcessMap: make(map[string]ptmgrtypes.TransactionFlow),
+		}
+		for _, tf := range flows {
+			s.incompleteTxSProcessMap[tf.ID().String()] = tf
+		}
+		return s
+	}
+
+	completed := newFlow("confirmed", stage(components.PrivateTxStageAssembling, 6*time.Second), stage(components.PrivateTxStageEndorsing, 5*time.Second))
+	// exited before the window, so not included
+	completedEarlier := newFlow("confirmed", stageAt(now.Add(-2*time.Hour), components.PrivateTxStageAssembling, 60*time.Second))
+	// exited before the retention period, so not retained at all
+	completedExpired := newFlow("confirmed", stageAt(now.Add(-48*time.Hour), components.PrivateTxStageAssembling, 60*time.Second))
+	seq1 := newStatsSequencer(
+		newFlow("assembled", stage(components.PrivateTxStageAssembling, 2*time.Second), stage(components.PrivateTxStageEndorsing, 0)),
+		newFlow("endorsed", stage(components.PrivateTxStageAssembling, 4*time.Second), stage(components.PrivateTxStageEndorsing, 3*time.Second)),
+		completed, completedEarlier, completedExpired,
+	)
+	// the latency of a completed transaction is retained once it is no longer in flight
+	seq1.removeTransactionProcessor(completedExpired.ID().String())
+	seq1.removeTransactionProcessor(completedEarlier.ID().String())
+	seq1.removeTransactionProcessor(completed.ID().String())
+	assert.Len(t, seq1.completedStageVisits, 3)
+
+	seq2 := newStatsSequencer(newFlow("delegated"))
+	queued := seq2.ProcessInFlightTransaction(ctx, &components.PrivateTransaction{ID: uuid.New()})
+	assert.False(t, queued)
+
+	p.sequencers[tktypes.RandAddress().String()] = seq1
+	p.sequencers[tktypes.RandAddress().String()] = seq2
+
+	stats := p.GetTxStats(ctx, tktypes.Timestamp(now.Add(-1*time.Hour).UnixNano()))
+	assert.Equal(t, map[string]int{
+		"assembled": 1,
+		"endorsed":  1,
+		"delegated": 1,
+		"new":       1,
+	}, stats.ByStatus)
+	assert.Equal(t, 3, stats.StageLatency[components.PrivateTxStageAssembling].Count)
+	assert.Equal(t, 4*time.Second, stats.StageLatency[components.PrivateTxStageAssembling].Average())
+	assert.Equal(t, 2, stats.StageLatency[components.PrivateTxStageEndorsing].Count)
+	assert.Equal(t, 4*time.Second, stats.StageLatency[components.PrivateTxStageEndorsing].Average())
+	assert.Nil(t, stats.StageLatency[components.PrivateTxStageDispatching])
+	assert.Zero(t, stats.StageLatency[components.PrivateTxStageDispatching].Average())
+	assert.Equal(t, 1, stats.DelegatedIn)
+}
+
+func TestCancelTransactionNoSequencer(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+
+	err := p.CancelTransaction(ctx, tktypes.RandAddress().String(), uuid.New())
+	assert.Regexp(t, "PD011852", err)
+}
+
+func TestRetainStageVisitsBounded(t *testing.T) {
+	s := &Sequencer{}
+	now := tktypes.TimestampNow()
+	timings := make([]*components.PrivateTxStageTiming, maxCompletedStageVisits+5)
+	for i := range timings {
+		timings[i] = &components.PrivateTxStageTiming{Stage: components.PrivateTxStageAssembling, Entered: now, Exited: confutil.P(now + tktypes.Timestamp(i))}
+	}
+	s.retainStageVisits(timings)
+	assert.Len(t, s.completedStageVisits, maxCompletedStageVisits)
+	// the oldest are dropped
+	assert.Equal(t, now+5, *s.completedStageVisits[0].Exited)
+}
@@ -101,6 +101,53 @@ type TransactionDelegatedEvent struct {
 	PrivateTransactionEventBase
 }
 
+// the local node lost a contention bid for one or more of the states used by the transaction
+// and coordination of the transaction must move to the node that won the bid
+type TransactionContentionLostEvent struct {
+	PrivateTransactionEventBase
+	WinningNode string
+}
+
+// the node that a transaction was delegated to acknowledged the delegation with the given ID
+type TransactionDelegationAcknowledgedEvent struct {
+	PrivateTransactionEventBase
+	DelegationID string
+}
+
+type TransactionDelegationFailedEvent struct {
+	PrivateTransactionEventBase
+	Error string
+}
+
+// the domain has validated the assembly of the transaction, and built the payloads of the attestation plan,
+// or failed to do so with the given error
+type TransactionAssemblyValidatedEvent struct {
+	PrivateTransactionEventBase
+	ValidationID    string
+	AttestationPlan []*prototk.AttestationRequest
+	Error           string
+}
+
+// the transaction was assembled to spend a state that another in-flight transaction had already claimed,
+// so it must be re-assembled before it can be endorsed
+type TransactionInputStateConflictEvent struct {
+	PrivateTransactionEventBase
+	StateID               string
+	ClaimingTransactionID string
+}
+
+// persisting the dispatch batch containing the transaction (or one it spends the states of) failed, so none
+// of the states flushed with it were written and its states have been discarded from the domain context
+type TransactionDispatchFailedEvent struct {
+	PrivateTransactionEventBase
+	Error string
+}
+
+type TransactionSignFailedEvent struct {
+	PrivateTransactionEventBase
+	Error string
+}
+
 type TransactionBlockedEvent struct {
 	PrivateTransactionEventBase
 }
@@ -138,9 +185,28 @@ func (event *ResolveVerifierResponseEvent) Validate(ctx context.Context) error {
 		//ts.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInvalidEventMissingField), "Verifier")
 		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerInvalidEventMissingField, "Verifier")
 	}
+	if event.VerifierType == nil {
+		log.L(ctx).Error("VerifierType is nil")
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerInvalidEventMissingField, "VerifierType")
+	}
 	return nil
 }
 
+// Raised when another coordinator asks us to endorse one of its transactions, so that the sequencer loop can check
+// whether that transaction spends any of the states our own transactions are spending
+type EndorsementContentionEvent struct {
+	PrivateTransactionEventBase
+	CoordinatorNode string
+	InputStateIDs   []string
+}
+
+// A request to cancel a transaction that has not yet been dispatched. The outcome is written to Result
+// from the sequencer loop, so it must be buffered.
+type TransactionCancelEvent struct {
+	PrivateTransactionEventBase
+	Result chan error
+}
+
 type TransactionFinalizedEvent struct {
 	PrivateTransactionEventBase
 }
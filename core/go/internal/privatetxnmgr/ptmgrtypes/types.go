@@ -45,16 +45,22 @@ type Transaction struct {
 type Publisher interface {
 	//Service for sending messages and events within the local node
 	PublishTransactionBlockedEvent(ctx context.Context, transactionId string)
-	PublishTransactionDispatchedEvent(ctx context.Context, transactionId string, nonce uint64, signingAddress string)
+	PublishTransactionDispatchedEvent(ctx context.Context, transactionId string, nonce uint64, signingAddress string, awaitConfirmation bool)
 	PublishTransactionAssembledEvent(ctx context.Context, transactionId string)
 	PublishTransactionAssembleFailedEvent(ctx context.Context, transactionId string, errorMessage string)
+	PublishTransactionAssemblyValidatedEvent(ctx context.Context, transactionId string, validationID string, attestationPlan []*prototk.AttestationRequest, errorMessage string)
 	PublishTransactionSignedEvent(ctx context.Context, transactionId string, attestationResult *prototk.AttestationResult)
+	PublishTransactionSignFailedEvent(ctx context.Context, transactionId string, errorMessage string)
 	PublishTransactionEndorsedEvent(ctx context.Context, transactionId string, attestationResult *prototk.AttestationResult, revertReason *string)
 	PublishResolveVerifierResponseEvent(ctx context.Context, transactionId string, lookup, algorithm, verifier, verifierType string)
 	PublishResolveVerifierErrorEvent(ctx context.Context, transactionId string, lookup, algorithm, errorMessage string)
 	PublishTransactionFinalizedEvent(ctx context.Context, transactionId string)
 	PublishTransactionFinalizeError(ctx context.Context, transactionId string, revertReason string, err error)
 	PublishTransactionConfirmedEvent(ctx context.Context, transactionId string)
+	PublishTransactionContentionLostEvent(ctx context.Context, transactionId string, winningNode string)
+	PublishTransactionDelegatedEvent(ctx context.Context, transactionId string)
+	PublishTransactionDelegationFailedEvent(ctx context.Context, transactionId string, errorMessage string)
+	PublishTransactionInputStateConflictEvent(ctx context.Context, transactionId string, stateID string, claimingTransactionID string)
 }
 
 // Map of signing address to an ordered list of transaction IDs that are ready to be dispatched by that signing address
@@ -75,6 +81,7 @@ type EndorsementGatherer interface {
 		transactionSpecification *prototk.TransactionSpecification,
 		verifiers []*prototk.ResolvedVerifier,
 		signatures []*prototk.AttestationResult,
+		endorsements []*prototk.AttestationResult,
 		inputStates []*prototk.EndorsableState,
 		readStates []*prototk.EndorsableState,
 		outputStates []*prototk.EndorsableState,
@@ -83,13 +90,31 @@ type EndorsementGatherer interface {
 		endorsementRequest *prototk.AttestationRequest) (*prototk.AttestationResult, *string, error)
 }
 
+// The input states claimed by the transactions in flight for a contract. A transaction claims its input states
+// once it is ready for endorsement, and holds them until it is finalized.
+type InputStateClaims interface {
+	// Returns the first of the given states that is claimed by a transaction other than txID, along with the
+	// ID of the transaction that claimed it, or empty strings if none of them are claimed
+	GetInputStateClaim(ctx context.Context, txID uuid.UUID, inputStateIDs []string) (stateID string, claimingTransactionID string)
+}
+
 type ContentionResolver interface {
-	Resolve(stateID, biddingContentionResolver1, biddingContentionResolver2 string) (string, error)
+	// Resolve picks the winner among any number of bidders for the given state
+	Resolve(stateID string, bidders []string) (string, error)
+	// ResolvePair is equivalent to calling Resolve with exactly two bidders
+	ResolvePair(stateID, bidder1, bidder2 string) (string, error)
 }
 
 type TransportWriter interface {
 	SendDelegationRequest(ctx context.Context, delegationId string, delegateNodeId string, transaction *components.PrivateTransaction) error
-	SendEndorsementRequest(ctx context.Context, party string, targetNode string, contractAddress string, transactionID string, attRequest *prototk.AttestationRequest, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, inputStates []*components.FullState, outputStates []*components.FullState, infoStates []*components.FullState) error
+	// SendEndorsementRequest sends a single message asking for the endorsement of the transaction by each of the given parties on the target node
+	SendEndorsementRequest(ctx context.Context, targetNode string, contractAddress string, transactionID string, requests []*EndorsementRequestParty, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, endorsements []*prototk.AttestationResult, inputStates []*components.FullState, outputStates []*components.FullState, infoStates []*components.FullState) error
+}
+
+// EndorsementRequestParty is the request for one party to fulfil an attestation request of a transaction
+type EndorsementRequestParty struct {
+	Party              string
+	AttestationRequest *prototk.AttestationRequest
 }
 
 type TransactionFlowStatus int
@@ -103,6 +128,7 @@ const (
 
 type TransactionFlow interface {
 	GetTxStatus(ctx context.Context) (components.PrivateTxStatus, error)
+	GetTxAttestations(ctx context.Context) *components.PrivateTxAttestations
 
 	ApplyEvent(ctx context.Context, event PrivateTransactionEvent)
 	Action(ctx context.Context)
@@ -110,6 +136,7 @@ type TransactionFlow interface {
 	PrepareTransaction(ctx context.Context, defaultSigner string) (*components.PrivateTransaction, error)
 	GetStateDistributions(ctx context.Context) (*components.StateDistributionSet, error)
 	CoordinatingLocally() bool
+	ContentionDelegating() bool
 	IsComplete() bool
 	ReadyForSequencing() bool
 	Dispatched() bool
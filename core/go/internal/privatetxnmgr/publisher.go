@@ -46,7 +46,7 @@ func (p *publisher) PublishTransactionBlockedEvent(ctx context.Context, transact
 
 }
 
-func (p *publisher) PublishTransactionDispatchedEvent(ctx context.Context, transactionId string, nonce uint64, signingAddress string) {
+func (p *publisher) PublishTransactionDispatchedEvent(ctx context.Context, transactionId string, nonce uint64, signingAddress string, awaitConfirmation bool) {
 
 	p.privateTxManager.HandleNewEvent(ctx, &ptmgrtypes.TransactionDispatchedEvent{
 		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
@@ -56,11 +56,12 @@ func (p *publisher) PublishTransactionDispatchedEvent(ctx context.Context, trans
 		Nonce:          nonce,
 		SigningAddress: signingAddress,
 	})
-	p.privateTxManager.publishToSubscribers(ctx, &components.TransactionDispatchedEvent{
-		TransactionID:  transactionId,
-		Nonce:          nonce,
-		SigningAddress: signingAddress,
-	})
+	p.privateTxManager.publishDispatched(ctx, &components.TransactionDispatchedEvent{
+		TransactionID:   transactionId,
+		ContractAddress: p.contractAddress,
+		Nonce:           nonce,
+		SigningAddress:  signingAddress,
+	}, awaitConfirmation)
 
 }
 
@@ -96,6 +97,17 @@ func (p *publisher) PublishTransactionSignedEvent(ctx context.Context, transacti
 	p.privateTxManager.HandleNewEvent(ctx, event)
 }
 
+func (p *publisher) PublishTransactionSignFailedEvent(ctx context.Context, transactionId string, errorMessage string) {
+	event := &ptmgrtypes.TransactionSignFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+		Error: errorMessage,
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
+
 func (p *publisher) PublishTransactionEndorsedEvent(ctx context.Context, transactionId string, endorsement *prototk.AttestationResult, revertReason *string) {
 	event := &ptmgrtypes.TransactionEndorsedEvent{
 		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
@@ -168,3 +180,60 @@ func (p *publisher) PublishTransactionConfirmedEvent(ctx context.Context, transa
 	}
 	p.privateTxManager.HandleNewEvent(ctx, event)
 }
+
+func (p *publisher) PublishTransactionContentionLostEvent(ctx context.Context, transactionId string, winningNode string) {
+	event := &ptmgrtypes.TransactionContentionLostEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+		WinningNode: winningNode,
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
+
+func (p *publisher) PublishTransactionDelegatedEvent(ctx context.Context, transactionId string) {
+	event := &ptmgrtypes.TransactionDelegatedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
+
+func (p *publisher) PublishTransactionDelegationFailedEvent(ctx context.Context, transactionId string, errorMessage string) {
+	event := &ptmgrtypes.TransactionDelegationFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+		Error: errorMessage,
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
+
+func (p *publisher) PublishTransactionAssemblyValidatedEvent(ctx context.Context, transactionId string, validationID string, attestationPlan []*prototk.AttestationRequest, errorMessage string) {
+	event := &ptmgrtypes.TransactionAssemblyValidatedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+		ValidationID:    validationID,
+		AttestationPlan: attestationPlan,
+		Error:           errorMessage,
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
+
+func (p *publisher) PublishTransactionInputStateConflictEvent(ctx context.Context, transactionId string, stateID string, claimingTransactionID string) {
+	event := &ptmgrtypes.TransactionInputStateConflictEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			ContractAddress: p.contractAddress,
+			TransactionID:   transactionId,
+		},
+		StateID:               stateID,
+		ClaimingTransactionID: claimingTransactionID,
+	}
+	p.privateTxManager.HandleNewEvent(ctx, event)
+}
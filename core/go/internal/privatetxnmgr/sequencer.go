@@ -33,6 +33,7 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/statedistribution"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
@@ -60,6 +61,13 @@ const (
 	SequencerStateStopped SequencerState = "stopped"
 )
 
+// The stage visits of transactions that are no longer in flight are retained for the stats up to this age, and this
+// many per sequencer, so a stats window longer than this only reflects the visits retained
+const (
+	completedStageVisitRetention = 24 * time.Hour
+	maxCompletedStageVisits      = 10000
+)
+
 var AllSequencerStates = []string{
 	string(SequencerStateNew),
 	string(SequencerStateRunning),
@@ -70,6 +78,17 @@ var AllSequencerStates = []string{
 	string(SequencerStateStopped),
 }
 
+// The summary retained for a transaction after it has been dispatched
+type dispatchedTransaction struct {
+	id              uuid.UUID
+	status          components.PrivateTxStatus
+	attestations    *components.PrivateTxAttestations
+	inputStateIDs   []string // still claimed until the transaction is finalized
+	outputStateIDs  []string // dependants stay on the same pool signer until the transaction is finalized
+	signer          string
+	finalizePending bool
+}
+
 type Sequencer struct {
 	ctx              context.Context
 	privateTxManager components.PrivateTxManager
@@ -81,8 +100,17 @@ type Sequencer struct {
 	evalInterval time.Duration // between how long the sequencer will do an evaluation to check & remove transactions that missed events
 
 	maxConcurrentProcess        int
+	dispatchConcurrency         int // number of signing addresses prepared in parallel on each dispatch
 	incompleteTxProcessMapMutex sync.Mutex
 	incompleteTxSProcessMap     map[string]ptmgrtypes.TransactionFlow // a map of all known transactions that are not completed
+	dispatchedTxs               map[string]*dispatchedTransaction     // transactions evicted from incompleteTxSProcessMap on dispatch, until they are confirmed
+
+	// snapshots taken on the sequencer loop of the attestations of the transactions in incompleteTxSProcessMap
+	attestations map[string]*components.PrivateTxAttestations
+
+	// stats, protected by incompleteTxProcessMapMutex
+	completedStageVisits []*components.PrivateTxStageTiming // exited stage visits of transactions that are no longer in flight, oldest first
+	delegatedIn          int                                // transactions delegated to us by other nodes
 
 	processedTxIDs    map[string]bool // an internal record of completed transactions to handle persistence delays that causes reprocessing
 	sequencerLoopDone chan struct{}
@@ -101,7 +129,9 @@ type Sequencer struct {
 	pendingEvents chan ptmgrtypes.PrivateTransactionEvent
 
 	contractAddress                tktypes.EthAddress // the contract address managed by the current sequencer
-	defaultSigner                  string
+	submitSigners                  []string           // pool of anonymous signers for transactions the domain has not assigned a signer to
+	submitSignerSelection          pldconf.SubmitSignerSelection
+	submitSignerNext               int // round-robin position in submitSigners, only used on the sequencer loop
 	nodeID                         string
 	domainAPI                      components.DomainSmartContract
 	components                     components.AllComponents
@@ -113,7 +143,33 @@ type Sequencer struct {
 	preparedTransactionDistributer preparedtxdistribution.PreparedTransactionDistributer
 	transportWriter                ptmgrtypes.TransportWriter
 	graph                          Graph
-	requestTimeout                 time.Duration
+	endorsementRequestTimeout      time.Duration
+	endorsementMaxRetries          int
+	signingTimeout                 time.Duration
+	signingHashThreshold           int
+	contentionRetry                *retry.Retry
+	contentionResolver             ptmgrtypes.ContentionResolver
+	maxRetries                     int
+	maxRetryDuration               time.Duration
+	endorsementRevertPolicy        pldconf.EndorsementRevertPolicy
+	flushFailurePolicy             pldconf.FlushFailurePolicy
+	handoffNodes                   []string
+	handoffNext                    int                        // round-robin position in handoffNodes, protected by incompleteTxProcessMapMutex
+	pendingHandoffs                map[string]*pendingHandoff // handoffs awaiting acknowledgement by the peer, protected by incompleteTxProcessMapMutex
+	handoffTimeout                 time.Duration
+	handoffMaxRetries              int
+	dependencyExpiry               time.Duration
+	dependencyFlushes              []syncpoints.DependencyFlush // queued writes of the recorded dependencies, only accessed on the event loop
+}
+
+// A transaction handed off to a peer stays tracked here until the peer acknowledges it, and is re-sent
+// (with the same delegation ID) if the acknowledgement does not arrive in time
+type pendingHandoff struct {
+	tx           *components.PrivateTransaction
+	peer         string
+	delegationID string
+	sent         time.Time
+	attempts     int
 }
 
 func NewSequencer(
@@ -141,10 +197,13 @@ func NewSequencer(
 		contractAddress:      contractAddress,
 		evalInterval:         confutil.DurationMin(sequencerConfig.EvaluationInterval, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.EvaluationInterval),
 		maxConcurrentProcess: confutil.Int(sequencerConfig.MaxConcurrentProcess, *pldconf.PrivateTxManagerDefaults.Sequencer.MaxConcurrentProcess),
+		dispatchConcurrency:  confutil.IntMin(sequencerConfig.DispatchConcurrency, 1, *pldconf.PrivateTxManagerDefaults.Sequencer.DispatchConcurrency),
 		state:                SequencerStateNew,
 		stateEntryTime:       time.Now(),
 
 		incompleteTxSProcessMap: make(map[string]ptmgrtypes.TransactionFlow),
+		dispatchedTxs:           make(map[string]*dispatchedTransaction),
+		attestations:            make(map[string]*components.PrivateTxAttestations),
 		persistenceRetryTimeout: confutil.DurationMin(sequencerConfig.PersistenceRetryTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.PersistenceRetryTimeout),
 
 		staleTimeout:                   confutil.DurationMin(sequencerConfig.StaleTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.StaleTimeout),
@@ -163,11 +222,29 @@ func NewSequencer(
 		preparedTransactionDistributer: preparedTransactionDistributer,
 		transportWriter:                transportWriter,
 		graph:                          NewGraph(),
-		requestTimeout:                 requestTimeout,
+		endorsementRequestTimeout:      confutil.DurationMin(sequencerConfig.EndorsementRequestTimeout, 0, requestTimeout.String()),
+		endorsementMaxRetries:          confutil.Int(sequencerConfig.EndorsementMaxRetries, *pldconf.PrivateTxManagerDefaults.Sequencer.EndorsementMaxRetries),
+		signingTimeout:                 confutil.DurationMin(sequencerConfig.SigningTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.SigningTimeout),
+		signingHashThreshold:           confutil.Int(sequencerConfig.SigningHashThreshold, 0),
+		contentionRetry:                retry.NewRetryLimited(&sequencerConfig.ContentionRetry, &pldconf.PrivateTxManagerDefaults.Sequencer.ContentionRetry),
+		contentionResolver:             NewContentionResolver(),
+		maxRetries:                     confutil.IntMin(sequencerConfig.RetryBudget.MaxRetries, 0, 0),
+		maxRetryDuration:               confutil.DurationMin(sequencerConfig.RetryBudget.MaxDuration, 0, "0"),
+		endorsementRevertPolicy:        pldconf.EndorsementRevertPolicy(confutil.StringNotEmpty(sequencerConfig.EndorsementRevertPolicy, *pldconf.PrivateTxManagerDefaults.Sequencer.EndorsementRevertPolicy)),
+		flushFailurePolicy:             pldconf.FlushFailurePolicy(confutil.StringNotEmpty(sequencerConfig.FlushFailurePolicy, *pldconf.PrivateTxManagerDefaults.Sequencer.FlushFailurePolicy)),
+		handoffNodes:                   sequencerConfig.HandoffNodes,
+		pendingHandoffs:                make(map[string]*pendingHandoff),
+		handoffTimeout:                 confutil.DurationMin(sequencerConfig.HandoffTimeout, 1*time.Millisecond, *pldconf.PrivateTxManagerDefaults.Sequencer.HandoffTimeout),
+		handoffMaxRetries:              confutil.IntMin(sequencerConfig.HandoffMaxRetries, 0, *pldconf.PrivateTxManagerDefaults.Sequencer.HandoffMaxRetries),
+		dependencyExpiry:               confutil.DurationMin(sequencerConfig.DependencyExpiry, 0, *pldconf.PrivateTxManagerDefaults.Sequencer.DependencyExpiry),
+		submitSignerSelection:          pldconf.SubmitSignerSelection(confutil.StringNotEmpty(sequencerConfig.SubmitSigners.Selection, *pldconf.PrivateTxManagerDefaults.Sequencer.SubmitSigners.Selection)),
+	}
 
-		// Randomly allocate a signer.
-		// TODO: rotation
-		defaultSigner: fmt.Sprintf("domains.%s.submit.%s", contractAddress, uuid.New()),
+	// Randomly allocate the pool of signers
+	submitSignerPoolSize := confutil.IntMin(sequencerConfig.SubmitSigners.PoolSize, 1, *pldconf.PrivateTxManagerDefaults.Sequencer.SubmitSigners.PoolSize)
+	newSequencer.submitSigners = make([]string, submitSignerPoolSize)
+	for i := range newSequencer.submitSigners {
+		newSequencer.submitSigners[i] = fmt.Sprintf("domains.%s.submit.%s", contractAddress, uuid.New())
 	}
 
 	log.L(ctx).Debugf("NewSequencer for contract address %s created: %+v", newSequencer.contractAddress, newSequencer)
@@ -191,28 +268,236 @@ func (s *Sequencer) getTransactionProcessor(txID string) ptmgrtypes.TransactionF
 	return transactionProcessor
 }
 
+func (s *Sequencer) getTransactionProcessors() []ptmgrtypes.TransactionFlow {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	transactionProcessors := make([]ptmgrtypes.TransactionFlow, 0, len(s.incompleteTxSProcessMap))
+	for _, transactionProcessor := range s.incompleteTxSProcessMap {
+		transactionProcessors = append(transactionProcessors, transactionProcessor)
+	}
+	return transactionProcessors
+}
+
 func (s *Sequencer) removeTransactionProcessor(txID string) {
 	s.incompleteTxProcessMapMutex.Lock()
 	defer s.incompleteTxProcessMapMutex.Unlock()
+	if txProc, ok := s.incompleteTxSProcessMap[txID]; ok {
+		// keep the latency of the stages it went through, for the stats
+		if status, err := txProc.GetTxStatus(s.ctx); err == nil {
+			s.retainStageVisits(status.StageTimings)
+		}
+	}
+	delete(s.incompleteTxSProcessMap, txID)
+	delete(s.attestations, txID)
+}
+
+// The flow of a transaction is only safe to read on the sequencer loop, so the attestations are snapshotted there
+// each time the transaction is updated, for GetTxAttestations to return from any goroutine
+func (s *Sequencer) snapshotAttestations(ctx context.Context, txProc ptmgrtypes.TransactionFlow) {
+	attestations := txProc.GetTxAttestations(ctx)
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	if _, ok := s.incompleteTxSProcessMap[attestations.TxID]; ok {
+		s.attestations[attestations.TxID] = attestations
+	}
+}
+
+// Once a transaction has been dispatched, everything needed to submit it has been persisted and the full
+// in-memory flow (assembled states, endorsements etc.) is no longer needed. So we swap it for a summary
+// of its status that is held until it is confirmed. This means only the transactions that are still being
+// coordinated count towards maxConcurrentProcess, and memory does not grow with those awaiting confirmation.
+func (s *Sequencer) evictDispatchedTransaction(ctx context.Context, txID, signingAddress string) {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	txProc, ok := s.incompleteTxSProcessMap[txID]
+	if !ok {
+		return
+	}
+	status, err := txProc.GetTxStatus(ctx)
+	if err != nil {
+		log.L(ctx).Warnf("Transaction %s status not retained after dispatch: %s", txID, err)
+		status = components.PrivateTxStatus{TxID: txID, Status: "dispatched"}
+	}
+	s.retainStageVisits(status.StageTimings)
+	s.dispatchedTxs[txID] = &dispatchedTransaction{
+		id:             txProc.ID(),
+		status:         status,
+		attestations:   txProc.GetTxAttestations(ctx), // we are on the sequencer loop
+		inputStateIDs:  txProc.InputStateIDs(),
+		outputStateIDs: txProc.OutputStateIDs(),
+		signer:         signingAddress,
+	}
 	delete(s.incompleteTxSProcessMap, txID)
+	delete(s.attestations, txID)
+}
+
+func (s *Sequencer) getDispatchedTransaction(txID string) *dispatchedTransaction {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	return s.dispatchedTxs[txID]
+}
+
+// Must be called holding incompleteTxProcessMapMutex
+func (s *Sequencer) retainStageVisits(stageTimings []*components.PrivateTxStageTiming) {
+	for _, st := range stageTimings {
+		if st.Exited != nil {
+			s.completedStageVisits = append(s.completedStageVisits, st)
+		}
+	}
+	// visits are appended roughly in the order they were exited, so the oldest are at the front
+	cutoff := tktypes.Timestamp(time.Now().Add(-completedStageVisitRetention).UnixNano())
+	drop := 0
+	for drop < len(s.completedStageVisits) && *s.completedStageVisits[drop].Exited < cutoff {
+		drop++
+	}
+	if excess := len(s.completedStageVisits) - drop - maxCompletedStageVisits; excess > 0 {
+		drop += excess
+	}
+	if drop > 0 {
+		s.completedStageVisits = append([]*components.PrivateTxStageTiming(nil), s.completedStageVisits[drop:]...)
+	}
+}
+
+// Only visits to a stage that were exited since the given time are included
+func addStageLatency(latency map[components.PrivateTxStage]*components.PrivateTxStageLatency, stageTimings []*components.PrivateTxStageTiming, since tktypes.Timestamp) {
+	for _, st := range stageTimings {
+		if st.Exited == nil || *st.Exited < since {
+			continue
+		}
+		l := latency[st.Stage]
+		if l == nil {
+			l = &components.PrivateTxStageLatency{}
+			latency[st.Stage] = l
+		}
+		l.Count++
+		l.Total += time.Duration(*st.Exited - st.Entered)
+	}
 }
 
 func (s *Sequencer) ProcessNewTransaction(ctx context.Context, tx *components.PrivateTransaction) (queued bool) {
+	handoff, queued := s.processNewTransaction(ctx, tx)
+	if handoff != nil {
+		s.sendHandoff(ctx, handoff)
+	}
+	return queued
+}
+
+func (s *Sequencer) processNewTransaction(ctx context.Context, tx *components.PrivateTransaction) (handoff *pendingHandoff, queued bool) {
 	s.incompleteTxProcessMapMutex.Lock()
 	defer s.incompleteTxProcessMapMutex.Unlock()
-	if s.incompleteTxSProcessMap[tx.ID.String()] == nil {
+	txID := tx.ID.String()
+	if s.incompleteTxSProcessMap[txID] == nil && s.dispatchedTxs[txID] == nil && s.pendingHandoffs[txID] == nil {
 		if len(s.incompleteTxSProcessMap) >= s.maxConcurrentProcess {
-			// TODO: decide how this map is managed, it shouldn't track the entire lifecycle
+			if handoff = s.handoff(tx); handoff != nil {
+				return handoff, false
+			}
 			// tx processing pool is full, queue the item
-			return true
+			return nil, true
 		} else {
-			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeID, s.components, s.domainAPI, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout)
+			s.incompleteTxSProcessMap[txID] = s.newTransactionFlow(ctx, tx)
 		}
 		s.pendingEvents <- &ptmgrtypes.TransactionSubmittedEvent{
-			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tx.ID.String()},
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txID},
 		}
 	}
-	return false
+	return nil, false
+}
+
+func (s *Sequencer) newTransactionFlow(ctx context.Context, tx *components.PrivateTransaction) ptmgrtypes.TransactionFlow {
+	return NewTransactionFlow(ctx, tx, s.nodeID, s.components, s.domainAPI, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s, s.endorsementRequestTimeout, s.endorsementMaxRetries, s.signingTimeout, s.signingHashThreshold, s.contentionRetry, s.maxRetries, s.maxRetryDuration, s.endorsementRevertPolicy, s.flushFailurePolicy)
+}
+
+// handoff chooses the next configured peer to delegate coordination of a new transaction to, when this
+// node is at capacity, and tracks the handoff until the peer acknowledges it. Only transactions submitted
+// to this node are handed off - those delegated to us arrive through ProcessInFlightTransaction and are
+// never handed on, so a transaction cannot bounce between overloaded peers. Must be called holding
+// incompleteTxProcessMapMutex, and the returned handoff sent once it is released.
+func (s *Sequencer) handoff(tx *components.PrivateTransaction) *pendingHandoff {
+	for range s.handoffNodes {
+		peer := s.handoffNodes[s.handoffNext%len(s.handoffNodes)]
+		s.handoffNext++
+		if peer == "" || peer == s.nodeID {
+			continue
+		}
+		handoff := &pendingHandoff{
+			tx:           tx,
+			peer:         peer,
+			delegationID: uuid.New().String(),
+			sent:         time.Now(),
+			attempts:     1,
+		}
+		s.pendingHandoffs[tx.ID.String()] = handoff
+		return handoff
+	}
+	return nil
+}
+
+// sendHandoff sends the delegation request for a handoff. A failure is only logged, as the handoff is
+// re-sent when it has not been acknowledged within the handoff timeout.
+func (s *Sequencer) sendHandoff(ctx context.Context, handoff *pendingHandoff) {
+	log.L(ctx).Infof("Sequencer at capacity (%d transactions) handing off coordination of transaction %s to %s (attempt %d)", s.maxConcurrentProcess, handoff.tx.ID, handoff.peer, handoff.attempts)
+	if err := s.transportWriter.SendDelegationRequest(ctx, handoff.delegationID, handoff.peer, handoff.tx); err != nil {
+		log.L(ctx).Warnf("Failed to hand off transaction %s to %s: %s", handoff.tx.ID, handoff.peer, err)
+	}
+}
+
+// HandoffAcknowledged is called when a peer acknowledges a handoff, at which point the delegation is
+// recorded against the transaction and it is no longer re-sent. Returns false if there is no pending
+// handoff of the transaction with the given delegation ID.
+func (s *Sequencer) HandoffAcknowledged(ctx context.Context, txID, delegationID string) bool {
+	s.incompleteTxProcessMapMutex.Lock()
+	handoff := s.pendingHandoffs[txID]
+	if handoff == nil || handoff.delegationID != delegationID {
+		s.incompleteTxProcessMapMutex.Unlock()
+		return false
+	}
+	delete(s.pendingHandoffs, txID)
+	s.incompleteTxProcessMapMutex.Unlock()
+
+	log.L(ctx).Infof("Handoff of transaction %s to %s acknowledged", txID, handoff.peer)
+	recordDelegation(ctx, s.components, handoff.tx.ID, handoff.peer)
+	return true
+}
+
+// retryHandoffs re-sends handoffs that have not been acknowledged within the handoff timeout. Once
+// the retries are exhausted the transaction is coordinated locally instead, so that it is never lost
+// to a peer that is not responding. Called on the sequencer loop.
+func (s *Sequencer) retryHandoffs(ctx context.Context) {
+	var resend, reclaim []*pendingHandoff
+	s.incompleteTxProcessMapMutex.Lock()
+	for txID, handoff := range s.pendingHandoffs {
+		if time.Since(handoff.sent) < s.handoffTimeout {
+			continue
+		}
+		if handoff.attempts > s.handoffMaxRetries {
+			delete(s.pendingHandoffs, txID)
+			s.incompleteTxSProcessMap[txID] = s.newTransactionFlow(ctx, handoff.tx)
+			reclaim = append(reclaim, handoff)
+			continue
+		}
+		handoff.attempts++
+		handoff.sent = time.Now()
+		resend = append(resend, handoff)
+	}
+	s.incompleteTxProcessMapMutex.Unlock()
+
+	for _, handoff := range resend {
+		s.sendHandoff(ctx, handoff)
+	}
+	for _, handoff := range reclaim {
+		log.L(ctx).Warnf("Handoff of transaction %s to %s not acknowledged after %d attempts. Coordinating it locally", handoff.tx.ID, handoff.peer, handoff.attempts)
+		s.handleEvent(ctx, &ptmgrtypes.TransactionSubmittedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: handoff.tx.ID.String()},
+		})
+	}
+}
+
+// recordDelegation durably records the node that coordination of a transaction has been delegated to,
+// against the transaction record. The delegation has already been sent, so a failure is only logged.
+func recordDelegation(ctx context.Context, c components.AllComponents, txID uuid.UUID, delegateNode string) {
+	if err := c.TxManager().SetTransactionCoordinator(ctx, c.Persistence().DB(), delegateNode, []uuid.UUID{txID}); err != nil {
+		log.L(ctx).Warnf("Failed to record delegation of transaction %s to %s: %s", txID, delegateNode, err)
+	}
 }
 
 func (s *Sequencer) ProcessInFlightTransaction(ctx context.Context, tx *components.PrivateTransaction) (queued bool) {
@@ -223,17 +508,17 @@ func (s *Sequencer) ProcessInFlightTransaction(ctx context.Context, tx *componen
 	s.incompleteTxProcessMapMutex.Lock()
 	defer s.incompleteTxProcessMapMutex.Unlock()
 	_, alreadyInMemory := s.incompleteTxSProcessMap[tx.ID.String()]
-	if alreadyInMemory {
+	if alreadyInMemory || s.dispatchedTxs[tx.ID.String()] != nil {
 		log.L(ctx).Warnf("Transaction %s already in memory. Ignoring", tx.ID)
 		return false
 	}
 	if s.incompleteTxSProcessMap[tx.ID.String()] == nil {
 		if len(s.incompleteTxSProcessMap) >= s.maxConcurrentProcess {
-			// TODO: decide how this map is managed, it shouldn't track the entire lifecycle
 			// tx processing pool is full, queue the item
 			return true
 		} else {
-			s.incompleteTxSProcessMap[tx.ID.String()] = NewTransactionFlow(ctx, tx, s.nodeID, s.components, s.domainAPI, s.publisher, s.endorsementGatherer, s.identityResolver, s.syncPoints, s.transportWriter, s.requestTimeout)
+			s.incompleteTxSProcessMap[tx.ID.String()] = s.newTransactionFlow(ctx, tx)
+			s.delegatedIn++
 		}
 		s.pendingEvents <- &ptmgrtypes.TransactionSwappedInEvent{
 			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tx.ID.String()},
@@ -248,6 +533,12 @@ func (s *Sequencer) HandleEvent(ctx context.Context, event ptmgrtypes.PrivateTra
 
 func (s *Sequencer) Start(c context.Context) (done <-chan struct{}, err error) {
 	s.syncPoints.Start()
+	// restore the ordering of any transactions that were in the sequencer before a restart, before any events are processed
+	dependencies, err := s.syncPoints.LoadDependencies(c, s.contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	s.graph.RestoreDependencies(c, dependencies)
 	s.sequencerLoopDone = make(chan struct{})
 	go s.evaluationLoop()
 	s.TriggerSequencerEvaluation()
@@ -282,6 +573,134 @@ func (s *Sequencer) GetTxStatus(ctx context.Context, txID string) (status compon
 	if txProc, ok := s.incompleteTxSProcessMap[txID]; ok {
 		return txProc.GetTxStatus(ctx)
 	}
+	if dispatched, ok := s.dispatchedTxs[txID]; ok {
+		return dispatched.status, nil
+	}
 	//TODO should be possible to query the status of a transaction that is not inflight
 	return components.PrivateTxStatus{}, i18n.NewError(ctx, msgs.MsgPrivateTxManagerInternalError, "Transaction not found")
 }
+
+// ResolveEndorsementContention is called when this node is asked to endorse a transaction coordinated by another node.
+// The check is handed to the sequencer loop, as it needs to look at the transactions we are coordinating.
+func (s *Sequencer) ResolveEndorsementContention(ctx context.Context, coordinatorNode string, transactionID string, inputStateIDs []string) {
+	if coordinatorNode == "" || coordinatorNode == s.nodeID || len(inputStateIDs) == 0 {
+		return
+	}
+	s.HandleEvent(ctx, &ptmgrtypes.EndorsementContentionEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: transactionID, ContractAddress: s.contractAddress.String()},
+		CoordinatorNode:             coordinatorNode,
+		InputStateIDs:               inputStateIDs,
+	})
+}
+
+// DelegationAcknowledged passes the acknowledgement of a delegation to the flow of the transaction, if it is in
+// flight in this sequencer, where it completes a delegation to the winner of a contention bid. Returns false if the
+// transaction is not in flight here.
+func (s *Sequencer) DelegationAcknowledged(ctx context.Context, txID, delegationID string) bool {
+	s.incompleteTxProcessMapMutex.Lock()
+	_, inFlight := s.incompleteTxSProcessMap[txID]
+	s.incompleteTxProcessMapMutex.Unlock()
+	if !inFlight {
+		return false
+	}
+	s.HandleEvent(ctx, &ptmgrtypes.TransactionDelegationAcknowledgedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txID, ContractAddress: s.contractAddress.String()},
+		DelegationID:                delegationID,
+	})
+	return true
+}
+
+// Returns nil if the transaction is no longer in memory, in which case the attestations persisted with its dispatch
+// can be read instead
+func (s *Sequencer) GetTxAttestations(ctx context.Context, txID string) *components.PrivateTxAttestations {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	if attestations, ok := s.attestations[txID]; ok {
+		return attestations
+	}
+	if _, ok := s.incompleteTxSProcessMap[txID]; ok {
+		// not yet updated on the sequencer loop, so not assembled either
+		return &components.PrivateTxAttestations{TxID: txID, Attestations: []*components.PrivateTxAttestation{}}
+	}
+	if dispatched, ok := s.dispatchedTxs[txID]; ok && dispatched.attestations != nil {
+		return dispatched.attestations
+	}
+	return nil
+}
+
+// CancelTransaction hands the cancellation to the sequencer loop, so that it cannot race with dispatch, and waits
+// for the outcome
+func (s *Sequencer) CancelTransaction(ctx context.Context, txID string) error {
+	event := &ptmgrtypes.TransactionCancelEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txID, ContractAddress: s.contractAddress.String()},
+		Result:                      make(chan error, 1),
+	}
+	select {
+	case s.pendingEvents <- event:
+	case <-s.sequencerLoopDone:
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelNotInFlight, txID, s.contractAddress)
+	case <-ctx.Done():
+		return i18n.NewError(ctx, msgs.MsgContextCanceled)
+	}
+	select {
+	case err := <-event.Result:
+		return err
+	case <-s.sequencerLoopDone:
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelNotInFlight, txID, s.contractAddress)
+	case <-ctx.Done():
+		return i18n.NewError(ctx, msgs.MsgContextCanceled)
+	}
+}
+
+// GetTxStats adds the transactions in flight in this sequencer, and the latency of the stage visits exited since
+// the given time by those and the transactions it has completed, to the stats
+func (s *Sequencer) GetTxStats(ctx context.Context, since tktypes.Timestamp, stats *components.PrivateTxStats) {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	for _, txProc := range s.incompleteTxSProcessMap {
+		status, err := txProc.GetTxStatus(ctx)
+		if err != nil {
+			log.L(ctx).Warnf("Transaction %s not included in stats: %s", txProc.ID(), err)
+			continue
+		}
+		stats.ByStatus[status.Status]++
+		addStageLatency(stats.StageLatency, status.StageTimings, since)
+	}
+	for _, dispatched := range s.dispatchedTxs {
+		// stage visits were retained in completedStageVisits on eviction
+		stats.ByStatus[dispatched.status.Status]++
+	}
+	addStageLatency(stats.StageLatency, s.completedStageVisits, since)
+	stats.DelegatedIn += s.delegatedIn
+}
+
+// GetInputStateClaim checks the given states against those claimed by the other transactions in flight, which are
+// those being coordinated locally that are ready for endorsement, and those that have been dispatched
+func (s *Sequencer) GetInputStateClaim(ctx context.Context, txID uuid.UUID, inputStateIDs []string) (string, string) {
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	claimed := make(map[string]string)
+	for _, txProc := range s.incompleteTxSProcessMap {
+		if txProc.ID() != txID && txProc.CoordinatingLocally() && txProc.ReadyForSequencing() {
+			for _, stateID := range txProc.InputStateIDs() {
+				claimed[stateID] = txProc.ID().String()
+			}
+		}
+	}
+	for _, dispatched := range s.dispatchedTxs {
+		for _, stateID := range dispatched.inputStateIDs {
+			claimed[stateID] = dispatched.id.String()
+		}
+	}
+	for _, stateID := range inputStateIDs {
+		if claimingTxID, ok := claimed[stateID]; ok {
+			return stateID, claimingTxID
+		}
+	}
+	return "", ""
+}
+
+// GetBlockedTransactions returns the endorsed transactions that could not be dispatched the last time the graph was evaluated
+func (s *Sequencer) GetBlockedTransactions(ctx context.Context) []*components.BlockedPrivateTransaction {
+	return s.graph.GetBlockedTransactions(ctx)
+}
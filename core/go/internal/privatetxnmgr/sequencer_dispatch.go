@@ -19,9 +19,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/preparedtxdistribution"
@@ -32,161 +36,99 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
 )
 
+// The outcome of preparing all the transactions for a single signing address, ready to be merged into the dispatch batch
+type signerDispatch struct {
+	sequence                 *syncpoints.PublicDispatch
+	coordinatedTransactions  []uuid.UUID
+	privateDispatches        []*components.ValidatedTransaction
+	preparedTransactions     []*components.PrepareTransactionWithRefs
+	preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistribution
+	stateDistributions       []*components.StateDistribution // all persisted with the dispatch
+	sendStateDistributions   []*components.StateDistribution // sent as soon as the dispatch is persisted, the rest are held until confirmation
+	localStateDistributions  []*components.StateDistribution
+}
+
 // synchronously prepare and dispatch all given transactions to their associated signing address / or deliver prepared transaction to their custodian
 func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransactions ptmgrtypes.DispatchableTransactions) error {
 	log.L(ctx).Debug("DispatchTransactions")
 	//prepare all transactions then dispatch them
 
+	dispatchableTransactions = s.allocateSubmitSigners(ctx, dispatchableTransactions)
+
 	// array of sequences with space for one per signing address
 	// dispatchableTransactions is a map of signing address to transaction IDs so we can group by signing address
 	dispatchBatch := &syncpoints.DispatchBatch{
 		PublicDispatches: make([]*syncpoints.PublicDispatch, 0, len(dispatchableTransactions)),
+		Coordinator:      s.nodeID,
 	}
 
 	stateDistributions := make([]*components.StateDistribution, 0)
+	sendStateDistributions := make([]*components.StateDistribution, 0)
 	localStateDistributions := make([]*components.StateDistribution, 0)
 	preparedTxnDistributions := make([]*preparedtxdistribution.PreparedTxnDistribution, 0)
 
-	completed := false // and include whether we committed the DB transaction or not
-	for signingAddress, transactionIDs := range dispatchableTransactions {
-		log.L(ctx).Debugf("DispatchTransactions: %d transactions for signingAddress %s", len(transactionIDs), signingAddress)
-
-		publicTransactionsToSend := make([]*components.PrivateTransaction, 0, len(transactionIDs))
-
-		sequence := &syncpoints.PublicDispatch{}
-
-		for _, transactionID := range transactionIDs {
-			// prepare all transactions for the given transaction IDs
-
-			txProcessor := s.getTransactionProcessor(transactionID)
-			if txProcessor == nil {
-				//TODO currently assume that all the transactions are in flight and in memory
-				// need to reload from database if not in memory
-				panic("Transaction not found")
-			}
-
-			// If we don't have a signing key for the TX at this point, we use our randomly assigned one
-			// TODO: Rotation
-			preparedTransaction, err := txProcessor.PrepareTransaction(ctx, s.defaultSigner)
-			if err != nil {
-				log.L(ctx).Errorf("Error preparing transaction: %s", err)
-				//TODO this is a really bad time to be getting an error.  need to think carefully about how to handle this
-				return err
-			}
-			hasPublicTransaction := preparedTransaction.PreparedPublicTransaction != nil
-			hasPrivateTransaction := preparedTransaction.PreparedPrivateTransaction != nil
-			switch {
-			case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_SEND_TRANSACTION && hasPublicTransaction && !hasPrivateTransaction:
-				log.L(ctx).Infof("Result of transaction %s is a prepared public transaction", preparedTransaction.ID)
-				publicTransactionsToSend = append(publicTransactionsToSend, preparedTransaction)
-				sequence.PrivateTransactionDispatches = append(sequence.PrivateTransactionDispatches, &syncpoints.DispatchPersisted{
-					PrivateTransactionID: transactionID,
-				})
-			case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_SEND_TRANSACTION && hasPrivateTransaction && !hasPublicTransaction:
-				log.L(ctx).Infof("Result of transaction %s is a chained private transaction", preparedTransaction.ID)
-				validatedPrivateTx, err := s.components.TxManager().PrepareInternalPrivateTransaction(ctx, s.components.Persistence().DB(), preparedTransaction.PreparedPrivateTransaction, pldapi.SubmitModeAuto)
-				if err != nil {
-					log.L(ctx).Errorf("Error preparing transaction %s: %s", preparedTransaction.ID, err)
-					// TODO: this is just an error situation for one transaction - this function is a batch function
-					return err
-				}
-				dispatchBatch.PrivateDispatches = append(dispatchBatch.PrivateDispatches, validatedPrivateTx)
-			case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_PREPARE_TRANSACTION && (hasPublicTransaction || hasPrivateTransaction):
-				log.L(ctx).Infof("Result of transaction %s is a prepared transaction public=%t private=%t", preparedTransaction.ID, hasPublicTransaction, hasPrivateTransaction)
-				preparedTransactionWithRefs := mapPreparedTransaction(preparedTransaction)
-				dispatchBatch.PreparedTransactions = append(dispatchBatch.PreparedTransactions, preparedTransactionWithRefs)
-				preparedTransactionJSON, err := json.Marshal(preparedTransactionWithRefs)
-				if err != nil {
-					log.L(ctx).Errorf("Error marshalling prepared transaction: %s", err)
-					// TODO: this is just an error situation for one transaction - this function is a batch function
-					return err
-				}
-				preparedTxnDistributions = append(preparedTxnDistributions, &preparedtxdistribution.PreparedTxnDistribution{
-					ID:                      uuid.New().String(),
-					PreparedTxnID:           preparedTransactionWithRefs.ID.String(),
-					IdentityLocator:         preparedTransactionWithRefs.Sender,
-					Domain:                  preparedTransactionWithRefs.Domain,
-					ContractAddress:         preparedTransactionWithRefs.To.String(),
-					PreparedTransactionJSON: preparedTransactionJSON,
-				})
-
-			default:
-				err = i18n.NewError(ctx, msgs.MsgPrivateTxMgrInvalidPrepareOutcome, preparedTransaction.ID, preparedTransaction.Inputs.Intent, hasPublicTransaction, hasPrivateTransaction)
-				log.L(ctx).Errorf("Error preparing transaction %s: %s", preparedTransaction.ID, err)
-				// TODO: this is just an error situation for one transaction - this function is a batch function
-				return err
-			}
-
-			sds, err := txProcessor.GetStateDistributions(ctx)
-			if err != nil {
-				return err
-			}
-			stateDistributions = append(stateDistributions, sds.Remote...)
-			localStateDistributions = append(localStateDistributions, sds.Local...)
-		}
-
-		preparedTransactionPayloads := make([]*pldapi.TransactionInput, len(publicTransactionsToSend))
-
-		for j, preparedTransaction := range publicTransactionsToSend {
-			preparedTransactionPayloads[j] = preparedTransaction.PreparedPublicTransaction
-		}
-
-		//Now we have the payloads, we can prepare the submission
-		publicTransactionEngine := s.components.PublicTxManager()
-
-		signers := make([]string, len(publicTransactionsToSend))
-		for i, pt := range publicTransactionsToSend {
-			unqualifiedSigner, err := tktypes.PrivateIdentityLocator(pt.Signer).Identity(ctx)
-			if err != nil {
-				errorMessage := fmt.Sprintf("failed to parse lookup key for signer %s : %s", pt.Signer, err)
-				log.L(ctx).Error(errorMessage)
-				return i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerInternalError, errorMessage)
-			}
+	// The transactions for each signing address are independent of those for every other signing address
+	// (the graph holds back dependants that have a different signer) so the signing addresses are prepared
+	// in parallel. Within a signing address the transactions are prepared in order, so that nonces are
+	// assigned in dependency order.
+	signingAddresses := make([]string, 0, len(dispatchableTransactions))
+	for signingAddress := range dispatchableTransactions {
+		signingAddresses = append(signingAddresses, signingAddress)
+	}
+	sort.Strings(signingAddresses)
+	results := make([]*signerDispatch, len(signingAddresses))
+	errs := make([]error, len(signingAddresses))
+	concurrency := make(chan struct{}, s.dispatchConcurrency)
+	var wg sync.WaitGroup
+	for i, signingAddress := range signingAddresses {
+		wg.Add(1)
+		concurrency <- struct{}{}
+		go func() {
+			defer func() {
+				<-concurrency
+				wg.Done()
+			}()
+			results[i], errs[i] = s.prepareSignerDispatch(ctx, signingAddress, dispatchableTransactions[signingAddress])
+		}()
+	}
+	wg.Wait()
 
-			signers[i] = unqualifiedSigner
+	completed := false // and include whether we committed the DB transaction or not
+	for _, result := range results {
+		// Must make sure from this point we return the nonces for every batch we prepared
+		if result != nil && result.sequence.PublicTxBatch != nil {
+			pubBatch := result.sequence.PublicTxBatch
+			defer func() {
+				pubBatch.Completed(ctx, completed)
+			}()
 		}
-		keyMgr := s.components.KeyManager()
-		resolvedAddrs, err := keyMgr.ResolveEthAddressBatchNewDatabaseTX(ctx, signers)
+	}
+	for _, err := range errs {
 		if err != nil {
 			return err
 		}
+	}
+	for _, result := range results {
+		dispatchBatch.CoordinatedTransactions = append(dispatchBatch.CoordinatedTransactions, result.coordinatedTransactions...)
+		dispatchBatch.PrivateDispatches = append(dispatchBatch.PrivateDispatches, result.privateDispatches...)
+		dispatchBatch.PreparedTransactions = append(dispatchBatch.PreparedTransactions, result.preparedTransactions...)
+		dispatchBatch.PublicDispatches = append(dispatchBatch.PublicDispatches, result.sequence)
+		preparedTxnDistributions = append(preparedTxnDistributions, result.preparedTxnDistributions...)
+		stateDistributions = append(stateDistributions, result.stateDistributions...)
+		sendStateDistributions = append(sendStateDistributions, result.sendStateDistributions...)
+		localStateDistributions = append(localStateDistributions, result.localStateDistributions...)
+	}
 
-		publicTXs := make([]*components.PublicTxSubmission, len(publicTransactionsToSend))
-		for i, pt := range publicTransactionsToSend {
-			log.L(ctx).Debugf("DispatchTransactions: creating PublicTxSubmission from %s", pt.Signer)
-			publicTXs[i] = &components.PublicTxSubmission{
-				Bindings: []*components.PaladinTXReference{{TransactionID: pt.ID, TransactionType: pldapi.TransactionTypePrivate.Enum()}},
-				PublicTxInput: pldapi.PublicTxInput{
-					From:            resolvedAddrs[i],
-					To:              &s.contractAddress,
-					PublicTxOptions: pldapi.PublicTxOptions{}, // TODO: Consider propagation from paladin transaction input
-				},
-			}
-
-			// TODO: This aligning with submission in public Tx manage
-			data, err := pt.PreparedPublicTransaction.ABI[0].EncodeCallDataJSONCtx(ctx, pt.PreparedPublicTransaction.Data)
-			if err != nil {
-				return err
+	// the attestations are persisted with the dispatch, so they can be inspected after the transaction is finalized
+	for _, sequence := range dispatchableTransactions {
+		for _, privateTransactionID := range sequence {
+			if txProc := s.getTransactionProcessor(privateTransactionID); txProc != nil {
+				dispatchBatch.Attestations = append(dispatchBatch.Attestations, txProc.GetTxAttestations(ctx))
 			}
-			publicTXs[i].Data = tktypes.HexBytes(data)
-		}
-		pubBatch, err := publicTransactionEngine.PrepareSubmissionBatch(ctx, publicTXs)
-		if err != nil {
-			return i18n.WrapError(ctx, err, msgs.MsgPrivTxMgrPublicTxFail)
 		}
-		// Must make sure from this point we return the nonces
-		sequence.PublicTxBatch = pubBatch
-		defer func() {
-			pubBatch.Completed(ctx, completed)
-		}()
-		if len(pubBatch.Rejected()) > 0 {
-			// We do not handle partial success - roll everything back
-			return i18n.WrapError(ctx, pubBatch.Rejected()[0].RejectedError(), msgs.MsgPrivTxMgrPublicTxFail)
-		}
-
-		dispatchBatch.PublicDispatches = append(dispatchBatch.PublicDispatches, sequence)
 	}
 
 	// TODO: per notes in endorsementGatherer determine if that's the right place to hold the domain context
@@ -205,16 +147,34 @@ func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransa
 	err = s.syncPoints.PersistDispatchBatch(s.endorsementGatherer.DomainContext(), s.contractAddress, dispatchBatch, stateDistributions, preparedTxnDistributions)
 	if err != nil {
 		log.L(ctx).Errorf("Error persisting batch: %s", err)
+		s.failDispatch(ctx, dispatchableTransactions, err)
 		return err
 	}
 	completed = true
+	// only the transactions submitted to the base ledger will be confirmed
+	submitted := make(map[string]bool)
+	for _, publicDispatch := range dispatchBatch.PublicDispatches {
+		for _, dispatch := range publicDispatch.PrivateTransactionDispatches {
+			submitted[dispatch.PrivateTransactionID] = true
+		}
+	}
 	for signingAddress, sequence := range dispatchableTransactions {
 		for _, privateTransactionID := range sequence {
-			s.publisher.PublishTransactionDispatchedEvent(ctx, privateTransactionID, uint64(0) /*TODO*/, signingAddress)
+			// We are on the sequencer loop, so record the dispatch in memory straight away as well as publishing the event.
+			// Otherwise a cancel that is already queued behind us would be applied to a transaction that has been dispatched.
+			if txProc := s.getTransactionProcessor(privateTransactionID); txProc != nil {
+				txProc.ApplyEvent(ctx, &ptmgrtypes.TransactionDispatchedEvent{
+					PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: privateTransactionID, ContractAddress: s.contractAddress.String()},
+					SigningAddress:              signingAddress,
+				})
+			}
+			s.evictDispatchedTransaction(ctx, privateTransactionID, signingAddress)
+			s.publisher.PublishTransactionDispatchedEvent(ctx, privateTransactionID, uint64(0) /*TODO*/, signingAddress, submitted[privateTransactionID])
 		}
 	}
 	//now that the DB write has been persisted, we can trigger the in-memory distribution of the prepared transactions and states
-	s.stateDistributer.DistributeStates(ctx, stateDistributions)
+	// (other than those the domain wants held back until the public transaction is confirmed)
+	s.stateDistributer.DistributeStates(ctx, sendStateDistributions)
 
 	s.preparedTransactionDistributer.DistributePreparedTransactions(ctx, preparedTxnDistributions)
 
@@ -229,6 +189,298 @@ func (s *Sequencer) DispatchTransactions(ctx context.Context, dispatchableTransa
 
 }
 
+// A dispatch batch is persisted in the same DB transaction as the flush of the domain context, so when it fails
+// none of the states in the flush were written. The states of the transactions in the batch, and of any other
+// transactions that spend them, are discarded from the domain context - while those of all other transactions
+// are kept to be written with a later dispatch. The transactions whose states were discarded are failed or
+// re-assembled according to the flush failure policy, as what they were assembled against no longer exists.
+func (s *Sequencer) failDispatch(ctx context.Context, dispatchableTransactions ptmgrtypes.DispatchableTransactions, err error) {
+	var failed []ptmgrtypes.TransactionFlow
+	minted := make(map[string]bool)
+	inBatch := make(map[string]bool)
+	for _, sequence := range dispatchableTransactions {
+		for _, privateTransactionID := range sequence {
+			inBatch[privateTransactionID] = true
+			if txProc := s.getTransactionProcessor(privateTransactionID); txProc != nil {
+				failed = append(failed, txProc)
+				for _, stateID := range txProc.OutputStateIDs() {
+					minted[stateID] = true
+				}
+			}
+		}
+	}
+	// keep going round until there are no more transactions spending states minted by those that failed
+	others := s.getTransactionProcessors()
+	for found := true; found; {
+		found = false
+		for _, txProc := range others {
+			if inBatch[txProc.ID().String()] {
+				continue
+			}
+			for _, stateID := range txProc.InputStateIDs() {
+				if minted[stateID] {
+					log.L(ctx).Warnf("Transaction %s spends state %s of a transaction that failed to dispatch", txProc.ID(), stateID)
+					inBatch[txProc.ID().String()] = true
+					failed = append(failed, txProc)
+					for _, stateID := range txProc.OutputStateIDs() {
+						minted[stateID] = true
+					}
+					found = true
+					break
+				}
+			}
+		}
+	}
+
+	txIDs := make([]uuid.UUID, len(failed))
+	for i, txProc := range failed {
+		txIDs[i] = txProc.ID()
+	}
+	s.endorsementGatherer.DomainContext().DiscardTransactions(txIDs...)
+	for _, txProc := range failed {
+		txProc.ApplyEvent(ctx, &ptmgrtypes.TransactionDispatchFailedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txProc.ID().String(), ContractAddress: s.contractAddress.String()},
+			Error:                       err.Error(),
+		})
+		s.removeFromGraph(ctx, txProc.ID())
+		txProc.Action(ctx)
+		s.snapshotAttestations(ctx, txProc)
+	}
+}
+
+// The transactions that the domain has not assigned a signer to are spread across the pool of anonymous signers
+// of the contract, so that they can be submitted in parallel. A transaction that spends a state minted by another
+// in the same dispatch, or in an earlier dispatch that is not yet finalized, is kept on the signer of that
+// transaction, so that the nonces follow the dependency order.
+func (s *Sequencer) allocateSubmitSigners(ctx context.Context, dispatchableTransactions ptmgrtypes.DispatchableTransactions) ptmgrtypes.DispatchableTransactions {
+	unassigned := dispatchableTransactions[""]
+	if len(unassigned) == 0 {
+		return dispatchableTransactions
+	}
+	allocated := make(ptmgrtypes.DispatchableTransactions, len(dispatchableTransactions)+len(s.submitSigners))
+	for signingAddress, sequence := range dispatchableTransactions {
+		if signingAddress != "" {
+			allocated[signingAddress] = sequence
+		}
+	}
+
+	load, mintedBy := s.submitSignerState()
+	for _, transactionID := range unassigned {
+		txProcessor := s.getTransactionProcessor(transactionID)
+		signer := ""
+		if txProcessor != nil {
+			for _, stateID := range txProcessor.InputStateIDs() {
+				if mintedBy[stateID] != "" {
+					signer = mintedBy[stateID]
+					break
+				}
+			}
+		}
+		if signer == "" {
+			signer = s.nextSubmitSigner(load)
+		}
+		load[signer]++
+		if txProcessor != nil {
+			for _, stateID := range txProcessor.OutputStateIDs() {
+				mintedBy[stateID] = signer
+			}
+		}
+		allocated[signer] = append(allocated[signer], transactionID)
+	}
+	log.L(ctx).Debugf("DispatchTransactions: allocated %d transactions across %d submit signers", len(unassigned), len(s.submitSigners))
+	return allocated
+}
+
+// The number of dispatched transactions awaiting finalization for each of the signers in the pool,
+// and the pool signer of each state minted by one of those transactions
+func (s *Sequencer) submitSignerState() (load map[string]int, mintedBy map[string]string) {
+	load = make(map[string]int, len(s.submitSigners))
+	for _, signer := range s.submitSigners {
+		load[signer] = 0
+	}
+	mintedBy = make(map[string]string)
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	for _, dispatched := range s.dispatchedTxs {
+		if _, ok := load[dispatched.signer]; ok {
+			load[dispatched.signer]++
+			for _, stateID := range dispatched.outputStateIDs {
+				mintedBy[stateID] = dispatched.signer
+			}
+		}
+	}
+	return load, mintedBy
+}
+
+func (s *Sequencer) nextSubmitSigner(load map[string]int) string {
+	if s.submitSignerSelection == pldconf.SubmitSignerSelectionLeastLoaded {
+		// ties go to the earliest in the pool
+		signer := s.submitSigners[0]
+		for _, candidate := range s.submitSigners[1:] {
+			if load[candidate] < load[signer] {
+				signer = candidate
+			}
+		}
+		return signer
+	}
+	signer := s.submitSigners[s.submitSignerNext%len(s.submitSigners)]
+	s.submitSignerNext++
+	return signer
+}
+
+func (s *Sequencer) prepareSignerDispatch(ctx context.Context, signingAddress string, transactionIDs []string) (*signerDispatch, error) {
+	log.L(ctx).Debugf("DispatchTransactions: %d transactions for signingAddress %s", len(transactionIDs), signingAddress)
+
+	publicTransactionsToSend := make([]*components.PrivateTransaction, 0, len(transactionIDs))
+
+	result := &signerDispatch{
+		sequence: &syncpoints.PublicDispatch{},
+	}
+	sequence := result.sequence
+
+	for _, transactionID := range transactionIDs {
+		// prepare all transactions for the given transaction IDs
+
+		txProcessor := s.getTransactionProcessor(transactionID)
+		if txProcessor == nil {
+			//TODO currently assume that all the transactions are in flight and in memory
+			// need to reload from database if not in memory
+			panic("Transaction not found")
+		}
+
+		// If we don't have a signing key for the TX at this point, we use the one allocated from our pool
+		preparedTransaction, err := txProcessor.PrepareTransaction(ctx, signingAddress)
+		if err != nil {
+			log.L(ctx).Errorf("Error preparing transaction: %s", err)
+			//TODO this is a really bad time to be getting an error.  need to think carefully about how to handle this
+			return nil, err
+		}
+		result.coordinatedTransactions = append(result.coordinatedTransactions, preparedTransaction.ID)
+		holdStateDistributions := false
+		hasPublicTransaction := preparedTransaction.PreparedPublicTransaction != nil
+		hasPrivateTransaction := preparedTransaction.PreparedPrivateTransaction != nil
+		switch {
+		case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_SEND_TRANSACTION && hasPublicTransaction && !hasPrivateTransaction:
+			log.L(ctx).Infof("Result of transaction %s is a prepared public transaction", preparedTransaction.ID)
+			publicTransactionsToSend = append(publicTransactionsToSend, preparedTransaction)
+			holdStateDistributions = s.domainAPI.Domain().StateDistributionOrder() == pldconf.StateDistributionAfterConfirm
+			sequence.PrivateTransactionDispatches = append(sequence.PrivateTransactionDispatches, &syncpoints.DispatchPersisted{
+				PrivateTransactionID: transactionID,
+			})
+		case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_SEND_TRANSACTION && hasPrivateTransaction && !hasPublicTransaction:
+			log.L(ctx).Infof("Result of transaction %s is a chained private transaction", preparedTransaction.ID)
+			validatedPrivateTx, err := s.components.TxManager().PrepareInternalPrivateTransaction(ctx, s.components.Persistence().DB(), preparedTransaction.PreparedPrivateTransaction, pldapi.SubmitModeAuto)
+			if err != nil {
+				log.L(ctx).Errorf("Error preparing transaction %s: %s", preparedTransaction.ID, err)
+				// TODO: this is just an error situation for one transaction - this function is a batch function
+				return nil, err
+			}
+			result.privateDispatches = append(result.privateDispatches, validatedPrivateTx)
+		case preparedTransaction.Inputs.Intent == prototk.TransactionSpecification_PREPARE_TRANSACTION && (hasPublicTransaction || hasPrivateTransaction):
+			log.L(ctx).Infof("Result of transaction %s is a prepared transaction public=%t private=%t", preparedTransaction.ID, hasPublicTransaction, hasPrivateTransaction)
+			preparedTransactionWithRefs := mapPreparedTransaction(preparedTransaction)
+			result.preparedTransactions = append(result.preparedTransactions, preparedTransactionWithRefs)
+			preparedTransactionJSON, err := json.Marshal(preparedTransactionWithRefs)
+			if err != nil {
+				log.L(ctx).Errorf("Error marshalling prepared transaction: %s", err)
+				// TODO: this is just an error situation for one transaction - this function is a batch function
+				return nil, err
+			}
+			result.preparedTxnDistributions = append(result.preparedTxnDistributions, &preparedtxdistribution.PreparedTxnDistribution{
+				ID:                      uuid.New().String(),
+				PreparedTxnID:           preparedTransactionWithRefs.ID.String(),
+				IdentityLocator:         preparedTransactionWithRefs.Sender,
+				Domain:                  preparedTransactionWithRefs.Domain,
+				ContractAddress:         preparedTransactionWithRefs.To.String(),
+				PreparedTransactionJSON: preparedTransactionJSON,
+			})
+
+		default:
+			err = i18n.NewError(ctx, msgs.MsgPrivateTxMgrInvalidPrepareOutcome, preparedTransaction.ID, preparedTransaction.Inputs.Intent, hasPublicTransaction, hasPrivateTransaction)
+			log.L(ctx).Errorf("Error preparing transaction %s: %s", preparedTransaction.ID, err)
+			// TODO: this is just an error situation for one transaction - this function is a batch function
+			return nil, err
+		}
+
+		sds, err := txProcessor.GetStateDistributions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.stateDistributions = append(result.stateDistributions, sds.Remote...)
+		if holdStateDistributions {
+			// persisted as held, so they are only sent once the confirmation is recorded (even across a restart)
+			for _, sd := range sds.Remote {
+				sd.HeldForTransaction = confutil.P(preparedTransaction.ID)
+			}
+		} else {
+			result.sendStateDistributions = append(result.sendStateDistributions, sds.Remote...)
+		}
+		result.localStateDistributions = append(result.localStateDistributions, sds.Local...)
+	}
+
+	preparedTransactionPayloads := make([]*pldapi.TransactionInput, len(publicTransactionsToSend))
+
+	for j, preparedTransaction := range publicTransactionsToSend {
+		preparedTransactionPayloads[j] = preparedTransaction.PreparedPublicTransaction
+	}
+
+	//Now we have the payloads, we can prepare the submission
+	publicTransactionEngine := s.components.PublicTxManager()
+
+	signers := make([]string, len(publicTransactionsToSend))
+	for i, pt := range publicTransactionsToSend {
+		unqualifiedSigner, err := tktypes.PrivateIdentityLocator(pt.Signer).Identity(ctx)
+		if err != nil {
+			errorMessage := fmt.Sprintf("failed to parse lookup key for signer %s : %s", pt.Signer, err)
+			log.L(ctx).Error(errorMessage)
+			return nil, i18n.WrapError(ctx, err, msgs.MsgPrivateTxManagerInternalError, errorMessage)
+		}
+
+		signers[i] = unqualifiedSigner
+	}
+	keyMgr := s.components.KeyManager()
+	resolvedAddrs, err := keyMgr.ResolveEthAddressBatchNewDatabaseTX(ctx, signers)
+	if err != nil {
+		return nil, err
+	}
+
+	publicTXs := make([]*components.PublicTxSubmission, len(publicTransactionsToSend))
+	for i, pt := range publicTransactionsToSend {
+		log.L(ctx).Debugf("DispatchTransactions: creating PublicTxSubmission from %s", pt.Signer)
+		publicTXs[i] = &components.PublicTxSubmission{
+			Bindings: []*components.PaladinTXReference{{TransactionID: pt.ID, TransactionType: pldapi.TransactionTypePrivate.Enum()}},
+			PublicTxInput: pldapi.PublicTxInput{
+				From:            resolvedAddrs[i],
+				To:              &s.contractAddress,
+				PublicTxOptions: pldapi.PublicTxOptions{}, // TODO: Consider propagation from paladin transaction input
+			},
+		}
+
+		// TODO: This aligning with submission in public Tx manage
+		data, err := pt.PreparedPublicTransaction.ABI[0].EncodeCallDataJSONCtx(ctx, pt.PreparedPublicTransaction.Data)
+		if err != nil {
+			return nil, err
+		}
+		publicTXs[i].Data = tktypes.HexBytes(data)
+	}
+	pubBatch, err := publicTransactionEngine.PrepareSubmissionBatch(ctx, publicTXs)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgPrivTxMgrPublicTxFail)
+	}
+	// The caller must make sure from this point we return the nonces
+	sequence.PublicTxBatch = pubBatch
+	if len(pubBatch.Rejected()) > 0 {
+		// We do not handle partial success - roll everything back
+		if err := s.components.Persistence().DB().Transaction(func(dbTX *gorm.DB) error {
+			return pubBatch.PersistRejected(ctx, dbTX)
+		}); err != nil {
+			return result, err
+		}
+		return result, i18n.WrapError(ctx, pubBatch.Rejected()[0].RejectedError(), msgs.MsgPrivTxMgrPublicTxFail)
+	}
+	return result, nil
+}
+
 func mapPreparedTransaction(tx *components.PrivateTransaction) *components.PrepareTransactionWithRefs {
 	pt := &components.PrepareTransactionWithRefs{
 		ID:       tx.ID,
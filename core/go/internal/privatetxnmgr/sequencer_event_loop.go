@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
@@ -45,6 +48,10 @@ func (s *Sequencer) evaluationLoop() {
 			s.handleEvent(ctx, pendingEvent)
 		case <-s.orchestrationEvalRequestChan:
 		case <-ticker.C:
+			s.retryHandoffs(ctx)
+			s.retryContentionDelegations(ctx)
+			s.checkDependencyFlushes(ctx)
+			s.expireRestoredDependencies(ctx)
 		case <-ctx.Done():
 			log.L(ctx).Infof("Sequencer loop exit due to canceled context, it processed %d transaction during its lifetime.", s.totalCompleted)
 			return
@@ -66,6 +73,17 @@ func (s *Sequencer) handleEvent(ctx context.Context, event ptmgrtypes.PrivateTra
 	transactionID := event.GetTransactionID()
 	log.L(ctx).Debugf("Sequencer handling event %T for transaction %s", event, transactionID)
 
+	if contention, ok := event.(*ptmgrtypes.EndorsementContentionEvent); ok {
+		// This is about another coordinator's transaction, not one of ours
+		s.resolveEndorsementContention(ctx, contention)
+		return
+	}
+
+	if dispatched := s.getDispatchedTransaction(transactionID); dispatched != nil {
+		s.handleDispatchedTransactionEvent(ctx, dispatched, event)
+		return
+	}
+
 	transactionProcessor := s.getTransactionProcessor(transactionID)
 	if transactionProcessor == nil {
 		//What has happened here is either:
@@ -78,6 +96,9 @@ func (s *Sequencer) handleEvent(ctx context.Context, event ptmgrtypes.PrivateTra
 		// in case of (b) we ignore it because an event for a completed transaction is redundant.
 		// most likely it is a tardy response for something we timed out waiting for and failed or retried successfully
 		log.L(ctx).Warnf("Received an event for a transaction that is not in flight %s", transactionID)
+		if cancel, ok := event.(*ptmgrtypes.TransactionCancelEvent); ok {
+			cancel.Result <- i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelNotInFlight, transactionID, s.contractAddress)
+		}
 		return
 	}
 
@@ -104,7 +125,7 @@ func (s *Sequencer) handleEvent(ctx context.Context, event ptmgrtypes.PrivateTra
 	*/
 	if transactionProcessor.IsComplete() {
 
-		s.graph.RemoveTransaction(ctx, transactionID)
+		s.removeFromGraph(ctx, transactionProcessor.ID())
 		s.removeTransactionProcessor(transactionID)
 	} else {
 
@@ -114,36 +135,179 @@ func (s *Sequencer) handleEvent(ctx context.Context, event ptmgrtypes.PrivateTra
 			Action is retry safe and idempotent.
 		*/
 		transactionProcessor.Action(ctx)
+		s.snapshotAttestations(ctx, transactionProcessor)
+	}
+
+	if !transactionProcessor.CoordinatingLocally() || !transactionProcessor.ReadyForSequencing() {
+		// coordination of this transaction has moved to another node (e.g. we lost a contention bid), or it has been cancelled, so it must not be dispatched from here.
+		// Dependencies restored for a transaction that has not yet been added back to the graph since a restart are kept until it completes.
+		if s.graph.IncludesTransaction(transactionID) {
+			s.removeFromGraph(ctx, transactionProcessor.ID())
+		}
 	}
 
 	if transactionProcessor.CoordinatingLocally() && transactionProcessor.ReadyForSequencing() && !transactionProcessor.Dispatched() {
 		// we are responsible for coordinating the endorsement flow for this transaction, ensure that it has been added it to the graph
 		// NOTE: AddTransaction is idempotent so we don't need to check whether we have already added it
-		s.graph.AddTransaction(ctx, transactionProcessor)
+		if dependencies := s.graph.AddTransaction(ctx, transactionProcessor); len(dependencies) > 0 {
+			// persist the dependencies so that the ordering survives a restart
+			s.dependencyFlushes = append(s.dependencyFlushes, s.syncPoints.QueueDependencies(ctx, s.contractAddress, dependencies))
+		}
 	}
 
-	//analyze the graph to see if we can dispatch any transactions
-	dispatchableTransactions, err := s.graph.GetDispatchableTransactions(ctx)
-	if err != nil {
-		//If the graph can't give us an answer without an error then we have no confidence that we are in possession of a valid
-		// graph of transactions that can successfully be dispatched so only option here is to abandon everything we have in-memory for this contract
-		// and start again
-		log.L(ctx).Errorf("Error getting dispatchable transactions: %s", err)
-		s.abort(err)
-		return
+	s.dispatchFromGraph(ctx)
+}
+
+// analyze the graph to see if we can dispatch any transactions
+//   - dependants with a different signer to their dependencies are held back until the dependencies have been dispatched,
+//     so we keep going round until there is nothing more to dispatch
+func (s *Sequencer) dispatchFromGraph(ctx context.Context) {
+	for {
+		dispatchableTransactions, err := s.graph.GetDispatchableTransactions(ctx)
+		if err != nil {
+			//If the graph can't give us an answer without an error then we have no confidence that we are in possession of a valid
+			// graph of transactions that can successfully be dispatched so only option here is to abandon everything we have in-memory for this contract
+			// and start again
+			log.L(ctx).Errorf("Error getting dispatchable transactions: %s", err)
+			s.abort(err)
+			return
+		}
+		if len(dispatchableTransactions) == 0 {
+			log.L(ctx).Debug("No dispatchable transactions")
+			return
+		}
+		err = s.DispatchTransactions(ctx, dispatchableTransactions)
+		if err != nil {
+			log.L(ctx).Errorf("Error dispatching transaction: %s", err)
+			// assuming this is a transient error with e.g. network or the DB, then we will try again next time round the loop
+			return
+		}
+
+		//DispatchTransactions is a persistence point so we can remove the transactions from our graph now that they are dispatched
+		s.graph.RemoveTransactions(ctx, dispatchableTransactions)
 	}
-	if len(dispatchableTransactions) == 0 {
-		log.L(ctx).Debug("No dispatchable transactions")
+
+}
+
+// Nothing reloads the transactions that were in flight before a restart, so the dependencies restored on transactions
+// that have not come back after the expiry are removed (along with their persisted records) rather than holding
+// back their dependants forever
+func (s *Sequencer) expireRestoredDependencies(ctx context.Context) {
+	if s.dependencyExpiry <= 0 {
 		return
 	}
-	err = s.DispatchTransactions(ctx, dispatchableTransactions)
-	if err != nil {
-		log.L(ctx).Errorf("Error dispatching transaction: %s", err)
-		// assuming this is a transient error with e.g. network or the DB, then we will try again next time round the loop
+	expired := s.graph.ExpireRestoredDependencies(ctx, time.Now().Add(-s.dependencyExpiry))
+	for _, txID := range expired {
+		s.dependencyFlushes = append(s.dependencyFlushes, s.syncPoints.QueueDependencyRemoval(ctx, s.contractAddress, txID))
+	}
+	if len(expired) > 0 {
+		s.dispatchFromGraph(ctx)
+	}
+}
+
+// Nothing waits on the dependency writes. If one fails the in-memory graph still holds the dependencies,
+// and they are only lost if the node also restarts before they are dispatched.
+func (s *Sequencer) checkDependencyFlushes(ctx context.Context) {
+	pending := s.dependencyFlushes[:0]
+	for _, f := range s.dependencyFlushes {
+		done, err := f.Flushed()
+		if !done {
+			pending = append(pending, f)
+		} else if err != nil {
+			log.L(ctx).Errorf("Error persisting sequencer dependencies for contract %s: %s", s.contractAddress, err)
+		}
+	}
+	s.dependencyFlushes = pending
+}
+
+// removeFromGraph removes a transaction that is leaving the sequencer without being dispatched, along with its persisted dependencies
+func (s *Sequencer) removeFromGraph(ctx context.Context, transactionID uuid.UUID) {
+	if s.graph.RemoveTransaction(ctx, transactionID.String()) {
+		s.dependencyFlushes = append(s.dependencyFlushes, s.syncPoints.QueueDependencyRemoval(ctx, s.contractAddress, transactionID))
+	}
+}
+
+// Events for a transaction that has been evicted from memory on dispatch. All that remains to be done
+// is to remove it from the domain context once it is confirmed, which is the same finalize the flow
+// would have performed (no receipt is written, as success receipts come from the domain event handler).
+func (s *Sequencer) handleDispatchedTransactionEvent(ctx context.Context, dispatched *dispatchedTransaction, event ptmgrtypes.PrivateTransactionEvent) {
+	txID := event.GetTransactionID()
+	s.incompleteTxProcessMapMutex.Lock()
+	defer s.incompleteTxProcessMapMutex.Unlock()
+	switch event := event.(type) {
+	case *ptmgrtypes.TransactionConfirmedEvent:
+		dispatched.status.Status = "confirmed"
+		dispatched.status.LatestEvent = "TransactionConfirmedEvent"
+		s.finalizeDispatched(ctx, dispatched)
+	case *ptmgrtypes.TransactionFinalizeError:
+		dispatched.status.LatestEvent = "TransactionFinalizeError"
+		dispatched.finalizePending = false
+		s.finalizeDispatched(ctx, dispatched)
+	case *ptmgrtypes.TransactionFinalizedEvent:
+		delete(s.dispatchedTxs, txID)
+		s.totalCompleted++
+	case *ptmgrtypes.TransactionCancelEvent:
+		event.Result <- i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelDispatched, txID)
+	default:
+		log.L(ctx).Debugf("Ignoring %T event for dispatched transaction %s", event, txID)
+	}
+}
+
+// Must be called holding incompleteTxProcessMapMutex
+func (s *Sequencer) finalizeDispatched(ctx context.Context, dispatched *dispatchedTransaction) {
+	if dispatched.finalizePending {
 		return
 	}
+	dispatched.finalizePending = true
+	txID := dispatched.id
+	s.syncPoints.QueueTransactionFinalize(ctx, s.domainAPI.Domain().Name(), s.contractAddress, txID, "",
+		func(ctx context.Context) {
+			log.L(ctx).Infof("Dispatched transaction %s finalize committed", txID)
+			s.endorsementGatherer.DomainContext().ResetTransactions(txID)
+			go s.publisher.PublishTransactionFinalizedEvent(ctx, txID.String())
+		},
+		func(ctx context.Context, rollbackErr error) {
+			log.L(ctx).Errorf("Dispatched transaction %s finalize rolled back: %s", txID, rollbackErr)
+			s.endorsementGatherer.DomainContext().Reset()
+			go s.publisher.PublishTransactionFinalizeError(ctx, txID.String(), "", rollbackErr)
+		},
+	)
+}
+
+// A transaction that lost a contention bid re-sends its delegation to the winner from its Action, until it is acknowledged
+func (s *Sequencer) retryContentionDelegations(ctx context.Context) {
+	for _, txProc := range s.getTransactionProcessors() {
+		if txProc.ContentionDelegating() {
+			txProc.Action(ctx)
+		}
+	}
+}
 
-	//DispatchTransactions is a persistence point so we can remove the transactions from our graph now that they are dispatched
-	s.graph.RemoveTransactions(ctx, dispatchableTransactions)
+// If any of the states spent by another coordinator's transaction is also spent by a transaction we are coordinating that
+// has not been dispatched, the two coordinators are bidding for the same state. Both nodes resolve the bid the same way,
+// so only the loser needs to act, by moving its transaction to the winning coordinator.
+func (s *Sequencer) resolveEndorsementContention(ctx context.Context, event *ptmgrtypes.EndorsementContentionEvent) {
+	requested := make(map[string]bool, len(event.InputStateIDs))
+	for _, stateID := range event.InputStateIDs {
+		requested[stateID] = true
+	}
 
+	for _, txProc := range s.getTransactionProcessors() {
+		if !txProc.CoordinatingLocally() || !txProc.ReadyForSequencing() {
+			continue
+		}
+		for _, stateID := range txProc.InputStateIDs() {
+			if !requested[stateID] {
+				continue
+			}
+			winner, err := s.contentionResolver.ResolvePair(stateID, s.nodeID, event.CoordinatorNode)
+			if err != nil {
+				log.L(ctx).Errorf("Failed to resolve contention for state %s between %s and %s: %s", stateID, s.nodeID, event.CoordinatorNode, err)
+			} else if winner != s.nodeID {
+				log.L(ctx).Infof("Transaction %s lost contention for state %s to node %s", txProc.ID(), stateID, winner)
+				s.publisher.PublishTransactionContentionLostEvent(ctx, txProc.ID().String(), winner)
+			}
+			break
+		}
+	}
 }
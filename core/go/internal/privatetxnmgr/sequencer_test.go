@@ -18,19 +18,26 @@ package privatetxnmgr
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/syncpoints"
+	"github.com/kaleido-io/paladin/core/internal/statedistribution"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/preparedtxdistributionmocks"
 	"github.com/kaleido-io/paladin/core/mocks/privatetxnmgrmocks"
 	"github.com/kaleido-io/paladin/core/mocks/statedistributionmocks"
 
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -183,3 +190,793 @@ func TestSequencerPollingLoopCancelContext(t *testing.T) {
 
 	cancel()
 }
+
+func TestSequencerRestoresDependenciesOnStart(t *testing.T) {
+
+	ctx := context.Background()
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	testOc, mocks, ocDone := newSequencerForTesting(t, ctx, domainAddress)
+	defer ocDone()
+	defer testOc.Stop()
+
+	dependant := uuid.New()
+	dependency := uuid.New()
+	flush := testOc.syncPoints.QueueDependencies(ctx, *domainAddress, []*syncpoints.SequencerDependency{
+		{Dependant: dependant, Dependency: dependency, StateID: "S0"},
+	})
+	require.Eventually(t, func() bool {
+		done, err := flush.Flushed()
+		require.NoError(t, err)
+		return done
+	}, 5*time.Second, 10*time.Millisecond)
+	dependencies, err := testOc.syncPoints.LoadDependencies(ctx, *domainAddress)
+	require.NoError(t, err)
+	assert.Len(t, dependencies, 1)
+
+	// a new sequencer for the same contract, as after a restart, picks up the dependency before processing any events
+	restarted := NewSequencer(ctx, mocks.privateTxManager, tktypes.RandHex(16), *domainAddress, &pldconf.PrivateTxManagerSequencerConfig{}, mocks.allComponents, mocks.domainSmartContract, mocks.endorsementGatherer, mocks.publisher, testOc.syncPoints, mocks.identityResolver, mocks.stateDistributer, mocks.preparedTransactionDistributer, mocks.transportWriter, 30*time.Second)
+	assert.Zero(t, restarted.dependencyExpiry) // restored dependencies are kept until their transactions come back, by default
+	restartedDone, err := restarted.Start(ctx)
+	require.NoError(t, err)
+	restarted.Stop()
+	<-restartedDone
+	assert.Equal(t, map[string]map[string][]string{
+		dependant.String(): {dependency.String(): {"S0"}},
+	}, restarted.graph.(*graph).dependencies)
+
+	// the dependency completing removes it from the database as well as the graph
+	restarted.removeFromGraph(ctx, dependency)
+	require.Eventually(t, func() bool {
+		dependencies, err := testOc.syncPoints.LoadDependencies(ctx, *domainAddress)
+		require.NoError(t, err)
+		return len(dependencies) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+type failedDependencyFlush struct{ err error }
+
+func (f *failedDependencyFlush) Flushed() (bool, error) { return true, f.err }
+
+func TestSequencerExpiresRestoredDependencies(t *testing.T) {
+
+	ctx := context.Background()
+	domainAddress := tktypes.MustEthAddress(tktypes.RandHex(20))
+	testOc, _, ocDone := newSequencerForTesting(t, ctx, domainAddress)
+	defer ocDone()
+	// the graph is only used on the sequencer loop
+	testOc.Stop()
+	<-testOc.sequencerLoopDone
+
+	dependant := uuid.New()
+	dependency := uuid.New()
+	testOc.syncPoints.QueueDependencies(ctx, *domainAddress, []*syncpoints.SequencerDependency{
+		{Dependant: dependant, Dependency: dependency, StateID: "S0"},
+	})
+	require.Eventually(t, func() bool {
+		dependencies, err := testOc.syncPoints.LoadDependencies(ctx, *domainAddress)
+		require.NoError(t, err)
+		return len(dependencies) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	testOc.graph.RestoreDependencies(ctx, []*syncpoints.SequencerDependency{
+		{Dependant: dependant, Dependency: dependency, StateID: "S0"},
+	})
+
+	// nothing expires when disabled
+	testOc.dependencyExpiry = 0
+	testOc.expireRestoredDependencies(ctx)
+	assert.Len(t, testOc.graph.(*graph).dependencies, 1)
+
+	// the dependency on a transaction that has not come back is removed from the database as well as the graph
+	testOc.dependencyExpiry = 1 * time.Nanosecond
+	time.Sleep(1 * time.Millisecond)
+	testOc.expireRestoredDependencies(ctx)
+	assert.Empty(t, testOc.graph.(*graph).dependencies)
+	require.Len(t, testOc.dependencyFlushes, 1)
+	// a write that fails is dropped after logging, as the graph still holds the dependencies
+	testOc.dependencyFlushes = append(testOc.dependencyFlushes, &failedDependencyFlush{err: fmt.Errorf("pop")})
+	require.Eventually(t, func() bool {
+		testOc.checkDependencyFlushes(ctx)
+		return len(testOc.dependencyFlushes) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	dependencies, err := testOc.syncPoints.LoadDependencies(ctx, *domainAddress)
+	require.NoError(t, err)
+	assert.Empty(t, dependencies)
+}
+
+func TestSequencerCancelTransaction(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, _, _ := newSequencerForTesting(t, ctx, nil)
+
+	err := testOc.CancelTransaction(ctx, uuid.NewString())
+	assert.Regexp(t, "PD011852", err)
+
+	txID := uuid.NewString()
+	tf := privatetxnmgrmocks.NewTransactionFlow(t)
+	tf.On("ApplyEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(*ptmgrtypes.TransactionCancelEvent).Result <- nil
+	}).Once()
+	tf.On("IsComplete").Return(false)
+	tf.On("Action", mock.Anything).Once()
+	tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID})
+	tf.On("CoordinatingLocally").Return(true)
+	tf.On("ReadyForSequencing").Return(false)
+	testOc.incompleteTxProcessMapMutex.Lock()
+	testOc.incompleteTxSProcessMap[txID] = tf
+	testOc.incompleteTxProcessMapMutex.Unlock()
+
+	err = testOc.CancelTransaction(ctx, txID)
+	require.NoError(t, err)
+
+	// once the sequencer has stopped, nothing can be cancelled
+	testOc.Stop()
+	<-testOc.sequencerLoopDone
+	err = testOc.CancelTransaction(ctx, txID)
+	assert.Regexp(t, "PD011852", err)
+}
+
+func TestSequencerCancelTransactionEventsFull(t *testing.T) {
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	testOc := &Sequencer{
+		contractAddress:   *tktypes.RandAddress(),
+		pendingEvents:     make(chan ptmgrtypes.PrivateTransactionEvent), // nothing is reading
+		sequencerLoopDone: make(chan struct{}),
+	}
+
+	cancelCtx()
+	err := testOc.CancelTransaction(ctx, uuid.NewString())
+	assert.Regexp(t, "PD010301", err)
+
+	close(testOc.sequencerLoopDone)
+	err = testOc.CancelTransaction(context.Background(), uuid.NewString())
+	assert.Regexp(t, "PD011852", err)
+
+}
+
+func TestSequencerResolveEndorsementContention(t *testing.T) {
+
+	ctx := context.Background()
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	contentionResolver := privatetxnmgrmocks.NewContentionResolver(t)
+	testOc.contentionResolver = contentionResolver
+
+	addFlow := func(readyForSequencing bool, inputStateIDs ...string) uuid.UUID {
+		txID := uuid.New()
+		tf := privatetxnmgrmocks.NewTransactionFlow(t)
+		tf.On("ID").Return(txID).Maybe()
+		tf.On("CoordinatingLocally").Return(true)
+		tf.On("ReadyForSequencing").Return(readyForSequencing)
+		tf.On("InputStateIDs").Return(inputStateIDs).Maybe()
+		testOc.incompleteTxProcessMapMutex.Lock()
+		testOc.incompleteTxSProcessMap[txID.String()] = tf
+		testOc.incompleteTxProcessMapMutex.Unlock()
+		return txID
+	}
+	lostTxID := addFlow(true, "state1", "state2")
+	addFlow(true, "state3")
+	addFlow(false, "state4")
+	addFlow(true, "state5")
+
+	contentionResolver.On("ResolvePair", "state1", testOc.nodeID, "nodeB").Return("nodeB", nil).Once()
+	contentionResolver.On("ResolvePair", "state3", testOc.nodeID, "nodeB").Return(testOc.nodeID, nil).Once()
+	lost := make(chan string, 1)
+	dependencyMocks.publisher.On("PublishTransactionContentionLostEvent", mock.Anything, lostTxID.String(), "nodeB").Run(func(args mock.Arguments) {
+		lost <- args.String(1)
+	}).Once()
+
+	testOc.resolveEndorsementContention(ctx, &ptmgrtypes.EndorsementContentionEvent{
+		CoordinatorNode: "nodeB",
+		InputStateIDs:   []string{"state1", "state2", "state3", "state4"},
+	})
+	assert.Equal(t, lostTxID.String(), waitForChannel(t, lost))
+
+}
+
+func TestSequencerEvictsDispatchedTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.maxConcurrentProcess = 10
+	domain := componentmocks.NewDomain(t)
+	domain.On("Name").Return("domain1")
+	dependencyMocks.domainSmartContract.On("Domain").Return(domain)
+	dependencyMocks.domainContext.On("ResetTransactions", mock.Anything).Return()
+	finalized := make(chan string, testOc.maxConcurrentProcess)
+	dependencyMocks.publisher.On("PublishTransactionFinalizedEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		finalized <- args[1].(string)
+	})
+
+	// Many more transactions than maxConcurrentProcess pass through, but only those that have
+	// not been dispatched are held in memory, and nothing is retained once they are confirmed
+	totalTransactions := 1000
+	for dispatched := 0; dispatched < totalTransactions; dispatched += testOc.maxConcurrentProcess {
+		txIDs := make([]string, testOc.maxConcurrentProcess)
+		for i := range txIDs {
+			txID := uuid.New()
+			txIDs[i] = txID.String()
+			tf := privatetxnmgrmocks.NewTransactionFlow(t)
+			tf.On("ID").Return(txID)
+			tf.On("GetTxStatus", mock.Anything).Return(components.PrivateTxStatus{TxID: txID.String(), Status: "dispatched"}, nil)
+			tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID.String()})
+			tf.On("InputStateIDs").Return([]string{tktypes.RandHex(32)})
+			tf.On("OutputStateIDs").Return([]string{})
+			testOc.incompleteTxProcessMapMutex.Lock()
+			require.Less(t, len(testOc.incompleteTxSProcessMap), testOc.maxConcurrentProcess)
+			testOc.incompleteTxSProcessMap[txID.String()] = tf
+			testOc.incompleteTxProcessMapMutex.Unlock()
+		}
+		for _, txID := range txIDs {
+			testOc.evictDispatchedTransaction(ctx, txID, "")
+		}
+
+		// the status of dispatched transactions is still available, and they cannot be cancelled
+		status, err := testOc.GetTxStatus(ctx, txIDs[0])
+		require.NoError(t, err)
+		assert.Equal(t, "dispatched", status.Status)
+		attestations := testOc.GetTxAttestations(ctx, txIDs[0])
+		require.NotNil(t, attestations)
+		assert.Equal(t, txIDs[0], attestations.TxID)
+		err = testOc.CancelTransaction(ctx, txIDs[0])
+		assert.Regexp(t, "PD011849", err)
+
+		for _, txID := range txIDs {
+			testOc.HandleEvent(ctx, &ptmgrtypes.TransactionConfirmedEvent{
+				PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txID},
+			})
+		}
+		for range txIDs {
+			txID := waitForChannel(t, finalized)
+			testOc.HandleEvent(ctx, &ptmgrtypes.TransactionFinalizedEvent{
+				PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: txID},
+			})
+		}
+	}
+
+	// Events are handled in order, so once this returns all the finalized events have been handled
+	err := testOc.CancelTransaction(ctx, uuid.NewString())
+	assert.Regexp(t, "PD011852", err)
+
+	testOc.incompleteTxProcessMapMutex.Lock()
+	defer testOc.incompleteTxProcessMapMutex.Unlock()
+	assert.Empty(t, testOc.incompleteTxSProcessMap)
+	assert.Empty(t, testOc.dispatchedTxs)
+	assert.Equal(t, int64(totalTransactions), testOc.totalCompleted)
+	dependencyMocks.domainContext.AssertNumberOfCalls(t, "ResetTransactions", totalTransactions)
+}
+
+func TestDispatchTransactionsStateDistributionOrder(t *testing.T) {
+	for _, order := range []pldconf.StateDistributionOrder{pldconf.StateDistributionBeforeDispatch, pldconf.StateDistributionAfterConfirm} {
+		t.Run(string(order), func(t *testing.T) {
+			ctx := context.Background()
+
+			testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+			domain := componentmocks.NewDomain(t)
+			domain.On("Name").Return("domain1").Maybe()
+			domain.On("StateDistributionOrder").Return(order)
+			dependencyMocks.domainSmartContract.On("Domain").Return(domain)
+			dependencyMocks.allComponents.On("PublicTxManager").Return(newFakePublicTxManager(t))
+			dependencyMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"signer1"}).Return([]*tktypes.EthAddress{tktypes.RandAddress()}, nil)
+			dependencyMocks.txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, testOc.nodeID, mock.Anything).Return(nil)
+			dependencyMocks.stateDistributer.On("BuildNullifiers", mock.Anything, mock.Anything).Return(nil, nil)
+			dependencyMocks.preparedTransactionDistributer.On("DistributePreparedTransactions", mock.Anything, mock.Anything).Return()
+			dependencyMocks.publisher.On("PublishTransactionDispatchedEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			dependencyMocks.publisher.On("PublishTransactionFinalizedEvent", mock.Anything, mock.Anything).Return().Maybe()
+			dependencyMocks.domainContext.On("ResetTransactions", mock.Anything).Return().Maybe()
+			dependencyMocks.domainContext.On("Ctx").Return(ctx)
+			dependencyMocks.domainContext.On("Info").Return(components.DomainContextInfo{ID: uuid.New()})
+			dependencyMocks.domainContext.On("Flush", mock.Anything).Return(func(error) {}, nil).Maybe()
+
+			// The distribution is persisted alongside the dispatch, so needs a state to refer to
+			stateID := tktypes.RandHex(32)
+			err := testOc.components.Persistence().DB().Exec(`INSERT INTO states ("id", "created", "domain_name") VALUES (?, ?, ?)`, stateID, 0, "domain1").Error
+			require.NoError(t, err)
+			stateDistribution := &components.StateDistribution{
+				ID:              uuid.NewString(),
+				StateID:         stateID,
+				IdentityLocator: "bob@node2",
+				Domain:          "domain1",
+				ContractAddress: testOc.contractAddress.String(),
+			}
+
+			txID := uuid.New()
+			cv, err := testABI[0].Inputs.ParseExternalData(map[string]any{
+				"inputs":  []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"outputs": []any{tktypes.Bytes32(tktypes.RandBytes(32))},
+				"data":    "0xfeedbeef",
+			})
+			require.NoError(t, err)
+			jsonData, err := cv.JSON()
+			require.NoError(t, err)
+			tf := privatetxnmgrmocks.NewTransactionFlow(t)
+			tf.On("PrepareTransaction", mock.Anything, mock.Anything).Return(&components.PrivateTransaction{
+				ID: txID,
+				Inputs: &components.TransactionInputs{
+					Domain: "domain1",
+					To:     testOc.contractAddress,
+					Intent: prototk.TransactionSpecification_SEND_TRANSACTION,
+				},
+				Signer:       "signer1",
+				PostAssembly: &components.TransactionPostAssembly{},
+				PreparedPublicTransaction: &pldapi.TransactionInput{
+					ABI: abi.ABI{testABI[0]},
+					TransactionBase: pldapi.TransactionBase{
+						To:   &testOc.contractAddress,
+						Data: tktypes.RawJSON(jsonData),
+					},
+				},
+			}, nil)
+			tf.On("GetStateDistributions", mock.Anything).Return(&components.StateDistributionSet{
+				Remote: []*components.StateDistribution{stateDistribution},
+			}, nil)
+			tf.On("ApplyEvent", mock.Anything, mock.Anything).Return()
+			tf.On("ID").Return(txID)
+			tf.On("GetTxStatus", mock.Anything).Return(components.PrivateTxStatus{TxID: txID.String(), Status: "dispatched"}, nil)
+			tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID.String()})
+			tf.On("InputStateIDs").Return([]string{})
+			tf.On("OutputStateIDs").Return([]string{})
+			testOc.incompleteTxSProcessMap[txID.String()] = tf
+
+			var sequence []string
+			dependencyMocks.stateDistributer.On("DistributeStates", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				sds := args[1].([]*components.StateDistribution)
+				if len(sds) > 0 {
+					assert.Equal(t, []*components.StateDistribution{stateDistribution}, sds)
+					sequence = append(sequence, "distributed")
+				}
+			}).Return()
+
+			// The public transaction is released for submission as DispatchTransactions returns
+			err = testOc.DispatchTransactions(ctx, ptmgrtypes.DispatchableTransactions{"signer1": {txID.String()}})
+			require.NoError(t, err)
+			sequence = append(sequence, "dispatched")
+
+			var heldFor []*statedistribution.StateDistributionPersisted
+			err = testOc.components.Persistence().DB().Table("state_distributions").
+				Where("id = ?", stateDistribution.ID).
+				Find(&heldFor).
+				Error
+			require.NoError(t, err)
+			require.Len(t, heldFor, 1)
+			if order == pldconf.StateDistributionAfterConfirm {
+				// held in the DB until the confirmation is recorded, so nothing is sent on dispatch
+				assert.Equal(t, []string{"dispatched"}, sequence)
+				assert.Equal(t, &txID, heldFor[0].HeldForTransaction)
+			} else {
+				assert.Nil(t, heldFor[0].HeldForTransaction)
+				assert.Equal(t, []string{"distributed", "dispatched"}, sequence)
+			}
+		})
+	}
+}
+
+func TestNewSequencerHandoffWhenOverloaded(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.maxConcurrentProcess = 0
+	testOc.handoffNodes = []string{testOc.nodeID, "node2"}
+	testOc.handoffTimeout = 0
+	testOc.handoffMaxRetries = 1
+
+	testTx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			From: "alice",
+		},
+		PreAssembly: &components.TransactionPreAssembly{},
+	}
+
+	// An overloaded node hands a new transaction off to a peer (skipping itself), rather than queuing it.
+	// It is tracked until the peer acknowledges it, even if the first send fails.
+	var delegationIDs []string
+	dependencyMocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Run(func(args mock.Arguments) {
+		delegationIDs = append(delegationIDs, args.String(1))
+	}).Return(errors.New("pop")).Once()
+	assert.False(t, testOc.ProcessNewTransaction(ctx, testTx))
+	assert.Empty(t, testOc.incompleteTxSProcessMap)
+	require.Contains(t, testOc.pendingHandoffs, testTx.ID.String())
+
+	// Submitting it again does not send a second handoff
+	assert.False(t, testOc.ProcessNewTransaction(ctx, testTx))
+	dependencyMocks.transportWriter.AssertNumberOfCalls(t, "SendDelegationRequest", 1)
+
+	// Unacknowledged handoffs are re-sent with the same delegation ID
+	dependencyMocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Run(func(args mock.Arguments) {
+		delegationIDs = append(delegationIDs, args.String(1))
+	}).Return(nil).Once()
+	testOc.retryHandoffs(ctx)
+	require.Len(t, delegationIDs, 2)
+	assert.Equal(t, delegationIDs[0], delegationIDs[1])
+
+	// The delegation is recorded only once the peer acknowledges it
+	assert.False(t, testOc.HandoffAcknowledged(ctx, testTx.ID.String(), uuid.New().String()))
+	dependencyMocks.txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, "node2", []uuid.UUID{testTx.ID}).Return(nil).Once()
+	assert.True(t, testOc.HandoffAcknowledged(ctx, testTx.ID.String(), delegationIDs[0]))
+	assert.Empty(t, testOc.pendingHandoffs)
+	assert.False(t, testOc.HandoffAcknowledged(ctx, testTx.ID.String(), delegationIDs[0]))
+
+	// Transactions delegated to us are never handed on, so they cannot loop between overloaded peers
+	assert.True(t, testOc.ProcessInFlightTransaction(ctx, testTx))
+	dependencyMocks.transportWriter.AssertNumberOfCalls(t, "SendDelegationRequest", 2)
+}
+
+func TestNewSequencerHandoffNotAcknowledged(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.maxConcurrentProcess = 0
+	testOc.handoffNodes = []string{"node2"}
+	testOc.handoffTimeout = 0
+	testOc.handoffMaxRetries = 1
+
+	testTx := &components.PrivateTransaction{
+		ID: uuid.New(),
+		Inputs: &components.TransactionInputs{
+			From: "alice",
+		},
+		PreAssembly: &components.TransactionPreAssembly{},
+	}
+
+	dependencyMocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Return(nil).Twice()
+	assert.False(t, testOc.ProcessNewTransaction(ctx, testTx))
+	testOc.retryHandoffs(ctx)
+
+	// Once the retries are exhausted, the transaction is coordinated locally rather than being lost
+	assembleFailed := make(chan bool, 1)
+	dependencyMocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("pop"))
+	dependencyMocks.publisher.On("PublishTransactionAssembleFailedEvent", mock.Anything, testTx.ID.String(), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		assembleFailed <- true
+	})
+	testOc.retryHandoffs(ctx)
+	_ = waitForChannel(t, assembleFailed)
+	assert.Empty(t, testOc.pendingHandoffs)
+	assert.Contains(t, testOc.incompleteTxSProcessMap, testTx.ID.String())
+	dependencyMocks.transportWriter.AssertNumberOfCalls(t, "SendDelegationRequest", 2)
+}
+
+// Sets up a batch of independent prepared transactions across a number of signing addresses, tracking how
+// many are being prepared at once and the order they are prepared in for each signing address
+type dispatchLoadTest struct {
+	dispatchable ptmgrtypes.DispatchableTransactions
+	barrier      bool // the first transaction for each signing address waits for all signing addresses to start
+
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+	started     int
+	allStarted  chan struct{}
+	prepared    map[string][]string
+}
+
+func newDispatchLoadTest(t *testing.T, testOc *Sequencer, dependencyMocks *sequencerDepencyMocks, signerCount, txPerSigner int) *dispatchLoadTest {
+	dl := &dispatchLoadTest{
+		dispatchable: make(ptmgrtypes.DispatchableTransactions),
+		allStarted:   make(chan struct{}),
+		prepared:     make(map[string][]string),
+	}
+	for i := 0; i < signerCount; i++ {
+		signer := fmt.Sprintf("signer%d", i)
+		for j := 0; j < txPerSigner; j++ {
+			txID := uuid.New()
+			first := j == 0
+			tf := privatetxnmgrmocks.NewTransactionFlow(t)
+			tf.On("PrepareTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				dl.prepare(t, signer, txID.String(), first)
+			}).Return(&components.PrivateTransaction{
+				ID: txID,
+				Inputs: &components.TransactionInputs{
+					Domain: "domain1",
+					To:     testOc.contractAddress,
+					Intent: prototk.TransactionSpecification_PREPARE_TRANSACTION,
+				},
+				PostAssembly:               &components.TransactionPostAssembly{},
+				PreparedPrivateTransaction: &pldapi.TransactionInput{},
+			}, nil)
+			tf.On("GetStateDistributions", mock.Anything).Return(&components.StateDistributionSet{}, nil)
+			tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID.String()})
+			testOc.incompleteTxSProcessMap[txID.String()] = tf
+			dl.dispatchable[signer] = append(dl.dispatchable[signer], txID.String())
+		}
+	}
+
+	publicTxMgr := componentmocks.NewPublicTxManager(t)
+	pubBatch := componentmocks.NewPublicTxBatch(t)
+	dependencyMocks.allComponents.On("PublicTxManager").Return(publicTxMgr)
+	dependencyMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{}).Return([]*tktypes.EthAddress{}, nil)
+	publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, []*components.PublicTxSubmission{}).Return(pubBatch, nil).Times(signerCount)
+	pubBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	// Fail after all the signing addresses are prepared, checking every public batch is completed
+	dependencyMocks.stateDistributer.On("BuildNullifiers", mock.Anything, mock.Anything).Return(nil, errors.New("pop"))
+	pubBatch.On("Completed", mock.Anything, false).Return().Times(signerCount)
+	return dl
+}
+
+func (dl *dispatchLoadTest) prepare(t *testing.T, signer, txID string, first bool) {
+	dl.lock.Lock()
+	dl.inFlight++
+	if dl.inFlight > dl.maxInFlight {
+		dl.maxInFlight = dl.inFlight
+	}
+	dl.prepared[signer] = append(dl.prepared[signer], txID)
+	if first {
+		dl.started++
+		if dl.started == len(dl.dispatchable) {
+			close(dl.allStarted)
+		}
+	}
+	dl.lock.Unlock()
+
+	if first && dl.barrier {
+		select {
+		case <-dl.allStarted:
+		case <-time.After(timeTillDeadline(t)):
+			assert.Fail(t, "signing addresses were not prepared concurrently")
+		}
+	} else {
+		time.Sleep(1 * time.Millisecond)
+	}
+
+	dl.lock.Lock()
+	dl.inFlight--
+	dl.lock.Unlock()
+}
+
+func TestDispatchTransactionsConcurrentAcrossSigners(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.dispatchConcurrency = 20
+	dl := newDispatchLoadTest(t, testOc, dependencyMocks, 20, 10)
+	dl.barrier = true
+
+	err := testOc.DispatchTransactions(ctx, dl.dispatchable)
+	assert.Regexp(t, "pop", err)
+
+	// every signing address was being prepared at the same time, and the order within each was preserved
+	assert.Equal(t, 20, dl.maxInFlight)
+	assert.Equal(t, map[string][]string(dl.dispatchable), dl.prepared)
+}
+
+func TestDispatchTransactionsConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.dispatchConcurrency = 1
+	dl := newDispatchLoadTest(t, testOc, dependencyMocks, 5, 5)
+
+	err := testOc.DispatchTransactions(ctx, dl.dispatchable)
+	assert.Regexp(t, "pop", err)
+
+	assert.Equal(t, 1, dl.maxInFlight)
+	assert.Equal(t, map[string][]string(dl.dispatchable), dl.prepared)
+}
+
+func TestDispatchTransactionsSignerFailCompletesOtherBatches(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	publicTxMgr := componentmocks.NewPublicTxManager(t)
+	pubBatch := componentmocks.NewPublicTxBatch(t)
+	dependencyMocks.allComponents.On("PublicTxManager").Return(publicTxMgr)
+	dependencyMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{}).Return([]*tktypes.EthAddress{}, nil)
+	publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(pubBatch, nil).Once()
+	pubBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	pubBatch.On("Completed", mock.Anything, false).Return().Once()
+
+	dispatchable := ptmgrtypes.DispatchableTransactions{}
+	for _, signer := range []string{"signer0", "signer1"} {
+		txID := uuid.New()
+		tf := privatetxnmgrmocks.NewTransactionFlow(t)
+		dispatchable[signer] = []string{txID.String()}
+		testOc.incompleteTxSProcessMap[txID.String()] = tf
+		if signer == "signer1" {
+			tf.On("PrepareTransaction", mock.Anything, mock.Anything).Return(nil, errors.New("pop"))
+			continue
+		}
+		tf.On("PrepareTransaction", mock.Anything, mock.Anything).Return(&components.PrivateTransaction{
+			ID: txID,
+			Inputs: &components.TransactionInputs{
+				Domain: "domain1",
+				To:     testOc.contractAddress,
+				Intent: prototk.TransactionSpecification_PREPARE_TRANSACTION,
+			},
+			PostAssembly:               &components.TransactionPostAssembly{},
+			PreparedPrivateTransaction: &pldapi.TransactionInput{},
+		}, nil)
+		tf.On("GetStateDistributions", mock.Anything).Return(&components.StateDistributionSet{}, nil)
+	}
+
+	// The nonces allocated for the signing address that succeeded are returned
+	err := testOc.DispatchTransactions(ctx, dispatchable)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDispatchTransactionsFlushFailDiscardsAndFails(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	publicTxMgr := componentmocks.NewPublicTxManager(t)
+	pubBatch := componentmocks.NewPublicTxBatch(t)
+	dependencyMocks.allComponents.On("PublicTxManager").Return(publicTxMgr)
+	dependencyMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{}).Return([]*tktypes.EthAddress{}, nil)
+	publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(pubBatch, nil).Once()
+	pubBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	pubBatch.On("Completed", mock.Anything, false).Return().Once()
+	dependencyMocks.stateDistributer.On("BuildNullifiers", mock.Anything, mock.Anything).Return(nil, nil)
+	dependencyMocks.domainContext.On("Ctx").Return(ctx)
+	dependencyMocks.domainContext.On("Info").Return(components.DomainContextInfo{ID: uuid.New()})
+	dependencyMocks.domainContext.On("Flush", mock.Anything).Return(nil, errors.New("pop"))
+
+	txID := uuid.New()
+	dependantID := uuid.New()
+	dependencyMocks.domainContext.On("DiscardTransactions", txID, dependantID).Return().Once()
+	tf := privatetxnmgrmocks.NewTransactionFlow(t)
+	tf.On("PrepareTransaction", mock.Anything, mock.Anything).Return(&components.PrivateTransaction{
+		ID: txID,
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     testOc.contractAddress,
+			Intent: prototk.TransactionSpecification_PREPARE_TRANSACTION,
+		},
+		PostAssembly:               &components.TransactionPostAssembly{},
+		PreparedPrivateTransaction: &pldapi.TransactionInput{},
+	}, nil)
+	tf.On("GetStateDistributions", mock.Anything).Return(&components.StateDistributionSet{}, nil)
+	tf.On("ID").Return(txID)
+	tf.On("ApplyEvent", mock.Anything, mock.MatchedBy(func(event *ptmgrtypes.TransactionDispatchFailedEvent) bool {
+		return event.TransactionID == txID.String() && event.Error == "pop"
+	})).Return().Once()
+	tf.On("Action", mock.Anything).Return().Once()
+	tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID.String()})
+	tf.On("OutputStateIDs").Return([]string{"S1"})
+	testOc.incompleteTxSProcessMap[txID.String()] = tf
+
+	// A transaction that spends a state minted by the one in the batch, and one that does not
+	dependant := privatetxnmgrmocks.NewTransactionFlow(t)
+	dependant.On("ID").Return(dependantID)
+	dependant.On("InputStateIDs").Return([]string{"S1"})
+	dependant.On("OutputStateIDs").Return([]string{"S2"})
+	dependant.On("ApplyEvent", mock.Anything, mock.MatchedBy(func(event *ptmgrtypes.TransactionDispatchFailedEvent) bool {
+		return event.TransactionID == dependantID.String() && event.Error == "pop"
+	})).Return().Once()
+	dependant.On("Action", mock.Anything).Return().Once()
+	dependant.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: dependantID.String()})
+	testOc.incompleteTxSProcessMap[dependantID.String()] = dependant
+	unrelatedID := uuid.New()
+	unrelated := privatetxnmgrmocks.NewTransactionFlow(t)
+	unrelated.On("ID").Return(unrelatedID)
+	unrelated.On("InputStateIDs").Return([]string{"S0"})
+	testOc.incompleteTxSProcessMap[unrelatedID.String()] = unrelated
+
+	// The flush of the domain context failed, so the states of the transaction and its dependant are discarded
+	// from the context, and both are told the dispatch failed
+	err := testOc.DispatchTransactions(ctx, ptmgrtypes.DispatchableTransactions{"signer0": {txID.String()}})
+	assert.Regexp(t, "pop", err)
+	assert.False(t, testOc.graph.IncludesTransaction(txID.String()))
+}
+
+func TestDispatchTransactionsSpreadAcrossSubmitSigners(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, dependencyMocks, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.submitSigners = []string{"submitter0", "submitter1", "submitter2"}
+
+	var lock sync.Mutex
+	prepared := make(map[string][]string)
+	txIDs := make([]string, 9)
+	for i := range txIDs {
+		txID := uuid.New()
+		txIDs[i] = txID.String()
+		// the first three transactions are a chain, each spending the output of the one before
+		inputStateIDs := []string{}
+		if i == 1 || i == 2 {
+			inputStateIDs = []string{fmt.Sprintf("state%d", i-1)}
+		}
+		tf := privatetxnmgrmocks.NewTransactionFlow(t)
+		tf.On("InputStateIDs").Return(inputStateIDs)
+		tf.On("OutputStateIDs").Return([]string{fmt.Sprintf("state%d", i)})
+		tf.On("GetTxAttestations", mock.Anything).Return(&components.PrivateTxAttestations{TxID: txID.String()})
+		tf.On("PrepareTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			lock.Lock()
+			defer lock.Unlock()
+			signer := args[1].(string)
+			prepared[signer] = append(prepared[signer], txID.String())
+		}).Return(&components.PrivateTransaction{
+			ID: txID,
+			Inputs: &components.TransactionInputs{
+				Domain: "domain1",
+				To:     testOc.contractAddress,
+				Intent: prototk.TransactionSpecification_PREPARE_TRANSACTION,
+			},
+			PostAssembly:               &components.TransactionPostAssembly{},
+			PreparedPrivateTransaction: &pldapi.TransactionInput{},
+		}, nil)
+		tf.On("GetStateDistributions", mock.Anything).Return(&components.StateDistributionSet{}, nil)
+		testOc.incompleteTxSProcessMap[txID.String()] = tf
+	}
+
+	publicTxMgr := componentmocks.NewPublicTxManager(t)
+	pubBatch := componentmocks.NewPublicTxBatch(t)
+	dependencyMocks.allComponents.On("PublicTxManager").Return(publicTxMgr)
+	dependencyMocks.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{}).Return([]*tktypes.EthAddress{}, nil)
+	publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, []*components.PublicTxSubmission{}).Return(pubBatch, nil).Times(3)
+	pubBatch.On("Rejected").Return([]components.PublicTxRejected{})
+	dependencyMocks.stateDistributer.On("BuildNullifiers", mock.Anything, mock.Anything).Return(nil, errors.New("pop"))
+	pubBatch.On("Completed", mock.Anything, false).Return().Times(3)
+
+	err := testOc.DispatchTransactions(ctx, ptmgrtypes.DispatchableTransactions{"": txIDs})
+	assert.Regexp(t, "pop", err)
+
+	// the chain stays on one signer, and the rest are shared round-robin across the pool
+	assert.Equal(t, map[string][]string{
+		"submitter0": {txIDs[0], txIDs[1], txIDs[2], txIDs[5], txIDs[8]},
+		"submitter1": {txIDs[3], txIDs[6]},
+		"submitter2": {txIDs[4], txIDs[7]},
+	}, prepared)
+
+	// and the rotation carries on from where it left off on the next dispatch
+	assert.Equal(t, "submitter1", testOc.nextSubmitSigner(nil))
+}
+
+func TestAllocateSubmitSignersLeastLoaded(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, _, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.submitSigners = []string{"submitter0", "submitter1", "submitter2"}
+	testOc.submitSignerSelection = pldconf.SubmitSignerSelectionLeastLoaded
+
+	// submitter0 has two transactions awaiting confirmation, and submitter1 has one, so the new transactions
+	// bring the others level with submitter0
+	for _, signer := range []string{"submitter0", "submitter0", "submitter1"} {
+		testOc.dispatchedTxs[uuid.NewString()] = &dispatchedTransaction{signer: signer}
+	}
+
+	txIDs := make([]string, 3)
+	for i := range txIDs {
+		txIDs[i] = uuid.NewString()
+		tf := privatetxnmgrmocks.NewTransactionFlow(t)
+		tf.On("InputStateIDs").Return([]string{})
+		tf.On("OutputStateIDs").Return([]string{})
+		testOc.incompleteTxSProcessMap[txIDs[i]] = tf
+	}
+
+	allocated := testOc.allocateSubmitSigners(ctx, ptmgrtypes.DispatchableTransactions{
+		"":            txIDs,
+		"endorser@me": {"domainSigned"},
+	})
+	assert.Equal(t, ptmgrtypes.DispatchableTransactions{
+		"submitter1":  {txIDs[1]},
+		"submitter2":  {txIDs[0], txIDs[2]},
+		"endorser@me": {"domainSigned"},
+	}, allocated)
+}
+
+func TestAllocateSubmitSignersPinsToEarlierDispatch(t *testing.T) {
+	ctx := context.Background()
+
+	testOc, _, _ := newSequencerForTesting(t, ctx, nil)
+	testOc.submitSigners = []string{"submitter0", "submitter1", "submitter2"}
+
+	// a transaction dispatched in an earlier batch on submitter2 is not yet finalized
+	mintedState := tktypes.RandHex(32)
+	testOc.dispatchedTxs[uuid.NewString()] = &dispatchedTransaction{signer: "submitter2", outputStateIDs: []string{mintedState}}
+
+	spender := uuid.NewString()
+	tf := privatetxnmgrmocks.NewTransactionFlow(t)
+	tf.On("InputStateIDs").Return([]string{mintedState})
+	tf.On("OutputStateIDs").Return([]string{})
+	testOc.incompleteTxSProcessMap[spender] = tf
+
+	other := uuid.NewString()
+	tf = privatetxnmgrmocks.NewTransactionFlow(t)
+	tf.On("InputStateIDs").Return([]string{tktypes.RandHex(32)})
+	tf.On("OutputStateIDs").Return([]string{})
+	testOc.incompleteTxSProcessMap[other] = tf
+
+	allocated := testOc.allocateSubmitSigners(ctx, ptmgrtypes.DispatchableTransactions{"": {spender, other}})
+	assert.Equal(t, ptmgrtypes.DispatchableTransactions{
+		"submitter2": {spender},
+		"submitter0": {other},
+	}, allocated)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+)
+
+// Each subscriber has its own bounded buffer of events, delivered in order on its own routine,
+// so that a slow subscriber cannot hold up the publisher (or other subscribers) indefinitely.
+type eventSubscriber struct {
+	deliver components.PrivateTxEventSubscriber
+	events  chan components.PrivateTxEvent
+	done    chan struct{}
+}
+
+func newEventSubscriber(deliver components.PrivateTxEventSubscriber, bufferSize int) *eventSubscriber {
+	es := &eventSubscriber{
+		deliver: deliver,
+		events:  make(chan components.PrivateTxEvent, bufferSize),
+		done:    make(chan struct{}),
+	}
+	go es.run()
+	return es
+}
+
+func (es *eventSubscriber) run() {
+	defer close(es.done)
+	for event := range es.events {
+		es.deliver(event)
+	}
+}
+
+// Must be called with the subscribers lock held, so there is only ever one publisher.
+// Returns true if the subscriber should be disconnected.
+func (es *eventSubscriber) publish(ctx context.Context, event components.PrivateTxEvent, policy pldconf.SubscriberOverflowPolicy) (disconnect bool) {
+	switch policy {
+	case pldconf.SubscriberOverflowPolicyDropOldest:
+		for {
+			select {
+			case es.events <- event:
+				return false
+			default:
+			}
+			// The subscriber might have consumed an event in the meantime, so we do not block here
+			select {
+			case dropped := <-es.events:
+				log.L(ctx).Warnf("Subscriber buffer full: dropped event %T", dropped)
+			default:
+			}
+		}
+	case pldconf.SubscriberOverflowPolicyDisconnect:
+		select {
+		case es.events <- event:
+			return false
+		default:
+			log.L(ctx).Warnf("Subscriber buffer full: disconnecting subscriber")
+			return true
+		}
+	default:
+		select {
+		case es.events <- event:
+		case <-ctx.Done():
+			log.L(ctx).Warnf("Context cancelled waiting for subscriber buffer space: dropped event %T", event)
+		}
+		return false
+	}
+}
+
+// Any events already buffered are still delivered
+func (es *eventSubscriber) close() {
+	close(es.events)
+}
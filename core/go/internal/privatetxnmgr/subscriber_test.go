@@ -0,0 +1,160 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package privatetxnmgr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowSubscriber struct {
+	received chan string
+	release  chan struct{}
+}
+
+// Each event is reported as soon as it is received, but the subscriber then waits to be released before returning
+func newSlowSubscriber(ctx context.Context, p *privateTxManager, policy pldconf.SubscriberOverflowPolicy, bufferSize int) *slowSubscriber {
+	p.subscriberOverflowPolicy = policy
+	p.subscriberBufferSize = bufferSize
+	s := &slowSubscriber{
+		received: make(chan string, 100),
+		release:  make(chan struct{}),
+	}
+	p.Subscribe(ctx, func(event components.PrivateTxEvent) {
+		s.received <- event.(*components.TransactionConfirmedEvent).TransactionID
+		<-s.release
+	})
+	return s
+}
+
+func publishConfirmedEvents(ctx context.Context, p *privateTxManager, ids ...string) {
+	for _, id := range ids {
+		p.publishToSubscribers(ctx, &components.TransactionConfirmedEvent{TransactionID: id})
+	}
+}
+
+func (s *slowSubscriber) receivedAfterRelease(t *testing.T, count int) []string {
+	close(s.release)
+	ids := make([]string, count)
+	for i := range ids {
+		select {
+		case ids[i] = <-s.received:
+		case <-time.After(timeTillDeadline(t)):
+			require.FailNow(t, fmt.Sprintf("timed out waiting for event %d", i))
+		}
+	}
+	return ids
+}
+
+func TestSubscriberOverflowDropOldest(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	s := newSlowSubscriber(ctx, p, pldconf.SubscriberOverflowPolicyDropOldest, 2)
+
+	// The first event is held by the subscriber, so does not take space in the buffer
+	publishConfirmedEvents(ctx, p, "tx0")
+	assert.Equal(t, "tx0", <-s.received)
+
+	publishConfirmedEvents(ctx, p, "tx1", "tx2", "tx3", "tx4")
+	assert.Len(t, p.subscribers, 1)
+
+	assert.Equal(t, []string{"tx3", "tx4"}, s.receivedAfterRelease(t, 2))
+}
+
+func TestSubscriberOverflowDisconnect(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	s := newSlowSubscriber(ctx, p, pldconf.SubscriberOverflowPolicyDisconnect, 2)
+	disconnected := p.subscribers[0]
+
+	publishConfirmedEvents(ctx, p, "tx0")
+	assert.Equal(t, "tx0", <-s.received)
+
+	publishConfirmedEvents(ctx, p, "tx1", "tx2", "tx3", "tx4")
+	assert.Empty(t, p.subscribers)
+
+	// Events buffered before the overflow are still delivered, and then the subscriber ends
+	assert.Equal(t, []string{"tx1", "tx2"}, s.receivedAfterRelease(t, 2))
+	<-disconnected.done
+	assert.Empty(t, s.received)
+}
+
+func TestSubscriberOverflowBlock(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	s := newSlowSubscriber(ctx, p, pldconf.SubscriberOverflowPolicyBlock, 2)
+
+	publishConfirmedEvents(ctx, p, "tx0")
+	assert.Equal(t, "tx0", <-s.received)
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		publishConfirmedEvents(ctx, p, "tx1", "tx2", "tx3")
+	}()
+	select {
+	case <-published:
+		assert.Fail(t, "publisher did not block on a full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, []string{"tx1", "tx2", "tx3"}, s.receivedAfterRelease(t, 3))
+	<-published
+}
+
+func TestSubscriberOverflowBlockContextCancelled(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	s := newSlowSubscriber(ctx, p, pldconf.SubscriberOverflowPolicyBlock, 1)
+
+	publishConfirmedEvents(ctx, p, "tx0")
+	assert.Equal(t, "tx0", <-s.received)
+	publishConfirmedEvents(ctx, p, "tx1")
+
+	cancelCtx()
+	publishConfirmedEvents(ctx, p, "tx2")
+
+	assert.Equal(t, []string{"tx1"}, s.receivedAfterRelease(t, 1))
+}
+
+func TestSubscribersClosedOnStop(t *testing.T) {
+	ctx := context.Background()
+	p, _ := NewPrivateTransactionMgrForTesting(t, "node1")
+	p.Subscribe(ctx, func(event components.PrivateTxEvent) {})
+	subscriber := p.subscribers[0]
+
+	p.Stop()
+	<-subscriber.done
+	assert.Empty(t, p.subscribers)
+}
+
+func TestInvalidSubscriberOverflowPolicy(t *testing.T) {
+	p := NewPrivateTransactionMgr(context.Background(), &pldconf.PrivateTxManagerConfig{
+		SubscriberBuffer: pldconf.SubscriberBufferConfig{
+			OverflowPolicy: confutil.P("wrong"),
+		},
+	})
+	err := p.PostInit(nil)
+	assert.Regexp(t, "PD011844.*wrong", err)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package syncpoints
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type transactionAttestationsPersisted struct {
+	Transaction     uuid.UUID          `gorm:"column:transaction"`
+	ContractAddress tktypes.EthAddress `gorm:"column:contract_address"`
+	Attestations    tktypes.RawJSON    `gorm:"column:attestations"`
+	Created         tktypes.Timestamp  `gorm:"column:created"`
+}
+
+// The attestations are written with the dispatch, so they can still be inspected once the transaction has been
+// finalized, or the node has restarted. A transaction that is re-assembled and dispatched again replaces them.
+func writeAttestations(ctx context.Context, dbTX *gorm.DB, contractAddress tktypes.EthAddress, attestations []*components.PrivateTxAttestations) error {
+	now := tktypes.TimestampNow()
+	persisted := make([]*transactionAttestationsPersisted, len(attestations))
+	for i, a := range attestations {
+		txID, err := uuid.Parse(a.TxID)
+		if err != nil {
+			return err
+		}
+		persisted[i] = &transactionAttestationsPersisted{
+			Transaction:     txID,
+			ContractAddress: contractAddress,
+			Attestations:    tktypes.JSONString(a.Attestations),
+			Created:         now,
+		}
+	}
+	log.L(ctx).Debugf("Writing attestations for %d transactions", len(persisted))
+	err := dbTX.
+		WithContext(ctx).
+		Table("transaction_attestations").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "transaction"}},
+			DoUpdates: clause.AssignmentColumns([]string{"contract_address", "attestations", "created"}),
+		}).
+		Create(persisted).
+		Error
+	if err != nil {
+		log.L(ctx).Errorf("Error persisting attestations: %s", err)
+	}
+	return err
+}
+
+func (s *syncPoints) GetTransactionAttestations(ctx context.Context, contractAddress tktypes.EthAddress, transactionID uuid.UUID) (*components.PrivateTxAttestations, error) {
+	var persisted []*transactionAttestationsPersisted
+	err := s.p.DB().
+		WithContext(ctx).
+		Table("transaction_attestations").
+		Where(`"transaction" = ?`, transactionID).
+		Where("contract_address = ?", contractAddress).
+		Limit(1).
+		Find(&persisted).
+		Error
+	if err != nil || len(persisted) == 0 {
+		return nil, err
+	}
+	attestations := &components.PrivateTxAttestations{
+		TxID: transactionID.String(),
+	}
+	if err := json.Unmarshal(persisted[0].Attestations, &attestations.Attestations); err != nil {
+		return nil, err
+	}
+	return attestations, nil
+}
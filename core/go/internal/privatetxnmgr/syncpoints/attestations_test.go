@@ -0,0 +1,121 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package syncpoints
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAttestations(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testContractAddress := tktypes.RandAddress()
+	testTxnID := uuid.New()
+	m.persistence.Mock.ExpectExec("INSERT.*transaction_attestations").WithArgs(
+		testTxnID, *testContractAddress, sqlmock.AnyArg(), sqlmock.AnyArg(),
+	).WillReturnResult(driver.ResultNoRows)
+
+	_, _, err := s.runBatch(ctx, m.persistence.P.DB(), []*syncPointOperation{{
+		contractAddress: *testContractAddress,
+		dispatchOperation: &dispatchOperation{
+			contractAddress: *testContractAddress,
+			attestations: []*components.PrivateTxAttestations{{
+				TxID:         testTxnID.String(),
+				Attestations: []*components.PrivateTxAttestation{{Name: "notary"}},
+			}},
+		},
+	}})
+	require.NoError(t, err)
+	assert.NoError(t, m.persistence.Mock.ExpectationsWereMet())
+}
+
+func TestWriteAttestationsBadTxID(t *testing.T) {
+	ctx := context.Background()
+
+	_, m := newSyncPointsForTesting(t)
+	err := writeAttestations(ctx, m.persistence.P.DB(), *tktypes.RandAddress(), []*components.PrivateTxAttestations{{TxID: "wrong"}})
+	assert.Regexp(t, "invalid UUID", err)
+}
+
+func TestWriteAttestationsFail(t *testing.T) {
+	ctx := context.Background()
+
+	_, m := newSyncPointsForTesting(t)
+	m.persistence.Mock.ExpectExec("INSERT.*transaction_attestations").WillReturnError(fmt.Errorf("pop"))
+
+	err := writeAttestations(ctx, m.persistence.P.DB(), *tktypes.RandAddress(), []*components.PrivateTxAttestations{{TxID: uuid.NewString()}})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetTransactionAttestations(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testContractAddress := tktypes.RandAddress()
+	testTxnID := uuid.New()
+	m.persistence.Mock.ExpectQuery("SELECT.*transaction_attestations").WithArgs(testTxnID, *testContractAddress, 1).WillReturnRows(
+		sqlmock.NewRows([]string{"transaction", "contract_address", "attestations"}).
+			AddRow(testTxnID, testContractAddress, `[{"name":"notary","parties":[{"party":"notary@node1","received":true}]}]`),
+	)
+	m.persistence.Mock.ExpectQuery("SELECT.*transaction_attestations").WillReturnRows(sqlmock.NewRows([]string{}))
+
+	attestations, err := s.GetTransactionAttestations(ctx, *testContractAddress, testTxnID)
+	require.NoError(t, err)
+	assert.Equal(t, &components.PrivateTxAttestations{
+		TxID: testTxnID.String(),
+		Attestations: []*components.PrivateTxAttestation{{
+			Name:    "notary",
+			Parties: []*components.PrivateTxAttestationParty{{Party: "notary@node1", Received: true}},
+		}},
+	}, attestations)
+
+	attestations, err = s.GetTransactionAttestations(ctx, *testContractAddress, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, attestations)
+}
+
+func TestGetTransactionAttestationsFail(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	m.persistence.Mock.ExpectQuery("SELECT.*transaction_attestations").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := s.GetTransactionAttestations(ctx, *tktypes.RandAddress(), uuid.New())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetTransactionAttestationsBadJSON(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	m.persistence.Mock.ExpectQuery("SELECT.*transaction_attestations").WillReturnRows(
+		sqlmock.NewRows([]string{"attestations"}).AddRow(`{!!!`),
+	)
+
+	_, err := s.GetTransactionAttestations(ctx, *tktypes.RandAddress(), uuid.New())
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncpoints
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/core/internal/flushwriter"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// A SequencerDependency records that one transaction in a sequencer spends a state minted by another,
+// so must not be dispatched before it
+type SequencerDependency struct {
+	Dependant  uuid.UUID
+	Dependency uuid.UUID
+	StateID    string
+}
+
+type sequencerDependencyPersisted struct {
+	ContractAddress tktypes.EthAddress `gorm:"column:contract_address"`
+	Dependant       uuid.UUID          `gorm:"column:dependant"`
+	Dependency      uuid.UUID          `gorm:"column:dependency"`
+	StateID         string             `gorm:"column:state_id"`
+	Created         tktypes.Timestamp  `gorm:"column:created"`
+}
+
+// a dependency operation either records the dependencies of a transaction that has entered the sequencer,
+// or removes all dependencies involving a transaction that has left the sequencer without being dispatched.
+// Dependencies of dispatched transactions are removed in the same database transaction as the dispatch.
+type dependencyOperation struct {
+	dependencies  []*sequencerDependencyPersisted
+	transactionID *uuid.UUID
+}
+
+// A DependencyFlush reports the outcome of writing a queued change to the recorded dependencies
+type DependencyFlush interface {
+	// Flushed returns whether the change has been written, or has failed to be written, without blocking
+	Flushed() (done bool, err error)
+}
+
+type dependencyFlush struct {
+	op   flushwriter.Operation[*syncPointOperation, *noResult]
+	done bool
+	err  error
+}
+
+func (f *dependencyFlush) Flushed() (bool, error) {
+	if !f.done {
+		select {
+		case r := <-f.op.Flushed():
+			f.done, f.err = true, r.Err
+		default:
+		}
+	}
+	return f.done, f.err
+}
+
+func (s *syncPoints) QueueDependencies(ctx context.Context, contractAddress tktypes.EthAddress, dependencies []*SequencerDependency) DependencyFlush {
+	now := tktypes.TimestampNow()
+	persisted := make([]*sequencerDependencyPersisted, len(dependencies))
+	for i, d := range dependencies {
+		persisted[i] = &sequencerDependencyPersisted{
+			ContractAddress: contractAddress,
+			Dependant:       d.Dependant,
+			Dependency:      d.Dependency,
+			StateID:         d.StateID,
+			Created:         now,
+		}
+	}
+	return s.queueDependencyOperation(ctx, contractAddress, &dependencyOperation{dependencies: persisted})
+}
+
+func (s *syncPoints) QueueDependencyRemoval(ctx context.Context, contractAddress tktypes.EthAddress, transactionID uuid.UUID) DependencyFlush {
+	return s.queueDependencyOperation(ctx, contractAddress, &dependencyOperation{transactionID: &transactionID})
+}
+
+func (s *syncPoints) queueDependencyOperation(ctx context.Context, contractAddress tktypes.EthAddress, dependencyOperation *dependencyOperation) DependencyFlush {
+	return &dependencyFlush{
+		op: s.writer.Queue(ctx, &syncPointOperation{
+			domainContext:       nil, // dependencies refer only to transaction IDs and state IDs, so no states need to be flushed
+			contractAddress:     contractAddress,
+			dependencyOperation: dependencyOperation,
+		}),
+	}
+}
+
+// Dependencies are read a page at a time, so that a large backlog is not read in a single query
+var loadDependenciesPageSize = 100
+
+func (s *syncPoints) LoadDependencies(ctx context.Context, contractAddress tktypes.EthAddress) ([]*SequencerDependency, error) {
+	var persisted []*sequencerDependencyPersisted
+	for {
+		q := s.p.DB().
+			WithContext(ctx).
+			Table("sequencer_dependencies").
+			Where("contract_address = ?", contractAddress)
+		if len(persisted) > 0 {
+			last := persisted[len(persisted)-1]
+			q = q.Where("dependant > ? OR (dependant = ? AND (dependency > ? OR (dependency = ? AND state_id > ?)))",
+				last.Dependant, last.Dependant, last.Dependency, last.Dependency, last.StateID)
+		}
+		var page []*sequencerDependencyPersisted
+		err := q.Order("dependant").
+			Order("dependency").
+			Order("state_id").
+			Limit(loadDependenciesPageSize).
+			Find(&page).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		persisted = append(persisted, page...)
+		if len(page) < loadDependenciesPageSize {
+			break
+		}
+	}
+	dependencies := make([]*SequencerDependency, len(persisted))
+	for i, d := range persisted {
+		dependencies[i] = &SequencerDependency{
+			Dependant:  d.Dependant,
+			Dependency: d.Dependency,
+			StateID:    d.StateID,
+		}
+	}
+	return dependencies, nil
+}
+
+func (s *syncPoints) writeDependencyOperations(ctx context.Context, dbTX *gorm.DB, dependencyOperations []*dependencyOperation) error {
+
+	// Operations are applied in the order they were queued, as a transaction can leave and re-enter the sequencer
+	for _, op := range dependencyOperations {
+		if op.transactionID != nil {
+			if err := deleteDependencies(ctx, dbTX, []uuid.UUID{*op.transactionID}); err != nil {
+				return err
+			}
+		}
+		if len(op.dependencies) > 0 {
+			log.L(ctx).Debugf("Writing sequencer dependencies %d", len(op.dependencies))
+			err := dbTX.
+				Table("sequencer_dependencies").
+				Clauses(clause.OnConflict{
+					Columns: []clause.Column{
+						{Name: "dependant"},
+						{Name: "dependency"},
+						{Name: "state_id"},
+					},
+					DoNothing: true, // immutable
+				}).
+				Create(op.dependencies).
+				Error
+			if err != nil {
+				log.L(ctx).Errorf("Error persisting sequencer dependencies: %s", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func deleteDependencies(ctx context.Context, dbTX *gorm.DB, transactionIDs []uuid.UUID) error {
+	err := dbTX.
+		Table("sequencer_dependencies").
+		Where("dependant IN (?) OR dependency IN (?)", transactionIDs, transactionIDs).
+		Delete(&sequencerDependencyPersisted{}).
+		Error
+	if err != nil {
+		log.L(ctx).Errorf("Error removing sequencer dependencies: %s", err)
+	}
+	return err
+}
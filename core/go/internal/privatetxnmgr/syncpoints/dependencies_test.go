@@ -0,0 +1,163 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncpoints
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDependencyOperations(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testContractAddress := tktypes.RandAddress()
+	testTxnID0 := uuid.New()
+	testTxnID1 := uuid.New()
+	testTxnID2 := uuid.New()
+	testSyncPointOperations := []*syncPointOperation{
+		{
+			contractAddress: *testContractAddress,
+			dependencyOperation: &dependencyOperation{
+				transactionID: &testTxnID1,
+			},
+		},
+		{
+			contractAddress: *testContractAddress,
+			dependencyOperation: &dependencyOperation{
+				dependencies: []*sequencerDependencyPersisted{
+					{ContractAddress: *testContractAddress, Dependant: testTxnID1, Dependency: testTxnID0, StateID: "S0"},
+					{ContractAddress: *testContractAddress, Dependant: testTxnID2, Dependency: testTxnID1, StateID: "S1"},
+				},
+			},
+		},
+		{
+			contractAddress: *testContractAddress,
+			dispatchOperation: &dispatchOperation{
+				coordinator:             "node1",
+				coordinatedTransactions: []uuid.UUID{testTxnID0},
+			},
+		},
+	}
+	dbTX := m.persistence.P.DB()
+	// the removal and the insert are applied in the order they were queued, then the dispatch removes the dependencies of
+	// the dispatched transactions
+	m.persistence.Mock.ExpectExec("DELETE.*sequencer_dependencies").WithArgs(testTxnID1, testTxnID1).WillReturnResult(driver.ResultNoRows)
+	m.persistence.Mock.ExpectExec("INSERT.*sequencer_dependencies").WithArgs(
+		*testContractAddress, testTxnID1, testTxnID0, "S0", sqlmock.AnyArg(),
+		*testContractAddress, testTxnID2, testTxnID1, "S1", sqlmock.AnyArg(),
+	).WillReturnResult(driver.ResultNoRows)
+	m.txMgr.On("SetTransactionCoordinator", mock.Anything, mock.Anything, "node1", []uuid.UUID{testTxnID0}).Return(nil)
+	m.persistence.Mock.ExpectExec("DELETE.*sequencer_dependencies").WithArgs(testTxnID0, testTxnID0).WillReturnResult(driver.ResultNoRows)
+
+	dbResultCB, res, err := s.runBatch(ctx, dbTX, testSyncPointOperations)
+	assert.NoError(t, err)
+	require.Len(t, res, 3)
+	assert.NoError(t, m.persistence.Mock.ExpectationsWereMet())
+	dbResultCB(nil)
+}
+
+func TestWriteDependencyOperationsFail(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testTxnID := uuid.New()
+	dbTX := m.persistence.P.DB()
+
+	m.persistence.Mock.ExpectExec("DELETE.*sequencer_dependencies").WillReturnError(fmt.Errorf("pop"))
+	_, _, err := s.runBatch(ctx, dbTX, []*syncPointOperation{
+		{dependencyOperation: &dependencyOperation{transactionID: &testTxnID}},
+	})
+	assert.Regexp(t, "pop", err)
+
+	m.persistence.Mock.ExpectExec("INSERT.*sequencer_dependencies").WillReturnError(fmt.Errorf("pop"))
+	_, _, err = s.runBatch(ctx, dbTX, []*syncPointOperation{
+		{dependencyOperation: &dependencyOperation{dependencies: []*sequencerDependencyPersisted{
+			{Dependant: testTxnID, Dependency: uuid.New(), StateID: "S0"},
+		}}},
+	})
+	assert.Regexp(t, "pop", err)
+
+	m.persistence.Mock.ExpectExec("DELETE.*sequencer_dependencies").WillReturnError(fmt.Errorf("pop"))
+	_, _, err = s.runBatch(ctx, dbTX, []*syncPointOperation{
+		{dispatchOperation: &dispatchOperation{coordinatedTransactions: []uuid.UUID{testTxnID}}},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestLoadDependencies(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testContractAddress := tktypes.RandAddress()
+	testTxnID0 := uuid.New()
+	testTxnID1 := uuid.New()
+
+	m.persistence.Mock.ExpectQuery("SELECT.*sequencer_dependencies").WithArgs(*testContractAddress, loadDependenciesPageSize).WillReturnRows(
+		sqlmock.NewRows([]string{"contract_address", "dependant", "dependency", "state_id", "created"}).
+			AddRow(testContractAddress.String(), testTxnID1.String(), testTxnID0.String(), "S0", 1000),
+	)
+	dependencies, err := s.LoadDependencies(ctx, *testContractAddress)
+	require.NoError(t, err)
+	assert.Equal(t, []*SequencerDependency{
+		{Dependant: testTxnID1, Dependency: testTxnID0, StateID: "S0"},
+	}, dependencies)
+
+	m.persistence.Mock.ExpectQuery("SELECT.*sequencer_dependencies").WillReturnError(fmt.Errorf("pop"))
+	_, err = s.LoadDependencies(ctx, *testContractAddress)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestLoadDependenciesPaged(t *testing.T) {
+	ctx := context.Background()
+
+	s, m := newSyncPointsForTesting(t)
+	testContractAddress := tktypes.RandAddress()
+	testTxnID0 := uuid.New()
+	testTxnID1 := uuid.New()
+
+	defer func() { loadDependenciesPageSize = 100 }()
+	loadDependenciesPageSize = 2
+
+	// the next page starts after the last row of the previous one, by primary key
+	m.persistence.Mock.ExpectQuery("SELECT.*sequencer_dependencies.*LIMIT").WithArgs(*testContractAddress, 2).WillReturnRows(
+		sqlmock.NewRows([]string{"contract_address", "dependant", "dependency", "state_id", "created"}).
+			AddRow(testContractAddress.String(), testTxnID1.String(), testTxnID0.String(), "S0", 1000).
+			AddRow(testContractAddress.String(), testTxnID1.String(), testTxnID0.String(), "S1", 1000),
+	)
+	m.persistence.Mock.ExpectQuery("SELECT.*sequencer_dependencies.*dependant >.*LIMIT").WithArgs(*testContractAddress, testTxnID1, testTxnID1, testTxnID0, testTxnID0, "S1", 2).WillReturnRows(
+		sqlmock.NewRows([]string{"contract_address", "dependant", "dependency", "state_id", "created"}).
+			AddRow(testContractAddress.String(), testTxnID1.String(), testTxnID0.String(), "S2", 1000),
+	)
+	dependencies, err := s.LoadDependencies(ctx, *testContractAddress)
+	require.NoError(t, err)
+	assert.Equal(t, []*SequencerDependency{
+		{Dependant: testTxnID1, Dependency: testTxnID0, StateID: "S0"},
+		{Dependant: testTxnID1, Dependency: testTxnID0, StateID: "S1"},
+		{Dependant: testTxnID1, Dependency: testTxnID0, StateID: "S2"},
+	}, dependencies)
+	require.NoError(t, m.persistence.Mock.ExpectationsWereMet())
+}
@@ -38,6 +38,10 @@ type dispatchOperation struct {
 	preparedTransactions     []*components.PrepareTransactionWithRefs
 	preparedTxnDistributions []*preparedtxdistribution.PreparedTxnDistributionPersisted
 	stateDistributions       []*statedistribution.StateDistributionPersisted
+	coordinator              string
+	coordinatedTransactions  []uuid.UUID
+	contractAddress          tktypes.EthAddress
+	attestations             []*components.PrivateTxAttestations
 }
 
 type DispatchPersisted struct {
@@ -59,6 +63,12 @@ type DispatchBatch struct {
 	PublicDispatches     []*PublicDispatch
 	PrivateDispatches    []*components.ValidatedTransaction
 	PreparedTransactions []*components.PrepareTransactionWithRefs
+	// The node coordinating the batch, which is recorded against each of the coordinated transactions
+	// that were submitted to this node
+	Coordinator             string
+	CoordinatedTransactions []uuid.UUID
+	// The attestations gathered for each of the transactions in the batch
+	Attestations []*components.PrivateTxAttestations
 }
 
 // PersistDispatches persists the dispatches to the database and coordinates with the public transaction manager
@@ -68,11 +78,12 @@ func (s *syncPoints) PersistDispatchBatch(dCtx components.DomainContext, contrac
 	stateDistributionsPersisted := make([]*statedistribution.StateDistributionPersisted, 0, len(stateDistributions))
 	for _, stateDistribution := range stateDistributions {
 		stateDistributionsPersisted = append(stateDistributionsPersisted, &statedistribution.StateDistributionPersisted{
-			ID:              stateDistribution.ID,
-			StateID:         tktypes.MustParseHexBytes(stateDistribution.StateID),
-			IdentityLocator: stateDistribution.IdentityLocator,
-			DomainName:      stateDistribution.Domain,
-			ContractAddress: *tktypes.MustEthAddress(stateDistribution.ContractAddress),
+			ID:                 stateDistribution.ID,
+			StateID:            tktypes.MustParseHexBytes(stateDistribution.StateID),
+			IdentityLocator:    stateDistribution.IdentityLocator,
+			DomainName:         stateDistribution.Domain,
+			ContractAddress:    *tktypes.MustEthAddress(stateDistribution.ContractAddress),
+			HeldForTransaction: stateDistribution.HeldForTransaction,
 		})
 	}
 
@@ -97,6 +108,10 @@ func (s *syncPoints) PersistDispatchBatch(dCtx components.DomainContext, contrac
 			preparedTransactions:     dispatchBatch.PreparedTransactions,
 			preparedTxnDistributions: preparedTxnDistributionsPersisted,
 			stateDistributions:       stateDistributionsPersisted,
+			coordinator:              dispatchBatch.Coordinator,
+			coordinatedTransactions:  dispatchBatch.CoordinatedTransactions,
+			contractAddress:          contractAddress,
+			attestations:             dispatchBatch.Attestations,
 		},
 	})
 
@@ -193,6 +208,27 @@ func (s *syncPoints) writeDispatchOperations(ctx context.Context, dbTX *gorm.DB,
 			}
 		}
 
+		if op.coordinator != "" && len(op.coordinatedTransactions) > 0 {
+			if err := s.txMgr.SetTransactionCoordinator(ctx, dbTX, op.coordinator, op.coordinatedTransactions); err != nil {
+				log.L(ctx).Errorf("Error recording transaction coordinator: %s", err)
+				return err
+			}
+		}
+
+		// Once dispatched, the ordering of the transactions is in the hands of the base ledger, so the sequencer
+		// dependencies are removed atomically with the dispatch
+		if len(op.coordinatedTransactions) > 0 {
+			if err := deleteDependencies(ctx, dbTX, op.coordinatedTransactions); err != nil {
+				return err
+			}
+		}
+
+		if len(op.attestations) > 0 {
+			if err := writeAttestations(ctx, dbTX, op.contractAddress, op.attestations); err != nil {
+				return err
+			}
+		}
+
 		if len(op.preparedTransactions) > 0 {
 			log.L(ctx).Debugf("Writing prepared transactions locally  %d", len(op.preparedTransactions))
 
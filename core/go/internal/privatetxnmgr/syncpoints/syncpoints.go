@@ -67,17 +67,33 @@ type SyncPoints interface {
 
 	// DelegateTransaction writes a record to the local database recording that we have received acknowledgement from the delegate node
 	QueueDelegationAck(dCtx components.DomainContext, contractAddress tktypes.EthAddress, delegationID uuid.UUID, onCommit func(context.Context), onRollback func(context.Context, error))
+
+	// QueueDependencies records the dependencies between transactions in the sequencer for the given contract, so that
+	// the sequencer can restore its dispatch ordering after a restart. They are removed when either transaction is dispatched.
+	// The returned flush reports the outcome of the write, without blocking the caller.
+	QueueDependencies(ctx context.Context, contractAddress tktypes.EthAddress, dependencies []*SequencerDependency) DependencyFlush
+
+	// QueueDependencyRemoval removes all the recorded dependencies involving a transaction that has left the sequencer without being dispatched
+	QueueDependencyRemoval(ctx context.Context, contractAddress tktypes.EthAddress, transactionID uuid.UUID) DependencyFlush
+
+	// LoadDependencies reads all the recorded dependencies for the sequencer of the given contract, a page at a time
+	LoadDependencies(ctx context.Context, contractAddress tktypes.EthAddress) ([]*SequencerDependency, error)
+
+	// GetTransactionAttestations reads the attestations persisted with the dispatch of a transaction, returning nil if there are none
+	GetTransactionAttestations(ctx context.Context, contractAddress tktypes.EthAddress, transactionID uuid.UUID) (*components.PrivateTxAttestations, error)
 	Close()
 }
 
 type syncPoints struct {
 	started bool
 	writer  flushwriter.Writer[*syncPointOperation, *noResult]
+	p       persistence.Persistence
 	txMgr   components.TXManager
 }
 
 func NewSyncPoints(ctx context.Context, conf *pldconf.FlushWriterConfig, p persistence.Persistence, txMgr components.TXManager) SyncPoints {
 	s := &syncPoints{
+		p:     p,
 		txMgr: txMgr,
 	}
 	s.writer = flushwriter.NewWriter(ctx, s.runBatch, p, conf, &WriterConfigDefaults)
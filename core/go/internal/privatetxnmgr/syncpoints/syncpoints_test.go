@@ -38,6 +38,7 @@ func newSyncPointsForTesting(t *testing.T) (*syncPoints, *dependencyMocks) {
 	}
 
 	return &syncPoints{
+		p:     p.P,
 		txMgr: mocks.txMgr,
 	}, mocks
 }
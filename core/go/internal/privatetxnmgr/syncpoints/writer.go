@@ -51,6 +51,7 @@ to atomically allocate and record the nonce under that same transaction.
 // or a delegate (intent to handover to a remote coordinator)
 // or receipt of an acknowledgement from a remote coordinator
 // or a receipt of a delegation from a remote assembler
+// or a change to the dependencies between transactions in the sequencer
 // but never more than one of these.  We probably could make the mutually exclusive nature more explicit by using interfaces but its not worth the added complexity
 
 type syncPointOperation struct {
@@ -60,6 +61,7 @@ type syncPointOperation struct {
 	dispatchOperation      *dispatchOperation
 	delegateOperation      *delegateOperation
 	delegationAckOperation *delegationAckOperation
+	dependencyOperation    *dependencyOperation
 }
 
 func (dso *syncPointOperation) WriteKey() string {
@@ -74,6 +76,7 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 	dispatchOperations := make([]*dispatchOperation, 0, len(values))
 	delegateOperations := make([]*delegateOperation, 0, len(values))
 	delegationAckOperations := make([]*delegationAckOperation, 0, len(values))
+	dependencyOperations := make([]*dependencyOperation, 0, len(values))
 	domainContextsToFlush := make(map[uuid.UUID]components.DomainContext)
 
 	for _, op := range values {
@@ -92,6 +95,9 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 		if op.delegationAckOperation != nil {
 			delegationAckOperations = append(delegationAckOperations, op.delegationAckOperation)
 		}
+		if op.dependencyOperation != nil {
+			dependencyOperations = append(dependencyOperations, op.dependencyOperation)
+		}
 	}
 
 	// We flush all of the affected domain contexts first, as they might contain states we need to refer
@@ -112,8 +118,8 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 			dbTXCallback(err)
 		}
 	}()
-	log.L(ctx).Infof("SyncPoints flush-writer: domain=contexts=%d finalizeOperations=%d dispatchOperations=%d delegateOperations=%d delegationAckOperations=%d",
-		len(domainContextsToFlush), len(finalizeOperations), len(dispatchOperations), len(delegateOperations), len(delegationAckOperations))
+	log.L(ctx).Infof("SyncPoints flush-writer: domain=contexts=%d finalizeOperations=%d dispatchOperations=%d delegateOperations=%d delegationAckOperations=%d dependencyOperations=%d",
+		len(domainContextsToFlush), len(finalizeOperations), len(dispatchOperations), len(delegateOperations), len(delegationAckOperations), len(dependencyOperations))
 	for _, dc := range domainContextsToFlush {
 		var domainCB func(error)
 		domainCB, err = dc.Flush(dbTX) // err variable must not be re-allocated
@@ -131,6 +137,12 @@ func (s *syncPoints) runBatch(ctx context.Context, dbTX *gorm.DB, values []*sync
 		err = s.writeFailureOperations(ctx, dbTX, finalizeOperations) // err variable must not be re-allocated
 	}
 
+	// Dependencies are written before dispatches, so that the dispatch of a transaction removes any
+	// dependencies recorded for it earlier in the same batch
+	if err == nil && len(dependencyOperations) > 0 {
+		err = s.writeDependencyOperations(ctx, dbTX, dependencyOperations) // err variable must not be re-allocated
+	}
+
 	if err == nil && len(dispatchOperations) > 0 {
 		err = s.writeDispatchOperations(ctx, dbTX, dispatchOperations) // err variable must not be re-allocated
 	}
@@ -17,10 +17,13 @@ package privatetxnmgr
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
@@ -28,9 +31,12 @@ import (
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
-func NewTransactionFlow(ctx context.Context, transaction *components.PrivateTransaction, nodeID string, components components.AllComponents, domainAPI components.DomainSmartContract, publisher ptmgrtypes.Publisher, endorsementGatherer ptmgrtypes.EndorsementGatherer, identityResolver components.IdentityResolver, syncPoints syncpoints.SyncPoints, transportWriter ptmgrtypes.TransportWriter, requestTimeout time.Duration) ptmgrtypes.TransactionFlow {
+func NewTransactionFlow(ctx context.Context, transaction *components.PrivateTransaction, nodeID string, components components.AllComponents, domainAPI components.DomainSmartContract, publisher ptmgrtypes.Publisher, endorsementGatherer ptmgrtypes.EndorsementGatherer, identityResolver components.IdentityResolver, syncPoints syncpoints.SyncPoints, transportWriter ptmgrtypes.TransportWriter, inputStateClaims ptmgrtypes.InputStateClaims, endorsementRequestTimeout time.Duration, endorsementMaxRetries int, signingTimeout time.Duration, signingHashThreshold int, contentionRetry *retry.Retry, maxRetries int, maxRetryDuration time.Duration, endorsementRevertPolicy pldconf.EndorsementRevertPolicy, flushFailurePolicy pldconf.FlushFailurePolicy) ptmgrtypes.TransactionFlow {
+	clock := ptmgrtypes.RealClock()
 	return &transactionFlow{
 		stageErrorRetry:             10 * time.Second,
 		domainAPI:                   domainAPI,
@@ -43,17 +49,28 @@ func NewTransactionFlow(ctx context.Context, transaction *components.PrivateTran
 		identityResolver:            identityResolver,
 		syncPoints:                  syncPoints,
 		transportWriter:             transportWriter,
+		inputStateClaims:            inputStateClaims,
 		finalizeRequired:            false,
 		finalizePending:             false,
 		requestedVerifierResolution: false,
 		requestedSignatures:         false,
 		requestedEndorsementTimes:   make(map[string]map[string]time.Time),
+		requestedEndorsementCounts:  make(map[string]map[string]int),
 		complete:                    false,
 		localCoordinator:            true,
 		readyForSequencing:          false,
 		dispatched:                  false,
-		clock:                       ptmgrtypes.RealClock(),
-		requestTimeout:              requestTimeout,
+		clock:                       clock,
+		endorsementRequestTimeout:   endorsementRequestTimeout,
+		endorsementMaxRetries:       endorsementMaxRetries,
+		signingTimeout:              signingTimeout,
+		signingHashThreshold:        signingHashThreshold,
+		contentionRetry:             contentionRetry,
+		maxRetries:                  maxRetries,
+		maxRetryDuration:            maxRetryDuration,
+		endorsementRevertPolicy:     endorsementRevertPolicy,
+		flushFailurePolicy:          flushFailurePolicy,
+		retryBudgetStart:            clock.Now(),
 	}
 }
 
@@ -71,6 +88,7 @@ type transactionFlow struct {
 	identityResolver            components.IdentityResolver
 	syncPoints                  syncpoints.SyncPoints
 	transportWriter             ptmgrtypes.TransportWriter
+	inputStateClaims            ptmgrtypes.InputStateClaims
 	finalizeRevertReason        string
 	finalizeRequired            bool
 	finalizePending             bool
@@ -78,22 +96,160 @@ type transactionFlow struct {
 	requestedVerifierResolution bool                            //TODO add precision here so that we can track individual requests and implement retry as per endorsement
 	requestedSignatures         bool                            //TODO add precision here so that we can track individual requests and implement retry as per endorsement
 	requestedEndorsementTimes   map[string]map[string]time.Time //map of attestationRequest names to a map of parties to the time the most request was made
+	requestedEndorsementCounts  map[string]map[string]int       //map of attestationRequest names to a map of parties to the number of times the request has been sent
 	localCoordinator            bool
 	readyForSequencing          bool
 	dispatched                  bool
+	delegated                   bool
+	assemblyValidated           bool            // true once the domain has validated (and built payloads for) the current assembly, reset on re-assembly
+	assemblyValidationID        string          // ID of the validation of the current assembly in flight with the domain, empty if there is none
+	dependenciesChecked         bool            // true once the dependencies have been checked for the current assembly, reset on re-assembly
+	inputStateConflictPending   bool            // true from finding an input state claimed by another transaction, until the conflict event triggers re-assembly
+	requestedAssemblyVerifiers  map[string]bool // lookup, algorithm and verifier type of the additional verifiers requested by the current assembly, reset on re-assembly
 	clock                       ptmgrtypes.Clock
-	requestTimeout              time.Duration
+	endorsementRequestTimeout   time.Duration
+	endorsementMaxRetries       int // re-sends of an unanswered endorsement request before the transaction is reverted (unlimited if zero)
+	signingTimeout              time.Duration
+	signingHashThreshold        int
+	contentionRetry             *retry.Retry
+	contentionWinner            string // set when we have lost a contention bid and coordination must move to the winning node
+	contentionDelegating        bool   // true from the first delegation request to the contention winner, until it is acknowledged or the retries are exhausted
+	contentionDelegationID      string // the same on every re-send, so the acknowledgement of any of them completes the delegation
+	contentionDelegationSent    time.Time
+	contentionDelegationCount   int           // delegation requests sent to the contention winner so far
+	contentionDelegationError   string        // why the latest delegation request could not be sent, if it failed
+	contentionDelegationAcked   bool          // set when the contention winner acknowledges the delegation
+	maxRetries                  int           // retries allowed across all stages before the transaction is failed (unlimited if zero)
+	maxRetryDuration            time.Duration // time after retryBudgetStart beyond which a retry fails the transaction (unlimited if zero)
+	retryBudgetStart            time.Time
+	endorsementRevertPolicy     pldconf.EndorsementRevertPolicy
+	flushFailurePolicy          pldconf.FlushFailurePolicy
+	retryCount                  int
+	stageTimings                []*stageTiming // every stage visited so far, in order, with the last one open until exitStage
+}
+
+type stageTiming struct {
+	stage   components.PrivateTxStage
+	entered time.Time
+	exited  *time.Time
 }
 
 func (tf *transactionFlow) GetTxStatus(ctx context.Context) (components.PrivateTxStatus, error) {
+	now := tf.clock.Now()
+	stageTimings := make([]*components.PrivateTxStageTiming, len(tf.stageTimings))
+	for i, st := range tf.stageTimings {
+		stageTimings[i] = &components.PrivateTxStageTiming{
+			Stage:   st.stage,
+			Entered: tktypes.Timestamp(st.entered.UnixNano()),
+		}
+		exited := now
+		if st.exited != nil {
+			exited = *st.exited
+			stageTimings[i].Exited = confutil.P(tktypes.Timestamp(exited.UnixNano()))
+		}
+		stageTimings[i].Duration = exited.Sub(st.entered).String()
+	}
+	label := ""
+	if tf.transaction.PreAssembly != nil {
+		label = tf.transaction.PreAssembly.Label
+	}
 	return components.PrivateTxStatus{
-		TxID:        tf.transaction.ID.String(),
-		Status:      tf.status,
-		LatestEvent: tf.latestEvent,
-		LatestError: tf.latestError,
+		TxID:         tf.transaction.ID.String(),
+		Status:       tf.status,
+		LatestEvent:  tf.latestEvent,
+		LatestError:  tf.latestError,
+		Label:        label,
+		StageTimings: stageTimings,
 	}, nil
 }
 
+func (tf *transactionFlow) GetTxAttestations(ctx context.Context) *components.PrivateTxAttestations {
+	attestations := &components.PrivateTxAttestations{
+		TxID:         tf.transaction.ID.String(),
+		Attestations: []*components.PrivateTxAttestation{},
+	}
+	postAssembly := tf.transaction.PostAssembly
+	if postAssembly == nil {
+		return attestations
+	}
+	for _, attRequest := range postAssembly.AttestationPlan {
+		results := postAssembly.Endorsements
+		if attRequest.AttestationType == prototk.AttestationType_SIGN {
+			results = postAssembly.Signatures
+		}
+		attestation := &components.PrivateTxAttestation{
+			Name:            attRequest.Name,
+			AttestationType: attRequest.AttestationType.String(),
+			Algorithm:       attRequest.Algorithm,
+			VerifierType:    attRequest.VerifierType,
+			Parties:         make([]*components.PrivateTxAttestationParty, len(attRequest.Parties)),
+		}
+		for i, party := range attRequest.Parties {
+			attParty := &components.PrivateTxAttestationParty{
+				Party:    party,
+				Verifier: tf.resolvedVerifier(party, attRequest.Algorithm, attRequest.VerifierType),
+			}
+			for _, result := range results {
+				if result.Name == attRequest.Name && result.Verifier != nil &&
+					result.Verifier.Lookup == party && result.Verifier.VerifierType == attRequest.VerifierType {
+					attParty.Received = true
+					attParty.Verifier = result.Verifier.Verifier
+					if attRequest.AttestationType == prototk.AttestationType_ENDORSE {
+						attParty.Result = prototk.EndorseTransactionResponse_SIGN.String()
+						if slices.Contains(result.Constraints, prototk.AttestationResult_ENDORSER_MUST_SUBMIT) {
+							attParty.Result = prototk.EndorseTransactionResponse_ENDORSER_SUBMIT.String()
+						}
+					}
+					break
+				}
+			}
+			attestation.Parties[i] = attParty
+		}
+		attestations.Attestations = append(attestations.Attestations, attestation)
+	}
+	return attestations
+}
+
+func (tf *transactionFlow) resolvedVerifier(lookup, algorithm, verifierType string) string {
+	if tf.transaction.PreAssembly == nil {
+		return ""
+	}
+	for _, rv := range tf.transaction.PreAssembly.Verifiers {
+		if rv.Lookup == lookup && rv.Algorithm == algorithm && rv.VerifierType == verifierType {
+			return rv.Verifier
+		}
+	}
+	return ""
+}
+
+// Actions are re-driven on every event, so entering the stage we are already in is a no-op
+func (tf *transactionFlow) enterStage(ctx context.Context, stage components.PrivateTxStage) {
+	if len(tf.stageTimings) > 0 {
+		current := tf.stageTimings[len(tf.stageTimings)-1]
+		if current.stage == stage && current.exited == nil {
+			return
+		}
+	}
+	tf.exitStage(ctx)
+	log.L(ctx).Debugf("Transaction %s entering stage %s", tf.transaction.ID.String(), stage)
+	tf.stageTimings = append(tf.stageTimings, &stageTiming{
+		stage:   stage,
+		entered: tf.clock.Now(),
+	})
+}
+
+func (tf *transactionFlow) exitStage(ctx context.Context) {
+	if len(tf.stageTimings) == 0 {
+		return
+	}
+	current := tf.stageTimings[len(tf.stageTimings)-1]
+	if current.exited == nil {
+		now := tf.clock.Now()
+		current.exited = &now
+		log.L(ctx).Debugf("Transaction %s exited stage %s after %s", tf.transaction.ID.String(), current.stage, now.Sub(current.entered))
+	}
+}
+
 func (tf *transactionFlow) IsComplete() bool {
 	return tf.complete
 }
@@ -114,10 +270,14 @@ func (tf *transactionFlow) CoordinatingLocally() bool {
 	return tf.localCoordinator
 }
 
+func (tf *transactionFlow) ContentionDelegating() bool {
+	return tf.contentionDelegating
+}
+
 func (tf *transactionFlow) PrepareTransaction(ctx context.Context, defaultSigner string) (*components.PrivateTransaction, error) {
 
 	if tf.transaction.Signer == "" {
-		log.L(ctx).Infof("Using random signing key from sequencer to prepare transaction: %s", defaultSigner)
+		log.L(ctx).Infof("Using signing key allocated by the sequencer to prepare transaction: %s", defaultSigner)
 		tf.transaction.Signer = defaultSigner
 	}
 
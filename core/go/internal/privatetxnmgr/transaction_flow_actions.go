@@ -17,16 +17,22 @@ package privatetxnmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"google.golang.org/protobuf/proto"
 )
 
 func (tf *transactionFlow) Action(ctx context.Context) {
@@ -53,6 +59,15 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 		return
 	}
 
+	if tf.delegated {
+		log.L(ctx).Infof("Transaction %s has been delegated", tf.transaction.ID.String())
+		return
+	}
+
+	if !tf.checkDependencies(ctx) {
+		return
+	}
+
 	if tf.transaction.PreAssembly == nil {
 		panic("PreAssembly is nil.")
 		//This should never happen unless there is a serious programming error or the memory has been corrupted
@@ -66,10 +81,12 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 		//if we have not sent a request, or if the request has timed out or been invalided by a re-assembly, then send the request
 		tf.requestVerifierResolution(ctx)
 		if tf.hasOutstandingVerifierRequests(ctx) {
+			tf.enterStage(ctx, components.PrivateTxStageResolving)
 			log.L(ctx).Infof("Transaction %s not ready to assemble. Waiting for verifiers to be resolved", tf.transaction.ID.String())
 			return
 		}
 
+		tf.enterStage(ctx, components.PrivateTxStageAssembling)
 		tf.requestAssemble(ctx)
 		if tf.transaction.PostAssembly == nil {
 			log.L(ctx).Infof("Transaction %s not assembled. Waiting for assembler to return", tf.transaction.ID.String())
@@ -81,6 +98,17 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 		}
 	}
 
+	// The domain might only have known it needed some verifiers once it had assembled the transaction
+	tf.requestAssemblyVerifierResolution(ctx)
+	if tf.hasOutstandingVerifierRequests(ctx) {
+		tf.enterStage(ctx, components.PrivateTxStageResolving)
+		log.L(ctx).Infof("Transaction %s not ready for endorsement. Waiting for verifiers requested during assembly to be resolved", tf.transaction.ID.String())
+		return
+	}
+
+	// Signatures are gathered alongside the endorsements, so are timed as part of the same stage
+	tf.enterStage(ctx, components.PrivateTxStageEndorsing)
+
 	// Must be signed on the same node as it was assembled so do this before considering whether to delegate
 	tf.requestSignatures(ctx)
 	if tf.hasOutstandingSignatureRequests() {
@@ -88,7 +116,16 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 	}
 	tf.status = "signed"
 
-	tf.delegateIfRequired(ctx)
+	if tf.contentionWinner != "" {
+		// we have lost a contention bid for one of our states so the only way forward is under the coordinator that won it
+		tf.delegateToContentionWinner(ctx)
+		return
+	}
+
+	if !tf.delegateIfRequired(ctx) {
+		log.L(ctx).Infof("Transaction %s cannot be coordinated on this node: %s", tf.transaction.ID.String(), tf.latestError)
+		return
+	}
 	if tf.status == "delegating" {
 		log.L(ctx).Infof("Transaction %s is delegating", tf.transaction.ID.String())
 		return
@@ -117,6 +154,20 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 			return
 		}
 	}
+	if !tf.assemblyValidated {
+		if !tf.checkInputStateClaims(ctx) {
+			return
+		}
+		if tf.assemblyValidationID == "" {
+			tf.requestAssemblyValidation(ctx)
+		}
+		log.L(ctx).Infof("Transaction %s not ready for endorsement. Waiting for the domain to validate the assembly", tf.transaction.ID.String())
+		return
+	}
+	if !tf.readyForSequencing && !tf.checkInputStateClaims(ctx) {
+		// another transaction became ready spending one of our input states while the assembly was being validated
+		return
+	}
 	log.L(ctx).Debugf("Transaction %s is ready (outputStatesPotential=%d outputStates=%d)",
 		tf.transaction.ID.String(), len(tf.transaction.PostAssembly.OutputStatesPotential), len(tf.transaction.PostAssembly.OutputStates))
 	tf.readyForSequencing = true
@@ -130,12 +181,14 @@ func (tf *transactionFlow) Action(ctx context.Context) {
 		return
 	}
 	tf.status = "endorsed"
+	tf.enterStage(ctx, components.PrivateTxStageDispatching)
 
 	reDelegate, err := tf.setTransactionSigner(ctx)
 	if err != nil {
 
 		log.L(ctx).Errorf("Invalid outcome from signer selection %s: %s", tf.transaction.ID.String(), err)
 		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerResolveDispatchError), err.Error())
+		tf.consumeRetry(ctx, "dispatch", tf.latestError)
 
 		//TODO as it stands, we will just enter a retry loop of trying to resolve the dispatcher next time the event loop triggers an action
 		// if we are lucky, that will be triggered by an event that somehow changes the in memory state in a way that the dispatcher can be
@@ -223,16 +276,86 @@ func (tf *transactionFlow) setTransactionSigner(ctx context.Context) (reDelegate
 	return false, nil
 }
 
+// Assembly is serialized, but a re-assembly race can still leave two in-flight transactions spending the
+// same state. The first to get to endorsement keeps the state, and any other is re-assembled before it
+// gathers endorsements, rather than the conflict being discovered when the domain context is flushed.
+func (tf *transactionFlow) checkInputStateClaims(ctx context.Context) bool {
+	if tf.inputStateConflictPending {
+		log.L(ctx).Infof("Transaction %s waiting to re-assemble after an input state conflict", tf.transaction.ID.String())
+		return false
+	}
+	stateID, claimingTransactionID := tf.inputStateClaims.GetInputStateClaim(ctx, tf.transaction.ID, tf.InputStateIDs())
+	if claimingTransactionID == "" {
+		return true
+	}
+	log.L(ctx).Warnf("Input state %s of transaction %s is already claimed by transaction %s", stateID, tf.transaction.ID.String(), claimingTransactionID)
+	tf.inputStateConflictPending = true
+	tf.publisher.PublishTransactionInputStateConflictEvent(ctx, tf.transaction.ID.String(), stateID, claimingTransactionID)
+	return false
+}
+
+// A transaction that declares a dependency on a transaction that has failed, or that does not exist,
+// can never succeed. So rather than leave it waiting we revert it with an error naming the dependency.
+// Returns false if the transaction cannot progress on this evaluation.
+func (tf *transactionFlow) checkDependencies(ctx context.Context) bool {
+	if tf.dependenciesChecked {
+		return true
+	}
+	for _, dep := range tf.transaction.DependsOn {
+		txMgr := tf.components.TxManager()
+		receipt, err := txMgr.GetTransactionReceiptByID(ctx, dep)
+		if err == nil && receipt == nil {
+			var depTx *pldapi.Transaction
+			depTx, err = txMgr.GetTransactionByID(ctx, dep)
+			if err == nil && depTx == nil {
+				tf.latestError = i18n.NewError(ctx, msgs.MsgPrivateTxManagerDependencyNotFound, dep).Error()
+				tf.revertTransaction(ctx, tf.latestError)
+				return false
+			}
+		}
+		if err != nil {
+			log.L(ctx).Errorf("Failed to check dependency %s of transaction %s: %s", dep, tf.transaction.ID.String(), err)
+			tf.latestError = err.Error()
+			return false
+		}
+		if receipt != nil && !receipt.Success {
+			tf.latestError = i18n.NewError(ctx, msgs.MsgPrivateTxManagerDependencyFailed, dep, receipt.FailureMessage).Error()
+			tf.revertTransaction(ctx, tf.latestError)
+			return false
+		}
+	}
+	// The check hits the DB, so is only repeated on re-assembly
+	tf.dependenciesChecked = true
+	return true
+}
+
 func (tf *transactionFlow) revertTransaction(ctx context.Context, revertReason string) {
 	log.L(ctx).Errorf("Reverting transaction %s: %s", tf.transaction.ID.String(), revertReason)
 	//trigger a finalize and update the transaction state so that finalize can be retried if it fails
 	tf.finalizeRequired = true
 	tf.finalizePending = true
 	tf.finalizeRevertReason = revertReason
+	tf.exitStage(ctx)
 	tf.finalize(ctx)
 
 }
 
+// Each stage of the flow retries independently, so this bounds the cumulative retry effort across all of them.
+// Records a retry of the given stage and reverts the transaction if that exhausts its retry budget, in which
+// case false is returned and the retry must not go ahead.
+func (tf *transactionFlow) consumeRetry(ctx context.Context, stage string, reason string) bool {
+	tf.retryCount++
+	elapsed := tf.clock.Now().Sub(tf.retryBudgetStart)
+	log.L(ctx).Debugf("Transaction %s retrying %s (retries=%d elapsed=%s): %s", tf.transaction.ID.String(), stage, tf.retryCount, elapsed, reason)
+	if (tf.maxRetries > 0 && tf.retryCount > tf.maxRetries) ||
+		(tf.maxRetryDuration > 0 && elapsed > tf.maxRetryDuration) {
+		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerRetryBudgetExhausted), stage, tf.retryCount, elapsed, reason)
+		tf.revertTransaction(ctx, tf.latestError)
+		return false
+	}
+	return true
+}
+
 func (tf *transactionFlow) finalize(ctx context.Context) {
 	log.L(ctx).Errorf("finalize transaction %s: %s", tf.transaction.ID.String(), tf.finalizeRevertReason)
 	//flush that to the txmgr database
@@ -267,7 +390,9 @@ func (tf *transactionFlow) finalize(ctx context.Context) {
 	)
 }
 
-func (tf *transactionFlow) delegateIfRequired(ctx context.Context) {
+// Returns false if the coordinator could not be determined, in which case the transaction must not be coordinated locally.
+// Domains that do not choose a coordinator selection get COORDINATOR_STATIC with no coordinator, and coordinate locally.
+func (tf *transactionFlow) delegateIfRequired(ctx context.Context) bool {
 	log.L(ctx).Debug("transactionFlow:delegateIfRequired")
 	contractConfig := tf.domainAPI.ContractConfig()
 
@@ -308,7 +433,7 @@ func (tf *transactionFlow) delegateIfRequired(ctx context.Context) {
 		if err != nil {
 			log.L(ctx).Errorf("Failed to get node name from locator %s: %s", knownCoordinator, err)
 			tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInternalError), err.Error())
-			return
+			return false
 		}
 		if coordinatorNode != tf.nodeID && coordinatorNode != "" {
 			tf.localCoordinator = false
@@ -323,11 +448,99 @@ func (tf *transactionFlow) delegateIfRequired(ctx context.Context) {
 			)
 			if err != nil {
 				tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInternalError), err.Error())
+				return true
 			}
+			recordDelegation(ctx, tf.components, tf.transaction.ID, coordinatorNode)
+			return true
+		}
+	}
+	return true
+}
+
+// The delegation to the winner of a contention bid is re-sent with backoff until the winner acknowledges it.
+// This is driven from the sequencer loop, by the acknowledgement event and by the evaluation ticker while
+// the acknowledgement is outstanding.
+func (tf *transactionFlow) delegateToContentionWinner(ctx context.Context) {
+	log.L(ctx).Debug("transactionFlow:delegateToContentionWinner")
+	if tf.finalizeRequired {
+		return
+	}
+	txID := tf.transaction.ID.String()
+	winningNode := tf.contentionWinner
+
+	if tf.contentionDelegationAcked {
+		if tf.contentionDelegating {
+			log.L(ctx).Infof("Delegation of transaction %s to contention winner %s acknowledged", txID, winningNode)
+			tf.contentionDelegating = false
+			recordDelegation(ctx, tf.components, tf.transaction.ID, winningNode)
+			tf.publisher.PublishTransactionDelegatedEvent(ctx, txID)
+		}
+		return
+	}
+
+	now := tf.clock.Now()
+	if tf.contentionDelegating {
+		if now.Before(tf.contentionDelegationSent.Add(tf.contentionRetry.Delay(tf.contentionDelegationCount))) {
 			return
 		}
+		if maxAttempts := tf.contentionRetry.MaxAttempts(); maxAttempts > 0 && tf.contentionDelegationCount >= maxAttempts {
+			reason := tf.contentionDelegationError
+			if reason == "" {
+				reason = i18n.NewError(ctx, msgs.MsgPrivateTxMgrDelegationNotAcknowledged, tf.contentionDelegationCount).Error()
+			}
+			tf.publisher.PublishTransactionDelegationFailedEvent(ctx, txID,
+				i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxMgrContentionRetryExhausted), winningNode, reason),
+			)
+			return
+		}
+	} else {
+		tf.contentionDelegating = true
+		tf.contentionDelegationID = uuid.New().String()
+		tf.status = "delegating"
 	}
 
+	tf.contentionDelegationCount++
+	tf.contentionDelegationSent = now
+	log.L(ctx).Infof("Delegating transaction %s to contention winner %s (attempt=%d)", txID, winningNode, tf.contentionDelegationCount)
+	tf.contentionDelegationError = ""
+	if err := tf.transportWriter.SendDelegationRequest(ctx, tf.contentionDelegationID, winningNode, tf.transaction); err != nil {
+		log.L(ctx).Errorf("Failed to delegate transaction %s to contention winner %s (attempt=%d): %s", txID, winningNode, tf.contentionDelegationCount, err)
+		tf.contentionDelegationError = err.Error()
+	}
+}
+
+// Give the domain the opportunity to reject a malformed assembly before we go to the expense of gathering
+// endorsements for it (a no-op for domains that have not opted in), and construct the payloads of any
+// endorsement requests that name a domain payload builder (the domain manager only allows payload builders
+// on endorsements, as the signatures have already been gathered by this point).
+// Both are calls into the domain, so they are made off the event loop, on a copy of the assembly that the
+// domain can build the payloads into, and the outcome is applied by a TransactionAssemblyValidatedEvent.
+func (tf *transactionFlow) requestAssemblyValidation(ctx context.Context) {
+	tf.assemblyValidationID = uuid.New().String()
+	validationID := tf.assemblyValidationID
+
+	postAssembly := *tf.transaction.PostAssembly
+	postAssembly.AttestationPlan = make([]*prototk.AttestationRequest, len(tf.transaction.PostAssembly.AttestationPlan))
+	for i, attRequest := range tf.transaction.PostAssembly.AttestationPlan {
+		postAssembly.AttestationPlan[i] = proto.Clone(attRequest).(*prototk.AttestationRequest)
+	}
+	tx := *tf.transaction
+	tx.PostAssembly = &postAssembly
+
+	domainAPI := tf.domainAPI
+	dCtx := tf.endorsementGatherer.DomainContext()
+	readTX := tf.components.Persistence().DB() // no DB transaction required here
+	go func() {
+		errorMessage := ""
+		if err := domainAPI.ValidateAssembled(dCtx, readTX, &tx); err != nil {
+			log.L(ctx).Errorf("ValidateAssembled failed for transaction %s: %s", tx.ID.String(), err)
+			errorMessage = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerValidateAssembledError), err.Error())
+		} else if err := domainAPI.BuildAttestationPayloads(dCtx, readTX, &tx); err != nil {
+			log.L(ctx).Errorf("BuildAttestationPayloads failed for transaction %s: %s", tx.ID.String(), err)
+			errorMessage = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerBuildPayloadError), err.Error())
+		}
+		tf.publisher.PublishTransactionAssemblyValidatedEvent(ctx, tx.ID.String(), validationID, postAssembly.AttestationPlan, errorMessage)
+	}()
 }
 
 func (tf *transactionFlow) requestAssemble(ctx context.Context) {
@@ -360,6 +573,10 @@ func (tf *transactionFlow) requestAssemble(ctx context.Context) {
 	if assemblingNode == tf.nodeID || assemblingNode == "" {
 		//we are the node that is responsible for assembling this transaction
 		readTX := tf.components.Persistence().DB() // no DB transaction required here
+		var previousLabel string
+		if tf.transaction.PreAssembly != nil {
+			previousLabel = tf.transaction.PreAssembly.Label
+		}
 		err = tf.domainAPI.AssembleTransaction(tf.endorsementGatherer.DomainContext(), readTX, tf.transaction)
 		if err != nil {
 			log.L(ctx).Errorf("AssembleTransaction failed: %s", err)
@@ -379,6 +596,12 @@ func (tf *transactionFlow) requestAssemble(ctx context.Context) {
 			)
 			return
 		}
+		if label := tf.transaction.PreAssembly.Label; label != previousLabel {
+			// The label is only for display, so failing to record it does not fail the transaction
+			if err := tf.components.TxManager().SetTransactionLabel(ctx, readTX, tf.transaction.ID, label); err != nil {
+				log.L(ctx).Warnf("Failed to record label for transaction %s: %s", tf.transaction.ID, err)
+			}
+		}
 
 		// Some validation that we are confident we can execute the given attestation plan
 		for _, attRequest := range tf.transaction.PostAssembly.AttestationPlan {
@@ -442,7 +665,16 @@ func (tf *transactionFlow) requestSignature(ctx context.Context, attRequest *pro
 		return
 	}
 	// TODO this could be calling out to a remote signer, should we be doing these in parallel?
-	signaturePayload, err := keyMgr.Sign(ctx, resolvedKey, attRequest.PayloadType, attRequest.Payload)
+	payloadType := tf.signingPayloadType(attRequest)
+	signaturePayload, err := tf.signWithTimeout(ctx, resolvedKey, payloadType, attRequest.Payload)
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.L(ctx).Errorf("timed out after %s signing for party %s (verifier=%s,algorithm=%s)", tf.signingTimeout, partyName, resolvedKey.Verifier.Verifier, attRequest.Algorithm)
+		tf.publisher.PublishTransactionSignFailedEvent(ctx,
+			tf.transaction.ID.String(),
+			i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerSignTimeout), tf.signingTimeout, partyName, resolvedKey.Verifier.Verifier, attRequest.Algorithm),
+		)
+		return
+	}
 	if err != nil {
 		log.L(ctx).Errorf("failed to sign for party %s (verifier=%s,algorithm=%s): %s", partyName, resolvedKey.Verifier.Verifier, attRequest.Algorithm, err)
 		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerSignError), partyName, resolvedKey.Verifier.Verifier, attRequest.Algorithm, err.Error())
@@ -462,11 +694,53 @@ func (tf *transactionFlow) requestSignature(ctx context.Context, attRequest *pro
 				VerifierType: attRequest.VerifierType,
 			},
 			Payload:     signaturePayload,
-			PayloadType: &attRequest.PayloadType,
+			PayloadType: &payloadType,
 		},
 	)
 }
 
+// Payloads that are larger than the configured threshold are hashed by the signer before signing,
+// if the requested payload type has a hashed equivalent. The payload type recorded in the
+// attestation result tells the verifier to recompute the hash.
+func (tf *transactionFlow) signingPayloadType(attRequest *prototk.AttestationRequest) string {
+	if tf.signingHashThreshold > 0 && len(attRequest.Payload) > tf.signingHashThreshold &&
+		attRequest.PayloadType == signpayloads.OPAQUE_TO_RSV {
+		return signpayloads.OPAQUE_KECCAK256_TO_RSV
+	}
+	return attRequest.PayloadType
+}
+
+// signWithTimeout bounds the time we wait for the key manager, so that a slow or hung signer
+// cannot block attestation gathering indefinitely. Returns context.DeadlineExceeded on timeout.
+func (tf *transactionFlow) signWithTimeout(ctx context.Context, resolvedKey *pldapi.KeyMappingAndVerifier, payloadType string, payload []byte) ([]byte, error) {
+	signCtx, cancel := context.WithTimeout(ctx, tf.signingTimeout)
+	defer cancel()
+
+	type signResult struct {
+		payload []byte
+		err     error
+	}
+	resultChan := make(chan signResult, 1)
+	go func() {
+		payload, err := tf.components.KeyManager().Sign(signCtx, resolvedKey, payloadType, payload)
+		resultChan <- signResult{payload, err}
+	}()
+
+	select {
+	case res := <-resultChan:
+		if res.err != nil && ctx.Err() == nil && signCtx.Err() == context.DeadlineExceeded {
+			// the signer honored the context, and gave up because of our timeout
+			return nil, context.DeadlineExceeded
+		}
+		return res.payload, res.err
+	case <-signCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, context.DeadlineExceeded
+	}
+}
+
 func (tf *transactionFlow) requestSignatures(ctx context.Context) {
 
 	if tf.requestedSignatures {
@@ -499,79 +773,111 @@ func (tf *transactionFlow) requestSignatures(ctx context.Context) {
 	tf.requestedSignatures = true
 }
 
-func (tf *transactionFlow) requestEndorsement(ctx context.Context, party string, attRequest *prototk.AttestationRequest) {
+// requestEndorsement gathers the endorsement of a local party directly, and returns the node of a remote
+// party so the request can be sent along with those for any other parties on the same node
+func (tf *transactionFlow) requestEndorsement(ctx context.Context, party string, attRequest *prototk.AttestationRequest, sequential bool) (remoteNode string) {
 
 	partyLocator := tktypes.PrivateIdentityLocator(party)
 	partyNode, err := partyLocator.Node(ctx, true)
 	if err != nil {
 		log.L(ctx).Errorf("Failed to get node name from locator %s: %s", party, err)
 		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInternalError), err.Error())
-		return
+		return ""
 	}
 
-	if partyNode == tf.nodeID || partyNode == "" {
-		// This is a local party, so we can endorse it directly
-		endorsement, revertReason, err := tf.endorsementGatherer.GatherEndorsement(
-			ctx,
-			tf.transaction.PreAssembly.TransactionSpecification,
-			tf.transaction.PreAssembly.Verifiers,
-			tf.transaction.PostAssembly.Signatures,
-			toEndorsableList(tf.transaction.PostAssembly.InputStates),
-			toEndorsableList(tf.transaction.PostAssembly.ReadStates),
-			toEndorsableList(tf.transaction.PostAssembly.OutputStates),
-			toEndorsableList(tf.transaction.PostAssembly.InfoStates),
-			party,
-			attRequest)
-		if err != nil {
-			log.L(ctx).Errorf("Failed to gather endorsement for party %s: %s", party, err)
-			tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInternalError), err.Error())
-			return
+	if partyNode != tf.nodeID && partyNode != "" {
+		// This is a remote party, so we need to send an endorsement request to the remote node
+		return partyNode
+	}
 
-		}
-		tf.publisher.PublishTransactionEndorsedEvent(ctx,
-			tf.transaction.ID.String(),
-			endorsement,
-			revertReason,
-		)
+	// This is a local party, so we can endorse it directly
+	endorsement, revertReason, err := tf.endorsementGatherer.GatherEndorsement(
+		ctx,
+		tf.transaction.PreAssembly.TransactionSpecification,
+		tf.transaction.PreAssembly.Verifiers,
+		tf.transaction.PostAssembly.Signatures,
+		tf.priorEndorsements(sequential),
+		toEndorsableList(tf.transaction.PostAssembly.InputStates),
+		toEndorsableList(tf.transaction.PostAssembly.ReadStates),
+		toEndorsableList(tf.transaction.PostAssembly.OutputStates),
+		toEndorsableList(tf.transaction.PostAssembly.InfoStates),
+		party,
+		attRequest)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to gather endorsement for party %s: %s", party, err)
+		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInternalError), err.Error())
+		return ""
 
-	} else {
-		// This is a remote party, so we need to send an endorsement request to the remote node
+	}
+	tf.publisher.PublishTransactionEndorsedEvent(ctx,
+		tf.transaction.ID.String(),
+		endorsement,
+		revertReason,
+	)
+	return ""
+}
 
-		err = tf.transportWriter.SendEndorsementRequest(
-			ctx,
-			party,
-			partyNode,
-			tf.transaction.Inputs.To.String(),
-			tf.transaction.ID.String(),
-			attRequest,
-			tf.transaction.PreAssembly.TransactionSpecification,
-			tf.transaction.PreAssembly.Verifiers,
-			tf.transaction.PostAssembly.Signatures,
-			tf.transaction.PostAssembly.InputStates,
-			tf.transaction.PostAssembly.OutputStates,
-			tf.transaction.PostAssembly.InfoStates,
-		)
-		if err != nil {
-			log.L(ctx).Errorf("Failed to send endorsement request to party %s: %s", party, err)
-			tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerEndorsementRequestError), party, err.Error())
+// When endorsing sequentially, each endorser gets to see the endorsements gathered before it
+func (tf *transactionFlow) priorEndorsements(sequential bool) []*prototk.AttestationResult {
+	if sequential {
+		return tf.transaction.PostAssembly.Endorsements
+	}
+	return nil
+}
+
+// Every remote party on the same node is asked in a single transport message, rather than one message per party
+func (tf *transactionFlow) sendEndorsementRequests(ctx context.Context, targetNode string, requests []*ptmgrtypes.EndorsementRequestParty, sequential bool) {
+	err := tf.transportWriter.SendEndorsementRequest(
+		ctx,
+		targetNode,
+		tf.transaction.Inputs.To.String(),
+		tf.transaction.ID.String(),
+		requests,
+		tf.transaction.PreAssembly.TransactionSpecification,
+		tf.transaction.PreAssembly.Verifiers,
+		tf.transaction.PostAssembly.Signatures,
+		tf.priorEndorsements(sequential),
+		tf.transaction.PostAssembly.InputStates,
+		tf.transaction.PostAssembly.OutputStates,
+		tf.transaction.PostAssembly.InfoStates,
+	)
+	if err != nil {
+		parties := make([]string, len(requests))
+		for i, request := range requests {
+			parties[i] = request.Party
 		}
+		log.L(ctx).Errorf("Failed to send endorsement request to parties %v on node %s: %s", parties, targetNode, err)
+		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerEndorsementRequestError), strings.Join(parties, ","), err.Error())
 	}
 }
 
 func (tf *transactionFlow) requestEndorsements(ctx context.Context) {
+	sequential := tf.domainAPI.Domain().SequentialEndorsement()
+	var remoteNodes []string
+	remoteRequests := make(map[string][]*ptmgrtypes.EndorsementRequestParty)
+	// the requests for remote parties are sent together for each node once we have been through all the
+	// outstanding requests (including when we stop early)
+	defer func() {
+		for _, node := range remoteNodes {
+			tf.sendEndorsementRequests(ctx, node, remoteRequests[node], sequential)
+		}
+	}()
 	for _, outstandingEndorsementRequest := range tf.outstandingEndorsementRequests(ctx) {
 		// there is a request in the attestation plan and we do not have a response to match it
 		// first lets see if we have recently sent a request for this endorsement and just need to be patient
+		attRequestName := outstandingEndorsementRequest.attRequest.Name
+		party := outstandingEndorsementRequest.party
 		previousRequestTime := time.Time{}
-		if timesForAttRequest, ok := tf.requestedEndorsementTimes[outstandingEndorsementRequest.attRequest.Name]; ok {
-			if t, ok := timesForAttRequest[outstandingEndorsementRequest.party]; ok {
+		if timesForAttRequest, ok := tf.requestedEndorsementTimes[attRequestName]; ok {
+			if t, ok := timesForAttRequest[party]; ok {
 				previousRequestTime = t
 			}
 		} else {
-			tf.requestedEndorsementTimes[outstandingEndorsementRequest.attRequest.Name] = make(map[string]time.Time)
+			tf.requestedEndorsementTimes[attRequestName] = make(map[string]time.Time)
+			tf.requestedEndorsementCounts[attRequestName] = make(map[string]int)
 		}
 
-		if !previousRequestTime.IsZero() && tf.clock.Now().Before(previousRequestTime.Add(tf.requestTimeout)) {
+		if !previousRequestTime.IsZero() && tf.clock.Now().Before(previousRequestTime.Add(tf.endorsementRequestTimeout)) {
 			//We have already sent a message for this request and the deadline has not passed
 			log.L(ctx).Debugf("Transaction %s endorsement already requested %v", tf.transaction.ID.String(), previousRequestTime)
 			return
@@ -580,13 +886,60 @@ func (tf *transactionFlow) requestEndorsements(ctx context.Context) {
 			log.L(ctx).Infof("Transaction %s endorsement has never been requested for attestation request:%s, party:%s", tf.transaction.ID.String(), outstandingEndorsementRequest.attRequest.Name, outstandingEndorsementRequest.party)
 		} else {
 			log.L(ctx).Infof("Previous endorsement request for transaction:%s, attestation request:%s, party:%s sent at %v has timed out", tf.transaction.ID.String(), outstandingEndorsementRequest.attRequest.Name, outstandingEndorsementRequest.party, previousRequestTime)
+			attempts := tf.requestedEndorsementCounts[attRequestName][party]
+			if tf.endorsementMaxRetries > 0 && attempts > tf.endorsementMaxRetries {
+				// the endorser is not answering, so fail the transaction rather than leave it waiting forever
+				tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerEndorsementTimeout), attRequestName, party, attempts, tf.endorsementRequestTimeout)
+				tf.revertTransaction(ctx, tf.latestError)
+				return
+			}
+			if !tf.consumeRetry(ctx, "endorse", fmt.Sprintf("endorsement request to %s timed out", outstandingEndorsementRequest.party)) {
+				return
+			}
+		}
+		if remoteNode := tf.requestEndorsement(ctx, party, outstandingEndorsementRequest.attRequest, sequential); remoteNode != "" {
+			if _, ok := remoteRequests[remoteNode]; !ok {
+				remoteNodes = append(remoteNodes, remoteNode)
+			}
+			remoteRequests[remoteNode] = append(remoteRequests[remoteNode], &ptmgrtypes.EndorsementRequestParty{
+				Party:              party,
+				AttestationRequest: outstandingEndorsementRequest.attRequest,
+			})
+		}
+		tf.requestedEndorsementTimes[attRequestName][party] = tf.clock.Now()
+		tf.requestedEndorsementCounts[attRequestName][party]++
+		if sequential {
+			// only one endorser is asked at a time - the next is asked once this one has responded
+			break
 		}
-		tf.requestEndorsement(ctx, outstandingEndorsementRequest.party, outstandingEndorsementRequest.attRequest)
-		tf.requestedEndorsementTimes[outstandingEndorsementRequest.attRequest.Name][outstandingEndorsementRequest.party] = tf.clock.Now()
 
 	}
 }
 
+func (tf *transactionFlow) requestAssemblyVerifierResolution(ctx context.Context) {
+	for _, v := range tf.transaction.PostAssembly.RequiredVerifiers {
+		if tf.isVerifierResolved(v) || tf.requestedAssemblyVerifiers[verifierRequestKey(v)] {
+			continue
+		}
+		if tf.requestedAssemblyVerifiers == nil {
+			tf.requestedAssemblyVerifiers = make(map[string]bool)
+		}
+		tf.requestedAssemblyVerifiers[verifierRequestKey(v)] = true
+		tf.identityResolver.ResolveVerifierAsync(
+			ctx,
+			v.Lookup,
+			v.Algorithm,
+			v.VerifierType,
+			func(ctx context.Context, verifier string) {
+				tf.publisher.PublishResolveVerifierResponseEvent(ctx, tf.transaction.ID.String(), v.Lookup, v.Algorithm, verifier, v.VerifierType)
+			},
+			func(ctx context.Context, err error) {
+				tf.publisher.PublishResolveVerifierErrorEvent(ctx, tf.transaction.ID.String(), v.Lookup, v.Algorithm, err.Error())
+			},
+		)
+	}
+}
+
 func (tf *transactionFlow) requestVerifierResolution(ctx context.Context) {
 
 	if tf.requestedVerifierResolution {
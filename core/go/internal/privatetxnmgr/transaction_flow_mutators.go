@@ -17,8 +17,10 @@ package privatetxnmgr
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
@@ -35,6 +37,8 @@ func (tf *transactionFlow) ApplyEvent(ctx context.Context, event ptmgrtypes.Priv
 		tf.applyTransactionSwappedInEvent(ctx, event)
 	case *ptmgrtypes.TransactionSignedEvent:
 		tf.applyTransactionSignedEvent(ctx, event)
+	case *ptmgrtypes.TransactionSignFailedEvent:
+		tf.applyTransactionSignFailedEvent(ctx, event)
 	case *ptmgrtypes.TransactionEndorsedEvent:
 		tf.applyTransactionEndorsedEvent(ctx, event)
 	case *ptmgrtypes.TransactionAssembledEvent:
@@ -43,12 +47,24 @@ func (tf *transactionFlow) ApplyEvent(ctx context.Context, event ptmgrtypes.Priv
 		tf.applyTransactionAssembleFailedEvent(ctx, event)
 	case *ptmgrtypes.TransactionDispatchedEvent:
 		tf.applyTransactionDispatchedEvent(ctx, event)
+	case *ptmgrtypes.TransactionDispatchFailedEvent:
+		tf.applyTransactionDispatchFailedEvent(ctx, event)
 	case *ptmgrtypes.TransactionConfirmedEvent:
 		tf.applyTransactionConfirmedEvent(ctx, event)
 	case *ptmgrtypes.TransactionRevertedEvent:
 		tf.applyTransactionRevertedEvent(ctx, event)
 	case *ptmgrtypes.TransactionDelegatedEvent:
 		tf.applyTransactionDelegatedEvent(ctx, event)
+	case *ptmgrtypes.TransactionContentionLostEvent:
+		tf.applyTransactionContentionLostEvent(ctx, event)
+	case *ptmgrtypes.TransactionDelegationAcknowledgedEvent:
+		tf.applyTransactionDelegationAcknowledgedEvent(ctx, event)
+	case *ptmgrtypes.TransactionDelegationFailedEvent:
+		tf.applyTransactionDelegationFailedEvent(ctx, event)
+	case *ptmgrtypes.TransactionAssemblyValidatedEvent:
+		tf.applyTransactionAssemblyValidatedEvent(ctx, event)
+	case *ptmgrtypes.TransactionInputStateConflictEvent:
+		tf.applyTransactionInputStateConflictEvent(ctx, event)
 	case *ptmgrtypes.ResolveVerifierResponseEvent:
 		tf.applyResolveVerifierResponseEvent(ctx, event)
 	case *ptmgrtypes.ResolveVerifierErrorEvent:
@@ -57,6 +73,8 @@ func (tf *transactionFlow) ApplyEvent(ctx context.Context, event ptmgrtypes.Priv
 		tf.applyTransactionFinalizedEvent(ctx, event)
 	case *ptmgrtypes.TransactionFinalizeError:
 		tf.applyTransactionFinalizeError(ctx, event)
+	case *ptmgrtypes.TransactionCancelEvent:
+		tf.applyTransactionCancelEvent(ctx, event)
 
 	default:
 		log.L(ctx).Warnf("Unknown event type: %T", event)
@@ -85,6 +103,11 @@ func (tf *transactionFlow) applyTransactionAssembledEvent(ctx context.Context, _
 		tf.revertTransaction(ctx, i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerAssembleRevert)))
 		return
 	}
+	if err := tf.validateEndorsementPolicy(ctx); err != nil {
+		log.L(ctx).Errorf("Attestation plan of transaction %s cannot be satisfied: %s", tf.transaction.ID, err)
+		tf.revertTransaction(ctx, err.Error())
+		return
+	}
 	tf.status = "assembled"
 
 }
@@ -92,6 +115,7 @@ func (tf *transactionFlow) applyTransactionAssembledEvent(ctx context.Context, _
 func (tf *transactionFlow) applyTransactionAssembleFailedEvent(ctx context.Context, event *ptmgrtypes.TransactionAssembleFailedEvent) {
 	log.L(ctx).Debugf("transactionFlow:applyTransactionAssembleFailedEvent: %s", event.Error)
 	tf.latestEvent = "TransactionAssembleFailedEvent"
+	tf.exitStage(ctx)
 	tf.latestError = event.Error
 	tf.finalizeRequired = true
 	tf.finalizeRevertReason = event.Error
@@ -104,10 +128,39 @@ func (tf *transactionFlow) applyTransactionSignedEvent(ctx context.Context, even
 
 }
 
+func (tf *transactionFlow) applyTransactionSignFailedEvent(ctx context.Context, event *ptmgrtypes.TransactionSignFailedEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionSignFailedEvent transactionID:%s: %s", tf.transaction.ID.String(), event.Error)
+	tf.latestEvent = "TransactionSignFailedEvent"
+	tf.latestError = event.Error
+	// allow the signatures to be requested again on the next evaluation
+	if tf.consumeRetry(ctx, "sign", event.Error) {
+		tf.requestedSignatures = false
+	}
+}
+
 func (tf *transactionFlow) applyTransactionEndorsedEvent(ctx context.Context, event *ptmgrtypes.TransactionEndorsedEvent) {
 	tf.latestEvent = "TransactionEndorsedEvent"
+	// a rejection with no attestation result cannot be validated, but still invalidates the assembly
+	if event.Endorsement != nil || event.RevertReason == nil {
+		if err := tf.validateEndorsement(ctx, event.Endorsement, event.RevertReason != nil); err != nil {
+			log.L(ctx).Warnf("Discarding endorsement response: %s", err)
+			tf.latestError = err.Error()
+			return
+		}
+	}
 	if event.RevertReason != nil {
 		log.L(ctx).Infof("Endorsement for transaction %s was rejected: %s", tf.transaction.ID.String(), *event.RevertReason)
+		if tf.endorsementRevertPolicy != pldconf.EndorsementRevertPolicyReassemble {
+			// the endorser has told us the transaction should revert, so there is no point waiting for the other endorsements
+			if tf.finalizeRequired {
+				// another endorser got there first
+				return
+			}
+			tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerEndorsementReverted),
+				event.Endorsement.GetName(), event.Endorsement.GetVerifier().GetLookup(), *event.RevertReason)
+			tf.revertTransaction(ctx, tf.latestError)
+			return
+		}
 		// endorsement errors trigger a re-assemble
 		// if the reason for the endorsement error is a change of state of the universe since the transaction was assembled, then the re-assemble may fail and cause the transaction to be reverted
 		// on the other hand, the re-assemble may result in an endorsable version of the transaction.
@@ -115,9 +168,19 @@ func (tf *transactionFlow) applyTransactionEndorsedEvent(ctx context.Context, ev
 		//TODO - there may be other endorsements that are en route, based on the previous assembly.  Need to make sure that
 		// we discard them when they do return.
 		//only apply at this stage, action will be taken later
-		tf.transaction.PostAssembly = nil
+		if !tf.consumeRetry(ctx, "assemble", *event.RevertReason) {
+			return
+		}
+		tf.discardAssembly()
 
 	} else {
+		// requests are re-sent when they time out, so a late response to an earlier request can arrive after we have the endorsement
+		for _, existing := range tf.transaction.PostAssembly.Endorsements {
+			if existing.Name == event.Endorsement.Name && existing.Verifier.GetLookup() == event.Endorsement.Verifier.Lookup {
+				log.L(ctx).Infof("Ignoring duplicate endorsement from %s to transaction %s", event.Endorsement.Verifier.Lookup, tf.transaction.ID.String())
+				return
+			}
+		}
 		log.L(ctx).Infof("Adding endorsement from %s to transaction %s", event.Endorsement.Verifier.Lookup, tf.transaction.ID.String())
 		tf.transaction.PostAssembly.Endorsements = append(tf.transaction.PostAssembly.Endorsements, event.Endorsement)
 
@@ -128,9 +191,26 @@ func (tf *transactionFlow) applyTransactionDispatchedEvent(ctx context.Context,
 	log.L(ctx).Debugf("transactionFlow:applyTransactionDispatchedEvent transactionID:%s nonce:%d signingAddress:%s", tf.transaction.ID.String(), event.Nonce, event.SigningAddress)
 	tf.latestEvent = "TransactionDispatchedEvent"
 	tf.status = "dispatched"
+	tf.exitStage(ctx)
 	tf.dispatched = true
 }
 
+func (tf *transactionFlow) applyTransactionDispatchFailedEvent(ctx context.Context, event *ptmgrtypes.TransactionDispatchFailedEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionDispatchFailedEvent transactionID:%s: %s", tf.transaction.ID.String(), event.Error)
+	tf.latestEvent = "TransactionDispatchFailedEvent"
+	tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerDispatchPersistFailed), event.Error)
+	// the states of the assembled transaction were discarded with the domain context, so it must not be dispatched as it is
+	tf.readyForSequencing = false
+	if tf.flushFailurePolicy != pldconf.FlushFailurePolicyReassemble {
+		tf.revertTransaction(ctx, tf.latestError)
+		return
+	}
+	if !tf.consumeRetry(ctx, "dispatch", tf.latestError) {
+		return
+	}
+	tf.discardAssembly()
+}
+
 func (tf *transactionFlow) applyTransactionConfirmedEvent(ctx context.Context, event *ptmgrtypes.TransactionConfirmedEvent) {
 	log.L(ctx).Debugf("transactionFlow:applyTransactionConfirmedEvent transactionID:%s contractAddress: %s", tf.transaction.ID.String(), event.ContractAddress)
 	tf.latestEvent = "TransactionConfirmedEvent"
@@ -148,6 +228,70 @@ func (tf *transactionFlow) applyTransactionDelegatedEvent(ctx context.Context, _
 	log.L(ctx).Debugf("transactionFlow:applyTransactionDelegatedEvent transactionID:%s", tf.transaction.ID.String())
 	tf.latestEvent = "TransactionDelegatedEvent"
 	tf.status = "delegated"
+	tf.exitStage(ctx)
+	tf.delegated = true
+	tf.contentionDelegating = false
+}
+
+func (tf *transactionFlow) applyTransactionContentionLostEvent(ctx context.Context, event *ptmgrtypes.TransactionContentionLostEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionContentionLostEvent transactionID:%s winningNode:%s", tf.transaction.ID.String(), event.WinningNode)
+	tf.latestEvent = "TransactionContentionLostEvent"
+	if tf.dispatched {
+		// too late to hand over coordination, the transaction is already on its way to the base ledger
+		log.L(ctx).Warnf("Ignoring lost contention for transaction %s as it has already been dispatched", tf.transaction.ID.String())
+		return
+	}
+	tf.contentionWinner = event.WinningNode
+	tf.localCoordinator = false
+	tf.readyForSequencing = false
+}
+
+func (tf *transactionFlow) applyTransactionDelegationAcknowledgedEvent(ctx context.Context, event *ptmgrtypes.TransactionDelegationAcknowledgedEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionDelegationAcknowledgedEvent transactionID:%s delegationID:%s", tf.transaction.ID.String(), event.DelegationID)
+	tf.latestEvent = "TransactionDelegationAcknowledgedEvent"
+	if !tf.contentionDelegating || event.DelegationID != tf.contentionDelegationID {
+		log.L(ctx).Warnf("Ignoring acknowledgement of delegation %s of transaction %s, as it is not the pending delegation", event.DelegationID, tf.transaction.ID.String())
+		return
+	}
+	tf.contentionDelegationAcked = true
+}
+
+func (tf *transactionFlow) applyTransactionDelegationFailedEvent(ctx context.Context, event *ptmgrtypes.TransactionDelegationFailedEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionDelegationFailedEvent transactionID:%s: %s", tf.transaction.ID.String(), event.Error)
+	tf.latestEvent = "TransactionDelegationFailedEvent"
+	tf.latestError = event.Error
+	tf.contentionDelegating = false
+	tf.finalizeRequired = true
+	tf.finalizeRevertReason = event.Error
+}
+
+func (tf *transactionFlow) applyTransactionAssemblyValidatedEvent(ctx context.Context, event *ptmgrtypes.TransactionAssemblyValidatedEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionAssemblyValidatedEvent transactionID:%s validationID:%s: %s", tf.transaction.ID.String(), event.ValidationID, event.Error)
+	tf.latestEvent = "TransactionAssemblyValidatedEvent"
+	if tf.assemblyValidationID == "" || event.ValidationID != tf.assemblyValidationID {
+		// the assembly it validated has since been discarded
+		log.L(ctx).Infof("Ignoring validation %s of a discarded assembly of transaction %s", event.ValidationID, tf.transaction.ID.String())
+		return
+	}
+	tf.assemblyValidationID = ""
+	if event.Error != "" {
+		tf.latestError = event.Error
+		tf.revertTransaction(ctx, tf.latestError)
+		return
+	}
+	tf.transaction.PostAssembly.AttestationPlan = event.AttestationPlan
+	tf.assemblyValidated = true
+}
+
+func (tf *transactionFlow) applyTransactionInputStateConflictEvent(ctx context.Context, event *ptmgrtypes.TransactionInputStateConflictEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionInputStateConflictEvent transactionID:%s state:%s claimedBy:%s", tf.transaction.ID.String(), event.StateID, event.ClaimingTransactionID)
+	tf.latestEvent = "TransactionInputStateConflictEvent"
+	tf.inputStateConflictPending = false
+	tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerInputStateConflict), event.StateID, event.ClaimingTransactionID)
+	if !tf.consumeRetry(ctx, "assemble", tf.latestError) {
+		return
+	}
+	tf.discardAssembly()
 }
 
 func (tf *transactionFlow) applyResolveVerifierResponseEvent(ctx context.Context, event *ptmgrtypes.ResolveVerifierResponseEvent) {
@@ -174,13 +318,17 @@ func (tf *transactionFlow) applyResolveVerifierErrorEvent(ctx context.Context, e
 	// all we can do it try to re-assemble the transaction
 	// TODO we might have other resolver verifieres in progress.  Need to make sure that when they are received, we only apply them if they
 	// happen to match the requirements new assembled transaction and if that is still nil, then discard them
-	tf.transaction.PostAssembly = nil
+	if !tf.consumeRetry(ctx, "resolve", *event.ErrorMessage) {
+		return
+	}
+	tf.discardAssembly()
 }
 
 func (tf *transactionFlow) applyTransactionFinalizedEvent(ctx context.Context, _ *ptmgrtypes.TransactionFinalizedEvent) {
 	log.L(ctx).Debugf("transactionFlow:applyTransactionFinalizedEvent transactionID:%s", tf.transaction.ID.String())
 	tf.latestEvent = "TransactionFinalizedEvent"
 	tf.complete = true
+	tf.exitStage(ctx)
 	log.L(ctx).Debug("HandleTransactionFinalizedEvent")
 }
 
@@ -191,3 +339,43 @@ func (tf *transactionFlow) applyTransactionFinalizeError(ctx context.Context, ev
 	tf.finalizeRequired = true
 	tf.finalizePending = false
 }
+
+func (tf *transactionFlow) applyTransactionCancelEvent(ctx context.Context, event *ptmgrtypes.TransactionCancelEvent) {
+	log.L(ctx).Debugf("transactionFlow:applyTransactionCancelEvent transactionID:%s", tf.transaction.ID.String())
+	tf.latestEvent = "TransactionCancelEvent"
+	txID := tf.transaction.ID.String()
+	switch {
+	case tf.status == "cancelled":
+		// already cancelled, and the finalize is either pending or being retried
+	case tf.dispatched:
+		event.Result <- i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelDispatched, txID)
+		return
+	case tf.delegated || tf.contentionDelegating || !tf.localCoordinator:
+		// the coordinator on the other node would carry on regardless
+		event.Result <- i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelDelegated, txID)
+		return
+	case tf.finalizeRequired:
+		event.Result <- i18n.NewError(ctx, msgs.MsgPrivateTxManagerCancelFinalizing, txID, tf.finalizeRevertReason)
+		return
+	default:
+		log.L(ctx).Infof("Cancelling transaction %s in status %s", txID, tf.status)
+		tf.status = "cancelled"
+		// make sure the sequencer takes it out of the graph, so it can never be dispatched
+		tf.readyForSequencing = false
+		tf.latestError = i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPrivateTxManagerTransactionCancelled))
+		tf.revertTransaction(ctx, tf.latestError)
+	}
+	event.Result <- nil
+}
+
+// discardAssembly resets everything derived from the current assembly, so the transaction is re-assembled
+// and the endorsements of the new assembly are requested afresh
+func (tf *transactionFlow) discardAssembly() {
+	tf.transaction.PostAssembly = nil
+	tf.assemblyValidated = false
+	tf.assemblyValidationID = ""
+	tf.dependenciesChecked = false
+	tf.requestedAssemblyVerifiers = nil
+	tf.requestedEndorsementTimes = make(map[string]map[string]time.Time)
+	tf.requestedEndorsementCounts = make(map[string]map[string]int)
+}
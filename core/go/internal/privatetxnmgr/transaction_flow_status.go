@@ -17,7 +17,11 @@ package privatetxnmgr
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 )
@@ -27,15 +31,12 @@ func (tf *transactionFlow) hasOutstandingVerifierRequests(ctx context.Context) b
 
 	// assume they are all resolved until we find one in RequiredVerifiers that is not in Verifiers
 	verifiersResolved := true
-	for _, v := range tf.transaction.PreAssembly.RequiredVerifiers {
-		thisVerifierIsResolved := false
-		for _, rv := range tf.transaction.PreAssembly.Verifiers {
-			if rv.Lookup == v.Lookup {
-				thisVerifierIsResolved = true
-				break
-			}
-		}
-		if !thisVerifierIsResolved {
+	requiredVerifiers := append([]*prototk.ResolveVerifierRequest{}, tf.transaction.PreAssembly.RequiredVerifiers...)
+	if tf.transaction.PostAssembly != nil {
+		requiredVerifiers = append(requiredVerifiers, tf.transaction.PostAssembly.RequiredVerifiers...)
+	}
+	for _, v := range requiredVerifiers {
+		if !tf.isVerifierResolved(v) {
 			verifiersResolved = false
 		}
 	}
@@ -48,6 +49,21 @@ func (tf *transactionFlow) hasOutstandingVerifierRequests(ctx context.Context) b
 
 }
 
+// A single identity can have verifiers of several types (an ETH address and a ZKP public key for example),
+// so a resolved verifier only satisfies a request for the same algorithm and verifier type
+func (tf *transactionFlow) isVerifierResolved(v *prototk.ResolveVerifierRequest) bool {
+	for _, rv := range tf.transaction.PreAssembly.Verifiers {
+		if rv.Lookup == v.Lookup && rv.Algorithm == v.Algorithm && rv.VerifierType == v.VerifierType {
+			return true
+		}
+	}
+	return false
+}
+
+func verifierRequestKey(v *prototk.ResolveVerifierRequest) string {
+	return fmt.Sprintf("%s|%s|%s", v.Lookup, v.Algorithm, v.VerifierType)
+}
+
 func (tf *transactionFlow) hasOutstandingSignatureRequests() bool {
 	outstandingSignatureRequests := false
 out:
@@ -74,16 +90,85 @@ func (tf *transactionFlow) hasOutstandingEndorsementRequests(ctx context.Context
 	return len(tf.outstandingEndorsementRequests(ctx)) > 0
 }
 
+// An endorsement (or endorsement rejection) is only accepted if it corresponds to an endorse request in the
+// current attestation plan, from one of the parties that was asked to endorse.
+// Rejections are not required to identify the verifier of the party that rejected the transaction.
+func (tf *transactionFlow) validateEndorsement(ctx context.Context, endorsement *prototk.AttestationResult, rejected bool) error {
+	if endorsement == nil {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerInvalidEndorsement, tf.transaction.ID, "attestation result")
+	}
+	verifier := endorsement.Verifier
+	if verifier == nil && !rejected {
+		return i18n.NewError(ctx, msgs.MsgPrivateTxManagerInvalidEndorsement, tf.transaction.ID, "verifier")
+	}
+	if tf.transaction.PostAssembly != nil {
+		for _, attRequest := range tf.transaction.PostAssembly.AttestationPlan {
+			if attRequest.AttestationType == prototk.AttestationType_ENDORSE &&
+				attRequest.Name == endorsement.Name &&
+				attRequest.AttestationType == endorsement.AttestationType &&
+				(verifier == nil || (attRequest.Algorithm == verifier.Algorithm &&
+					attRequest.VerifierType == verifier.VerifierType &&
+					slices.Contains(attRequest.Parties, verifier.Lookup))) {
+				return nil
+			}
+		}
+	}
+	return i18n.NewError(ctx, msgs.MsgPrivateTxManagerUnrequestedEndorsement,
+		endorsement.Name, endorsement.AttestationType, verifier.GetAlgorithm(), verifier.GetLookup(), tf.transaction.ID)
+}
+
 type outstandingEndorsementRequest struct {
 	attRequest *prototk.AttestationRequest
 	party      string
 }
 
+// endorsementRequirement applies the endorsement policy of the contract to an endorse attestation request,
+// returning the parties that can be asked to endorse, and how many of them must endorse
+func (tf *transactionFlow) endorsementRequirement(attRequest *prototk.AttestationRequest) ([]string, int) {
+	parties := attRequest.Parties
+	required := len(parties)
+	if attRequest.Threshold != nil {
+		required = int(*attRequest.Threshold)
+	}
+	contractConfig := tf.domainAPI.ContractConfig()
+	switch contractConfig.GetEndorsementPolicy() {
+	case prototk.ContractConfig_ENDORSEMENT_THRESHOLD:
+		if contractConfig.EndorsementThreshold != nil {
+			required = int(*contractConfig.EndorsementThreshold)
+		}
+	case prototk.ContractConfig_ENDORSEMENT_NOTARY:
+		// validateEndorsementPolicy has checked the notary is the only party
+		required = len(parties)
+	}
+	if required < 1 || required > len(parties) {
+		required = len(parties)
+	}
+	return parties, required
+}
+
+// validateEndorsementPolicy checks the attestation plan of an assembled transaction can be satisfied under the
+// endorsement policy of the contract. Under a notary policy every endorse request must name the notary alone,
+// as we have no way to tell which of several parties is the notary.
+func (tf *transactionFlow) validateEndorsementPolicy(ctx context.Context) error {
+	if tf.domainAPI.ContractConfig().GetEndorsementPolicy() != prototk.ContractConfig_ENDORSEMENT_NOTARY {
+		return nil
+	}
+	for _, attRequest := range tf.transaction.PostAssembly.AttestationPlan {
+		if attRequest.AttestationType == prototk.AttestationType_ENDORSE && len(attRequest.Parties) != 1 {
+			return i18n.NewError(ctx, msgs.MsgPrivateTxManagerNotaryEndorsementParties, attRequest.Name, len(attRequest.Parties))
+		}
+	}
+	return nil
+}
+
 func (tf *transactionFlow) outstandingEndorsementRequests(ctx context.Context) []*outstandingEndorsementRequest {
 	outstandingEndorsementRequests := make([]*outstandingEndorsementRequest, 0)
 	for _, attRequest := range tf.transaction.PostAssembly.AttestationPlan {
 		if attRequest.AttestationType == prototk.AttestationType_ENDORSE {
-			for _, party := range attRequest.Parties {
+			parties, required := tf.endorsementRequirement(attRequest)
+			endorsed := 0
+			var outstandingForRequest []*outstandingEndorsementRequest
+			for _, party := range parties {
 				found := false
 				for _, endorsement := range tf.transaction.PostAssembly.Endorsements {
 					found = endorsement.Name == attRequest.Name &&
@@ -99,11 +184,18 @@ func (tf *transactionFlow) outstandingEndorsementRequests(ctx context.Context) [
 						break
 					}
 				}
-				if !found {
+				if found {
+					endorsed++
+				} else {
 					log.L(ctx).Debugf("endorsement request for %s outstanding for transaction %s", party, tf.transaction.ID)
-					outstandingEndorsementRequests = append(outstandingEndorsementRequests, &outstandingEndorsementRequest{party: party, attRequest: attRequest})
+					outstandingForRequest = append(outstandingForRequest, &outstandingEndorsementRequest{party: party, attRequest: attRequest})
 				}
 			}
+			if endorsed < required {
+				outstandingEndorsementRequests = append(outstandingEndorsementRequests, outstandingForRequest...)
+			} else if len(outstandingForRequest) > 0 {
+				log.L(ctx).Debugf("endorsement request %s for transaction %s satisfied with %d of %d parties", attRequest.Name, tf.transaction.ID, endorsed, len(attRequest.Parties))
+			}
 		}
 	}
 	return outstandingEndorsementRequests
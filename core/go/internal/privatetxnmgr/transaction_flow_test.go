@@ -17,22 +17,29 @@ package privatetxnmgr
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/privatetxnmgrmocks"
 	"github.com/kaleido-io/paladin/core/mocks/prvtxsyncpointsmocks"
+	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type transactionProcessorDepencyMocks struct {
@@ -48,6 +55,7 @@ type transactionProcessorDepencyMocks struct {
 	identityResolver    *componentmocks.IdentityResolver
 	syncPoints          *prvtxsyncpointsmocks.SyncPoints
 	transportWriter     *privatetxnmgrmocks.TransportWriter
+	inputStateClaims    *privatetxnmgrmocks.InputStateClaims
 }
 
 func newPaladinTransactionProcessorForTesting(t *testing.T, ctx context.Context, transaction *components.PrivateTransaction) (*transactionFlow, *transactionProcessorDepencyMocks) {
@@ -65,6 +73,7 @@ func newPaladinTransactionProcessorForTesting(t *testing.T, ctx context.Context,
 		identityResolver:    componentmocks.NewIdentityResolver(t),
 		syncPoints:          prvtxsyncpointsmocks.NewSyncPoints(t),
 		transportWriter:     privatetxnmgrmocks.NewTransportWriter(t),
+		inputStateClaims:    privatetxnmgrmocks.NewInputStateClaims(t),
 	}
 	contractAddress := tktypes.RandAddress()
 	mocks.allComponents.On("StateManager").Return(mocks.stateStore).Maybe()
@@ -79,13 +88,41 @@ func newPaladinTransactionProcessorForTesting(t *testing.T, ctx context.Context,
 
 	domain := componentmocks.NewDomain(t)
 	domain.On("Configuration").Return(&prototk.DomainConfig{}).Maybe()
+	domain.On("SequentialEndorsement").Return(false).Maybe()
 	mocks.domainSmartContract.On("Domain").Return(domain).Maybe()
+	mocks.domainSmartContract.On("ValidateAssembled", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mocks.domainSmartContract.On("BuildAttestationPayloads", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mocks.inputStateClaims.On("GetInputStateClaim", mock.Anything, mock.Anything, mock.Anything).Return("", "").Maybe()
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	mocks.allComponents.On("Persistence").Return(mp.P).Maybe()
 
-	tp := NewTransactionFlow(ctx, transaction, tktypes.RandHex(16), mocks.allComponents, mocks.domainSmartContract, mocks.publisher, mocks.endorsementGatherer, mocks.identityResolver, mocks.syncPoints, mocks.transportWriter, 1*time.Minute)
+	tp := NewTransactionFlow(ctx, transaction, tktypes.RandHex(16), mocks.allComponents, mocks.domainSmartContract, mocks.publisher, mocks.endorsementGatherer, mocks.identityResolver, mocks.syncPoints, mocks.transportWriter, mocks.inputStateClaims, 1*time.Minute, 0, 1*time.Minute, 0, retry.NewRetryLimited(&pldconf.PrivateTxManagerDefaults.Sequencer.ContentionRetry), 0, 0, pldconf.EndorsementRevertPolicy(*pldconf.PrivateTxManagerDefaults.Sequencer.EndorsementRevertPolicy), pldconf.FlushFailurePolicy(*pldconf.PrivateTxManagerDefaults.Sequencer.FlushFailurePolicy))
 
 	return tp.(*transactionFlow), mocks
 }
 
+// validateAssembly runs an action that requests the validation of the assembly by the domain, and applies the
+// result as the sequencer would on receiving the event that is published with it
+func validateAssembly(ctx context.Context, t *testing.T, tp *transactionFlow, mocks *transactionProcessorDepencyMocks) {
+	validated := make(chan *ptmgrtypes.TransactionAssemblyValidatedEvent, 1)
+	mocks.publisher.On("PublishTransactionAssemblyValidatedEvent", mock.Anything, tp.transaction.ID.String(), mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		validated <- &ptmgrtypes.TransactionAssemblyValidatedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: args.String(1)},
+			ValidationID:                args.String(2),
+			AttestationPlan:             args.Get(3).([]*prototk.AttestationRequest),
+			Error:                       args.String(4),
+		}
+	}).Once()
+	tp.Action(ctx)
+	select {
+	case event := <-validated:
+		tp.ApplyEvent(ctx, event)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "assembly was not validated")
+	}
+}
+
 func TestHasOutstandingEndorsementRequestsMultipleRequestsIncomplete(t *testing.T) {
 	// When there is an attestation plan with multiple AttestationRequest
 	// but not enough AttestationResult
@@ -645,6 +682,91 @@ func TestHasOutstandingEndorsementRequestSingleRequestMultiplePartiesCompleteMix
 	assert.False(t, result)
 }
 
+func TestEndorsementPolicyPerContract(t *testing.T) {
+	// Two contracts of the same domain, with the same attestation plan, can
+	// require different endorsements based on the policy in their contract config
+
+	ctx := context.Background()
+	aliceIdentityLocator := "alice@node1"
+	bobIdentityLocator := "bob@node2"
+	carolIdentityLocator := "carol@node3"
+
+	endorsement := func(party string) *prototk.AttestationResult {
+		return &prototk.AttestationResult{
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Verifier: &prototk.ResolvedVerifier{
+				Lookup:       party,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				Verifier:     tktypes.RandAddress().String(),
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+			Payload: tktypes.RandBytes(32),
+		}
+	}
+	newFlowForContract := func(contractConfig *prototk.ContractConfig, parties ...string) *transactionFlow {
+		testTx := &components.PrivateTransaction{
+			ID:          uuid.New(),
+			PreAssembly: &components.TransactionPreAssembly{},
+			PostAssembly: &components.TransactionPostAssembly{
+				AttestationPlan: []*prototk.AttestationRequest{
+					{
+						Name:            "foo",
+						AttestationType: prototk.AttestationType_ENDORSE,
+						Algorithm:       algorithms.ECDSA_SECP256K1,
+						VerifierType:    verifiers.ETH_ADDRESS,
+						PayloadType:     signpayloads.OPAQUE_TO_RSV,
+						Parties:         parties,
+					},
+				},
+			},
+		}
+		tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+		psc := componentmocks.NewDomainSmartContract(t)
+		psc.On("ContractConfig").Return(contractConfig)
+		tp.domainAPI = psc
+		return tp
+	}
+
+	// contract1 requires 2 of the 3 parties to endorse
+	tp1 := newFlowForContract(&prototk.ContractConfig{
+		EndorsementPolicy:    prototk.ContractConfig_ENDORSEMENT_THRESHOLD,
+		EndorsementThreshold: confutil.P(int32(2)),
+	}, aliceIdentityLocator, bobIdentityLocator, carolIdentityLocator)
+	require.NoError(t, tp1.validateEndorsementPolicy(ctx))
+	// contract2 only requires the notary to endorse, so a plan naming several parties cannot be satisfied
+	notaryConfig := &prototk.ContractConfig{
+		EndorsementPolicy: prototk.ContractConfig_ENDORSEMENT_NOTARY,
+	}
+	err := newFlowForContract(notaryConfig, aliceIdentityLocator, bobIdentityLocator, carolIdentityLocator).validateEndorsementPolicy(ctx)
+	assert.Regexp(t, "PD011862.*'foo'.*3", err)
+	tp2 := newFlowForContract(notaryConfig, aliceIdentityLocator)
+	require.NoError(t, tp2.validateEndorsementPolicy(ctx))
+
+	outstanding := tp1.outstandingEndorsementRequests(ctx)
+	assert.Len(t, outstanding, 3)
+	outstanding = tp2.outstandingEndorsementRequests(ctx)
+	require.Len(t, outstanding, 1)
+	assert.Equal(t, aliceIdentityLocator, outstanding[0].party)
+
+	// bob endorses both - neither is complete
+	tp1.transaction.PostAssembly.Endorsements = append(tp1.transaction.PostAssembly.Endorsements, endorsement(bobIdentityLocator))
+	tp2.transaction.PostAssembly.Endorsements = append(tp2.transaction.PostAssembly.Endorsements, endorsement(bobIdentityLocator))
+	outstanding = tp1.outstandingEndorsementRequests(ctx)
+	require.Len(t, outstanding, 2)
+	assert.Equal(t, aliceIdentityLocator, outstanding[0].party)
+	assert.Equal(t, carolIdentityLocator, outstanding[1].party)
+	assert.True(t, tp2.hasOutstandingEndorsementRequests(ctx))
+
+	// carol endorses contract1, which meets the threshold
+	tp1.transaction.PostAssembly.Endorsements = append(tp1.transaction.PostAssembly.Endorsements, endorsement(carolIdentityLocator))
+	assert.False(t, tp1.hasOutstandingEndorsementRequests(ctx))
+
+	// alice (the notary) endorses contract2, which is then complete
+	tp2.transaction.PostAssembly.Endorsements = append(tp2.transaction.PostAssembly.Endorsements, endorsement(aliceIdentityLocator))
+	assert.False(t, tp2.hasOutstandingEndorsementRequests(ctx))
+}
+
 func TestRequestEndorsements(t *testing.T) {
 	ctx := context.Background()
 	newTxID := uuid.New()
@@ -715,53 +837,251 @@ func TestRequestEndorsements(t *testing.T) {
 	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
 	mocks.transportWriter.On("SendEndorsementRequest",
 		mock.Anything,
-		"alice@node1",
 		"node1",
 		testContractAddress.String(),
 		newTxID.String(),
-		mock.Anything, //attRequest
+		endorsementRequestsFor("alice@node1"),
 		mock.Anything, //TransactionSpecification,
 		mock.Anything, //Verifiers,
 		mock.Anything, //Signatures,
+		mock.Anything, //Endorsements,
 		mock.Anything, //InputStates,
 		mock.Anything, //OutputStates,
 		mock.Anything, //InfoStates,
 	).Return(nil).Once()
+	// bob and carol are both on node2, so are sent a single request
 	mocks.transportWriter.On("SendEndorsementRequest",
 		mock.Anything,
-		"bob@node2",
 		"node2",
 		testContractAddress.String(),
 		newTxID.String(),
-		mock.Anything, //attRequest
+		endorsementRequestsFor("bob@node2", "carol@node2"),
 		mock.Anything, //TransactionSpecification,
 		mock.Anything, //Verifiers,
 		mock.Anything, //Signatures,
+		mock.Anything, //Endorsements,
 		mock.Anything, //InputStates,
 		mock.Anything, //OutputStates,
 		mock.Anything, //InfoStates,
 	).Return(nil).Once()
+	validateAssembly(ctx, t, tp, mocks)
+	tp.Action(ctx)
+
+	mocks.transportWriter.AssertExpectations(t)
+
+	//Check that we don't send the same requests again (we specified Once in the mocks above)
+	tp.Action(ctx)
+
+}
+
+func TestRequestEndorsementsSequential(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+
+	aliceIdentityLocator := "alice@node1"
+	aliceVerifier := tktypes.RandAddress().String()
+	bobIdentityLocator := "bob@node2"
+	bobVerifier := tktypes.RandAddress().String()
+	carolIdentityLocator := "carol@node2"
+	carolVerifier := tktypes.RandAddress().String()
+
+	testContractAddress := *tktypes.RandAddress()
+	testTx := &components.PrivateTransaction{
+		ID: newTxID,
+		Inputs: &components.TransactionInputs{
+			To:   testContractAddress,
+			From: aliceIdentityLocator,
+		},
+		PreAssembly: &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{
+				From:          aliceIdentityLocator,
+				TransactionId: newTxID.String(),
+			},
+			Verifiers: []*prototk.ResolvedVerifier{
+				{Lookup: aliceIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: aliceVerifier},
+				{Lookup: bobIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: bobVerifier},
+				{Lookup: carolIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: carolVerifier},
+			},
+		},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "foo",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties: []string{
+						aliceIdentityLocator,
+						bobIdentityLocator,
+						carolIdentityLocator,
+					},
+				},
+			},
+		},
+	}
+
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	// swap in a domain that is configured for sequential endorsement
+	sequentialDomain := componentmocks.NewDomain(t)
+	sequentialDomain.On("SequentialEndorsement").Return(true)
+	psc := componentmocks.NewDomainSmartContract(t)
+	psc.On("Domain").Return(sequentialDomain)
+	psc.On("ContractConfig").Return(&prototk.ContractConfig{}).Maybe()
+	psc.On("ValidateAssembled", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	psc.On("BuildAttestationPayloads", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	tp.domainAPI = psc
+
+	endorsementFrom := func(lookup, verifier string) *prototk.AttestationResult {
+		return &prototk.AttestationResult{
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Verifier: &prototk.ResolvedVerifier{
+				Lookup:       lookup,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				Verifier:     verifier,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		}
+	}
+	endorsed := func(endorsement *prototk.AttestationResult) {
+		tp.applyTransactionEndorsedEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+				TransactionID:   newTxID.String(),
+				ContractAddress: testContractAddress.String(),
+			},
+			Endorsement: endorsement,
+		})
+	}
+	expectEndorsementRequest := func(party, node string, priorEndorsers ...string) {
+		mocks.transportWriter.On("SendEndorsementRequest",
+			mock.Anything,
+			node,
+			testContractAddress.String(),
+			newTxID.String(),
+			endorsementRequestsFor(party),
+			mock.Anything, //TransactionSpecification,
+			mock.Anything, //Verifiers,
+			mock.Anything, //Signatures,
+			mock.MatchedBy(func(endorsements []*prototk.AttestationResult) bool {
+				if len(endorsements) != len(priorEndorsers) {
+					return false
+				}
+				for i, e := range endorsements {
+					if e.Verifier.Lookup != priorEndorsers[i] {
+						return false
+					}
+				}
+				return true
+			}),
+			mock.Anything, //InputStates,
+			mock.Anything, //OutputStates,
+			mock.Anything, //InfoStates,
+		).Return(nil).Once()
+	}
+
+	// Only the first endorser is asked, with no prior endorsements
+	expectEndorsementRequest(aliceIdentityLocator, "node1")
+	validateAssembly(ctx, t, tp, mocks)
+	tp.Action(ctx)
+	mocks.transportWriter.AssertExpectations(t)
+
+	// Nothing more is sent until alice responds
+	tp.Action(ctx)
+
+	// Bob is asked next, and sees alice's endorsement
+	endorsed(endorsementFrom(aliceIdentityLocator, aliceVerifier))
+	expectEndorsementRequest(bobIdentityLocator, "node2", aliceIdentityLocator)
+	tp.Action(ctx)
+	mocks.transportWriter.AssertExpectations(t)
+
+	// Carol is asked last, and sees both prior endorsements
+	endorsed(endorsementFrom(bobIdentityLocator, bobVerifier))
+	expectEndorsementRequest(carolIdentityLocator, "node2", aliceIdentityLocator, bobIdentityLocator)
+	tp.Action(ctx)
+	mocks.transportWriter.AssertExpectations(t)
+}
+
+func TestRequestEndorsementsBuiltPayload(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+
+	aliceIdentityLocator := "alice@node1"
+	aliceVerifier := tktypes.RandAddress().String()
+
+	testContractAddress := *tktypes.RandAddress()
+	testTx := &components.PrivateTransaction{
+		ID: newTxID,
+		Inputs: &components.TransactionInputs{
+			To:   testContractAddress,
+			From: aliceIdentityLocator,
+		},
+		PreAssembly: &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{
+				From:          aliceIdentityLocator,
+				TransactionId: newTxID.String(),
+			},
+			Verifiers: []*prototk.ResolvedVerifier{
+				{Lookup: aliceIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: aliceVerifier},
+			},
+		},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "foo",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					PayloadBuilder:  confutil.P("builder1"),
+					Parties: []string{
+						aliceIdentityLocator,
+					},
+				},
+			},
+		},
+	}
+
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	// swap in a contract whose domain builds the payload
+	domain := componentmocks.NewDomain(t)
+	domain.On("SequentialEndorsement").Return(false)
+	psc := componentmocks.NewDomainSmartContract(t)
+	psc.On("Domain").Return(domain)
+	psc.On("ContractConfig").Return(&prototk.ContractConfig{}).Maybe()
+	psc.On("ValidateAssembled", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	// the payload is built into a copy of the assembly, which the validation event applies to the transaction
+	psc.On("BuildAttestationPayloads", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tx := args.Get(2).(*components.PrivateTransaction)
+		tx.PostAssembly.AttestationPlan[0].Payload = []byte("built payload")
+	}).Return(nil).Once()
+	tp.domainAPI = psc
+
 	mocks.transportWriter.On("SendEndorsementRequest",
 		mock.Anything,
-		"carol@node2",
-		"node2",
+		"node1",
 		testContractAddress.String(),
 		newTxID.String(),
-		mock.Anything, //attRequest
+		mock.MatchedBy(func(requests []*ptmgrtypes.EndorsementRequestParty) bool {
+			return len(requests) == 1 && requests[0].Party == aliceIdentityLocator && string(requests[0].AttestationRequest.Payload) == "built payload"
+		}),
 		mock.Anything, //TransactionSpecification,
 		mock.Anything, //Verifiers,
 		mock.Anything, //Signatures,
+		mock.Anything, //Endorsements,
 		mock.Anything, //InputStates,
 		mock.Anything, //OutputStates,
 		mock.Anything, //InfoStates,
 	).Return(nil).Once()
-	tp.Action(ctx)
 
+	validateAssembly(ctx, t, tp, mocks)
+	tp.Action(ctx)
 	mocks.transportWriter.AssertExpectations(t)
 
-	//Check that we don't send the same requests again (we specified Once in the mocks above)
+	// The payload is only built once for the assembly
 	tp.Action(ctx)
-
 }
 
 func TestTimedOutEndorsementRequest(t *testing.T) {
@@ -843,26 +1163,26 @@ func TestTimedOutEndorsementRequest(t *testing.T) {
 	fakeClock := &fakeClock{timePassed: 0}
 	tp.clock = fakeClock
 
-	expectEndorsementRequest := func(party, node string) {
+	expectEndorsementRequest := func(node string, parties ...string) {
 		mocks.transportWriter.On("SendEndorsementRequest",
 			mock.Anything,
-			party,
 			node,
 			testContractAddress.String(),
 			newTxID.String(),
-			mock.Anything, //attRequest
+			endorsementRequestsFor(parties...),
 			mock.Anything, //TransactionSpecification,
 			mock.Anything, //Verifiers,
 			mock.Anything, //Signatures,
+			mock.Anything, //Endorsements,
 			mock.Anything, //InputStates,
 			mock.Anything, //OutputStates,
 			mock.Anything, //InfoStates,
 		).Return(nil).Once()
 	}
 
-	expectEndorsementRequest("alice@node1", "node1")
-	expectEndorsementRequest("bob@node2", "node2")
-	expectEndorsementRequest("carol@node2", "node2")
+	expectEndorsementRequest("node1", "alice@node1")
+	expectEndorsementRequest("node2", "bob@node2", "carol@node2")
+	validateAssembly(ctx, t, tp, mocks)
 	tp.Action(ctx)
 	mocks.transportWriter.AssertExpectations(t)
 
@@ -871,9 +1191,8 @@ func TestTimedOutEndorsementRequest(t *testing.T) {
 
 	//simulate the passing of time
 	fakeClock.timePassed = 1*time.Minute + 1*time.Second
-	expectEndorsementRequest("alice@node1", "node1")
-	expectEndorsementRequest("bob@node2", "node2")
-	expectEndorsementRequest("carol@node2", "node2")
+	expectEndorsementRequest("node1", "alice@node1")
+	expectEndorsementRequest("node2", "bob@node2", "carol@node2")
 	tp.Action(ctx)
 	mocks.transportWriter.AssertExpectations(t)
 
@@ -887,7 +1206,8 @@ func TestTimedOutEndorsementRequest(t *testing.T) {
 			ContractAddress: testContractAddress.String(),
 		},
 		Endorsement: &prototk.AttestationResult{
-			Name: "foo",
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
 			Verifier: &prototk.ResolvedVerifier{
 				Lookup:       bobIdentityLocator,
 				Algorithm:    algorithms.ECDSA_SECP256K1,
@@ -899,8 +1219,8 @@ func TestTimedOutEndorsementRequest(t *testing.T) {
 
 	//simulate the passing of time
 	fakeClock.timePassed = fakeClock.timePassed + 1*time.Minute + 1*time.Second
-	expectEndorsementRequest("alice@node1", "node1")
-	expectEndorsementRequest("carol@node2", "node2")
+	expectEndorsementRequest("node1", "alice@node1")
+	expectEndorsementRequest("node2", "carol@node2")
 	tp.Action(ctx)
 }
 
@@ -991,26 +1311,26 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 	fakeClock := &fakeClock{timePassed: 0}
 	tp.clock = fakeClock
 
-	expectEndorsementRequest := func(party, node string) {
+	expectEndorsementRequest := func(node string, parties ...string) {
 		mocks.transportWriter.On("SendEndorsementRequest",
 			mock.Anything,
-			party,
 			node,
 			testContractAddress.String(),
 			newTxID.String(),
-			mock.Anything, //attRequest
+			endorsementRequestsFor(parties...),
 			mock.Anything, //TransactionSpecification,
 			mock.Anything, //Verifiers,
 			mock.Anything, //Signatures,
+			mock.Anything, //Endorsements,
 			mock.Anything, //InputStates,
 			mock.Anything, //OutputStates,
 			mock.Anything, //InfoStates,
 		).Return(nil).Once()
 	}
 
-	expectEndorsementRequest("alice@node1", "node1")
-	expectEndorsementRequest("bob@node2", "node2")
-	expectEndorsementRequest("carol@node2", "node2")
+	expectEndorsementRequest("node1", "alice@node1")
+	expectEndorsementRequest("node2", "bob@node2", "carol@node2")
+	validateAssembly(ctx, t, tp, mocks)
 	tp.Action(ctx)
 	mocks.transportWriter.AssertExpectations(t)
 
@@ -1021,7 +1341,8 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 			ContractAddress: testContractAddress.String(),
 		},
 		Endorsement: &prototk.AttestationResult{
-			Name: "foo",
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
 			Verifier: &prototk.ResolvedVerifier{
 				Lookup:       aliceIdentityLocator,
 				Algorithm:    algorithms.ECDSA_SECP256K1,
@@ -1033,8 +1354,7 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 
 	//simulate the passing of time
 	fakeClock.timePassed = 1*time.Minute + 1*time.Second
-	expectEndorsementRequest("bob@node2", "node2")
-	expectEndorsementRequest("carol@node2", "node2")
+	expectEndorsementRequest("node2", "bob@node2", "carol@node2")
 	tp.Action(ctx)
 	mocks.transportWriter.AssertExpectations(t)
 
@@ -1045,7 +1365,8 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 			ContractAddress: testContractAddress.String(),
 		},
 		Endorsement: &prototk.AttestationResult{
-			Name: "foo",
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
 			Verifier: &prototk.ResolvedVerifier{
 				Lookup:       carolIdentityLocator,
 				Algorithm:    algorithms.ECDSA_SECP256K1,
@@ -1060,7 +1381,8 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 			ContractAddress: testContractAddress.String(),
 		},
 		Endorsement: &prototk.AttestationResult{
-			Name: "foo",
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
 			Verifier: &prototk.ResolvedVerifier{
 				Lookup:       carolIdentityLocator,
 				Algorithm:    algorithms.ECDSA_SECP256K1,
@@ -1073,12 +1395,1149 @@ func TestDuplicateEndorsementResponse(t *testing.T) {
 	// no further action because we are still waiting for a response from bob
 	//even though we have received 3 responses, we are not ready for dispatch because 2 of them are duplicates
 	tp.Action(ctx)
+	// and the duplicate is only recorded once
+	require.Len(t, testTx.PostAssembly.Endorsements, 2)
+	assert.Equal(t, aliceIdentityLocator, testTx.PostAssembly.Endorsements[0].Verifier.Lookup)
+	assert.Equal(t, carolIdentityLocator, testTx.PostAssembly.Endorsements[1].Verifier.Lookup)
 }
 
-type fakeClock struct {
-	timePassed time.Duration
+func TestEndorsementRequestRetriesExhausted(t *testing.T) {
+	// alice answers straight away, but bob never answers so his request is re-sent up to the limit, after
+	// which the transaction is reverted
+	ctx := context.Background()
+	newTxID := uuid.New()
+
+	aliceIdentityLocator := "alice@node1"
+	aliceVerifier := tktypes.RandAddress().String()
+	bobIdentityLocator := "bob@node2"
+	bobVerifier := tktypes.RandAddress().String()
+
+	testContractAddress := *tktypes.RandAddress()
+	testTx := &components.PrivateTransaction{
+		ID: newTxID,
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     testContractAddress,
+			From:   aliceIdentityLocator,
+		},
+		PreAssembly: &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{
+				From:          aliceIdentityLocator,
+				TransactionId: newTxID.String(),
+			},
+			Verifiers: []*prototk.ResolvedVerifier{
+				{Lookup: aliceIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: aliceVerifier},
+				{Lookup: bobIdentityLocator, Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: bobVerifier},
+			},
+		},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "foo",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties:         []string{aliceIdentityLocator, bobIdentityLocator},
+				},
+			},
+		},
+	}
+
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.endorsementRequestTimeout = 10 * time.Second
+	tp.endorsementMaxRetries = 2
+	fakeClock := &fakeClock{timePassed: 0}
+	tp.clock = fakeClock
+
+	expectEndorsementRequests := func(party, node string, times int) {
+		mocks.transportWriter.On("SendEndorsementRequest",
+			mock.Anything,
+			node,
+			testContractAddress.String(),
+			newTxID.String(),
+			endorsementRequestsFor(party),
+			mock.Anything, //TransactionSpecification,
+			mock.Anything, //Verifiers,
+			mock.Anything, //Signatures,
+			mock.Anything, //Endorsements,
+			mock.Anything, //InputStates,
+			mock.Anything, //OutputStates,
+			mock.Anything, //InfoStates,
+		).Return(nil).Times(times)
+	}
+	expectEndorsementRequests(aliceIdentityLocator, "node1", 1)
+	expectEndorsementRequests(bobIdentityLocator, "node2", 3)
+
+	validateAssembly(ctx, t, tp, mocks)
+	tp.Action(ctx)
+	tp.applyTransactionEndorsedEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{
+			TransactionID:   newTxID.String(),
+			ContractAddress: testContractAddress.String(),
+		},
+		Endorsement: &prototk.AttestationResult{
+			Name:            "foo",
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Verifier: &prototk.ResolvedVerifier{
+				Lookup:       aliceIdentityLocator,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				Verifier:     aliceVerifier,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	})
+
+	// the original request to bob, and two re-sends
+	for i := 0; i < 3; i++ {
+		tp.Action(ctx)
+		tp.Action(ctx)
+		fakeClock.timePassed += 11 * time.Second
+	}
+	assert.False(t, tp.finalizeRequired)
+
+	// no response to the last re-send either
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, newTxID, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "PD011856.*foo.*bob@node2.*3 attempts.*10s", reason)
+	}), mock.Anything, mock.Anything).Return().Once()
+	tp.Action(ctx)
+	assert.True(t, tp.finalizeRequired)
+	assert.Regexp(t, "PD011856", tp.latestError)
 }
 
-func (f *fakeClock) Now() time.Time {
-	return time.Now().Add(f.timePassed)
+func TestReassemblyResetsEndorsementRequests(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+	testTx := &components.PrivateTransaction{
+		ID: newTxID,
+		Inputs: &components.TransactionInputs{
+			Domain: "domain1",
+			To:     *tktypes.RandAddress(),
+			From:   "alice@node1",
+		},
+		PostAssembly: &components.TransactionPostAssembly{},
+	}
+	tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.requestedEndorsementTimes["foo"] = map[string]time.Time{"bob@node2": time.Now()}
+	tp.requestedEndorsementCounts["foo"] = map[string]int{"bob@node2": 5}
+
+	// the requests of the discarded assembly do not count against the endorsements of the new one
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionInputStateConflictEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: newTxID.String()},
+		StateID:                     "S1",
+		ClaimingTransactionID:       uuid.NewString(),
+	})
+	assert.Nil(t, tp.transaction.PostAssembly)
+	assert.Empty(t, tp.requestedEndorsementTimes)
+	assert.Empty(t, tp.requestedEndorsementCounts)
+}
+
+func TestContentionLostDelegationRetryExhausted(t *testing.T) {
+	// when we have lost a contention bid and the winning node cannot be reached
+	// we retry the delegation with backoff, and fail the transaction once the retries are exhausted
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.contentionRetry = retry.NewRetryLimited(&pldconf.RetryConfigWithMax{
+		RetryConfig: pldconf.RetryConfig{
+			InitialDelay: confutil.P("1ms"),
+			MaxDelay:     confutil.P("1ms"),
+		},
+		MaxAttempts: confutil.P(3),
+	})
+
+	delegationIDs := make(map[string]bool)
+	mocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Run(func(args mock.Arguments) {
+		delegationIDs[args.String(1)] = true
+	}).Return(fmt.Errorf("pop")).Times(3)
+	var errorMessage string
+	mocks.publisher.On("PublishTransactionDelegationFailedEvent", mock.Anything, testTx.ID.String(), mock.Anything).Run(func(args mock.Arguments) {
+		errorMessage = args.String(2)
+	}).Once()
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionContentionLostEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		WinningNode:                 "node2",
+	})
+	assert.False(t, tp.CoordinatingLocally())
+	tp.Action(ctx)
+	assert.Equal(t, "delegating", tp.status)
+	assert.True(t, tp.ContentionDelegating())
+
+	// an action before the retry delay has passed does not send again
+	tp.contentionRetry = retry.NewRetryLimited(&pldconf.RetryConfigWithMax{
+		RetryConfig: pldconf.RetryConfig{
+			InitialDelay: confutil.P("1h"),
+		},
+		MaxAttempts: confutil.P(3),
+	})
+	tp.Action(ctx)
+	mocks.transportWriter.AssertNumberOfCalls(t, "SendDelegationRequest", 1)
+
+	tp.contentionRetry = retry.NewRetryLimited(&pldconf.RetryConfigWithMax{
+		RetryConfig: pldconf.RetryConfig{
+			InitialDelay: confutil.P("1ms"),
+			MaxDelay:     confutil.P("1ms"),
+		},
+		MaxAttempts: confutil.P(3),
+	})
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+		tp.Action(ctx)
+	}
+	mocks.transportWriter.AssertNumberOfCalls(t, "SendDelegationRequest", 3)
+	// every attempt re-sends the same delegation, so the acknowledgement of any of them completes it
+	assert.Len(t, delegationIDs, 1)
+	assert.Regexp(t, "PD011834.*node2.*pop", errorMessage)
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDelegationFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		Error:                       errorMessage,
+	})
+	assert.True(t, tp.finalizeRequired)
+	assert.False(t, tp.ContentionDelegating())
+	assert.Equal(t, errorMessage, tp.finalizeRevertReason)
+}
+
+func TestContentionLostDelegationNotAcknowledged(t *testing.T) {
+	// a delegation that is sent but never acknowledged by the winner fails once the retries are exhausted
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.contentionRetry = retry.NewRetryLimited(&pldconf.RetryConfigWithMax{
+		RetryConfig: pldconf.RetryConfig{
+			InitialDelay: confutil.P("1ms"),
+			MaxDelay:     confutil.P("1ms"),
+		},
+		MaxAttempts: confutil.P(2),
+	})
+
+	mocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Return(nil).Twice()
+	var errorMessage string
+	mocks.publisher.On("PublishTransactionDelegationFailedEvent", mock.Anything, testTx.ID.String(), mock.Anything).Run(func(args mock.Arguments) {
+		errorMessage = args.String(2)
+	}).Once()
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionContentionLostEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		WinningNode:                 "node2",
+	})
+	for i := 0; i < 3; i++ {
+		tp.Action(ctx)
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.Regexp(t, "PD011834.*node2.*PD011863", errorMessage)
+}
+
+func TestContentionLostDelegationAcknowledged(t *testing.T) {
+	// the delegation to the contention winner completes only when the winner acknowledges it
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	var delegationID string
+	mocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node2", testTx).Run(func(args mock.Arguments) {
+		delegationID = args.String(1)
+	}).Return(nil).Once()
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionContentionLostEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		WinningNode:                 "node2",
+	})
+	tp.Action(ctx)
+	assert.True(t, tp.ContentionDelegating())
+
+	// an acknowledgement of some other delegation is ignored
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDelegationAcknowledgedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		DelegationID:                uuid.NewString(),
+	})
+	tp.Action(ctx)
+	assert.True(t, tp.ContentionDelegating())
+
+	txManager := componentmocks.NewTXManager(t)
+	mocks.allComponents.On("TxManager").Return(txManager)
+	txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, "node2", []uuid.UUID{testTx.ID}).Return(nil).Once()
+	mocks.publisher.On("PublishTransactionDelegatedEvent", mock.Anything, testTx.ID.String()).Once()
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDelegationAcknowledgedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		DelegationID:                delegationID,
+	})
+	tp.Action(ctx)
+	assert.False(t, tp.ContentionDelegating())
+
+	// further actions do not delegate again
+	tp.Action(ctx)
+}
+
+func TestStaticCoordinator(t *testing.T) {
+	// with a static coordinator, the transaction is always delegated to the node of the coordinator,
+	// whoever endorses it, unless that is this node
+	ctx := context.Background()
+
+	newFlowForCoordinator := func(staticCoordinator *string) (*transactionFlow, *transactionProcessorDepencyMocks) {
+		testTx := &components.PrivateTransaction{
+			ID:          uuid.New(),
+			Inputs:      &components.TransactionInputs{To: *tktypes.RandAddress()},
+			PreAssembly: &components.TransactionPreAssembly{},
+			PostAssembly: &components.TransactionPostAssembly{
+				AttestationPlan: []*prototk.AttestationRequest{
+					{
+						Name:            "endorsers",
+						AttestationType: prototk.AttestationType_ENDORSE,
+						Parties:         []string{"alice@node1", "bob@node2"},
+					},
+				},
+			},
+		}
+		tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+		tp.nodeID = "node1"
+		psc := componentmocks.NewDomainSmartContract(t)
+		psc.On("ContractConfig").Return(&prototk.ContractConfig{
+			CoordinatorSelection: prototk.ContractConfig_COORDINATOR_STATIC,
+			StaticCoordinator:    staticCoordinator,
+		})
+		tp.domainAPI = psc
+		return tp, mocks
+	}
+
+	// remote coordinator
+	tp, mocks := newFlowForCoordinator(confutil.P("coordinator@node3"))
+	txManager := componentmocks.NewTXManager(t)
+	mocks.allComponents.On("TxManager").Return(txManager)
+	txManager.On("SetTransactionCoordinator", mock.Anything, mock.Anything, "node3", []uuid.UUID{tp.transaction.ID}).Return(nil).Once()
+	mocks.transportWriter.On("SendDelegationRequest", mock.Anything, mock.Anything, "node3", tp.transaction).Return(nil).Once()
+	assert.True(t, tp.delegateIfRequired(ctx))
+	assert.False(t, tp.CoordinatingLocally())
+	assert.Equal(t, "delegating", tp.status)
+
+	// the coordinator is on this node, so no delegation is sent
+	tp, _ = newFlowForCoordinator(confutil.P("coordinator@node1"))
+	assert.True(t, tp.delegateIfRequired(ctx))
+	assert.True(t, tp.CoordinatingLocally())
+	assert.NotEqual(t, "delegating", tp.status)
+
+	// no coordinator was provided by the domain, which is the default for domains that do not choose, so it is coordinated locally
+	tp, _ = newFlowForCoordinator(nil)
+	assert.True(t, tp.delegateIfRequired(ctx))
+	assert.True(t, tp.CoordinatingLocally())
+
+	// the coordinator cannot be parsed, so the transaction must not be coordinated here in its place
+	tp, _ = newFlowForCoordinator(confutil.P("coordinator@node3@node4"))
+	assert.False(t, tp.delegateIfRequired(ctx))
+	assert.True(t, tp.CoordinatingLocally())
+	assert.Regexp(t, "PD011801", tp.latestError)
+}
+
+func TestSignatureRequestTimeout(t *testing.T) {
+	// a signer that does not respond within the signing timeout must not block
+	// attestation gathering, and the signature is requested again on the next evaluation
+	ctx := context.Background()
+	signRequest := &prototk.AttestationRequest{
+		Name:            "sign",
+		AttestationType: prototk.AttestationType_SIGN,
+		Algorithm:       algorithms.ECDSA_SECP256K1,
+		VerifierType:    verifiers.ETH_ADDRESS,
+		PayloadType:     signpayloads.OPAQUE_TO_RSV,
+		Payload:         []byte("some-payload"),
+		Parties:         []string{"alice"},
+	}
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{signRequest},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.signingTimeout = 10 * time.Millisecond
+
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{
+			KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{Identifier: "alice"}},
+			Verifier:           &pldapi.KeyVerifier{Verifier: "alice-verifier"},
+		}, nil)
+	slowSigner := make(chan struct{})
+	defer close(slowSigner)
+	mocks.keyManager.On("Sign", mock.Anything, mock.Anything, signpayloads.OPAQUE_TO_RSV, []byte("some-payload")).
+		Run(func(args mock.Arguments) {
+			<-slowSigner
+		}).Return(nil, nil)
+	failed := make(chan string, 1)
+	mocks.publisher.On("PublishTransactionSignFailedEvent", mock.Anything, testTx.ID.String(), mock.Anything).Run(func(args mock.Arguments) {
+		failed <- args.String(2)
+	}).Once()
+
+	tp.requestSignatures(ctx)
+	assert.True(t, tp.requestedSignatures)
+
+	errorMessage := <-failed
+	assert.Regexp(t, "PD011836.*10ms.*alice.*alice-verifier", errorMessage)
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionSignFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		Error:                       errorMessage,
+	})
+	assert.False(t, tp.requestedSignatures)
+	assert.Equal(t, errorMessage, tp.latestError)
+	assert.Empty(t, testTx.PostAssembly.Signatures)
+}
+
+func TestSignatureLargePayloadHashed(t *testing.T) {
+	// payloads above the signing hash threshold are hashed by the signer, and the
+	// resulting signature records the hashed payload type for verification
+	ctx := context.Background()
+	largePayload := make([]byte, 100)
+	signRequest := &prototk.AttestationRequest{
+		Name:            "sign",
+		AttestationType: prototk.AttestationType_SIGN,
+		Algorithm:       algorithms.ECDSA_SECP256K1,
+		VerifierType:    verifiers.ETH_ADDRESS,
+		PayloadType:     signpayloads.OPAQUE_TO_RSV,
+		Payload:         largePayload,
+		Parties:         []string{"alice"},
+	}
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{signRequest},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.signingHashThreshold = 64
+
+	mocks.keyManager.On("ResolveKeyNewDatabaseTX", mock.Anything, "alice", algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS).
+		Return(&pldapi.KeyMappingAndVerifier{
+			KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{Identifier: "alice"}},
+			Verifier:           &pldapi.KeyVerifier{Verifier: "alice-verifier"},
+		}, nil)
+	mocks.keyManager.On("Sign", mock.Anything, mock.Anything, signpayloads.OPAQUE_KECCAK256_TO_RSV, largePayload).
+		Return([]byte("signature"), nil).Once()
+	signed := make(chan *prototk.AttestationResult, 1)
+	mocks.publisher.On("PublishTransactionSignedEvent", mock.Anything, testTx.ID.String(), mock.Anything).Run(func(args mock.Arguments) {
+		signed <- args.Get(2).(*prototk.AttestationResult)
+	}).Once()
+
+	tp.requestSignatures(ctx)
+	result := <-signed
+	assert.Equal(t, []byte("signature"), result.Payload)
+	assert.Equal(t, signpayloads.OPAQUE_KECCAK256_TO_RSV, *result.PayloadType)
+
+	// payloads within the threshold are signed as requested
+	assert.Equal(t, signpayloads.OPAQUE_TO_RSV, tp.signingPayloadType(&prototk.AttestationRequest{
+		PayloadType: signpayloads.OPAQUE_TO_RSV,
+		Payload:     largePayload[:64],
+	}))
+}
+
+type fakeClock struct {
+	timePassed time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	return time.Now().Add(f.timePassed)
+}
+
+func TestAssemblyValidatedAfterReassembly(t *testing.T) {
+	// the validation of an assembly that was discarded while the domain was validating it is ignored,
+	// and the new assembly is validated afresh
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	validated := make(chan string, 1)
+	mocks.publisher.On("PublishTransactionAssemblyValidatedEvent", mock.Anything, testTx.ID.String(), mock.Anything, mock.Anything, "").Run(func(args mock.Arguments) {
+		validated <- args.String(2)
+	}).Once()
+	tp.requestAssemblyValidation(ctx)
+	staleValidationID := <-validated
+
+	tp.discardAssembly()
+	testTx.PostAssembly = &components.TransactionPostAssembly{
+		AttestationPlan: []*prototk.AttestationRequest{},
+	}
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionAssemblyValidatedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		ValidationID:                staleValidationID,
+	})
+	assert.False(t, tp.assemblyValidated)
+
+	validateAssembly(ctx, t, tp, mocks)
+	assert.True(t, tp.assemblyValidated)
+	assert.Empty(t, tp.assemblyValidationID)
+}
+
+func TestDependencyFailedOrMissing(t *testing.T) {
+	// a transaction declaring a dependency that failed, or does not exist, is reverted
+	// immediately rather than waiting for a dependency that can never complete
+	ctx := context.Background()
+	failedDep := uuid.New()
+	missingDep := uuid.New()
+	pendingDep := uuid.New()
+
+	txMgr := componentmocks.NewTXManager(t)
+	txMgr.On("GetTransactionReceiptByID", mock.Anything, failedDep).Return(&pldapi.TransactionReceipt{
+		ID: failedDep,
+		TransactionReceiptData: pldapi.TransactionReceiptData{
+			Success:        false,
+			FailureMessage: "cancelled",
+		},
+	}, nil)
+	txMgr.On("GetTransactionReceiptByID", mock.Anything, missingDep).Return(nil, nil)
+	txMgr.On("GetTransactionByID", mock.Anything, missingDep).Return(nil, nil)
+	txMgr.On("GetTransactionReceiptByID", mock.Anything, pendingDep).Return(nil, nil)
+	txMgr.On("GetTransactionByID", mock.Anything, pendingDep).Return(&pldapi.Transaction{ID: &pendingDep}, nil)
+
+	for dep, expectedError := range map[uuid.UUID]string{
+		failedDep:  "PD011837.*" + failedDep.String() + ".*cancelled",
+		missingDep: "PD011838.*" + missingDep.String(),
+	} {
+		testTx := &components.PrivateTransaction{
+			ID:          uuid.New(),
+			Inputs:      &components.TransactionInputs{Domain: "domain1"},
+			PreAssembly: &components.TransactionPreAssembly{},
+			DependsOn:   []uuid.UUID{pendingDep, dep},
+		}
+		tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+		mocks.allComponents.On("TxManager").Return(txMgr)
+		mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.MatchedBy(func(reason string) bool {
+			return assert.Regexp(t, expectedError, reason)
+		}), mock.Anything, mock.Anything).Return().Once()
+
+		tp.Action(ctx)
+		assert.True(t, tp.finalizeRequired)
+		assert.True(t, tp.finalizePending)
+		assert.Regexp(t, expectedError, tp.latestError)
+	}
+
+	// the dependencies are only checked once for each assembly
+	txMgr = componentmocks.NewTXManager(t)
+	txMgr.On("GetTransactionReceiptByID", mock.Anything, pendingDep).Return(nil, nil).Once()
+	txMgr.On("GetTransactionByID", mock.Anything, pendingDep).Return(&pldapi.Transaction{ID: &pendingDep}, nil).Once()
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, &components.PrivateTransaction{
+		ID:        uuid.New(),
+		DependsOn: []uuid.UUID{pendingDep},
+	})
+	mocks.allComponents.On("TxManager").Return(txMgr)
+	assert.True(t, tp.checkDependencies(ctx))
+	assert.True(t, tp.checkDependencies(ctx))
+	assert.False(t, tp.finalizeRequired)
+	assert.True(t, tp.dependenciesChecked)
+}
+
+func TestVerifierRequestedDuringAssemblyResolvedBeforeEndorsement(t *testing.T) {
+	ctx := context.Background()
+	newTxID := uuid.New()
+
+	aliceIdentityLocator := "alice@node1"
+	bobIdentityLocator := "bob@node2"
+	bobVerifier := tktypes.RandAddress().String()
+	testContractAddress := *tktypes.RandAddress()
+
+	// bob was not known to be needed until the transaction was assembled
+	testTx := &components.PrivateTransaction{
+		ID: newTxID,
+		Inputs: &components.TransactionInputs{
+			To:   testContractAddress,
+			From: aliceIdentityLocator,
+		},
+		PreAssembly: &components.TransactionPreAssembly{
+			TransactionSpecification: &prototk.TransactionSpecification{
+				From:          aliceIdentityLocator,
+				TransactionId: newTxID.String(),
+			},
+			Verifiers: []*prototk.ResolvedVerifier{
+				{
+					Lookup:       aliceIdentityLocator,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+					Verifier:     tktypes.RandAddress().String(),
+				},
+			},
+		},
+		PostAssembly: &components.TransactionPostAssembly{
+			RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+				{
+					Lookup:       bobIdentityLocator,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "foo",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties: []string{
+						aliceIdentityLocator,
+						bobIdentityLocator,
+					},
+				},
+			},
+		},
+	}
+
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	var resolved func(ctx context.Context, verifier string)
+	mocks.identityResolver.On("ResolveVerifierAsync", mock.Anything, bobIdentityLocator, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			resolved = args.Get(4).(func(ctx context.Context, verifier string))
+		}).Once()
+
+	// No endorsements are requested while bob's verifier is outstanding (the transport writer mock would fail)
+	tp.Action(ctx)
+	require.NotNil(t, resolved)
+	tp.Action(ctx)
+
+	mocks.publisher.On("PublishResolveVerifierResponseEvent", mock.Anything, newTxID.String(), bobIdentityLocator, algorithms.ECDSA_SECP256K1, bobVerifier, verifiers.ETH_ADDRESS).
+		Run(func(args mock.Arguments) {
+			tp.ApplyEvent(ctx, &ptmgrtypes.ResolveVerifierResponseEvent{
+				PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: newTxID.String()},
+				Lookup:                      confutil.P(bobIdentityLocator),
+				Algorithm:                   confutil.P(algorithms.ECDSA_SECP256K1),
+				Verifier:                    confutil.P(bobVerifier),
+				VerifierType:                confutil.P(verifiers.ETH_ADDRESS),
+			})
+		}).Once()
+	resolved(ctx, bobVerifier)
+	validateAssembly(ctx, t, tp, mocks)
+
+	// Once resolved, endorsement proceeds with bob's verifier available to the endorsers
+	for _, party := range []string{aliceIdentityLocator, bobIdentityLocator} {
+		node, _ := tktypes.PrivateIdentityLocator(party).Node(ctx, false)
+		mocks.transportWriter.On("SendEndorsementRequest",
+			mock.Anything,
+			node,
+			testContractAddress.String(),
+			newTxID.String(),
+			endorsementRequestsFor(party),
+			mock.Anything, //TransactionSpecification,
+			mock.MatchedBy(func(verifiers []*prototk.ResolvedVerifier) bool {
+				return len(verifiers) == 2 && verifiers[1].Verifier == bobVerifier
+			}),
+			mock.Anything, //Signatures,
+			mock.Anything, //Endorsements,
+			mock.Anything, //InputStates,
+			mock.Anything, //OutputStates,
+			mock.Anything, //InfoStates,
+		).Return(nil).Once()
+	}
+	tp.Action(ctx)
+	mocks.transportWriter.AssertExpectations(t)
+}
+
+func TestRetryBudgetExhaustedAcrossStages(t *testing.T) {
+	// each stage only retries a couple of times, but the retries add up across the lifecycle of
+	// the transaction so it is failed once the overall budget is used up, whichever stage it is in
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		Inputs:      &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.maxRetries = 3
+	tp.endorsementRevertPolicy = pldconf.EndorsementRevertPolicyReassemble
+
+	signFailed := &ptmgrtypes.TransactionSignFailedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		Error:                       "sign failed",
+	}
+	tp.requestedSignatures = true
+	tp.ApplyEvent(ctx, signFailed)
+	assert.False(t, tp.requestedSignatures)
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		RevertReason:                confutil.P("endorsement rejected"),
+	})
+	assert.Nil(t, testTx.PostAssembly)
+	testTx.PostAssembly = &components.TransactionPostAssembly{}
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.ResolveVerifierErrorEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+		Lookup:                      confutil.P("alice"),
+		Algorithm:                   confutil.P(algorithms.ECDSA_SECP256K1),
+		ErrorMessage:                confutil.P("resolve failed"),
+	})
+	assert.Nil(t, testTx.PostAssembly)
+	testTx.PostAssembly = &components.TransactionPostAssembly{}
+	assert.False(t, tp.finalizeRequired)
+
+	// the next retry, in any stage, exceeds the budget
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "PD011843.*sign.*4.*sign failed", reason)
+	}), mock.Anything, mock.Anything).Return().Once()
+	tp.requestedSignatures = true
+	tp.ApplyEvent(ctx, signFailed)
+	assert.True(t, tp.requestedSignatures)
+	assert.True(t, tp.finalizeRequired)
+	assert.True(t, tp.finalizePending)
+	assert.Regexp(t, "PD011843", tp.latestError)
+
+	// and nothing further is attempted while the failure is finalized
+	tp.Action(ctx)
+}
+
+func TestDispatchFailedPolicy(t *testing.T) {
+	ctx := context.Background()
+	newTx := func() *components.PrivateTransaction {
+		return &components.PrivateTransaction{
+			ID:           uuid.New(),
+			Inputs:       &components.TransactionInputs{Domain: "domain1"},
+			PreAssembly:  &components.TransactionPreAssembly{},
+			PostAssembly: &components.TransactionPostAssembly{},
+		}
+	}
+	dispatchFailed := func(tp *transactionFlow) {
+		tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDispatchFailedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tp.transaction.ID.String()},
+			Error:                       "pop",
+		})
+	}
+
+	// by default the transaction is failed
+	testTx := newTx()
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.readyForSequencing = true
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "PD011858.*pop", reason)
+	}), mock.Anything, mock.Anything).Return().Once()
+	dispatchFailed(tp)
+	assert.False(t, tp.readyForSequencing)
+	assert.True(t, tp.finalizePending)
+
+	// or it is re-assembled
+	testTx = newTx()
+	tp, _ = newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.flushFailurePolicy = pldconf.FlushFailurePolicyReassemble
+	tp.readyForSequencing = true
+	tp.assemblyValidated = true
+	dispatchFailed(tp)
+	assert.False(t, tp.readyForSequencing)
+	assert.False(t, tp.finalizeRequired)
+	assert.False(t, tp.assemblyValidated)
+	assert.Nil(t, testTx.PostAssembly)
+	assert.Regexp(t, "PD011858.*pop", tp.latestError)
+}
+
+func TestRetryBudgetDurationExceeded(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		Inputs:      &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	fakeClock := &fakeClock{timePassed: 0}
+	tp.clock = fakeClock
+	tp.maxRetryDuration = 1 * time.Minute
+
+	assert.True(t, tp.consumeRetry(ctx, "endorse", "timed out"))
+
+	fakeClock.timePassed = 1*time.Minute + 1*time.Second
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.Anything, mock.Anything, mock.Anything).Return().Once()
+	assert.False(t, tp.consumeRetry(ctx, "endorse", "timed out"))
+	assert.True(t, tp.finalizeRequired)
+	assert.Regexp(t, "PD011843.*endorse.*2.*timed out", tp.latestError)
+}
+
+func TestEndorsementNotMatchingAttestationPlan(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		Inputs:      &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "sender",
+					AttestationType: prototk.AttestationType_SIGN,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					Parties:         []string{"alice@node1"},
+				},
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					Parties:         []string{"bob@node2"},
+				},
+			},
+		},
+	}
+	tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	endorse := func(name string, attType prototk.AttestationType, algorithm, lookup string, revertReason *string) {
+		var verifier *prototk.ResolvedVerifier
+		if lookup != "" {
+			verifier = &prototk.ResolvedVerifier{
+				Lookup:       lookup,
+				Algorithm:    algorithm,
+				Verifier:     tktypes.RandAddress().String(),
+				VerifierType: verifiers.ETH_ADDRESS,
+			}
+		}
+		tp.ApplyEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+			Endorsement: &prototk.AttestationResult{
+				Name:            name,
+				AttestationType: attType,
+				Verifier:        verifier,
+			},
+			RevertReason: revertReason,
+		})
+	}
+
+	// none of these correspond to the endorse request in the plan
+	endorse("unrequested", prototk.AttestationType_ENDORSE, algorithms.ECDSA_SECP256K1, "bob@node2", nil)
+	assert.Regexp(t, "PD011845.*unrequested", tp.latestError)
+	endorse("sender", prototk.AttestationType_SIGN, algorithms.ECDSA_SECP256K1, "alice@node1", nil)
+	assert.Regexp(t, "PD011845.*sender", tp.latestError)
+	endorse("notary", prototk.AttestationType_GENERATE_PROOF, algorithms.ECDSA_SECP256K1, "bob@node2", nil)
+	assert.Regexp(t, "PD011845.*GENERATE_PROOF", tp.latestError)
+	endorse("notary", prototk.AttestationType_ENDORSE, "wrong", "bob@node2", nil)
+	assert.Regexp(t, "PD011845.*wrong", tp.latestError)
+	endorse("notary", prototk.AttestationType_ENDORSE, algorithms.ECDSA_SECP256K1, "carol@node2", nil)
+	assert.Regexp(t, "PD011845.*carol@node2", tp.latestError)
+	endorse("notary", prototk.AttestationType_ENDORSE, algorithms.ECDSA_SECP256K1, "", nil)
+	assert.Regexp(t, "PD011846.*verifier", tp.latestError)
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+	})
+	assert.Regexp(t, "PD011846.*attestation result", tp.latestError)
+	assert.Empty(t, testTx.PostAssembly.Endorsements)
+
+	// an unrequested rejection does not cause the transaction to be re-assembled
+	endorse("unrequested", prototk.AttestationType_ENDORSE, algorithms.ECDSA_SECP256K1, "bob@node2", confutil.P("rejected"))
+	require.NotNil(t, testTx.PostAssembly)
+
+	// the requested endorsement is accepted
+	endorse("notary", prototk.AttestationType_ENDORSE, algorithms.ECDSA_SECP256K1, "bob@node2", nil)
+	assert.Len(t, testTx.PostAssembly.Endorsements, 1)
+	assert.True(t, tp.IsEndorsed(ctx))
+
+	// as is a rejection that does not identify the verifier
+	tp.endorsementRevertPolicy = pldconf.EndorsementRevertPolicyReassemble
+	endorse("notary", prototk.AttestationType_ENDORSE, "", "", confutil.P("rejected"))
+	assert.Nil(t, testTx.PostAssembly)
+}
+
+func TestEndorsementRevertFailsTransaction(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		Inputs:      &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "group",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.ETH_ADDRESS,
+					Parties:         []string{"alice@node1", "bob@node2", "carol@node3"},
+				},
+			},
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.endorsementRevertPolicy = pldconf.EndorsementRevertPolicyFail
+
+	endorse := func(lookup string, revertReason *string) {
+		tp.ApplyEvent(ctx, &ptmgrtypes.TransactionEndorsedEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+			Endorsement: &prototk.AttestationResult{
+				Name:            "group",
+				AttestationType: prototk.AttestationType_ENDORSE,
+				Verifier: &prototk.ResolvedVerifier{
+					Lookup:       lookup,
+					Algorithm:    algorithms.ECDSA_SECP256K1,
+					Verifier:     tktypes.RandAddress().String(),
+					VerifierType: verifiers.ETH_ADDRESS,
+				},
+			},
+			RevertReason: revertReason,
+		})
+	}
+
+	endorse("alice@node1", nil)
+	assert.Len(t, testTx.PostAssembly.Endorsements, 1)
+
+	// the transaction is failed with the reason from bob, without waiting for carol
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "PD011848.*group.*bob@node2.*insufficient funds", reason)
+	}), mock.Anything, mock.Anything).Return().Once()
+	endorse("bob@node2", confutil.P("insufficient funds"))
+	assert.True(t, tp.finalizeRequired)
+	assert.True(t, tp.finalizePending)
+	assert.Regexp(t, "PD011848", tp.latestError)
+	assert.NotNil(t, testTx.PostAssembly)
+	assert.Zero(t, tp.retryCount)
+
+	// a further rejection does not finalize the transaction again
+	endorse("carol@node3", confutil.P("also rejected"))
+	assert.Regexp(t, "insufficient funds", tp.finalizeRevertReason)
+}
+
+func TestStageTimings(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		Inputs:      &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{},
+	}
+	tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	fakeClock := &fakeClock{timePassed: 0}
+	tp.clock = fakeClock
+
+	tp.enterStage(ctx, components.PrivateTxStageAssembling)
+	fakeClock.timePassed = 1 * time.Second
+	// re-entering the current stage does not restart it
+	tp.enterStage(ctx, components.PrivateTxStageAssembling)
+	fakeClock.timePassed = 3 * time.Second
+	tp.enterStage(ctx, components.PrivateTxStageEndorsing)
+	fakeClock.timePassed = 10 * time.Second
+
+	s, err := tp.GetTxStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, s.StageTimings, 2)
+	assert.Equal(t, components.PrivateTxStageAssembling, s.StageTimings[0].Stage)
+	require.NotNil(t, s.StageTimings[0].Exited)
+	assert.Regexp(t, `^3\.\d+s$|^3s$`, s.StageTimings[0].Duration)
+	// the current stage is measured up to now
+	assert.Equal(t, components.PrivateTxStageEndorsing, s.StageTimings[1].Stage)
+	assert.Nil(t, s.StageTimings[1].Exited)
+
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDispatchedEvent{
+		PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: testTx.ID.String()},
+	})
+	s, err = tp.GetTxStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, s.StageTimings, 2)
+	assert.NotNil(t, s.StageTimings[1].Exited)
+}
+
+func TestGetTxAttestations(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:     uuid.New(),
+		Inputs: &components.TransactionInputs{Domain: "domain1"},
+		PreAssembly: &components.TransactionPreAssembly{
+			Verifiers: []*prototk.ResolvedVerifier{
+				{Lookup: "alice@node1", Algorithm: "algo1", VerifierType: "vtype1", Verifier: "alice-verifier"},
+				{Lookup: "bob@node2", Algorithm: "algo1", VerifierType: "vtype2", Verifier: "bob-other-verifier"},
+			},
+		},
+	}
+	tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	// Nothing to report until the transaction is assembled
+	a := tp.GetTxAttestations(ctx)
+	assert.Equal(t, testTx.ID.String(), a.TxID)
+	assert.Empty(t, a.Attestations)
+
+	testTx.PostAssembly = &components.TransactionPostAssembly{
+		AttestationPlan: []*prototk.AttestationRequest{
+			{Name: "sender", AttestationType: prototk.AttestationType_SIGN, Algorithm: "algo1", VerifierType: "vtype1", Parties: []string{"alice@node1"}},
+			{Name: "endorsers", AttestationType: prototk.AttestationType_ENDORSE, Algorithm: "algo1", VerifierType: "vtype1", Parties: []string{"alice@node1", "bob@node2", "carol@node3"}},
+		},
+		Signatures: []*prototk.AttestationResult{
+			{Name: "sender", AttestationType: prototk.AttestationType_SIGN, Verifier: &prototk.ResolvedVerifier{Lookup: "alice@node1", VerifierType: "vtype1", Verifier: "alice-verifier"}},
+		},
+		Endorsements: []*prototk.AttestationResult{
+			{Name: "endorsers", AttestationType: prototk.AttestationType_ENDORSE, Verifier: &prototk.ResolvedVerifier{Lookup: "alice@node1", VerifierType: "vtype1", Verifier: "alice-verifier"}},
+			{
+				Name: "endorsers", AttestationType: prototk.AttestationType_ENDORSE, Verifier: &prototk.ResolvedVerifier{Lookup: "bob@node2", VerifierType: "vtype1", Verifier: "bob-verifier"},
+				Constraints: []prototk.AttestationResult_AttestationConstraint{prototk.AttestationResult_ENDORSER_MUST_SUBMIT},
+			},
+		},
+	}
+
+	a = tp.GetTxAttestations(ctx)
+	require.Len(t, a.Attestations, 2)
+	assert.Equal(t, "SIGN", a.Attestations[0].AttestationType)
+	assert.Equal(t, []*components.PrivateTxAttestationParty{
+		{Party: "alice@node1", Verifier: "alice-verifier", Received: true},
+	}, a.Attestations[0].Parties)
+	assert.Equal(t, "ENDORSE", a.Attestations[1].AttestationType)
+	assert.Equal(t, "algo1", a.Attestations[1].Algorithm)
+	assert.Equal(t, "vtype1", a.Attestations[1].VerifierType)
+	assert.Equal(t, []*components.PrivateTxAttestationParty{
+		{Party: "alice@node1", Verifier: "alice-verifier", Received: true, Result: "SIGN"},
+		// the verifier resolved for bob before assembly was of a different type, so the one from the endorsement is reported
+		{Party: "bob@node2", Verifier: "bob-verifier", Received: true, Result: "ENDORSER_SUBMIT"},
+		{Party: "carol@node3", Received: false},
+	}, a.Attestations[1].Parties)
+}
+
+func TestRequestAssembleRecordsLabel(t *testing.T) {
+	ctx := context.Background()
+	testTx := &components.PrivateTransaction{
+		ID:     uuid.New(),
+		Inputs: &components.TransactionInputs{Domain: "domain1", From: "alice"},
+		PreAssembly: &components.TransactionPreAssembly{
+			Label: "Transfer FT1 to org2",
+		},
+	}
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	txManager := componentmocks.NewTXManager(t)
+	mocks.allComponents.On("TxManager").Return(txManager)
+
+	// The domain refines the label once it knows the details of the transaction
+	mocks.domainSmartContract.On("AssembleTransaction", mock.Anything, mock.Anything, testTx).Run(func(args mock.Arguments) {
+		testTx.PreAssembly.Label = "Transfer 23 FT1 to org2"
+		testTx.PostAssembly = &components.TransactionPostAssembly{AssemblyResult: prototk.AssembleTransactionResponse_OK}
+	}).Return(nil)
+	mocks.publisher.On("PublishTransactionAssembledEvent", mock.Anything, testTx.ID.String()).Return()
+	txManager.On("SetTransactionLabel", mock.Anything, mock.Anything, testTx.ID, "Transfer 23 FT1 to org2").Return(fmt.Errorf("pop")).Once()
+
+	// Failing to record the label does not fail the assembly
+	tp.requestAssemble(ctx)
+	mocks.publisher.AssertNumberOfCalls(t, "PublishTransactionAssembledEvent", 1)
+	s, err := tp.GetTxStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Transfer 23 FT1 to org2", s.Label)
+
+	// Re-assembly with the same label does not record it again
+	testTx.PostAssembly = nil
+	tp.requestAssemble(ctx)
+	mocks.publisher.AssertNumberOfCalls(t, "PublishTransactionAssembledEvent", 2)
+}
+
+func TestCancelTransaction(t *testing.T) {
+	ctx := context.Background()
+	newTx := func() *components.PrivateTransaction {
+		return &components.PrivateTransaction{
+			ID:          uuid.New(),
+			Inputs:      &components.TransactionInputs{Domain: "domain1"},
+			PreAssembly: &components.TransactionPreAssembly{},
+		}
+	}
+	cancel := func(tp *transactionFlow) error {
+		event := &ptmgrtypes.TransactionCancelEvent{
+			PrivateTransactionEventBase: ptmgrtypes.PrivateTransactionEventBase{TransactionID: tp.transaction.ID.String()},
+			Result:                      make(chan error, 1),
+		}
+		tp.ApplyEvent(ctx, event)
+		return <-event.Result
+	}
+
+	testTx := newTx()
+	tp, mocks := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+	tp.readyForSequencing = true
+	mocks.syncPoints.On("QueueTransactionFinalize", mock.Anything, "domain1", mock.Anything, testTx.ID, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "PD011853", reason)
+	}), mock.Anything, mock.Anything).Return().Once()
+	require.NoError(t, cancel(tp))
+	assert.Equal(t, "cancelled", tp.status)
+	assert.False(t, tp.readyForSequencing)
+	assert.True(t, tp.finalizePending)
+
+	// cancelling again is a no-op
+	require.NoError(t, cancel(tp))
+
+	tp, _ = newPaladinTransactionProcessorForTesting(t, ctx, newTx())
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDispatchedEvent{})
+	assert.Regexp(t, "PD011849", cancel(tp))
+	assert.Equal(t, "dispatched", tp.status)
+
+	tp, _ = newPaladinTransactionProcessorForTesting(t, ctx, newTx())
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionDelegatedEvent{})
+	assert.Regexp(t, "PD011850", cancel(tp))
+
+	tp, _ = newPaladinTransactionProcessorForTesting(t, ctx, newTx())
+	tp.ApplyEvent(ctx, &ptmgrtypes.TransactionAssembleFailedEvent{Error: "pop"})
+	assert.Regexp(t, "PD011851.*pop", cancel(tp))
+}
+
+func TestValidateEndorsementVerifierTypeMismatch(t *testing.T) {
+	ctx := context.Background()
+	notaryIdentityLocator := "notary@node1"
+	testTx := &components.PrivateTransaction{
+		ID:          uuid.New(),
+		PreAssembly: &components.TransactionPreAssembly{},
+		PostAssembly: &components.TransactionPostAssembly{
+			AttestationPlan: []*prototk.AttestationRequest{
+				{
+					Name:            "notary",
+					AttestationType: prototk.AttestationType_ENDORSE,
+					Algorithm:       algorithms.ECDSA_SECP256K1,
+					VerifierType:    verifiers.HEX_ECDSA_PUBKEY_UNCOMPRESSED,
+					PayloadType:     signpayloads.OPAQUE_TO_RSV,
+					Parties:         []string{notaryIdentityLocator},
+				},
+			},
+		},
+	}
+	tp, _ := newPaladinTransactionProcessorForTesting(t, ctx, testTx)
+
+	endorsement := func(verifierType string) *prototk.AttestationResult {
+		return &prototk.AttestationResult{
+			Name:            "notary",
+			AttestationType: prototk.AttestationType_ENDORSE,
+			Verifier: &prototk.ResolvedVerifier{
+				Lookup:       notaryIdentityLocator,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifierType,
+				Verifier:     tktypes.RandHex(64),
+			},
+		}
+	}
+	require.NoError(t, tp.validateEndorsement(ctx, endorsement(verifiers.HEX_ECDSA_PUBKEY_UNCOMPRESSED), false))
+
+	// The same party and algorithm, but the endorsement was made against a different type of verifier
+	err := tp.validateEndorsement(ctx, endorsement(verifiers.ETH_ADDRESS), false)
+	assert.Regexp(t, "PD011845", err)
+}
+
+// matches the endorsement requests coalesced into a single message for a node, for the given parties in order
+func endorsementRequestsFor(parties ...string) any {
+	return mock.MatchedBy(func(requests []*ptmgrtypes.EndorsementRequestParty) bool {
+		if len(requests) != len(parties) {
+			return false
+		}
+		for i, r := range requests {
+			if r.Party != parties[i] {
+				return false
+			}
+		}
+		return true
+	})
 }
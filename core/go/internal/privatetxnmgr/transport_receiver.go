@@ -44,7 +44,9 @@ func (p *privateTxManager) ReceiveTransportMessage(ctx context.Context, message
 	case "EndorsementResponse":
 		go p.handleEndorsementResponse(ctx, messagePayload)
 	case "DelegationRequest":
-		go p.handleDelegationRequest(ctx, messagePayload)
+		go p.handleDelegationRequest(ctx, messagePayload, replyToDestination)
+	case "DelegationRequestAcknowledgment":
+		go p.handleDelegationRequestAcknowledgment(ctx, messagePayload)
 	default:
 		log.L(ctx).Errorf("Unknown message type: %s", message.MessageType)
 	}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/privatetxnmgr/ptmgrtypes"
 	engineProto "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
@@ -84,11 +85,18 @@ func (tw *transportWriter) SendDelegationRequest(
 }
 
 // TODO do we have duplication here?  contractAddress and transactionID are in the transactionSpecification
-func (tw *transportWriter) SendEndorsementRequest(ctx context.Context, party string, targetNode string, contractAddress string, transactionID string, attRequest *prototk.AttestationRequest, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, inputStates []*components.FullState, outputStates []*components.FullState, infoStates []*components.FullState) error {
-	attRequestAny, err := anypb.New(attRequest)
-	if err != nil {
-		log.L(ctx).Error("Error marshalling attestation request", err)
-		return err
+func (tw *transportWriter) SendEndorsementRequest(ctx context.Context, targetNode string, contractAddress string, transactionID string, requests []*ptmgrtypes.EndorsementRequestParty, transactionSpecification *prototk.TransactionSpecification, verifiers []*prototk.ResolvedVerifier, signatures []*prototk.AttestationResult, endorsements []*prototk.AttestationResult, inputStates []*components.FullState, outputStates []*components.FullState, infoStates []*components.FullState) error {
+	requestsPB := make([]*engineProto.EndorsementRequestParty, len(requests))
+	for i, request := range requests {
+		attRequestAny, err := anypb.New(request.AttestationRequest)
+		if err != nil {
+			log.L(ctx).Error("Error marshalling attestation request", err)
+			return err
+		}
+		requestsPB[i] = &engineProto.EndorsementRequestParty{
+			AttestationRequest: attRequestAny,
+			Party:              request.Party,
+		}
 	}
 
 	transactionSpecificationAny, err := anypb.New(transactionSpecification)
@@ -114,6 +122,15 @@ func (tw *transportWriter) SendEndorsementRequest(ctx context.Context, party str
 		}
 		signaturesAny[i] = signatureAny
 	}
+	endorsementsAny := make([]*anypb.Any, len(endorsements))
+	for i, endorsement := range endorsements {
+		endorsementAny, err := anypb.New(endorsement)
+		if err != nil {
+			log.L(ctx).Error("Error marshalling endorsement", err)
+			return err
+		}
+		endorsementsAny[i] = endorsementAny
+	}
 
 	inputStatesAny := make([]*anypb.Any, len(inputStates))
 	endorseableInputStates := toEndorsableList(inputStates)
@@ -151,15 +168,21 @@ func (tw *transportWriter) SendEndorsementRequest(ctx context.Context, party str
 	endorsementRequest := &engineProto.EndorsementRequest{
 		ContractAddress:          contractAddress,
 		TransactionId:            transactionID,
-		AttestationRequest:       attRequestAny,
-		Party:                    party,
 		TransactionSpecification: transactionSpecificationAny,
 		Verifiers:                verifiersAny,
 		Signatures:               signaturesAny,
+		Endorsements:             endorsementsAny,
 		InputStates:              inputStatesAny,
 		OutputStates:             outputStatesAny,
 		InfoStates:               infoStatesAny,
 	}
+	if len(requestsPB) == 1 {
+		// a request for a single party is sent in the same form as before requests were coalesced
+		endorsementRequest.AttestationRequest = requestsPB[0].AttestationRequest
+		endorsementRequest.Party = requestsPB[0].Party
+	} else {
+		endorsementRequest.Requests = requestsPB
+	}
 
 	endorsementRequestBytes, err := proto.Marshal(endorsementRequest)
 	if err != nil {
@@ -68,6 +68,9 @@ type BalanceManagerWithInMemoryTracking struct {
 	// if set, any top up request with amount required below this threshold won't happen
 	minThreshold *big.Int
 
+	// if set, signing addresses must keep this much of their balance in reserve, and are topped up to cover it
+	minGasReserve *big.Int
+
 	// a map of fueling destination addresses and a mutex to indicate whether it's no longer the first
 	// time the current balance manager instance is handling fueling request to this destination address.
 	// When the mutex is set, balance manager will confidently use the internal trackedFuelingTransactions map
@@ -99,9 +102,12 @@ func (af *BalanceManagerWithInMemoryTracking) TopUpAccount(ctx context.Context,
 	}
 	log.L(ctx).Debugf("Calculate the amount to be topped up for address %+v ; autoFueling config: %+v", addAccount, af)
 
-	if addAccount.Spent.Sign() > 0 && addAccount.Spent.Cmp(addAccount.Balance) > 0 {
-		spentCopy := new(big.Int).Set(addAccount.Spent)
-		topUpAmount := spentCopy.Sub(spentCopy, addAccount.Balance)
+	required := new(big.Int).Set(addAccount.Spent)
+	if addAccount.Reserve != nil {
+		required = required.Add(required, addAccount.Reserve)
+	}
+	if required.Sign() > 0 && required.Cmp(addAccount.Balance) > 0 {
+		topUpAmount := required.Sub(required, addAccount.Balance)
 		if af.proactiveFuelingTransactionTotal > addAccount.SpentTransactionCount {
 			// when we don't have enough (minimum fuel ahead) number of transactions
 			// we use the configured calculation methods to calculate the value for the empty slots to fill
@@ -112,8 +118,12 @@ func (af *BalanceManagerWithInMemoryTracking) TopUpAccount(ctx context.Context,
 			case pldconf.ProactiveAutoFuelingCalcMethodMax:
 				topUpAmount = topUpAmount.Add(topUpAmount, addAccount.MaxCost.Mul(addAccount.MaxCost, extraFillAmountInt))
 			case pldconf.ProactiveAutoFuelingCalcMethodAverage:
+				if addAccount.SpentTransactionCount == 0 {
+					// only the reserve is required, so there is no average cost to fill the slots with
+					break
+				}
 				spentTransactionCountBigInt := big.NewInt(int64(addAccount.SpentTransactionCount))
-				spentCopy = new(big.Int).Set(addAccount.Spent)
+				spentCopy := new(big.Int).Set(addAccount.Spent)
 				avgAmount := spentCopy.Div(spentCopy, spentTransactionCountBigInt)
 				topUpAmount = topUpAmount.Add(topUpAmount, avgAmount.Mul(avgAmount, extraFillAmountInt))
 			}
@@ -153,7 +163,7 @@ func (af *BalanceManagerWithInMemoryTracking) TopUpAccount(ctx context.Context,
 		}
 		log.L(ctx).Debugf("Requesting top up for address %s using calculated amount: %s based on spent: %s", addAccount.Address, topUpAmount.String(), addAccount.Spent.String())
 		// after all the above amount tuning, do a final threshold check if there is one
-		return af.TransferGasFromAutoFuelingSource(ctx, addAccount.Address, topUpAmount)
+		return af.TransferGasFromAutoFuelingSource(ctx, addAccount.Address, addAccount.Balance, topUpAmount)
 	}
 	return nil, nil
 }
@@ -201,10 +211,12 @@ func (af *BalanceManagerWithInMemoryTracking) GetAddressBalance(ctx context.Cont
 		Spent:   big.NewInt(0),
 		MinCost: big.NewInt(0),
 		MaxCost: big.NewInt(0),
+		Reserve: af.minGasReserve,
 	}, nil
 }
 
-func (af *BalanceManagerWithInMemoryTracking) TransferGasFromAutoFuelingSource(ctx context.Context, destAddress tktypes.EthAddress, value *big.Int) (fuelingTx *pldapi.PublicTx, err error) {
+// The destBalance is the balance of the destination address that the value was calculated from
+func (af *BalanceManagerWithInMemoryTracking) TransferGasFromAutoFuelingSource(ctx context.Context, destAddress tktypes.EthAddress, destBalance, value *big.Int) (fuelingTx *pldapi.PublicTx, err error) {
 	// check whether there is a pending fueling transaction already
 	// check whether the current balance manager already tracking the existing in-flight fueling transactions
 	log.L(ctx).Tracef("TransferGasFromAutoFuelingSource entry, source address: %s, destination address: %s, amount: %s", af.sourceAddress, destAddress, value.String())
@@ -273,7 +285,34 @@ func (af *BalanceManagerWithInMemoryTracking) TransferGasFromAutoFuelingSource(c
 
 	// for the situation of the requested value + gas fee is greater than the balance, we only figure this out after the new transaction is executed
 
-	// 2) Perform transaction to transfer value to the dest address
+	// 2) Re-check the destination immediately before submitting, as things might have moved on since we decided to fuel it
+	// (another fueling transaction might have been submitted, or completed and increased the balance)
+	fuelingTx, err = af.pubTxMgr.GetPendingFuelingTransaction(ctx, *af.sourceAddress, destAddress)
+	if err != nil {
+		log.L(ctx).Errorf("TransferGasFromAutoFuelingSource error occurred when re-checking pending fueling tx for address: %s, error: %+v", destAddress, err)
+		return nil, err
+	}
+	if fuelingTx != nil {
+		log.L(ctx).Debugf("TransferGasFromAutoFuelingSource found pending fueling request from=%s nonce=%d for destination address: %s before submitting", fuelingTx.From, fuelingTx.Nonce, destAddress)
+		af.trackedFuelingTransactions[destAddress] = fuelingTx
+		return fuelingTx, nil
+	}
+	af.NotifyAddressBalanceChanged(ctx, destAddress)
+	destAccount, err := af.GetAddressBalance(ctx, destAddress)
+	if err != nil {
+		log.L(ctx).Errorf("TransferGasFromAutoFuelingSource failed to re-check balance of destination: %s", destAddress)
+		return nil, err
+	}
+	if increase := new(big.Int).Sub(destAccount.Balance, destBalance); increase.Sign() > 0 {
+		if increase.Cmp(value) >= 0 {
+			log.L(ctx).Debugf("TransferGasFromAutoFuelingSource skipped fueling destination address: %s as its balance increased from %s to %s, covering the requested amount: %s", destAddress, destBalance.String(), destAccount.Balance.String(), value.String())
+			return nil, nil
+		}
+		value = new(big.Int).Sub(value, increase)
+		log.L(ctx).Debugf("TransferGasFromAutoFuelingSource reduced fueling amount for destination address: %s to %s as its balance increased from %s to %s", destAddress, value.String(), destBalance.String(), destAccount.Balance.String())
+	}
+
+	// 3) Perform transaction to transfer value to the dest address
 
 	log.L(ctx).Debugf("TransferGasFromAutoFuelingSource submitting a fueling tx for  destination address: %s ", destAddress)
 	submission, err := af.pubTxMgr.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
@@ -342,6 +381,7 @@ func NewBalanceManagerWithInMemoryTracking(ctx context.Context, conf *pldconf.Pu
 		minDestBalance:                     minDestBalance,
 		maxDestBalance:                     maxDestBalance,
 		minThreshold:                       minThreshold,
+		minGasReserve:                      confutil.BigIntOrNil(conf.BalanceManager.MinGasReserve),
 		destinationAddressesFuelingTracked: make(map[tktypes.EthAddress]*sync.Mutex),
 		trackedFuelingTransactions:         make(map[tktypes.EthAddress]*pldapi.PublicTx),
 		addressBalanceChangedMap:           make(map[tktypes.EthAddress]bool),
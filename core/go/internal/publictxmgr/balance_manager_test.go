@@ -277,7 +277,15 @@ func generateExpectedFuelingTransaction(idx int, amountToTransfer uint64, from,
 	}
 }
 
-func mockAutoFuelTransactionSubmit(m *mocksAndTestControl, bm *BalanceManagerWithInMemoryTracking, uncachedBalance bool) {
+func mockAutoFuelDestinationRecheck(m *mocksAndTestControl, destAccount *AddressAccount) {
+	// Immediately before submitting, still no auto-fueling TX in flight, and the balance is unchanged
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, destAccount.Address, "latest").Return((*tktypes.HexUint256)(destAccount.Balance), nil).Once()
+}
+
+func mockAutoFuelTransactionSubmit(m *mocksAndTestControl, bm *BalanceManagerWithInMemoryTracking, destAccount *AddressAccount, uncachedBalance bool) {
+	mockAutoFuelDestinationRecheck(m, destAccount)
+
 	// Then insert of the auto-fueling transaction
 	m.db.ExpectBegin()
 	m.db.ExpectExec("INSERT.*public_txns").WillReturnResult(driver.ResultNoRows)
@@ -313,7 +321,7 @@ func TestTopUpWithNoAmountModificationWithMultipleFuelingTxs(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	expectedTopUpAmount := big.NewInt(100)
 	expectedFuelingTransaction1 := generateExpectedFuelingTransaction(0, expectedTopUpAmount.Uint64(), *bm.sourceAddress, testDestAddress)
@@ -347,7 +355,7 @@ func TestTopUpWithNoAmountModificationWithMultipleFuelingTxs(t *testing.T) {
 	m.db.ExpectQuery("SELECT.*public_txns").WillReturnRows(sqlmock.NewRows([]string{"from", "nonce", `Completed__tx_hash`}).
 		AddRow(expectedFuelingTransaction1.From, expectedFuelingTransaction1.Nonce, tktypes.Bytes32(tktypes.RandBytes(32))))
 
-	mockAutoFuelTransactionSubmit(m, bm, false)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp2, false)
 
 	fuelingTx2, err := bm.TopUpAccount(ctx, accountToTopUp2)
 	require.NoError(t, err)
@@ -371,6 +379,7 @@ func TestTopUpWithNoAmountModificationWithMultipleFuelingTxs(t *testing.T) {
 	m.db.ExpectQuery("SELECT.*public_txns").WillReturnRows(sqlmock.NewRows([]string{"from", "nonce", `Completed__tx_hash`}).
 		AddRow(expectedFuelingTransaction2.From, expectedFuelingTransaction2.Nonce, tktypes.Bytes32(tktypes.RandBytes(32))))
 
+	mockAutoFuelDestinationRecheck(m, accountToTopUp3)
 	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
 		Return(ethclient.EstimateGasResult{}, fmt.Errorf("pop")).Once()
 
@@ -411,7 +420,7 @@ func TestTopUpSuccessTopUpMinAheadUseMin(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	// set the minimum to have 2 extra spaces
 	bm.proactiveFuelingTransactionTotal = 4
@@ -446,7 +455,7 @@ func TestTopUpSuccessTopUpMinAheadUseMax(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	// set the minimum to have 2 extra spaces
 	bm.proactiveFuelingTransactionTotal = 4
@@ -481,7 +490,7 @@ func TestTopUpSuccessTopUpMinAheadUseAvg(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	// set the minimum to have 2 extra spaces
 	bm.proactiveFuelingTransactionTotal = 4
@@ -497,6 +506,39 @@ func TestTopUpSuccessTopUpMinAheadUseAvg(t *testing.T) {
 
 }
 
+func TestTopUpSuccessGasReserve(t *testing.T) {
+	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		m.disableManagerStart = true
+		conf.BalanceManager.MinGasReserve = confutil.P("150")
+	})
+	defer done()
+
+	testDestAddress := *tktypes.RandAddress()
+	m.ethClient.On("GetBalance", mock.Anything, testDestAddress, "latest").Return(tktypes.Uint64ToUint256(100), nil).Once()
+	accountToTopUp, err := bm.GetAddressBalance(ctx, testDestAddress)
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), accountToTopUp.Reserve.Int64())
+	assert.Equal(t, int64(-50), accountToTopUp.GetAvailableToSpend(ctx).Int64())
+
+	// Mock no auto-fueling TX in flight
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
+
+	// nothing has been spent, so there is no average to fill the extra spaces with
+	bm.proactiveFuelingTransactionTotal = 4
+	bm.proactiveFuelingCalcMethod = pldconf.ProactiveAutoFuelingCalcMethodAverage
+
+	// the expectTopUpAmount is just what is needed to cover the reserve
+	expectedTopUpAmount := big.NewInt(50)
+
+	expectedFuelingTransaction1 := generateExpectedFuelingTransaction(0, expectedTopUpAmount.Uint64(), *bm.sourceAddress, testDestAddress)
+	fuelingTx, err := bm.TopUpAccount(ctx, accountToTopUp)
+	require.NoError(t, err)
+	assert.Equal(t, expectedFuelingTransaction1, fuelingTx)
+
+}
+
 func TestTopUpSuccessUseMinDestBalance(t *testing.T) {
 	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
 		m.disableManagerStart = true
@@ -516,7 +558,7 @@ func TestTopUpSuccessUseMinDestBalance(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	// set min top up to balance to 250 (50 above the required amount)
 	bm.minDestBalance = big.NewInt(250)
@@ -547,7 +589,7 @@ func TestTopUpSuccessUseMaxDestBalance(t *testing.T) {
 	// Mock no auto-fueling TX in flight
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
-	mockAutoFuelTransactionSubmit(m, bm, true)
+	mockAutoFuelTransactionSubmit(m, bm, accountToTopUp, true)
 
 	// set max top up to balance to 150 (50 below the required amount)
 	bm.maxDestBalance = big.NewInt(150)
@@ -718,3 +760,119 @@ func TestTopUpFailedDueToUnableToGetSourceAddressBalance(t *testing.T) {
 	assert.Nil(t, fuelingTx)
 	assert.Regexp(t, "pop", err.Error())
 }
+
+func TestTopUpSkippedAsBalanceSufficientBeforeSubmission(t *testing.T) {
+	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		m.disableManagerStart = true
+	})
+	defer done()
+
+	accountToTopUp := &AddressAccount{
+		Balance:               big.NewInt(100),
+		Spent:                 big.NewInt(200),
+		Address:               *tktypes.RandAddress(),
+		SpentTransactionCount: 2,
+		MinCost:               big.NewInt(50),
+		MaxCost:               big.NewInt(150),
+	}
+	// Mock no auto-fueling TX in flight
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, *bm.sourceAddress, "latest").Return(tktypes.Uint64ToUint256(400), nil).Once()
+
+	// By the time we come to submit, another fueling transaction has completed and covered the amount required
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, accountToTopUp.Address, "latest").Return(tktypes.Uint64ToUint256(200), nil).Once()
+
+	fuelingTx, err := bm.TopUpAccount(ctx, accountToTopUp)
+	require.NoError(t, err)
+	assert.Nil(t, fuelingTx)
+	assert.Empty(t, bm.trackedFuelingTransactions)
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestTopUpReducedAsBalanceIncreasedBeforeSubmission(t *testing.T) {
+	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		m.disableManagerStart = true
+	})
+	defer done()
+
+	testDestAddress := *tktypes.RandAddress()
+	accountToTopUp := &AddressAccount{
+		Balance:               big.NewInt(100),
+		Spent:                 big.NewInt(200),
+		Address:               testDestAddress,
+		SpentTransactionCount: 2,
+		MinCost:               big.NewInt(50),
+		MaxCost:               big.NewInt(150),
+	}
+	// Mock no auto-fueling TX in flight
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+
+	// The balance has gone up by 30 since we decided to fuel
+	mockAutoFuelTransactionSubmit(m, bm, &AddressAccount{
+		Address: testDestAddress,
+		Balance: big.NewInt(130),
+	}, true)
+
+	fuelingTx, err := bm.TopUpAccount(ctx, accountToTopUp)
+	require.NoError(t, err)
+	assert.Equal(t, generateExpectedFuelingTransaction(0, 70, *bm.sourceAddress, testDestAddress), fuelingTx)
+}
+
+func TestTopUpPendingFuelingFoundBeforeSubmission(t *testing.T) {
+	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		m.disableManagerStart = true
+	})
+	defer done()
+
+	testDestAddress := *tktypes.RandAddress()
+	accountToTopUp := &AddressAccount{
+		Balance:               big.NewInt(100),
+		Spent:                 big.NewInt(200),
+		Address:               testDestAddress,
+		SpentTransactionCount: 2,
+		MinCost:               big.NewInt(50),
+		MaxCost:               big.NewInt(150),
+	}
+	// Mock no auto-fueling TX in flight
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, *bm.sourceAddress, "latest").Return(tktypes.Uint64ToUint256(400), nil).Once()
+
+	// But one has been submitted by the time we come to submit
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{"from", "nonce"}).AddRow(
+		bm.sourceAddress, 12345,
+	))
+
+	fuelingTx, err := bm.TopUpAccount(ctx, accountToTopUp)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12345), fuelingTx.Nonce.Uint64())
+	assert.Equal(t, fuelingTx, bm.trackedFuelingTransactions[testDestAddress])
+}
+
+func TestTopUpFailedDueToUnableToRecheckDestination(t *testing.T) {
+	ctx, bm, _, m, done := newTestBalanceManager(t, true, func(m *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		m.disableManagerStart = true
+	})
+	defer done()
+
+	accountToTopUp := &AddressAccount{
+		Balance:               big.NewInt(100),
+		Spent:                 big.NewInt(200),
+		Address:               *tktypes.RandAddress(),
+		SpentTransactionCount: 2,
+		MinCost:               big.NewInt(50),
+		MaxCost:               big.NewInt(150),
+	}
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, *bm.sourceAddress, "latest").Return(tktypes.Uint64ToUint256(400), nil)
+
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnError(fmt.Errorf("pop"))
+	_, err := bm.TopUpAccount(ctx, accountToTopUp)
+	assert.Regexp(t, "pop", err)
+
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
+	m.ethClient.On("GetBalance", mock.Anything, accountToTopUp.Address, "latest").Return(nil, fmt.Errorf("snap")).Once()
+	_, err = bm.TopUpAccount(ctx, accountToTopUp)
+	assert.Regexp(t, "snap", err)
+}
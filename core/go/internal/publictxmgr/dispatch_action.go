@@ -17,6 +17,7 @@ package publictxmgr
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -49,27 +50,25 @@ func (pte *pubTxManager) dispatchAction(ctx context.Context, from tktypes.EthAdd
 	startTime := time.Now()
 	go func() {
 		pte.inFlightOrchestratorMux.Lock()
-		defer pte.inFlightOrchestratorMux.Unlock()
 		inFlightOrchestrator, orchestratorInFlight := pte.inFlightOrchestrators[from]
-		switch action {
-		case ActionCompleted:
-			// Only need to pass this on if there's an orchestrator in flight for this signing address
-			if orchestratorInFlight {
-				inFlightOrchestrator.dispatchAction(ctx, nonce, action, response)
-			}
-		case ActionSuspend, ActionResume:
-			suspended := false
-			if action == ActionSuspend {
-				suspended = true
-			}
-			if !orchestratorInFlight {
-				// no in-flight orchestrator for the signing address, it's OK to update the DB directly
-				response <- pte.persistSuspendedFlag(ctx, from, nonce, suspended)
-			} else {
-				// has to be done in the context of the orchestrator
-				inFlightOrchestrator.dispatchAction(ctx, nonce, action, response)
+		if !orchestratorInFlight {
+			defer pte.inFlightOrchestratorMux.Unlock()
+			switch action {
+			case ActionCompleted:
+				// Nothing in flight for this signing address to notify
+				response <- nil
+			case ActionSuspend, ActionResume:
+				// no in-flight orchestrator for the signing address, it's OK to update the DB directly,
+				// as we hold the lock that stops one being started until we are done
+				response <- pte.persistSuspendedFlag(ctx, from, nonce, action == ActionSuspend)
 			}
+			return
 		}
+		// The orchestrator applies the action under its own lock, so we release the lock on the map of
+		// orchestrators first. Otherwise actions for different signing addresses (such as the confirmations
+		// fanned out by NotifyConfirmPersisted) would be serialized behind one another.
+		pte.inFlightOrchestratorMux.Unlock()
+		inFlightOrchestrator.dispatchAction(ctx, nonce, action, response)
 	}()
 
 	select {
@@ -109,5 +108,44 @@ func (oc *orchestrator) dispatchAction(ctx context.Context, nonce uint64, action
 			response <- oc.persistSuspendedFlag(ctx, oc.signingAddress, nonce, suspendedFlag)
 		}
 		oc.MarkInFlightTxStale()
+	} else if action == ActionCompleted {
+		// The transaction is no longer in flight, so there is nothing to notify
+		response <- nil
+	}
+}
+
+// ResubmitAllForAddress forces an immediate resubmission, with a bumped gas price, of every submitted
+// transaction for the signing address that is still in-flight and not yet confirmed.
+// Returns the nonces that were marked for resubmission, in nonce order.
+func (pte *pubTxManager) ResubmitAllForAddress(ctx context.Context, from tktypes.EthAddress) ([]uint64, error) {
+	pte.inFlightOrchestratorMux.Lock()
+	defer pte.inFlightOrchestratorMux.Unlock()
+	inFlightOrchestrator, orchestratorInFlight := pte.inFlightOrchestrators[from]
+	if !orchestratorInFlight {
+		// nothing is in-flight for this signing address, so there is nothing stuck to resubmit
+		log.L(ctx).Infof("No in-flight transactions to resubmit for %s", from)
+		return []uint64{}, nil
+	}
+	return inFlightOrchestrator.resubmitAll(ctx), nil
+}
+
+func (oc *orchestrator) resubmitAll(ctx context.Context) []uint64 {
+	oc.inFlightTxsMux.Lock()
+	defer oc.inFlightTxsMux.Unlock()
+	inFlight := make([]*inFlightTransactionStageController, len(oc.inFlightTxs))
+	copy(inFlight, oc.inFlightTxs)
+	sort.Slice(inFlight, func(i, j int) bool {
+		return inFlight[i].stateManager.GetNonce() < inFlight[j].stateManager.GetNonce()
+	})
+	nonces := []uint64{}
+	for _, it := range inFlight {
+		if it.RequestResubmit(ctx) {
+			nonces = append(nonces, it.stateManager.GetNonce())
+		}
+	}
+	log.L(ctx).Infof("Requested resubmission of %d transactions for %s: %v", len(nonces), oc.signingAddress, nonces)
+	if len(nonces) > 0 {
+		oc.MarkInFlightTxStale()
 	}
+	return nonces
 }
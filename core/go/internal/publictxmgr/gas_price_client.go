@@ -50,11 +50,13 @@ type GasPriceClient interface {
 //   - Cached gas price
 //   - Gas Oracle
 //   - Node gas_Price
+//   - Fallback gas price (only if the node gas_Price fails)
 type HybridGasPriceClient struct {
-	hasZeroGasPrice bool
-	fixedGasPrice   *fftypes.JSONAny
-	ethClient       ethclient.EthClient
-	gasPriceCache   cache.Cache[string, *fftypes.JSONAny]
+	hasZeroGasPrice  bool
+	fixedGasPrice    *fftypes.JSONAny
+	fallbackGasPrice *fftypes.JSONAny
+	ethClient        ethclient.EthClient
+	gasPriceCache    cache.Cache[string, *fftypes.JSONAny]
 }
 
 func (hGpc *HybridGasPriceClient) HasZeroGasPrice(ctx context.Context) bool {
@@ -110,6 +112,11 @@ func (hGpc *HybridGasPriceClient) getGasPriceJSON(ctx context.Context) (gasPrice
 	log.L(ctx).Debugf("Retrieving gas price from node eth call")
 	gasPriceHexInt, err := hGpc.ethClient.GasPrice(ctx)
 	if err != nil {
+		if !hGpc.fallbackGasPrice.IsNil() {
+			// the fallback is not cached, so we go back to the node as soon as it recovers
+			log.L(ctx).Warnf("Failed to retrieve gas price from the node, using fallback gas price %s: %s", hGpc.fallbackGasPrice, err)
+			return hGpc.fallbackGasPrice, nil
+		}
 		// no fallback is available, return the error
 		log.L(ctx).Errorf("Failed to retrieve gas price from the node")
 		return nil, err
@@ -153,6 +160,10 @@ func NewGasPriceClient(ctx context.Context, conf *pldconf.PublicTxManagerConfig)
 	if b != nil && string(b) != `null` {
 		gasPriceClient.fixedGasPrice = fftypes.JSONAnyPtrBytes(b)
 	}
+	b, _ = json.Marshal(conf.GasPrice.FallbackGasPrice)
+	if b != nil && string(b) != `null` {
+		gasPriceClient.fallbackGasPrice = fftypes.JSONAnyPtrBytes(b)
+	}
 	gasPriceClient.gasPriceCache = gasPriceCache
 	return gasPriceClient
 }
@@ -140,6 +140,34 @@ func TestFixedGasPrice(t *testing.T) {
 	}, gpo)
 }
 
+func TestFallbackGasPrice(t *testing.T) {
+	ctx := context.Background()
+	gasPriceClient := NewGasPriceClient(ctx, &pldconf.PublicTxManagerConfig{
+		GasPrice: pldconf.GasPriceConfig{
+			FallbackGasPrice: "1020304050",
+		},
+	})
+	hgc := gasPriceClient.(*HybridGasPriceClient)
+	mEC := ethclientmocks.NewEthClient(t)
+	hgc.Init(ctx, mEC)
+
+	// the fallback is used when the node cannot provide a price
+	mEC.On("GasPrice", ctx).Return(nil, fmt.Errorf("pop")).Once()
+	gpo, err := hgc.GetGasPriceObject(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.PublicTxGasPricing{
+		GasPrice: tktypes.Int64ToInt256(1020304050),
+	}, gpo)
+
+	// but it is not cached, so the node price is used once it recovers
+	mEC.On("GasPrice", ctx).Return(tktypes.Uint64ToUint256(1000), nil).Once()
+	gpo, err = hgc.GetGasPriceObject(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.PublicTxGasPricing{
+		GasPrice: tktypes.Int64ToInt256(1000),
+	}, gpo)
+}
+
 func TestGasPriceClient(t *testing.T) {
 	ctx := context.Background()
 
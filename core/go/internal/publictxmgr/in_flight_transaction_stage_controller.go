@@ -72,6 +72,17 @@ type inFlightTransactionStageController struct {
 
 	newStatus *InFlightStatus
 
+	// the orchestrator's resubmit interval, unless overridden for this transaction
+	txResubmitInterval time.Duration
+
+	// set when a resubmission has been explicitly requested, so the next evaluation does not wait for
+	// the resubmit interval, and the gas price is bumped even if the node has not moved its price
+	resubmitRequested bool
+
+	// the number of consecutive times the node has rejected a replacement of this transaction as underpriced,
+	// each of which increases the gas price bump applied on the next attempt
+	underpricedReplacements int
+
 	// deleteRequested bool // figure out what's the reliable approach for deletion
 }
 
@@ -133,6 +144,14 @@ func NewInFlightTransactionStageController(
 		timeLineLoggingEnabled: logrus.IsLevelEnabled(logrus.DebugLevel),
 	}
 
+	ift.txResubmitInterval = oc.resubmitInterval
+	if ptx.ResubmitInterval != nil {
+		// validated on submission
+		if d, err := time.ParseDuration(*ptx.ResubmitInterval); err == nil && d > 0 {
+			ift.txResubmitInterval = max(d, veryShortMinimum)
+		}
+	}
+
 	ift.MarkTime("wait_in_inflight_queue")
 	imtxs := NewInMemoryTxStateManager(enth.ctx, ptx)
 	ift.stateManager = NewInFlightTransactionStateManager(enth.thMetrics, enth.balanceManager, enth.bIndexer, ift, imtxs, oc.retry, oc, oc.submissionWriter, ift.testOnlyNoEventMode)
@@ -251,6 +270,7 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 									rsc.StageOutputsToBePersisted.UpdateSubStatus(BaseTxActionRetrieveGasPrice, nil, fftypes.JSONAnyPtr(`{"error":"`+rsIn.GasPriceOutput.Err.Error()+`"}`))
 								} else {
 									gpo := it.calculateNewGasPrice(ctx, rsc.InMemoryTx.GetGasPriceObject(), rsIn.GasPriceOutput.GasPriceObject)
+									it.resubmitRequested = false
 									gpoJSON, _ := json.Marshal(gpo)
 									rsc.StageOutputsToBePersisted.TxUpdates = &BaseTXUpdates{GasPricing: gpo}
 									rsc.StageOutputsToBePersisted.UpdateSubStatus(BaseTxActionRetrieveGasPrice, fftypes.JSONAnyPtr(string(gpoJSON)), nil)
@@ -362,6 +382,18 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 								rsc.SetNewPersistenceUpdateOutput()
 								if rsIn.SubmitOutput.Err != nil {
 									log.L(ctx).Errorf("Submitting transaction error for transaction %s: %+v", rsc.InMemoryTx.GetSignerNonce(), rsIn.SubmitOutput.Err)
+									it.recordSubmissionFailure(ctx)
+									if rsIn.SubmitOutput.ErrorReason == string(ethclient.ErrorReasonReplacementUnderpriced) && it.stateManager.GetSignedTransaction() == nil {
+										if it.atGasPriceIncreaseMax(rsc.InMemoryTx.GetGasPriceObject()) {
+											// no higher price can be offered, so wait for the resubmit interval rather than retrying straight away
+											log.L(ctx).Warnf("Replacement of transaction %s rejected as underpriced at the maximum gas price", rsc.InMemoryTx.GetSignerNonce())
+											it.underpricedReplacements = 0
+											it.resubmitRequested = false
+										} else {
+											it.underpricedReplacements++
+											it.resubmitRequested = true
+										}
+									}
 									errMsg := rsIn.SubmitOutput.Err.Error()
 									rsc.StageOutputsToBePersisted.TxUpdates = &BaseTXUpdates{
 										ErrorMessage: &errMsg,
@@ -372,6 +404,8 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 										rsc.StageOutputsToBePersisted.TxUpdates.LastSubmit = confutil.P(tktypes.TimestampNow())
 									}
 								} else {
+									it.recordSubmissionSuccess()
+									it.underpricedReplacements = 0
 									if rsIn.SubmitOutput.SubmissionOutcome == SubmissionOutcomeSubmittedNew {
 										// new transaction submitted successfully
 										rsc.StageOutputsToBePersisted.UpdateSubStatus(BaseTxActionSubmitTransaction, fftypes.JSONAnyPtr(fmt.Sprintf(`{"hash":"%s"}`, rsIn.SubmitOutput.TxHash)), nil)
@@ -497,7 +531,11 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 		} else {
 			// we have a transaction hash recorded, we must ensure we checks the hash matches
 			// the state we persisted by triggering a submission
-			if !it.stateManager.ValidatedTransactionHashMatchState(ctx) {
+			if it.underpricedReplacements > 0 && it.resubmitRequested {
+				// re-signing at the same gas price would just be rejected again
+				log.L(ctx).Debugf("Transaction with ID %s entering retrieve gas price as the replacement was underpriced %d times.", it.stateManager.GetSignerNonce(), it.underpricedReplacements)
+				it.TriggerNewStageRun(ctx, InFlightTxStageRetrieveGasPrice, BaseTxSubStatusStale, nil)
+			} else if !it.stateManager.ValidatedTransactionHashMatchState(ctx) {
 				if it.stateManager.CanSubmit(ctx, tOut.Cost) {
 					log.L(ctx).Debugf("Transaction with ID %s entering signing stage as current state hasn't been validated.", it.stateManager.GetSignerNonce())
 					it.TriggerNewStageRun(ctx, InFlightTxStageSigning, BaseTxSubStatusReceived, nil)
@@ -507,9 +545,17 @@ func (it *inFlightTransactionStageController) ProduceLatestInFlightStageContext(
 			} else {
 				// once we validated the transaction hash matched the transaction state
 				lastSubmitTime := it.stateManager.GetLastSubmitTime()
-				if lastSubmitTime != nil && time.Since(lastSubmitTime.Time()) > it.resubmitInterval {
+				if lastSubmitTime != nil && it.resubmitRequested {
+					// do a resubmission as one has been explicitly requested
+					log.L(ctx).Debugf("Transaction with ID %s entering retrieve gas price as resubmission was requested.", it.stateManager.GetSignerNonce())
+					it.TriggerNewStageRun(ctx, InFlightTxStageRetrieveGasPrice, BaseTxSubStatusStale, nil)
+				} else if lastSubmitTime != nil && time.Since(lastSubmitTime.Time()) > it.txResubmitInterval && it.stateManager.GetSignedTransaction() != nil {
+					// an externally signed transaction can only be re-sent exactly as it was signed
+					log.L(ctx).Debugf("Transaction with ID %s entering signing stage to resend the signed transaction as exceeded resubmit interval of %s.", it.stateManager.GetSignerNonce(), it.txResubmitInterval.String())
+					it.TriggerNewStageRun(ctx, InFlightTxStageSigning, BaseTxSubStatusStale, nil)
+				} else if lastSubmitTime != nil && time.Since(lastSubmitTime.Time()) > it.txResubmitInterval {
 					// do a resubmission when exceeded the resubmit interval
-					log.L(ctx).Debugf("Transaction with ID %s entering retrieve gas price as exceeded resubmit interval of %s.", it.stateManager.GetSignerNonce(), it.resubmitInterval.String())
+					log.L(ctx).Debugf("Transaction with ID %s entering retrieve gas price as exceeded resubmit interval of %s.", it.stateManager.GetSignerNonce(), it.txResubmitInterval.String())
 					it.TriggerNewStageRun(ctx, InFlightTxStageRetrieveGasPrice, BaseTxSubStatusStale, nil)
 				} else {
 					// check and track the existing transaction hash
@@ -535,10 +581,19 @@ func (it *inFlightTransactionStageController) calculateNewGasPrice(ctx context.C
 	// The change is not made here to InMemoryTx, but rather pushed to TxUpdates for persisting.
 	// So we need to make sure we don't edit the in-memory existing object by passing it to calculateNewGasPrice
 
-	if newGpo.GasPrice != nil && existingGpo.GasPrice != nil && existingGpo.GasPrice.Int().Cmp(newGpo.GasPrice.Int()) == 1 {
+	// when a resubmission was explicitly requested, we must bump even if the price has not moved
+	bumpFrom := 1
+	if it.resubmitRequested {
+		bumpFrom = 0
+	}
+
+	// each time the node has rejected a replacement as underpriced, we bump by a further multiple of the percentage
+	increasePercent := big.NewInt(int64(it.gasPriceIncreasePercent * (1 + it.underpricedReplacements)))
+
+	if newGpo.GasPrice != nil && existingGpo.GasPrice != nil && existingGpo.GasPrice.Int().Cmp(newGpo.GasPrice.Int()) >= bumpFrom {
 		// existing gas price already above the new gas price, increase using percentage
 		newPercentage := big.NewInt(100)
-		newPercentage = newPercentage.Add(newPercentage, big.NewInt(int64(it.gasPriceIncreasePercent)))
+		newPercentage = newPercentage.Add(newPercentage, increasePercent)
 		newGasPrice := new(big.Int).Mul(existingGpo.GasPrice.Int(), newPercentage)
 		newGasPrice = newGasPrice.Div(newGasPrice, big.NewInt(100))
 		if it.gasPriceIncreaseMax != nil && newGasPrice.Cmp(it.gasPriceIncreaseMax) == 1 {
@@ -549,26 +604,73 @@ func (it *inFlightTransactionStageController) calculateNewGasPrice(ctx context.C
 			MaxFeePerGas:         existingGpo.MaxFeePerGas,         // copy over unchanged (although expected to be unset)
 			MaxPriorityFeePerGas: existingGpo.MaxPriorityFeePerGas, //   "
 		}
-	} else if newGpo.MaxFeePerGas != nil && existingGpo.MaxFeePerGas != nil && existingGpo.MaxFeePerGas.Int().Cmp(newGpo.MaxFeePerGas.Int()) == 1 {
-		// existing MaxFeePerGas already above the new MaxFeePerGas, increase using percentage
-		newPercentage := big.NewInt(100)
-
-		newPercentage = newPercentage.Add(newPercentage, big.NewInt(int64(it.gasPriceIncreasePercent)))
-		newMaxFeePerGas := new(big.Int).Mul(existingGpo.MaxFeePerGas.Int(), newPercentage)
-		newMaxFeePerGas = newMaxFeePerGas.Div(newMaxFeePerGas, big.NewInt(100))
-		if it.gasPriceIncreaseMax != nil && newMaxFeePerGas.Cmp(it.gasPriceIncreaseMax) == 1 {
-			newMaxFeePerGas.Set(it.gasPriceIncreaseMax)
-		}
-		newGpo = &pldapi.PublicTxGasPricing{
-			GasPrice:             existingGpo.GasPrice, // copy over unchanged (although expected to be unset)
-			MaxFeePerGas:         (*tktypes.HexUint256)(newMaxFeePerGas),
-			MaxPriorityFeePerGas: existingGpo.MaxPriorityFeePerGas,
-		}
+	} else if newGpo.MaxFeePerGas != nil && existingGpo.MaxFeePerGas != nil {
+		newGpo = it.calculateNewEIP1559GasPrice(ctx, existingGpo, newGpo, increasePercent)
 	}
 
 	return newGpo
 }
 
+// Whether the submitted gas price has already reached the configured maximum, so no further bump is possible
+func (it *inFlightTransactionStageController) atGasPriceIncreaseMax(gpo *pldapi.PublicTxGasPricing) bool {
+	if it.gasPriceIncreaseMax == nil || gpo == nil {
+		return false
+	}
+	if gpo.MaxFeePerGas != nil {
+		return gpo.MaxFeePerGas.Int().Cmp(it.gasPriceIncreaseMax) >= 0
+	}
+	return gpo.GasPrice != nil && gpo.GasPrice.Int().Cmp(it.gasPriceIncreaseMax) >= 0
+}
+
+// A replacement EIP-1559 transaction is only accepted by the node if both the max fee and the priority fee
+// are raised by the price bump percentage. The base fee and the priority fee move independently, so each
+// is bumped from the value last submitted, unless the gas price oracle has already moved it higher still.
+func (it *inFlightTransactionStageController) calculateNewEIP1559GasPrice(ctx context.Context, existingGpo *pldapi.PublicTxGasPricing, newGpo *pldapi.PublicTxGasPricing, increasePercent *big.Int) *pldapi.PublicTxGasPricing {
+	if !it.resubmitRequested &&
+		existingGpo.MaxFeePerGas.Int().Cmp(newGpo.MaxFeePerGas.Int()) == 0 &&
+		gasValueOrZero(existingGpo.MaxPriorityFeePerGas).Cmp(gasValueOrZero(newGpo.MaxPriorityFeePerGas)) == 0 {
+		// nothing has moved, so we resubmit the transaction as it is
+		return newGpo
+	}
+
+	newMaxFeePerGas := bumpGasValue(existingGpo.MaxFeePerGas.Int(), increasePercent)
+	if it.gasPriceIncreaseMax != nil && newMaxFeePerGas.Cmp(it.gasPriceIncreaseMax) == 1 {
+		newMaxFeePerGas.Set(it.gasPriceIncreaseMax)
+	}
+	if newGpo.MaxFeePerGas.Int().Cmp(newMaxFeePerGas) == 1 {
+		newMaxFeePerGas.Set(newGpo.MaxFeePerGas.Int())
+	}
+
+	newMaxPriorityFeePerGas := bumpGasValue(gasValueOrZero(existingGpo.MaxPriorityFeePerGas), increasePercent)
+	if oracleTip := gasValueOrZero(newGpo.MaxPriorityFeePerGas); oracleTip.Cmp(newMaxPriorityFeePerGas) == 1 {
+		newMaxPriorityFeePerGas.Set(oracleTip)
+	}
+	// the priority fee can never exceed the max fee
+	if newMaxPriorityFeePerGas.Cmp(newMaxFeePerGas) == 1 {
+		newMaxPriorityFeePerGas.Set(newMaxFeePerGas)
+	}
+
+	log.L(ctx).Debugf("Transaction with ID %s EIP-1559 gas price bumped from maxFeePerGas=%s,maxPriorityFeePerGas=%s to maxFeePerGas=%s,maxPriorityFeePerGas=%s",
+		it.stateManager.GetSignerNonce(), existingGpo.MaxFeePerGas, existingGpo.MaxPriorityFeePerGas, newMaxFeePerGas, newMaxPriorityFeePerGas)
+	return &pldapi.PublicTxGasPricing{
+		GasPrice:             existingGpo.GasPrice, // copy over unchanged (although expected to be unset)
+		MaxFeePerGas:         (*tktypes.HexUint256)(newMaxFeePerGas),
+		MaxPriorityFeePerGas: (*tktypes.HexUint256)(newMaxPriorityFeePerGas),
+	}
+}
+
+func gasValueOrZero(v *tktypes.HexUint256) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v.Int()
+}
+
+func bumpGasValue(v *big.Int, increasePercent *big.Int) *big.Int {
+	newValue := new(big.Int).Mul(v, new(big.Int).Add(big.NewInt(100), increasePercent))
+	return newValue.Div(newValue, big.NewInt(100))
+}
+
 func calculateGasRequiredForTransaction(ctx context.Context, gpo *pldapi.PublicTxGasPricing, gasLimit uint64) (gasRequired *big.Int, err error) {
 	if gpo.GasPrice != nil {
 		log.L(ctx).Debugf("gas calculation using GasPrice (%+v)", gpo.GasPrice)
@@ -602,8 +704,41 @@ func (it *inFlightTransactionStageController) NotifyStatusUpdate(ctx context.Con
 	return true, nil
 }
 
+// NextResubmitDue returns when the transaction will next be due for resubmission, if it has been submitted
+func (it *inFlightTransactionStageController) NextResubmitDue() *time.Time {
+	it.transactionMux.Lock()
+	defer it.transactionMux.Unlock()
+	lastSubmitTime := it.stateManager.GetLastSubmitTime()
+	if lastSubmitTime == nil || it.stateManager.IsReadyToExit() {
+		return nil
+	}
+	due := lastSubmitTime.Time().Add(it.txResubmitInterval)
+	return &due
+}
+
+// RequestResubmit marks a submitted transaction for immediate resubmission with a bumped gas price.
+// Returns false if the transaction has not been submitted yet, or is already confirmed (or on its way out).
+func (it *inFlightTransactionStageController) RequestResubmit(ctx context.Context) bool {
+	it.transactionMux.Lock()
+	defer it.transactionMux.Unlock()
+	if it.stateManager.IsReadyToExit() ||
+		(it.newStatus != nil && *it.newStatus == InFlightStatusConfirmReceived) ||
+		it.stateManager.GetTransactionHash() == nil ||
+		it.stateManager.GetSignedTransaction() != nil /* we cannot bump the gas price of an externally signed transaction */ {
+		log.L(ctx).Debugf("Transaction with ID %s not eligible for resubmission in status: %s", it.stateManager.GetSignerNonce(), it.stateManager.GetInFlightStatus())
+		return false
+	}
+	it.resubmitRequested = true
+	return true
+}
+
 func (it *inFlightTransactionStageController) TriggerRetrieveGasPrice(ctx context.Context) error {
 	it.executeAsync(func() {
+		if fixedGasPricing := it.stateManager.GetFixedGasPricing(); it.stateManager.GetSignedTransaction() != nil {
+			// the gas pricing of an externally signed transaction is fixed by the signature
+			it.stateManager.AddGasPriceOutput(ctx, fixedGasPricing, nil)
+			return
+		}
 		gasPrice, err := it.gasPriceClient.GetGasPriceObject(ctx)
 		it.stateManager.AddGasPriceOutput(ctx, gasPrice, err)
 	}, ctx, it.stateManager.GetStage(ctx), false)
@@ -625,6 +760,11 @@ func (it *inFlightTransactionStageController) TriggerStatusUpdate(ctx context.Co
 }
 func (it *inFlightTransactionStageController) TriggerSignTx(ctx context.Context) error {
 	it.executeAsync(func() {
+		if signedMessage := it.stateManager.GetSignedTransaction(); signedMessage != nil {
+			// signed externally, so we just submit the payload we were given
+			it.stateManager.AddSignOutput(ctx, signedMessage, calculateTransactionHash(signedMessage), nil)
+			return
+		}
 		signedMessage, txHash, err := it.signTx(ctx, it.stateManager.GetFrom(), it.stateManager.BuildEthTX())
 		log.L(ctx).Debugf("Adding signed message to output, hash %s, signedMessage not nil %t, err %+v", txHash, signedMessage != nil, err)
 		it.stateManager.AddSignOutput(ctx, signedMessage, txHash, err)
@@ -560,3 +560,69 @@ func TestProduceLatestInFlightStageContextRetrieveGasPanic(t *testing.T) {
 	assert.NotEqual(t, rsc, it.stateManager.GetRunningStageContext(ctx))
 	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
 }
+
+func TestCalculateNewGasPriceEIP1559(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t)
+	defer done()
+	it, _ := newInflightTransaction(o, 1)
+	it.gasPriceIncreasePercent = 10
+
+	submitted := &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(100),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(10),
+	}
+
+	// nothing has moved, so the transaction is resubmitted as it is
+	gpo := it.calculateNewGasPrice(ctx, submitted, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(100),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(10),
+	})
+	assert.Equal(t, big.NewInt(100), gpo.MaxFeePerGas.Int())
+	assert.Equal(t, big.NewInt(10), gpo.MaxPriorityFeePerGas.Int())
+
+	// only the base fee has risen - we take the higher max fee from the oracle, but still
+	// need to bump the priority fee for the node to accept the replacement
+	gpo = it.calculateNewGasPrice(ctx, submitted, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(150),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(10),
+	})
+	assert.Equal(t, big.NewInt(150), gpo.MaxFeePerGas.Int())
+	assert.Equal(t, big.NewInt(11), gpo.MaxPriorityFeePerGas.Int())
+	assert.Nil(t, gpo.GasPrice)
+
+	// the mempool is congested - we take the higher priority fee from the oracle, and the max fee
+	// from the oracle has not risen enough for a replacement so it is bumped
+	gpo = it.calculateNewGasPrice(ctx, submitted, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(105),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(30),
+	})
+	assert.Equal(t, big.NewInt(110), gpo.MaxFeePerGas.Int())
+	assert.Equal(t, big.NewInt(30), gpo.MaxPriorityFeePerGas.Int())
+
+	// a resubmission was requested with no change in the oracle, so both are bumped
+	it.resubmitRequested = true
+	gpo = it.calculateNewGasPrice(ctx, submitted, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(100),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(10),
+	})
+	assert.Equal(t, big.NewInt(110), gpo.MaxFeePerGas.Int())
+	assert.Equal(t, big.NewInt(11), gpo.MaxPriorityFeePerGas.Int())
+
+	// the max fee is capped, and the priority fee can never exceed the max fee
+	it.gasPriceIncreaseMax = big.NewInt(104)
+	gpo = it.calculateNewGasPrice(ctx, submitted, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas:         tktypes.Uint64ToUint256(90),
+		MaxPriorityFeePerGas: tktypes.Uint64ToUint256(200),
+	})
+	assert.Equal(t, big.NewInt(104), gpo.MaxFeePerGas.Int())
+	assert.Equal(t, big.NewInt(104), gpo.MaxPriorityFeePerGas.Int())
+
+	// no priority fee was submitted, and the oracle does not supply one
+	gpo = it.calculateNewGasPrice(ctx, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas: tktypes.Uint64ToUint256(100),
+	}, &pldapi.PublicTxGasPricing{
+		MaxFeePerGas: tktypes.Uint64ToUint256(100),
+	})
+	assert.Equal(t, big.NewInt(104), gpo.MaxFeePerGas.Int())
+	assert.Zero(t, gpo.MaxPriorityFeePerGas.Int().Sign())
+}
@@ -450,3 +450,106 @@ func TestProduceLatestInFlightStageContextTriggerSubmit(t *testing.T) {
 	assert.NotEmpty(t, inFlightStageMananger.bufferedStageOutputs[0].SubmitOutput.SubmissionTime)
 	assert.Equal(t, SubmissionOutcomeFailedRequiresRetry, inFlightStageMananger.bufferedStageOutputs[0].SubmitOutput.SubmissionOutcome)
 }
+
+func TestProduceLatestInFlightStageContextSubmitReplacementUnderpriced(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t)
+	defer done()
+	it, mTS := newInflightTransaction(o, 1)
+	it.testOnlyNoActionMode = true
+	it.testOnlyNoEventMode = true
+	mTS.statusUpdater = &mockStatusUpdater{
+		updateSubStatus: func(ctx context.Context, imtx InMemoryTxStateReadOnly, subStatus BaseTxSubStatus, action BaseTxAction, info, err *fftypes.JSONAny, actionOccurred *tktypes.Timestamp) error {
+			return nil
+		},
+	}
+	txHash := confutil.P(tktypes.Bytes32Keccak([]byte("0x000001")))
+	mTS.ApplyInMemoryUpdates(ctx, &BaseTXUpdates{
+		GasPricing: &pldapi.PublicTxGasPricing{
+			GasPrice: tktypes.Uint64ToUint256(100),
+		},
+		FirstSubmit:     confutil.P(tktypes.TimestampNow()),
+		TransactionHash: txHash,
+	})
+	it.gasPriceIncreasePercent = 10
+	sameGasPrice := &pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(100)}
+
+	// the first replacement is rejected by the node as underpriced
+	inFlightStageMananger := it.stateManager.(*inFlightTransactionState)
+	it.TriggerNewStageRun(ctx, InFlightTxStageSubmitting, BaseTxSubStatusReceived, []byte("signedMessage"))
+	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
+	it.stateManager.AddSubmitOutput(ctx, nil, confutil.P(tktypes.TimestampNow()), SubmissionOutcomeFailedRequiresRetry,
+		ethclient.ErrorReasonReplacementUnderpriced, fmt.Errorf("replacement transaction underpriced"))
+	_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{PreviousNonceCostUnknown: true})
+	assert.Equal(t, 1, it.underpricedReplacements)
+	assert.True(t, it.resubmitRequested)
+	firstBump := it.calculateNewGasPrice(ctx, it.stateManager.GetGasPriceObject(), sameGasPrice)
+	assert.Equal(t, int64(120), firstBump.GasPrice.Int().Int64())
+
+	// rather than re-submitting the same signed transaction, we go back round to get a new gas price
+	it.stateManager.ClearRunningStageContext(ctx)
+	_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{PreviousNonceCostUnknown: true})
+	assert.Equal(t, InFlightTxStageRetrieveGasPrice, it.stateManager.GetRunningStageContext(ctx).Stage)
+
+	// rejected again, so the bump on retry is larger again
+	it.TriggerNewStageRun(ctx, InFlightTxStageSubmitting, BaseTxSubStatusReceived, []byte("signedMessage"))
+	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
+	it.stateManager.AddSubmitOutput(ctx, nil, confutil.P(tktypes.TimestampNow()), SubmissionOutcomeFailedRequiresRetry,
+		ethclient.ErrorReasonReplacementUnderpriced, fmt.Errorf("replacement transaction underpriced"))
+	_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{PreviousNonceCostUnknown: true})
+	assert.Equal(t, 2, it.underpricedReplacements)
+	secondBump := it.calculateNewGasPrice(ctx, it.stateManager.GetGasPriceObject(), sameGasPrice)
+	assert.Equal(t, int64(130), secondBump.GasPrice.Int().Int64())
+
+	// a successful submission resets the count
+	it.TriggerNewStageRun(ctx, InFlightTxStageSubmitting, BaseTxSubStatusReceived, []byte("signedMessage"))
+	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
+	it.stateManager.AddSubmitOutput(ctx, txHash, confutil.P(tktypes.TimestampNow()), SubmissionOutcomeSubmittedNew, ethclient.ErrorReason(""), nil)
+	_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{PreviousNonceCostUnknown: true})
+	assert.Zero(t, it.underpricedReplacements)
+}
+
+func TestProduceLatestInFlightStageContextSubmitReplacementUnderpricedAtMax(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t)
+	defer done()
+	it, mTS := newInflightTransaction(o, 1)
+	it.testOnlyNoActionMode = true
+	it.testOnlyNoEventMode = true
+	mTS.statusUpdater = &mockStatusUpdater{
+		updateSubStatus: func(ctx context.Context, imtx InMemoryTxStateReadOnly, subStatus BaseTxSubStatus, action BaseTxAction, info, err *fftypes.JSONAny, actionOccurred *tktypes.Timestamp) error {
+			return nil
+		},
+	}
+	mTS.ApplyInMemoryUpdates(ctx, &BaseTXUpdates{
+		GasPricing: &pldapi.PublicTxGasPricing{
+			GasPrice: tktypes.Uint64ToUint256(100),
+		},
+		FirstSubmit:     confutil.P(tktypes.TimestampNow()),
+		TransactionHash: confutil.P(tktypes.Bytes32Keccak([]byte("0x000001"))),
+	})
+	it.gasPriceIncreasePercent = 10
+	it.gasPriceIncreaseMax = big.NewInt(100)
+
+	// the price cannot be bumped any further, so we do not go straight back round to retry
+	inFlightStageMananger := it.stateManager.(*inFlightTransactionState)
+	it.TriggerNewStageRun(ctx, InFlightTxStageSubmitting, BaseTxSubStatusReceived, []byte("signedMessage"))
+	inFlightStageMananger.bufferedStageOutputs = make([]*StageOutput, 0)
+	it.stateManager.AddSubmitOutput(ctx, nil, confutil.P(tktypes.TimestampNow()), SubmissionOutcomeFailedRequiresRetry,
+		ethclient.ErrorReasonReplacementUnderpriced, fmt.Errorf("replacement transaction underpriced"))
+	_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{PreviousNonceCostUnknown: true})
+	assert.Zero(t, it.underpricedReplacements)
+	assert.False(t, it.resubmitRequested)
+}
+
+func TestAtGasPriceIncreaseMax(t *testing.T) {
+	_, o, _, done := newTestOrchestrator(t)
+	defer done()
+	it, _ := newInflightTransaction(o, 1)
+
+	assert.False(t, it.atGasPriceIncreaseMax(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(100)}))
+	it.gasPriceIncreaseMax = big.NewInt(100)
+	assert.False(t, it.atGasPriceIncreaseMax(nil))
+	assert.False(t, it.atGasPriceIncreaseMax(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(99)}))
+	assert.True(t, it.atGasPriceIncreaseMax(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(100)}))
+	assert.False(t, it.atGasPriceIncreaseMax(&pldapi.PublicTxGasPricing{MaxFeePerGas: tktypes.Uint64ToUint256(50)}))
+	assert.True(t, it.atGasPriceIncreaseMax(&pldapi.PublicTxGasPricing{MaxFeePerGas: tktypes.Uint64ToUint256(100)}))
+}
@@ -174,6 +174,18 @@ func (imtxs *inMemoryTxState) GetGasLimit() uint64 {
 	return imtxs.mtx.ptx.Gas
 }
 
+func (imtxs *inMemoryTxState) GetSignedTransaction() tktypes.HexBytes {
+	return imtxs.mtx.ptx.SignedTx
+}
+
+func (imtxs *inMemoryTxState) GetFixedGasPricing() *pldapi.PublicTxGasPricing {
+	if imtxs.mtx.ptx.FixedGasPricing == nil {
+		return nil
+	}
+	gasPricing := recoverGasPriceOptions(imtxs.mtx.ptx.FixedGasPricing)
+	return &gasPricing
+}
+
 func (imtxs *inMemoryTxState) GetInFlightStatus() InFlightStatus {
 	return imtxs.mtx.InFlightStatus
 }
@@ -31,6 +31,8 @@ type NextNonceCallback func(ctx context.Context, signer tktypes.EthAddress) (uin
 type NonceAssignmentIntent interface {
 	Complete(ctx context.Context)
 	AssignNextNonce(ctx context.Context) (uint64, error)
+	PeekNextNonce() uint64
+	LockNextNonce(ctx context.Context) (uint64, error)
 	Address() tktypes.EthAddress
 	Rollback(ctx context.Context)
 }
@@ -203,6 +205,33 @@ func (i *nonceAssignmentIntent) AssignNextNonce(ctx context.Context) (uint64, er
 	return value, nil
 }
 
+// PeekNextNonce returns the nonce the next call to AssignNextNonce would return, without assigning it.
+// If another intent has assigned nonces for the signing address and not yet completed, this waits for it to
+// complete or roll back, so the value is only a prediction for as long as this intent has not assigned itself
+func (i *nonceAssignmentIntent) PeekNextNonce() uint64 {
+	if i.locked {
+		return i.cachedNonce.value
+	}
+	i.cachedNonce.nonceMux.Lock()
+	defer i.cachedNonce.nonceMux.Unlock()
+	return i.cachedNonce.value
+}
+
+// LockNextNonce takes the same lock as AssignNextNonce without assigning a nonce, and returns the next nonce
+// as it was before this intent assigned any. Nothing else can assign a nonce for the signing address
+// until Complete or Rollback is called, so the caller can safely use a nonce below the returned value
+func (i *nonceAssignmentIntent) LockNextNonce(ctx context.Context) (uint64, error) {
+	if i.completed {
+		return 0, i18n.NewError(ctx, msgs.MsgPublicBatchCompleted)
+	}
+	if !i.locked {
+		i.cachedNonce.nonceMux.Lock()
+		i.initialValue = i.cachedNonce.value
+		i.locked = true
+	}
+	return i.initialValue, nil
+}
+
 func (i *nonceAssignmentIntent) Complete(ctx context.Context) {
 	//If we never took the lock or if we have already completed, then this is a no-op
 	if !i.completed && i.locked {
@@ -97,6 +97,25 @@ func TestAssignNonce(t *testing.T) {
 
 }
 
+func TestPeekNextNonce(t *testing.T) {
+	ctx := context.Background()
+	nonceCache := newNonceCacheForTesting(func(ctx context.Context, signer tktypes.EthAddress) (uint64, error) {
+		return uint64(42), nil
+	})
+	defer nonceCache.Stop()
+	intent, err := nonceCache.IntentToAssignNonce(ctx, tktypes.EthAddress(tktypes.RandBytes(20)))
+	require.NoError(t, err)
+	defer intent.Rollback(ctx)
+
+	// peeking does not assign, either before or after this intent has taken the lock
+	assert.Equal(t, uint64(42), intent.PeekNextNonce())
+	assert.Equal(t, uint64(42), intent.PeekNextNonce())
+	nextNonce, err := intent.AssignNextNonce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), nextNonce)
+	assert.Equal(t, uint64(43), intent.PeekNextNonce())
+}
+
 func TestIntentToAssignNonceRollbackNoAssign(t *testing.T) {
 	ctx := context.Background()
 	callbackHasBeenCalled := false
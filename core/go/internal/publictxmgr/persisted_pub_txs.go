@@ -26,18 +26,20 @@ import (
 
 // public_transactions
 type DBPublicTxn struct {
-	SignerNonce     string                 `gorm:"column:signer_nonce;primaryKey"`
-	From            tktypes.EthAddress     `gorm:"column:from"`
-	Nonce           uint64                 `gorm:"column:nonce"`
-	Created         tktypes.Timestamp      `gorm:"column:created;autoCreateTime:nano"`
-	To              *tktypes.EthAddress    `gorm:"column:to"`
-	Gas             uint64                 `gorm:"column:gas"`
-	FixedGasPricing tktypes.RawJSON        `gorm:"column:fixed_gas_pricing"`
-	Value           *tktypes.HexUint256    `gorm:"column:value"`
-	Data            tktypes.HexBytes       `gorm:"column:data"`
-	Suspended       bool                   `gorm:"column:suspended"`                                // excluded from processing because it's suspended by user
-	Completed       *DBPublicTxnCompletion `gorm:"foreignKey:signer_nonce;references:signer_nonce"` // excluded from processing because it's done
-	Submissions     []*DBPubTxnSubmission  `gorm:"-"`                                               // we do the aggregation, not GORM
+	SignerNonce      string                 `gorm:"column:signer_nonce;primaryKey"`
+	From             tktypes.EthAddress     `gorm:"column:from"`
+	Nonce            uint64                 `gorm:"column:nonce"`
+	Created          tktypes.Timestamp      `gorm:"column:created;autoCreateTime:nano"`
+	To               *tktypes.EthAddress    `gorm:"column:to"`
+	Gas              uint64                 `gorm:"column:gas"`
+	FixedGasPricing  tktypes.RawJSON        `gorm:"column:fixed_gas_pricing"`
+	Value            *tktypes.HexUint256    `gorm:"column:value"`
+	Data             tktypes.HexBytes       `gorm:"column:data"`
+	ResubmitInterval *string                `gorm:"column:resubmit_interval"`
+	Suspended        bool                   `gorm:"column:suspended"`                                // excluded from processing because it's suspended by user
+	SignedTx         tktypes.HexBytes       `gorm:"column:signed_tx"`                                // only set for transactions signed externally, which we cannot re-sign
+	Completed        *DBPublicTxnCompletion `gorm:"foreignKey:signer_nonce;references:signer_nonce"` // excluded from processing because it's done
+	Submissions      []*DBPubTxnSubmission  `gorm:"-"`                                               // we do the aggregation, not GORM
 	// Binding is used only on queries by transaction (GORM doesn't seem to allow us to define a separate struct for this)
 	Binding *DBPublicTxnBinding `gorm:"foreignKey:signer_nonce;references:signer_nonce;"`
 }
@@ -61,6 +63,7 @@ type DBPubTxnSubmission struct {
 	Created         tktypes.Timestamp `gorm:"column:created;autoCreateTime:false"` // we set this as we track the record in memory too
 	TransactionHash tktypes.Bytes32   `gorm:"column:tx_hash"`
 	GasPricing      tktypes.RawJSON   `gorm:"column:gas_pricing"` // no filtering allowed on this field as it's complex JSON gasPrice/maxFeePerGas/maxPriorityFeePerGas calculation
+	GapFill         bool              `gorm:"column:gap_fill"`    // a gap-fill transaction that used the nonce, so the transaction fails if it is mined
 }
 
 func (DBPubTxnSubmission) TableName() string {
@@ -79,6 +82,36 @@ func (DBPublicTxnCompletion) TableName() string {
 	return "public_completions"
 }
 
+// public_txn_rejections - only written when configured to persist rejected transactions
+type DBPublicTxnRejection struct {
+	ID              uuid.UUID                             `gorm:"column:id;primaryKey"`
+	Created         tktypes.Timestamp                     `gorm:"column:created;autoCreateTime:false"`
+	From            tktypes.EthAddress                    `gorm:"column:from"`
+	To              *tktypes.EthAddress                   `gorm:"column:to"`
+	Gas             *uint64                               `gorm:"column:gas"`
+	FixedGasPricing tktypes.RawJSON                       `gorm:"column:fixed_gas_pricing"`
+	Value           *tktypes.HexUint256                   `gorm:"column:value"`
+	Data            tktypes.HexBytes                      `gorm:"column:data"`
+	RejectReason    string                                `gorm:"column:reject_reason"`
+	RevertData      tktypes.HexBytes                      `gorm:"column:revert_data"`
+	Transaction     *uuid.UUID                            `gorm:"column:transaction"`
+	TransactionType *tktypes.Enum[pldapi.TransactionType] `gorm:"column:tx_type"`
+}
+
+func (DBPublicTxnRejection) TableName() string {
+	return "public_txn_rejections"
+}
+
+// public_txn_resubmits - an out of gas transaction waiting to be resubmitted with a higher gas limit
+type DBPublicTxnResubmit struct {
+	SignerNonce string            `gorm:"column:signer_nonce;primaryKey"`
+	Created     tktypes.Timestamp `gorm:"column:created;autoCreateTime:false"`
+}
+
+func (DBPublicTxnResubmit) TableName() string {
+	return "public_txn_resubmits"
+}
+
 func (s *DBPubTxnSubmission) WriteKey() string {
 	// Just use the from address as the write key, so all submissions on the same signing address get batched together
 	return strings.Split(s.SignerNonce, ":")[0]
@@ -86,7 +119,8 @@ func (s *DBPubTxnSubmission) WriteKey() string {
 
 type bindingsMatchingSubmission struct {
 	DBPublicTxnBinding `gorm:"embedded"`
-	Submission         *DBPubTxnSubmission `gorm:"foreignKey:signer_nonce;references:signer_nonce;"`
+	Submission         *DBPubTxnSubmission    `gorm:"foreignKey:signer_nonce;references:signer_nonce;"`
+	Completed          *DBPublicTxnCompletion `gorm:"foreignKey:signer_nonce;references:signer_nonce;"`
 }
 
 type txFromOnly struct {
@@ -0,0 +1,72 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package publictxmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+const rejectedExpiryBatchSize = 1000
+
+func (ble *pubTxManager) rejectedRetentionLoop() {
+	defer close(ble.rejectedRetentionDone)
+
+	ctx := log.WithLogField(ble.ctx, "role", "rejected_tx_retention")
+	ticker := time.NewTicker(ble.rejectedCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := ble.deleteExpiredRejections(ctx); err != nil {
+				log.L(ctx).Errorf("Failed to delete expired rejected transactions: %s", err)
+			}
+		}
+	}
+}
+
+// Records of rejected transactions are only kept for audit and debugging, so they are deleted
+// once they are older than the retention period, in batches to keep each DB transaction small.
+func (ble *pubTxManager) deleteExpiredRejections(ctx context.Context) (deletedCount int, err error) {
+	cutoff := tktypes.Timestamp(time.Now().Add(-ble.rejectedMaxRetention).UnixNano())
+	for {
+		expired := ble.p.DB().
+			Table("public_txn_rejections").
+			Select("id").
+			Where("created < ?", cutoff).
+			Limit(rejectedExpiryBatchSize)
+		result := ble.p.DB().
+			WithContext(ctx).
+			Table("public_txn_rejections").
+			Where("id IN (?)", expired).
+			Delete(nil)
+		if result.Error != nil {
+			return deletedCount, result.Error
+		}
+		deletedCount += int(result.RowsAffected)
+		if result.RowsAffected < rejectedExpiryBatchSize {
+			if deletedCount > 0 {
+				log.L(ctx).Infof("Deleted %d expired rejected transaction records", deletedCount)
+			}
+			return deletedCount, nil
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package publictxmgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/pkg/ethclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteExpiredRejections(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.PersistRejected = confutil.P(true)
+		conf.Manager.RejectedRetention.MaxRetention = confutil.P("1h")
+	})
+	defer done()
+
+	from := tktypes.RandAddress()
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{}, fmt.Errorf("execution reverted"))
+	for i := 0; i < 2; i++ {
+		_, err := ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+			PublicTxInput: pldapi.PublicTxInput{From: from},
+		})
+		assert.Regexp(t, "execution reverted", err)
+	}
+	rejections, err := ble.QueryPublicTxRejections(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(10).Equal("from", from).Sort("created").Query())
+	require.NoError(t, err)
+	require.Len(t, rejections, 2)
+
+	// Age the first record beyond the retention period
+	err = ble.p.DB().Table("public_txn_rejections").
+		Where("id = ?", rejections[0].ID).
+		Update("created", tktypes.Timestamp(time.Now().Add(-2*time.Hour).UnixNano())).
+		Error
+	require.NoError(t, err)
+
+	deleted, err := ble.deleteExpiredRejections(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	remaining, err := ble.QueryPublicTxRejections(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(10).Equal("from", from).Query())
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, rejections[1].ID, remaining[0].ID)
+
+	deleted, err = ble.deleteExpiredRejections(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func TestRejectedRetentionLoop(t *testing.T) {
+	_, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+		conf.Manager.PersistRejected = confutil.P(true)
+	})
+	defer done()
+
+	// Errors are just logged
+	m.db.ExpectExec("DELETE.*public_txn_rejections").WillReturnError(fmt.Errorf("pop"))
+	ble.rejectedCheckInterval = 1 * time.Millisecond
+	ble.rejectedRetentionDone = make(chan struct{})
+	go ble.rejectedRetentionLoop()
+	require.Eventually(t, func() bool { return m.db.ExpectationsWereMet() == nil }, 5*time.Second, 1*time.Millisecond)
+}
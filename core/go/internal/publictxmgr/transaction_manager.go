@@ -16,6 +16,7 @@
 package publictxmgr
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -62,6 +63,8 @@ const (
 	UpdateDelete                   // Instructs that the transaction should be removed completely from persistence - generally only returned when TX status is TxStatusDeleteRequested
 )
 
+const outOfGasRecoveryBatchSize = 100
+
 // Public Tx Engine:
 // - It offers two ways of calculating gas price: use a fixed number, use the built-in API of a ethereum connector
 // - It resubmits the transaction based on a configured interval until it succeed or fail
@@ -92,8 +95,11 @@ type pubTxManager struct {
 	// a map of signing addresses and transaction engines
 	inFlightOrchestrators       map[tktypes.EthAddress]*orchestrator
 	signingAddressesPausedUntil map[tktypes.EthAddress]time.Time
-	inFlightOrchestratorMux     sync.Mutex
-	inFlightOrchestratorStale   chan bool
+	submissionFailurePauses     map[tktypes.EthAddress]int
+	// the subset of the paused signing addresses that are waiting for their balance to be topped up
+	signingAddressesPausedForBalance map[tktypes.EthAddress]bool
+	inFlightOrchestratorMux          sync.Mutex
+	inFlightOrchestratorStale        chan bool
 
 	// inbound concurrency control TBD
 
@@ -103,9 +109,23 @@ type pubTxManager struct {
 	orchestratorStaleTimeout time.Duration
 	orchestratorSwapTimeout  time.Duration
 	retry                    *retry.Retry
+	failurePauseThreshold    int
+	failurePauseBackoff      *retry.Retry
+	gasReservePause          time.Duration
 	enginePollingInterval    time.Duration
 	nonceCacheTimeout        time.Duration
+	confirmationConcurrency  int
+	persistRejected          bool
+	rejectedMaxRetention     time.Duration
+	rejectedCheckInterval    time.Duration
 	engineLoopDone           chan struct{}
+	rejectedRetentionDone    chan struct{}
+	outOfGasRecoveryDone     chan struct{}
+
+	// out of gas resubmission
+	outOfGasRetry           bool
+	outOfGasIncreasePercent int
+	outOfGasMaxAttempts     int
 
 	activityRecordCache     cache.Cache[string, *txActivityRecords]
 	maxActivityRecordsPerTx int
@@ -128,29 +148,47 @@ func NewPublicTransactionManager(ctx context.Context, conf *pldconf.PublicTxMana
 
 	gasPriceClient := NewGasPriceClient(ctx, conf)
 	gasPriceIncreaseMax := confutil.BigIntOrNil(conf.GasPrice.IncreaseMax)
+	gasPriceIncreasePercent := confutil.Int(conf.GasPrice.IncreasePercentage, *pldconf.PublicTxManagerDefaults.GasPrice.IncreasePercentage)
+	gasPriceMinIncreasePercent := confutil.Int(conf.GasPrice.MinIncreasePercentage, *pldconf.PublicTxManagerDefaults.GasPrice.MinIncreasePercentage)
+	if gasPriceIncreasePercent < gasPriceMinIncreasePercent {
+		log.L(ctx).Debugf("Gas price increase percentage %d raised to the minimum of %d", gasPriceIncreasePercent, gasPriceMinIncreasePercent)
+		gasPriceIncreasePercent = gasPriceMinIncreasePercent
+	}
 
 	log.L(ctx).Debugf("Enterprise transaction handler created")
 
 	ptmCtx, ptmCtxCancel := context.WithCancel(log.WithLogField(ctx, "role", "public_tx_mgr"))
 
 	return &pubTxManager{
-		ctx:                         ptmCtx,
-		ctxCancel:                   ptmCtxCancel,
-		conf:                        conf,
-		gasPriceClient:              gasPriceClient,
-		inFlightOrchestratorStale:   make(chan bool, 1),
-		signingAddressesPausedUntil: make(map[tktypes.EthAddress]time.Time),
-		maxInflight:                 confutil.IntMin(conf.Manager.MaxInFlightOrchestrators, 1, *pldconf.PublicTxManagerDefaults.Manager.MaxInFlightOrchestrators),
-		orchestratorSwapTimeout:     confutil.DurationMin(conf.Manager.OrchestratorSwapTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorSwapTimeout),
-		orchestratorStaleTimeout:    confutil.DurationMin(conf.Manager.OrchestratorStaleTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorStaleTimeout),
-		orchestratorIdleTimeout:     confutil.DurationMin(conf.Manager.OrchestratorIdleTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorIdleTimeout),
-		enginePollingInterval:       confutil.DurationMin(conf.Manager.Interval, 50*time.Millisecond, *pldconf.PublicTxManagerDefaults.Manager.Interval),
-		nonceCacheTimeout:           confutil.DurationMin(conf.Manager.NonceCacheTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.NonceCacheTimeout),
-		retry:                       retry.NewRetryIndefinite(&conf.Manager.Retry),
-		gasPriceIncreaseMax:         gasPriceIncreaseMax,
-		gasPriceIncreasePercent:     confutil.Int(conf.GasPrice.IncreasePercentage, *pldconf.PublicTxManagerDefaults.GasPrice.IncreasePercentage),
-		activityRecordCache:         cache.NewCache[string, *txActivityRecords](&conf.Manager.ActivityRecords.CacheConfig, &pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.CacheConfig),
-		maxActivityRecordsPerTx:     confutil.Int(conf.Manager.ActivityRecords.RecordsPerTransaction, *pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.RecordsPerTransaction),
+		ctx:                              ptmCtx,
+		ctxCancel:                        ptmCtxCancel,
+		conf:                             conf,
+		gasPriceClient:                   gasPriceClient,
+		inFlightOrchestratorStale:        make(chan bool, 1),
+		signingAddressesPausedUntil:      make(map[tktypes.EthAddress]time.Time),
+		submissionFailurePauses:          make(map[tktypes.EthAddress]int),
+		signingAddressesPausedForBalance: make(map[tktypes.EthAddress]bool),
+		maxInflight:                      confutil.IntMin(conf.Manager.MaxInFlightOrchestrators, 1, *pldconf.PublicTxManagerDefaults.Manager.MaxInFlightOrchestrators),
+		orchestratorSwapTimeout:          confutil.DurationMin(conf.Manager.OrchestratorSwapTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorSwapTimeout),
+		orchestratorStaleTimeout:         confutil.DurationMin(conf.Manager.OrchestratorStaleTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorStaleTimeout),
+		orchestratorIdleTimeout:          confutil.DurationMin(conf.Manager.OrchestratorIdleTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.OrchestratorIdleTimeout),
+		enginePollingInterval:            confutil.DurationMin(conf.Manager.Interval, 50*time.Millisecond, *pldconf.PublicTxManagerDefaults.Manager.Interval),
+		nonceCacheTimeout:                confutil.DurationMin(conf.Manager.NonceCacheTimeout, 0, *pldconf.PublicTxManagerDefaults.Manager.NonceCacheTimeout),
+		confirmationConcurrency:          confutil.IntMin(conf.Manager.ConfirmationConcurrency, 1, *pldconf.PublicTxManagerDefaults.Manager.ConfirmationConcurrency),
+		persistRejected:                  confutil.Bool(conf.Manager.PersistRejected, *pldconf.PublicTxManagerDefaults.Manager.PersistRejected),
+		rejectedMaxRetention:             confutil.DurationMin(conf.Manager.RejectedRetention.MaxRetention, 0, *pldconf.PublicTxManagerDefaults.Manager.RejectedRetention.MaxRetention),
+		rejectedCheckInterval:            confutil.DurationMin(conf.Manager.RejectedRetention.CheckInterval, 1*time.Second, *pldconf.PublicTxManagerDefaults.Manager.RejectedRetention.CheckInterval),
+		outOfGasRetry:                    confutil.Bool(conf.Manager.OutOfGasRetry.Enabled, *pldconf.PublicTxManagerDefaults.Manager.OutOfGasRetry.Enabled),
+		outOfGasIncreasePercent:          confutil.IntMin(conf.Manager.OutOfGasRetry.IncreasePercentage, 1, *pldconf.PublicTxManagerDefaults.Manager.OutOfGasRetry.IncreasePercentage),
+		outOfGasMaxAttempts:              confutil.IntMin(conf.Manager.OutOfGasRetry.MaxAttempts, 0, *pldconf.PublicTxManagerDefaults.Manager.OutOfGasRetry.MaxAttempts),
+		retry:                            retry.NewRetryIndefinite(&conf.Manager.Retry),
+		failurePauseThreshold:            confutil.IntMin(conf.Manager.SubmissionFailurePause.ConsecutiveFailures, 0, *pldconf.PublicTxManagerDefaults.Manager.SubmissionFailurePause.ConsecutiveFailures),
+		failurePauseBackoff:              retry.NewRetryIndefinite(&conf.Manager.SubmissionFailurePause.Backoff, &pldconf.PublicTxManagerDefaults.Manager.SubmissionFailurePause.Backoff),
+		gasReservePause:                  confutil.DurationMin(conf.BalanceManager.GasReservePause, 0, *pldconf.PublicTxManagerDefaults.BalanceManager.GasReservePause),
+		gasPriceIncreaseMax:              gasPriceIncreaseMax,
+		gasPriceIncreasePercent:          gasPriceIncreasePercent,
+		activityRecordCache:              cache.NewCache[string, *txActivityRecords](&conf.Manager.ActivityRecords.CacheConfig, &pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.CacheConfig),
+		maxActivityRecordsPerTx:          confutil.Int(conf.Manager.ActivityRecords.RecordsPerTransaction, *pldconf.PublicTxManagerDefaults.Manager.ActivityRecords.RecordsPerTransaction),
 	}
 }
 
@@ -202,6 +240,16 @@ func (ble *pubTxManager) Start() error {
 		log.L(ctx).Debugf("Kicking off  enterprise handler engine loop")
 		go ble.engineLoop()
 	}
+	if ble.persistRejected && ble.rejectedMaxRetention > 0 && ble.rejectedRetentionDone == nil {
+		ble.rejectedRetentionDone = make(chan struct{})
+		go ble.rejectedRetentionLoop()
+	}
+	if ble.outOfGasRecoveryDone == nil {
+		// resubmissions pending from before we started are recovered even if out of gas retry has since been disabled,
+		// as the failure receipt was not written for them
+		ble.outOfGasRecoveryDone = make(chan struct{})
+		go ble.recoverOutOfGasResubmits(tktypes.TimestampNow())
+	}
 	ble.MarkInFlightOrchestratorsStale()
 	ble.submissionWriter.Start()
 	log.L(ctx).Infof("Started public transaction manager")
@@ -219,6 +267,12 @@ func (ble *pubTxManager) Stop() {
 	if ble.engineLoopDone != nil {
 		<-ble.engineLoopDone
 	}
+	if ble.rejectedRetentionDone != nil {
+		<-ble.rejectedRetentionDone
+	}
+	if ble.outOfGasRecoveryDone != nil {
+		<-ble.outOfGasRecoveryDone
+	}
 }
 
 type preparedTransaction struct {
@@ -227,6 +281,8 @@ type preparedTransaction struct {
 	rejectError error                 // only if rejected
 	revertData  tktypes.HexBytes      // only if rejected, and was available
 	nsi         NonceAssignmentIntent // only if accepted
+	signedTx    tktypes.HexBytes      // only if signed externally
+	signedNonce uint64                // only if signed externally
 }
 
 type preparedTransactionBatch struct {
@@ -243,7 +299,7 @@ func (pb *preparedTransactionBatch) Submit(ctx context.Context, dbTX *gorm.DB) (
 	publicTxBindings := make([]*DBPublicTxnBinding, 0, len(pb.accepted))
 	for i, accepted := range pb.accepted {
 		ptx := accepted.(*preparedTransaction)
-		persistedTransactions[i], err = pb.ble.finalizeNonceForPersistedTX(ctx, ptx)
+		persistedTransactions[i], err = pb.ble.finalizeNonceForPersistedTX(ctx, dbTX, ptx)
 		if err != nil {
 			return err
 		}
@@ -271,19 +327,40 @@ func (pb *preparedTransactionBatch) Submit(ctx context.Context, dbTX *gorm.DB) (
 			Create(publicTxBindings).
 			Error
 	}
+	if err == nil {
+		err = pb.PersistRejected(ctx, dbTX)
+	}
 
 	return err
 }
 
+// PersistRejected writes a record of the rejected transactions, if configured to, in the caller's DB transaction
+func (pb *preparedTransactionBatch) PersistRejected(ctx context.Context, dbTX *gorm.DB) error {
+	if !pb.ble.persistRejected {
+		return nil
+	}
+	for _, rejected := range pb.rejected {
+		if err := pb.ble.persistRejection(ctx, dbTX, rejected.(*preparedTransaction)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (pb *preparedTransactionBatch) Accepted() []components.PublicTxAccepted { return pb.accepted }
 func (pb *preparedTransactionBatch) Rejected() []components.PublicTxRejected { return pb.rejected }
 
 func (pb *preparedTransactionBatch) Completed(ctx context.Context, committed bool) {
 	for _, pt := range pb.accepted {
+		nsi := pt.(*preparedTransaction).nsi
+		if nsi == nil {
+			// signed transaction rejected before the nonce was assigned
+			continue
+		}
 		if committed {
-			pt.(*preparedTransaction).nsi.Complete(ctx)
+			nsi.Complete(ctx)
 		} else {
-			pt.(*preparedTransaction).nsi.Rollback(ctx)
+			nsi.Rollback(ctx)
 		}
 	}
 	if committed && len(pb.accepted) > 0 {
@@ -343,6 +420,11 @@ func (ble *pubTxManager) PrepareSubmissionBatch(ctx context.Context, transaction
 // public transaction interface for the special case of a single transaction that will succeed or fail.
 // Other callers have to handle the Accepted()/Rejected() list to decide what they do for a split result.
 func (ble *pubTxManager) SingleTransactionSubmit(ctx context.Context, transaction *components.PublicTxSubmission) (components.PublicTxAccepted, error) {
+	return ble.singleTransactionSubmit(ctx, transaction, nil)
+}
+
+// inTX, if set, is called in the same DB transaction that the accepted transaction is written in
+func (ble *pubTxManager) singleTransactionSubmit(ctx context.Context, transaction *components.PublicTxSubmission, inTX func(dbTX *gorm.DB) error) (components.PublicTxAccepted, error) {
 	batch, err := ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{transaction})
 	if err != nil {
 		return nil, err
@@ -354,9 +436,21 @@ func (ble *pubTxManager) SingleTransactionSubmit(ctx context.Context, transactio
 	}()
 	// Try to submit
 	if len(batch.Rejected()) > 0 {
+		if ble.persistRejected {
+			if err := ble.p.DB().Transaction(func(dbTX *gorm.DB) error {
+				return batch.PersistRejected(ctx, dbTX)
+			}); err != nil {
+				return nil, err
+			}
+		}
 		return nil, batch.Rejected()[0].RejectedError()
 	}
 	err = ble.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		if inTX != nil {
+			if err := inTX(dbTX); err != nil {
+				return err
+			}
+		}
 		return batch.Submit(ctx, dbTX)
 	})
 	if err != nil {
@@ -392,6 +486,27 @@ func buildEthTX(
 	return ethTx
 }
 
+func (ble *pubTxManager) EstimateGas(ctx context.Context, txi *pldapi.PublicTxInput) (tktypes.HexUint64, error) {
+	if txi.From == nil {
+		return 0, i18n.NewError(ctx, msgs.MsgInvalidTXMissingFromAddr)
+	}
+	gasEstimateResult, err := ble.ethClient.EstimateGasNoResolve(ctx, buildEthTX(
+		*txi.From,
+		nil, /* nonce not assigned */
+		txi.To,
+		txi.Data,
+		&txi.PublicTxOptions,
+	))
+	if err != nil {
+		if len(gasEstimateResult.RevertData) > 0 {
+			// the revert data is more useful to the caller than the error from the node
+			return 0, ble.rootTxMgr.CalculateRevertError(ctx, ble.p.DB(), gasEstimateResult.RevertData)
+		}
+		return 0, err
+	}
+	return gasEstimateResult.GasLimit, nil
+}
+
 // PrepareSubmission prepares and validates the transaction input data so that a later call to
 // Submit can be made in the middle of a wider database transaction with minimal risk of error
 func (ble *pubTxManager) prepareSubmission(ctx context.Context, batchSoFar []*preparedTransaction, txi *components.PublicTxSubmission) (preparedSubmission *preparedTransaction, err error) {
@@ -406,16 +521,37 @@ func (ble *pubTxManager) prepareSubmission(ctx context.Context, batchSoFar []*pr
 		},
 	}
 
-	if txi.From == nil {
+	if txi.SignedTransaction != nil {
+		// Everything we need comes from the signed payload, so there is no gas estimation to do
+		if err := ble.decodeSignedTransaction(ctx, txi, pt); err != nil {
+			return nil, err
+		}
+	} else if txi.From == nil {
 		return nil, i18n.NewError(ctx, msgs.MsgInvalidTXMissingFromAddr)
+	} else {
+		pt.tx.From = *txi.From
+	}
+
+	if txi.ResubmitInterval != nil {
+		if d, err := time.ParseDuration(*txi.ResubmitInterval); err != nil || d <= 0 {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidResubmitInterval, *txi.ResubmitInterval)
+		}
+	}
+
+	if txi.PublicTxOptions.Nonce != nil {
+		if pt.signedTx != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgNonceOverrideSignedTransaction)
+		}
+		if err := ble.checkNonceOverride(ctx, batchSoFar, pt.tx.From, txi.PublicTxOptions.Nonce.Uint64()); err != nil {
+			return nil, err
+		}
 	}
-	pt.tx.From = *txi.From
 
 	prepareStart := time.Now()
 	var txType InFlightTxOperation
 
 	rejected := false
-	if pt.tx.Gas == nil || *pt.tx.Gas == 0 {
+	if pt.signedTx == nil && (pt.tx.Gas == nil || *pt.tx.Gas == 0) {
 		gasEstimateResult, err := ble.ethClient.EstimateGasNoResolve(ctx, buildEthTX(
 			*txi.From,
 			nil, /* nonce not assigned at this point */
@@ -432,6 +568,7 @@ func (ble *pubTxManager) prepareSubmission(ctx context.Context, batchSoFar []*pr
 				if len(gasEstimateResult.RevertData) > 0 {
 					// we can use the error dictionary callback to TXManager to look up the ABI
 					// Note: The ABI is already persisted before TXManager calls down into us.
+					pt.revertData = gasEstimateResult.RevertData
 					pt.rejectError = ble.rootTxMgr.CalculateRevertError(ctx, ble.p.DB(), gasEstimateResult.RevertData)
 					log.L(ctx).Warnf("Estimate gas reverted (%s): %s", err, pt.rejectError)
 				}
@@ -446,20 +583,49 @@ func (ble *pubTxManager) prepareSubmission(ctx context.Context, batchSoFar []*pr
 	}
 
 	if !rejected {
-		// Need to check for an existing NSI for the address in the batch
+		// Need to check for an existing NSI for the address in the batch.
+		// A nonce override also holds an intent, so that it is checked against the nonce cache.
+		var assignedInBatch uint64
 		for _, alreadyInBatch := range batchSoFar {
 			if alreadyInBatch.nsi != nil && alreadyInBatch.nsi.Address() == pt.tx.From {
 				pt.nsi = alreadyInBatch.nsi
+				if alreadyInBatch.tx.PublicTxOptions.Nonce == nil {
+					assignedInBatch++
+				}
 			}
 		}
+		var newIntent bool
 		if pt.nsi == nil {
 			pt.nsi, err = ble.nonceManager.IntentToAssignNonce(ctx, pt.tx.From)
+			newIntent = true
 		}
 		if err != nil {
 			log.L(ctx).Errorf("HandleNewTx <%s> error assigning nonce for transaction: %+v, request: (%+v)", txType, err, pt.tx)
 			ble.thMetrics.RecordOperationMetrics(ctx, string(txType), string(GenericStatusFail), time.Since(prepareStart).Seconds())
 			return nil, err
 		}
+		if pt.tx.PublicTxOptions.Nonce != nil {
+			// This is checked again holding the nonce lock when the batch is written, but we catch it here
+			// so that the caller gets the error before the batch is built
+			if nonce, nextNonce := pt.tx.PublicTxOptions.Nonce.Uint64(), pt.nsi.PeekNextNonce(); nonce >= nextNonce {
+				if newIntent {
+					pt.nsi.Rollback(ctx)
+				}
+				return nil, i18n.NewError(ctx, msgs.MsgNonceOverrideNotAssigned, nonce, pt.tx.From, nextNonce)
+			}
+		}
+		if pt.signedTx != nil {
+			// We cannot re-sign, so a payload that does not have the nonce we are going to assign is rejected
+			// on its own here, rather than failing the whole batch when the nonces are assigned
+			if expectedNonce := pt.nsi.PeekNextNonce() + assignedInBatch; expectedNonce != pt.signedNonce {
+				if newIntent {
+					pt.nsi.Rollback(ctx)
+				}
+				pt.nsi = nil
+				pt.rejectError = i18n.NewError(ctx, msgs.MsgSignedTransactionNonceMismatch, pt.tx.From, pt.signedNonce, expectedNonce)
+				return pt, nil
+			}
+		}
 	}
 
 	ble.thMetrics.RecordOperationMetrics(ctx, string(txType), string(GenericStatusSuccess), time.Since(prepareStart).Seconds())
@@ -468,24 +634,158 @@ func (ble *pubTxManager) prepareSubmission(ctx context.Context, batchSoFar []*pr
 
 }
 
-func (ble *pubTxManager) finalizeNonceForPersistedTX(ctx context.Context, ptx *preparedTransaction) (*DBPublicTxn, error) {
-	nonce, err := ptx.nsi.AssignNextNonce(ctx)
+// A nonce override is for filling a gap below the next nonce of the nonce cache, so we check the nonce has
+// not been used on chain by a submission outside of Paladin. A nonce at or beyond the next nonce of the cache
+// is refused, as the cache would assign it again. Whether it is already assigned to a public transaction
+// (in-flight or completed) is checked when the batch is written, holding the nonce lock for the address.
+func (ble *pubTxManager) checkNonceOverride(ctx context.Context, batchSoFar []*preparedTransaction, from tktypes.EthAddress, nonce uint64) error {
+	for _, alreadyInBatch := range batchSoFar {
+		if alreadyInBatch.tx.From == from && alreadyInBatch.tx.PublicTxOptions.Nonce != nil && alreadyInBatch.tx.PublicTxOptions.Nonce.Uint64() == nonce {
+			return i18n.NewError(ctx, msgs.MsgNonceOverrideInUse, nonce, from)
+		}
+	}
+	nextNonce, err := ble.ethClient.GetTransactionCount(ctx, from)
 	if err != nil {
-		log.L(ctx).Errorf("Failed to assign nonce to public transaction %+v: %s", ptx, err)
-		return nil, err
+		return err
+	}
+	if nonce < nextNonce.Uint64() {
+		return i18n.NewError(ctx, msgs.MsgNonceOverrideUsedOnChain, nonce, from, nextNonce.Uint64())
+	}
+	return nil
+}
+
+func (ble *pubTxManager) decodeSignedTransaction(ctx context.Context, txi *components.PublicTxSubmission, pt *preparedTransaction) error {
+	signer, ethTx, err := ethsigner.RecoverRawTransaction(ctx, ethtypes.HexBytes0xPrefix(txi.SignedTransaction), ble.ethClient.ChainID())
+	if err != nil {
+		return i18n.WrapError(ctx, err, msgs.MsgInvalidSignedTransaction)
+	}
+	from := tktypes.EthAddress(*signer)
+	if txi.From != nil && *txi.From != from {
+		return i18n.NewError(ctx, msgs.MsgSignedTransactionFromMismatch, from, txi.From)
+	}
+	signedTo := (*tktypes.EthAddress)(ethTx.To)
+	if txi.Data != nil && (!txi.To.Equals(signedTo) || !bytes.Equal(txi.Data, ethTx.Data)) {
+		// when the caller also supplies the transaction, the signed payload must be for the same thing
+		return i18n.NewError(ctx, msgs.MsgSignedTransactionMismatch, txi.To, signedTo)
 	}
+	pt.tx.From = from
+	pt.tx.To = signedTo
+	pt.tx.Data = tktypes.HexBytes(ethTx.Data)
+	pt.tx.Gas = confutil.P(tktypes.HexUint64(ethTx.GasLimit.Uint64()))
+	pt.tx.Value = (*tktypes.HexUint256)(ethTx.Value)
+	pt.tx.PublicTxGasPricing = pldapi.PublicTxGasPricing{
+		GasPrice:             (*tktypes.HexUint256)(ethTx.GasPrice),
+		MaxFeePerGas:         (*tktypes.HexUint256)(ethTx.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*tktypes.HexUint256)(ethTx.MaxPriorityFeePerGas),
+	}
+	pt.signedTx = txi.SignedTransaction
+	pt.signedNonce = ethTx.Nonce.Uint64()
+	return nil
+}
+
+func (ble *pubTxManager) persistRejection(ctx context.Context, dbTX *gorm.DB, pt *preparedTransaction) error {
+	tx := pt.tx
+	rejection := DBPublicTxnRejection{
+		Created:      tktypes.TimestampNow(),
+		From:         tx.From,
+		To:           tx.To,
+		Gas:          (*uint64)(tx.Gas),
+		Value:        tx.Value,
+		Data:         tx.Data,
+		RejectReason: pt.rejectError.Error(),
+		RevertData:   pt.revertData,
+	}
+	if tx.PublicTxGasPricing != (pldapi.PublicTxGasPricing{}) {
+		rejection.FixedGasPricing = tktypes.JSONString(tx.PublicTxGasPricing)
+	}
+	// One record per binding, consistent with how we return public transactions with bindings
+	rejections := make([]*DBPublicTxnRejection, 0, len(pt.bindings))
+	for _, bnd := range pt.bindings {
+		r := rejection
+		r.ID = uuid.New()
+		r.Transaction = &bnd.TransactionID
+		r.TransactionType = &bnd.TransactionType
+		rejections = append(rejections, &r)
+	}
+	if len(rejections) == 0 {
+		rejection.ID = uuid.New()
+		rejections = append(rejections, &rejection)
+	}
+	log.L(ctx).Infof("Persisting rejected public transaction from=%s to=%s: %s", tx.From, tx.To, rejection.RejectReason)
+	return dbTX.
+		WithContext(ctx).
+		Table("public_txn_rejections").
+		Create(rejections).
+		Error
+}
+
+// Holding the nonce lock for the address until the DB transaction completes, so no other batch can assign
+// the nonce, or override it, between the check and the insert
+func (ble *pubTxManager) checkNonceOverrideUnassigned(ctx context.Context, dbTX *gorm.DB, ptx *preparedTransaction, nonce uint64) error {
+	nextNonce, err := ptx.nsi.LockNextNonce(ctx)
+	if err != nil {
+		return err
+	}
+	if nonce >= nextNonce {
+		return i18n.NewError(ctx, msgs.MsgNonceOverrideNotAssigned, nonce, ptx.tx.From, nextNonce)
+	}
+	var ptxs []*DBPublicTxn
+	err = dbTX.
+		WithContext(ctx).
+		Table("public_txns").
+		Where("signer_nonce = ?", fmt.Sprintf("%s:%d", ptx.tx.From, nonce)).
+		Limit(1).
+		Find(&ptxs).
+		Error
+	if err != nil {
+		return err
+	}
+	if len(ptxs) > 0 {
+		return i18n.NewError(ctx, msgs.MsgNonceOverrideInUse, nonce, ptx.tx.From)
+	}
+	return nil
+}
+
+func (ble *pubTxManager) finalizeNonceForPersistedTX(ctx context.Context, dbTX *gorm.DB, ptx *preparedTransaction) (*DBPublicTxn, error) {
 	tx := ptx.tx
+	var nonce uint64
+	if tx.PublicTxOptions.Nonce != nil {
+		nonce = tx.PublicTxOptions.Nonce.Uint64()
+		if err := ble.checkNonceOverrideUnassigned(ctx, dbTX, ptx, nonce); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		nonce, err = ptx.nsi.AssignNextNonce(ctx)
+		if err != nil {
+			log.L(ctx).Errorf("Failed to assign nonce to public transaction %+v: %s", ptx, err)
+			return nil, err
+		}
+	}
+	if ptx.signedTx != nil && nonce != ptx.signedNonce {
+		// We cannot re-sign, so the nonce in the payload must be the one we would allocate
+		return nil, i18n.NewError(ctx, msgs.MsgSignedTransactionNonceMismatch, tx.From, ptx.signedNonce, nonce)
+	}
 	tx.Nonce = tktypes.HexUint64(nonce)
 	log.L(ctx).Infof("Creating a new public transaction from=%s nonce=%d (%s)", tx.From, tx.Nonce /* number */, tx.Nonce /* hex */)
 	log.L(ctx).Tracef("payload: %+v", tx)
-	return &DBPublicTxn{
+	ptxn := &DBPublicTxn{
 		SignerNonce: fmt.Sprintf("%s:%d", tx.From, tx.Nonce), // having a single key rather than compound key helps us simplify cross-table correlation, particularly for batch lookup
 		From:        tx.From,
 		Nonce:       tx.Nonce.Uint64(),
 		To:          tx.To,
 		Gas:         tx.Gas.Uint64(),
 		Data:        tx.Data,
-	}, nil
+
+		ResubmitInterval: tx.ResubmitInterval,
+	}
+	if ptx.signedTx != nil {
+		// the value and gas pricing are fixed by the signature
+		ptxn.SignedTx = ptx.signedTx
+		ptxn.Value = tx.Value
+		ptxn.FixedGasPricing = tktypes.JSONString(tx.PublicTxGasPricing)
+	}
+	return ptxn, nil
 }
 
 func recoverGasPriceOptions(gpoJSON tktypes.RawJSON) (ptgp pldapi.PublicTxGasPricing) {
@@ -501,6 +801,44 @@ func (ble *pubTxManager) QueryPublicTxWithBindings(ctx context.Context, dbTX *go
 	return ble.queryPublicTxWithBinding(ctx, dbTX, nil, jq)
 }
 
+// Component interface: query the rejected public transactions, which are only persisted if configured.
+// Returns one record per binding, as with accepted transactions
+func (ble *pubTxManager) QueryPublicTxRejections(ctx context.Context, dbTX *gorm.DB, jq *query.QueryJSON) ([]*pldapi.PublicTxRejection, error) {
+	q := dbTX.Table("public_txn_rejections").WithContext(ctx)
+	if jq != nil {
+		q = filters.BuildGORM(ctx, jq, q, components.PublicTxRejectionFilterFields)
+	}
+	var dbRejections []*DBPublicTxnRejection
+	if err := q.Find(&dbRejections).Error; err != nil {
+		return nil, err
+	}
+	results := make([]*pldapi.PublicTxRejection, len(dbRejections))
+	for i, r := range dbRejections {
+		results[i] = &pldapi.PublicTxRejection{
+			ID:           r.ID,
+			Created:      r.Created,
+			From:         r.From,
+			To:           r.To,
+			Data:         r.Data,
+			Submitted:    false,
+			RejectReason: r.RejectReason,
+			RevertData:   r.RevertData,
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Gas:                (*tktypes.HexUint64)(r.Gas),
+				Value:              r.Value,
+				PublicTxGasPricing: recoverGasPriceOptions(r.FixedGasPricing),
+			},
+		}
+		if r.Transaction != nil && r.TransactionType != nil {
+			results[i].PublicTxBinding = pldapi.PublicTxBinding{
+				Transaction:     *r.Transaction,
+				TransactionType: *r.TransactionType,
+			}
+		}
+	}
+	return results, nil
+}
+
 // Component interface: query the associated public transactions, for a set of parent Paladin transactions
 // Can return the same public transaction multiple times, if bound to multiple private transactions.
 // The results are grouped, so the caller can be assured to have exactly one entry in the map (even if an empty array) per supplied TX ID
@@ -650,6 +988,7 @@ func mapPersistedTransaction(ptx *DBPublicTxn) *pldapi.PublicTx {
 		PublicTxOptions: pldapi.PublicTxOptions{
 			Gas:                (*tktypes.HexUint64)(&ptx.Gas),
 			Value:              ptx.Value,
+			ResubmitInterval:   ptx.ResubmitInterval,
 			PublicTxGasPricing: recoverGasPriceOptions(ptx.FixedGasPricing),
 		},
 	}
@@ -792,8 +1131,29 @@ func (pte *pubTxManager) GetPublicTransactionForHash(ctx context.Context, dbTX *
 
 }
 
-// note this function guarantees the return order of the matches corresponds to the input order
-func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify) ([]*components.PublicTxMatch, error) {
+// Returns every submission made for the transaction, oldest first, including after it has completed
+func (pte *pubTxManager) GetPublicTransactionSubmissions(ctx context.Context, dbTX *gorm.DB, from tktypes.EthAddress, nonce uint64) ([]*pldapi.PublicTxSubmissionData, error) {
+	var pSubs []*DBPubTxnSubmission
+	err := dbTX.
+		WithContext(ctx).
+		Table("public_submissions").
+		Where("signer_nonce = ?", fmt.Sprintf("%s:%d", from, nonce)).
+		Order("created ASC").
+		Find(&pSubs).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]*pldapi.PublicTxSubmissionData, len(pSubs))
+	for i, pSub := range pSubs {
+		subs[i] = mapPersistedSubmissionData(pSub)
+	}
+	return subs, nil
+}
+
+// note this function guarantees the return order of the matches corresponds to the input order.
+// Completions changed by a re-org are flagged as corrected in the matches, but only updated if applyCorrections is set
+func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, dbTX *gorm.DB, itxs []*blockindexer.IndexedTransactionNotify, applyCorrections bool) ([]*components.PublicTxMatch, error) {
 
 	// Do a DB query in the TX to reverse lookup the TX details we need to match/update the completed status
 	// and return the list that matched (which is very possibly none as we only track transactions submitted
@@ -805,8 +1165,10 @@ func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, d
 	var lookups []*bindingsMatchingSubmission
 	err := dbTX.
 		Table("public_txn_bindings").
-		Select(`"transaction"`, `"tx_type"`, `"Submission"."signer_nonce"`, `"Submission"."tx_hash"`).
+		Select(`"transaction"`, `"tx_type"`, `"Submission"."signer_nonce"`, `"Submission"."tx_hash"`, `"Submission"."gap_fill"`,
+			`"Completed"."signer_nonce"`, `"Completed"."tx_hash"`, `"Completed"."success"`).
 		Joins("Submission").
+		Joins("Completed").
 		Where(`"Submission"."tx_hash" IN (?)`, txHashes).
 		Find(&lookups).
 		Error
@@ -818,24 +1180,60 @@ func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, d
 	// the results in the original order
 	results := make([]*components.PublicTxMatch, 0, len(lookups))
 	completions := make([]*DBPublicTxnCompletion, 0, len(lookups))
+	corrections := make([]*DBPublicTxnCompletion, 0)
+	resubmits := make([]*DBPublicTxnResubmit, 0)
 	for _, txi := range itxs {
 		for _, match := range lookups {
 			if txi.Hash.Equals(&match.Submission.TransactionHash) {
+				if match.Submission.GapFill {
+					// The nonce was used by a gap-fill transaction while this transaction was suspended, so
+					// however the gap-fill transaction itself went, this transaction has failed
+					log.L(ctx).Warnf("Public transaction %s failed, as its nonce was used by gap-fill transaction %s", match.SignerNonce, txi.Hash)
+					gapFill := *txi
+					gapFill.Result = pldapi.TXResult_FAILURE.Enum()
+					gapFill.RevertReason = nil
+					txi = &gapFill
+				}
+				completion := &DBPublicTxnCompletion{
+					SignerNonce:     match.SignerNonce,
+					TransactionHash: txi.Hash,
+					Success:         txi.Result.V() == pldapi.TXResult_SUCCESS,
+					RevertData:      txi.RevertReason,
+				}
+				// A completion we already have is only replaced if a re-org changed which submission was
+				// mined, or the result of it - otherwise this is just a re-delivery of the same confirmation
+				corrected := match.Completed != nil && match.Completed.SignerNonce != "" &&
+					(!match.Completed.TransactionHash.Equals(&completion.TransactionHash) || match.Completed.Success != completion.Success)
+				if corrected {
+					log.L(ctx).Warnf("Outcome of public transaction %s changed by re-org: previous hash=%s success=%t new hash=%s success=%t",
+						match.SignerNonce, match.Completed.TransactionHash, match.Completed.Success, completion.TransactionHash, completion.Success)
+					corrections = append(corrections, completion)
+				} else {
+					// completions to insert, in the order of the inputs
+					completions = append(completions, completion)
+				}
 				// matched results in the order of the inputs
-				results = append(results, &components.PublicTxMatch{
+				result := &components.PublicTxMatch{
 					PaladinTXReference: components.PaladinTXReference{
 						TransactionID:   match.Transaction,
 						TransactionType: match.TransactionType,
 					},
 					IndexedTransactionNotify: txi,
-				})
-				// completions to insert, in the order of the inputs
-				completions = append(completions, &DBPublicTxnCompletion{
-					SignerNonce:     match.SignerNonce,
-					TransactionHash: txi.Hash,
-					Success:         txi.Result.V() == pldapi.TXResult_SUCCESS,
-					RevertData:      txi.RevertReason,
-				})
+					Corrected:                corrected,
+					GapFilled:                match.Submission.GapFill,
+				}
+				if pte.outOfGasRetry && !corrected && !result.GapFilled && !completion.Success && len(txi.RevertReason) == 0 {
+					if result.OutOfGasResubmit, err = pte.checkOutOfGasResubmit(ctx, dbTX, match.SignerNonce, match.Transaction, txi); err != nil {
+						return nil, err
+					}
+					if result.OutOfGasResubmit {
+						resubmits = append(resubmits, &DBPublicTxnResubmit{
+							SignerNonce: match.SignerNonce,
+							Created:     tktypes.TimestampNow(),
+						})
+					}
+				}
+				results = append(results, result)
 				break
 			}
 		}
@@ -856,6 +1254,36 @@ func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, d
 		}
 	}
 
+	if len(resubmits) > 0 {
+		// The failure receipt is not written for these, so we must record that they are pending resubmission
+		// in the same DB transaction, for the resubmission to be recovered if we stop before it is committed
+		err := dbTX.
+			Table("public_txn_resubmits").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "signer_nonce"}},
+				DoNothing: true,
+			}).
+			Create(resubmits).
+			Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if applyCorrections && len(corrections) > 0 {
+		err := dbTX.
+			Table("public_completions").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "signer_nonce"}},
+				DoUpdates: clause.AssignmentColumns([]string{"tx_hash", "success", "revert_data"}),
+			}).
+			Create(corrections).
+			Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return results, nil
 
 }
@@ -863,7 +1291,242 @@ func (pte *pubTxManager) MatchUpdateConfirmedTransactions(ctx context.Context, d
 // We've got to be super careful not to block this thread, so we treat this just like a suspend/resume
 // on each of these transactions
 func (pte *pubTxManager) NotifyConfirmPersisted(ctx context.Context, confirms []*components.PublicTxMatch) {
+	// Confirmations for each signing address are delivered in order (so in nonce order within a block),
+	// with a bounded number of signing addresses processed in parallel
+	var addresses []tktypes.EthAddress
+	byAddress := make(map[tktypes.EthAddress][]*components.PublicTxMatch)
 	for _, conf := range confirms {
-		_ = pte.dispatchAction(ctx, *conf.From, conf.Nonce, ActionCompleted)
+		if _, exists := byAddress[*conf.From]; !exists {
+			addresses = append(addresses, *conf.From)
+		}
+		byAddress[*conf.From] = append(byAddress[*conf.From], conf)
+	}
+	for _, conf := range confirms {
+		if conf.OutOfGasResubmit {
+			go pte.resubmitOutOfGas(fmt.Sprintf("%s:%d", conf.From, conf.Nonce))
+		}
+		if conf.To != nil {
+			// this might be an auto-fueling transaction, topping up an address that is waiting for its balance
+			pte.resumeSigningAddressPausedForBalance(ctx, *conf.To)
+		}
+	}
+	forEachBounded(pte.confirmationConcurrency, addresses, func(from tktypes.EthAddress) {
+		for _, conf := range byAddress[from] {
+			_ = pte.dispatchAction(ctx, from, conf.Nonce, ActionCompleted)
+		}
+	})
+}
+
+// An on-chain failure is treated as out of gas if there is no revert data, and all of the gas was used.
+// We only resubmit if the number of attempts so far (up to and including this one) has not reached the limit,
+// which is calculated the same way if the confirmation is re-delivered.
+func (pte *pubTxManager) checkOutOfGasResubmit(ctx context.Context, dbTX *gorm.DB, signerNonce string, txID uuid.UUID, txi *blockindexer.IndexedTransactionNotify) (bool, error) {
+	var ptxs []*DBPublicTxn
+	err := dbTX.
+		WithContext(ctx).
+		Table("public_txns").
+		Where("signer_nonce = ?", signerNonce).
+		Find(&ptxs).
+		Error
+	if err != nil {
+		return false, err
+	}
+	if len(ptxs) == 0 || ptxs[0].SignedTx != nil || txi.GasUsed < ptxs[0].Gas {
+		// Externally signed transactions cannot be re-signed with a higher gas limit
+		return false, nil
+	}
+
+	var attempts int64
+	err = dbTX.
+		WithContext(ctx).
+		Table("public_txn_bindings").
+		Joins(`JOIN "public_txns" ON "public_txns"."signer_nonce" = "public_txn_bindings"."signer_nonce"`).
+		Where(`"public_txn_bindings"."transaction" = ?`, txID).
+		Where(`"public_txns"."created" <= ?`, ptxs[0].Created).
+		Count(&attempts).
+		Error
+	if err != nil {
+		return false, err
+	}
+	if attempts > int64(pte.outOfGasMaxAttempts) {
+		log.L(ctx).Warnf("Transaction %s ran out of gas with gas limit %d after %d attempts", signerNonce, ptxs[0].Gas, attempts)
+		return false, nil
+	}
+	log.L(ctx).Warnf("Transaction %s ran out of gas with gas limit %d (attempt %d)", signerNonce, ptxs[0].Gas, attempts)
+	return true, nil
+}
+
+// Runs after the out of gas completion has been committed, so we cannot fail the confirmation
+// and instead retry indefinitely until we succeed (or are shut down, in which case the pending
+// resubmission is recovered when we next start)
+func (pte *pubTxManager) resubmitOutOfGas(signerNonce string) {
+	ctx := pte.ctx
+	err := pte.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+		return true, pte.resubmitOutOfGasAttempt(ctx, signerNonce)
+	})
+	if err != nil {
+		log.L(ctx).Errorf("Failed to resubmit out of gas transaction %s: %s", signerNonce, err)
+	}
+}
+
+// Pending resubmissions written before we started are processed in order of their creation, one at a time
+func (pte *pubTxManager) recoverOutOfGasResubmits(startTime tktypes.Timestamp) {
+	defer close(pte.outOfGasRecoveryDone)
+
+	ctx := log.WithLogField(pte.ctx, "role", "out_of_gas_recovery")
+	var last *DBPublicTxnResubmit
+	for {
+		var page []*DBPublicTxnResubmit
+		err := pte.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+			q := pte.p.DB().
+				WithContext(ctx).
+				Table("public_txn_resubmits").
+				Where("created < ?", startTime)
+			if last != nil {
+				q = q.Where("created > ? OR (created = ? AND signer_nonce > ?)", last.Created, last.Created, last.SignerNonce)
+			}
+			return true, q.
+				Order("created ASC").
+				Order("signer_nonce ASC").
+				Limit(outOfGasRecoveryBatchSize).
+				Find(&page).
+				Error
+		})
+		if err != nil {
+			log.L(ctx).Errorf("Out of gas resubmission recovery stopped: %s", err)
+			return
+		}
+		for _, r := range page {
+			log.L(ctx).Infof("Recovering out of gas resubmission of %s", r.SignerNonce)
+			pte.resubmitOutOfGas(r.SignerNonce)
+		}
+		if len(page) < outOfGasRecoveryBatchSize {
+			return
+		}
+		last = page[len(page)-1]
+	}
+}
+
+func (pte *pubTxManager) deleteOutOfGasResubmit(ctx context.Context, dbTX *gorm.DB, signerNonce string) (bool, error) {
+	result := dbTX.
+		WithContext(ctx).
+		Table("public_txn_resubmits").
+		Where("signer_nonce = ?", signerNonce).
+		Delete(&DBPublicTxnResubmit{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (pte *pubTxManager) resubmitOutOfGasAttempt(ctx context.Context, signerNonce string) error {
+	var pending []*DBPublicTxnResubmit
+	var ptxs []*DBPublicTxn
+	var bindings []*DBPublicTxnBinding
+	err := pte.p.DB().
+		WithContext(ctx).
+		Table("public_txn_resubmits").
+		Where("signer_nonce = ?", signerNonce).
+		Find(&pending).
+		Error
+	if err != nil || len(pending) == 0 {
+		// Nothing to do if the resubmission has already been committed
+		return err
+	}
+	err = pte.p.DB().
+		WithContext(ctx).
+		Table("public_txns").
+		Where("signer_nonce = ?", signerNonce).
+		Find(&ptxs).
+		Error
+	if err == nil {
+		err = pte.p.DB().
+			WithContext(ctx).
+			Table("public_txn_bindings").
+			Where("signer_nonce = ?", signerNonce).
+			Find(&bindings).
+			Error
+	}
+	if err == nil && (len(ptxs) == 0 || len(bindings) == 0) {
+		_, err = pte.deleteOutOfGasResubmit(ctx, pte.p.DB(), signerNonce)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	ptx := ptxs[0]
+
+	// If a confirmation is re-delivered, we might already have resubmitted
+	var newer int64
+	err = pte.p.DB().
+		WithContext(ctx).
+		Table("public_txn_bindings").
+		Joins(`JOIN "public_txns" ON "public_txns"."signer_nonce" = "public_txn_bindings"."signer_nonce"`).
+		Where(`"public_txn_bindings"."transaction" = ?`, bindings[0].Transaction).
+		Where(`"public_txns"."created" > ?`, ptx.Created).
+		Count(&newer).
+		Error
+	if err == nil && newer > 0 {
+		_, err = pte.deleteOutOfGasResubmit(ctx, pte.p.DB(), signerNonce)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	gas := new(big.Int).SetUint64(ptx.Gas)
+	gas.Mul(gas, big.NewInt(int64(100+pte.outOfGasIncreasePercent)))
+	gas.Div(gas, big.NewInt(100))
+	submission := &components.PublicTxSubmission{
+		PublicTxInput: pldapi.PublicTxInput{
+			From: &ptx.From,
+			To:   ptx.To,
+			Data: ptx.Data,
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Gas:                confutil.P(tktypes.HexUint64(gas.Uint64())),
+				Value:              ptx.Value,
+				ResubmitInterval:   ptx.ResubmitInterval,
+				PublicTxGasPricing: recoverGasPriceOptions(ptx.FixedGasPricing),
+			},
+		},
+	}
+	for _, b := range bindings {
+		submission.Bindings = append(submission.Bindings, &components.PaladinTXReference{
+			TransactionID:   b.Transaction,
+			TransactionType: b.TransactionType,
+		})
+	}
+	// The pending resubmission is removed in the same DB transaction as the resubmission is written,
+	// so we cannot resubmit twice if there are concurrent attempts
+	_, err = pte.singleTransactionSubmit(ctx, submission, func(dbTX *gorm.DB) error {
+		deleted, err := pte.deleteOutOfGasResubmit(ctx, dbTX, signerNonce)
+		if err == nil && !deleted {
+			err = i18n.NewError(ctx, msgs.MsgOutOfGasAlreadyResubmitted, signerNonce)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	log.L(ctx).Infof("Resubmitted out of gas transaction %s with gas limit %d", signerNonce, gas)
+	return nil
+}
+
+// forEachBounded calls fn for every item, with at most concurrency calls in flight at once,
+// and returns when all calls have completed
+func forEachBounded[T any](concurrency int, items []T, fn func(T)) {
+	work := make(chan T)
+	var wg sync.WaitGroup
+	concurrency = max(concurrency, 1)
+	for i := 0; i < concurrency && i < len(items); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				fn(item)
+			}
+		}()
+	}
+	for _, item := range items {
+		work <- item
 	}
+	close(work)
+	wg.Wait()
 }
@@ -172,6 +172,7 @@ func (ble *pubTxManager) poll(ctx context.Context) (polled int, total int) {
 				log.L(ctx).Infof("Engine pause, attempt to stop orchestrator for signing address %s", signingAddress)
 				oc.Stop()
 				ble.signingAddressesPausedUntil[signingAddress] = time.Now().Add(ble.orchestratorSwapTimeout)
+				delete(ble.signingAddressesPausedForBalance, signingAddress)
 			}
 		}
 	}
@@ -180,6 +181,57 @@ func (ble *pubTxManager) poll(ctx context.Context) (polled int, total int) {
 	return polled, total
 }
 
+// Called by an orchestrator when its signing address has failed submission too many times in a row.
+// The address is excluded from polling for a period that backs off exponentially, until a submission
+// succeeds again after it is resumed.
+func (ble *pubTxManager) pauseSigningAddressForFailures(ctx context.Context, signingAddress tktypes.EthAddress) time.Duration {
+	ble.inFlightOrchestratorMux.Lock()
+	defer ble.inFlightOrchestratorMux.Unlock()
+
+	pauses := ble.submissionFailurePauses[signingAddress] + 1
+	ble.submissionFailurePauses[signingAddress] = pauses
+	pauseFor := ble.failurePauseBackoff.Delay(pauses)
+	ble.signingAddressesPausedUntil[signingAddress] = time.Now().Add(pauseFor)
+	delete(ble.signingAddressesPausedForBalance, signingAddress)
+	log.L(ctx).Warnf("Engine paused signing address %s for %s after repeated submission failures (pauses=%d)", signingAddress, pauseFor, pauses)
+	return pauseFor
+}
+
+// Called by an orchestrator when its signing address cannot afford the next transaction while keeping the
+// minimum gas reserve. The address is excluded from polling until a transaction we submit to it is confirmed
+// (such as an auto-fueling transaction), or the pause expires, and its balance is refreshed when it resumes.
+func (ble *pubTxManager) pauseSigningAddressForBalance(ctx context.Context, signingAddress tktypes.EthAddress) {
+	ble.inFlightOrchestratorMux.Lock()
+	defer ble.inFlightOrchestratorMux.Unlock()
+
+	ble.signingAddressesPausedUntil[signingAddress] = time.Now().Add(ble.gasReservePause)
+	ble.signingAddressesPausedForBalance[signingAddress] = true
+	ble.balanceManager.NotifyAddressBalanceChanged(ctx, signingAddress)
+	log.L(ctx).Warnf("Engine paused signing address %s for up to %s as its balance is below the gas reserve", signingAddress, ble.gasReservePause)
+}
+
+func (ble *pubTxManager) resumeSigningAddressPausedForBalance(ctx context.Context, signingAddress tktypes.EthAddress) {
+	ble.inFlightOrchestratorMux.Lock()
+	paused := ble.signingAddressesPausedForBalance[signingAddress]
+	if paused {
+		delete(ble.signingAddressesPausedForBalance, signingAddress)
+		delete(ble.signingAddressesPausedUntil, signingAddress)
+		ble.balanceManager.NotifyAddressBalanceChanged(ctx, signingAddress)
+	}
+	ble.inFlightOrchestratorMux.Unlock()
+
+	if paused {
+		log.L(ctx).Infof("Engine resumed signing address %s that was paused for its balance", signingAddress)
+		ble.MarkInFlightOrchestratorsStale()
+	}
+}
+
+func (ble *pubTxManager) resetSubmissionFailurePauses(signingAddress tktypes.EthAddress) {
+	ble.inFlightOrchestratorMux.Lock()
+	defer ble.inFlightOrchestratorMux.Unlock()
+	delete(ble.submissionFailurePauses, signingAddress)
+}
+
 func (ble *pubTxManager) MarkInFlightOrchestratorsStale() {
 	// try to send an item in `InFlightStale` channel, which has a buffer of 1
 	// to trigger a polling event to update the in flight transaction orchestrators
@@ -21,6 +21,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
 	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
@@ -175,6 +177,12 @@ func newTestPublicTxManager(t *testing.T, realDBAndSigner bool, extraSetup ...fu
 		pmgr.ethClient = pmgr.ethClientFactory.SharedWS()
 		pmgr.gasPriceClient.Init(ctx, pmgr.ethClient)
 	} else {
+		if !realDBAndSigner {
+			// recovery of out of gas resubmissions is tested against a real DB, so we don't
+			// have its query racing with the expectations of the mock DB
+			pmgr.outOfGasRecoveryDone = make(chan struct{})
+			close(pmgr.outOfGasRecoveryDone)
+		}
 		err = pmgr.Start()
 		require.NoError(t, err)
 	}
@@ -250,6 +258,7 @@ func TestTransactionLifecycleRealKeyMgrAndDB(t *testing.T) {
 		}
 
 	}
+	txs[2].ResubmitInterval = confutil.P("30s")
 
 	// gas estimate and nonce should be cached - so are once'd
 	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
@@ -288,6 +297,7 @@ func TestTransactionLifecycleRealKeyMgrAndDB(t *testing.T) {
 		assert.Equal(t, *resolvedKey, qTX.From)
 		assert.Equal(t, uint64(i)+baseNonce, qTX.Nonce.Uint64())
 		assert.Equal(t, txs[i].Data, qTX.Data)
+		assert.Equal(t, txs[i].ResubmitInterval, qTX.ResubmitInterval)
 		require.Greater(t, len(qTX.Activity), 0)
 	}
 
@@ -300,6 +310,16 @@ func TestTransactionLifecycleRealKeyMgrAndDB(t *testing.T) {
 		assert.Equal(t, baseNonce+uint64(i), queryTxs[0].Nonce.Uint64())
 	}
 
+	// Query by the bound private transaction, to find the nonce it was dispatched with
+	byBinding, err := ble.QueryPublicTxWithBindings(ctx, ble.p.DB(),
+		query.NewQueryBuilder().Equal("transaction", txIDs[3]).Query())
+	require.NoError(t, err)
+	require.Len(t, byBinding, 1)
+	assert.Equal(t, txIDs[3], byBinding[0].Transaction)
+	assert.Equal(t, pldapi.TransactionTypePrivate.Enum(), byBinding[0].TransactionType)
+	assert.Equal(t, *resolvedKey, byBinding[0].From)
+	assert.Equal(t, baseNonce+3, byBinding[0].Nonce.Uint64())
+
 	// Check we can select to just see confirmed (which this isn't yet)
 	byTxn, err = ble.QueryPublicTxForTransactions(ctx, ble.p.DB(), txIDs,
 		query.NewQueryBuilder().NotNull("transactionHash").Query())
@@ -363,7 +383,7 @@ func TestTransactionLifecycleRealKeyMgrAndDB(t *testing.T) {
 	var allMatches []*components.PublicTxMatch
 	confirmationsMatched := make(map[uuid.UUID]*components.PublicTxMatch)
 	for _, confirmation := range gatheredConfirmations {
-		matches, err := ble.MatchUpdateConfirmedTransactions(ctx, ble.p.DB(), []*blockindexer.IndexedTransactionNotify{confirmation})
+		matches, err := ble.MatchUpdateConfirmedTransactions(ctx, ble.p.DB(), []*blockindexer.IndexedTransactionNotify{confirmation}, true)
 		require.NoError(t, err)
 		// NOTE: This is a good test that we definitely persist _before_ we submit as
 		// otherwise we could miss notifying users of their transactions completing.
@@ -458,6 +478,147 @@ func TestSubmitFailures(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestEstimateGas(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false)
+	defer done()
+
+	from := tktypes.RandAddress()
+	to := tktypes.RandAddress()
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.MatchedBy(func(tx *ethsigner.Transaction) bool {
+		return string(tx.From) == fmt.Sprintf(`"%s"`, from) && tktypes.EthAddress(*tx.To) == *to && tx.Nonce == nil
+	}), mock.Anything).
+		Return(ethclient.EstimateGasResult{GasLimit: tktypes.HexUint64(12345)}, nil).Once()
+	gas, err := ble.EstimateGas(ctx, &pldapi.PublicTxInput{
+		From: from,
+		To:   to,
+		Data: tktypes.HexBytes("calldata"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tktypes.HexUint64(12345), gas)
+
+	// estimation failure - for non-revert
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{}, fmt.Errorf("GasEstimate error")).Once()
+	_, err = ble.EstimateGas(ctx, &pldapi.PublicTxInput{From: from})
+	assert.Regexp(t, "GasEstimate error", err)
+
+	// estimation failure - for revert
+	sampleRevertData := tktypes.HexBytes("some data")
+	m.txManager.On("CalculateRevertError", mock.Anything, mock.Anything, sampleRevertData).Return(fmt.Errorf("mapped revert error"))
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{
+			RevertData: sampleRevertData,
+		}, fmt.Errorf("execution reverted")).Once()
+	_, err = ble.EstimateGas(ctx, &pldapi.PublicTxInput{From: from})
+	assert.Regexp(t, "mapped revert error", err)
+
+	// missing from
+	_, err = ble.EstimateGas(ctx, &pldapi.PublicTxInput{})
+	assert.Regexp(t, "PD011936", err)
+}
+
+func TestPersistRejectedTransactionRealDB(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.PersistRejected = confutil.P(true)
+	})
+	defer done()
+
+	sampleRevertData := tktypes.HexBytes("some data")
+	m.txManager.On("CalculateRevertError", mock.Anything, mock.Anything, sampleRevertData).Return(fmt.Errorf("mapped revert error"))
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{
+			RevertData: sampleRevertData,
+		}, fmt.Errorf("execution reverted")).Once()
+
+	txID := uuid.New()
+	from := tktypes.RandAddress()
+	to := tktypes.RandAddress()
+	_, err := ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		Bindings: []*components.PaladinTXReference{
+			{TransactionID: txID, TransactionType: pldapi.TransactionTypePrivate.Enum()},
+		},
+		PublicTxInput: pldapi.PublicTxInput{
+			From: from,
+			To:   to,
+			Data: tktypes.HexBytes("calldata"),
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Value: tktypes.Uint64ToUint256(100),
+				PublicTxGasPricing: pldapi.PublicTxGasPricing{
+					GasPrice: tktypes.Uint64ToUint256(10),
+				},
+			},
+		},
+	})
+	assert.Regexp(t, "mapped revert error", err)
+
+	// The rejection is queryable, but nothing was submitted
+	rejections, err := ble.QueryPublicTxRejections(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(10).Equal("transaction", txID).Query())
+	require.NoError(t, err)
+	require.Len(t, rejections, 1)
+	r := rejections[0]
+	assert.False(t, r.Submitted)
+	assert.Equal(t, *from, r.From)
+	assert.Equal(t, to, r.To)
+	assert.Equal(t, tktypes.HexBytes("calldata"), r.Data)
+	assert.Equal(t, "mapped revert error", r.RejectReason)
+	assert.Equal(t, sampleRevertData, r.RevertData)
+	assert.Equal(t, txID, r.Transaction)
+	assert.Equal(t, pldapi.TransactionTypePrivate.Enum(), r.TransactionType)
+	assert.Equal(t, uint64(100), r.Value.Int().Uint64())
+	assert.Equal(t, uint64(10), r.GasPrice.Int().Uint64())
+	assert.Nil(t, r.Gas)
+
+	ptxs, err := ble.QueryPublicTxWithBindings(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	assert.Empty(t, ptxs)
+
+	// Unbound rejections without revert data are stored once
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{}, fmt.Errorf("execution reverted")).Once()
+	_, err = ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		PublicTxInput: pldapi.PublicTxInput{
+			From: from,
+		},
+	})
+	assert.Regexp(t, "execution reverted", err)
+
+	rejections, err = ble.QueryPublicTxRejections(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(10).Equal("from", from).Sort("created").Query())
+	require.NoError(t, err)
+	require.Len(t, rejections, 2)
+	assert.Equal(t, uuid.UUID{}, rejections[1].Transaction)
+	assert.Empty(t, rejections[1].RevertData)
+}
+
+func TestPersistRejectedTransactionFail(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.PersistRejected = confutil.P(true)
+	})
+	defer done()
+
+	m.ethClient.On("EstimateGasNoResolve", mock.Anything, mock.Anything, mock.Anything).
+		Return(ethclient.EstimateGasResult{}, fmt.Errorf("execution reverted")).Once()
+	m.db.ExpectBegin()
+	m.db.ExpectExec("INSERT.*public_txn_rejections").WillReturnError(fmt.Errorf("pop"))
+	m.db.ExpectRollback()
+
+	_, err := ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		PublicTxInput: pldapi.PublicTxInput{
+			From: tktypes.RandAddress(),
+		},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestQueryPublicTxRejectionsFail(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false)
+	defer done()
+
+	m.db.ExpectQuery("SELECT.*public_txn_rejections").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := ble.QueryPublicTxRejections(ctx, ble.p.DB(), query.NewQueryBuilder().Limit(1).Query())
+	assert.Regexp(t, "pop", err)
+}
+
 func TestAddActivityDisabled(t *testing.T) {
 	_, ble, _, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
 		conf.Manager.ActivityRecords.RecordsPerTransaction = confutil.P(0)
@@ -515,6 +676,25 @@ func TestHandleNewTransactionTransferOnlyWithProvideGas(t *testing.T) {
 
 }
 
+func TestHandleNewTransactionBadResubmitInterval(t *testing.T) {
+	ctx := context.Background()
+	_, ble, _, done := newTestPublicTxManager(t, false)
+	defer done()
+
+	for _, interval := range []string{"wrong", "-1s", "0"} {
+		_, err := ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+			PublicTxInput: pldapi.PublicTxInput{
+				From: tktypes.RandAddress(),
+				PublicTxOptions: pldapi.PublicTxOptions{
+					ResubmitInterval: confutil.P(interval),
+				},
+			},
+		})
+		assert.Regexp(t, "PD011937", err)
+	}
+
+}
+
 func TestEngineSuspendResumeRealDB(t *testing.T) {
 
 	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
@@ -594,3 +774,641 @@ func TestEngineSuspendResumeRealDB(t *testing.T) {
 	assert.Equal(t, txNonce, newNonce)
 
 }
+
+func TestForEachBoundedConcurrency(t *testing.T) {
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var active, maxActive, total atomic.Int32
+	forEachBounded(5, items, func(i int) {
+		now := active.Add(1)
+		for {
+			prev := maxActive.Load()
+			if now <= prev || maxActive.CompareAndSwap(prev, now) {
+				break
+			}
+		}
+		time.Sleep(1 * time.Millisecond)
+		active.Add(-1)
+		total.Add(1)
+	})
+	assert.Equal(t, int32(100), total.Load())
+	assert.LessOrEqual(t, maxActive.Load(), int32(5))
+	assert.Greater(t, maxActive.Load(), int32(1))
+}
+
+func TestDispatchActionNotSerializedAcrossSigningAddresses(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	oBusy := NewOrchestrator(ble, *tktypes.RandAddress(), ble.conf)
+	oIdle := NewOrchestrator(ble, *tktypes.RandAddress(), ble.conf)
+	ble.inFlightOrchestratorMux.Lock()
+	ble.inFlightOrchestrators = map[tktypes.EthAddress]*orchestrator{
+		oBusy.signingAddress: oBusy,
+		oIdle.signingAddress: oIdle,
+	}
+	ble.inFlightOrchestratorMux.Unlock()
+
+	// An action for one signing address that is waiting on its orchestrator...
+	oBusy.inFlightTxsMux.Lock()
+	busyDone := make(chan error, 1)
+	go func() {
+		busyDone <- ble.dispatchAction(ctx, oBusy.signingAddress, 1, ActionCompleted)
+	}()
+
+	// ... does not hold up those for other signing addresses, including those with nothing in flight
+	require.NoError(t, ble.dispatchAction(ctx, oIdle.signingAddress, 1, ActionCompleted))
+	require.NoError(t, ble.dispatchAction(ctx, *tktypes.RandAddress(), 1, ActionCompleted))
+
+	oBusy.inFlightTxsMux.Unlock()
+	require.NoError(t, <-busyDone)
+}
+
+func TestMatchUpdateConfirmedTransactionsReorg(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true)
+	defer done()
+
+	// A transaction that was submitted twice, with different gas prices
+	from := *tktypes.RandAddress()
+	signerNonce := fmt.Sprintf("%s:%d", from, 1000)
+	txID := uuid.New()
+	txHash1 := tktypes.Bytes32(tktypes.RandBytes(32))
+	txHash2 := tktypes.Bytes32(tktypes.RandBytes(32))
+	db := ble.p.DB()
+	require.NoError(t, db.Create(&DBPublicTxn{SignerNonce: signerNonce, From: from, Nonce: 1000, Gas: 21000}).Error)
+	require.NoError(t, db.Create(&DBPublicTxnBinding{SignerNonce: signerNonce, Transaction: txID, TransactionType: pldapi.TransactionTypePublic.Enum()}).Error)
+	require.NoError(t, db.Create([]*DBPubTxnSubmission{
+		{SignerNonce: signerNonce, Created: tktypes.TimestampNow(), TransactionHash: txHash1},
+		{SignerNonce: signerNonce, Created: tktypes.TimestampNow(), TransactionHash: txHash2},
+	}).Error)
+
+	applyCorrections := true
+	confirm := func(txHash tktypes.Bytes32, result pldapi.EthTransactionResult) *components.PublicTxMatch {
+		matches, err := ble.MatchUpdateConfirmedTransactions(ctx, db, []*blockindexer.IndexedTransactionNotify{{
+			IndexedTransaction: pldapi.IndexedTransaction{
+				Hash:   txHash,
+				From:   &from,
+				Nonce:  1000,
+				Result: result.Enum(),
+			},
+		}}, applyCorrections)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, txID, matches[0].TransactionID)
+		return matches[0]
+	}
+	checkCompletion := func(txHash tktypes.Bytes32, success bool) {
+		var completions []*DBPublicTxnCompletion
+		require.NoError(t, db.Where("signer_nonce = ?", signerNonce).Find(&completions).Error)
+		require.Len(t, completions, 1)
+		assert.Equal(t, txHash, completions[0].TransactionHash)
+		assert.Equal(t, success, completions[0].Success)
+	}
+
+	// First confirmation, and a re-delivery of the same confirmation
+	assert.False(t, confirm(txHash1, pldapi.TXResult_SUCCESS).Corrected)
+	assert.False(t, confirm(txHash1, pldapi.TXResult_SUCCESS).Corrected)
+	checkCompletion(txHash1, true)
+
+	// A re-org means the same submission is mined in a different block, but this time fails
+	assert.True(t, confirm(txHash1, pldapi.TXResult_FAILURE).Corrected)
+	checkCompletion(txHash1, false)
+
+	// Another re-org means the other submission is the one that is mined
+	assert.True(t, confirm(txHash2, pldapi.TXResult_SUCCESS).Corrected)
+	checkCompletion(txHash2, true)
+	assert.False(t, confirm(txHash2, pldapi.TXResult_SUCCESS).Corrected)
+
+	// When corrections are not applied, the change is still reported but the completion is left alone
+	applyCorrections = false
+	assert.True(t, confirm(txHash1, pldapi.TXResult_FAILURE).Corrected)
+	checkCompletion(txHash2, true)
+}
+
+func TestGapFillConfirmedRealDB(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+		conf.Manager.OutOfGasRetry = pldconf.PublicTxManagerOutOfGasRetryConfig{
+			Enabled: confutil.P(true),
+		}
+	})
+	defer done()
+
+	from := *tktypes.RandAddress()
+	signerNonce := fmt.Sprintf("%s:%d", from, 1000)
+	txID := uuid.New()
+	gapFillHash := tktypes.Bytes32(tktypes.RandBytes(32))
+	db := ble.p.DB()
+	require.NoError(t, db.Create(&DBPublicTxn{SignerNonce: signerNonce, From: from, Nonce: 1000, Gas: 100000, Suspended: true}).Error)
+	require.NoError(t, db.Create(&DBPublicTxnBinding{SignerNonce: signerNonce, Transaction: txID, TransactionType: pldapi.TransactionTypePublic.Enum()}).Error)
+	require.NoError(t, db.Create(&DBPubTxnSubmission{SignerNonce: signerNonce, Created: tktypes.TimestampNow(), TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32))}).Error)
+	require.NoError(t, db.Create(&DBPubTxnSubmission{SignerNonce: signerNonce, Created: tktypes.TimestampNow(), TransactionHash: gapFillHash, GapFill: true}).Error)
+
+	// The gap-fill transaction succeeds, using all of its gas, but the transaction it displaced has failed
+	matches, err := ble.MatchUpdateConfirmedTransactions(ctx, db, []*blockindexer.IndexedTransactionNotify{{
+		IndexedTransaction: pldapi.IndexedTransaction{
+			Hash:   gapFillHash,
+			From:   &from,
+			Nonce:  1000,
+			Result: pldapi.TXResult_SUCCESS.Enum(),
+		},
+		GasUsed: gapFillGasLimit,
+	}}, true)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, txID, matches[0].TransactionID)
+	assert.True(t, matches[0].GapFilled)
+	assert.False(t, matches[0].OutOfGasResubmit)
+	assert.Equal(t, pldapi.TXResult_FAILURE, matches[0].Result.V())
+
+	var completions []*DBPublicTxnCompletion
+	require.NoError(t, db.Table("public_completions").Where("signer_nonce = ?", signerNonce).Find(&completions).Error)
+	require.Len(t, completions, 1)
+	assert.Equal(t, gapFillHash, completions[0].TransactionHash)
+	assert.False(t, completions[0].Success)
+}
+
+func TestOutOfGasResubmitRealDB(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+		conf.Manager.OutOfGasRetry = pldconf.PublicTxManagerOutOfGasRetryConfig{
+			Enabled:            confutil.P(true),
+			IncreasePercentage: confutil.P(50),
+			MaxAttempts:        confutil.P(1),
+		}
+	})
+	defer done()
+
+	from := *tktypes.RandAddress()
+	signerNonce := fmt.Sprintf("%s:%d", from, 1000)
+	txID := uuid.New()
+	txHash := tktypes.Bytes32(tktypes.RandBytes(32))
+	db := ble.p.DB()
+	require.NoError(t, db.Create(&DBPublicTxn{SignerNonce: signerNonce, From: from, Nonce: 1000, Gas: 100000, Data: tktypes.HexBytes("some data")}).Error)
+	require.NoError(t, db.Create(&DBPublicTxnBinding{SignerNonce: signerNonce, Transaction: txID, TransactionType: pldapi.TransactionTypePublic.Enum()}).Error)
+	require.NoError(t, db.Create(&DBPubTxnSubmission{SignerNonce: signerNonce, Created: tktypes.TimestampNow(), TransactionHash: txHash}).Error)
+
+	confirm := func(txHash tktypes.Bytes32, nonce, gasUsed uint64, revertData tktypes.HexBytes) *components.PublicTxMatch {
+		matches, err := ble.MatchUpdateConfirmedTransactions(ctx, db, []*blockindexer.IndexedTransactionNotify{{
+			IndexedTransaction: pldapi.IndexedTransaction{
+				Hash:   txHash,
+				From:   &from,
+				Nonce:  nonce,
+				Result: pldapi.TXResult_FAILURE.Enum(),
+			},
+			RevertReason: revertData,
+			GasUsed:      gasUsed,
+		}}, true)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, txID, matches[0].TransactionID)
+		return matches[0]
+	}
+
+	// A revert, or a failure that did not use all the gas, is not out of gas
+	assert.False(t, confirm(txHash, 1000, 100000, tktypes.HexBytes("revert")).OutOfGasResubmit)
+	assert.False(t, confirm(txHash, 1000, 99999, nil).OutOfGasResubmit)
+
+	// Running out of gas results in a resubmission with a higher gas limit, on a new nonce
+	match := confirm(txHash, 1000, 100000, nil)
+	require.True(t, match.OutOfGasResubmit)
+	// The pending resubmission is recorded with the confirmation
+	pendingResubmits := func() (signerNonces []string) {
+		require.NoError(t, db.Table("public_txn_resubmits").Pluck("signer_nonce", &signerNonces).Error)
+		return signerNonces
+	}
+	assert.Equal(t, []string{signerNonce}, pendingResubmits())
+	// No orchestrator is running to be notified, so we don't wait for it
+	cancelledCtx, cancelCtx := context.WithCancel(ctx)
+	cancelCtx()
+	ble.NotifyConfirmPersisted(cancelledCtx, []*components.PublicTxMatch{match})
+
+	var resubmitted *pldapi.PublicTx
+	require.Eventually(t, func() bool {
+		txs, err := ble.QueryPublicTxForTransactions(ctx, db, []uuid.UUID{txID}, nil)
+		require.NoError(t, err)
+		if len(txs[txID]) != 2 {
+			return false
+		}
+		resubmitted = txs[txID][1]
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(150000), resubmitted.Gas.Uint64())
+	assert.Equal(t, uint64(mockBaseNonce), resubmitted.Nonce.Uint64())
+	assert.Equal(t, tktypes.HexBytes("some data"), resubmitted.Data)
+	// ... and is no longer pending once it is committed
+	assert.Empty(t, pendingResubmits())
+	require.NoError(t, ble.resubmitOutOfGasAttempt(ctx, signerNonce))
+
+	// Re-delivery of the original confirmation does not resubmit again
+	require.True(t, confirm(txHash, 1000, 100000, nil).OutOfGasResubmit)
+	require.NoError(t, ble.resubmitOutOfGasAttempt(ctx, signerNonce))
+	txs, err := ble.QueryPublicTxForTransactions(ctx, db, []uuid.UUID{txID}, nil)
+	require.NoError(t, err)
+	assert.Len(t, txs[txID], 2)
+	assert.Empty(t, pendingResubmits())
+
+	// Once the attempts are exhausted, the failure is reported
+	txHash2 := tktypes.Bytes32(tktypes.RandBytes(32))
+	require.NoError(t, db.Create(&DBPubTxnSubmission{SignerNonce: fmt.Sprintf("%s:%d", from, mockBaseNonce), Created: tktypes.TimestampNow(), TransactionHash: txHash2}).Error)
+	assert.False(t, confirm(txHash2, mockBaseNonce, 150000, nil).OutOfGasResubmit)
+}
+
+func TestOutOfGasResubmitRecovery(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+		conf.Manager.OutOfGasRetry.IncreasePercentage = confutil.P(10)
+	})
+	defer done()
+
+	// More pending resubmissions than fit in a page, all created at the same time so the paging has to break the tie.
+	// The transactions that are no longer bound, or have been resubmitted already, are just removed.
+	from := *tktypes.RandAddress()
+	db := ble.p.DB()
+	now := tktypes.TimestampNow()
+	txID := uuid.New()
+	var ptxs []*DBPublicTxn
+	var resubmits []*DBPublicTxnResubmit
+	for i := 0; i < outOfGasRecoveryBatchSize+1; i++ {
+		signerNonce := fmt.Sprintf("%s:%d", from, 1000+i)
+		ptxs = append(ptxs, &DBPublicTxn{SignerNonce: signerNonce, From: from, Nonce: uint64(1000 + i), Gas: 100000, Created: now})
+		resubmits = append(resubmits, &DBPublicTxnResubmit{SignerNonce: signerNonce, Created: now})
+	}
+	require.NoError(t, db.Create(ptxs).Error)
+	require.NoError(t, db.Create(resubmits).Error)
+	// The last one is still bound to a transaction, and needs resubmitting
+	require.NoError(t, db.Create(&DBPublicTxnBinding{SignerNonce: ptxs[outOfGasRecoveryBatchSize].SignerNonce, Transaction: txID, TransactionType: pldapi.TransactionTypePublic.Enum()}).Error)
+	// One created after we started is left for the confirmation that wrote it
+	later := &DBPublicTxn{SignerNonce: fmt.Sprintf("%s:%d", from, 999), From: from, Nonce: 999, Gas: 100000}
+	require.NoError(t, db.Create(later).Error)
+	require.NoError(t, db.Create(&DBPublicTxnResubmit{SignerNonce: later.SignerNonce, Created: now + 1}).Error)
+
+	ble.outOfGasRecoveryDone = make(chan struct{})
+	ble.recoverOutOfGasResubmits(now + 1)
+
+	var pending []string
+	require.NoError(t, db.Table("public_txn_resubmits").Pluck("signer_nonce", &pending).Error)
+	assert.Equal(t, []string{later.SignerNonce}, pending)
+	txs, err := ble.QueryPublicTxForTransactions(ctx, db, []uuid.UUID{txID}, nil)
+	require.NoError(t, err)
+	require.Len(t, txs[txID], 2)
+	assert.Equal(t, uint64(110000), txs[txID][1].Gas.Uint64())
+}
+
+func TestOutOfGasResubmitRecoveryStopped(t *testing.T) {
+	_, ble, _, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	done()
+
+	ble.outOfGasRecoveryDone = make(chan struct{})
+	ble.recoverOutOfGasResubmits(tktypes.TimestampNow())
+	<-ble.outOfGasRecoveryDone
+}
+
+func TestGetPublicTransactionSubmissions(t *testing.T) {
+	ctx, ble, _, done := newTestPublicTxManager(t, true)
+	defer done()
+
+	// A transaction that was resubmitted three times with increasing gas prices
+	from := *tktypes.RandAddress()
+	signerNonce := fmt.Sprintf("%s:%d", from, 1000)
+	txID := uuid.New()
+	db := ble.p.DB()
+	require.NoError(t, db.Create(&DBPublicTxn{SignerNonce: signerNonce, From: from, Nonce: 1000, Gas: 21000}).Error)
+	require.NoError(t, db.Create(&DBPublicTxnBinding{SignerNonce: signerNonce, Transaction: txID, TransactionType: pldapi.TransactionTypePublic.Enum()}).Error)
+	now := tktypes.TimestampNow()
+	txHashes := make([]tktypes.Bytes32, 3)
+	subs := make([]*DBPubTxnSubmission, len(txHashes))
+	for i := range txHashes {
+		txHashes[i] = tktypes.Bytes32(tktypes.RandBytes(32))
+		subs[i] = &DBPubTxnSubmission{
+			SignerNonce:     signerNonce,
+			Created:         now + tktypes.Timestamp(i),
+			TransactionHash: txHashes[i],
+			GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(uint64(100 * (i + 1)))}),
+		}
+	}
+	// Insert out of order, to check the ordering is by creation time
+	require.NoError(t, db.Create([]*DBPubTxnSubmission{subs[2], subs[0], subs[1]}).Error)
+
+	// An unrelated nonce for the same signer is not included
+	require.NoError(t, db.Create(&DBPubTxnSubmission{
+		SignerNonce: fmt.Sprintf("%s:%d", from, 1001), Created: now, TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+	}).Error)
+
+	// Complete the transaction, as the history must still be available afterwards
+	matches, err := ble.MatchUpdateConfirmedTransactions(ctx, db, []*blockindexer.IndexedTransactionNotify{{
+		IndexedTransaction: pldapi.IndexedTransaction{
+			Hash:   txHashes[2],
+			From:   &from,
+			Nonce:  1000,
+			Result: pldapi.TXResult_SUCCESS.Enum(),
+		},
+	}}, true)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	history, err := ble.GetPublicTransactionSubmissions(ctx, db, from, 1000)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	for i, sub := range history {
+		assert.Equal(t, txHashes[i], sub.TransactionHash)
+		assert.Equal(t, subs[i].Created, sub.Time)
+		assert.Equal(t, uint64(100*(i+1)), sub.GasPrice.Int().Uint64())
+	}
+
+	// Unknown nonce gives an empty list
+	history, err = ble.GetPublicTransactionSubmissions(ctx, db, from, 999)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestGetPublicTransactionSubmissionsFail(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false)
+	defer done()
+
+	m.db.ExpectQuery("SELECT.*public_submissions").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := ble.GetPublicTransactionSubmissions(ctx, ble.p.DB(), *tktypes.RandAddress(), 1000)
+	assert.Regexp(t, "pop", err)
+}
+
+func signExternally(t *testing.T, kp *secp256k1.KeyPair, chainID int64, nonce uint64) tktypes.HexBytes {
+	ethTx := &ethsigner.Transaction{
+		Nonce:                ethtypes.NewHexIntegerU64(nonce),
+		GasLimit:             ethtypes.NewHexIntegerU64(21000),
+		MaxFeePerGas:         ethtypes.NewHexIntegerU64(2000000000),
+		MaxPriorityFeePerGas: ethtypes.NewHexIntegerU64(1000000000),
+		To:                   tktypes.RandAddress().Address0xHex(),
+		Value:                ethtypes.NewHexIntegerU64(100),
+		Data:                 ethtypes.MustNewHexBytes0xPrefix("0xfeedbeef"),
+	}
+	signed, err := ethTx.SignEIP1559(kp, chainID)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestSignedTransactionLifecycleRealDB(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.Interval = confutil.P("50ms")
+		conf.Orchestrator.Interval = confutil.P("50ms")
+		conf.Manager.OrchestratorIdleTimeout = confutil.P("1ms")
+	})
+	defer done()
+
+	chainID := int64(12345)
+	m.ethClient.On("ChainID").Return(chainID)
+	kp, err := secp256k1.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	signer := tktypes.EthAddress(kp.Address)
+	baseNonce := uint64(1000)
+	m.ethClient.On("GetTransactionCount", mock.Anything, signer).
+		Return(confutil.P(tktypes.HexUint64(baseNonce)), nil).Once()
+
+	// The signed payload must be for the nonce we would allocate
+	txID := uuid.New()
+	fakeTxManagerInsert(t, ble.p.DB(), txID, signer.String())
+	submission := func(nonce uint64) *components.PublicTxSubmission {
+		return &components.PublicTxSubmission{
+			Bindings: []*components.PaladinTXReference{
+				{TransactionID: txID, TransactionType: pldapi.TransactionTypePublic.Enum()},
+			},
+			SignedTransaction: signExternally(t, kp, chainID, nonce),
+		}
+	}
+	_, err = ble.SingleTransactionSubmit(ctx, submission(baseNonce+1))
+	assert.Regexp(t, "PD011940", err)
+
+	// The exact signed payload is sent to the chain - with no gas estimation, or signing by us
+	signedTx := submission(baseNonce)
+	submitted := make(chan *tktypes.Bytes32, 1)
+	srtx := m.ethClient.On("SendRawTransaction", mock.Anything, mock.Anything)
+	srtx.Run(func(args mock.Arguments) {
+		assert.Equal(t, signedTx.SignedTransaction, args[1].(tktypes.HexBytes))
+		txHash := calculateTransactionHash(args[1].(tktypes.HexBytes))
+		srtx.Return(txHash, nil)
+		submitted <- txHash
+	})
+	_, err = ble.SingleTransactionSubmit(ctx, signedTx)
+	require.NoError(t, err)
+
+	ptxs, err := ble.QueryPublicTxForTransactions(ctx, ble.p.DB(), []uuid.UUID{txID}, nil)
+	require.NoError(t, err)
+	require.Len(t, ptxs[txID], 1)
+	ptx := ptxs[txID][0]
+	assert.Equal(t, signer, ptx.From)
+	assert.Equal(t, baseNonce, ptx.Nonce.Uint64())
+	assert.Equal(t, uint64(21000), ptx.Gas.Uint64())
+	assert.Equal(t, int64(100), ptx.Value.Int().Int64())
+	assert.Equal(t, int64(2000000000), ptx.MaxFeePerGas.Int().Int64())
+
+	// Track it through to confirmation
+	var txHash *tktypes.Bytes32
+	select {
+	case txHash = <-submitted:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for submission")
+	}
+	matches, err := ble.MatchUpdateConfirmedTransactions(ctx, ble.p.DB(), []*blockindexer.IndexedTransactionNotify{{
+		IndexedTransaction: pldapi.IndexedTransaction{
+			Hash:   *txHash,
+			From:   &signer,
+			Nonce:  baseNonce,
+			Result: pldapi.TXResult_SUCCESS.Enum(),
+		},
+	}}, true)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, txID, matches[0].TransactionID)
+	ble.NotifyConfirmPersisted(ctx, matches)
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for ble.getOrchestratorCount() > 0 {
+		<-ticker.C
+		if t.Failed() {
+			return
+		}
+	}
+}
+
+func TestNonceOverrideRealDB(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, true, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	signer := *tktypes.RandAddress()
+	m.ethClient.On("GetTransactionCount", mock.Anything, signer).
+		Return(confutil.P(tktypes.HexUint64(10)), nil).Times(7)
+	m.ethClient.On("GetTransactionCount", mock.Anything, signer).
+		Return(nil, fmt.Errorf("pop")).Once()
+	submission := func(nonce *uint64) *components.PublicTxSubmission {
+		return &components.PublicTxSubmission{
+			PublicTxInput: pldapi.PublicTxInput{
+				From: &signer,
+				To:   tktypes.RandAddress(),
+				PublicTxOptions: pldapi.PublicTxOptions{
+					Gas:   confutil.P(tktypes.HexUint64(21000)),
+					Nonce: (*tktypes.HexUint64)(nonce),
+				},
+			},
+		}
+	}
+
+	// The forced nonce is used as supplied, filling the gap below the next nonce of the nonce cache
+	accepted, err := ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(12))))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), accepted.PublicTx().Nonce.Uint64())
+
+	// The next nonce is still assigned from the nonce cache
+	accepted, err = ble.SingleTransactionSubmit(ctx, submission(nil))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(mockBaseNonce), accepted.PublicTx().Nonce.Uint64())
+
+	var ptxs []*DBPublicTxn
+	err = ble.p.DB().Table("public_txns").Where(`"from" = ?`, signer).Order("nonce").Find(&ptxs).Error
+	require.NoError(t, err)
+	require.Len(t, ptxs, 2)
+	assert.Equal(t, uint64(12), ptxs[0].Nonce)
+	assert.Equal(t, uint64(mockBaseNonce), ptxs[1].Nonce)
+
+	// Already assigned to a public transaction, which is checked in the DB transaction of the batch
+	_, err = ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(12))))
+	assert.Regexp(t, "PD011941", err)
+	_, err = ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(mockBaseNonce))))
+	assert.Regexp(t, "PD011941", err)
+
+	// Not below the next nonce of the nonce cache, which would go on to assign it again
+	_, err = ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(mockBaseNonce+1))))
+	assert.Regexp(t, "PD011947", err)
+
+	// Nor below the next nonce once the batch before it in the same DB transaction has assigned it
+	batch, err := ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{
+		submission(nil),
+		submission(confutil.P(uint64(11))),
+	})
+	require.NoError(t, err)
+	err = ble.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		if err := batch.Submit(ctx, dbTX); err != nil {
+			return err
+		}
+		// the lock on the nonce cache is held until the batch completes
+		nextNonce, err := batch.Accepted()[1].(*preparedTransaction).nsi.LockNextNonce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(mockBaseNonce+1), nextNonce)
+		return nil
+	})
+	batch.Completed(ctx, err == nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(mockBaseNonce+1), batch.Accepted()[0].PublicTx().Nonce.Uint64())
+	assert.Equal(t, uint64(11), batch.Accepted()[1].PublicTx().Nonce.Uint64())
+
+	// Already used on chain
+	_, err = ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(9))))
+	assert.Regexp(t, "PD011942.*10", err)
+
+	// Duplicated in the batch
+	_, err = ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{
+		submission(confutil.P(uint64(13))),
+		submission(confutil.P(uint64(13))),
+	})
+	assert.Regexp(t, "PD011941", err)
+
+	// Failure to query the chain
+	_, err = ble.SingleTransactionSubmit(ctx, submission(confutil.P(uint64(14))))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestNonceOverrideDBFail(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, mock.Anything).Return(confutil.P(tktypes.HexUint64(10)), nil)
+	m.db.ExpectBegin()
+	m.db.ExpectQuery("SELECT.*public_txns").WillReturnError(fmt.Errorf("pop"))
+	m.db.ExpectRollback()
+
+	_, err := ble.SingleTransactionSubmit(ctx, &components.PublicTxSubmission{
+		PublicTxInput: pldapi.PublicTxInput{
+			From: tktypes.RandAddress(),
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Gas:   confutil.P(tktypes.HexUint64(21000)),
+				Nonce: confutil.P(tktypes.HexUint64(10)),
+			},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+	require.NoError(t, m.db.ExpectationsWereMet())
+}
+
+func TestSignedTransactionInvalid(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+	m.ethClient.On("ChainID").Return(int64(12345))
+
+	_, err := ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{{
+		SignedTransaction: tktypes.HexBytes{0x01, 0x02},
+	}})
+	assert.Regexp(t, "PD011938", err)
+
+	kp, err := secp256k1.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+	_, err = ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{{
+		PublicTxInput:     pldapi.PublicTxInput{From: tktypes.RandAddress()},
+		SignedTransaction: signExternally(t, kp, 12345, 0),
+	}})
+	assert.Regexp(t, "PD011939", err)
+	_, err = ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{{
+		PublicTxInput: pldapi.PublicTxInput{
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Nonce: confutil.P(tktypes.HexUint64(0)),
+			},
+		},
+		SignedTransaction: signExternally(t, kp, 12345, 0),
+	}})
+	assert.Regexp(t, "PD011943", err)
+	_, err = ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{{
+		PublicTxInput: pldapi.PublicTxInput{
+			To:   tktypes.RandAddress(),
+			Data: tktypes.HexBytes{0xfe, 0xed, 0xbe, 0xef},
+		},
+		SignedTransaction: signExternally(t, kp, 12345, 0),
+	}})
+	assert.Regexp(t, "PD011945", err)
+}
+
+func TestSignedTransactionNonceMismatchRejectedAlone(t *testing.T) {
+	ctx, ble, m, done := newTestPublicTxManager(t, false, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		mocks.disableManagerStart = true
+	})
+	defer done()
+
+	chainID := int64(12345)
+	m.ethClient.On("ChainID").Return(chainID)
+	kp, err := secp256k1.GenerateSecp256k1KeyPair()
+	require.NoError(t, err)
+
+	// Only the transaction signed with a nonce we would not allocate is rejected, and the nonces
+	// of the rest of the batch are unaffected
+	batch, err := ble.PrepareSubmissionBatch(ctx, []*components.PublicTxSubmission{
+		{SignedTransaction: signExternally(t, kp, chainID, mockBaseNonce)},
+		{SignedTransaction: signExternally(t, kp, chainID, mockBaseNonce+5)},
+		{SignedTransaction: signExternally(t, kp, chainID, mockBaseNonce+1)},
+	})
+	require.NoError(t, err)
+	defer batch.Completed(ctx, false)
+	require.Len(t, batch.Rejected(), 1)
+	assert.Regexp(t, "PD011940", batch.Rejected()[0].RejectedError())
+	assert.Len(t, batch.Accepted(), 2)
+}
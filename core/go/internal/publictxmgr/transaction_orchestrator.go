@@ -21,14 +21,18 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 
 	"github.com/kaleido-io/paladin/core/pkg/ethclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -124,6 +128,7 @@ type orchestrator struct {
 
 	// in flight txs array
 	maxInFlightTxs       int
+	maxActiveTxs         int                                   // transactions beyond this point in the queue are held until those ahead of them complete
 	inFlightTxs          []*inFlightTransactionStageController // a queue of all the in flight transactions
 	inFlightTxsMux       sync.Mutex
 	orchestratorLoopDone chan struct{}
@@ -139,10 +144,21 @@ type orchestrator struct {
 
 	staleTimeout    time.Duration
 	lastQueueUpdate time.Time
+
+	// submission failures in a row across all transactions for this signing address
+	consecutiveSubmissionFailures int
+	hadSuccessfulSubmission       bool
+
+	// gap filling of nonces held by suspended transactions, when the queue is stale behind them
+	gapFillEnabled bool
+	gapFilledNonce *uint64
 }
 
 const veryShortMinimum = 50 * time.Millisecond
 
+// the gas used by a transfer with no data, such as a gap-fill transaction
+const gapFillGasLimit = 21000
+
 func NewOrchestrator(
 	ble *pubTxManager,
 	signingAddress tktypes.EthAddress,
@@ -155,6 +171,7 @@ func NewOrchestrator(
 		orchestratorBirthTime:       time.Now(),
 		orchestratorPollingInterval: confutil.DurationMin(conf.Orchestrator.Interval, veryShortMinimum, *pldconf.PublicTxManagerDefaults.Orchestrator.Interval),
 		maxInFlightTxs:              confutil.IntMin(conf.Orchestrator.MaxInFlight, 1, *pldconf.PublicTxManagerDefaults.Orchestrator.MaxInFlight),
+		maxActiveTxs:                confutil.IntMin(conf.Orchestrator.MaxInFlightPerOrchestrator, 1, *pldconf.PublicTxManagerDefaults.Orchestrator.MaxInFlightPerOrchestrator),
 		signingAddress:              signingAddress,
 		state:                       OrchestratorStateNew,
 		stateEntryTime:              time.Now(),
@@ -170,6 +187,7 @@ func NewOrchestrator(
 		transactionSubmissionRetry: retry.NewRetryLimited(&conf.Orchestrator.SubmissionRetry),
 		staleTimeout:               confutil.DurationMin(conf.Orchestrator.StaleTimeout, 0, *pldconf.PublicTxManagerDefaults.Orchestrator.StaleTimeout),
 		hasZeroGasPrice:            ble.gasPriceClient.HasZeroGasPrice(ctx),
+		gapFillEnabled:             confutil.Bool(conf.Orchestrator.GapFill.Enabled, *pldconf.PublicTxManagerDefaults.Orchestrator.GapFill.Enabled),
 		InFlightTxsStale:           make(chan bool, 1),
 		stopProcess:                make(chan bool, 1),
 		ethClient:                  ble.ethClient,
@@ -187,13 +205,13 @@ func (oc *orchestrator) orchestratorLoop() {
 
 	defer close(oc.orchestratorLoopDone)
 
-	ticker := time.NewTicker(oc.orchestratorPollingInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(oc.orchestratorPollingInterval)
+	defer timer.Stop()
 	for {
 		// an InFlight
 		select {
 		case <-oc.InFlightTxsStale:
-		case <-ticker.C:
+		case <-timer.C:
 		case <-ctx.Done():
 			log.L(ctx).Infof("Orchestrator loop exit due to canceled context, it processed %d transaction during its lifetime.", oc.totalCompleted)
 			return
@@ -206,10 +224,35 @@ func (oc *orchestrator) orchestratorLoop() {
 		}
 		polled, total := oc.pollAndProcess(ctx)
 		log.L(ctx).Debugf("Orchestrator loop polled %d txs, there are %d txs in total", polled, total)
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(oc.nextPollDelay())
 	}
 
 }
 
+// The polling interval is shortened when a transaction in-flight has a resubmit interval override
+// that means it will be due for resubmission before the next poll
+func (oc *orchestrator) nextPollDelay() time.Duration {
+	oc.inFlightTxsMux.Lock()
+	defer oc.inFlightTxsMux.Unlock()
+	delay := oc.orchestratorPollingInterval
+	for _, it := range oc.inFlightTxs {
+		// anything already overdue is picked up on the normal polling cycle
+		if due := it.NextResubmitDue(); due != nil {
+			if untilDue := time.Until(*due); untilDue > 0 {
+				delay = min(delay, untilDue)
+			}
+		}
+	}
+	return max(delay, veryShortMinimum)
+}
+
 // Used in unit tests
 func (oc *orchestrator) getFirstInFlight() (ift *inFlightTransactionStageController) {
 	oc.inFlightTxsMux.Lock()
@@ -317,7 +360,14 @@ func (oc *orchestrator) pollAndProcess(ctx context.Context) (polled int, total i
 	// now check and process each transaction
 
 	if total > 0 {
-		waitingForBalance, _ := oc.ProcessInFlightTransactions(ctx, oc.inFlightTxs)
+		// Only the transactions at the front of the queue are signed and submitted. The rest stay queued
+		// in nonce order, and are promoted as those ahead of them are confirmed and removed from the queue.
+		active := oc.inFlightTxs
+		if len(active) > oc.maxActiveTxs {
+			log.L(ctx).Debugf("Orchestrator holding %d transactions until earlier nonces complete (maxInFlightPerOrchestrator=%d)", len(active)-oc.maxActiveTxs, oc.maxActiveTxs)
+			active = active[:oc.maxActiveTxs]
+		}
+		waitingForBalance, _ := oc.ProcessInFlightTransactions(ctx, active)
 		if queueUpdated {
 			oc.lastQueueUpdate = time.Now()
 		}
@@ -331,6 +381,12 @@ func (oc *orchestrator) pollAndProcess(ctx context.Context) (polled int, total i
 			oc.state = OrchestratorStateRunning
 			oc.stateEntryTime = time.Now()
 		}
+		if oc.state == OrchestratorStateStale && oc.gapFillEnabled && oc.fillNonceGap(ctx) {
+			// the queue should now start moving again, so we give it a full stale timeout before we check again
+			oc.lastQueueUpdate = time.Now()
+			oc.state = OrchestratorStateRunning
+			oc.stateEntryTime = time.Now()
+		}
 	} else if oc.state != OrchestratorStateIdle {
 		oc.state = OrchestratorStateIdle
 		oc.stateEntryTime = time.Now()
@@ -340,6 +396,94 @@ func (oc *orchestrator) pollAndProcess(ctx context.Context) (polled int, total i
 	return polled, total
 }
 
+// A queue that is stale might be blocked by a gap in the nonces before it, that will never be filled because the
+// transaction that was assigned the nonce is suspended. When that is the case we use the nonce for a zero-value
+// transaction to self, and request resubmission of the in-flight transactions so the ones behind it are mined.
+// The suspended transaction is left as it is, and will fail with a nonce too low error if it is resumed.
+// Must be called with the inFlightTxsMux held. Returns true if a gap-fill transaction was submitted.
+func (oc *orchestrator) fillNonceGap(ctx context.Context) bool {
+	headNonce := oc.inFlightTxs[0].stateManager.GetNonce()
+	for _, it := range oc.inFlightTxs[1:] {
+		headNonce = min(headNonce, it.stateManager.GetNonce())
+	}
+	nextNonce, err := oc.ethClient.GetTransactionCount(ctx, oc.signingAddress)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to get the next nonce for %s to check for a nonce gap: %s", oc.signingAddress, err)
+		return false
+	}
+	gapNonce := nextNonce.Uint64()
+	if gapNonce >= headNonce || (oc.gapFilledNonce != nil && *oc.gapFilledNonce == gapNonce) {
+		// not blocked by a gap, or we already tried to fill it
+		return false
+	}
+
+	var suspended []*DBPublicTxn
+	err = oc.p.DB().
+		WithContext(ctx).
+		Table("public_txns").
+		Joins("Completed").
+		Where(`"Completed"."tx_hash" IS NULL`).
+		Where("suspended IS TRUE").
+		Where(`"from" = ?`, oc.signingAddress).
+		Where("nonce = ?", gapNonce).
+		Limit(1).
+		Find(&suspended).
+		Error
+	if err != nil {
+		log.L(ctx).Errorf("Failed to query for a suspended transaction with nonce %s:%d: %s", oc.signingAddress, gapNonce, err)
+		return false
+	}
+	if len(suspended) == 0 {
+		log.L(ctx).Warnf("Queue for %s is blocked at nonce %d, which is not held by a suspended transaction, so will not be gap filled", oc.signingAddress, gapNonce)
+		return false
+	}
+
+	gasPricing, err := oc.gasPriceClient.GetGasPriceObject(ctx)
+	var signedMessage []byte
+	var txHash *tktypes.Bytes32
+	if err == nil {
+		signedMessage, txHash, err = oc.signTx(ctx, oc.signingAddress, buildEthTX(oc.signingAddress, &gapNonce, &oc.signingAddress, nil, &pldapi.PublicTxOptions{
+			Gas:                confutil.P(tktypes.HexUint64(gapFillGasLimit)),
+			Value:              tktypes.Uint64ToUint256(0),
+			PublicTxGasPricing: *gasPricing,
+		}))
+	}
+	if err == nil {
+		// The gap-fill is recorded as a submission of the suspended transaction before it is sent, so that
+		// when it is mined the suspended transaction is completed as failed, and gets its failure receipt
+		err = oc.p.DB().
+			WithContext(ctx).
+			Table("public_submissions").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tx_hash"}},
+				DoNothing: true, // the same gap-fill signed again on a retry
+			}).
+			Create(&DBPubTxnSubmission{
+				SignerNonce:     suspended[0].SignerNonce,
+				Created:         tktypes.TimestampNow(),
+				TransactionHash: *txHash,
+				GasPricing:      tktypes.JSONString(gasPricing),
+				GapFill:         true,
+			}).
+			Error
+	}
+	if err == nil {
+		_, err = oc.ethClient.SendRawTransaction(ctx, signedMessage)
+	}
+	if err != nil {
+		// we try again the next time the orchestrator is stale
+		log.L(ctx).Errorf("Failed to submit gap-fill transaction for %s:%d: %s", oc.signingAddress, gapNonce, err)
+		return false
+	}
+	oc.gapFilledNonce = &gapNonce
+	log.L(ctx).Warnf("Submitted gap-fill transaction %s for %s:%d held by suspended transaction", txHash, oc.signingAddress, gapNonce)
+	oc.addActivityRecord(suspended[0].SignerNonce, i18n.ExpandWithCode(ctx, i18n.MessageKey(msgs.MsgPublicTxNonceGapFilled), oc.signingAddress, gapNonce, txHash))
+	for _, it := range oc.inFlightTxs {
+		it.RequestResubmit(ctx)
+	}
+	return true
+}
+
 // this function should only have one running instance at any given time
 func (oc *orchestrator) ProcessInFlightTransactions(ctx context.Context, its []*inFlightTransactionStageController) (waitingForBalance bool, err error) {
 	processStart := time.Now()
@@ -400,6 +544,13 @@ func (oc *orchestrator) ProcessInFlightTransactions(ctx context.Context, its []*
 		_, _ = oc.balanceManager.TopUpAccount(ctx, addressAccount)
 	}
 
+	if waitingForBalance && addressAccount.Reserve != nil {
+		// Rather than waiting on a balance we know is insufficient, we stop and pause the address until it is refreshed
+		log.L(ctx).Debugf("%s Address %s cannot afford the next transaction while keeping the gas reserve %s, credit after estimated cost: %s", now.String(), oc.signingAddress, addressAccount.Reserve.String(), addressAccount.GetAvailableToSpend(ctx).String())
+		oc.pauseSigningAddressForBalance(ctx, oc.signingAddress)
+		oc.Stop()
+	}
+
 	log.L(ctx).Debugf("%s ProcessInFlightTransaction exit for signing address: %s", now.String(), oc.signingAddress)
 	log.L(ctx).Debugf("Orchestrator process loop took %s", time.Since(processStart))
 	return waitingForBalance, nil
@@ -412,6 +563,30 @@ func (oc *orchestrator) Start(ctx context.Context) (done <-chan struct{}, err er
 	return oc.orchestratorLoopDone, nil
 }
 
+// Called from the orchestrator loop for every failed submission. Once the signing address has failed
+// enough times in a row, it is paused and this orchestrator stops, to avoid wasting calls to the node
+// on an address that cannot currently submit (such as due to insufficient funds).
+func (oc *orchestrator) recordSubmissionFailure(ctx context.Context) {
+	if oc.failurePauseThreshold <= 0 {
+		return
+	}
+	oc.consecutiveSubmissionFailures++
+	if oc.consecutiveSubmissionFailures >= oc.failurePauseThreshold {
+		oc.consecutiveSubmissionFailures = 0
+		oc.pauseSigningAddressForFailures(ctx, oc.signingAddress)
+		oc.Stop()
+	}
+}
+
+func (oc *orchestrator) recordSubmissionSuccess() {
+	oc.consecutiveSubmissionFailures = 0
+	if !oc.hadSuccessfulSubmission {
+		// pauses only carry across orchestrators for the same address, so we only need to reset on the first
+		oc.hadSuccessfulSubmission = true
+		oc.resetSubmissionFailurePauses(oc.signingAddress)
+	}
+}
+
 // Stop the InFlight transaction process.
 func (oc *orchestrator) Stop() {
 	// try to send an item in `stopProcess` channel, which has a buffer of 1
@@ -23,13 +23,19 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/core/pkg/ethclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -159,6 +165,48 @@ func TestNewOrchestratorPollingRemoveCompleted(t *testing.T) {
 	<-ocDone
 }
 
+func TestOrchestratorMaxInFlightPerOrchestrator(t *testing.T) {
+
+	ctx, o, m, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Orchestrator.MaxInFlight = confutil.P(3)
+		conf.Orchestrator.MaxInFlightPerOrchestrator = confutil.P(1)
+	})
+	defer done()
+	assert.Equal(t, 1, o.maxActiveTxs)
+
+	confirmed := InFlightStatusConfirmReceived
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		it, _ := newInflightTransaction(o, nonce)
+		it.hasZeroGasPrice = true
+		it.newStatus = &confirmed
+		o.inFlightTxs = append(o.inFlightTxs, it)
+	}
+	o.state = OrchestratorStateRunning
+
+	// only the first is actioned, the others are held in the queue
+	_, total := o.pollAndProcess(ctx)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, InFlightTxStageStatusUpdate, o.inFlightTxs[0].stateManager.GetStage(ctx))
+	assert.Empty(t, o.inFlightTxs[1].stateManager.GetStage(ctx))
+	assert.Empty(t, o.inFlightTxs[2].stateManager.GetStage(ctx))
+
+	// as each one completes, the next is promoted until the queue is empty
+	for i := 0; i < 5; i++ {
+		m.db.ExpectQuery("SELECT.*public_txn").WillReturnRows(sqlmock.NewRows([]string{}))
+	}
+	ocDone, _ := o.Start(ctx)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for o.state != OrchestratorStateIdle && !t.Failed() {
+		<-ticker.C
+	}
+	assert.Empty(t, o.inFlightTxs)
+	assert.Equal(t, int64(3), o.totalCompleted)
+
+	o.Stop()
+	<-ocDone
+}
+
 func TestOrchestratorTriggerTopUp(t *testing.T) {
 
 	autoFuelingSourceAddr := *tktypes.RandAddress()
@@ -197,7 +245,8 @@ func TestOrchestratorTriggerTopUp(t *testing.T) {
 	o.inFlightTxs = []*inFlightTransactionStageController{mockIT}
 	o.state = OrchestratorStateRunning
 
-	// Mock no auto-fueling TX in flight
+	// Mock no auto-fueling TX in flight, both when deciding to fuel and immediately before submitting
+	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 	m.db.ExpectQuery("SELECT.*public_txns.*data IS NULL").WillReturnRows(sqlmock.NewRows([]string{}))
 
 	// Then insert of the auto-fueling transaction
@@ -232,3 +281,416 @@ func TestOrchestratorTriggerTopUp(t *testing.T) {
 	o.Stop()
 	<-oDone
 }
+
+func TestResubmitAllForAddress(t *testing.T) {
+
+	ctx, o, _, done := newTestOrchestrator(t)
+	defer done()
+
+	submitted := func(tx *DBPublicTxn) {
+		tx.Submissions = []*DBPubTxnSubmission{{
+			Created:         tktypes.TimestampNow(),
+			TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+			GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)}),
+		}}
+	}
+
+	// Several stuck transactions, held out of order in the in-flight list
+	stuck3, _ := newInflightTransaction(o, 3, submitted)
+	stuck1, _ := newInflightTransaction(o, 1, submitted)
+	stuck2, _ := newInflightTransaction(o, 2, submitted)
+	// One that has just had its confirmation notified
+	confirmed4, _ := newInflightTransaction(o, 4, submitted)
+	confirmedStatus := InFlightStatusConfirmReceived
+	confirmed4.newStatus = &confirmedStatus
+	// One that has never been submitted
+	unsubmitted5, _ := newInflightTransaction(o, 5)
+	o.inFlightTxs = []*inFlightTransactionStageController{stuck3, stuck1, confirmed4, stuck2, unsubmitted5}
+
+	// Nothing in flight for the address
+	nonces, err := o.ResubmitAllForAddress(ctx, o.signingAddress)
+	require.NoError(t, err)
+	assert.Empty(t, nonces)
+
+	o.inFlightOrchestrators = map[tktypes.EthAddress]*orchestrator{o.signingAddress: o}
+	nonces, err = o.ResubmitAllForAddress(ctx, o.signingAddress)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, nonces)
+	assert.False(t, confirmed4.resubmitRequested)
+	assert.False(t, unsubmitted5.resubmitRequested)
+
+	for _, it := range []*inFlightTransactionStageController{stuck1, stuck2, stuck3} {
+		it.testOnlyNoActionMode = true
+		it.stateManager.SetValidatedTransactionHashMatchState(ctx, true)
+		// all are re-kicked immediately, without waiting for the resubmit interval
+		_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{})
+		assert.Equal(t, InFlightTxStageRetrieveGasPrice, it.stateManager.GetStage(ctx))
+
+		// and the gas price is bumped even though the node price has not moved
+		it.gasPriceIncreasePercent = 10
+		gpo := it.calculateNewGasPrice(ctx, it.stateManager.GetGasPriceObject(), &pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)})
+		assert.Equal(t, int64(1100), gpo.GasPrice.Int().Int64())
+	}
+	assert.Equal(t, 1, len(o.InFlightTxsStale))
+
+}
+
+func TestPerTransactionResubmitInterval(t *testing.T) {
+
+	ctx, o, _, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Orchestrator.Interval = confutil.P("5s")
+		conf.Orchestrator.ResubmitInterval = confutil.P("5m")
+	})
+	defer done()
+
+	submittedAt := func(ago time.Duration) func(tx *DBPublicTxn) {
+		return func(tx *DBPublicTxn) {
+			tx.Submissions = []*DBPubTxnSubmission{{
+				Created:         tktypes.Timestamp(time.Now().Add(-ago).UnixNano()),
+				TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+				GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)}),
+			}}
+		}
+	}
+
+	urgent, _ := newInflightTransaction(o, 1, submittedAt(2*time.Second), func(tx *DBPublicTxn) {
+		tx.ResubmitInterval = confutil.P("1s")
+	})
+	routine, _ := newInflightTransaction(o, 2, submittedAt(2*time.Second))
+	assert.Equal(t, 1*time.Second, urgent.txResubmitInterval)
+	assert.Equal(t, 5*time.Minute, routine.txResubmitInterval)
+
+	for _, it := range []*inFlightTransactionStageController{urgent, routine} {
+		it.testOnlyNoActionMode = true
+		it.stateManager.SetValidatedTransactionHashMatchState(ctx, true)
+		_ = it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{})
+	}
+	// Only the urgent one is due for resubmission
+	assert.Equal(t, InFlightTxStageRetrieveGasPrice, urgent.stateManager.GetStage(ctx))
+	assert.Nil(t, routine.stateManager.GetRunningStageContext(ctx))
+
+	// Polling is shortened so the urgent transaction is evaluated before the normal poll interval
+	routine2, _ := newInflightTransaction(o, 3, submittedAt(0))
+	urgent2, _ := newInflightTransaction(o, 4, submittedAt(0), func(tx *DBPublicTxn) {
+		tx.ResubmitInterval = confutil.P("2s")
+	})
+	o.inFlightTxs = []*inFlightTransactionStageController{routine2}
+	assert.Equal(t, 5*time.Second, o.nextPollDelay())
+	o.inFlightTxs = []*inFlightTransactionStageController{routine2, urgent2}
+	delay := o.nextPollDelay()
+	assert.Greater(t, delay, 1*time.Second)
+	assert.LessOrEqual(t, delay, 2*time.Second)
+
+}
+
+func TestOrchestratorPausedOnRepeatedSubmissionFailures(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.SubmissionFailurePause.ConsecutiveFailures = confutil.P(3)
+		conf.Manager.SubmissionFailurePause.Backoff = pldconf.RetryConfig{
+			InitialDelay: confutil.P("1h"),
+			MaxDelay:     confutil.P("3h"),
+			Factor:       confutil.P(2.0),
+		}
+	})
+	defer done()
+
+	it, mTS := newInflightTransaction(o, 1)
+	it.testOnlyNoActionMode = true
+	mTS.statusUpdater = &mockStatusUpdater{
+		updateSubStatus: func(ctx context.Context, imtx InMemoryTxStateReadOnly, subStatus BaseTxSubStatus, action BaseTxAction, info, err *fftypes.JSONAny, actionOccurred *tktypes.Timestamp) error {
+			return nil
+		},
+	}
+	mTS.ApplyInMemoryUpdates(ctx, &BaseTXUpdates{
+		GasPricing: &pldapi.PublicTxGasPricing{
+			GasPrice: tktypes.Uint64ToUint256(10),
+		},
+	})
+	it.TriggerNewStageRun(ctx, InFlightTxStageSubmitting, BaseTxSubStatusReceived, []byte("signedMessage"))
+
+	submitFail := func() {
+		mTS.bufferedStageOutputs = make([]*StageOutput, 0)
+		it.stateManager.AddSubmitOutput(ctx, nil, confutil.P(tktypes.TimestampNow()), SubmissionOutcomeFailedRequiresRetry, ethclient.ErrorReasonInsufficientFunds, fmt.Errorf("insufficient funds"))
+		it.ProduceLatestInFlightStageContext(ctx, &OrchestratorContext{})
+	}
+	isPaused := func() (time.Duration, bool) {
+		pausedUntil, paused := o.signingAddressesPausedUntil[o.signingAddress]
+		return time.Until(pausedUntil), paused
+	}
+
+	// Below the threshold, we keep going
+	submitFail()
+	submitFail()
+	_, paused := isPaused()
+	assert.False(t, paused)
+	assert.Empty(t, o.stopProcess)
+
+	// At the threshold, the address is paused and the orchestrator stops
+	submitFail()
+	pauseFor, paused := isPaused()
+	assert.True(t, paused)
+	assert.Greater(t, pauseFor, 59*time.Minute)
+	assert.LessOrEqual(t, pauseFor, 1*time.Hour)
+	assert.Len(t, o.stopProcess, 1)
+
+	// A new orchestrator for the address that fails again is paused for longer
+	o2 := NewOrchestrator(o.pubTxManager, o.signingAddress, o.conf)
+	for i := 0; i < 3; i++ {
+		o2.recordSubmissionFailure(ctx)
+	}
+	pauseFor, _ = isPaused()
+	assert.Greater(t, pauseFor, 119*time.Minute)
+	assert.Len(t, o2.stopProcess, 1)
+
+	// A successful submission resets the backoff, so the next pause starts from the beginning
+	o3 := NewOrchestrator(o.pubTxManager, o.signingAddress, o.conf)
+	o3.recordSubmissionSuccess()
+	assert.Empty(t, o.submissionFailurePauses)
+	for i := 0; i < 3; i++ {
+		o3.recordSubmissionFailure(ctx)
+	}
+	pauseFor, _ = isPaused()
+	assert.LessOrEqual(t, pauseFor, 1*time.Hour)
+}
+
+func TestOrchestratorSubmissionFailurePauseDisabled(t *testing.T) {
+	ctx, o, _, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Manager.SubmissionFailurePause.ConsecutiveFailures = confutil.P(0)
+	})
+	defer done()
+
+	for i := 0; i < 100; i++ {
+		o.recordSubmissionFailure(ctx)
+	}
+	assert.Empty(t, o.signingAddressesPausedUntil)
+	assert.Empty(t, o.stopProcess)
+}
+
+func newGapFillTestOrchestrator(t *testing.T) (context.Context, *orchestrator, *mocksAndTestControl, func()) {
+	ctx, o, m, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.Orchestrator.MaxInFlight = confutil.P(2)
+		conf.Orchestrator.StaleTimeout = confutil.P("1ms")
+		conf.Orchestrator.GapFill.Enabled = confutil.P(true)
+		conf.GasPrice.FixedGasPrice = 1
+	})
+	assert.True(t, o.gapFillEnabled)
+	m.ethClient.On("GetBalance", mock.Anything, o.signingAddress, "latest").Return(tktypes.Uint64ToUint256(100), nil).Maybe()
+
+	// Two transactions submitted behind nonce 4, which is held by a suspended transaction
+	for nonce := uint64(5); nonce <= 6; nonce++ {
+		it, _ := newInflightTransaction(o, nonce, func(tx *DBPublicTxn) {
+			tx.Submissions = []*DBPubTxnSubmission{{
+				Created:         tktypes.TimestampNow(),
+				TransactionHash: tktypes.Bytes32(tktypes.RandBytes(32)),
+				GasPricing:      tktypes.JSONString(&pldapi.PublicTxGasPricing{GasPrice: tktypes.Int64ToInt256(1000)}),
+			}}
+		})
+		it.testOnlyNoActionMode = true
+		o.inFlightTxs = append(o.inFlightTxs, it)
+	}
+	o.state = OrchestratorStateRunning
+	return ctx, o, m, done
+}
+
+func mockGapFillSigning(m *mocksAndTestControl, o *orchestrator) {
+	keyMapping := &pldapi.KeyMappingAndVerifier{
+		KeyMappingWithPath: &pldapi.KeyMappingWithPath{KeyMapping: &pldapi.KeyMapping{Identifier: "any.key"}},
+		Verifier:           &pldapi.KeyVerifier{Verifier: o.signingAddress.String()},
+	}
+	m.ethClient.On("ChainID").Return(int64(1122334455))
+	mockKeyMgr := m.keyManager.(*componentmocks.KeyManager)
+	mockKeyMgr.On("ReverseKeyLookup", mock.Anything, mock.Anything, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, o.signingAddress.String()).
+		Return(keyMapping, nil)
+	mockKeyMgr.On("Sign", mock.Anything, keyMapping, signpayloads.OPAQUE_TO_RSV, mock.Anything).
+		Return(tktypes.RandBytes(65), nil)
+}
+
+func TestOrchestratorGapFillSuspendedNonce(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(4)), nil)
+	suspendedSignerNonce := fmt.Sprintf("%s:%d", o.signingAddress, 4)
+	m.db.ExpectQuery("SELECT.*public_txns.*suspended IS TRUE").WillReturnRows(sqlmock.NewRows([]string{"signer_nonce", "from", "nonce"}).AddRow(
+		suspendedSignerNonce, o.signingAddress, 4,
+	))
+	mockGapFillSigning(m, o)
+	// the gap-fill is bound to the suspended transaction before it is sent
+	m.db.ExpectExec("INSERT.*public_submissions.*gap_fill").WithArgs(suspendedSignerNonce, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), true).
+		WillReturnResult(driver.ResultNoRows)
+	gapFillTxHash := tktypes.Bytes32(tktypes.RandBytes(32))
+	m.ethClient.On("SendRawTransaction", mock.Anything, mock.Anything).Return(&gapFillTxHash, nil).Once()
+
+	// The queue goes stale, and the gap is filled, which puts it back to running
+	time.Sleep(5 * time.Millisecond)
+	_, total := o.pollAndProcess(ctx)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, OrchestratorStateRunning, o.state)
+	require.NoError(t, m.db.ExpectationsWereMet())
+	assert.Equal(t, uint64(4), *o.gapFilledNonce)
+	for _, it := range o.inFlightTxs {
+		assert.True(t, it.resubmitRequested)
+	}
+	records := o.getActivityRecords(suspendedSignerNonce)
+	require.Len(t, records, 1)
+	assert.Regexp(t, "PD011944", records[0].Message)
+
+	// We do not try to fill the same nonce again
+	assert.False(t, o.fillNonceGap(ctx))
+}
+
+func TestOrchestratorGapFillDisabled(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+	o.gapFillEnabled = false
+
+	time.Sleep(5 * time.Millisecond)
+	_, _ = o.pollAndProcess(ctx)
+	assert.Equal(t, OrchestratorStateStale, o.state)
+	m.ethClient.AssertNotCalled(t, "GetTransactionCount", mock.Anything, mock.Anything)
+}
+
+func TestOrchestratorGapFillNoGap(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(5)), nil)
+	assert.False(t, o.fillNonceGap(ctx))
+	assert.Nil(t, o.gapFilledNonce)
+}
+
+func TestOrchestratorGapFillGetTransactionCountFail(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(nil, fmt.Errorf("pop"))
+	assert.False(t, o.fillNonceGap(ctx))
+	assert.Nil(t, o.gapFilledNonce)
+}
+
+func TestOrchestratorGapFillNotSuspended(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(4)), nil)
+	m.db.ExpectQuery("SELECT.*public_txns").WillReturnRows(sqlmock.NewRows([]string{}))
+	assert.False(t, o.fillNonceGap(ctx))
+	assert.Nil(t, o.gapFilledNonce)
+}
+
+func TestOrchestratorGapFillQueryFail(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(4)), nil)
+	m.db.ExpectQuery("SELECT.*public_txns").WillReturnError(fmt.Errorf("pop"))
+	assert.False(t, o.fillNonceGap(ctx))
+	assert.Nil(t, o.gapFilledNonce)
+}
+
+func TestOrchestratorGapFillSendFail(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(4)), nil)
+	m.db.ExpectQuery("SELECT.*public_txns").WillReturnRows(sqlmock.NewRows([]string{"signer_nonce", "from", "nonce"}).AddRow(
+		fmt.Sprintf("%s:%d", o.signingAddress, 4), o.signingAddress, 4,
+	))
+	mockGapFillSigning(m, o)
+	m.db.ExpectExec("INSERT.*public_submissions").WillReturnResult(driver.ResultNoRows)
+	m.ethClient.On("SendRawTransaction", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+
+	assert.False(t, o.fillNonceGap(ctx))
+	require.NoError(t, m.db.ExpectationsWereMet())
+	// the nonce is only recorded as filled after a successful send, so we try again
+	assert.Nil(t, o.gapFilledNonce)
+	for _, it := range o.inFlightTxs {
+		assert.False(t, it.resubmitRequested)
+	}
+}
+
+func TestOrchestratorGapFillBindFail(t *testing.T) {
+	ctx, o, m, done := newGapFillTestOrchestrator(t)
+	defer done()
+
+	m.ethClient.On("GetTransactionCount", mock.Anything, o.signingAddress).Return(confutil.P(tktypes.HexUint64(4)), nil)
+	m.db.ExpectQuery("SELECT.*public_txns").WillReturnRows(sqlmock.NewRows([]string{"signer_nonce", "from", "nonce"}).AddRow(
+		fmt.Sprintf("%s:%d", o.signingAddress, 4), o.signingAddress, 4,
+	))
+	mockGapFillSigning(m, o)
+	m.db.ExpectExec("INSERT.*public_submissions").WillReturnError(fmt.Errorf("pop"))
+
+	assert.False(t, o.fillNonceGap(ctx))
+	require.NoError(t, m.db.ExpectationsWereMet())
+	assert.Nil(t, o.gapFilledNonce)
+	m.ethClient.AssertNotCalled(t, "SendRawTransaction", mock.Anything, mock.Anything)
+}
+
+func TestOrchestratorPausedBelowGasReserve(t *testing.T) {
+	ctx, o, m, done := newTestOrchestrator(t, func(mocks *mocksAndTestControl, conf *pldconf.PublicTxManagerConfig) {
+		conf.GasPrice.FixedGasPrice = 1
+		conf.BalanceManager.MinGasReserve = confutil.P("100")
+	})
+	defer done()
+
+	// A transaction that costs 2000 gas * 10 wei
+	it, mTS := newInflightTransaction(o, 1)
+	it.testOnlyNoActionMode = true
+	mTS.statusUpdater = &mockStatusUpdater{
+		updateSubStatus: func(ctx context.Context, imtx InMemoryTxStateReadOnly, subStatus BaseTxSubStatus, action BaseTxAction, info, err *fftypes.JSONAny, actionOccurred *tktypes.Timestamp) error {
+			return nil
+		},
+	}
+	mTS.ApplyInMemoryUpdates(ctx, &BaseTXUpdates{
+		GasPricing: &pldapi.PublicTxGasPricing{
+			GasPrice: tktypes.Uint64ToUint256(10),
+		},
+	})
+
+	// Enough to cover the transaction and the reserve
+	m.ethClient.On("GetBalance", mock.Anything, o.signingAddress, "latest").Return(tktypes.Uint64ToUint256(20100), nil).Once()
+	waitingForBalance, err := o.ProcessInFlightTransactions(ctx, []*inFlightTransactionStageController{it})
+	require.NoError(t, err)
+	assert.False(t, waitingForBalance)
+	assert.Empty(t, o.signingAddressesPausedUntil)
+	assert.Empty(t, o.stopProcess)
+
+	// Not enough once the reserve is held back, so the address is paused rather than submitting
+	o.balanceManager.NotifyAddressBalanceChanged(ctx, o.signingAddress)
+	m.ethClient.On("GetBalance", mock.Anything, o.signingAddress, "latest").Return(tktypes.Uint64ToUint256(20050), nil).Once()
+	waitingForBalance, err = o.ProcessInFlightTransactions(ctx, []*inFlightTransactionStageController{it})
+	require.NoError(t, err)
+	assert.True(t, waitingForBalance)
+	pausedUntil, paused := o.signingAddressesPausedUntil[o.signingAddress]
+	assert.True(t, paused)
+	assert.Greater(t, time.Until(pausedUntil), 59*time.Second)
+	assert.True(t, o.signingAddressesPausedForBalance[o.signingAddress])
+	assert.Len(t, o.stopProcess, 1)
+
+	// A confirmed transaction to the address resumes it, and its balance is refreshed
+	cancelledCtx, cancelCtx := context.WithCancel(ctx)
+	cancelCtx()
+	o.NotifyConfirmPersisted(cancelledCtx, []*components.PublicTxMatch{{
+		IndexedTransactionNotify: &blockindexer.IndexedTransactionNotify{
+			IndexedTransaction: pldapi.IndexedTransaction{
+				From:  tktypes.RandAddress(),
+				To:    &o.signingAddress,
+				Nonce: 1,
+			},
+		},
+	}})
+	assert.Empty(t, o.signingAddressesPausedUntil)
+	assert.Empty(t, o.signingAddressesPausedForBalance)
+	assert.Len(t, o.inFlightOrchestratorStale, 1)
+	m.ethClient.On("GetBalance", mock.Anything, o.signingAddress, "latest").Return(tktypes.Uint64ToUint256(50000), nil).Once()
+	addressAccount, err := o.balanceManager.GetAddressBalance(ctx, o.signingAddress)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50000), addressAccount.Balance.Int64())
+
+	// Other pauses are not affected
+	o.resumeSigningAddressPausedForBalance(ctx, o.signingAddress)
+	o.pauseSigningAddressForBalance(ctx, o.signingAddress)
+	o.pauseSigningAddressForFailures(ctx, o.signingAddress)
+	o.resumeSigningAddressPausedForBalance(ctx, o.signingAddress)
+	assert.Contains(t, o.signingAddressesPausedUntil, o.signingAddress)
+}
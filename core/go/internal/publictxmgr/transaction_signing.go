@@ -29,24 +29,24 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
-func (it *inFlightTransactionStageController) signTx(ctx context.Context, from tktypes.EthAddress, ethTx *ethsigner.Transaction) ([]byte, *tktypes.Bytes32, error) {
+func (oc *orchestrator) signTx(ctx context.Context, from tktypes.EthAddress, ethTx *ethsigner.Transaction) ([]byte, *tktypes.Bytes32, error) {
 	log.L(ctx).Debugf("signTx entry")
 	signStart := time.Now()
 
 	// Reverse resolve the key - to get to this point it will be in the key management system
-	resolvedKey, err := it.keymgr.ReverseKeyLookup(ctx, it.pubTxManager.p.DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, from.String())
+	resolvedKey, err := oc.keymgr.ReverseKeyLookup(ctx, oc.pubTxManager.p.DB(), algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, from.String())
 	if err != nil {
 		log.L(ctx).Errorf("signing failed to resolve key %s for signing: %s", from.String(), err)
-		it.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusFail), time.Since(signStart).Seconds())
+		oc.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusFail), time.Since(signStart).Seconds())
 		return nil, nil, err
 	}
 	// Sign
-	sigPayload := ethTx.SignaturePayloadEIP1559(it.ethClient.ChainID())
+	sigPayload := ethTx.SignaturePayloadEIP1559(oc.ethClient.ChainID())
 	sigPayloadHash := sha3.NewLegacyKeccak256()
 	_, err = sigPayloadHash.Write(sigPayload.Bytes())
 	var signatureRSV []byte
 	if err == nil {
-		signatureRSV, err = it.keymgr.Sign(ctx, resolvedKey, signpayloads.OPAQUE_TO_RSV, tktypes.HexBytes(sigPayloadHash.Sum(nil)))
+		signatureRSV, err = oc.keymgr.Sign(ctx, resolvedKey, signpayloads.OPAQUE_TO_RSV, tktypes.HexBytes(sigPayloadHash.Sum(nil)))
 	}
 	var sig *secp256k1.SignatureData
 	if err == nil {
@@ -58,11 +58,11 @@ func (it *inFlightTransactionStageController) signTx(ctx context.Context, from t
 	}
 	if err != nil {
 		log.L(ctx).Errorf("signing failed with keyHandle %s (addr=%s): %s", resolvedKey.KeyHandle, resolvedKey.Verifier.Verifier, err)
-		it.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusFail), time.Since(signStart).Seconds())
+		oc.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusFail), time.Since(signStart).Seconds())
 		return nil, nil, err
 	}
 	calculatedHash := calculateTransactionHash(signedMessage)
 	log.L(ctx).Debugf("Calculated Hash %s of transaction %s:%d", calculatedHash, ethTx.From, ethTx.Nonce.Uint64())
-	it.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusSuccess), time.Since(signStart).Seconds())
+	oc.thMetrics.RecordOperationMetrics(ctx, string(InFlightTxOperationSign), string(GenericStatusSuccess), time.Since(signStart).Seconds())
 	return signedMessage, calculatedHash, err
 }
@@ -92,6 +92,11 @@ func (it *inFlightTransactionStageController) submitTX(ctx context.Context, mtx
 				it.gasPriceClient.DeleteCache(ctx)
 				log.L(ctx).Debug("Underpriced, removed gas price cache")
 				submissionOutcome = SubmissionOutcomeFailedRequiresRetry
+			case ethclient.ErrorReasonReplacementUnderpriced:
+				// resubmitting the same signed transaction will just be rejected again, so we
+				// go back round to bump the gas price by more than we did last time
+				log.L(ctx).Warnf("Replacement transaction %s rejected as underpriced: %s", mtx.GetSignerNonce(), submissionError)
+				submissionOutcome = SubmissionOutcomeFailedRequiresRetry
 			case ethclient.ErrorReasonTransactionReverted:
 				// transaction could be reverted due to gas estimate too low, clear the cache before try again
 				it.gasPriceClient.DeleteCache(ctx)
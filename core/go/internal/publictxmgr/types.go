@@ -134,6 +134,8 @@ type AddressAccount struct {
 	MinCost               *big.Int
 	MaxCost               *big.Int
 	Spent                 *big.Int
+	// if set, this amount of the balance is held back, and is not available to spend
+	Reserve *big.Int
 }
 
 func (ab *AddressAccount) Spend(ctx context.Context, cost *big.Int) (availableToSpend *big.Int) {
@@ -159,7 +161,11 @@ func (ab *AddressAccount) Spend(ctx context.Context, cost *big.Int) (availableTo
 
 func (ab *AddressAccount) GetAvailableToSpend(ctx context.Context) *big.Int {
 	balanceCopy := new(big.Int).Set(ab.Balance)
-	return balanceCopy.Sub(balanceCopy, ab.Spent)
+	balanceCopy.Sub(balanceCopy, ab.Spent)
+	if ab.Reserve != nil {
+		balanceCopy.Sub(balanceCopy, ab.Reserve)
+	}
+	return balanceCopy
 }
 
 type Confirmation struct {
@@ -269,6 +275,9 @@ type InMemoryTxStateReadOnly interface {
 	GetInFlightStatus() InFlightStatus
 	GetSignerNonce() string
 	GetGasLimit() uint64
+	// only set for transactions signed externally, which are always submitted exactly as signed
+	GetSignedTransaction() tktypes.HexBytes
+	GetFixedGasPricing() *pldapi.PublicTxGasPricing
 	IsReadyToExit() bool
 }
 type InMemoryTxStateManager interface {
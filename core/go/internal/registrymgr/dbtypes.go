@@ -16,27 +16,48 @@
 package registrymgr
 
 import (
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
 type DBEntry struct {
-	Registry         string            `gorm:"column:registry;primaryKey"`
-	ID               tktypes.HexBytes  `gorm:"column:id;primaryKey"`
-	Name             string            `gorm:"column:name"`
-	Created          tktypes.Timestamp `gorm:"column:created;autoCreateTime:nano"`
-	Updated          tktypes.Timestamp `gorm:"column:updated;autoUpdateTime:nano"`
-	Active           bool              `gorm:"column:active"`
-	ParentID         tktypes.HexBytes  `gorm:"column:parent_id"`
-	TransactionHash  *tktypes.Bytes32  `gorm:"column:tx_hash"`
-	BlockNumber      *int64            `gorm:"column:block_number"`
-	TransactionIndex *int64            `gorm:"column:tx_index"`
-	LogIndex         *int64            `gorm:"column:log_index"`
+	Registry         string             `gorm:"column:registry;primaryKey"`
+	ID               tktypes.HexBytes   `gorm:"column:id;primaryKey"`
+	Name             string             `gorm:"column:name"`
+	Created          tktypes.Timestamp  `gorm:"column:created;autoCreateTime:nano"`
+	Updated          tktypes.Timestamp  `gorm:"column:updated;autoUpdateTime:nano"`
+	Active           bool               `gorm:"column:active"`
+	ParentID         tktypes.HexBytes   `gorm:"column:parent_id"`
+	ValidUntil       *tktypes.Timestamp `gorm:"column:valid_until"`
+	TransactionHash  *tktypes.Bytes32   `gorm:"column:tx_hash"`
+	BlockNumber      *int64             `gorm:"column:block_number"`
+	TransactionIndex *int64             `gorm:"column:tx_index"`
+	LogIndex         *int64             `gorm:"column:log_index"`
 }
 
 func (dbe DBEntry) TableName() string {
 	return "reg_entries"
 }
 
+func (dbe *DBEntry) mapToAPI(withActive bool) *pldapi.RegistryEntry {
+	entry := &pldapi.RegistryEntry{
+		Registry:        dbe.Registry,
+		ID:              dbe.ID,
+		Name:            dbe.Name,
+		ValidUntil:      dbe.ValidUntil,
+		OnChainLocation: locationToAPI(dbe.TransactionHash, dbe.BlockNumber, dbe.TransactionIndex, dbe.LogIndex),
+	}
+	// Return nil (not empty) for parent string here - this avoids DB index complexity with null values
+	if len(dbe.ParentID) > 0 {
+		entry.ParentID = dbe.ParentID
+	}
+	if withActive {
+		entry.ActiveFlag = &pldapi.ActiveFlag{Active: dbe.Active}
+	}
+	return entry
+}
+
 type DBProperty struct {
 	Registry         string            `gorm:"column:registry;primaryKey"`
 	EntryID          tktypes.HexBytes  `gorm:"column:entry_id;primaryKey"`
@@ -54,3 +75,45 @@ type DBProperty struct {
 func (dbe DBProperty) TableName() string {
 	return "reg_props"
 }
+
+func (dbp *DBProperty) mapToAPI(withActive bool) *pldapi.RegistryProperty {
+	prop := &pldapi.RegistryProperty{
+		Registry:        dbp.Registry,
+		EntryID:         dbp.EntryID,
+		Name:            dbp.Name,
+		Value:           dbp.Value,
+		OnChainLocation: locationToAPI(dbp.TransactionHash, dbp.BlockNumber, dbp.TransactionIndex, dbp.LogIndex),
+	}
+	if withActive {
+		prop.ActiveFlag = &pldapi.ActiveFlag{Active: dbp.Active}
+	}
+	return prop
+}
+
+// For block info, our insert logic ensures if one is set they are all set
+func locationToAPI(txHash *tktypes.Bytes32, blockNumber, txIndex, logIndex *int64) *pldapi.OnChainLocation {
+	if blockNumber == nil {
+		return nil
+	}
+	return &pldapi.OnChainLocation{
+		TransactionHash:  txHash,
+		BlockNumber:      *blockNumber,
+		TransactionIndex: *txIndex,
+		LogIndex:         *logIndex,
+	}
+}
+
+func locationToProto(location *pldapi.OnChainLocation) *prototk.OnChainEventLocation {
+	if location == nil {
+		return nil
+	}
+	protoLocation := &prototk.OnChainEventLocation{
+		BlockNumber:      location.BlockNumber,
+		TransactionIndex: location.TransactionIndex,
+		LogIndex:         location.LogIndex,
+	}
+	if location.TransactionHash != nil {
+		protoLocation.TransactionHash = location.TransactionHash.String()
+	}
+	return protoLocation
+}
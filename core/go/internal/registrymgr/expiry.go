@@ -0,0 +1,66 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package registrymgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+func (rm *registryManager) expiryLoop() {
+	defer close(rm.expiryDone)
+
+	ctx := log.WithLogField(rm.bgCtx, "role", "registry_expiry")
+	ticker := time.NewTicker(rm.expiryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rm.expiryStop:
+			return
+		case <-ticker.C:
+			if _, err := rm.expireEntries(ctx); err != nil {
+				log.L(ctx).Errorf("Failed to expire registry entries: %s", err)
+			}
+		}
+	}
+}
+
+// Queries for active entries already exclude those that have passed their validUntil time, but
+// we mark them as inactive in the DB so they are returned by queries for inactive entries and
+// are no longer used for transport lookups held in the cache. The registry can re-activate an
+// entry at any point, by publishing it again with a new validUntil time (or none).
+func (rm *registryManager) expireEntries(ctx context.Context) (int64, error) {
+	now := tktypes.Timestamp(rm.now().UnixNano())
+	result := rm.p.DB().WithContext(ctx).
+		Table("reg_entries").
+		Where("active IS TRUE").
+		Where("valid_until <= ?", now).
+		Updates(map[string]any{
+			"active":  false,
+			"updated": now,
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.L(ctx).Infof("Marked %d expired registry entries inactive", result.RowsAffected)
+		rm.transportDetailsCache.Clear()
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package registrymgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryLoopMarksEntriesInactive(t *testing.T) {
+	ctx, rm, tp, _, done := newTestRegistry(t, true, func(mc *mockComponents, conf *pldconf.RegistryManagerConfig, regConf *prototk.RegistryConfig) {
+		conf.RegistryManager.ExpiryCheckInterval = confutil.P("10ms")
+	})
+	defer done()
+	require.NotNil(t, rm.expiryStop)
+
+	entry := &prototk.RegistryEntry{Id: randID(), Name: "entry1", Active: true, ValidUntil: time.Now().Add(50 * time.Millisecond).UnixNano()}
+	_, err := tp.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{entry},
+	})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		var dbEntry DBEntry
+		err := rm.p.DB().Where("id = ?", tktypes.MustParseHexBytes(entry.Id)).First(&dbEntry).Error
+		return err == nil && !dbEntry.Active
+	}, 5*time.Second, 10*time.Millisecond)
+
+	entries, err := tp.r.QueryEntries(ctx, rm.p.DB(), pldapi.ActiveFilterInactive, query.NewQueryBuilder().Limit(100).Query())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.Id, entries[0].ID.HexString())
+}
+
+func TestExpiryLoopDisabled(t *testing.T) {
+	_, rm, _, _, done := newTestRegistry(t, false, func(mc *mockComponents, conf *pldconf.RegistryManagerConfig, regConf *prototk.RegistryConfig) {
+		conf.RegistryManager.ExpiryCheckInterval = confutil.P("0")
+	})
+	defer done()
+	assert.Nil(t, rm.expiryStop)
+}
+
+func TestExpiryLoopSweepFail(t *testing.T) {
+	_, _, _, m, done := newTestRegistry(t, false, func(mc *mockComponents, conf *pldconf.RegistryManagerConfig, regConf *prototk.RegistryConfig) {
+		conf.RegistryManager.ExpiryCheckInterval = confutil.P("1ms")
+		mc.db.MatchExpectationsInOrder(false)
+		mc.db.ExpectExec("UPDATE.*reg_entries").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	// The loop logs the error and continues
+	assert.Eventually(t, func() bool {
+		return m.db.ExpectationsWereMet() == nil
+	}, 5*time.Second, 1*time.Millisecond)
+}
+
+func TestExpireEntriesFail(t *testing.T) {
+	ctx, rm, _, m, done := newTestRegistry(t, false)
+	defer done()
+
+	m.db.ExpectExec("UPDATE.*reg_entries").WillReturnError(fmt.Errorf("pop"))
+
+	_, err := rm.expireEntries(ctx)
+	assert.Regexp(t, "pop", err)
+}
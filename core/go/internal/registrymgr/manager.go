@@ -18,6 +18,7 @@ package registrymgr
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 )
@@ -40,9 +42,10 @@ type registryManager struct {
 
 	conf *pldconf.RegistryManagerConfig
 
-	p            persistence.Persistence
-	blockIndexer blockindexer.BlockIndexer
-	rpcModule    *rpcserver.RPCModule
+	p                persistence.Persistence
+	blockIndexer     blockindexer.BlockIndexer
+	transportManager components.TransportManager
+	rpcModule        *rpcserver.RPCModule
 
 	// We provide a high level of customization of how the nodes are looked up in the registry
 	registryTransportLookups map[string]*transportLookup
@@ -53,6 +56,11 @@ type registryManager struct {
 
 	registriesByID   map[uuid.UUID]*registry
 	registriesByName map[string]*registry
+
+	expiryCheckInterval time.Duration
+	now                 func() time.Time
+	expiryStop          chan struct{}
+	expiryDone          chan struct{}
 }
 
 func NewRegistryManager(bgCtx context.Context, conf *pldconf.RegistryManagerConfig) components.RegistryManager {
@@ -63,14 +71,29 @@ func NewRegistryManager(bgCtx context.Context, conf *pldconf.RegistryManagerConf
 		registriesByName:         make(map[string]*registry),
 		registryTransportLookups: make(map[string]*transportLookup),
 		transportDetailsCache:    cache.NewCache[string, []*components.RegistryNodeTransportEntry](&conf.RegistryManager.RegistryCache, pldconf.RegistryCacheDefaults),
+		expiryCheckInterval:      confutil.DurationMin(conf.RegistryManager.ExpiryCheckInterval, 0, *pldconf.RegistryManagerDefaults.ExpiryCheckInterval),
+		now:                      time.Now,
 	}
 }
 
 func (rm *registryManager) PreInit(pic components.PreInitComponents) (_ *components.ManagerInitResult, err error) {
 	rm.p = pic.Persistence()
 
+	// An allow-list of registries can be configured, with all registries trusted if it is empty
+	trusted := make(map[string]bool)
+	for _, regName := range rm.conf.RegistryManager.TrustedRegistries {
+		if rm.conf.Registries[regName] == nil {
+			return nil, i18n.NewError(rm.bgCtx, msgs.MsgRegistryTrustedNotConfigured, regName)
+		}
+		trusted[regName] = true
+	}
+
 	// For each of the registries, parse the transport lookup semantics
 	for regName, regConf := range rm.conf.Registries {
+		if len(trusted) > 0 && !trusted[regName] {
+			log.L(rm.bgCtx).Warnf("Transport lookups disabled for registry '%s' as it is not in the trusted registries list", regName)
+			continue
+		}
 		if confutil.Bool(regConf.Transports.Enabled, *pldconf.RegistryTransportsDefaults.Enabled) {
 			if rm.registryTransportLookups[regName], err = newTransportLookup(rm.bgCtx, regName, &regConf.Transports); err != nil {
 				return nil, err
@@ -86,12 +109,25 @@ func (rm *registryManager) PreInit(pic components.PreInitComponents) (_ *compone
 
 func (rm *registryManager) PostInit(c components.AllComponents) error {
 	rm.blockIndexer = c.BlockIndexer()
+	rm.transportManager = c.TransportManager()
 	return nil
 }
 
-func (rm *registryManager) Start() error { return nil }
+func (rm *registryManager) Start() error {
+	if rm.expiryCheckInterval > 0 {
+		rm.expiryStop = make(chan struct{})
+		rm.expiryDone = make(chan struct{})
+		go rm.expiryLoop()
+	}
+	return nil
+}
 
 func (rm *registryManager) Stop() {
+	if rm.expiryStop != nil {
+		close(rm.expiryStop)
+		<-rm.expiryDone
+	}
+
 	rm.mux.Lock()
 	var allRegistries []*registry
 	for _, t := range rm.registriesByID {
@@ -199,3 +235,33 @@ func (rm *registryManager) GetNodeTransports(ctx context.Context, node string) (
 
 	return nil, i18n.NewError(ctx, msgs.MsgRegistryNodeEntiresNotFound, node)
 }
+
+// Returns the transports this node has published for itself in the given registry, read directly
+// from the registry (rather than the cache) so that operators can verify what other nodes will see
+func (rm *registryManager) getOwnTransports(ctx context.Context, registryName string) ([]*pldapi.RegistryNodeTransportEntry, error) {
+	rm.mux.Lock()
+	r := rm.registriesByName[registryName]
+	tl := rm.registryTransportLookups[registryName]
+	rm.mux.Unlock()
+	if r == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgRegistryNotFound, registryName)
+	}
+	if tl == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgRegistryTransportsNotEnabled, registryName)
+	}
+
+	regTransports, err := tl.getNodeTransports(ctx, rm.p.DB() /* no TX needed */, r, rm.transportManager.LocalNodeName())
+	if err != nil {
+		return nil, err
+	}
+	transports := make([]*pldapi.RegistryNodeTransportEntry, len(regTransports))
+	for i, t := range regTransports {
+		transports[i] = &pldapi.RegistryNodeTransportEntry{
+			Node:      t.Node,
+			Registry:  t.Registry,
+			Transport: t.Transport,
+			Details:   t.Details,
+		}
+	}
+	return transports, nil
+}
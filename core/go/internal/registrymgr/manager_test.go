@@ -39,6 +39,7 @@ type mockComponents struct {
 	db            sqlmock.Sqlmock
 	allComponents *componentmocks.AllComponents
 	blockIndexer  *componentmocks.BlockIndexer
+	transportMgr  *componentmocks.TransportManager
 }
 
 func newTestRegistryManager(t *testing.T, realDB bool, conf *pldconf.RegistryManagerConfig, extraSetup ...func(mc *mockComponents)) (context.Context, *registryManager, *mockComponents, func()) {
@@ -47,8 +48,10 @@ func newTestRegistryManager(t *testing.T, realDB bool, conf *pldconf.RegistryMan
 	mc := &mockComponents{
 		blockIndexer:  componentmocks.NewBlockIndexer(t),
 		allComponents: componentmocks.NewAllComponents(t),
+		transportMgr:  componentmocks.NewTransportManager(t),
 	}
 	mc.allComponents.On("BlockIndexer").Return(mc.blockIndexer).Maybe()
+	mc.allComponents.On("TransportManager").Return(mc.transportMgr).Maybe()
 
 	var p persistence.Persistence
 	var err error
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync/atomic"
 
@@ -235,11 +236,15 @@ func (r *registry) upsertRegistryRecords(ctx context.Context, dbTX *gorm.DB, pro
 			Name:     protoEntry.Name,
 			Active:   protoEntry.Active,
 		}
+		if protoEntry.ValidUntil > 0 {
+			validUntil := tktypes.Timestamp(protoEntry.ValidUntil)
+			dbe.ValidUntil = &validUntil
+		}
 		if protoEntry.Location != nil {
 			txHash, _ := tktypes.ParseBytes32(protoEntry.Location.TransactionHash)
 			dbe.TransactionHash = &txHash
 			dbe.BlockNumber = &protoEntry.Location.BlockNumber
-			dbe.TransactionIndex = &protoEntry.Location.LogIndex
+			dbe.TransactionIndex = &protoEntry.Location.TransactionIndex
 			dbe.LogIndex = &protoEntry.Location.LogIndex
 		}
 		dbEntries[i] = dbe
@@ -280,7 +285,7 @@ func (r *registry) upsertRegistryRecords(ctx context.Context, dbTX *gorm.DB, pro
 			txHash, _ := tktypes.ParseBytes32(protoProp.Location.TransactionHash)
 			dbp.TransactionHash = &txHash
 			dbp.BlockNumber = &protoProp.Location.BlockNumber
-			dbp.TransactionIndex = &protoProp.Location.LogIndex
+			dbp.TransactionIndex = &protoProp.Location.TransactionIndex
 			dbp.LogIndex = &protoProp.Location.LogIndex
 		}
 		dbProps[i] = dbp
@@ -299,7 +304,8 @@ func (r *registry) upsertRegistryRecords(ctx context.Context, dbTX *gorm.DB, pro
 				},
 				DoUpdates: clause.AssignmentColumns([]string{
 					"updated",
-					"active", // this is the primary thing that can actually be mutated
+					"active",      // this is the primary thing that can actually be mutated
+					"valid_until", // an update with no expiry clears any previous one
 					"tx_hash",
 					"block_number",
 					"tx_index",
@@ -391,14 +397,18 @@ func (r *registry) QueryEntries(ctx context.Context, dbTX *gorm.DB, fActive plda
 			Where(`"reg_entries"."registry" = ?`, r.name),
 		dfs)
 
+	// Entries that have passed their validUntil time are inactive, even before the
+	// background sweep has marked them as such in the DB
+	now := tktypes.Timestamp(r.rm.now().UnixNano())
 	switch fActive {
 	case pldapi.ActiveFilterAny: // no filter
 	case pldapi.ActiveFilterInactive:
-		q = q.Where(`"reg_entries"."active" IS FALSE`)
+		q = q.Where(`("reg_entries"."active" IS FALSE OR "reg_entries"."valid_until" <= ?)`, now)
 	case pldapi.ActiveFilterActive:
 		fallthrough
 	default:
-		q = q.Where(`"reg_entries"."active" IS TRUE`)
+		q = q.Where(`"reg_entries"."active" IS TRUE`).
+			Where(`("reg_entries"."valid_until" IS NULL OR "reg_entries"."valid_until" > ?)`, now)
 	}
 
 	// After BuildGORM completes, dfs will have a list of all the fields used in the query.
@@ -424,28 +434,11 @@ func (r *registry) QueryEntries(ctx context.Context, dbTX *gorm.DB, fActive plda
 
 	entries := make([]*pldapi.RegistryEntry, len(dbEntries))
 	for i, dbe := range dbEntries {
-		entry := &pldapi.RegistryEntry{
-			Registry: dbe.Registry,
-			ID:       dbe.ID,
-			Name:     dbe.Name,
-		}
-		// Return nil (not empty) for parent string here - this avoids DB index complexity with null values
-		if len(dbe.ParentID) > 0 {
-			entry.ParentID = dbe.ParentID
-		}
 		// Return the active field in the JSON if the query was anything apart from "active"
-		if fActive != pldapi.ActiveFilterActive {
-			entry.ActiveFlag = &pldapi.ActiveFlag{Active: dbe.Active}
+		entries[i] = dbe.mapToAPI(fActive != pldapi.ActiveFilterActive)
+		if entries[i].ActiveFlag != nil && dbe.ValidUntil != nil && *dbe.ValidUntil <= now {
+			entries[i].ActiveFlag.Active = false
 		}
-		// For block info, our insert logic ensures if one is set they are all set
-		if dbe.BlockNumber != nil {
-			entry.OnChainLocation = &pldapi.OnChainLocation{
-				BlockNumber:      *dbe.BlockNumber,
-				TransactionIndex: *dbe.TransactionIndex,
-				LogIndex:         *dbe.TransactionIndex,
-			}
-		}
-		entries[i] = entry
 	}
 
 	return entries, nil
@@ -477,25 +470,8 @@ func (r *registry) GetEntryProperties(ctx context.Context, dbTX *gorm.DB, fActiv
 
 	props := make([]*pldapi.RegistryProperty, len(dbProps))
 	for i, dbp := range dbProps {
-		prop := &pldapi.RegistryProperty{
-			Registry: dbp.Registry,
-			EntryID:  dbp.EntryID,
-			Name:     dbp.Name,
-			Value:    dbp.Value,
-		}
 		// Return the active field in the JSON if the query was anything apart from "active"
-		if fActive != pldapi.ActiveFilterActive {
-			prop.ActiveFlag = &pldapi.ActiveFlag{Active: dbp.Active}
-		}
-		// For block info, our insert logic ensures if one is set they are all set
-		if dbp.BlockNumber != nil {
-			prop.OnChainLocation = &pldapi.OnChainLocation{
-				BlockNumber:      *dbp.BlockNumber,
-				TransactionIndex: *dbp.TransactionIndex,
-				LogIndex:         *dbp.TransactionIndex,
-			}
-		}
-		props[i] = prop
+		props[i] = dbp.mapToAPI(fActive != pldapi.ActiveFilterActive)
 	}
 
 	return props, nil
@@ -542,6 +518,135 @@ func (r *registry) QueryEntriesWithProps(ctx context.Context, dbTX *gorm.DB, fAc
 	return withProps, nil
 }
 
+// Snapshots are read a page at a time, so that a large registry is not read in a single query
+var snapshotPageSize = 100
+
+// ExportSnapshot returns every entry and property in the registry, regardless of whether it is active.
+// Parents are always listed (and so imported) before their children.
+func (r *registry) ExportSnapshot(ctx context.Context, dbTX *gorm.DB) (*pldapi.RegistrySnapshot, error) {
+	var dbEntries []*DBEntry
+	for {
+		q := dbTX.WithContext(ctx).
+			Table("reg_entries").
+			Where("registry = ?", r.name)
+		if len(dbEntries) > 0 {
+			last := dbEntries[len(dbEntries)-1]
+			q = q.Where("created > ? OR (created = ? AND id > ?)", last.Created, last.Created, last.ID)
+		}
+		var page []*DBEntry
+		err := q.Order("created").
+			Order("id").
+			Limit(snapshotPageSize).
+			Find(&page).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		dbEntries = append(dbEntries, page...)
+		if len(page) < snapshotPageSize {
+			break
+		}
+	}
+
+	var dbProps []*DBProperty
+	for {
+		q := dbTX.WithContext(ctx).
+			Table("reg_props").
+			Where("registry = ?", r.name)
+		if len(dbProps) > 0 {
+			last := dbProps[len(dbProps)-1]
+			q = q.Where("entry_id > ? OR (entry_id = ? AND name > ?)", last.EntryID, last.EntryID, last.Name)
+		}
+		var page []*DBProperty
+		err := q.Order("entry_id").
+			Order("name").
+			Limit(snapshotPageSize).
+			Find(&page).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		dbProps = append(dbProps, page...)
+		if len(page) < snapshotPageSize {
+			break
+		}
+	}
+
+	snapshot := &pldapi.RegistrySnapshot{
+		Registry:   r.name,
+		Entries:    make([]*pldapi.RegistryEntry, len(dbEntries)),
+		Properties: make([]*pldapi.RegistryProperty, len(dbProps)),
+	}
+	for i, dbe := range orderParentsFirst(dbEntries) {
+		snapshot.Entries[i] = dbe.mapToAPI(true)
+	}
+	for i, dbp := range dbProps {
+		snapshot.Properties[i] = dbp.mapToAPI(true)
+	}
+	return snapshot, nil
+}
+
+// Entries created in the same DB transaction share a created time, so we order by depth in the hierarchy
+func orderParentsFirst(dbEntries []*DBEntry) []*DBEntry {
+	byID := make(map[string]*DBEntry, len(dbEntries))
+	for _, dbe := range dbEntries {
+		byID[dbe.ID.String()] = dbe
+	}
+	depths := make(map[string]int, len(dbEntries))
+	var depth func(dbe *DBEntry) int
+	depth = func(dbe *DBEntry) int {
+		d, ok := depths[dbe.ID.String()]
+		if !ok {
+			if parent := byID[dbe.ParentID.String()]; len(dbe.ParentID) > 0 && parent != nil {
+				d = depth(parent) + 1
+			}
+			depths[dbe.ID.String()] = d
+		}
+		return d
+	}
+	sort.SliceStable(dbEntries, func(i, j int) bool {
+		return depth(dbEntries[i]) < depth(dbEntries[j])
+	})
+	return dbEntries
+}
+
+// ImportSnapshot loads a snapshot from ExportSnapshot through the same validation and upsert logic
+// used for records from the registry plugin, so it is safe to import over existing records.
+func (r *registry) ImportSnapshot(ctx context.Context, dbTX *gorm.DB, snapshot *pldapi.RegistrySnapshot) (func(), error) {
+	if snapshot.Registry != r.name {
+		return nil, i18n.NewError(ctx, msgs.MsgRegistrySnapshotMismatch, snapshot.Registry, r.name)
+	}
+
+	protoEntries := make([]*prototk.RegistryEntry, len(snapshot.Entries))
+	for i, e := range snapshot.Entries {
+		protoEntries[i] = &prototk.RegistryEntry{
+			Id:       e.ID.String(),
+			Name:     e.Name,
+			Active:   e.ActiveFlag == nil || e.ActiveFlag.Active,
+			Location: locationToProto(e.OnChainLocation),
+		}
+		if e.ValidUntil != nil {
+			protoEntries[i].ValidUntil = int64(*e.ValidUntil)
+		}
+		if len(e.ParentID) > 0 {
+			protoEntries[i].ParentId = e.ParentID.String()
+		}
+	}
+	protoProps := make([]*prototk.RegistryProperty, len(snapshot.Properties))
+	for i, p := range snapshot.Properties {
+		protoProps[i] = &prototk.RegistryProperty{
+			EntryId:        p.EntryID.String(),
+			Name:           p.Name,
+			Value:          p.Value,
+			Active:         p.ActiveFlag == nil || p.ActiveFlag.Active,
+			PluginReserved: strings.HasPrefix(p.Name, "$"),
+			Location:       locationToProto(p.OnChainLocation),
+		}
+	}
+
+	return r.upsertRegistryRecords(ctx, dbTX, protoEntries, protoProps)
+}
+
 func (r *registry) close() {
 	r.cancelCtx()
 	<-r.initDone
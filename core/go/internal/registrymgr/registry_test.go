@@ -23,11 +23,13 @@ import (
 	"math/big"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
@@ -196,6 +198,8 @@ func TestUpsertRegistryRecordsRealDBok(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, entries, 2)
 	assert.Equal(t, rootEntry1.Id, entries[0].ID.HexString())
+	assert.Equal(t, rootEntry1.Location.TransactionIndex, entries[0].TransactionIndex)
+	assert.Equal(t, rootEntry1.Location.LogIndex, entries[0].LogIndex)
 	require.Len(t, entries[0].Properties, 2)
 	require.Equal(t, rootEntry1SysProp.Value, entries[0].Properties[rootEntry1SysProp.Name])
 	require.Equal(t, rootEntry1Props1.Value, entries[0].Properties[rootEntry1Props1.Name])
@@ -304,6 +308,86 @@ func TestUpsertRegistryRecordsRealDBok(t *testing.T) {
 	require.Equal(t, rootEntry2Props2.Value, propsMap[rootEntry2Props2.Name])
 }
 
+func TestUpsertRegistryRecordsValidUntilRealDBok(t *testing.T) {
+	ctx, rm, tp, _, done := newTestRegistry(t, true)
+	defer done()
+
+	r, err := rm.GetRegistry(ctx, "test1")
+	require.NoError(t, err)
+	db := rm.p.DB()
+
+	now := time.Now()
+	rm.now = func() time.Time { return now }
+
+	// One entry that expires in the future, one that has already expired, and one with no expiry
+	expiresLater := &prototk.RegistryEntry{Id: randID(), Name: "later", Active: true, ValidUntil: now.Add(1 * time.Hour).UnixNano()}
+	expired := &prototk.RegistryEntry{Id: randID(), Name: "expired", Active: true, ValidUntil: now.Add(-1 * time.Second).UnixNano()}
+	noExpiry := &prototk.RegistryEntry{Id: randID(), Name: "noexpiry", Active: true}
+	_, err = tp.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries:    []*prototk.RegistryEntry{expiresLater, expired, noExpiry},
+		Properties: []*prototk.RegistryProperty{randPropFor(expiresLater.Id), randPropFor(expired.Id)},
+	})
+	require.NoError(t, err)
+
+	entryNames := func(fActive pldapi.ActiveFilter) []string {
+		entries, err := r.QueryEntriesWithProps(ctx, db, fActive, query.NewQueryBuilder().Sort(".name").Limit(100).Query())
+		require.NoError(t, err)
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name
+		}
+		return names
+	}
+
+	// The expired entry is excluded from active queries, even though it is still active in the DB
+	assert.Equal(t, []string{"later", "noexpiry"}, entryNames(pldapi.ActiveFilterActive))
+	assert.Equal(t, []string{"expired"}, entryNames(pldapi.ActiveFilterInactive))
+	entries, err := r.QueryEntriesWithProps(ctx, db, pldapi.ActiveFilterAny,
+		query.NewQueryBuilder().Equal(".name", "expired").Limit(100).Query())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Active)
+	assert.Equal(t, expired.ValidUntil, int64(*entries[0].ValidUntil))
+	require.Len(t, entries[0].Properties, 1)
+
+	// Move time past the expiry of the other entry
+	now = now.Add(2 * time.Hour)
+	assert.Equal(t, []string{"noexpiry"}, entryNames(pldapi.ActiveFilterActive))
+	assert.Equal(t, []string{"expired", "later"}, entryNames(pldapi.ActiveFilterInactive))
+
+	// The sweep marks both inactive in the DB, and clears the transport cache
+	rm.transportDetailsCache.Set("node1", []*components.RegistryNodeTransportEntry{})
+	count, err := rm.expireEntries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	_, cached := rm.transportDetailsCache.Get("node1")
+	assert.False(t, cached)
+	snapshot, err := r.ExportSnapshot(ctx, db)
+	require.NoError(t, err)
+	for _, e := range snapshot.Entries {
+		assert.Equal(t, e.Name == "noexpiry", e.Active, e.Name)
+	}
+	assert.Equal(t, []string{"expired", "later"}, entryNames(pldapi.ActiveFilterInactive))
+
+	// Nothing more to expire
+	count, err = rm.expireEntries(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// The registry can re-publish the entry without an expiry to re-activate it
+	expiresLater.ValidUntil = 0
+	_, err = tp.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{expiresLater},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later", "noexpiry"}, entryNames(pldapi.ActiveFilterActive))
+	entries, err = r.QueryEntriesWithProps(ctx, db, pldapi.ActiveFilterActive,
+		query.NewQueryBuilder().Equal(".name", "later").Limit(100).Query())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Nil(t, entries[0].ValidUntil)
+}
+
 func TestUpsertRegistryRecordsInsertBadID(t *testing.T) {
 	ctx, _, tp, m, done := newTestRegistry(t, false)
 	defer done()
@@ -24,6 +24,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
 )
 
 func (rm *registryManager) RPCModule() *rpcserver.RPCModule {
@@ -35,7 +36,10 @@ func (rm *registryManager) initRPC() {
 		Add("reg_registries", rm.rpcListRegistries()).
 		Add("reg_queryEntries", rm.rpcQueryEntries()).
 		Add("reg_queryEntriesWithProps", rm.rpcQueryEntriesWithProps()).
-		Add("reg_getEntryProperties", rm.rpcGetEntryProperties())
+		Add("reg_getEntryProperties", rm.rpcGetEntryProperties()).
+		Add("reg_exportSnapshot", rm.rpcExportSnapshot()).
+		Add("reg_importSnapshot", rm.rpcImportSnapshot()).
+		Add("reg_getOwnTransports", rm.rpcGetOwnTransports())
 }
 
 func (rm *registryManager) rpcListRegistries() rpcserver.RPCHandler {
@@ -94,3 +98,44 @@ func (rm *registryManager) rpcGetEntryProperties() rpcserver.RPCHandler {
 		)
 	})
 }
+
+func (rm *registryManager) rpcExportSnapshot() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		registryName string,
+	) (*pldapi.RegistrySnapshot, error) {
+		return withRegistry(ctx, rm, registryName,
+			func(r components.Registry) (*pldapi.RegistrySnapshot, error) {
+				return r.ExportSnapshot(ctx, rm.p.DB())
+			},
+		)
+	})
+}
+
+func (rm *registryManager) rpcImportSnapshot() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		snapshot pldapi.RegistrySnapshot,
+	) (bool, error) {
+		return withRegistry(ctx, rm, snapshot.Registry,
+			func(r components.Registry) (bool, error) {
+				var postCommit func()
+				err := rm.p.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+					postCommit, err = r.ImportSnapshot(ctx, dbTX, &snapshot)
+					return err
+				})
+				if err != nil {
+					return false, err
+				}
+				postCommit()
+				return true, nil
+			},
+		)
+	})
+}
+
+func (rm *registryManager) rpcGetOwnTransports() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		registryName string,
+	) ([]*pldapi.RegistryNodeTransportEntry, error) {
+		return rm.getOwnTransports(ctx, registryName)
+	})
+}
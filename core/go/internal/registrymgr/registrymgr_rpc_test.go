@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -28,6 +29,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -99,3 +101,137 @@ func newTestRPCServer(t *testing.T, ctx context.Context, rm *registryManager) (r
 	return c, s.Stop
 
 }
+
+func TestRPCExportImportSnapshot(t *testing.T) {
+	// Small pages, so the export has to page through the entries and properties
+	defaultPageSize := snapshotPageSize
+	snapshotPageSize = 2
+	defer func() { snapshotPageSize = defaultPageSize }()
+
+	ctx, rm1, tp1, _, done1 := newTestRegistry(t, true)
+	defer done1()
+	rpc1, rpcDone1 := newTestRPCServer(t, ctx, rm1)
+	defer rpcDone1()
+
+	// A hierarchy with inactive records, and plugin reserved properties
+	rootEntry := &prototk.RegistryEntry{Id: randID(), Name: "root", Location: randChainInfo(), Active: true}
+	childEntry := &prototk.RegistryEntry{Id: randID(), Name: "child", ParentId: rootEntry.Id, Location: randChainInfo(), Active: true, ValidUntil: time.Now().Add(1 * time.Hour).UnixNano()}
+	inactiveEntry := &prototk.RegistryEntry{Id: randID(), Name: "gone", Location: randChainInfo(), Active: false}
+	inactiveProp := randPropFor(childEntry.Id)
+	inactiveProp.Active = false
+	_, err := tp1.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{rootEntry, childEntry, inactiveEntry},
+		Properties: []*prototk.RegistryProperty{
+			randPropFor(rootEntry.Id),
+			newSystemPropFor(rootEntry.Id, "$owner", "0x12345"),
+			inactiveProp,
+			{EntryId: childEntry.Id, Name: "offchain", Value: "no location", Active: true},
+		},
+	})
+	require.NoError(t, err)
+
+	var snapshot1 *pldapi.RegistrySnapshot
+	err = rpc1.CallRPC(ctx, &snapshot1, "reg_exportSnapshot", tp1.r.name)
+	require.NoError(t, err)
+	assert.Equal(t, "test1", snapshot1.Registry)
+	require.Len(t, snapshot1.Entries, 3)
+	require.Len(t, snapshot1.Properties, 4)
+	// root and gone are both top-level entries created together, so their relative order is by ID
+	var exportedRoot *pldapi.RegistryEntry
+	for _, e := range snapshot1.Entries {
+		if e.Name == "root" {
+			exportedRoot = e
+		}
+	}
+	require.NotNil(t, exportedRoot)
+	assert.Equal(t, "child", snapshot1.Entries[2].Name)
+	assert.Equal(t, childEntry.ValidUntil, int64(*snapshot1.Entries[2].ValidUntil))
+	assert.Equal(t, &pldapi.OnChainLocation{
+		TransactionHash:  confutil.P(tktypes.MustParseBytes32(rootEntry.Location.TransactionHash)),
+		BlockNumber:      rootEntry.Location.BlockNumber,
+		TransactionIndex: rootEntry.Location.TransactionIndex,
+		LogIndex:         rootEntry.Location.LogIndex,
+	}, exportedRoot.OnChainLocation)
+
+	// Load it into a separate node
+	_, rm2, _, _, done2 := newTestRegistry(t, true)
+	defer done2()
+	rpc2, rpcDone2 := newTestRPCServer(t, ctx, rm2)
+	defer rpcDone2()
+
+	var imported bool
+	err = rpc2.CallRPC(ctx, &imported, "reg_importSnapshot", snapshot1)
+	require.NoError(t, err)
+	assert.True(t, imported)
+
+	// Importing again is safe, as it goes through the same upsert as the registry plugin
+	err = rpc2.CallRPC(ctx, &imported, "reg_importSnapshot", snapshot1)
+	require.NoError(t, err)
+
+	var snapshot2 *pldapi.RegistrySnapshot
+	err = rpc2.CallRPC(ctx, &snapshot2, "reg_exportSnapshot", "test1")
+	require.NoError(t, err)
+	assert.Equal(t, snapshot1.Registry, snapshot2.Registry)
+	assert.ElementsMatch(t, snapshot1.Entries, snapshot2.Entries)
+	assert.ElementsMatch(t, snapshot1.Properties, snapshot2.Properties)
+
+	err = rpc2.CallRPC(ctx, &imported, "reg_importSnapshot", &pldapi.RegistrySnapshot{Registry: "unknown"})
+	assert.Regexp(t, "PD012101", err)
+
+	r2, err := rm2.GetRegistry(ctx, "test1")
+	require.NoError(t, err)
+	_, err = r2.ImportSnapshot(ctx, rm2.p.DB(), &pldapi.RegistrySnapshot{Registry: "other"})
+	assert.Regexp(t, "PD012110", err)
+}
+
+func TestRPCGetOwnTransports(t *testing.T) {
+	ctx, rm, tp, mc, done := newTestRegistry(t, true)
+	defer done()
+	mc.transportMgr.On("LocalNodeName").Return("node1")
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, rm)
+	defer rpcDone()
+
+	// Nothing published yet
+	var transports []*pldapi.RegistryNodeTransportEntry
+	err := rpc.CallRPC(ctx, &transports, "reg_getOwnTransports", "test1")
+	require.NoError(t, err)
+	assert.Empty(t, transports)
+
+	// Publish our own transport details, alongside those of another node
+	node1Entry := &prototk.RegistryEntry{Id: randID(), Name: "node1", Location: randChainInfo(), Active: true}
+	node2Entry := &prototk.RegistryEntry{Id: randID(), Name: "node2", Location: randChainInfo(), Active: true}
+	_, regErr := tp.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{node1Entry, node2Entry},
+		Properties: []*prototk.RegistryProperty{
+			newPropFor(node1Entry.Id, "organization", "Widgets 4 You"),
+			newPropFor(node1Entry.Id, "transport.grpc", "my endpoint"),
+			newPropFor(node2Entry.Id, "transport.grpc", "their endpoint"),
+		},
+	})
+	require.NoError(t, regErr)
+
+	err = rpc.CallRPC(ctx, &transports, "reg_getOwnTransports", "test1")
+	require.NoError(t, err)
+	assert.Equal(t, []*pldapi.RegistryNodeTransportEntry{
+		{
+			Node:      "node1",
+			Registry:  "test1",
+			Transport: "grpc",
+			Details:   "my endpoint",
+		},
+	}, transports)
+
+	err = rpc.CallRPC(ctx, &transports, "reg_getOwnTransports", "unknown")
+	assert.Regexp(t, "PD012101", err)
+}
+
+func TestGetOwnTransportsNotEnabled(t *testing.T) {
+	ctx, rm, _, _, done := newTestRegistry(t, false, func(mc *mockComponents, conf *pldconf.RegistryManagerConfig, regConf *prototk.RegistryConfig) {
+		conf.Registries["test1"].Transports.Enabled = confutil.P(false)
+	})
+	defer done()
+
+	_, err := rm.getOwnTransports(ctx, "test1")
+	assert.Regexp(t, "PD012111", err)
+}
@@ -16,12 +16,15 @@
 package registrymgr
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/stretchr/testify/require"
 )
@@ -136,6 +139,74 @@ func TestGetNodeTransportsCustomSettingsRealDB(t *testing.T) {
 
 }
 
+func TestGetNodeTransportsTrustedRegistriesRealDB(t *testing.T) {
+	ctx, rm, tp1, _, done := newTestRegistry(t, true, func(mc *mockComponents, conf *pldconf.RegistryManagerConfig, regConf *prototk.RegistryConfig) {
+		conf.Registries["test2"] = &pldconf.RegistryConfig{}
+		conf.RegistryManager.TrustedRegistries = []string{"test2"}
+	})
+	defer done()
+
+	tp2 := newTestPlugin(&plugintk.RegistryAPIFunctions{
+		ConfigureRegistry: func(ctx context.Context, ctr *prototk.ConfigureRegistryRequest) (*prototk.ConfigureRegistryResponse, error) {
+			return &prototk.ConfigureRegistryResponse{RegistryConfig: &prototk.RegistryConfig{}}, nil
+		},
+	})
+	_, err := rm.RegistryRegistered("test2", uuid.New(), tp2)
+	require.NoError(t, err)
+	tp2.r = rm.registriesByName["test2"]
+	<-tp2.r.initDone
+
+	// The untrusted registry attempts to redirect node1, and is the only one to know about node2
+	untrusted1 := &prototk.RegistryEntry{Id: randID(), Name: "node1", Active: true}
+	untrusted2 := &prototk.RegistryEntry{Id: randID(), Name: "node2", Active: true}
+	_, err = tp1.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{untrusted1, untrusted2},
+		Properties: []*prototk.RegistryProperty{
+			newPropFor(untrusted1.Id, "transport.websockets", "rogue details"),
+			newPropFor(untrusted2.Id, "transport.websockets", "rogue details"),
+		},
+	})
+	require.NoError(t, err)
+
+	trusted1 := &prototk.RegistryEntry{Id: randID(), Name: "node1", Active: true}
+	_, err = tp2.r.UpsertRegistryRecords(ctx, &prototk.UpsertRegistryRecordsRequest{
+		Entries: []*prototk.RegistryEntry{trusted1},
+		Properties: []*prototk.RegistryProperty{
+			newPropFor(trusted1.Id, "transport.websockets", "trusted details"),
+		},
+	})
+	require.NoError(t, err)
+
+	transports, err := rm.GetNodeTransports(ctx, "node1")
+	require.NoError(t, err)
+	require.Equal(t, []*components.RegistryNodeTransportEntry{
+		{
+			Node:      "node1",
+			Registry:  "test2",
+			Transport: "websockets",
+			Details:   "trusted details",
+		},
+	}, transports)
+
+	_, err = rm.GetNodeTransports(ctx, "node2")
+	require.Regexp(t, "PD012100", err)
+}
+
+func TestTrustedRegistryNotConfigured(t *testing.T) {
+	_, rm, mc, done := newTestRegistryManager(t, false, &pldconf.RegistryManagerConfig{
+		Registries: map[string]*pldconf.RegistryConfig{
+			"test1": {},
+		},
+		RegistryManager: pldconf.RegistryManagerManagerConfig{
+			TrustedRegistries: []string{"test1", "test2"},
+		},
+	}, func(mc *mockComponents) { mc.noInit = true })
+	defer done()
+
+	_, err := rm.PreInit(mc.allComponents)
+	require.Regexp(t, "PD012112.*test2", err)
+}
+
 func TestGetNodeTransportsErr(t *testing.T) {
 	ctx, rm, _, m, done := newTestRegistry(t, false)
 	defer done()
@@ -19,6 +19,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
@@ -27,6 +28,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
 )
 
 const RETRY_TIMEOUT = 5 * time.Second
@@ -67,6 +69,7 @@ type StateDistributionPersisted struct {
 	NullifierAlgorithm    *string            `json:"nullifierAlgorithm,omitempty"`
 	NullifierVerifierType *string            `json:"nullifierVerifierType,omitempty"`
 	NullifierPayloadType  *string            `json:"nullifierPayloadType,omitempty"`
+	HeldForTransaction    *uuid.UUID         `json:"heldForTransaction,omitempty"`
 }
 
 /*
@@ -82,6 +85,8 @@ type StateDistributer interface {
 	Stop(ctx context.Context)
 	BuildNullifiers(ctx context.Context, stateDistributions []*components.StateDistribution) ([]*components.NullifierUpsert, error)
 	DistributeStates(ctx context.Context, stateDistributions []*components.StateDistribution)
+	ReleaseHeldDistributions(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) (func(), error)
+	DiscardHeldDistributions(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) error
 }
 
 type stateDistributer struct {
@@ -118,67 +123,7 @@ func (sd *stateDistributer) Start(bgCtx context.Context) error {
 	// TODO: This needs to be a worker per-peer - probably a whole state distributor per peer that can be swapped in/out.
 	// Currently it only runs on startup, and pushes all state distributions from before the startup time into the distributor.
 	startTime := tktypes.TimestampNow()
-	go func() {
-		page := 0
-		dispatched := 0
-		var lastEntry *StateDistributionPersisted
-		finished := false
-		for !finished {
-			err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
-				page++
-				var stateDistributions []*StateDistributionPersisted
-				query := sd.persistence.DB().Table("state_distributions").
-					Select("state_distributions.*").
-					Joins("LEFT JOIN state_distribution_acknowledgments ON state_distributions.id = state_distribution_acknowledgments.state_distribution").
-					Where("state_distribution_acknowledgments.id IS NULL").
-					Where("created < ?", startTime).
-					Order("created").
-					Limit(100)
-				if lastEntry != nil {
-					query = query.Where("created > ?", lastEntry.Created)
-				}
-				err = query.Find(&stateDistributions).Error
-
-				if err != nil {
-					log.L(ctx).Errorf("Error getting state distributions: %s", err)
-					return true, err
-				}
-
-				log.L(ctx).Infof("stateDistributer loaded %d state distributions on startup (page=%d)", len(stateDistributions), page)
-
-				for _, stateDistribution := range stateDistributions {
-					state, err := sd.stateManager.GetState(ctx, sd.persistence.DB(), /* no TX for now */
-						stateDistribution.DomainName, stateDistribution.ContractAddress, stateDistribution.StateID, true, false)
-					if err != nil {
-						log.L(ctx).Errorf("Error getting state: %s", err)
-						continue
-					}
-
-					sd.inputChan <- &components.StateDistribution{
-						ID:                    stateDistribution.ID,
-						StateID:               stateDistribution.StateID.String(),
-						IdentityLocator:       stateDistribution.IdentityLocator,
-						Domain:                stateDistribution.DomainName,
-						ContractAddress:       stateDistribution.ContractAddress.String(),
-						SchemaID:              state.Schema.String(),
-						StateDataJson:         string(state.Data),
-						NullifierAlgorithm:    stateDistribution.NullifierAlgorithm,
-						NullifierVerifierType: stateDistribution.NullifierVerifierType,
-						NullifierPayloadType:  stateDistribution.NullifierPayloadType,
-					}
-
-					dispatched++
-					lastEntry = stateDistribution
-				}
-				finished = (len(stateDistributions) == 0)
-				return false, nil
-			})
-			if err != nil {
-				log.L(ctx).Warnf("exiting before sending all recovered state distributions")
-			}
-		}
-		log.L(ctx).Infof("stateDistributer finished startup recovery after dispatching %d distributions", dispatched)
-	}()
+	go sd.recoverPendingDistributions(ctx, startTime)
 
 	go func() {
 		log.L(ctx).Info("stateDistributer:Loop starting loop")
@@ -221,6 +166,130 @@ func (sd *stateDistributer) Start(bgCtx context.Context) error {
 	return nil
 }
 
+// Distributions are persisted in the same DB transaction that dispatches the private transaction,
+// and stay pending until an acknowledgement is persisted. So if we restart mid-distribution we
+// reload everything that was unacknowledged before we started, and resume sending it.
+// Those still held until their transaction is confirmed are left for ReleaseHeldDistributions.
+func (sd *stateDistributer) recoverPendingDistributions(ctx context.Context, startTime tktypes.Timestamp) {
+	page := 0
+	dispatched := 0
+	var lastEntry *StateDistributionPersisted
+	finished := false
+	for !finished {
+		err := sd.retry.Do(ctx, func(attempt int) (retryable bool, err error) {
+			page++
+			var stateDistributions []*StateDistributionPersisted
+			query := sd.persistence.DB().Table("state_distributions").
+				Select("state_distributions.*").
+				Joins("LEFT JOIN state_distribution_acknowledgments ON state_distributions.id = state_distribution_acknowledgments.state_distribution").
+				Where("state_distribution_acknowledgments.id IS NULL").
+				Where("state_distributions.held_for_transaction IS NULL").
+				Where("created < ?", startTime).
+				Order("created").
+				Order("id").
+				Limit(100)
+			if lastEntry != nil {
+				// Page on created+id, as all the distributions for a transaction are written with the same timestamp
+				query = query.Where("(created > ? OR (created = ? AND state_distributions.id > ?))", lastEntry.Created, lastEntry.Created, lastEntry.ID)
+			}
+			err = query.Find(&stateDistributions).Error
+
+			if err != nil {
+				log.L(ctx).Errorf("Error getting state distributions: %s", err)
+				return true, err
+			}
+
+			log.L(ctx).Infof("stateDistributer loaded %d state distributions on startup (page=%d)", len(stateDistributions), page)
+
+			for _, stateDistribution := range stateDistributions {
+				// always move past this entry, even if we fail to load the state, so we cannot loop forever
+				lastEntry = stateDistribution
+				distribution, err := sd.loadDistribution(ctx, stateDistribution)
+				if err != nil {
+					continue
+				}
+				sd.inputChan <- distribution
+
+				dispatched++
+			}
+			finished = (len(stateDistributions) == 0)
+			return false, nil
+		})
+		if err != nil {
+			log.L(ctx).Warnf("exiting before sending all recovered state distributions")
+			return
+		}
+	}
+	log.L(ctx).Infof("stateDistributer finished startup recovery after dispatching %d distributions", dispatched)
+}
+
+func (sd *stateDistributer) loadDistribution(ctx context.Context, stateDistribution *StateDistributionPersisted) (*components.StateDistribution, error) {
+	state, err := sd.stateManager.GetState(ctx, sd.persistence.DB(), /* no TX for now */
+		stateDistribution.DomainName, stateDistribution.ContractAddress, stateDistribution.StateID, true, false)
+	if err != nil {
+		log.L(ctx).Errorf("Error getting state: %s", err)
+		return nil, err
+	}
+	return &components.StateDistribution{
+		ID:                    stateDistribution.ID,
+		StateID:               stateDistribution.StateID.String(),
+		IdentityLocator:       stateDistribution.IdentityLocator,
+		Domain:                stateDistribution.DomainName,
+		ContractAddress:       stateDistribution.ContractAddress.String(),
+		SchemaID:              state.Schema.String(),
+		StateDataJson:         string(state.Data),
+		NullifierAlgorithm:    stateDistribution.NullifierAlgorithm,
+		NullifierVerifierType: stateDistribution.NullifierVerifierType,
+		NullifierPayloadType:  stateDistribution.NullifierPayloadType,
+	}, nil
+}
+
+// ReleaseHeldDistributions is called in the DB transaction that records the confirmation of the given private
+// transactions. The distributions held for them are no longer held from the point that commits, so would be
+// recovered on a restart, and the returned function must be called after the commit to send them.
+func (sd *stateDistributer) ReleaseHeldDistributions(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) (func(), error) {
+	var held []*StateDistributionPersisted
+	err := dbTX.
+		WithContext(ctx).
+		Table("state_distributions").
+		Where("held_for_transaction IN ?", txIDs).
+		Find(&held).
+		Error
+	if err == nil && len(held) > 0 {
+		err = dbTX.
+			WithContext(ctx).
+			Table("state_distributions").
+			Where("held_for_transaction IN ?", txIDs).
+			Update("held_for_transaction", nil).
+			Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		log.L(ctx).Infof("Releasing %d state distributions held until confirmation", len(held))
+		distributions := make([]*components.StateDistribution, 0, len(held))
+		for _, stateDistribution := range held {
+			// a failure here leaves the distribution to be recovered on restart
+			if distribution, err := sd.loadDistribution(ctx, stateDistribution); err == nil {
+				distributions = append(distributions, distribution)
+			}
+		}
+		sd.DistributeStates(ctx, distributions)
+	}, nil
+}
+
+// DiscardHeldDistributions is called in the DB transaction that records the on-chain failure of the given
+// private transactions, as the states held for them must never be sent
+func (sd *stateDistributer) DiscardHeldDistributions(ctx context.Context, dbTX *gorm.DB, txIDs []uuid.UUID) error {
+	return dbTX.
+		WithContext(ctx).
+		Table("state_distributions").
+		Where("held_for_transaction IN ?", txIDs).
+		Delete(&StateDistributionPersisted{}).
+		Error
+}
+
 func (sd *stateDistributer) buildNullifier(ctx context.Context, krc components.KeyResolutionContextLazyDB, s *components.StateDistribution) (*components.NullifierUpsert, error) {
 	// We need to call the signing engine with the local identity to build the nullifier
 	log.L(ctx).Infof("Generating nullifier for state %s on node %s (algorithm=%s,verifierType=%s,payloadType=%s)",
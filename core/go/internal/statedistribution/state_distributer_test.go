@@ -20,17 +20,22 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
+	pb "github.com/kaleido-io/paladin/core/pkg/proto/engine"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
 )
 
 type mockComponents struct {
@@ -173,3 +178,150 @@ func TestBuildNullifiersNotLocal(t *testing.T) {
 	assert.Regexp(t, "PD012400", err)
 
 }
+
+func TestRecoverPendingDistributionsOnRestart(t *testing.T) {
+
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	// Simulate a restart where we had persisted four distributions, but only the first
+	// was acknowledged before we stopped. Three share a timestamp as they were written
+	// in a single DB transaction, and we must page across them without skipping any.
+	contractAddr := tktypes.RandAddress()
+	created1 := tktypes.TimestampNow() - 1000
+	created2 := created1 + 1
+	pending := []*StateDistributionPersisted{
+		{Created: created1, ID: "dist2", StateID: tktypes.RandBytes(32), IdentityLocator: "bob@node2", DomainName: "domain1", ContractAddress: *contractAddr},
+		{Created: created1, ID: "dist3", StateID: tktypes.RandBytes(32), IdentityLocator: "carol@node3", DomainName: "domain1", ContractAddress: *contractAddr},
+		{Created: created2, ID: "dist4", StateID: tktypes.RandBytes(32), IdentityLocator: "bob@node2", DomainName: "domain1", ContractAddress: *contractAddr},
+	}
+	distRows := func(dists ...*StateDistributionPersisted) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"created", "id", "state_id", "identity_locator", "domain_name", "contract_address"})
+		for _, d := range dists {
+			rows.AddRow(d.Created, d.ID, d.StateID, d.IdentityLocator, d.DomainName, d.ContractAddress)
+		}
+		return rows
+	}
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions.*held_for_transaction IS NULL").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnError(fmt.Errorf("pop")) // retried
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnRows(distRows(pending[0], pending[1]))
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions").
+		WithArgs(sqlmock.AnyArg(), created1, created1, "dist3", 100).
+		WillReturnRows(distRows(pending[2]))
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions").
+		WithArgs(sqlmock.AnyArg(), created2, created2, "dist4", 100).
+		WillReturnRows(distRows())
+
+	schemaID := tktypes.Bytes32(tktypes.RandBytes(32))
+	mc.stateManager.On("GetState", mock.Anything, mock.Anything, "domain1", *contractAddr, mock.Anything, true, false).
+		Return(func(_ context.Context, _ *gorm.DB, _ string, _ tktypes.EthAddress, stateID tktypes.HexBytes, _, _ bool) (*pldapi.State, error) {
+			return &pldapi.State{StateBase: pldapi.StateBase{ID: stateID, Schema: schemaID, Data: tktypes.RawJSON(`{"some":"data"}`)}}, nil
+		})
+
+	sent := make(chan *pb.StateProducedEvent, len(pending))
+	mc.transportManager.On("RegisterClient", mock.Anything, sd).Return(nil)
+	mc.transportManager.On("Send", mock.Anything, mock.Anything).Return(func(_ context.Context, msg *components.TransportMessage) error {
+		var spe pb.StateProducedEvent
+		err := proto.Unmarshal(msg.Payload, &spe)
+		require.NoError(t, err)
+		node, _ := tktypes.PrivateIdentityLocator(spe.Party).Node(ctx, false)
+		assert.Equal(t, node, msg.Node)
+		sent <- &spe
+		return nil
+	})
+
+	sd.retry = retry.NewRetryIndefinite(&pldconf.RetryConfig{InitialDelay: confutil.P("1ms")})
+	err := sd.Start(ctx)
+	require.NoError(t, err)
+	defer sd.Stop(ctx)
+
+	for _, expected := range pending {
+		spe := <-sent
+		assert.Equal(t, expected.ID, spe.DistributionId)
+		assert.Equal(t, expected.IdentityLocator, spe.Party)
+		assert.Equal(t, expected.StateID.String(), spe.StateId)
+		assert.Equal(t, schemaID.String(), spe.SchemaId)
+		assert.JSONEq(t, `{"some":"data"}`, spe.StateDataJson)
+	}
+
+}
+
+func TestReleaseHeldDistributions(t *testing.T) {
+
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	txID := uuid.New()
+	contractAddr := tktypes.RandAddress()
+	stateID := tktypes.HexBytes(tktypes.RandBytes(32))
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions.*held_for_transaction").
+		WithArgs(txID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state_id", "identity_locator", "domain_name", "contract_address", "held_for_transaction"}).
+			AddRow("dist1", stateID, "bob@node2", "domain1", contractAddr, txID))
+	mc.db.Mock.ExpectExec("UPDATE.*state_distributions.*held_for_transaction").
+		WithArgs(nil, txID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	schemaID := tktypes.Bytes32(tktypes.RandBytes(32))
+	mc.stateManager.On("GetState", mock.Anything, mock.Anything, "domain1", *contractAddr, stateID, true, false).
+		Return(&pldapi.State{StateBase: pldapi.StateBase{ID: stateID, Schema: schemaID, Data: tktypes.RawJSON(`{"some":"data"}`)}}, nil)
+
+	postCommit, err := sd.ReleaseHeldDistributions(ctx, mc.db.P.DB(), []uuid.UUID{txID})
+	require.NoError(t, err)
+	require.NoError(t, mc.db.Mock.ExpectationsWereMet())
+
+	// nothing is sent until the release has committed
+	released := make(chan *components.StateDistribution, 1)
+	go func() {
+		released <- <-sd.inputChan
+	}()
+	postCommit()
+	distribution := <-released
+	assert.Equal(t, "dist1", distribution.ID)
+	assert.Equal(t, stateID.String(), distribution.StateID)
+	assert.Equal(t, schemaID.String(), distribution.SchemaID)
+	assert.Nil(t, distribution.HeldForTransaction)
+
+}
+
+func TestReleaseHeldDistributionsNoneHeld(t *testing.T) {
+
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	postCommit, err := sd.ReleaseHeldDistributions(ctx, mc.db.P.DB(), []uuid.UUID{uuid.New()})
+	require.NoError(t, err)
+	postCommit()
+	require.NoError(t, mc.db.Mock.ExpectationsWereMet())
+
+}
+
+func TestReleaseHeldDistributionsFail(t *testing.T) {
+
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	mc.db.Mock.ExpectQuery("SELECT.*state_distributions").
+		WillReturnError(fmt.Errorf("pop"))
+
+	_, err := sd.ReleaseHeldDistributions(ctx, mc.db.P.DB(), []uuid.UUID{uuid.New()})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestDiscardHeldDistributions(t *testing.T) {
+
+	ctx, mc, sd := newTestStateDistributor(t)
+
+	txID := uuid.New()
+	mc.db.Mock.ExpectExec("DELETE.*state_distributions.*held_for_transaction").
+		WithArgs(txID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := sd.DiscardHeldDistributions(ctx, mc.db.P.DB(), []uuid.UUID{txID})
+	require.NoError(t, err)
+	require.NoError(t, mc.db.Mock.ExpectationsWereMet())
+
+}
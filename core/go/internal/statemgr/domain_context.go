@@ -450,6 +450,54 @@ func (dc *domainContext) ResetTransactions(transactions ...uuid.UUID) {
 	dc.txLocks = newLocks
 }
 
+func (dc *domainContext) DiscardTransactions(transactions ...uuid.UUID) {
+	dc.stateLock.Lock()
+	defer dc.stateLock.Unlock()
+
+	discarded := make(map[uuid.UUID]bool, len(transactions))
+	for _, tx := range transactions {
+		discarded[tx] = true
+	}
+	createdBy := make(map[string]bool)
+	newLocks := make([]*pldapi.StateLock, 0, len(dc.txLocks))
+	for _, lock := range dc.txLocks {
+		if !discarded[lock.Transaction] {
+			newLocks = append(newLocks, lock)
+			continue
+		}
+		if lock.Type.V() == pldapi.StateLockTypeCreate {
+			createdBy[lock.State.String()] = true
+			delete(dc.creatingStates, lock.State.String())
+		}
+	}
+	dc.txLocks = newLocks
+
+	// A flush that is still in progress is left alone, but the writes of a failed one go back to
+	// the front of the un-flushed set - without those of the discarded transactions
+	writes := []*pendingStateWrites{dc.unFlushed}
+	if dc.flushing != nil && dc.flushing.flushResult != nil {
+		writes = []*pendingStateWrites{dc.flushing, dc.unFlushed}
+		dc.flushing = nil
+	}
+	unFlushed := dc.newPendingStateWrites()
+	for _, w := range writes {
+		if w == nil {
+			continue
+		}
+		for _, s := range w.states {
+			if !createdBy[s.ID.String()] {
+				unFlushed.states = append(unFlushed.states, s)
+			}
+		}
+		for _, n := range w.stateNullifiers {
+			if !createdBy[n.State.String()] {
+				unFlushed.stateNullifiers = append(unFlushed.stateNullifiers, n)
+			}
+		}
+	}
+	dc.unFlushed = unFlushed
+}
+
 func (dc *domainContext) StateLocksByTransaction() map[uuid.UUID][]pldapi.StateLock {
 	dc.stateLock.Lock()
 	defer dc.stateLock.Unlock()
@@ -536,10 +584,16 @@ func (dc *domainContext) Flush(dbTX *gorm.DB) (postDBTx func(error), err error)
 	}
 
 	// Return a callback to the owner of the DB Transaction, so they can tell us if the commit succeeded
+	flushing := dc.flushing
 	return func(commitError error) {
 		dc.stateLock.Lock()
 		defer dc.stateLock.Unlock()
 
+		if dc.flushing != flushing {
+			// The context has been reset since this flush started, so has no record of it
+			log.L(ctx).Debugf("flush completed after reset of domain context err=%v", commitError)
+			return
+		}
 		if commitError != nil {
 			// The error sits on the context until a Reset() is called
 			dc.flushing.setError(commitError)
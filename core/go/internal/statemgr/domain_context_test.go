@@ -32,6 +32,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 const fakeCoinABI = `{
@@ -489,6 +490,18 @@ func checkPostCommit(t *testing.T, ss *stateManager, txID uuid.UUID, expectedSpe
 		require.Contains(t, infoIDs, sID.String())
 	}
 
+	// The consumed and produced references carry the schema of every state
+	checkRefs := func(refs []*pldapi.StateReference, states []*pldapi.StateBase) {
+		require.Len(t, refs, len(states))
+		for i, ref := range refs {
+			require.Equal(t, states[i].ID, ref.ID)
+			require.NotNil(t, ref.Schema)
+			require.Equal(t, states[i].Schema, *ref.Schema)
+		}
+	}
+	checkRefs(txStates.Consumed, txStates.Spent)
+	checkRefs(txStates.Produced, txStates.Confirmed)
+
 }
 
 func TestStateContextMintSpendWithNullifier(t *testing.T) {
@@ -713,6 +726,111 @@ func TestDomainContextFlushErrorCapture(t *testing.T) {
 
 }
 
+func TestDomainContextFlushPartialFailure(t *testing.T) {
+
+	ctx, ss, _, done := newDBTestStateManager(t)
+	defer done()
+
+	schemas, err := ss.EnsureABISchemas(ctx, ss.p.DB(), "domain1", []*abi.Parameter{testABIParam(t, fakeCoinABI)})
+	require.NoError(t, err)
+	schemaID := schemas[0].ID()
+
+	contractAddress, dc := newTestDomainContext(t, ctx, ss, "domain1", false)
+	defer dc.Close()
+
+	tx1 := uuid.New()
+	data1 := `{"amount": 100, "owner": "0xf7b1c69F5690993F2C8ecE56cc89D42b1e737180"}`
+	states, err := dc.UpsertStates(ss.p.DB(), genWidget(t, schemaID, &tx1, data1), genWidget(t, schemaID, &tx1, data1))
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+
+	// The states are written by the flush, but something else in the same DB transaction fails
+	var postDBTx func(error)
+	err = ss.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		postDBTx, err = dc.Flush(dbTX)
+		require.NoError(t, err)
+		return fmt.Errorf("pop")
+	})
+	require.Regexp(t, "pop", err)
+	postDBTx(err)
+
+	// None of the states were committed
+	dbStates, err := ss.FindContractStates(ctx, ss.p.DB(), "domain1", contractAddress, schemaID, query.NewQueryBuilder().Query(), pldapi.StateStatusAll)
+	require.NoError(t, err)
+	assert.Empty(t, dbStates)
+
+	// The context cannot be used until it is reset
+	_, _, err = dc.FindAvailableStates(ss.p.DB(), schemaID, query.NewQueryBuilder().Query())
+	assert.Regexp(t, "PD010119.*pop", err)
+
+	// After the reset, the context holds none of the states that failed to flush
+	dc.Reset()
+	_, available, err := dc.FindAvailableStates(ss.p.DB(), schemaID, query.NewQueryBuilder().Query())
+	require.NoError(t, err)
+	assert.Empty(t, available)
+	assert.Empty(t, dc.StateLocksByTransaction())
+
+	// A flush that completes after the context has been reset is ignored
+	_, err = dc.UpsertStates(ss.p.DB(), genWidget(t, schemaID, &tx1, data1))
+	require.NoError(t, err)
+	postDBTx, err = dc.Flush(ss.p.DB())
+	require.NoError(t, err)
+	dc.Reset()
+	postDBTx(fmt.Errorf("crackle"))
+	syncFlushContext(t, dc)
+
+}
+
+func TestDomainContextDiscardTransactionsAfterFlushFailure(t *testing.T) {
+
+	ctx, ss, _, done := newDBTestStateManager(t)
+	defer done()
+
+	schemas, err := ss.EnsureABISchemas(ctx, ss.p.DB(), "domain1", []*abi.Parameter{testABIParam(t, fakeCoinABI)})
+	require.NoError(t, err)
+	schemaID := schemas[0].ID()
+
+	contractAddress, dc := newTestDomainContext(t, ctx, ss, "domain1", false)
+	defer dc.Close()
+
+	tx1 := uuid.New()
+	tx2 := uuid.New()
+	data1 := fmt.Sprintf(`{"amount": 100, "owner": "0xf7b1c69F5690993F2C8ecE56cc89D42b1e737180", "salt": "%s"}`, tktypes.RandHex(32))
+	data2 := fmt.Sprintf(`{"amount": 200, "owner": "0xf7b1c69F5690993F2C8ecE56cc89D42b1e737180", "salt": "%s"}`, tktypes.RandHex(32))
+	states1, err := dc.UpsertStates(ss.p.DB(), genWidget(t, schemaID, &tx1, data1))
+	require.NoError(t, err)
+	states2, err := dc.UpsertStates(ss.p.DB(), genWidget(t, schemaID, &tx2, data2))
+	require.NoError(t, err)
+
+	// The states are written by the flush, but something else in the same DB transaction fails
+	var postDBTx func(error)
+	err = ss.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		postDBTx, err = dc.Flush(dbTX)
+		require.NoError(t, err)
+		return fmt.Errorf("pop")
+	})
+	require.Regexp(t, "pop", err)
+	postDBTx(err)
+
+	// Discarding the first transaction clears the failure, and keeps only the state of the second
+	dc.DiscardTransactions(tx1)
+	_, available, err := dc.FindAvailableStates(ss.p.DB(), schemaID, query.NewQueryBuilder().Query())
+	require.NoError(t, err)
+	require.Len(t, available, 1)
+	assert.Equal(t, states2[0].ID, available[0].ID)
+	locks := dc.StateLocksByTransaction()
+	assert.Empty(t, locks[tx1])
+	assert.Len(t, locks[tx2], 1)
+
+	// ... which is written by the next flush
+	syncFlushContext(t, dc)
+	dbStates, err := ss.FindContractStates(ctx, ss.p.DB(), "domain1", contractAddress, schemaID, query.NewQueryBuilder().Query(), pldapi.StateStatusAll)
+	require.NoError(t, err)
+	require.Len(t, dbStates, 1)
+	assert.Equal(t, states2[0].ID, dbStates[0].ID)
+	assert.NotEqual(t, states1[0].ID, dbStates[0].ID)
+}
+
 func TestDCMergeUnFlushedWhileFlushing(t *testing.T) {
 
 	ctx, ss, _, _, done := newDBMockStateManager(t)
@@ -0,0 +1,221 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statemgr
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+)
+
+const migrateStatesBatchSize = 100
+
+// Migrations are registered by the domain manager each time a domain is initialized,
+// replacing any previous set registered for that domain.
+func (ss *stateManager) RegisterSchemaMigrations(ctx context.Context, domainName string, migrations []*components.SchemaMigration) error {
+	domainMigrations := make(map[tktypes.Bytes32]*components.SchemaMigration, len(migrations))
+	for _, m := range migrations {
+		if m.FromSchemaID == m.ToSchemaID || m.Migrate == nil {
+			return i18n.NewError(ctx, msgs.MsgStateMigrationInvalid, m.FromSchemaID, m.ToSchemaID, domainName)
+		}
+		domainMigrations[m.FromSchemaID] = m
+	}
+
+	// Migrations can be chained through multiple versions of a schema, but must always end on a current schema
+	for fromSchemaID := range domainMigrations {
+		visited := map[tktypes.Bytes32]bool{}
+		for m := domainMigrations[fromSchemaID]; m != nil; m = domainMigrations[m.ToSchemaID] {
+			if visited[m.FromSchemaID] {
+				return i18n.NewError(ctx, msgs.MsgStateMigrationCycle, domainName, m.FromSchemaID)
+			}
+			visited[m.FromSchemaID] = true
+		}
+	}
+
+	ss.migrationLock.Lock()
+	defer ss.migrationLock.Unlock()
+	ss.schemaMigrations[domainName] = domainMigrations
+	return nil
+}
+
+func (ss *stateManager) getSchemaMigration(domainName string, schemaID tktypes.Bytes32) *components.SchemaMigration {
+	ss.migrationLock.RLock()
+	defer ss.migrationLock.RUnlock()
+	return ss.schemaMigrations[domainName][schemaID]
+}
+
+func (ss *stateManager) supersededSchemaIDs(domainName string) []tktypes.Bytes32 {
+	ss.migrationLock.RLock()
+	defer ss.migrationLock.RUnlock()
+	schemaIDs := make([]tktypes.Bytes32, 0, len(ss.schemaMigrations[domainName]))
+	for schemaID := range ss.schemaMigrations[domainName] {
+		schemaIDs = append(schemaIDs, schemaID)
+	}
+	return schemaIDs
+}
+
+// The superseded schemas whose states end up on the given schema once migrated
+func (ss *stateManager) schemaIDsMigratingTo(domainName string, toSchemaID tktypes.Bytes32) []tktypes.Bytes32 {
+	ss.migrationLock.RLock()
+	defer ss.migrationLock.RUnlock()
+	domainMigrations := ss.schemaMigrations[domainName]
+	var schemaIDs []tktypes.Bytes32
+	for fromSchemaID, m := range domainMigrations {
+		finalSchemaID := m.ToSchemaID
+		for next := domainMigrations[finalSchemaID]; next != nil; next = domainMigrations[finalSchemaID] {
+			finalSchemaID = next.ToSchemaID
+		}
+		if finalSchemaID == toSchemaID {
+			schemaIDs = append(schemaIDs, fromSchemaID)
+		}
+	}
+	return schemaIDs
+}
+
+// Migrates the supplied state in-place, if it is stored against a superseded schema.
+// The state retains its ID, but the data and labels are re-written for the current version of the schema.
+func (ss *stateManager) migrateState(ctx context.Context, dbTX *gorm.DB, state *pldapi.State) (migrated bool, err error) {
+	m := ss.getSchemaMigration(state.DomainName, state.Schema)
+	if m == nil {
+		return false, nil
+	}
+
+	data := state.Data
+	toSchemaID := state.Schema
+	for ; m != nil; m = ss.getSchemaMigration(state.DomainName, toSchemaID) {
+		log.L(ctx).Infof("Migrating state %s in domain %s from schema %s to schema %s", state.ID, state.DomainName, m.FromSchemaID, m.ToSchemaID)
+		if data, err = m.Migrate(ctx, state.ContractAddress, state.ID, data); err != nil {
+			return false, err
+		}
+		toSchemaID = m.ToSchemaID
+	}
+
+	schema, err := ss.GetSchema(ctx, dbTX, state.DomainName, toSchemaID, true)
+	if err != nil {
+		return false, err
+	}
+
+	// The ID is retained through the migration, so we process the new data as if the domain supplied its own hash
+	s, err := schema.ProcessState(ctx, state.ContractAddress, data, state.ID, true)
+	if err != nil {
+		return false, err
+	}
+	fromSchemaID := state.Schema
+	state.Schema = toSchemaID
+	state.Data = s.Data
+	state.Labels = s.Labels
+	state.Int64Labels = s.Int64Labels
+
+	// Concurrent readers can race to migrate the same state. The update only applies if the state is still
+	// on the schema we read it with, and only the reader that applied it re-writes the labels.
+	err = dbTX.Transaction(func(dbTX *gorm.DB) error {
+		result := dbTX.
+			Table("states").
+			WithContext(ctx).
+			Where("domain_name = ?", state.DomainName).
+			Where("id = ?", state.ID).
+			Where("schema = ?", fromSchemaID).
+			Updates(map[string]any{
+				"schema": state.Schema,
+				"data":   state.Data,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		for _, labelTable := range []string{"state_labels", "state_int64_labels"} {
+			err := dbTX.
+				Table(labelTable).
+				WithContext(ctx).
+				Where("domain_name = ?", state.DomainName).
+				Where("state = ?", state.ID).
+				Delete(nil).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(state.Labels) > 0 {
+			if err := dbTX.Table("state_labels").WithContext(ctx).Create(state.Labels).Error; err != nil {
+				return err
+			}
+		}
+		if len(state.Int64Labels) > 0 {
+			return dbTX.Table("state_int64_labels").WithContext(ctx).Create(state.Int64Labels).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (ss *stateManager) MigrateStates(ctx context.Context, dbTX *gorm.DB, domainName string) (int, error) {
+	return ss.migrateStates(ctx, dbTX, domainName, nil, ss.supersededSchemaIDs(domainName))
+}
+
+// Queries are against a single schema, so before a query runs any states that are still stored against
+// a schema that migrates to the queried schema must be migrated, otherwise they would be missed.
+func (ss *stateManager) migrateStatesTo(ctx context.Context, dbTX *gorm.DB, domainName string, contractAddress *tktypes.EthAddress, toSchemaID tktypes.Bytes32) error {
+	_, err := ss.migrateStates(ctx, dbTX, domainName, contractAddress, ss.schemaIDsMigratingTo(domainName, toSchemaID))
+	return err
+}
+
+func (ss *stateManager) migrateStates(ctx context.Context, dbTX *gorm.DB, domainName string, contractAddress *tktypes.EthAddress, fromSchemaIDs []tktypes.Bytes32) (int, error) {
+	if len(fromSchemaIDs) == 0 {
+		return 0, nil
+	}
+
+	// Each migrated state drops out of the query, so we just keep querying until there are none left
+	count := 0
+	for {
+		var states []*pldapi.State
+		q := dbTX.
+			Table("states").
+			WithContext(ctx).
+			Where("domain_name = ?", domainName).
+			Where("schema IN (?)", fromSchemaIDs)
+		if contractAddress != nil {
+			q = q.Where("contract_address = ?", contractAddress)
+		}
+		err := q.
+			Limit(migrateStatesBatchSize).
+			Find(&states).
+			Error
+		if err != nil {
+			return count, err
+		}
+		for _, s := range states {
+			if _, err := ss.migrateState(ctx, dbTX, s); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(states) < migrateStatesBatchSize {
+			if count > 0 {
+				log.L(ctx).Infof("Migrated %d states in domain %s", count, domainName)
+			}
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statemgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The second version of the fake coin adds a "locked" field
+const fakeCoinABIv2 = `{
+	"type": "tuple",
+	"internalType": "struct FakeCoin",
+	"components": [
+		{
+			"name": "salt",
+			"type": "bytes32"
+		},
+		{
+			"name": "owner",
+			"type": "address",
+			"indexed": true
+		},
+		{
+			"name": "amount",
+			"type": "uint256",
+			"indexed": true
+		},
+		{
+			"name": "locked",
+			"type": "bool",
+			"indexed": true
+		}
+	]
+}`
+
+func addLockedField(ctx context.Context, contractAddress tktypes.EthAddress, stateID tktypes.HexBytes, data tktypes.RawJSON) (tktypes.RawJSON, error) {
+	var coin map[string]any
+	if err := json.Unmarshal(data, &coin); err != nil {
+		return nil, err
+	}
+	coin["locked"] = false
+	return tktypes.JSONString(coin), nil
+}
+
+func TestMigrateStatesFromOldToNewSchema(t *testing.T) {
+	ctx, ss, m, done := newDBTestStateManager(t)
+	defer done()
+
+	_ = mockDomain(t, m, "domain1", false)
+
+	schemas, err := ss.EnsureABISchemas(ctx, ss.p.DB(), "domain1", []*abi.Parameter{
+		testABIParam(t, fakeCoinABI),
+		testABIParam(t, fakeCoinABIv2),
+	})
+	require.NoError(t, err)
+	schemaV1, schemaV2 := schemas[0].ID(), schemas[1].ID()
+
+	contractAddress := tktypes.RandAddress()
+	salts := make([]string, 3)
+	upserts := make([]*components.StateUpsertOutsideContext, len(salts))
+	for i := range upserts {
+		salts[i] = tktypes.RandHex(32)
+		upserts[i] = &components.StateUpsertOutsideContext{
+			SchemaID:        schemaV1,
+			ContractAddress: *contractAddress,
+			Data: tktypes.RawJSON(fmt.Sprintf(
+				`{"amount": %d, "owner": "0x615dD09124271D8008225054d85Ffe720E7a447A", "salt": "0x%s"}`,
+				i+1, salts[i])),
+		}
+	}
+	oldStates, err := ss.WritePreVerifiedStates(ctx, ss.p.DB(), "domain1", upserts)
+	require.NoError(t, err)
+
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemaV1, ToSchemaID: schemaV2, Migrate: addLockedField},
+	})
+	require.NoError(t, err)
+
+	var unlocked *query.QueryJSON
+	err = json.Unmarshal([]byte(`{"eq":[{"field":"locked","value":false}]}`), &unlocked)
+	require.NoError(t, err)
+	findStates := func(schemaID tktypes.Bytes32) int {
+		states, err := ss.FindContractStates(ctx, ss.p.DB(), "domain1", *contractAddress, schemaID, &query.QueryJSON{}, "all")
+		require.NoError(t, err)
+		return len(states)
+	}
+
+	// Reading the first state migrates it lazily, retaining the ID
+	state, err := ss.GetState(ctx, ss.p.DB(), "domain1", *contractAddress, oldStates[0].ID, true, true)
+	require.NoError(t, err)
+	assert.Equal(t, oldStates[0].ID, state.ID)
+	assert.Equal(t, schemaV2, state.Schema)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"amount": "1",
+		"owner": "0x615dd09124271d8008225054d85ffe720e7a447a",
+		"salt": "0x%s",
+		"locked": false
+	}`, salts[0]), state.Data.String())
+	assert.Len(t, state.Labels, 2)
+	assert.Len(t, state.Int64Labels, 1)
+
+	assert.Equal(t, 2, findStates(schemaV1))
+
+	// Reading again finds it already migrated
+	state, err = ss.GetState(ctx, ss.p.DB(), "domain1", *contractAddress, oldStates[0].ID, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, schemaV2, state.Schema)
+	assert.Empty(t, state.Labels)
+
+	// A reader holding a copy of a state from before it was migrated does not migrate it again
+	staleState := *oldStates[1]
+	staleState.Labels, staleState.Int64Labels = nil, nil
+	_, err = ss.GetState(ctx, ss.p.DB(), "domain1", *contractAddress, oldStates[1].ID, true, true)
+	require.NoError(t, err)
+	migrated, err := ss.migrateState(ctx, ss.p.DB(), &staleState)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, schemaV2, staleState.Schema)
+	assert.Equal(t, 1, findStates(schemaV1))
+
+	// Querying the new schema migrates the rest, so the migrated labels are queryable
+	states, err := ss.FindContractStates(ctx, ss.p.DB(), "domain1", *contractAddress, schemaV2, unlocked, "all")
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+	assert.Equal(t, 0, findStates(schemaV1))
+	assert.Equal(t, 3, findStates(schemaV2))
+
+	// Nothing left to do
+	count, err := ss.MigrateStates(ctx, ss.p.DB(), "domain1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestMigrateStatesViaRPC(t *testing.T) {
+	ctx, ss, c, m, done := newTestRPCServer(t)
+	defer done()
+
+	_ = mockDomain(t, m, "domain1", false)
+
+	schemas, err := ss.EnsureABISchemas(ctx, ss.p.DB(), "domain1", []*abi.Parameter{
+		testABIParam(t, fakeCoinABI),
+		testABIParam(t, fakeCoinABIv2),
+	})
+	require.NoError(t, err)
+	_, err = ss.WritePreVerifiedStates(ctx, ss.p.DB(), "domain1", []*components.StateUpsertOutsideContext{
+		{
+			SchemaID:        schemas[0].ID(),
+			ContractAddress: *tktypes.RandAddress(),
+			Data:            tktypes.RawJSON(fmt.Sprintf(`{"amount": 1, "owner": "0x615dD09124271D8008225054d85Ffe720E7a447A", "salt": "%s"}`, tktypes.RandHex(32))),
+		},
+	})
+	require.NoError(t, err)
+
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemas[0].ID(), ToSchemaID: schemas[1].ID(), Migrate: addLockedField},
+	})
+	require.NoError(t, err)
+
+	var migrated int
+	rpcErr := c.CallRPC(ctx, &migrated, "pstate_migrateStates", "domain1")
+	require.NoError(t, rpcErr)
+	assert.Equal(t, 1, migrated)
+}
+
+func TestMigrateStatesNoMigrations(t *testing.T) {
+	ctx, ss, _, _, done := newDBMockStateManager(t)
+	defer done()
+
+	migrated, err := ss.MigrateStates(ctx, ss.p.DB(), "domain1")
+	require.NoError(t, err)
+	assert.Zero(t, migrated)
+}
+
+func TestMigrateStatesQueryFail(t *testing.T) {
+	ctx, ss, db, _, done := newDBMockStateManager(t)
+	defer done()
+
+	schemaV1, schemaV2 := tktypes.Bytes32Keccak([]byte("v1")), tktypes.Bytes32Keccak([]byte("v2"))
+	err := ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemaV1, ToSchemaID: schemaV2, Migrate: addLockedField},
+	})
+	require.NoError(t, err)
+
+	db.ExpectQuery("SELECT.*states").WillReturnError(fmt.Errorf("pop"))
+
+	_, err = ss.MigrateStates(ctx, ss.p.DB(), "domain1")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestMigrateStateFail(t *testing.T) {
+	ctx, ss, m, done := newDBTestStateManager(t)
+	defer done()
+
+	_ = mockDomain(t, m, "domain1", false)
+
+	schemas, err := ss.EnsureABISchemas(ctx, ss.p.DB(), "domain1", []*abi.Parameter{
+		testABIParam(t, fakeCoinABI),
+		testABIParam(t, fakeCoinABIv2),
+	})
+	require.NoError(t, err)
+	contractAddress := tktypes.RandAddress()
+	oldStates, err := ss.WritePreVerifiedStates(ctx, ss.p.DB(), "domain1", []*components.StateUpsertOutsideContext{
+		{
+			SchemaID:        schemas[0].ID(),
+			ContractAddress: *contractAddress,
+			Data:            tktypes.RawJSON(fmt.Sprintf(`{"amount": 1, "owner": "0x615dD09124271D8008225054d85Ffe720E7a447A", "salt": "%s"}`, tktypes.RandHex(32))),
+		},
+	})
+	require.NoError(t, err)
+
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemas[0].ID(), ToSchemaID: schemas[1].ID(), Migrate: func(ctx context.Context, contractAddress tktypes.EthAddress, stateID tktypes.HexBytes, data tktypes.RawJSON) (tktypes.RawJSON, error) {
+			return nil, fmt.Errorf("pop")
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = ss.GetState(ctx, ss.p.DB(), "domain1", *contractAddress, oldStates[0].ID, true, true)
+	assert.Regexp(t, "pop", err)
+
+	_, err = ss.MigrateStates(ctx, ss.p.DB(), "domain1")
+	assert.Regexp(t, "pop", err)
+
+	// Migrating to data that is invalid for the new schema also fails
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemas[0].ID(), ToSchemaID: schemas[1].ID(), Migrate: func(ctx context.Context, contractAddress tktypes.EthAddress, stateID tktypes.HexBytes, data tktypes.RawJSON) (tktypes.RawJSON, error) {
+			return data, nil
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = ss.GetState(ctx, ss.p.DB(), "domain1", *contractAddress, oldStates[0].ID, true, true)
+	assert.Regexp(t, "locked", err)
+}
+
+func TestRegisterSchemaMigrationsInvalid(t *testing.T) {
+	ctx, ss, _, _, done := newDBMockStateManager(t)
+	defer done()
+
+	schemaV1, schemaV2 := tktypes.Bytes32Keccak([]byte("v1")), tktypes.Bytes32Keccak([]byte("v2"))
+
+	err := ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemaV1, ToSchemaID: schemaV1, Migrate: addLockedField},
+	})
+	assert.Regexp(t, "PD010132", err)
+
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemaV1, ToSchemaID: schemaV2},
+	})
+	assert.Regexp(t, "PD010132", err)
+
+	err = ss.RegisterSchemaMigrations(ctx, "domain1", []*components.SchemaMigration{
+		{FromSchemaID: schemaV1, ToSchemaID: schemaV2, Migrate: addLockedField},
+		{FromSchemaID: schemaV2, ToSchemaID: schemaV1, Migrate: addLockedField},
+	})
+	assert.Regexp(t, "PD010133", err)
+}
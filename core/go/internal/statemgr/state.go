@@ -42,6 +42,15 @@ type transactionStateRecord struct {
 	ConfirmedState tktypes.HexBytes `gorm:"column:confirmed_state"`
 }
 
+func (r *transactionStateRecord) stateReference() *pldapi.StateReference {
+	ref := &pldapi.StateReference{ID: r.State}
+	if r.ID != nil {
+		schema := r.Schema
+		ref.Schema = &schema
+	}
+	return ref
+}
+
 func (transactionStateRecord) TableName() string {
 	return "states"
 }
@@ -197,7 +206,17 @@ func (ss *stateManager) GetState(ctx context.Context, dbTX *gorm.DB, domainName
 	if err == nil && len(states) == 0 && failNotFound {
 		return nil, i18n.NewError(ctx, msgs.MsgStateNotFound, stateID)
 	}
-	return states[0], err
+	if err != nil || len(states) == 0 {
+		return nil, err
+	}
+	// States stored against a superseded schema are migrated lazily as they are read
+	state := states[0]
+	if migrated, err := ss.migrateState(ctx, dbTX, state); err != nil {
+		return nil, err
+	} else if migrated && !withLabels {
+		state.Labels, state.Int64Labels = nil, nil
+	}
+	return state, nil
 }
 
 // Built in fields all start with "." as that prevents them
@@ -361,6 +380,10 @@ func (ss *stateManager) findStatesCommon(
 	}
 
 	schema, err = ss.GetSchema(ctx, dbTX, domainName, schemaID, true)
+	if err == nil {
+		// States stored against a superseded schema are migrated lazily as they are read
+		err = ss.migrateStatesTo(ctx, dbTX, domainName, contractAddress, schemaID)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -42,6 +43,8 @@ type stateManager struct {
 	rpcModule         *rpcserver.RPCModule
 	domainContextLock sync.Mutex
 	domainContexts    map[uuid.UUID]*domainContext
+	migrationLock     sync.RWMutex
+	schemaMigrations  map[string]map[tktypes.Bytes32]*components.SchemaMigration
 }
 
 var SchemaCacheDefaults = &pldconf.CacheConfig{
@@ -50,10 +53,11 @@ var SchemaCacheDefaults = &pldconf.CacheConfig{
 
 func NewStateManager(ctx context.Context, conf *pldconf.StateStoreConfig, p persistence.Persistence) components.StateManager {
 	ss := &stateManager{
-		p:              p,
-		conf:           conf,
-		abiSchemaCache: cache.NewCache[string, components.Schema](&conf.SchemaCache, SchemaCacheDefaults),
-		domainContexts: make(map[uuid.UUID]*domainContext),
+		p:                p,
+		conf:             conf,
+		abiSchemaCache:   cache.NewCache[string, components.Schema](&conf.SchemaCache, SchemaCacheDefaults),
+		domainContexts:   make(map[uuid.UUID]*domainContext),
+		schemaMigrations: make(map[string]map[tktypes.Bytes32]*components.SchemaMigration),
 	}
 	ss.bgCtx, ss.cancelCtx = context.WithCancel(ctx)
 	return ss
@@ -156,6 +160,7 @@ func (ss *stateManager) GetTransactionStates(ctx context.Context, dbTX *gorm.DB,
 	for _, s := range records {
 		switch s.RecordType {
 		case "spent":
+			txStates.Consumed = append(txStates.Consumed, s.stateReference())
 			if s.ID == nil {
 				hasUnavailable = true
 				unavailable.Spent = append(unavailable.Spent, s.State)
@@ -170,6 +175,7 @@ func (ss *stateManager) GetTransactionStates(ctx context.Context, dbTX *gorm.DB,
 				txStates.Read = append(txStates.Read, &s.StateBase)
 			}
 		case "confirmed":
+			txStates.Produced = append(txStates.Produced, s.stateReference())
 			if s.ID == nil {
 				hasUnavailable = true
 				unavailable.Confirmed = append(unavailable.Confirmed, s.State)
@@ -24,6 +24,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
 )
 
 func (ss *stateManager) RPCModule() *rpcserver.RPCModule {
@@ -37,7 +38,8 @@ func (ss *stateManager) initRPC() {
 		Add("pstate_queryStates", ss.rpcQueryStates()).
 		Add("pstate_queryContractStates", ss.rpcQueryContractStates()).
 		Add("pstate_queryNullifiers", ss.rpcQueryNullifiers()).
-		Add("pstate_queryContractNullifiers", ss.rpcQueryContractNullifiers())
+		Add("pstate_queryContractNullifiers", ss.rpcQueryContractNullifiers()).
+		Add("pstate_migrateStates", ss.rpcMigrateStates())
 }
 
 func (ss *stateManager) rpcListSchema() rpcserver.RPCHandler {
@@ -115,3 +117,15 @@ func (ss *stateManager) rpcQueryContractNullifiers() rpcserver.RPCHandler {
 		return ss.FindContractNullifiers(ctx, ss.p.DB(), domain, contractAddress, schema, &query, status)
 	})
 }
+
+func (ss *stateManager) rpcMigrateStates() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		domain string,
+	) (migrated int, err error) {
+		err = ss.p.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+			migrated, err = ss.MigrateStates(ctx, dbTX, domain)
+			return err
+		})
+		return migrated, err
+	})
+}
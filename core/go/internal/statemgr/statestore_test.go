@@ -130,6 +130,8 @@ func TestGetTransactionStatesUnavailable(t *testing.T) {
 	require.Equal(t, []tktypes.HexBytes{stateID2}, txStates.Unavailable.Read)
 	require.Equal(t, []tktypes.HexBytes{stateID3}, txStates.Unavailable.Confirmed)
 	require.Equal(t, []tktypes.HexBytes{stateID4}, txStates.Unavailable.Info)
+	require.Equal(t, []*pldapi.StateReference{{ID: stateID1}}, txStates.Consumed)
+	require.Equal(t, []*pldapi.StateReference{{ID: stateID3}}, txStates.Produced)
 }
 
 func TestGetTransactionStatesFail(t *testing.T) {
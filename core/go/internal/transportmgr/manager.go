@@ -18,17 +18,22 @@ package transportmgr
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/filters"
 	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 )
 
@@ -40,6 +45,7 @@ type transportManager struct {
 	conf            *pldconf.TransportManagerConfig
 	localNodeName   string
 	registryManager components.RegistryManager
+	persistence     persistence.Persistence
 
 	transportsByID   map[uuid.UUID]*transport
 	transportsByName map[string]*transport
@@ -47,16 +53,29 @@ type transportManager struct {
 	destinations      map[string]components.TransportClient
 	destinationsFixed bool
 	destinationsMux   sync.RWMutex
+
+	peerInboundConcurrency int
+	inboundQueueTimeout    time.Duration
+	peerInboundSlots       map[string]*peerInboundSlots // only for peers with messages being processed or waiting, protected by peerInboundMux
+	peerInboundMux         sync.Mutex
+}
+
+type peerInboundSlots struct {
+	slots chan struct{}
+	users int // messages holding or waiting for a slot, the entry is removed when this drops to zero
 }
 
 func NewTransportManager(bgCtx context.Context, conf *pldconf.TransportManagerConfig) components.TransportManager {
 	return &transportManager{
-		bgCtx:            bgCtx,
-		conf:             conf,
-		localNodeName:    conf.NodeName,
-		transportsByID:   make(map[uuid.UUID]*transport),
-		transportsByName: make(map[string]*transport),
-		destinations:     make(map[string]components.TransportClient),
+		bgCtx:                  bgCtx,
+		conf:                   conf,
+		localNodeName:          conf.NodeName,
+		transportsByID:         make(map[uuid.UUID]*transport),
+		transportsByName:       make(map[string]*transport),
+		destinations:           make(map[string]components.TransportClient),
+		peerInboundConcurrency: confutil.IntMin(conf.Inbound.PeerConcurrency, 0, *pldconf.TransportManagerDefaults.Inbound.PeerConcurrency),
+		inboundQueueTimeout:    confutil.DurationMin(conf.Inbound.QueueTimeout, 0, *pldconf.TransportManagerDefaults.Inbound.QueueTimeout),
+		peerInboundSlots:       make(map[string]*peerInboundSlots),
 	}
 }
 
@@ -75,6 +94,7 @@ func (tm *transportManager) PostInit(c components.AllComponents) error {
 	// plugin manager starts, and thus before any domain would have started any go-routine
 	// that could have cached a nil value in memory.
 	tm.registryManager = c.RegistryManager()
+	tm.persistence = c.Persistence()
 	return nil
 }
 
@@ -114,6 +134,52 @@ func (tm *transportManager) RegisterClient(ctx context.Context, client component
 
 }
 
+// When configured, each peer node has a fixed number of slots for processing inbound messages
+// concurrently, so a flood from one peer cannot starve the others. When all slots are in use the
+// caller is held until one frees up, pushing back on the transport, and after the queue timeout
+// an error is returned so the caller can move the message to the dead letters.
+func (tm *transportManager) acquireInboundSlot(ctx context.Context, peer string) (release func(), err error) {
+	tm.peerInboundMux.Lock()
+	p := tm.peerInboundSlots[peer]
+	if p == nil {
+		p = &peerInboundSlots{slots: make(chan struct{}, tm.peerInboundConcurrency)}
+		tm.peerInboundSlots[peer] = p
+	}
+	p.users++
+	tm.peerInboundMux.Unlock()
+
+	select {
+	case p.slots <- struct{}{}:
+		return func() { <-p.slots; tm.releaseInboundSlots(peer, p) }, nil
+	default:
+	}
+
+	log.L(ctx).Debugf("All %d inbound slots for peer '%s' are busy", tm.peerInboundConcurrency, peer)
+	timer := time.NewTimer(tm.inboundQueueTimeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+		return func() { <-p.slots; tm.releaseInboundSlots(peer, p) }, nil
+	case <-timer.C:
+		tm.releaseInboundSlots(peer, p)
+		return nil, i18n.NewError(ctx, msgs.MsgTransportInboundPeerBusy, peer, tm.inboundQueueTimeout, tm.peerInboundConcurrency)
+	case <-ctx.Done():
+		tm.releaseInboundSlots(peer, p)
+		return nil, i18n.NewError(ctx, msgs.MsgContextCanceled)
+	}
+}
+
+// Once nothing from a peer is being processed or waiting, its slots are removed, so that we
+// only hold state for peers that are currently active
+func (tm *transportManager) releaseInboundSlots(peer string, p *peerInboundSlots) {
+	tm.peerInboundMux.Lock()
+	defer tm.peerInboundMux.Unlock()
+	p.users--
+	if p.users == 0 {
+		delete(tm.peerInboundSlots, peer)
+	}
+}
+
 func (tm *transportManager) cleanupTransport(t *transport) {
 	// must not hold the transport lock when running this
 	t.close()
@@ -247,13 +313,14 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 		msg.MessageID = uuid.New()
 	}
 	err = transport.send(ctx, &prototk.Message{
-		MessageType:   msg.MessageType,
-		MessageId:     msg.MessageID.String(),
-		CorrelationId: correlID,
-		Component:     msg.Component,
-		Node:          msg.Node,
-		ReplyTo:       msg.ReplyTo,
-		Payload:       msg.Payload,
+		MessageType:     msg.MessageType,
+		MessageId:       msg.MessageID.String(),
+		CorrelationId:   correlID,
+		Component:       msg.Component,
+		Node:            msg.Node,
+		ReplyTo:         msg.ReplyTo,
+		Payload:         msg.Payload,
+		ProtocolVersion: components.TransportProtocolVersion,
 	})
 	if err != nil {
 		return err
@@ -261,3 +328,26 @@ func (tm *transportManager) Send(ctx context.Context, msg *components.TransportM
 
 	return nil
 }
+
+var deadLetterFilters = filters.FieldMap{
+	"id":            filters.UUIDField("id"),
+	"created":       filters.Int64Field("created"),
+	"transport":     filters.StringField("transport"),
+	"node":          filters.StringField("node"),
+	"component":     filters.StringField("component"),
+	"messageType":   filters.StringField("message_type"),
+	"correlationId": filters.UUIDField("correlation_id"),
+	"reason":        filters.StringField("reason"),
+}
+
+func (tm *transportManager) queryDeadLetters(ctx context.Context, jq *query.QueryJSON) ([]*pldapi.TransportDeadLetter, error) {
+	if jq.Limit == nil || *jq.Limit == 0 {
+		return nil, i18n.NewError(ctx, msgs.MsgTransportQueryLimitRequired)
+	}
+	q := filters.BuildGORM(ctx, jq, tm.persistence.DB().WithContext(ctx).Table("transport_dead_letters"), deadLetterFilters)
+	var deadLetters []*pldapi.TransportDeadLetter
+	if err := q.Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
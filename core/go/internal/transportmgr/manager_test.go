@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/core/pkg/persistence"
+	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
@@ -36,6 +39,19 @@ import (
 type mockComponents struct {
 	c               *componentmocks.AllComponents
 	registryManager *componentmocks.RegistryManager
+	db              sqlmock.Sqlmock
+	p               persistence.Persistence // mocked unless the test setup provides a real DB
+	pDone           func()
+}
+
+func withRealDB(t *testing.T) func(mc *mockComponents) components.TransportClient {
+	return func(mc *mockComponents) components.TransportClient {
+		p, pDone, err := persistence.NewUnitTestPersistence(context.Background(), "transportmgr")
+		require.NoError(t, err)
+		mc.p = p
+		mc.pDone = pDone
+		return nil
+	}
 }
 
 func newMockComponents(t *testing.T) *mockComponents {
@@ -59,6 +75,17 @@ func newTestTransportManager(t *testing.T, conf *pldconf.TransportManagerConfig,
 		}
 	}
 
+	if mc.p == nil {
+		mp, err := mockpersistence.NewSQLMockProvider()
+		require.NoError(t, err)
+		mc.p = mp.P
+		mc.db = mp.Mock
+		mc.pDone = func() {
+			require.NoError(t, mp.Mock.ExpectationsWereMet())
+		}
+	}
+	mc.c.On("Persistence").Return(mc.p).Maybe()
+
 	tm := NewTransportManager(ctx, conf)
 
 	ir, err := tm.PreInit(mc.c)
@@ -83,6 +110,7 @@ func newTestTransportManager(t *testing.T, conf *pldconf.TransportManagerConfig,
 		logrus.SetLevel(oldLevel)
 		cancelCtx()
 		tm.Stop()
+		mc.pDone()
 	}
 }
 
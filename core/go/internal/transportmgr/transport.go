@@ -27,10 +27,13 @@ import (
 	"github.com/kaleido-io/paladin/core/internal/msgs"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/retry"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm/clause"
 )
 
 type transport struct {
@@ -140,6 +143,10 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 		return nil, i18n.NewError(ctx, msgs.MsgTransportInvalidNodeReceived, msg.Node, t.tm.localNodeName)
 	}
 
+	if msg.ProtocolVersion != components.TransportProtocolVersion {
+		return nil, i18n.NewError(ctx, msgs.MsgTransportProtocolVersionMismatch, msg.ReplyTo, msg.ProtocolVersion, components.TransportProtocolVersion)
+	}
+
 	msgID, err := uuid.Parse(msg.MessageId)
 	if err != nil {
 		log.L(ctx).Errorf("Invalid messageId from transport: %s", protoToJSON(msg))
@@ -162,7 +169,7 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 		log.L(ctx).Tracef("transport %s message received: %s", t.name, protoToJSON(msg))
 	}
 
-	if err = t.deliverMessage(ctx, msg.Component, &components.TransportMessage{
+	tMsg := &components.TransportMessage{
 		MessageID:     msgID,
 		MessageType:   msg.MessageType,
 		Component:     msg.Component,
@@ -170,13 +177,49 @@ func (t *transport) ReceiveMessage(ctx context.Context, req *prototk.ReceiveMess
 		Node:          msg.Node,
 		ReplyTo:       msg.ReplyTo,
 		Payload:       msg.Payload,
-	}); err != nil {
+	}
+
+	// Bound the number of messages from the sending peer we process at once, if configured
+	if t.tm.peerInboundConcurrency > 0 {
+		release, err := t.tm.acquireInboundSlot(ctx, msg.ReplyTo)
+		if err != nil {
+			// Returning the error would fail the stream of all the messages from the peer, so instead
+			// the message is moved aside to the dead letters, where it can be inspected
+			if err := t.deadLetter(ctx, tMsg, err.Error()); err != nil {
+				return nil, err
+			}
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+		defer release()
+	}
+
+	if err = t.deliverMessage(ctx, msg.Component, tMsg); err != nil {
 		return nil, err
 	}
 
 	return &prototk.ReceiveMessageResponse{}, nil
 }
 
+func (t *transport) deadLetter(ctx context.Context, msg *components.TransportMessage, reason string) error {
+	log.L(ctx).Errorf("transport %s message id=%s from %s moved to dead letters: %s", t.name, msg.MessageID, msg.ReplyTo, reason)
+	return t.tm.persistence.DB().
+		WithContext(ctx).
+		Table("transport_dead_letters").
+		Clauses(clause.OnConflict{DoNothing: true}). // a redelivery of a message already in the dead letters
+		Create(&pldapi.TransportDeadLetter{
+			ID:            msg.MessageID,
+			Created:       tktypes.TimestampNow(),
+			Transport:     t.name,
+			Node:          msg.ReplyTo,
+			Component:     msg.Component,
+			MessageType:   msg.MessageType,
+			CorrelationID: msg.CorrelationID,
+			Payload:       msg.Payload,
+			Reason:        reason,
+		}).
+		Error
+}
+
 func (t *transport) deliverMessage(ctx context.Context, destIdentity string, msg *components.TransportMessage) error {
 	t.tm.destinationsMux.RLock()
 	defer t.tm.destinationsMux.RUnlock()
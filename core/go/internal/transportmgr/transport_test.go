@@ -20,14 +20,17 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
+	"github.com/kaleido-io/paladin/core/pkg/persistence/mockpersistence"
 	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -144,6 +147,7 @@ func TestSendMessage(t *testing.T) {
 		assert.Equal(t, message.Component, sent.Component)
 		assert.Equal(t, message.ReplyTo, sent.ReplyTo)
 		assert.Equal(t, message.Payload, sent.Payload)
+		assert.Equal(t, int32(components.TransportProtocolVersion), sent.ProtocolVersion)
 
 		// ... if we didn't have a connection established we'd expect to come back to request the details
 		gtdr, err := tp.t.GetTransportDetails(ctx, &prototk.GetTransportDetailsRequest{
@@ -324,13 +328,14 @@ func TestReceiveMessage(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		MessageId:     uuid.NewString(),
-		CorrelationId: confutil.P(uuid.NewString()),
-		Node:          "node1",
-		Component:     "receivingClient1",
-		ReplyTo:       "node2",
-		MessageType:   "myMessageType",
-		Payload:       []byte("some data"),
+		MessageId:       uuid.NewString(),
+		CorrelationId:   confutil.P(uuid.NewString()),
+		Node:            "node1",
+		Component:       "receivingClient1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 
 	rmr, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
@@ -347,13 +352,14 @@ func TestReceiveMessageNoReceiver(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		MessageId:     uuid.NewString(),
-		CorrelationId: confutil.P(uuid.NewString()),
-		Node:          "node1",
-		Component:     "receivingClient1",
-		ReplyTo:       "node2",
-		MessageType:   "myMessageType",
-		Payload:       []byte("some data"),
+		MessageId:       uuid.NewString(),
+		CorrelationId:   confutil.P(uuid.NewString()),
+		Node:            "node1",
+		Component:       "receivingClient1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
@@ -367,13 +373,14 @@ func TestReceiveMessageInvalidDestination(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		MessageId:     uuid.NewString(),
-		CorrelationId: confutil.P(uuid.NewString()),
-		Component:     "___",
-		Node:          "node1",
-		ReplyTo:       "node2",
-		MessageType:   "myMessageType",
-		Payload:       []byte("some data"),
+		MessageId:       uuid.NewString(),
+		CorrelationId:   confutil.P(uuid.NewString()),
+		Component:       "___",
+		Node:            "node1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
@@ -389,13 +396,14 @@ func TestReceiveMessageNotInit(t *testing.T) {
 	tp.t.initialized.Store(false)
 
 	msg := &prototk.Message{
-		MessageId:     uuid.NewString(),
-		CorrelationId: confutil.P(uuid.NewString()),
-		Component:     "to",
-		Node:          "node1",
-		ReplyTo:       "node2",
-		MessageType:   "myMessageType",
-		Payload:       []byte("some data"),
+		MessageId:       uuid.NewString(),
+		CorrelationId:   confutil.P(uuid.NewString()),
+		Component:       "to",
+		Node:            "node1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 		Message: msg,
@@ -419,11 +427,12 @@ func TestReceiveMessageWrongNode(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		Component:   "to",
-		Node:        "node2",
-		ReplyTo:     "node2",
-		MessageType: "myMessageType",
-		Payload:     []byte("some data"),
+		Component:       "to",
+		Node:            "node2",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 		Message: msg,
@@ -436,12 +445,13 @@ func TestReceiveMessageBadDestination(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		MessageId:   uuid.NewString(),
-		Component:   "to",
-		Node:        "node2",
-		ReplyTo:     "node1",
-		MessageType: "myMessageType",
-		Payload:     []byte("some data"),
+		MessageId:       uuid.NewString(),
+		Component:       "to",
+		Node:            "node2",
+		ReplyTo:         "node1",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 		Message: msg,
@@ -449,16 +459,43 @@ func TestReceiveMessageBadDestination(t *testing.T) {
 	assert.Regexp(t, "PD012005", err)
 }
 
+func TestReceiveMessageWrongProtocolVersion(t *testing.T) {
+	ctx, _, tp, done := newTestTransport(t)
+	defer done()
+
+	msg := &prototk.Message{
+		MessageId:       uuid.NewString(),
+		Component:       "to",
+		Node:            "node1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion + 1,
+	}
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: msg,
+	})
+	assert.Regexp(t, "PD012014.*node2", err)
+
+	// Messages from senders that do not set the version are rejected too
+	msg.ProtocolVersion = 0
+	_, err = tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: msg,
+	})
+	assert.Regexp(t, "PD012014", err)
+}
+
 func TestReceiveMessageBadMsgID(t *testing.T) {
 	ctx, _, tp, done := newTestTransport(t)
 	defer done()
 
 	msg := &prototk.Message{
-		Component:   "to",
-		Node:        "node1",
-		ReplyTo:     "node2",
-		MessageType: "myMessageType",
-		Payload:     []byte("some data"),
+		Component:       "to",
+		Node:            "node1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 		Message: msg,
@@ -471,16 +508,226 @@ func TestReceiveMessageBadCorrelID(t *testing.T) {
 	defer done()
 
 	msg := &prototk.Message{
-		MessageId:     uuid.NewString(),
-		CorrelationId: confutil.P("wrong"),
-		Component:     "to",
-		Node:          "node1",
-		ReplyTo:       "node2",
-		MessageType:   "myMessageType",
-		Payload:       []byte("some data"),
+		MessageId:       uuid.NewString(),
+		CorrelationId:   confutil.P("wrong"),
+		Component:       "to",
+		Node:            "node1",
+		ReplyTo:         "node2",
+		MessageType:     "myMessageType",
+		Payload:         []byte("some data"),
+		ProtocolVersion: components.TransportProtocolVersion,
 	}
 	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 		Message: msg,
 	})
 	assert.Regexp(t, "PD012000", err)
 }
+
+func TestReceiveMessageFloodConcurrencyBound(t *testing.T) {
+	const bound = 3
+	const floodSize = 50
+
+	var inFlight, maxInFlight atomic.Int32
+	unblock := make(chan struct{})
+	ctx, tm, tp, done := newTestTransport(t, func(mc *mockComponents) components.TransportClient {
+		receivingClient := componentmocks.NewTransportClient(t)
+		receivingClient.On("Destination").Return("receivingClient1")
+		receivingClient.On("ReceiveTransportMessage", mock.Anything, mock.Anything).Return().Run(func(args mock.Arguments) {
+			n := inFlight.Add(1)
+			for {
+				prev := maxInFlight.Load()
+				if n <= prev || maxInFlight.CompareAndSwap(prev, n) {
+					break
+				}
+			}
+			<-unblock
+			inFlight.Add(-1)
+		})
+		return receivingClient
+	})
+	defer done()
+	tm.peerInboundConcurrency = bound
+
+	errs := make(chan error, floodSize)
+	for i := 0; i < floodSize; i++ {
+		go func() {
+			_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+				Message: &prototk.Message{
+					MessageId:       uuid.NewString(),
+					Node:            "node1",
+					Component:       "receivingClient1",
+					ReplyTo:         "node2",
+					MessageType:     "myMessageType",
+					Payload:         []byte("some data"),
+					ProtocolVersion: components.TransportProtocolVersion,
+				},
+			})
+			errs <- err
+		}()
+	}
+
+	// The receiver fills up to the bound, and no further
+	require.Eventually(t, func() bool { return inFlight.Load() == bound }, 5*time.Second, 1*time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(bound), inFlight.Load())
+
+	// Once we let them through, everything is processed without exceeding the bound
+	close(unblock)
+	for i := 0; i < floodSize; i++ {
+		require.NoError(t, <-errs)
+	}
+	assert.Equal(t, int32(bound), maxInFlight.Load())
+
+	// Nothing is retained for the peer once it is idle
+	tm.peerInboundMux.Lock()
+	assert.Empty(t, tm.peerInboundSlots)
+	tm.peerInboundMux.Unlock()
+}
+
+func TestReceiveMessagePeerBusyDeadLetter(t *testing.T) {
+	received := make(chan string, 10)
+	unblock := make(chan struct{})
+	ctx, tm, tp, done := newTestTransport(t, withRealDB(t), func(mc *mockComponents) components.TransportClient {
+		receivingClient := componentmocks.NewTransportClient(t)
+		receivingClient.On("Destination").Return("receivingClient1")
+		receivingClient.On("ReceiveTransportMessage", mock.Anything, mock.Anything).Return().Run(func(args mock.Arguments) {
+			msg := args[1].(*components.TransportMessage)
+			received <- msg.ReplyTo
+			if msg.ReplyTo == "node2" {
+				<-unblock
+			}
+		})
+		return receivingClient
+	})
+	defer done()
+	tm.peerInboundConcurrency = 1
+	tm.inboundQueueTimeout = 1 * time.Millisecond
+
+	newMessage := func(replyTo string) *prototk.Message {
+		return &prototk.Message{
+			MessageId:       uuid.NewString(),
+			CorrelationId:   confutil.P(uuid.NewString()),
+			Node:            "node1",
+			Component:       "receivingClient1",
+			ReplyTo:         replyTo,
+			MessageType:     "myMessageType",
+			Payload:         []byte("some data"),
+			ProtocolVersion: components.TransportProtocolVersion,
+		}
+	}
+
+	// The first message from node2 holds its only slot until we unblock it
+	firstDone := make(chan error)
+	go func() {
+		_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: newMessage("node2")})
+		firstDone <- err
+	}()
+	assert.Equal(t, "node2", <-received)
+
+	// The next message from node2 cannot be processed in time, so it is moved to the dead letters
+	// without failing the call, which would close the stream of messages from node2
+	deadMsg := newMessage("node2")
+	_, err := tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: deadMsg})
+	require.NoError(t, err)
+
+	// A redelivery of the same message is not recorded twice
+	_, err = tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: deadMsg})
+	require.NoError(t, err)
+
+	// Other peers are unaffected
+	_, err = tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{Message: newMessage("node3")})
+	require.NoError(t, err)
+	assert.Equal(t, "node3", <-received)
+
+	close(unblock)
+	require.NoError(t, <-firstDone)
+
+	deadLetters, err := tm.queryDeadLetters(ctx, query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, deadMsg.MessageId, deadLetters[0].ID.String())
+	assert.Equal(t, "test1", deadLetters[0].Transport)
+	assert.Equal(t, "node2", deadLetters[0].Node)
+	assert.Equal(t, "receivingClient1", deadLetters[0].Component)
+	assert.Equal(t, "myMessageType", deadLetters[0].MessageType)
+	assert.Equal(t, *deadMsg.CorrelationId, deadLetters[0].CorrelationID.String())
+	assert.Equal(t, "some data", string(deadLetters[0].Payload))
+	assert.Regexp(t, "PD012013.*node2", deadLetters[0].Reason)
+
+	deadLetters, err = tm.queryDeadLetters(ctx, query.NewQueryBuilder().Limit(10).Equal("node", "node3").Query())
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+
+	// Nothing is retained for the peers once they are idle
+	tm.peerInboundMux.Lock()
+	assert.Empty(t, tm.peerInboundSlots)
+	tm.peerInboundMux.Unlock()
+}
+
+func TestReceiveMessageDeadLetterFail(t *testing.T) {
+	ctx, tm, tp, done := newTestTransport(t)
+	defer done()
+	tm.peerInboundConcurrency = 1
+	tm.inboundQueueTimeout = 1 * time.Millisecond
+
+	// Hold the only slot for node2
+	release, err := tm.acquireInboundSlot(ctx, "node2")
+	require.NoError(t, err)
+	defer release()
+
+	mp, err := mockpersistence.NewSQLMockProvider()
+	require.NoError(t, err)
+	tm.persistence = mp.P
+	mp.Mock.ExpectExec("INSERT.*transport_dead_letters").WillReturnError(fmt.Errorf("pop"))
+
+	// If the message cannot be kept, the transport is told it was not received
+	_, err = tp.t.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
+		Message: &prototk.Message{
+			MessageId:       uuid.NewString(),
+			Node:            "node1",
+			Component:       "receivingClient1",
+			ReplyTo:         "node2",
+			MessageType:     "myMessageType",
+			Payload:         []byte("some data"),
+			ProtocolVersion: components.TransportProtocolVersion,
+		},
+	})
+	assert.Regexp(t, "pop", err)
+	require.NoError(t, mp.Mock.ExpectationsWereMet())
+}
+
+func TestAcquireInboundSlotCancelled(t *testing.T) {
+	ctx, tm, _, done := newTestTransport(t)
+	defer done()
+	tm.peerInboundConcurrency = 1
+	tm.inboundQueueTimeout = 1 * time.Hour
+
+	// Hold the only slot for node2
+	release, err := tm.acquireInboundSlot(ctx, "node2")
+	require.NoError(t, err)
+
+	// Cancelled context while waiting
+	cancelledCtx, cancelCtx := context.WithCancel(ctx)
+	cancelCtx()
+	_, err = tm.acquireInboundSlot(cancelledCtx, "node2")
+	assert.Regexp(t, "PD010301", err)
+
+	// The slots for node2 are only removed once the holder is done with them
+	assert.Len(t, tm.peerInboundSlots, 1)
+	release()
+	assert.Empty(t, tm.peerInboundSlots)
+}
+
+func TestQueryDeadLettersNoLimit(t *testing.T) {
+	ctx, tm, _, done := newTestTransport(t)
+	defer done()
+
+	_, err := tm.queryDeadLetters(ctx, query.NewQueryBuilder().Query())
+	assert.Regexp(t, "PD012015", err)
+}
+
+func TestInboundConcurrencyDisabledByDefault(t *testing.T) {
+	_, tm, _, done := newTestTransport(t)
+	defer done()
+	assert.Zero(t, tm.peerInboundConcurrency)
+}
@@ -19,6 +19,8 @@ package transportmgr
 import (
 	"context"
 
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 )
 
@@ -30,7 +32,8 @@ func (tm *transportManager) initRPC() {
 	tm.rpcModule = rpcserver.NewRPCModule("transport").
 		Add("transport_nodeName", tm.rpcNodeName()).
 		Add("transport_localTransports", tm.rpcLocalTransports()).
-		Add("transport_localTransportDetails", tm.rpcLocalTransportDetails())
+		Add("transport_localTransportDetails", tm.rpcLocalTransportDetails()).
+		Add("transport_queryDeadLetters", tm.rpcQueryDeadLetters())
 }
 
 func (tm *transportManager) rpcNodeName() rpcserver.RPCHandler {
@@ -54,3 +57,11 @@ func (tm *transportManager) rpcLocalTransportDetails() rpcserver.RPCHandler {
 		return tm.getLocalTransportDetails(ctx, transportName)
 	})
 }
+
+func (tm *transportManager) rpcQueryDeadLetters() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		query query.QueryJSON,
+	) ([]*pldapi.TransportDeadLetter, error) {
+		return tm.queryDeadLetters(ctx, &query)
+	})
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +62,19 @@ func TestRPCLocalDetails(t *testing.T) {
 
 }
 
+func TestRPCQueryDeadLetters(t *testing.T) {
+	ctx, tm, _, done := newTestTransport(t, withRealDB(t))
+	defer done()
+
+	rpc, rpcDone := newTestRPCServer(t, ctx, tm)
+	defer rpcDone()
+
+	var deadLetters []*pldapi.TransportDeadLetter
+	err := rpc.CallRPC(ctx, &deadLetters, "transport_queryDeadLetters", query.NewQueryBuilder().Limit(10).Query())
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
 func newTestRPCServer(t *testing.T, ctx context.Context, tm *transportManager) (rpcclient.Client, func()) {
 
 	s, err := rpcserver.NewRPCServer(ctx, &pldconf.RPCServerConfig{
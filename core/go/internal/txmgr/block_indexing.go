@@ -18,7 +18,10 @@ package txmgr
 import (
 	"context"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
@@ -35,7 +38,7 @@ func (tm *txManager) blockIndexerPreCommit(
 
 	// Pass the list of transactions to the public transaction manager, who will pass us back an
 	// ORDERED list of matches to transaction IDs based on the bindings.
-	txMatches, err := tm.publicTxMgr.MatchUpdateConfirmedTransactions(ctx, dbTX, transactions)
+	txMatches, err := tm.publicTxMgr.MatchUpdateConfirmedTransactions(ctx, dbTX, transactions, tm.reorgPolicy == pldconf.ReorgPolicyCorrect)
 	if err != nil {
 		return nil, err
 	}
@@ -47,14 +50,40 @@ func (tm *txManager) blockIndexerPreCommit(
 	// separate ordering context of the block listener of that domain (we do not promise
 	// order of confirmation delivery between public and private transactions)
 	finalizeInfo := make([]*components.ReceiptInput, 0, len(txMatches))
+	correctedInfo := make([]*components.ReceiptInput, 0)
+	correctedPrivateInfo := make([]*components.ReceiptInput, 0)
+	correctedForPrivateTx := make([]*components.PublicTxMatch, 0)
 	failedForPrivateTx := make([]*components.PublicTxMatch, 0)
 	for _, match := range txMatches {
+		if match.OutOfGasResubmit {
+			log.L(ctx).Warnf("Base ledger transaction for transaction %s ran out of gas and will be resubmitted hash=%s block=%d",
+				match.TransactionID, match.Hash, match.BlockNumber)
+			continue
+		}
+		if match.Corrected {
+			// A re-org has changed the outcome of a transaction we have already written a receipt for.
+			// For private transactions the receipt from the domain is kept, other than the on-chain outcome.
+			if tm.reorgPolicy == pldconf.ReorgPolicyCorrect {
+				log.L(ctx).Warnf("Correcting receipt for transaction %s after re-org hash=%s block=%d result=%s",
+					match.TransactionID, match.Hash, match.BlockNumber, match.Result)
+				if match.TransactionType.V() == pldapi.TransactionTypePrivate {
+					correctedPrivateInfo = append(correctedPrivateInfo, tm.mapBlockchainReceipt(ctx, match))
+					correctedForPrivateTx = append(correctedForPrivateTx, match)
+				} else {
+					correctedInfo = append(correctedInfo, tm.mapBlockchainReceipt(ctx, match))
+				}
+			} else {
+				log.L(ctx).Warnf("Ignoring re-org for transaction %s with existing receipt hash=%s block=%d result=%s",
+					match.TransactionID, match.Hash, match.BlockNumber, match.Result)
+			}
+			continue
+		}
 		switch match.TransactionType.V() {
 		case pldapi.TransactionTypePublic:
 			log.L(ctx).Infof("Writing receipt for transaction %s hash=%s block=%d result=%s",
 				match.TransactionID, match.Hash, match.BlockNumber, match.Result)
 			// Map to the common format for finalizing transactions whether the make it on chain or not
-			finalizeInfo = append(finalizeInfo, tm.mapBlockchainReceipt(match))
+			finalizeInfo = append(finalizeInfo, tm.mapBlockchainReceipt(ctx, match))
 		case pldapi.TransactionTypePrivate:
 			if match.Result.V() != pldapi.TXResult_SUCCESS {
 				log.L(ctx).Infof("Base ledger transaction for private transaction %s FAILED hash=%s block=%d result=%s",
@@ -69,15 +98,28 @@ func (tm *txManager) blockIndexerPreCommit(
 	if err := tm.FinalizeTransactions(ctx, dbTX, finalizeInfo); err != nil {
 		return nil, err
 	}
+	if err := tm.correctReceipts(ctx, dbTX, correctedInfo, false); err != nil {
+		return nil, err
+	}
+	if err := tm.correctReceipts(ctx, dbTX, correctedPrivateInfo, true); err != nil {
+		return nil, err
+	}
 
 	// Deliver the failures to the private transaction manager
+	var privateFailuresCommitted func()
 	if len(failedForPrivateTx) > 0 {
-		if err := tm.privateTxMgr.NotifyFailedPublicTx(ctx, dbTX, failedForPrivateTx); err != nil {
+		if privateFailuresCommitted, err = tm.privateTxMgr.NotifyFailedPublicTx(ctx, dbTX, failedForPrivateTx); err != nil {
 			return nil, err
 		}
 	}
 
 	return func() {
+		if privateFailuresCommitted != nil {
+			privateFailuresCommitted()
+		}
+		if len(correctedForPrivateTx) > 0 {
+			tm.privateTxMgr.NotifyCorrectedPublicTx(ctx, correctedForPrivateTx)
+		}
 		// We need to notify the public TX manager when the DB transaction for these has completed,
 		// so it can remove any in-memory processing (this is regardless of they were matched to
 		// a public or private transaction)
@@ -87,7 +129,7 @@ func (tm *txManager) blockIndexerPreCommit(
 	}, nil
 }
 
-func (tm *txManager) mapBlockchainReceipt(pubTx *components.PublicTxMatch) *components.ReceiptInput {
+func (tm *txManager) mapBlockchainReceipt(ctx context.Context, pubTx *components.PublicTxMatch) *components.ReceiptInput {
 	receipt := &components.ReceiptInput{
 		TransactionID: pubTx.TransactionID,
 		OnChain: tktypes.OnChainLocation{
@@ -99,7 +141,10 @@ func (tm *txManager) mapBlockchainReceipt(pubTx *components.PublicTxMatch) *comp
 		ContractAddress: pubTx.ContractAddress,
 		RevertData:      pubTx.RevertReason,
 	}
-	if pubTx.Result.V() == pldapi.TXResult_SUCCESS {
+	if pubTx.GapFilled {
+		receipt.ReceiptType = components.RT_FailedWithMessage
+		receipt.FailureMessage = i18n.NewError(ctx, msgs.MsgPublicTxNonceUsedByGapFill, pubTx.Nonce, pubTx.From, pubTx.Hash).Error()
+	} else if pubTx.Result.V() == pldapi.TXResult_SUCCESS {
 		receipt.ReceiptType = components.RT_Success
 	} else {
 		receipt.ReceiptType = components.RT_FailedOnChainWithRevertData
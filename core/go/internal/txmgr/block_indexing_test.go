@@ -16,18 +16,21 @@
 package txmgr
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/pkg/blockindexer"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -73,7 +76,7 @@ func TestPublicConfirmWithErrorDecodeRealDB(t *testing.T) {
 		mockSubmissionBatch.On("Completed", mock.Anything, true).Return(nil)
 		mc.publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockSubmissionBatch, nil)
 
-		mut := mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi})
+		mut := mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true)
 		mut.Run(func(args mock.Arguments) {
 			mut.Return([]*components.PublicTxMatch{
 				{
@@ -127,7 +130,7 @@ func TestPublicConfirmMatch(t *testing.T) {
 	txi.ContractAddress = tktypes.RandAddress()
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
-		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}).
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
 			Return([]*components.PublicTxMatch{
 				{
 					PaladinTXReference: components.PaladinTXReference{
@@ -165,10 +168,11 @@ func TestPrivateConfirmMatchPrivateFailures(t *testing.T) {
 	txID1 := uuid.New()
 	txiFail2 := newTestConfirm(revertData) // one failed
 	txID2 := uuid.New()
+	privateFailuresCommitted := false
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
 		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything,
-			[]*blockindexer.IndexedTransactionNotify{txiOk1, txiFail2}).
+			[]*blockindexer.IndexedTransactionNotify{txiOk1, txiFail2}, true).
 			Return([]*components.PublicTxMatch{
 				{
 					PaladinTXReference: components.PaladinTXReference{
@@ -189,7 +193,7 @@ func TestPrivateConfirmMatchPrivateFailures(t *testing.T) {
 		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
 			return len(matches) == 1 &&
 				matches[0].TransactionID == txID2
-		})).Return(nil)
+		})).Return(func() { privateFailuresCommitted = true }, nil)
 
 		mc.publicTxMgr.On("NotifyConfirmPersisted", mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
 			return len(matches) == 2 &&
@@ -202,7 +206,67 @@ func TestPrivateConfirmMatchPrivateFailures(t *testing.T) {
 	postCommit, err := txm.blockIndexerPreCommit(ctx, txm.p.DB(), []*pldapi.IndexedBlock{},
 		[]*blockindexer.IndexedTransactionNotify{txiOk1, txiFail2})
 	require.NoError(t, err)
+	assert.False(t, privateFailuresCommitted)
 	postCommit()
+	assert.True(t, privateFailuresCommitted)
+}
+
+func TestConfirmMatchOutOfGasResubmit(t *testing.T) {
+
+	txiPublic := newTestConfirm()
+	txID1 := uuid.New()
+	txiPrivate := newTestConfirm()
+	txID2 := uuid.New()
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything,
+			[]*blockindexer.IndexedTransactionNotify{txiPublic, txiPrivate}, true).
+			Return([]*components.PublicTxMatch{
+				{
+					PaladinTXReference: components.PaladinTXReference{
+						TransactionID:   txID1,
+						TransactionType: pldapi.TransactionTypePublic.Enum(),
+					},
+					IndexedTransactionNotify: txiPublic,
+					OutOfGasResubmit:         true,
+				},
+				{
+					PaladinTXReference: components.PaladinTXReference{
+						TransactionID:   txID2,
+						TransactionType: pldapi.TransactionTypePrivate.Enum(),
+					},
+					IndexedTransactionNotify: txiPrivate,
+					OutOfGasResubmit:         true,
+				},
+			}, nil)
+
+		// No receipts are written, or failures notified, but the public TX manager is still notified
+		mc.publicTxMgr.On("NotifyConfirmPersisted", mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
+			return len(matches) == 2
+		}))
+	})
+	defer done()
+
+	postCommit, err := txm.blockIndexerPreCommit(ctx, txm.p.DB(), []*pldapi.IndexedBlock{},
+		[]*blockindexer.IndexedTransactionNotify{txiPublic, txiPrivate})
+	require.NoError(t, err)
+	postCommit()
+}
+
+func TestMapBlockchainReceiptGapFilled(t *testing.T) {
+	ctx := context.Background()
+	txm := &txManager{}
+
+	txi := newTestConfirm()
+	txi.Result = pldapi.TXResult_FAILURE.Enum()
+	receipt := txm.mapBlockchainReceipt(ctx, &components.PublicTxMatch{
+		PaladinTXReference:       components.PaladinTXReference{TransactionID: uuid.New()},
+		IndexedTransactionNotify: txi,
+		GapFilled:                true,
+	})
+	assert.Equal(t, components.RT_FailedWithMessage, receipt.ReceiptType)
+	assert.Regexp(t, "PD011948.*"+txi.Hash.String(), receipt.FailureMessage)
+	assert.Equal(t, txi.Hash, receipt.OnChain.TransactionHash)
 }
 
 func TestNoConfirmMatch(t *testing.T) {
@@ -210,7 +274,7 @@ func TestNoConfirmMatch(t *testing.T) {
 	txi := newTestConfirm()
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
-		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}).
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
 			Return(nil, nil)
 	})
 	defer done()
@@ -226,7 +290,7 @@ func TestConfirmMatchFAil(t *testing.T) {
 	txi := newTestConfirm()
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
-		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}).
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
 			Return(nil, fmt.Errorf("pop"))
 	})
 	defer done()
@@ -242,7 +306,7 @@ func TestPrivateConfirmError(t *testing.T) {
 	txID := uuid.New()
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
-		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}).
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
 			Return([]*components.PublicTxMatch{
 				{
 					PaladinTXReference: components.PaladinTXReference{
@@ -252,7 +316,7 @@ func TestPrivateConfirmError(t *testing.T) {
 					IndexedTransactionNotify: txi,
 				},
 			}, nil)
-		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+		mc.privateTxMgr.On("NotifyFailedPublicTx", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
 	})
 	defer done()
 
@@ -267,7 +331,7 @@ func TestConfirmInsertError(t *testing.T) {
 	txID := uuid.New()
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
-		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}).
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
 			Return([]*components.PublicTxMatch{
 				{
 					PaladinTXReference: components.PaladinTXReference{
@@ -286,3 +350,143 @@ func TestConfirmInsertError(t *testing.T) {
 		[]*blockindexer.IndexedTransactionNotify{txi})
 	assert.Regexp(t, "pop", err)
 }
+
+func testReorgFlipsOutcome(t *testing.T, policy pldconf.ReorgPolicy) (*pldapi.TransactionTrace, *pldapi.TransactionReceipt) {
+	txi := newTestConfirm()
+	reorgTxi := newTestConfirm([]byte("revert"))
+	reorgTxi.From, reorgTxi.Nonce = txi.From, txi.Nonce
+	var txID *uuid.UUID
+
+	ctx, txm, done := newTestTransactionManager(t, true,
+		mockPublicSubmitTxOkOrReject(t),
+		mockQueryPublicTxForTransactions(func(ids []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error) {
+			return map[uuid.UUID][]*pldapi.PublicTx{}, nil
+		}),
+		func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+			conf.ReorgPolicy = confutil.P(string(policy))
+			for _, confirm := range []*blockindexer.IndexedTransactionNotify{txi, reorgTxi} {
+				mut := mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{confirm}, policy == pldconf.ReorgPolicyCorrect)
+				mut.Run(func(args mock.Arguments) {
+					mut.Return([]*components.PublicTxMatch{
+						{
+							PaladinTXReference: components.PaladinTXReference{
+								TransactionID:   *txID,
+								TransactionType: pldapi.TransactionTypePublic.Enum(),
+							},
+							IndexedTransactionNotify: confirm,
+							// the public TX manager has already recorded a different outcome for the re-org'd confirmation
+							Corrected: confirm == reorgTxi,
+						},
+					}, nil)
+				})
+			}
+			mc.publicTxMgr.On("NotifyConfirmPersisted", mock.Anything, mock.Anything)
+			mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+				Return([]*tktypes.EthAddress{tktypes.RandAddress()}, nil)
+		})
+	defer done()
+
+	txID, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		ABI: abi.ABI{{Type: abi.Function, Name: "doIt"}},
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			From:     "sender1",
+			To:       tktypes.RandAddress(),
+			Function: "doIt",
+		},
+	})
+	require.NoError(t, err)
+
+	// The transaction is confirmed successfully
+	postCommit, err := txm.blockIndexerPreCommit(ctx, txm.p.DB(), []*pldapi.IndexedBlock{}, []*blockindexer.IndexedTransactionNotify{txi})
+	require.NoError(t, err)
+	postCommit()
+	receipt, err := txm.GetTransactionReceiptByID(ctx, *txID)
+	require.NoError(t, err)
+	require.True(t, receipt.Success)
+
+	// Then a re-org changes the outcome to a failure
+	postCommit, err = txm.blockIndexerPreCommit(ctx, txm.p.DB(), []*pldapi.IndexedBlock{}, []*blockindexer.IndexedTransactionNotify{reorgTxi})
+	require.NoError(t, err)
+	postCommit()
+
+	receipt, err = txm.GetTransactionReceiptByID(ctx, *txID)
+	require.NoError(t, err)
+	trace, err := txm.ExportTransactionTrace(ctx, *txID)
+	require.NoError(t, err)
+	return trace, receipt
+}
+
+func traceHasEvent(trace *pldapi.TransactionTrace, eventType pldapi.TransactionTraceEventType) bool {
+	for _, e := range trace.Events {
+		if e.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPublicConfirmReorgCorrectsReceipt(t *testing.T) {
+	trace, receipt := testReorgFlipsOutcome(t, pldconf.ReorgPolicyCorrect)
+	assert.False(t, receipt.Success)
+	assert.Regexp(t, "PD012221", receipt.FailureMessage)
+	assert.True(t, traceHasEvent(trace, pldapi.TransactionTraceEventReceiptFinalized))
+	assert.True(t, traceHasEvent(trace, pldapi.TransactionTraceEventReceiptCorrected))
+}
+
+func TestPublicConfirmReorgIgnored(t *testing.T) {
+	trace, receipt := testReorgFlipsOutcome(t, pldconf.ReorgPolicyIgnore)
+	assert.True(t, receipt.Success)
+	assert.False(t, traceHasEvent(trace, pldapi.TransactionTraceEventReceiptCorrected))
+}
+
+func TestPrivateConfirmReorgCorrectsReceipt(t *testing.T) {
+	txi := newTestConfirm()
+	txID := uuid.New()
+	corrected := false
+
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("MatchUpdateConfirmedTransactions", mock.Anything, mock.Anything, []*blockindexer.IndexedTransactionNotify{txi}, true).
+			Return([]*components.PublicTxMatch{
+				{
+					PaladinTXReference: components.PaladinTXReference{
+						TransactionID:   txID,
+						TransactionType: pldapi.TransactionTypePrivate.Enum(),
+					},
+					IndexedTransactionNotify: txi,
+					Corrected:                true,
+				},
+			}, nil)
+		mc.privateTxMgr.On("NotifyCorrectedPublicTx", mock.Anything, mock.MatchedBy(func(matches []*components.PublicTxMatch) bool {
+			return len(matches) == 1 && matches[0].TransactionID == txID
+		})).Run(func(args mock.Arguments) { corrected = true })
+		mc.publicTxMgr.On("NotifyConfirmPersisted", mock.Anything, mock.Anything)
+	})
+	defer done()
+
+	// The base ledger transaction previously failed, and the domain contract address is kept on correction
+	contractAddr := tktypes.RandAddress()
+	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{{
+		ReceiptType:     components.RT_FailedWithMessage,
+		Domain:          "domain1",
+		TransactionID:   txID,
+		FailureMessage:  "failed before re-org",
+		ContractAddress: contractAddr,
+	}})
+	require.NoError(t, err)
+
+	// A re-org means it succeeded, and subscribers only hear about it after commit
+	postCommit, err := txm.blockIndexerPreCommit(ctx, txm.p.DB(), []*pldapi.IndexedBlock{}, []*blockindexer.IndexedTransactionNotify{txi})
+	require.NoError(t, err)
+	assert.False(t, corrected)
+	postCommit()
+	assert.True(t, corrected)
+
+	receipt, err := txm.GetTransactionReceiptByID(ctx, txID)
+	require.NoError(t, err)
+	assert.True(t, receipt.Success)
+	assert.Empty(t, receipt.FailureMessage)
+	assert.Equal(t, "domain1", receipt.Domain)
+	assert.Equal(t, contractAddr, receipt.ContractAddress)
+	assert.Equal(t, txi.Hash, *receipt.TransactionHash)
+}
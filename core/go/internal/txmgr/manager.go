@@ -17,9 +17,16 @@ package txmgr
 
 import (
 	"context"
+	"regexp"
+	"sync"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 
 	"github.com/kaleido-io/paladin/core/pkg/ethclient"
 	"github.com/kaleido-io/paladin/core/pkg/persistence"
@@ -31,11 +38,36 @@ import (
 
 func NewTXManager(ctx context.Context, conf *pldconf.TxManagerConfig) components.TXManager {
 	return &txManager{
-		abiCache: cache.NewCache[tktypes.Bytes32, *pldapi.StoredABI](&conf.ABI.Cache, &pldconf.TxManagerDefaults.ABI.Cache),
+		bgCtx:                 ctx,
+		conf:                  conf,
+		abiCache:              cache.NewCache[tktypes.Bytes32, *pldapi.StoredABI](&conf.ABI.Cache, &pldconf.TxManagerDefaults.ABI.Cache),
+		abiAutoStore:          confutil.Bool(conf.ABI.AutoStore, *pldconf.TxManagerDefaults.ABI.AutoStore),
+		maxDependencies:       confutil.IntMin(conf.MaxDependencies, 0, *pldconf.TxManagerDefaults.MaxDependencies),
+		preparedMaxRetention:  confutil.DurationMin(conf.PreparedTransactions.MaxRetention, 0, "0"),
+		preparedCheckInterval: confutil.DurationMin(conf.PreparedTransactions.CheckInterval, 1*time.Second, *pldconf.TxManagerDefaults.PreparedTransactions.CheckInterval),
+		reorgPolicy:           pldconf.ReorgPolicy(confutil.StringNotEmpty(conf.ReorgPolicy, *pldconf.TxManagerDefaults.ReorgPolicy)),
+		receiptSubs:           map[string]*receiptSubscription{},
+		receiptsWritten:       make(chan struct{}, 1),
+		receiptSubsConf: receiptSubscriptionsConf{
+			batchSize:             confutil.IntMin(conf.ReceiptSubscriptions.BatchSize, 1, *pldconf.TxManagerDefaults.ReceiptSubscriptions.BatchSize),
+			recheckInterval:       confutil.DurationMin(conf.ReceiptSubscriptions.RecheckInterval, 1*time.Millisecond, *pldconf.TxManagerDefaults.ReceiptSubscriptions.RecheckInterval),
+			commitTimeout:         confutil.DurationMin(conf.ReceiptSubscriptions.CommitTimeout, 0, *pldconf.TxManagerDefaults.ReceiptSubscriptions.CommitTimeout),
+			commitRecheckInterval: confutil.DurationMin(conf.ReceiptSubscriptions.CommitRecheckInterval, 1*time.Millisecond, *pldconf.TxManagerDefaults.ReceiptSubscriptions.CommitRecheckInterval),
+		},
+		now: time.Now,
 	}
 }
 
+type receiptSubscriptionsConf struct {
+	batchSize             int
+	recheckInterval       time.Duration
+	commitTimeout         time.Duration
+	commitRecheckInterval time.Duration
+}
+
 type txManager struct {
+	bgCtx            context.Context
+	conf             *pldconf.TxManagerConfig
 	p                persistence.Persistence
 	localNodeName    string
 	ethClientFactory ethclient.EthClientFactory
@@ -46,8 +78,27 @@ type txManager struct {
 	stateMgr         components.StateManager
 	identityResolver components.IdentityResolver
 	abiCache         cache.Cache[tktypes.Bytes32, *pldapi.StoredABI]
+	abiAutoStore     bool
+	abiCounters      abiStorageCounters
+	maxDependencies  int
+	keyPathOverrides *regexp.Regexp
+	reorgPolicy      pldconf.ReorgPolicy
 	rpcModule        *rpcserver.RPCModule
 	debugRpcModule   *rpcserver.RPCModule
+
+	preparedMaxRetention  time.Duration
+	preparedCheckInterval time.Duration
+	now                   func() time.Time
+	retentionStop         chan struct{}
+	retentionDone         chan struct{}
+
+	receiptSubsLock sync.Mutex
+	receiptSubs     map[string]*receiptSubscription
+	receiptSubsConf receiptSubscriptionsConf
+
+	receiptsWritten      chan struct{}
+	receiptSequencerStop chan struct{}
+	receiptSequencerDone chan struct{}
 }
 
 func (tm *txManager) PostInit(c components.AllComponents) error {
@@ -64,6 +115,18 @@ func (tm *txManager) PostInit(c components.AllComponents) error {
 }
 
 func (tm *txManager) PreInit(c components.PreInitComponents) (*components.ManagerInitResult, error) {
+	switch tm.reorgPolicy {
+	case pldconf.ReorgPolicyCorrect, pldconf.ReorgPolicyIgnore:
+	default:
+		return nil, i18n.NewError(tm.bgCtx, msgs.MsgTxMgrInvalidReorgPolicy, tm.reorgPolicy)
+	}
+	if tm.conf.AllowedKeyPathOverrides != nil {
+		var err error
+		// The whole key path must match, not just a part of it
+		if tm.keyPathOverrides, err = regexp.Compile(`^(?:` + *tm.conf.AllowedKeyPathOverrides + `)$`); err != nil {
+			return nil, i18n.WrapError(tm.bgCtx, err, msgs.MsgTxMgrKeyPathOverridePolicyInvalid, *tm.conf.AllowedKeyPathOverrides)
+		}
+	}
 	tm.buildRPCModule()
 	return &components.ManagerInitResult{
 		RPCModules:       []*rpcserver.RPCModule{tm.rpcModule, tm.debugRpcModule},
@@ -71,6 +134,27 @@ func (tm *txManager) PreInit(c components.PreInitComponents) (*components.Manage
 	}, nil
 }
 
-func (tm *txManager) Start() error { return nil }
+func (tm *txManager) Start() error {
+	if tm.preparedMaxRetention > 0 {
+		tm.retentionStop = make(chan struct{})
+		tm.retentionDone = make(chan struct{})
+		go tm.preparedRetentionLoop()
+	}
+	if tm.receiptSequencerDone == nil { // only start once
+		tm.receiptSequencerStop = make(chan struct{})
+		tm.receiptSequencerDone = make(chan struct{})
+		go tm.receiptSequencerLoop()
+	}
+	return nil
+}
 
-func (tm *txManager) Stop() {}
+func (tm *txManager) Stop() {
+	if tm.retentionStop != nil {
+		close(tm.retentionStop)
+		<-tm.retentionDone
+	}
+	if tm.receiptSequencerStop != nil {
+		close(tm.receiptSequencerStop)
+		<-tm.receiptSequencerDone
+	}
+}
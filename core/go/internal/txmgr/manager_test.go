@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/core/mocks/componentmocks"
 	"github.com/kaleido-io/paladin/core/mocks/ethclientmocks"
@@ -105,6 +106,12 @@ func newTestTransactionManager(t *testing.T, realDB bool, init ...func(conf *pld
 	err = txm.PostInit(componentMocks)
 	require.NoError(t, err)
 
+	if !realDB {
+		// the receipt sequencer would make unexpected queries against the mock DB
+		txm.receiptSequencerDone = make(chan struct{})
+		close(txm.receiptSequencerDone)
+	}
+
 	err = txm.Start()
 	require.NoError(t, err)
 
@@ -114,3 +121,19 @@ func newTestTransactionManager(t *testing.T, realDB bool, init ...func(conf *pld
 	}
 
 }
+
+func TestPreInitBadKeyPathOverridePolicy(t *testing.T) {
+	txm := NewTXManager(context.Background(), &pldconf.TxManagerConfig{
+		AllowedKeyPathOverrides: confutil.P(`[[`),
+	})
+	_, err := txm.PreInit(componentmocks.NewAllComponents(t))
+	assert.Regexp(t, "PD012232", err)
+}
+
+func TestPreInitBadReorgPolicy(t *testing.T) {
+	txm := NewTXManager(context.Background(), &pldconf.TxManagerConfig{
+		ReorgPolicy: confutil.P("wrong"),
+	})
+	_, err := txm.PreInit(componentmocks.NewAllComponents(t))
+	assert.Regexp(t, "PD012236.*wrong", err)
+}
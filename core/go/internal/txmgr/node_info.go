@@ -0,0 +1,73 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"runtime/debug"
+	"sort"
+
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+)
+
+// Can be set at build time with:
+// -ldflags "-X github.com/kaleido-io/paladin/core/internal/txmgr.buildVersion=v1.2.3"
+var buildVersion = ""
+
+func nodeVersion() string {
+	if buildVersion != "" {
+		return buildVersion
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+		return bi.Main.Version
+	}
+	return "unknown"
+}
+
+// GetNodeInfo returns the information other nodes and operators need to check compatibility
+// with this node before interacting with it.
+func (tm *txManager) GetNodeInfo(ctx context.Context) (*pldapi.NodeInfo, error) {
+	info := &pldapi.NodeInfo{
+		Name:                     tm.localNodeName,
+		Version:                  nodeVersion(),
+		TransportProtocolVersion: components.TransportProtocolVersion,
+		Domains:                  []string{},
+	}
+
+	// The node always signs base ledger transactions with secp256k1 keys, and each
+	// loaded domain adds any signing algorithms it implements
+	signingAlgorithms := map[string]bool{algorithms.ECDSA_SECP256K1: true}
+	for name := range tm.domainMgr.ConfiguredDomains() {
+		domain, err := tm.domainMgr.GetDomainByName(ctx, name)
+		if err != nil {
+			log.L(ctx).Debugf("Domain %s not included in node info: %s", name, err)
+			continue
+		}
+		info.Domains = append(info.Domains, name)
+		for algorithm := range domain.Configuration().SigningAlgorithms {
+			signingAlgorithms[algorithm] = true
+		}
+	}
+	sort.Strings(info.Domains)
+	for algorithm := range signingAlgorithms {
+		info.SigningAlgorithms = append(info.SigningAlgorithms, algorithm)
+	}
+	sort.Strings(info.SigningAlgorithms)
+	return info, nil
+}
@@ -18,6 +18,7 @@ package txmgr
 import (
 	"context"
 	"encoding/json"
+	"sync/atomic"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
@@ -45,6 +46,13 @@ type PersistedABIEntry struct {
 	Definition tktypes.RawJSON  `gorm:"column:definition"`
 }
 
+// Counters since startup, to help operators understand the growth of ABI storage
+type abiStorageCounters struct {
+	stored       atomic.Int64 // new ABIs written to the DB
+	deduplicated atomic.Int64 // requests to store an ABI that was already stored
+	notStored    atomic.Int64 // inline ABIs on calls that were only used in memory, as auto-storage is disabled
+}
+
 var abiFilters = filters.FieldMap{
 	"id":      filters.UUIDField("id"),
 	"created": filters.TimestampField("created"),
@@ -91,6 +99,7 @@ func (tm *txManager) UpsertABI(ctx context.Context, dbTX *gorm.DB, a abi.ABI) (*
 	pa, existing := tm.abiCache.Get(*hash)
 	if existing {
 		log.L(ctx).Debugf("ABI %s already cached", hash)
+		tm.abiCounters.deduplicated.Add(1)
 		return pa, nil
 	}
 
@@ -111,9 +120,10 @@ func (tm *txManager) UpsertABI(ctx context.Context, dbTX *gorm.DB, a abi.ABI) (*
 	}
 
 	// Otherwise ask the DB to store
+	var inserted int64
 	abiBytes, err := json.Marshal(a)
 	if err == nil {
-		err = dbTX.
+		result := dbTX.
 			Table("abis").
 			Clauses(clause.OnConflict{
 				Columns: []clause.Column{
@@ -124,8 +134,8 @@ func (tm *txManager) UpsertABI(ctx context.Context, dbTX *gorm.DB, a abi.ABI) (*
 			Create(&PersistedABI{
 				Hash: *hash,
 				ABI:  abiBytes,
-			}).
-			Error
+			})
+		err, inserted = result.Error, result.RowsAffected
 	}
 	if err == nil && len(abiEntries) > 0 {
 		err = dbTX.
@@ -137,12 +147,50 @@ func (tm *txManager) UpsertABI(ctx context.Context, dbTX *gorm.DB, a abi.ABI) (*
 	if err != nil {
 		return nil, err
 	}
+	if inserted > 0 {
+		tm.abiCounters.stored.Add(1)
+	} else {
+		tm.abiCounters.deduplicated.Add(1)
+	}
 	// Now we can cache it
 	pa = &pldapi.StoredABI{Hash: *hash, ABI: a}
 	tm.abiCache.Set(*hash, pa)
 	return pa, err
 }
 
+// Resolves an inline ABI without storing it, for use when auto-storage is disabled.
+// It is not cached either, as the cache only holds ABIs that have been stored.
+func (tm *txManager) resolveInlineABI(ctx context.Context, a abi.ABI) (*pldapi.StoredABI, error) {
+	hash, err := tktypes.ABISolDefinitionHash(ctx, a)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, msgs.MsgTxMgrInvalidABI)
+	}
+	if pa, existing := tm.abiCache.Get(*hash); existing {
+		return pa, nil
+	}
+	tm.abiCounters.notStored.Add(1)
+	return &pldapi.StoredABI{Hash: *hash, ABI: a}, nil
+}
+
+func (tm *txManager) GetABIStorageStats(ctx context.Context) (*pldapi.ABIStorageStats, error) {
+	var stored int64
+	err := tm.p.DB().
+		WithContext(ctx).
+		Table("abis").
+		Count(&stored).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &pldapi.ABIStorageStats{
+		AutoStore:    tm.abiAutoStore,
+		Stored:       stored,
+		NewlyStored:  tm.abiCounters.stored.Load(),
+		Deduplicated: tm.abiCounters.deduplicated.Load(),
+		NotStored:    tm.abiCounters.notStored.Load(),
+	}, nil
+}
+
 func (tm *txManager) queryABIs(ctx context.Context, jq *query.QueryJSON) ([]*pldapi.StoredABI, error) {
 	qw := &queryWrapper[PersistedABI, pldapi.StoredABI]{
 		p:           tm.p,
@@ -16,15 +16,22 @@
 package txmgr
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/pkg/ethclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetABIByHashError(t *testing.T) {
@@ -99,3 +106,112 @@ func TestUpsertABIFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 
 }
+
+func callWithInlineABI(ctx context.Context, t *testing.T, txm *txManager, a abi.ABI) {
+	tx := pldclient.New().ForABI(ctx, a).
+		Function("getSpins").
+		Public().
+		To(tktypes.RandAddress()).
+		Inputs(map[string]any{"wheel": "of fortune"}).
+		BuildTX()
+	require.NoError(t, tx.Error())
+
+	var result any
+	err := txm.CallTransaction(ctx, &result, tx.CallTX())
+	require.Regexp(t, "PD011517", err) // means we successfully submitted it to the client
+}
+
+func TestABIAutoStoreDisabled(t *testing.T) {
+	ec := ethclient.NewUnconnectedRPCClient(context.Background(), &pldconf.EthClientConfig{}, 0)
+
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		conf.ABI.AutoStore = confutil.P(false)
+		mc.ethClientFactory.On("HTTPClient").Return(ec)
+	})
+	defer done()
+
+	getSpinsABI := abi.ABI{{
+		Name:    "getSpins",
+		Type:    abi.Function,
+		Inputs:  abi.ParameterArray{{Name: "wheel", Type: "string"}},
+		Outputs: abi.ParameterArray{{Name: "times", Type: "uint256"}},
+	}}
+	hash, err := tktypes.ABISolDefinitionHash(ctx, getSpinsABI)
+	require.NoError(t, err)
+
+	// The inline ABI on the call is used, but not persisted
+	callWithInlineABI(ctx, t, txm, getSpinsABI)
+	pa, err := txm.getABIByHash(ctx, txm.p.DB(), *hash)
+	require.NoError(t, err)
+	assert.Nil(t, pa)
+
+	stats, err := txm.GetABIStorageStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.ABIStorageStats{NotStored: 1}, stats)
+
+	// Explicit storage still works, and storing again is deduplicated (whether we have it cached or not)
+	_, err = txm.storeABI(ctx, txm.p.DB(), getSpinsABI)
+	require.NoError(t, err)
+	_, err = txm.storeABI(ctx, txm.p.DB(), getSpinsABI)
+	require.NoError(t, err)
+	txm.abiCache = cache.NewCache[tktypes.Bytes32, *pldapi.StoredABI](&pldconf.CacheConfig{}, &pldconf.TxManagerDefaults.ABI.Cache)
+	_, err = txm.storeABI(ctx, txm.p.DB(), getSpinsABI)
+	require.NoError(t, err)
+
+	// Once stored, a call with the same ABI is not counted as not stored
+	callWithInlineABI(ctx, t, txm, getSpinsABI)
+
+	stats, err = txm.GetABIStorageStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.ABIStorageStats{
+		Stored:       1,
+		NewlyStored:  1,
+		Deduplicated: 2,
+		NotStored:    1,
+	}, stats)
+}
+
+func TestABIAutoStoreEnabled(t *testing.T) {
+	ec := ethclient.NewUnconnectedRPCClient(context.Background(), &pldconf.EthClientConfig{}, 0)
+
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.ethClientFactory.On("HTTPClient").Return(ec)
+	})
+	defer done()
+
+	getSpinsABI := abi.ABI{{
+		Name:   "getSpins",
+		Type:   abi.Function,
+		Inputs: abi.ParameterArray{{Name: "wheel", Type: "string"}},
+	}}
+	hash, err := tktypes.ABISolDefinitionHash(ctx, getSpinsABI)
+	require.NoError(t, err)
+
+	callWithInlineABI(ctx, t, txm, getSpinsABI)
+	callWithInlineABI(ctx, t, txm, getSpinsABI)
+	pa, err := txm.getABIByHash(ctx, txm.p.DB(), *hash)
+	require.NoError(t, err)
+	require.NotNil(t, pa)
+	assert.Equal(t, getSpinsABI, pa.ABI)
+
+	stats, err := txm.GetABIStorageStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.ABIStorageStats{
+		AutoStore:    true,
+		Stored:       1,
+		NewlyStored:  1,
+		Deduplicated: 1,
+	}, stats)
+}
+
+func TestGetABIStorageStatsFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*abis").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	_, err := txm.GetABIStorageStats(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
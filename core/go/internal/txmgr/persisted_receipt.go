@@ -47,6 +47,8 @@ type transactionReceipt struct {
 	FailureMessage   *string             `gorm:"column:failure_message"`
 	RevertData       tktypes.HexBytes    `gorm:"column:revert_data"`
 	ContractAddress  *tktypes.EthAddress `gorm:"column:contract_address"`
+	Corrected        *tktypes.Timestamp  `gorm:"column:corrected"`         // set if the receipt was re-written after a re-org changed the outcome
+	Sequence         *int64              `gorm:"column:sequence;<-:false"` // assigned after commit by the receipt sequencer, and cleared on correction
 }
 
 func mapPersistedReceipt(receipt *transactionReceipt) *pldapi.TransactionReceiptData {
@@ -80,11 +82,30 @@ var transactionReceiptFilters = filters.FieldMap{
 	"success":         filters.BooleanField("success"),
 	"transactionHash": filters.HexBytesField("tx_hash"),
 	"blockNumber":     filters.Int64Field("block_number"),
+	"sequence":        filters.Int64Field("sequence"),
 }
 
 // FinalizeTransactions is called by the block indexing routine, but also can be called
 // by the private transaction manager if transactions fail without making it to the blockchain
 func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, info []*components.ReceiptInput) error {
+	return tm.writeReceipts(ctx, dbTX, info, nil)
+}
+
+// correctReceipts re-writes existing receipts, where a re-org has changed the on-chain outcome.
+// Everything other than the original indexed time is replaced, except the contract address of private
+// transactions which comes from the domain rather than the base ledger transaction.
+// The sequence is cleared, so the receipt sequencer gives the corrected receipt a new one and it is
+// streamed again to receipt subscriptions.
+func (tm *txManager) correctReceipts(ctx context.Context, dbTX *gorm.DB, info []*components.ReceiptInput, private bool) error {
+	columns := []string{"success", "failure_message", "revert_data", "tx_hash", "tx_index", "log_index", "source", "block_number", "corrected", "sequence"}
+	if !private {
+		columns = append(columns, "contract_address")
+	}
+	return tm.writeReceipts(ctx, dbTX, info, columns)
+}
+
+func (tm *txManager) writeReceipts(ctx context.Context, dbTX *gorm.DB, info []*components.ReceiptInput, correctColumns []string) error {
+	correction := correctColumns != nil
 
 	if len(info) == 0 {
 		return nil
@@ -132,16 +153,30 @@ func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, in
 		default:
 			return i18n.NewError(ctx, msgs.MsgTxMgrInvalidReceiptNotification, tktypes.JSONString(ri))
 		}
-		log.L(ctx).Infof("Inserting receipt txId=%s success=%t failure=%s txHash=%v", receipt.TransactionID, receipt.Success, failureMsg, receipt.TransactionHash)
+		if correction {
+			receipt.Corrected = &receipt.Indexed
+			log.L(ctx).Warnf("Correcting receipt txId=%s success=%t failure=%s txHash=%v", receipt.TransactionID, receipt.Success, failureMsg, receipt.TransactionHash)
+		} else {
+			log.L(ctx).Infof("Inserting receipt txId=%s success=%t failure=%s txHash=%v", receipt.TransactionID, receipt.Success, failureMsg, receipt.TransactionHash)
+		}
 		receiptsToInsert = append(receiptsToInsert, receipt)
 	}
 
 	if len(receiptsToInsert) > 0 {
-		err := dbTX.Table("transaction_receipts").
-			Clauses(clause.OnConflict{
+		onConflict := clause.OnConflict{
+			Columns:   []clause.Column{{Name: "transaction"}},
+			DoNothing: true, // once inserted, the receipt is immutable
+		}
+		if correction {
+			// the only exception is a re-org, which also clears the sequence so the corrected
+			// receipt is given a new one, and streamed again to subscriptions
+			onConflict = clause.OnConflict{
 				Columns:   []clause.Column{{Name: "transaction"}},
-				DoNothing: true, // once inserted, the receipt is immutable
-			}).
+				DoUpdates: clause.AssignmentColumns(correctColumns),
+			}
+		}
+		err := dbTX.Table("transaction_receipts").
+			Clauses(onConflict).
 			Create(receiptsToInsert).
 			Error
 		if err != nil {
@@ -149,8 +184,8 @@ func (tm *txManager) FinalizeTransactions(ctx context.Context, dbTX *gorm.DB, in
 		}
 	}
 
-	// TODO: Need to create an guaranteed increasing event table for these receipts, as applications
-	//       must be able to efficiently and reliably listen for them as they are written (good or bad)
+	// The receipts are given a sequence once the caller commits, and then delivered to subscriptions
+	tm.notifyReceiptsWritten()
 
 	return nil
 }
@@ -310,6 +345,7 @@ func (tm *txManager) QueryTransactionReceipts(ctx context.Context, jq *query.Que
 		mapResult: func(pt *transactionReceipt) (*pldapi.TransactionReceipt, error) {
 			return &pldapi.TransactionReceipt{
 				ID:                     pt.TransactionID,
+				Sequence:               uint64(int64OrZero(pt.Sequence)),
 				TransactionReceiptData: *mapPersistedReceipt(pt),
 			}, nil
 		},
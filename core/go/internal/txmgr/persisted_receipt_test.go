@@ -16,8 +16,10 @@
 package txmgr
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -148,13 +150,12 @@ func TestFinalizeTransactionsInsertOkOffChain(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	receipt, err := txm.GetTransactionReceiptByID(ctx, *txID)
-	require.NoError(t, err)
-	require.NotNil(t, receipt)
+	receipt := waitReceiptSequenced(t, ctx, txm, *txID)
 	require.JSONEq(t, fmt.Sprintf(`{
 		"id":"%s",
+		"sequence":%d,
 		"failureMessage":"PD012214: Unable to decode revert data (no revert data available)"
-	}`, txID), string(tktypes.JSONString(receipt)))
+	}`, txID, receipt.Sequence), string(tktypes.JSONString(receipt)))
 
 }
 
@@ -209,12 +210,14 @@ func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	waitReceiptSequenced(t, ctx, txm, *txID)
 	receipt, err := txm.GetTransactionReceiptByIDFull(ctx, *txID)
 	require.NoError(t, err)
 
 	require.NotNil(t, receipt)
 	require.JSONEq(t, fmt.Sprintf(`{
 		"id":"%s",
+		"sequence":%d,
 		"domain": "domain1",
 		"blockNumber":12345, 
 		"logIndex":5,
@@ -224,7 +227,7 @@ func TestFinalizeTransactionsInsertOkEvent(t *testing.T) {
 		"transactionIndex":10,
 		"states": {"none": true},
 		"domainReceiptError": "not available"
-	}`, txID), tktypes.JSONString(receipt).Pretty())
+	}`, txID, receipt.Sequence), tktypes.JSONString(receipt).Pretty())
 
 }
 
@@ -262,6 +265,32 @@ func TestCalculateRevertErrorDecodeFail(t *testing.T) {
 
 }
 
+func TestCalculateRevertErrorStoredABI(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true)
+	defer done()
+
+	// Gas estimate rejections are decoded using the ABI stored when the transaction was resolved
+	errABI := abi.ABI{{Type: abi.Error, Name: "BadValue", Inputs: abi.ParameterArray{
+		{Type: "uint256", Name: "value"},
+	}}}
+	_, err := txm.storeABI(ctx, txm.p.DB(), errABI)
+	require.NoError(t, err)
+
+	revertData, err := errABI.Errors()["BadValue"].EncodeCallDataValuesCtx(ctx, []any{12345})
+	require.NoError(t, err)
+	err = txm.CalculateRevertError(ctx, txm.p.DB(), revertData)
+	assert.Regexp(t, `PD012216.*BadValue\("12345"\)`, err)
+
+	// Falls back to the raw data when there is no matching error in any stored ABI
+	unknownABI := abi.ABI{{Type: abi.Error, Name: "Unknown", Inputs: abi.ParameterArray{}}}
+	unknownData, err := unknownABI.Errors()["Unknown"].EncodeCallDataValuesCtx(ctx, []any{})
+	require.NoError(t, err)
+	err = txm.CalculateRevertError(ctx, txm.p.DB(), unknownData)
+	assert.Regexp(t, "PD012221.*"+tktypes.HexBytes(unknownData).String(), err)
+
+}
+
 func TestGetTransactionReceiptNoResult(t *testing.T) {
 
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
@@ -384,3 +413,15 @@ func TestDecodeEvent(t *testing.T) {
 	assert.Regexp(t, "PD020015", err)
 
 }
+
+func waitReceiptSequenced(t *testing.T, ctx context.Context, txm *txManager, txID uuid.UUID) *pldapi.TransactionReceipt {
+	for {
+		receipt, err := txm.GetTransactionReceiptByID(ctx, txID)
+		require.NoError(t, err)
+		require.NotNil(t, receipt)
+		if receipt.Sequence != 0 {
+			return receipt
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
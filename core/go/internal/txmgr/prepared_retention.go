@@ -0,0 +1,100 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"gorm.io/gorm"
+)
+
+const preparedExpiryBatchSize = 100
+
+type expiredPreparedTransaction struct {
+	ID     uuid.UUID `gorm:"column:id"`
+	Domain string    `gorm:"column:domain"`
+}
+
+func (tm *txManager) preparedRetentionLoop() {
+	defer close(tm.retentionDone)
+
+	ctx := log.WithLogField(tm.bgCtx, "role", "prepared_tx_retention")
+	ticker := time.NewTicker(tm.preparedCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tm.retentionStop:
+			return
+		case <-ticker.C:
+			if _, err := tm.expirePreparedTransactions(ctx); err != nil {
+				log.L(ctx).Errorf("Failed to expire prepared transactions: %s", err)
+			}
+		}
+	}
+}
+
+// Prepared transactions that were submitted to this node with SubmitModeExternal, but have not had
+// a receipt within the retention period, are finalized with a failure receipt so they do not
+// remain pending forever. A prepared transaction submitted after this point will not replace it.
+func (tm *txManager) expirePreparedTransactions(ctx context.Context) (expiredCount int, err error) {
+	cutoff := tktypes.Timestamp(tm.now().Add(-tm.preparedMaxRetention).UnixNano())
+	failureMessage := i18n.NewError(ctx, msgs.MsgTxMgrPreparedTransactionExpired, tm.preparedMaxRetention).Error()
+	for {
+		var expired []*expiredPreparedTransaction
+		err = tm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+			err := dbTX.WithContext(ctx).
+				Table("prepared_txns").
+				Select(`"prepared_txns"."id", "prepared_txns"."domain"`).
+				Joins(`JOIN "transactions" ON "transactions"."id" = "prepared_txns"."id"`).
+				Joins(`LEFT JOIN "transaction_receipts" ON "transaction_receipts"."transaction" = "prepared_txns"."id"`).
+				Where(`"transactions"."submit_mode" = ?`, pldapi.SubmitModeExternal).
+				Where(`"transactions"."created" < ?`, cutoff).
+				Where(`"transaction_receipts"."transaction" IS NULL`).
+				Limit(preparedExpiryBatchSize).
+				Find(&expired).
+				Error
+			if err != nil || len(expired) == 0 {
+				return err
+			}
+			receipts := make([]*components.ReceiptInput, len(expired))
+			for i, e := range expired {
+				log.L(ctx).Warnf("Expiring prepared transaction %s that was not submitted within %s", e.ID, tm.preparedMaxRetention)
+				receipts[i] = &components.ReceiptInput{
+					ReceiptType:    components.RT_FailedWithMessage,
+					TransactionID:  e.ID,
+					Domain:         e.Domain,
+					FailureMessage: failureMessage,
+				}
+			}
+			return tm.FinalizeTransactions(ctx, dbTX, receipts)
+		})
+		if err != nil {
+			return expiredCount, err
+		}
+		expiredCount += len(expired)
+		if len(expired) < preparedExpiryBatchSize {
+			return expiredCount, nil
+		}
+	}
+}
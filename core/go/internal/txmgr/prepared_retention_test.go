@@ -0,0 +1,158 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpirePreparedTransactions(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		conf.PreparedTransactions.MaxRetention = confutil.P("10m")
+	})
+	defer done()
+
+	fakeNow := time.Now()
+	txm.now = func() time.Time { return fakeNow }
+
+	contractAddr := *tktypes.RandAddress()
+	newPrivateTX := func(idempotencyKey string, submitMode pldapi.SubmitMode) *components.ValidatedTransaction {
+		tx, err := txm.resolveNewTransaction(ctx, txm.p.DB(), &pldapi.TransactionInput{
+			TransactionBase: pldapi.TransactionBase{
+				From:           "me",
+				IdempotencyKey: idempotencyKey,
+				Type:           pldapi.TransactionTypePrivate.Enum(),
+				Domain:         "domain1",
+				To:             &contractAddr,
+				Function:       "doThing1",
+			},
+			ABI: abi.ABI{{Type: abi.Function, Name: "doThing1"}},
+		}, submitMode)
+		require.NoError(t, err)
+		_, err = txm.insertTransactions(ctx, txm.p.DB(), []*components.ValidatedTransaction{tx}, false)
+		require.NoError(t, err)
+		return tx
+	}
+	writePrepared := func(tx *components.ValidatedTransaction) {
+		err := txm.WritePreparedTransactions(ctx, txm.p.DB(), []*components.PrepareTransactionWithRefs{{
+			ID:     *tx.Transaction.ID,
+			Domain: "domain1",
+			To:     &contractAddr,
+			Transaction: &pldapi.TransactionInput{
+				TransactionBase: pldapi.TransactionBase{
+					From:     "me@node1",
+					Type:     pldapi.TransactionTypePrivate.Enum(),
+					Domain:   "domain2",
+					To:       tktypes.RandAddress(),
+					Function: "doThing2",
+				},
+				ABI: abi.ABI{{Type: abi.Function, Name: "doThing2"}},
+			},
+		}})
+		require.NoError(t, err)
+	}
+
+	// One prepared TX that is never submitted, one that gets a receipt, and one
+	// that is not for external submission (which never expires)
+	unsubmitted := newPrivateTX("unsubmitted", pldapi.SubmitModeExternal)
+	writePrepared(unsubmitted)
+	submitted := newPrivateTX("submitted", pldapi.SubmitModeExternal)
+	writePrepared(submitted)
+	err := txm.FinalizeTransactions(ctx, txm.p.DB(), []*components.ReceiptInput{
+		{TransactionID: *submitted.Transaction.ID, ReceiptType: components.RT_Success, Domain: "domain1"},
+	})
+	require.NoError(t, err)
+	auto := newPrivateTX("auto", pldapi.SubmitModeAuto)
+	writePrepared(auto)
+
+	// Nothing has expired yet
+	expired, err := txm.expirePreparedTransactions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	// Move the clock past the retention period
+	fakeNow = fakeNow.Add(10*time.Minute + 1*time.Second)
+	expired, err = txm.expirePreparedTransactions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	receipt, err := txm.GetTransactionReceiptByID(ctx, *unsubmitted.Transaction.ID)
+	require.NoError(t, err)
+	require.NotNil(t, receipt)
+	assert.False(t, receipt.Success)
+	assert.Equal(t, "domain1", receipt.Domain)
+	assert.Regexp(t, "PD012235.*never submitted.*10m0s", receipt.FailureMessage)
+
+	receipt, err = txm.GetTransactionReceiptByID(ctx, *submitted.Transaction.ID)
+	require.NoError(t, err)
+	assert.True(t, receipt.Success)
+
+	receipt, err = txm.GetTransactionReceiptByID(ctx, *auto.Transaction.ID)
+	require.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	// Only expired once
+	expired, err = txm.expirePreparedTransactions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+}
+
+func TestExpirePreparedTransactionsFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*prepared_txns").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	_, err := txm.expirePreparedTransactions(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestPreparedRetentionLoop(t *testing.T) {
+
+	var mc *mockComponents
+	_, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, _mc *mockComponents) {
+		mc = _mc
+		conf.PreparedTransactions.MaxRetention = confutil.P("1h")
+	})
+	defer done()
+
+	// Restart the loop on a short interval, and wait for it to poll (errors are just logged)
+	txm.Stop()
+	txm.preparedCheckInterval = 1 * time.Millisecond
+	mc.db.ExpectBegin()
+	mc.db.ExpectQuery("SELECT.*prepared_txns").WillReturnError(fmt.Errorf("pop"))
+	mc.db.ExpectRollback()
+	require.NoError(t, txm.Start())
+	require.Eventually(t, func() bool { return mc.db.ExpectationsWereMet() == nil }, 5*time.Second, 1*time.Millisecond)
+
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+const defaultPrivateTxStatsWindow = 1 * time.Hour
+
+type receiptCount struct {
+	Success bool `gorm:"column:success"`
+	Count   int  `gorm:"column:count"`
+}
+
+// GetPrivateTransactionStats combines the in-memory stats of the private transaction manager, with
+// counts from the persisted transactions and receipts over the given window (one hour if not set)
+func (tm *txManager) GetPrivateTransactionStats(ctx context.Context, window string) (*pldapi.PrivateTransactionStats, error) {
+	windowDuration := defaultPrivateTxStatsWindow
+	if window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return nil, i18n.NewError(ctx, msgs.MsgTxMgrInvalidStatsWindow, window)
+		}
+		windowDuration = d
+	}
+	since := tktypes.Timestamp(time.Now().Add(-windowDuration).UnixNano())
+
+	inMemory := tm.privateTxMgr.GetTxStats(ctx, since)
+	stats := &pldapi.PrivateTransactionStats{
+		Window:      windowDuration.String(),
+		ByStatus:    inMemory.ByStatus,
+		DelegatedIn: inMemory.DelegatedIn,
+	}
+	for _, count := range inMemory.ByStatus {
+		stats.InFlight += count
+	}
+	if l := inMemory.StageLatency[components.PrivateTxStageAssembling]; l != nil {
+		stats.AverageAssemblyLatency = l.Average().String()
+	}
+	if l := inMemory.StageLatency[components.PrivateTxStageEndorsing]; l != nil {
+		stats.AverageEndorsementLatency = l.Average().String()
+	}
+
+	var submitted, delegatedOut int64
+	err := tm.p.DB().
+		WithContext(ctx).
+		Table("transactions").
+		Where("type = ?", pldapi.TransactionTypePrivate.Enum()).
+		Where("created >= ?", since).
+		Count(&submitted).
+		Error
+	if err == nil {
+		// Transactions delegated to us by other nodes are not persisted here, so anything with a
+		// coordinator other than ourselves was submitted here and delegated out
+		err = tm.p.DB().
+			WithContext(ctx).
+			Table("transactions").
+			Where("type = ?", pldapi.TransactionTypePrivate.Enum()).
+			Where("created >= ?", since).
+			Where("coordinator IS NOT NULL AND coordinator <> ?", tm.localNodeName).
+			Count(&delegatedOut).
+			Error
+	}
+	var receiptCounts []*receiptCount
+	if err == nil {
+		err = tm.p.DB().
+			WithContext(ctx).
+			Table("transaction_receipts").
+			Select("transaction_receipts.success AS success, COUNT(*) AS count").
+			Joins(`JOIN transactions ON transactions.id = transaction_receipts."transaction"`).
+			Where("transactions.type = ?", pldapi.TransactionTypePrivate.Enum()).
+			Where("transaction_receipts.indexed >= ?", since).
+			Group("transaction_receipts.success").
+			Find(&receiptCounts).
+			Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats.Submitted = int(submitted)
+	stats.DelegatedOut = int(delegatedOut)
+	for _, rc := range receiptCounts {
+		if rc.Success {
+			stats.Succeeded += rc.Count
+		} else {
+			stats.Failed += rc.Count
+		}
+	}
+	if total := stats.Succeeded + stats.Failed; total > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(total)
+	}
+	return stats, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"gorm.io/gorm"
+)
+
+// Receipts are written in the DB transaction of the caller of FinalizeTransactions, and those DB transactions
+// can commit in any order, or roll back. So the sequence is not assigned on insert, where a subscription
+// could see a later sequence before an earlier one is committed. Instead this single routine assigns the
+// sequences to receipts once they are committed, so that sequences only become visible in order, with no gaps.
+//
+// The notification from writeReceipts arrives before the commit, so after one we re-check quickly until
+// the commit timeout, and then fall back to checking at the recheck interval.
+func (tm *txManager) receiptSequencerLoop() {
+	defer close(tm.receiptSequencerDone)
+
+	ctx := log.WithLogField(tm.bgCtx, "role", "receipt_sequencer")
+	conf := &tm.receiptSubsConf
+	var notifiedAt *time.Time
+	for {
+		assigned, err := tm.assignReceiptSequences(ctx)
+		if err != nil {
+			log.L(ctx).Errorf("Failed to assign receipt sequences: %s", err)
+		}
+		if assigned > 0 {
+			tm.notifyReceiptSubscriptions()
+		}
+		if notifiedAt != nil && time.Since(*notifiedAt) >= conf.commitTimeout {
+			notifiedAt = nil
+		}
+		wait := conf.recheckInterval
+		if err == nil && assigned == conf.batchSize {
+			wait = 0
+		} else if err == nil && notifiedAt != nil {
+			wait = conf.commitRecheckInterval
+		}
+		select {
+		case <-tm.receiptSequencerStop:
+			return
+		case <-tm.receiptsWritten:
+			if notifiedAt == nil {
+				now := time.Now()
+				notifiedAt = &now
+			}
+		case <-time.After(wait):
+		}
+	}
+}
+
+// notifyReceiptsWritten is called when receipts are written, and does not block
+func (tm *txManager) notifyReceiptsWritten() {
+	select {
+	case tm.receiptsWritten <- struct{}{}:
+	default:
+	}
+}
+
+func (tm *txManager) assignReceiptSequences(ctx context.Context) (assigned int, err error) {
+	err = tm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		var unsequenced []uuid.UUID
+		err := dbTX.WithContext(ctx).
+			Table("transaction_receipts").
+			Where(`"sequence" IS NULL`).
+			Order(`"indexed"`).
+			Order(`"transaction"`).
+			Limit(tm.receiptSubsConf.batchSize).
+			Pluck("transaction", &unsequenced).
+			Error
+		if err != nil || len(unsequenced) == 0 {
+			return err
+		}
+		var maxSequence *int64
+		err = dbTX.WithContext(ctx).
+			Table("transaction_receipts").
+			Select(`MAX("sequence")`).
+			Scan(&maxSequence).
+			Error
+		if err != nil {
+			return err
+		}
+		var nextSequence int64 = 1
+		if maxSequence != nil {
+			nextSequence = *maxSequence + 1
+		}
+		for _, txID := range unsequenced {
+			err := dbTX.WithContext(ctx).
+				Table("transaction_receipts").
+				Where(`"transaction" = ?`, txID).
+				Update("sequence", nextSequence).
+				Error
+			if err != nil {
+				return err
+			}
+			nextSequence++
+		}
+		assigned = len(unsequenced)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if assigned > 0 {
+		log.L(ctx).Debugf("Assigned sequences to %d receipts", assigned)
+	}
+	return assigned, nil
+}
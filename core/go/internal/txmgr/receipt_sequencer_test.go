@@ -0,0 +1,129 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignReceiptSequencesInOrder(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf)
+	defer done()
+
+	// Stop the background sequencer, so we can check the receipts before they are given a sequence
+	txm.Stop()
+	txm.receiptSequencerStop = nil
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	tx2 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx1, tx2)
+
+	var unsequenced int64
+	err := txm.p.DB().Table("transaction_receipts").Where(`"sequence" IS NULL`).Count(&unsequenced).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unsequenced)
+
+	assigned, err := txm.assignReceiptSequences(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, assigned)
+
+	r1, err := txm.GetTransactionReceiptByID(ctx, tx1)
+	require.NoError(t, err)
+	r2, err := txm.GetTransactionReceiptByID(ctx, tx2)
+	require.NoError(t, err)
+	assert.NotZero(t, r1.Sequence)
+	assert.Equal(t, r1.Sequence+1, r2.Sequence)
+
+	assigned, err = txm.assignReceiptSequences(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, assigned)
+
+}
+
+func TestAssignReceiptSequencesQueryFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	_, err := txm.assignReceiptSequences(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestAssignReceiptSequencesMaxFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnRows(sqlmock.NewRows([]string{"transaction"}).AddRow(uuid.NewString()))
+		mc.db.ExpectQuery("SELECT.*MAX").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	_, err := txm.assignReceiptSequences(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestAssignReceiptSequencesUpdateFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnRows(sqlmock.NewRows([]string{"transaction"}).AddRow(uuid.NewString()))
+		mc.db.ExpectQuery("SELECT.*MAX").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(10))
+		mc.db.ExpectExec("UPDATE.*transaction_receipts").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	_, err := txm.assignReceiptSequences(ctx)
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestReceiptSequencerLoopAssignFail(t *testing.T) {
+
+	_, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectBegin()
+		mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+	})
+	defer done()
+
+	txm.notifyReceiptsWritten()
+	txm.notifyReceiptsWritten()
+	assert.Len(t, txm.receiptsWritten, 1)
+
+	// Run the loop against the mock DB until the failed assignment, and stop it
+	txm.receiptSequencerStop = make(chan struct{})
+	txm.receiptSequencerDone = make(chan struct{})
+	go txm.receiptSequencerLoop()
+	txm.Stop()
+	txm.receiptSequencerStop = nil
+
+}
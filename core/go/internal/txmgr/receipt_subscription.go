@@ -0,0 +1,182 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// receiptSubscription streams receipts to a WebSocket client in the order they were committed, using
+// the sequence of the receipt table as a checkpoint the client can resume from.
+//
+// Sequences are only assigned by the receipt sequencer once receipts are committed, in order and with
+// no gaps, so the subscription simply delivers everything after the last sequence it delivered. It is
+// notified by the receipt sequencer when new sequences are assigned.
+type receiptSubscription struct {
+	tm           *txManager
+	ctx          context.Context
+	sub          rpcserver.RPCSubscription
+	options      *pldapi.TransactionReceiptSubscribeOptions
+	lastSequence int64
+	notified     chan struct{}
+	done         chan struct{}
+}
+
+type receiptWithTransaction struct {
+	Receipt transactionReceipt `gorm:"embedded"`
+	From    *string            `gorm:"column:tx_from"` // nil if the transaction was not submitted on this node
+	Type    *string            `gorm:"column:tx_type"`
+}
+
+func (tm *txManager) SubscribeReceipts(ctx context.Context, sub rpcserver.RPCSubscription, options *pldapi.TransactionReceiptSubscribeOptions) error {
+	if options.From != nil {
+		// match the fully qualified sender stored on submission
+		identifier, node, err := tktypes.PrivateIdentityLocator(*options.From).Validate(ctx, tm.localNodeName, false)
+		if err != nil {
+			return err
+		}
+		options.From = confutil.P(fmt.Sprintf("%s@%s", identifier, node))
+	}
+	if options.Type != nil {
+		if _, err := options.Type.Validate(); err != nil {
+			return err
+		}
+	}
+
+	rs := &receiptSubscription{
+		tm:       tm,
+		ctx:      log.WithLogField(ctx, "receipt_sub", sub.ID()),
+		sub:      sub,
+		options:  options,
+		notified: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if options.FromSequence != nil {
+		rs.lastSequence = int64(*options.FromSequence)
+	} else {
+		var maxSequence *int64
+		err := tm.p.DB().WithContext(ctx).
+			Table("transaction_receipts").
+			Select(`MAX("sequence")`).
+			Scan(&maxSequence).
+			Error
+		if err != nil {
+			return err
+		}
+		if maxSequence != nil {
+			rs.lastSequence = *maxSequence
+		}
+	}
+
+	tm.receiptSubsLock.Lock()
+	tm.receiptSubs[sub.ID()] = rs
+	tm.receiptSubsLock.Unlock()
+
+	log.L(rs.ctx).Infof("Receipt subscription started after sequence %d", rs.lastSequence)
+	go rs.deliveryLoop()
+	return nil
+}
+
+// notifyReceiptSubscriptions is called when receipts are given sequences, and does not block
+func (tm *txManager) notifyReceiptSubscriptions() {
+	tm.receiptSubsLock.Lock()
+	defer tm.receiptSubsLock.Unlock()
+	for _, rs := range tm.receiptSubs {
+		select {
+		case rs.notified <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (rs *receiptSubscription) deliveryLoop() {
+	defer close(rs.done)
+	defer func() {
+		rs.tm.receiptSubsLock.Lock()
+		delete(rs.tm.receiptSubs, rs.sub.ID())
+		rs.tm.receiptSubsLock.Unlock()
+		log.L(rs.ctx).Infof("Receipt subscription ended at sequence %d", rs.lastSequence)
+	}()
+
+	conf := &rs.tm.receiptSubsConf
+	for {
+		caughtUp, err := rs.deliverBatch()
+		if err != nil {
+			log.L(rs.ctx).Errorf("Receipt subscription delivery failed: %s", err)
+		}
+		wait := conf.recheckInterval
+		if err == nil && !caughtUp {
+			wait = 0
+		}
+		select {
+		case <-rs.sub.Done():
+			return
+		case <-rs.notified:
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (rs *receiptSubscription) deliverBatch() (caughtUp bool, err error) {
+	batchSize := rs.tm.receiptSubsConf.batchSize
+	var receipts []*receiptWithTransaction
+	err = rs.tm.p.DB().WithContext(rs.ctx).
+		Table(`transaction_receipts AS r`).
+		Select(`r.*, t."from" AS tx_from, t."type" AS tx_type`).
+		Joins(`LEFT JOIN transactions AS t ON t."id" = r."transaction"`).
+		Where(`r."sequence" > ?`, rs.lastSequence).
+		Order(`r."sequence"`).
+		Limit(batchSize).
+		Find(&receipts).
+		Error
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range receipts {
+		sequence := *r.Receipt.Sequence
+		if rs.matches(r) {
+			receipt := &pldapi.TransactionReceipt{
+				ID:                     r.Receipt.TransactionID,
+				Sequence:               uint64(sequence),
+				TransactionReceiptData: *mapPersistedReceipt(&r.Receipt),
+			}
+			if err := rs.sub.Send(rs.ctx, receipt); err != nil {
+				return false, err
+			}
+		}
+		rs.lastSequence = sequence
+	}
+	return len(receipts) < batchSize, nil
+}
+
+func (rs *receiptSubscription) matches(r *receiptWithTransaction) bool {
+	if rs.options.From != nil && (r.From == nil || *r.From != *rs.options.From) {
+		return false
+	}
+	if rs.options.Type != nil && (r.Type == nil || *r.Type != string(*rs.options.Type)) {
+		return false
+	}
+	return true
+}
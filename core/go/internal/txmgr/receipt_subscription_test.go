@@ -0,0 +1,360 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/core/internal/components"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type testReceiptSub struct {
+	id      string
+	done    chan struct{}
+	sent    chan *pldapi.TransactionReceipt
+	sendErr error
+}
+
+func newTestReceiptSub() *testReceiptSub {
+	return &testReceiptSub{
+		id:   uuid.NewString(),
+		done: make(chan struct{}),
+		sent: make(chan *pldapi.TransactionReceipt, 10),
+	}
+}
+
+func (ts *testReceiptSub) ID() string { return ts.id }
+
+func (ts *testReceiptSub) Done() <-chan struct{} { return ts.done }
+
+func (ts *testReceiptSub) Send(ctx context.Context, result any) error {
+	if ts.sendErr != nil {
+		return ts.sendErr
+	}
+	ts.sent <- result.(*pldapi.TransactionReceipt)
+	return nil
+}
+
+func (ts *testReceiptSub) next(t *testing.T) *pldapi.TransactionReceipt {
+	select {
+	case r := <-ts.sent:
+		return r
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for receipt")
+		return nil
+	}
+}
+
+func mockReceiptSubscriptionConf(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+	mc.privateTxMgr.On("HandleNewTx", mock.Anything, mock.Anything).Return(nil).Maybe()
+	conf.ReceiptSubscriptions.RecheckInterval = confutil.P("10ms")
+	conf.ReceiptSubscriptions.CommitTimeout = confutil.P("100ms")
+	conf.ReceiptSubscriptions.CommitRecheckInterval = confutil.P("1ms")
+}
+
+func sendTestPrivateTx(t *testing.T, ctx context.Context, txm *txManager, from string) uuid.UUID {
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	callData, err := exampleABI[0].EncodeCallDataJSON([]byte(`[]`))
+	require.NoError(t, err)
+
+	txID, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			From:     from,
+			Type:     pldapi.TransactionTypePrivate.Enum(),
+			Domain:   "domain1",
+			Function: "doIt",
+			To:       tktypes.MustEthAddress(tktypes.RandHex(20)),
+			Data:     tktypes.JSONString(tktypes.HexBytes(callData)),
+		},
+		ABI: exampleABI,
+	})
+	require.NoError(t, err)
+	return *txID
+}
+
+func finalizeTestReceipts(t *testing.T, ctx context.Context, txm *txManager, txIDs ...uuid.UUID) {
+	err := txm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		receipts := make([]*components.ReceiptInput, len(txIDs))
+		for i, txID := range txIDs {
+			receipts[i] = &components.ReceiptInput{
+				TransactionID:  txID,
+				Domain:         "domain1",
+				ReceiptType:    components.RT_FailedWithMessage,
+				FailureMessage: fmt.Sprintf("failure %d", i),
+			}
+		}
+		return txm.FinalizeTransactions(ctx, dbTX, receipts)
+	})
+	require.NoError(t, err)
+}
+
+func TestReceiptSubscriptionResumeAndFilter(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		conf.ReceiptSubscriptions.BatchSize = confutil.P(2)
+	})
+	defer done()
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	tx2 := sendTestPrivateTx(t, ctx, txm, "you")
+	tx3 := uuid.New() // not known locally
+	finalizeTestReceipts(t, ctx, txm, tx1, tx2, tx3)
+
+	// All receipts from the start, over multiple batches
+	sub1 := newTestReceiptSub()
+	defer close(sub1.done)
+	err := txm.SubscribeReceipts(ctx, sub1, &pldapi.TransactionReceiptSubscribeOptions{
+		FromSequence: confutil.P(uint64(0)),
+	})
+	require.NoError(t, err)
+	r1 := sub1.next(t)
+	assert.Equal(t, tx1, r1.ID)
+	assert.Equal(t, "failure 0", r1.FailureMessage)
+	r2 := sub1.next(t)
+	assert.Equal(t, tx2, r2.ID)
+	r3 := sub1.next(t)
+	assert.Equal(t, tx3, r3.ID)
+	assert.Greater(t, r2.Sequence, r1.Sequence)
+	assert.Greater(t, r3.Sequence, r2.Sequence)
+
+	// Resume after the first, filtered to the sender of the second
+	sub2 := newTestReceiptSub()
+	defer close(sub2.done)
+	err = txm.SubscribeReceipts(ctx, sub2, &pldapi.TransactionReceiptSubscribeOptions{
+		From:         confutil.P("you"),
+		Type:         confutil.P(pldapi.TransactionTypePrivate.Enum()),
+		FromSequence: &r1.Sequence,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tx2, sub2.next(t).ID)
+
+	// Filtered to a type that does not match, then a new receipt that does not match either
+	sub3 := newTestReceiptSub()
+	err = txm.SubscribeReceipts(ctx, sub3, &pldapi.TransactionReceiptSubscribeOptions{
+		Type:         confutil.P(pldapi.TransactionTypePublic.Enum()),
+		FromSequence: confutil.P(uint64(0)),
+	})
+	require.NoError(t, err)
+	rs3 := txm.receiptSubs[sub3.id]
+
+	tx4 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx4)
+	assert.Equal(t, tx4, sub1.next(t).ID)
+
+	close(sub3.done)
+	<-rs3.done
+	assert.Empty(t, sub2.sent)
+	assert.Empty(t, sub3.sent)
+
+}
+
+func TestReceiptSubscriptionRPC(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf)
+	defer done()
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx1)
+
+	sub := newTestReceiptSub()
+	defer close(sub.done)
+	res := txm.rpcSubscribeReceipts().Subscribe(ctx, &rpcclient.RPCRequest{
+		JSONRpc: "2.0",
+		ID:      fftypes.JSONAnyPtr(`1`),
+		Method:  "ptx_subscribeReceipts",
+		Params:  []*fftypes.JSONAny{fftypes.JSONAnyPtr(`{"from":"me","fromSequence":0}`)},
+	}, sub)
+	require.Nil(t, res.Error)
+	assert.Equal(t, fmt.Sprintf(`"%s"`, sub.id), res.Result.String())
+	assert.Equal(t, tx1, sub.next(t).ID)
+
+}
+
+func TestReceiptSubscriptionNewOnly(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf)
+	defer done()
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx1)
+	waitReceiptSequenced(t, ctx, txm, tx1)
+
+	sub := newTestReceiptSub()
+	err := txm.SubscribeReceipts(ctx, sub, &pldapi.TransactionReceiptSubscribeOptions{})
+	require.NoError(t, err)
+	rs := txm.receiptSubs[sub.id]
+
+	tx2 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx2)
+	assert.Equal(t, tx2, sub.next(t).ID)
+
+	close(sub.done)
+	<-rs.done
+	assert.Empty(t, txm.receiptSubs)
+
+}
+
+func TestReceiptSubscriptionCorrectionResequenced(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf)
+	defer done()
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	tx2 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx1, tx2)
+
+	sub := newTestReceiptSub()
+	defer close(sub.done)
+	err := txm.SubscribeReceipts(ctx, sub, &pldapi.TransactionReceiptSubscribeOptions{
+		FromSequence: confutil.P(uint64(0)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tx1, sub.next(t).ID)
+	r2 := sub.next(t)
+	assert.Equal(t, tx2, r2.ID)
+
+	// Correcting the first receipt gives it a new sequence, so it is streamed again
+	err = txm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+		return txm.correctReceipts(ctx, dbTX, []*components.ReceiptInput{{
+			TransactionID:  tx1,
+			Domain:         "domain1",
+			ReceiptType:    components.RT_FailedWithMessage,
+			FailureMessage: "corrected",
+		}}, true)
+	})
+	require.NoError(t, err)
+	r1 := sub.next(t)
+	assert.Equal(t, tx1, r1.ID)
+	assert.Equal(t, "corrected", r1.FailureMessage)
+	assert.Equal(t, r2.Sequence+1, r1.Sequence)
+
+}
+
+func TestReceiptSubscriptionSendFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, true, mockReceiptSubscriptionConf)
+	defer done()
+
+	tx1 := sendTestPrivateTx(t, ctx, txm, "me")
+	finalizeTestReceipts(t, ctx, txm, tx1)
+	waitReceiptSequenced(t, ctx, txm, tx1)
+
+	sub := newTestReceiptSub()
+	sub.sendErr = fmt.Errorf("pop")
+	err := txm.SubscribeReceipts(ctx, sub, &pldapi.TransactionReceiptSubscribeOptions{
+		FromSequence: confutil.P(uint64(0)),
+	})
+	require.NoError(t, err)
+	rs := txm.receiptSubs[sub.id]
+
+	caughtUp, err := rs.deliverBatch()
+	assert.Regexp(t, "pop", err)
+	assert.False(t, caughtUp)
+
+	close(sub.done)
+	<-rs.done
+
+}
+
+func TestReceiptSubscriptionBadType(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	err := txm.SubscribeReceipts(ctx, newTestReceiptSub(), &pldapi.TransactionReceiptSubscribeOptions{
+		Type: confutil.P(pldapi.TransactionType("wrong").Enum()),
+	})
+	assert.Regexp(t, "PD020003", err)
+
+}
+
+func TestReceiptSubscriptionBadFrom(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	err := txm.SubscribeReceipts(ctx, newTestReceiptSub(), &pldapi.TransactionReceiptSubscribeOptions{
+		From: confutil.P("!!!wrong"),
+	})
+	assert.Error(t, err)
+
+}
+
+func TestReceiptSubscriptionMaxSequenceFail(t *testing.T) {
+
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*MAX").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	err := txm.SubscribeReceipts(ctx, newTestReceiptSub(), &pldapi.TransactionReceiptSubscribeOptions{})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestReceiptSubscriptionQueryFail(t *testing.T) {
+
+	var db sqlmock.Sqlmock
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		db = mc.db
+		db.ExpectQuery("SELECT.*transaction_receipts").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	sub := newTestReceiptSub()
+	err := txm.SubscribeReceipts(ctx, sub, &pldapi.TransactionReceiptSubscribeOptions{
+		FromSequence: confutil.P(uint64(10)),
+	})
+	require.NoError(t, err)
+	rs := txm.receiptSubs[sub.id]
+
+	for db.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	close(sub.done)
+	<-rs.done
+	assert.Equal(t, int64(10), rs.lastSequence)
+
+}
+
+func TestReceiptSubscriptionNotifyNonBlocking(t *testing.T) {
+
+	_, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	rs := &receiptSubscription{notified: make(chan struct{}, 1)}
+	txm.receiptSubs["sub1"] = rs
+	txm.notifyReceiptSubscriptions()
+	txm.notifyReceiptSubscriptions()
+	assert.Len(t, rs.notified, 1)
+
+}
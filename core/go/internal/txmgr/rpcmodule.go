@@ -34,6 +34,7 @@ func (tm *txManager) buildRPCModule() {
 		Add("ptx_prepareTransaction", tm.rpcPrepareTransaction()).
 		Add("ptx_prepareTransactions", tm.rpcPrepareTransactions()).
 		Add("ptx_call", tm.rpcCall()).
+		Add("ptx_estimateGasForPrivateTransaction", tm.rpcEstimateGasForPrivateTransaction()).
 		Add("ptx_getTransaction", tm.rpcGetTransaction()).
 		Add("ptx_getTransactionFull", tm.rpcGetTransactionFull()).
 		Add("ptx_getTransactionByIdempotencyKey", tm.rpcGetTransactionByIdempotencyKey()).
@@ -45,11 +46,17 @@ func (tm *txManager) buildRPCModule() {
 		Add("ptx_getDomainReceipt", tm.rpcGetDomainReceipt()).
 		Add("ptx_getStateReceipt", tm.rpcGetStateReceipt()).
 		Add("ptx_queryTransactionReceipts", tm.rpcQueryTransactionReceipts()).
+		AddSubscription("ptx_subscribeReceipts", tm.rpcSubscribeReceipts()).
 		Add("ptx_getTransactionDependencies", tm.rpcGetTransactionDependencies()).
 		Add("ptx_queryPublicTransactions", tm.rpcQueryPublicTransactions()).
 		Add("ptx_queryPendingPublicTransactions", tm.rpcQueryPendingPublicTransactions()).
+		Add("ptx_queryRejectedPublicTransactions", tm.rpcQueryRejectedPublicTransactions()).
 		Add("ptx_getPublicTransactionByNonce", tm.rpcGetPublicTransactionByNonce()).
 		Add("ptx_getPublicTransactionByHash", tm.rpcGetPublicTransactionByHash()).
+		Add("ptx_getPublicTransactionGasHistory", tm.rpcGetPublicTransactionGasHistory()).
+		Add("ptx_getPublicTransactionNonces", tm.rpcGetPublicTransactionNonces()).
+		Add("ptx_resubmitAllForAddress", tm.rpcResubmitAllForAddress()).
+		Add("ptx_cancelPrivateTransaction", tm.rpcCancelPrivateTransaction()).
 		Add("ptx_getPreparedTransaction", tm.rpcGetPreparedTransaction()).
 		Add("ptx_queryPreparedTransactions", tm.rpcQueryPreparedTransactions()).
 		Add("ptx_storeABI", tm.rpcStoreABI()).
@@ -61,7 +68,13 @@ func (tm *txManager) buildRPCModule() {
 		Add("ptx_resolveVerifier", tm.rpcResolveVerifier())
 
 	tm.debugRpcModule = rpcserver.NewRPCModule("debug").
-		Add("debug_getTransactionStatus", tm.rpcDebugTransactionStatus())
+		Add("debug_getTransactionStatus", tm.rpcDebugTransactionStatus()).
+		Add("debug_getPrivateTransactionAttestations", tm.rpcDebugPrivateTransactionAttestations()).
+		Add("debug_getBlockedTransactions", tm.rpcDebugBlockedTransactions()).
+		Add("debug_exportTransactionTrace", tm.rpcDebugExportTransactionTrace()).
+		Add("debug_nodeInfo", tm.rpcDebugNodeInfo()).
+		Add("debug_getPrivateTxStats", tm.rpcDebugPrivateTxStats()).
+		Add("debug_getABIStats", tm.rpcDebugABIStats())
 }
 
 func (tm *txManager) rpcSendTransaction() rpcserver.RPCHandler {
@@ -105,6 +118,14 @@ func (tm *txManager) rpcCall() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcEstimateGasForPrivateTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		tx *pldapi.TransactionInput,
+	) (tktypes.HexUint64, error) {
+		return tm.EstimateGasForPrivateTransaction(ctx, tx)
+	})
+}
+
 func (tm *txManager) rpcGetTransaction() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		id uuid.UUID,
@@ -173,6 +194,23 @@ func (tm *txManager) rpcGetTransactionReceiptFull() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcResubmitAllForAddress() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		from tktypes.EthAddress,
+	) ([]tktypes.HexUint64, error) {
+		return tm.ResubmitAllPublicTransactionsForAddress(ctx, from)
+	})
+}
+
+func (tm *txManager) rpcCancelPrivateTransaction() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		id uuid.UUID,
+	) (bool, error) {
+		err := tm.CancelPrivateTransaction(ctx, id)
+		return err == nil, err
+	})
+}
+
 func (tm *txManager) rpcGetPreparedTransaction() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		id uuid.UUID,
@@ -214,6 +252,15 @@ func (tm *txManager) rpcQueryTransactionReceipts() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcSubscribeReceipts() rpcserver.RPCSubscriptionHandler {
+	return rpcserver.RPCSubscription1(func(ctx context.Context,
+		sub rpcserver.RPCSubscription,
+		options pldapi.TransactionReceiptSubscribeOptions,
+	) error {
+		return tm.SubscribeReceipts(ctx, sub, &options)
+	})
+}
+
 func (tm *txManager) rpcQueryPreparedTransactions() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		query query.QueryJSON,
@@ -238,6 +285,14 @@ func (tm *txManager) rpcQueryPendingPublicTransactions() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcQueryRejectedPublicTransactions() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		query query.QueryJSON,
+	) ([]*pldapi.PublicTxRejection, error) {
+		return tm.queryRejectedPublicTransactions(ctx, &query)
+	})
+}
+
 func (tm *txManager) rpcGetPublicTransactionByNonce() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod2(func(ctx context.Context,
 		from tktypes.EthAddress,
@@ -255,6 +310,23 @@ func (tm *txManager) rpcGetPublicTransactionByHash() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcGetPublicTransactionNonces() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		id uuid.UUID,
+	) ([]*pldapi.PublicTxNonceMapping, error) {
+		return tm.GetPublicTransactionNonces(ctx, id)
+	})
+}
+
+func (tm *txManager) rpcGetPublicTransactionGasHistory() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		from tktypes.EthAddress,
+		nonce tktypes.HexUint64,
+	) ([]*pldapi.PublicTxSubmissionData, error) {
+		return tm.GetPublicTransactionGasHistory(ctx, from, nonce)
+	})
+}
+
 func (tm *txManager) rpcStoreABI() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod1(func(ctx context.Context,
 		a abi.ABI,
@@ -298,6 +370,51 @@ func (tm *txManager) rpcDebugTransactionStatus() rpcserver.RPCHandler {
 	})
 }
 
+func (tm *txManager) rpcDebugPrivateTransactionAttestations() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod2(func(ctx context.Context,
+		contractAddress string,
+		id uuid.UUID,
+	) (*components.PrivateTxAttestations, error) {
+		return tm.privateTxMgr.GetTxAttestations(ctx, contractAddress, id.String())
+	})
+}
+
+func (tm *txManager) rpcDebugBlockedTransactions() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		contractAddress string,
+	) ([]*components.BlockedPrivateTransaction, error) {
+		return tm.privateTxMgr.GetBlockedTransactions(ctx, contractAddress)
+	})
+}
+
+func (tm *txManager) rpcDebugExportTransactionTrace() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		id uuid.UUID,
+	) (*pldapi.TransactionTrace, error) {
+		return tm.ExportTransactionTrace(ctx, id)
+	})
+}
+
+func (tm *txManager) rpcDebugNodeInfo() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod0(func(ctx context.Context) (*pldapi.NodeInfo, error) {
+		return tm.GetNodeInfo(ctx)
+	})
+}
+
+func (tm *txManager) rpcDebugPrivateTxStats() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod1(func(ctx context.Context,
+		window string,
+	) (*pldapi.PrivateTransactionStats, error) {
+		return tm.GetPrivateTransactionStats(ctx, window)
+	})
+}
+
+func (tm *txManager) rpcDebugABIStats() rpcserver.RPCHandler {
+	return rpcserver.RPCMethod0(func(ctx context.Context) (*pldapi.ABIStorageStats, error) {
+		return tm.GetABIStorageStats(ctx)
+	})
+}
+
 func (tm *txManager) rpcDecodeError() rpcserver.RPCHandler {
 	return rpcserver.RPCMethod2(func(ctx context.Context,
 		revertError tktypes.HexBytes,
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -32,6 +34,7 @@ import (
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
 	"github.com/kaleido-io/paladin/toolkit/pkg/rpcserver"
@@ -225,10 +228,16 @@ func TestPublicTransactionLifecycle(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, tx2ID, *tx2.ID)
 
-	// Submit again and check we get the right error with the ID
-	err = rpcClient.CallRPC(ctx, &txIDs, "ptx_sendTransactions", []*pldapi.TransactionInput{tx2Input})
+	// Submit again on its own and check we get the right error with the ID
+	var txID uuid.UUID
+	err = rpcClient.CallRPC(ctx, &txID, "ptx_sendTransaction", tx2Input)
 	assert.Regexp(t, fmt.Sprintf("PD012220.*tx2=%s", tx2ID), err)
 
+	// Submit again in a batch, and check the existing ID is returned
+	err = rpcClient.CallRPC(ctx, &txIDs, "ptx_sendTransactions", []*pldapi.TransactionInput{tx2Input})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx2ID}, txIDs)
+
 	// Null on not found is the consistent ethereum pattern
 	var txNotFound *pldapi.Transaction
 	err = rpcClient.CallRPC(ctx, &txns, "ptx_getTransaction", uuid.New())
@@ -441,6 +450,127 @@ func TestPublicTransactionPassthroughQueries(t *testing.T) {
 	assert.Equal(t, sampleTxns[0], txn)
 }
 
+func TestQueryRejectedPublicTransactionsRPC(t *testing.T) {
+
+	rejection := &pldapi.PublicTxRejection{
+		ID:           uuid.New(),
+		From:         tktypes.EthAddress(tktypes.RandBytes(20)),
+		RejectReason: "execution reverted",
+		RevertData:   tktypes.HexBytes("some data"),
+	}
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("QueryPublicTxRejections", mock.Anything, mock.Anything, mock.Anything).Return([]*pldapi.PublicTxRejection{rejection}, nil).Once()
+		mc.publicTxMgr.On("QueryPublicTxRejections", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	})
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var rejections []*pldapi.PublicTxRejection
+	err = rpcClient.CallRPC(ctx, &rejections, "ptx_queryRejectedPublicTransactions", query.NewQueryBuilder().Limit(100).Query())
+	require.NoError(t, err)
+	assert.Equal(t, []*pldapi.PublicTxRejection{rejection}, rejections)
+
+	err = rpcClient.CallRPC(ctx, &rejections, "ptx_queryRejectedPublicTransactions", query.NewQueryBuilder().Query())
+	require.Regexp(t, "PD012200", err)
+
+	err = rpcClient.CallRPC(ctx, &rejections, "ptx_queryRejectedPublicTransactions", query.NewQueryBuilder().Limit(100).Query())
+	require.Regexp(t, "pop", err)
+}
+
+func TestResubmitAllForAddressRPC(t *testing.T) {
+
+	from := tktypes.EthAddress(tktypes.RandBytes(20))
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("ResubmitAllForAddress", mock.Anything, from).Return([]uint64{3, 4, 5}, nil).Once()
+		mc.publicTxMgr.On("ResubmitAllForAddress", mock.Anything, from).Return(nil, fmt.Errorf("pop")).Once()
+	})
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var nonces []tktypes.HexUint64
+	err = rpcClient.CallRPC(ctx, &nonces, "ptx_resubmitAllForAddress", from)
+	require.NoError(t, err)
+	assert.Equal(t, []tktypes.HexUint64{3, 4, 5}, nonces)
+
+	err = rpcClient.CallRPC(ctx, &nonces, "ptx_resubmitAllForAddress", from)
+	require.Regexp(t, "pop", err)
+}
+
+func TestPublicTransactionNoncesRPC(t *testing.T) {
+
+	txID := uuid.New()
+	from := tktypes.EthAddress(tktypes.RandBytes(20))
+	txHash := tktypes.Bytes32(tktypes.RandBytes(32))
+	binding := pldapi.PublicTxBinding{Transaction: txID, TransactionType: pldapi.TransactionTypePrivate.Enum()}
+	var mockQuery func(jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error)
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t,
+		mockQueryPublicTxWithBindings(func(jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error) { return mockQuery(jq) }),
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	// A dispatched private transaction that was resubmitted on a second nonce, where the first was confirmed
+	mockQuery = func(jq *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error) {
+		assert.JSONEq(t, fmt.Sprintf(`{
+			"eq": [{"field":"transaction","value":"%s"}],
+			"sort": ["created"]}`, txID), string(tktypes.JSONString(jq)))
+		return []*pldapi.PublicTxWithBinding{
+			{PublicTx: &pldapi.PublicTx{From: from, Nonce: 10, TransactionHash: &txHash}, PublicTxBinding: binding},
+			{PublicTx: &pldapi.PublicTx{From: from, Nonce: 11}, PublicTxBinding: binding},
+		}, nil
+	}
+	var mappings []*pldapi.PublicTxNonceMapping
+	err = rpcClient.CallRPC(ctx, &mappings, "ptx_getPublicTransactionNonces", txID)
+	require.NoError(t, err)
+	assert.Equal(t, []*pldapi.PublicTxNonceMapping{
+		{PublicTxBinding: binding, From: from, Nonce: 10, TransactionHash: &txHash},
+		{PublicTxBinding: binding, From: from, Nonce: 11},
+	}, mappings)
+
+	mockQuery = func(_ *query.QueryJSON) ([]*pldapi.PublicTxWithBinding, error) { return nil, fmt.Errorf("pop") }
+	err = rpcClient.CallRPC(ctx, &mappings, "ptx_getPublicTransactionNonces", txID)
+	require.Regexp(t, "pop", err)
+}
+
+func TestPublicTransactionGasHistoryRPC(t *testing.T) {
+
+	from := tktypes.EthAddress(tktypes.RandBytes(20))
+	history := []*pldapi.PublicTxSubmissionData{
+		{
+			Time:               tktypes.TimestampNow(),
+			TransactionHash:    tktypes.Bytes32(tktypes.RandBytes(32)),
+			PublicTxGasPricing: pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(100)},
+		},
+		{
+			Time:               tktypes.TimestampNow(),
+			TransactionHash:    tktypes.Bytes32(tktypes.RandBytes(32)),
+			PublicTxGasPricing: pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(110)},
+		},
+	}
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.publicTxMgr.On("GetPublicTransactionSubmissions", mock.Anything, mock.Anything, from, uint64(12345)).Return(history, nil).Once()
+		mc.publicTxMgr.On("GetPublicTransactionSubmissions", mock.Anything, mock.Anything, from, uint64(12345)).Return(nil, fmt.Errorf("pop")).Once()
+	})
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var result []*pldapi.PublicTxSubmissionData
+	err = rpcClient.CallRPC(ctx, &result, "ptx_getPublicTransactionGasHistory", from, tktypes.HexUint64(12345))
+	require.NoError(t, err)
+	assert.Equal(t, tktypes.JSONString(history), tktypes.JSONString(result))
+
+	err = rpcClient.CallRPC(ctx, &result, "ptx_getPublicTransactionGasHistory", from, tktypes.HexUint64(12345))
+	require.Regexp(t, "pop", err)
+}
+
 func TestDetailedReceiptRPCsNotFound(t *testing.T) {
 
 	ctx, url, _, done := newTestTransactionManagerWithRPC(t, func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
@@ -523,6 +653,486 @@ func TestDebugTransactionStatus(t *testing.T) {
 
 }
 
+func TestDebugPrivateTransactionAttestations(t *testing.T) {
+
+	contractAddress := tktypes.RandAddress()
+	txID := uuid.New().String()
+
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t,
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.privateTxMgr.On("GetTxAttestations", mock.Anything, contractAddress.String(), txID).Return(&components.PrivateTxAttestations{
+				TxID: txID,
+				Attestations: []*components.PrivateTxAttestation{
+					{
+						Name:            "notary",
+						AttestationType: "ENDORSE",
+						Parties: []*components.PrivateTxAttestationParty{
+							{Party: "notary@node1", Verifier: "0x1234", Received: true, Result: "ENDORSER_SUBMIT"},
+						},
+					},
+				},
+			}, nil)
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var result components.PrivateTxAttestations
+	err = rpcClient.CallRPC(ctx, &result, "debug_getPrivateTransactionAttestations", contractAddress.String(), txID)
+	require.NoError(t, err)
+	assert.Equal(t, txID, result.TxID)
+	require.Len(t, result.Attestations, 1)
+	require.Len(t, result.Attestations[0].Parties, 1)
+	assert.Equal(t, "0x1234", result.Attestations[0].Parties[0].Verifier)
+	assert.True(t, result.Attestations[0].Parties[0].Received)
+	assert.Equal(t, "ENDORSER_SUBMIT", result.Attestations[0].Parties[0].Result)
+
+}
+
+func TestDebugBlockedTransactions(t *testing.T) {
+
+	contractAddress := tktypes.RandAddress()
+	blockedTxID := uuid.New().String()
+	blockerTxID := uuid.New().String()
+
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t,
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.privateTxMgr.On("GetBlockedTransactions", mock.Anything, contractAddress.String()).Return([]*components.BlockedPrivateTransaction{
+				{
+					TxID: blockedTxID,
+					BlockedBy: []*components.PrivateTxBlocker{
+						{TxID: blockerTxID, Reason: components.PrivateTxBlockedDependency, States: []string{"state1"}},
+					},
+				},
+			}, nil)
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var result []*components.BlockedPrivateTransaction
+	err = rpcClient.CallRPC(ctx, &result, "debug_getBlockedTransactions", contractAddress.String())
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, blockedTxID, result[0].TxID)
+	require.Len(t, result[0].BlockedBy, 1)
+	assert.Equal(t, blockerTxID, result[0].BlockedBy[0].TxID)
+	assert.Equal(t, components.PrivateTxBlockedDependency, result[0].BlockedBy[0].Reason)
+	assert.Equal(t, []string{"state1"}, result[0].BlockedBy[0].States)
+
+}
+
+func TestDebugNodeInfo(t *testing.T) {
+
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t,
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.domainManager.On("ConfiguredDomains").Return(map[string]*pldconf.PluginConfig{
+				"domain1": {}, "domain2": {}, "notready": {},
+			})
+			domain1 := componentmocks.NewDomain(t)
+			domain1.On("Configuration").Return(&prototk.DomainConfig{
+				SigningAlgorithms: map[string]int32{"domain:domain1:snark:babyjubjub": 32},
+			})
+			domain2 := componentmocks.NewDomain(t)
+			domain2.On("Configuration").Return(&prototk.DomainConfig{
+				SigningAlgorithms: map[string]int32{algorithms.ECDSA_SECP256K1: 32},
+			})
+			mc.domainManager.On("GetDomainByName", mock.Anything, "domain1").Return(domain1, nil)
+			mc.domainManager.On("GetDomainByName", mock.Anything, "domain2").Return(domain2, nil)
+			mc.domainManager.On("GetDomainByName", mock.Anything, "notready").Return(nil, fmt.Errorf("not initialized"))
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var info *pldapi.NodeInfo
+	err = rpcClient.CallRPC(ctx, &info, "debug_nodeInfo")
+	require.NoError(t, err)
+	assert.Equal(t, "node1", info.Name)
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, components.TransportProtocolVersion, info.TransportProtocolVersion)
+	assert.Equal(t, []string{"domain:domain1:snark:babyjubjub", algorithms.ECDSA_SECP256K1}, info.SigningAlgorithms)
+	assert.Equal(t, []string{"domain1", "domain2"}, info.Domains)
+
+	buildVersion = "v1.2.3"
+	defer func() { buildVersion = "" }()
+	err = rpcClient.CallRPC(ctx, &info, "debug_nodeInfo")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", info.Version)
+
+}
+
+func TestDebugPrivateTxStats(t *testing.T) {
+
+	senderAddr := tktypes.RandAddress()
+	ctx, url, tmr, done := newTestTransactionManagerWithRPC(t,
+		mockPublicSubmitTxOkOrReject(t),
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.privateTxMgr.On("HandleNewTx", mock.Anything, mock.Anything).Return(nil)
+			mc.privateTxMgr.On("GetTxStats", mock.Anything, mock.Anything).Return(&components.PrivateTxStats{
+				ByStatus: map[string]int{"assembled": 2, "dispatched": 1},
+				StageLatency: map[components.PrivateTxStage]*components.PrivateTxStageLatency{
+					components.PrivateTxStageAssembling: {Count: 2, Total: 3 * time.Second},
+				},
+				DelegatedIn: 3,
+			})
+			mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+				Return([]*tktypes.EthAddress{senderAddr}, nil)
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	sendTx := func(txType pldapi.TransactionType) uuid.UUID {
+		var txID uuid.UUID
+		err := rpcClient.CallRPC(ctx, &txID, "ptx_sendTransaction", &pldapi.TransactionInput{
+			ABI: exampleABI,
+			TransactionBase: pldapi.TransactionBase{
+				From:     "sender1",
+				Type:     txType.Enum(),
+				Domain:   "domain1",
+				Function: "doIt",
+				To:       tktypes.RandAddress(),
+				Data:     tktypes.RawJSON(`[]`),
+			},
+		})
+		require.NoError(t, err)
+		return txID
+	}
+	privateTxIDs := make([]uuid.UUID, 4)
+	for i := range privateTxIDs {
+		privateTxIDs[i] = sendTx(pldapi.TransactionTypePrivate)
+	}
+	publicTxID := sendTx(pldapi.TransactionTypePublic)
+
+	// one delegated to another node, and one coordinated by this node
+	err = tmr.SetTransactionCoordinator(ctx, tmr.p.DB(), "node2", privateTxIDs[0:1])
+	require.NoError(t, err)
+	err = tmr.SetTransactionCoordinator(ctx, tmr.p.DB(), "node1", privateTxIDs[1:2])
+	require.NoError(t, err)
+
+	// one success and two failures for private transactions, and a public failure that is not counted
+	err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
+		{TransactionID: privateTxIDs[0], ReceiptType: components.RT_Success},
+		{TransactionID: privateTxIDs[1], ReceiptType: components.RT_FailedWithMessage, FailureMessage: "failed"},
+		{TransactionID: privateTxIDs[2], ReceiptType: components.RT_FailedWithMessage, FailureMessage: "failed"},
+		{TransactionID: publicTxID, ReceiptType: components.RT_FailedWithMessage, FailureMessage: "failed"},
+	})
+	require.NoError(t, err)
+
+	var stats *pldapi.PrivateTransactionStats
+	err = rpcClient.CallRPC(ctx, &stats, "debug_getPrivateTxStats", "")
+	require.NoError(t, err)
+	assert.Equal(t, &pldapi.PrivateTransactionStats{
+		Window:                 "1h0m0s",
+		InFlight:               3,
+		ByStatus:               map[string]int{"assembled": 2, "dispatched": 1},
+		AverageAssemblyLatency: "1.5s",
+		DelegatedIn:            3,
+		DelegatedOut:           1,
+		Submitted:              4,
+		Succeeded:              1,
+		Failed:                 2,
+		FailureRate:            float64(2) / float64(3),
+	}, stats)
+
+	// nothing in persisted history falls within a window that has only just started
+	time.Sleep(1 * time.Millisecond)
+	err = rpcClient.CallRPC(ctx, &stats, "debug_getPrivateTxStats", "1ms")
+	require.NoError(t, err)
+	assert.Equal(t, "1ms", stats.Window)
+	assert.Zero(t, stats.Submitted)
+	assert.Zero(t, stats.DelegatedOut)
+	assert.Zero(t, stats.Succeeded+stats.Failed)
+	assert.Zero(t, stats.FailureRate)
+	assert.Equal(t, 3, stats.InFlight)
+
+	err = rpcClient.CallRPC(ctx, &stats, "debug_getPrivateTxStats", "-1h")
+	assert.Regexp(t, "PD012237", err)
+
+}
+
+func TestDebugABIStats(t *testing.T) {
+
+	ctx, url, _, done := newTestTransactionManagerWithRPC(t)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	var hash tktypes.Bytes32
+	err = rpcClient.CallRPC(ctx, &hash, "ptx_storeABI", abi.ABI{{Type: abi.Function, Name: "get"}})
+	require.NoError(t, err)
+
+	var stats *pldapi.ABIStorageStats
+	err = rpcClient.CallRPC(ctx, &stats, "debug_getABIStats")
+	require.NoError(t, err)
+	assert.True(t, stats.AutoStore)
+	assert.Equal(t, int64(1), stats.Stored)
+	assert.Equal(t, int64(1), stats.NewlyStored)
+
+}
+
+func TestCancelPrivateTransaction(t *testing.T) {
+
+	senderAddr := tktypes.RandAddress()
+	contractAddr := tktypes.RandAddress()
+	ctx, url, tmr, done := newTestTransactionManagerWithRPC(t,
+		mockPublicSubmitTxOkOrReject(t),
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.privateTxMgr.On("HandleNewTx", mock.Anything, mock.Anything).Return(nil)
+			mc.privateTxMgr.On("CancelTransaction", mock.Anything, contractAddr.String(), mock.Anything).Return(nil).Once()
+			mc.privateTxMgr.On("CancelTransaction", mock.Anything, contractAddr.String(), mock.Anything).Return(fmt.Errorf("PD011849: already dispatched")).Once()
+			mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+				Return([]*tktypes.EthAddress{senderAddr}, nil)
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	sendTx := func(txType pldapi.TransactionType) uuid.UUID {
+		var txID uuid.UUID
+		err := rpcClient.CallRPC(ctx, &txID, "ptx_sendTransaction", &pldapi.TransactionInput{
+			ABI: exampleABI,
+			TransactionBase: pldapi.TransactionBase{
+				From:     "sender1",
+				Type:     txType.Enum(),
+				Domain:   "domain1",
+				Function: "doIt",
+				To:       contractAddr,
+				Data:     tktypes.RawJSON(`[]`),
+			},
+		})
+		require.NoError(t, err)
+		return txID
+	}
+	var cancelled bool
+
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", uuid.New())
+	assert.Regexp(t, "PD012238", err)
+
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", sendTx(pldapi.TransactionTypePublic))
+	assert.Regexp(t, "PD012239", err)
+
+	// in flight, so passed to the private transaction manager
+	txID := sendTx(pldapi.TransactionTypePrivate)
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", txID)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", sendTx(pldapi.TransactionTypePrivate))
+	assert.Regexp(t, "PD011849", err)
+
+	// once the cancellation is finalized, cancelling again succeeds without going to the private transaction manager
+	successTxID := sendTx(pldapi.TransactionTypePrivate)
+	err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
+		{TransactionID: txID, ReceiptType: components.RT_FailedWithMessage, FailureMessage: "PD011853: Transaction cancelled"},
+		{TransactionID: successTxID, ReceiptType: components.RT_Success},
+	})
+	require.NoError(t, err)
+	cancelled = false
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", txID)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	err = rpcClient.CallRPC(ctx, &cancelled, "ptx_cancelPrivateTransaction", successTxID)
+	assert.Regexp(t, "PD012240", err)
+
+}
+
+func TestDebugExportTransactionTrace(t *testing.T) {
+
+	senderAddr := tktypes.RandAddress()
+	var publicTxns map[uuid.UUID][]*pldapi.PublicTx
+	ctx, url, tmr, done := newTestTransactionManagerWithRPC(t,
+		mockPublicSubmitTxOkOrReject(t),
+		mockQueryPublicTxForTransactions(func(ids []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error) {
+			return publicTxns, nil
+		}),
+		func(tmc *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+				Return([]*tktypes.EthAddress{senderAddr}, nil)
+		},
+	)
+	defer done()
+
+	rpcClient, err := rpcclient.NewHTTPClient(ctx, &pldconf.HTTPClientConfig{URL: url})
+	require.NoError(t, err)
+
+	// Not found is null
+	var trace *pldapi.TransactionTrace
+	err = rpcClient.CallRPC(ctx, &trace, "debug_exportTransactionTrace", uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, trace)
+
+	var txID uuid.UUID
+	err = rpcClient.CallRPC(ctx, &txID, "ptx_sendTransaction", &pldapi.TransactionInput{
+		ABI: abi.ABI{{Type: abi.Function, Name: "set", Inputs: abi.ParameterArray{{Type: "uint256"}}}},
+		TransactionBase: pldapi.TransactionBase{
+			From:     "sender1",
+			To:       tktypes.RandAddress(),
+			Function: "set",
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			Data:     tktypes.RawJSON(`[12345]`),
+		},
+	})
+	require.NoError(t, err)
+	tx, err := tmr.GetTransactionByID(ctx, txID)
+	require.NoError(t, err)
+
+	// Mock up a public transaction that was re-submitted with a higher gas price, then confirmed
+	t0 := tx.Created
+	txHash1 := tktypes.Bytes32(tktypes.RandBytes(32))
+	txHash2 := tktypes.Bytes32(tktypes.RandBytes(32))
+	publicTxns = map[uuid.UUID][]*pldapi.PublicTx{
+		txID: {
+			{
+				From:            *senderAddr,
+				Nonce:           12345,
+				Created:         t0 + 1,
+				CompletedAt:     confutil.P(t0 + 5),
+				TransactionHash: &txHash2,
+				Success:         confutil.P(true),
+				Submissions: []*pldapi.PublicTxSubmissionData{
+					{Time: t0 + 4, TransactionHash: txHash2, PublicTxGasPricing: pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(200)}},
+					{Time: t0 + 2, TransactionHash: txHash1, PublicTxGasPricing: pldapi.PublicTxGasPricing{GasPrice: tktypes.Uint64ToUint256(100)}},
+				},
+				Activity: []pldapi.TransactionActivityRecord{
+					{Time: t0 + 3, Message: "gas price increased"},
+				},
+			},
+		},
+	}
+
+	err = tmr.FinalizeTransactions(ctx, tmr.p.DB(), []*components.ReceiptInput{
+		{
+			TransactionID: txID,
+			ReceiptType:   components.RT_Success,
+			OnChain: tktypes.OnChainLocation{
+				Type:            tktypes.OnChainTransaction,
+				TransactionHash: txHash2,
+				BlockNumber:     12345,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = rpcClient.CallRPC(ctx, &trace, "debug_exportTransactionTrace", txID)
+	require.NoError(t, err)
+	require.NotNil(t, trace)
+	assert.Equal(t, txID, *trace.Transaction.ID)
+	require.NotNil(t, trace.Receipt)
+	assert.True(t, trace.Receipt.Success)
+	assert.Nil(t, trace.PrivateState)
+
+	eventTypes := make([]pldapi.TransactionTraceEventType, len(trace.Events))
+	for i, e := range trace.Events {
+		eventTypes[i] = e.Type
+	}
+	assert.Equal(t, []pldapi.TransactionTraceEventType{
+		pldapi.TransactionTraceEventCreated,
+		pldapi.TransactionTraceEventPublicCreated,
+		pldapi.TransactionTraceEventSubmitted,
+		pldapi.TransactionTraceEventActivity,
+		pldapi.TransactionTraceEventSubmitted,
+		pldapi.TransactionTraceEventGasPriceChanged,
+		pldapi.TransactionTraceEventPublicCompleted,
+		pldapi.TransactionTraceEventReceiptFinalized,
+	}, eventTypes)
+	assert.Equal(t, txHash1.String(), trace.Events[2].Message)
+	assert.Equal(t, "gas price increased", trace.Events[3].Message)
+	assert.Equal(t, uint64(12345), trace.Events[5].PublicTx.Nonce.Uint64())
+	assert.JSONEq(t, `{"previous":{"gasPrice":"0x64"},"new":{"gasPrice":"0xc8"}}`, tktypes.JSONString(trace.Events[5].Data).String())
+
+}
+
+func TestDebugExportTransactionTracePrivate(t *testing.T) {
+
+	contractAddress := tktypes.RandAddress()
+	var privateTxMgr *componentmocks.PrivateTxManager
+	ctx, txm, done := newTestTransactionManager(t, true,
+		mockQueryPublicTxForTransactions(func(ids []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error) {
+			return nil, nil
+		}),
+		func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+			privateTxMgr = mc.privateTxMgr
+			privateTxMgr.On("HandleNewTx", mock.Anything, mock.Anything).Return(nil)
+		},
+	)
+	defer done()
+
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	callData, err := exampleABI[0].EncodeCallDataJSON([]byte(`[]`))
+	require.NoError(t, err)
+
+	txID, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			From:     "me",
+			Type:     pldapi.TransactionTypePrivate.Enum(),
+			Domain:   "domain1",
+			Function: "doIt",
+			To:       contractAddress,
+			Data:     tktypes.JSONString(tktypes.HexBytes(callData)),
+		},
+		ABI: exampleABI,
+	})
+	require.NoError(t, err)
+	tx, err := txm.GetTransactionByID(ctx, *txID)
+	require.NoError(t, err)
+
+	// The transaction has been assembled and is now endorsing
+	t0 := tx.Created
+	privateTxMgr.On("GetTxStatus", mock.Anything, contractAddress.String(), txID.String()).Return(components.PrivateTxStatus{
+		TxID:   txID.String(),
+		Status: "endorsing",
+		StageTimings: []*components.PrivateTxStageTiming{
+			{Stage: components.PrivateTxStageAssembling, Entered: t0 + 1, Exited: confutil.P(t0 + 2)},
+			{Stage: components.PrivateTxStageEndorsing, Entered: t0 + 2},
+		},
+	}, nil)
+	privateTxMgr.On("GetTxAttestations", mock.Anything, contractAddress.String(), txID.String()).Return(&components.PrivateTxAttestations{
+		TxID: txID.String(),
+		Attestations: []*components.PrivateTxAttestation{
+			{Name: "notary", AttestationType: "endorse", Parties: []*components.PrivateTxAttestationParty{
+				{Party: "notary@node1", Received: true, Result: "ENDORSER_SUBMIT"},
+			}},
+		},
+	}, nil)
+
+	trace, err := txm.ExportTransactionTrace(ctx, *txID)
+	require.NoError(t, err)
+	require.NotNil(t, trace)
+	assert.Equal(t, "endorsing", trace.PrivateState.Status)
+
+	eventTypes := make([]pldapi.TransactionTraceEventType, len(trace.Events))
+	for i, e := range trace.Events {
+		eventTypes[i] = e.Type
+	}
+	assert.Equal(t, []pldapi.TransactionTraceEventType{
+		pldapi.TransactionTraceEventCreated,
+		pldapi.TransactionTraceEventStageEntered,
+		pldapi.TransactionTraceEventStageExited,
+		pldapi.TransactionTraceEventStageEntered,
+	}, eventTypes)
+	assert.Equal(t, "assembling", trace.Events[1].Message)
+	assert.Equal(t, "endorsing", trace.Events[3].Message)
+	assert.JSONEq(t, `[{"name":"notary","attestationType":"endorse","algorithm":"","verifierType":"","parties":[{"party":"notary@node1","received":true,"result":"ENDORSER_SUBMIT"}]}]`,
+		tktypes.JSONString(trace.Attestations).String())
+
+}
+
 func TestQueryPreparedTransactionsNotFound(t *testing.T) {
 
 	ctx, url, _, done := newTestTransactionManagerWithRPC(t)
@@ -595,3 +1205,35 @@ func TestPrepareTransactions(t *testing.T) {
 	require.Equal(t, pldapi.SubmitModeExternal, returnedTX.SubmitMode.V())
 
 }
+
+func TestExportTransactionTraceReceiptRemoved(t *testing.T) {
+
+	txID := uuid.New()
+	ctx, txm, done := newTestTransactionManager(t, false,
+		mockQueryPublicTxForTransactions(func(ids []uuid.UUID, jq *query.QueryJSON) (map[uuid.UUID][]*pldapi.PublicTx, error) {
+			return nil, nil
+		}),
+		func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+			mc.db.ExpectBegin()
+			mc.db.ExpectQuery("SELECT.*transactions").WillReturnRows(sqlmock.NewRows(
+				[]string{"id", "type", "created", "from", "TransactionReceipt__transaction", "TransactionReceipt__success"},
+			).AddRow(txID.String(), pldapi.TransactionTypePublic.Enum(), 1000, "sender1", txID.String(), true))
+			mc.db.ExpectQuery("SELECT.*transaction_deps").WillReturnRows(sqlmock.NewRows([]string{}))
+			mc.db.ExpectCommit()
+			mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnRows(sqlmock.NewRows(
+				[]string{"transaction", "success"},
+			).AddRow(txID.String(), true))
+			// The receipt has gone by the time we read its indexed time
+			mc.db.ExpectQuery("SELECT.*transaction_receipts").WillReturnRows(sqlmock.NewRows([]string{}))
+		},
+	)
+	defer done()
+
+	trace, err := txm.ExportTransactionTrace(ctx, txID)
+	require.NoError(t, err)
+	require.NotNil(t, trace)
+	assert.True(t, trace.Receipt.Success)
+	require.Len(t, trace.Events, 1)
+	assert.Equal(t, pldapi.TransactionTraceEventCreated, trace.Events[0].Type)
+
+}
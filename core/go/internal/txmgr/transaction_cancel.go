@@ -0,0 +1,55 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+)
+
+// CancelPrivateTransaction stops a private transaction that has not yet been dispatched to the base ledger,
+// and finalizes it with a failure receipt. Calling it again once that receipt has been written succeeds.
+func (tm *txManager) CancelPrivateTransaction(ctx context.Context, id uuid.UUID) error {
+	tx, err := tm.GetTransactionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return i18n.NewError(ctx, msgs.MsgTxMgrCancelNotFound, id)
+	}
+	if tx.Type.V() != pldapi.TransactionTypePrivate || tx.To == nil {
+		return i18n.NewError(ctx, msgs.MsgTxMgrCancelNotPrivate, id)
+	}
+
+	receipt, err := tm.GetTransactionReceiptByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if receipt != nil {
+		if !receipt.Success && strings.HasPrefix(receipt.FailureMessage, string(msgs.MsgPrivateTxManagerTransactionCancelled)) {
+			// already cancelled
+			return nil
+		}
+		return i18n.NewError(ctx, msgs.MsgTxMgrCancelAlreadyFinalized, id)
+	}
+
+	return tm.privateTxMgr.CancelTransaction(ctx, tx.To.String(), id)
+}
@@ -36,13 +36,17 @@ var transactionFilters = filters.FieldMap{
 	"domain":         filters.StringField("domain"),
 	"from":           filters.StringField("from"),
 	"to":             filters.HexBytesField("to"),
+	"coordinator":    filters.StringField("coordinator"),
+	"label":          filters.StringField("label"),
 }
 
 func mapPersistedTXBase(pt *persistedTransaction) *pldapi.Transaction {
 	res := &pldapi.Transaction{
-		ID:         &pt.ID,
-		Created:    pt.Created,
-		SubmitMode: pt.SubmitMode,
+		ID:          &pt.ID,
+		Created:     pt.Created,
+		SubmitMode:  pt.SubmitMode,
+		Coordinator: stringOrEmpty(pt.Coordinator),
+		Label:       stringOrEmpty(pt.Label),
 		TransactionBase: pldapi.TransactionBase{
 			IdempotencyKey: stringOrEmpty(pt.IdempotencyKey),
 			Type:           pt.Type,
@@ -202,6 +206,13 @@ func (tm *txManager) queryPublicTransactions(ctx context.Context, jq *query.Quer
 	return tm.publicTxMgr.QueryPublicTxWithBindings(ctx, tm.p.DB(), jq)
 }
 
+func (tm *txManager) queryRejectedPublicTransactions(ctx context.Context, jq *query.QueryJSON) ([]*pldapi.PublicTxRejection, error) {
+	if err := checkLimitSet(ctx, jq); err != nil {
+		return nil, err
+	}
+	return tm.publicTxMgr.QueryPublicTxRejections(ctx, tm.p.DB(), jq)
+}
+
 func (tm *txManager) GetPublicTransactionByNonce(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64) (*pldapi.PublicTxWithBinding, error) {
 	prs, err := tm.publicTxMgr.QueryPublicTxWithBindings(ctx, tm.p.DB(),
 		query.NewQueryBuilder().Limit(1).
@@ -214,6 +225,45 @@ func (tm *txManager) GetPublicTransactionByNonce(ctx context.Context, from tktyp
 	return prs[0], nil
 }
 
+// Returns every public transaction submitted on behalf of the given Paladin transaction, in the order they were created.
+// There can be more than one, such as when a transaction is resubmitted after running out of gas.
+func (tm *txManager) GetPublicTransactionNonces(ctx context.Context, id uuid.UUID) ([]*pldapi.PublicTxNonceMapping, error) {
+	prs, err := tm.publicTxMgr.QueryPublicTxWithBindings(ctx, tm.p.DB(),
+		query.NewQueryBuilder().
+			Equal("transaction", id).
+			Sort("created").
+			Query())
+	if err != nil {
+		return nil, err
+	}
+	mappings := make([]*pldapi.PublicTxNonceMapping, len(prs))
+	for i, pr := range prs {
+		mappings[i] = &pldapi.PublicTxNonceMapping{
+			PublicTxBinding: pr.PublicTxBinding,
+			From:            pr.From,
+			Nonce:           pr.Nonce,
+			TransactionHash: pr.TransactionHash,
+		}
+	}
+	return mappings, nil
+}
+
+func (tm *txManager) ResubmitAllPublicTransactionsForAddress(ctx context.Context, from tktypes.EthAddress) ([]tktypes.HexUint64, error) {
+	nonces, err := tm.publicTxMgr.ResubmitAllForAddress(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	resubmitted := make([]tktypes.HexUint64, len(nonces))
+	for i, nonce := range nonces {
+		resubmitted[i] = tktypes.HexUint64(nonce)
+	}
+	return resubmitted, nil
+}
+
+func (tm *txManager) GetPublicTransactionGasHistory(ctx context.Context, from tktypes.EthAddress, nonce tktypes.HexUint64) ([]*pldapi.PublicTxSubmissionData, error) {
+	return tm.publicTxMgr.GetPublicTransactionSubmissions(ctx, tm.p.DB(), from, nonce.Uint64())
+}
+
 func (tm *txManager) GetPublicTransactionByHash(ctx context.Context, hash tktypes.Bytes32) (*pldapi.PublicTxWithBinding, error) {
 	return tm.publicTxMgr.GetPublicTransactionForHash(ctx, tm.p.DB(), hash)
 }
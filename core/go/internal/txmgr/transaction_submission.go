@@ -52,6 +52,8 @@ type persistedTransaction struct {
 	From               string                               `gorm:"column:from"`
 	To                 *tktypes.EthAddress                  `gorm:"column:to"`
 	Data               tktypes.RawJSON                      `gorm:"column:data"` // we always store in JSON object format
+	Coordinator        *string                              `gorm:"column:coordinator"`
+	Label              *string                              `gorm:"column:label"`
 	TransactionDeps    []*transactionDep                    `gorm:"foreignKey:transaction;references:id"`
 	TransactionReceipt *transactionReceipt                  `gorm:"foreignKey:transaction;references:id"`
 }
@@ -71,7 +73,7 @@ var defaultConstructorSignature = func() string {
 	return sig
 }()
 
-func (tm *txManager) resolveFunction(ctx context.Context, dbTX *gorm.DB, inputABI abi.ABI, inputABIRef *tktypes.Bytes32, requiredFunction string, to *tktypes.EthAddress) (_ *components.ResolvedFunction, err error) {
+func (tm *txManager) resolveFunction(ctx context.Context, dbTX *gorm.DB, inputABI abi.ABI, inputABIRef *tktypes.Bytes32, requiredFunction string, to *tktypes.EthAddress, storeABI bool) (_ *components.ResolvedFunction, err error) {
 
 	// Lookup the ABI we're working with.
 	// Only needs to contain the function definition we're calling, but can be the whole ABI of the contract.
@@ -92,7 +94,11 @@ func (tm *txManager) resolveFunction(ctx context.Context, dbTX *gorm.DB, inputAB
 			// (we need something to hash to an abiReference in all cases)
 			inputABI = abi.ABI{defaultConstructor}
 		}
-		pa, err = tm.UpsertABI(ctx, dbTX, inputABI)
+		if storeABI {
+			pa, err = tm.UpsertABI(ctx, dbTX, inputABI)
+		} else {
+			pa, err = tm.resolveInlineABI(ctx, inputABI)
+		}
 	}
 	if err != nil || pa == nil {
 		return nil, i18n.WrapError(ctx, err, msgs.MsgTxMgrABIReferenceLookupFailed, inputABIRef)
@@ -214,7 +220,7 @@ func (tm *txManager) parseInputs(
 func (tm *txManager) SendTransaction(ctx context.Context, tx *pldapi.TransactionInput) (*uuid.UUID, error) {
 	// TODO: Add flush writer for parallel performance here, that calls sendTransactions
 	// in the flush writer on the batch (rather than doing a DB commit per TX)
-	txIDs, err := tm.SendTransactions(ctx, []*pldapi.TransactionInput{tx})
+	txIDs, err := tm.processNewTransactions(ctx, []*pldapi.TransactionInput{tx}, pldapi.SubmitModeAuto, false)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +228,7 @@ func (tm *txManager) SendTransaction(ctx context.Context, tx *pldapi.Transaction
 }
 
 func (tm *txManager) PrepareTransaction(ctx context.Context, tx *pldapi.TransactionInput) (*uuid.UUID, error) {
-	txIDs, err := tm.PrepareTransactions(ctx, []*pldapi.TransactionInput{tx})
+	txIDs, err := tm.processNewTransactions(ctx, []*pldapi.TransactionInput{tx}, pldapi.SubmitModeExternal, false)
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +277,32 @@ func (tm *txManager) CallTransaction(ctx context.Context, result any, call *plda
 	return err
 }
 
+// EstimateGasForPrivateTransaction dry-runs a private transaction through the private transaction manager, without
+// persisting or dispatching it, to estimate the gas of the public transaction it would result in.
+func (tm *txManager) EstimateGasForPrivateTransaction(ctx context.Context, tx *pldapi.TransactionInput) (tktypes.HexUint64, error) {
+	if tx.Type.V() != pldapi.TransactionTypePrivate || tx.To == nil {
+		return 0, i18n.NewError(ctx, msgs.MsgTxMgrEstimateNotPrivateInvoke)
+	}
+	if tx.From == "" {
+		// Unlike a call, the transaction must have a sender to be assembled
+		return 0, i18n.NewError(ctx, msgs.MsgTxMgrPublicSenderNotValidLocal, tx.From)
+	}
+
+	// The ABI is treated as for a call, so is only stored if auto-storage is enabled
+	txi, err := tm.resolveNewTransaction(ctx, tm.p.DB(), tx, pldapi.SubmitModeCall)
+	if err != nil {
+		return 0, err
+	}
+
+	return tm.privateTxMgr.EstimatePrivateTransactionGas(ctx, &components.TransactionInputs{
+		Domain:   tx.Domain,
+		From:     tx.From,
+		To:       *tx.To,
+		Function: txi.Function.Definition,
+		Inputs:   txi.Inputs,
+	}, tx.PublicTxOptions)
+}
+
 func (tm *txManager) callTransactionPublic(ctx context.Context, result any, call *pldapi.TransactionCall, txi *components.ValidatedTransaction, serializer *abi.Serializer) (err error) {
 
 	ec := tm.ethClientFactory.HTTPClient().(ethclient.EthClientWithKeyManager)
@@ -355,30 +387,87 @@ func (tm *txManager) UpsertInternalPrivateTxsFinalizeIDs(ctx context.Context, db
 	return nil
 }
 
+// Records the node coordinating each of the given private transactions.
+// Only transactions submitted to this node have a record here - so IDs of transactions delegated
+// to this node by others are ignored.
+func (tm *txManager) SetTransactionCoordinator(ctx context.Context, dbTX *gorm.DB, coordinator string, txIDs []uuid.UUID) error {
+	if len(txIDs) == 0 {
+		return nil
+	}
+	return dbTX.
+		WithContext(ctx).
+		Table("transactions").
+		Where("id IN (?)", txIDs).
+		Update("coordinator", coordinator).
+		Error
+}
+
+// Records the human-readable label supplied by the domain for a private transaction.
+// As with the coordinator, transactions delegated to this node by others have no record here so are ignored.
+func (tm *txManager) SetTransactionLabel(ctx context.Context, dbTX *gorm.DB, txID uuid.UUID, label string) error {
+	return dbTX.
+		WithContext(ctx).
+		Table("transactions").
+		Where("id = ?", txID).
+		Update("label", label).
+		Error
+}
+
+// On the batch paths an idempotency key that is already used resolves to the existing transaction ID,
+// rather than failing the whole batch, so a client retrying a partially applied batch converges
 func (tm *txManager) SendTransactions(ctx context.Context, txs []*pldapi.TransactionInput) (txIDs []uuid.UUID, err error) {
-	return tm.processNewTransactions(ctx, txs, pldapi.SubmitModeAuto)
+	return tm.processNewTransactionsResolveExisting(ctx, txs, pldapi.SubmitModeAuto)
 }
 
 func (tm *txManager) PrepareTransactions(ctx context.Context, txs []*pldapi.TransactionInput) (txIDs []uuid.UUID, err error) {
-	return tm.processNewTransactions(ctx, txs, pldapi.SubmitModeExternal)
+	return tm.processNewTransactionsResolveExisting(ctx, txs, pldapi.SubmitModeExternal)
 }
 
-func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.TransactionInput, submitMode pldapi.SubmitMode) (txIDs []uuid.UUID, err error) {
+// A concurrent retry of the same batch can insert an idempotency key between us looking it up, and inserting it.
+// In that case the whole batch has rolled back, so we resolve each input again against what is now in the DB.
+func (tm *txManager) processNewTransactionsResolveExisting(ctx context.Context, txs []*pldapi.TransactionInput, submitMode pldapi.SubmitMode) (txIDs []uuid.UUID, err error) {
+	txIDs, err = tm.processNewTransactions(ctx, txs, submitMode, true)
+	if ffErr, ok := err.(i18n.FFError); ok && ffErr.MessageKey() == msgs.MsgTxMgrIdempotencyKeyClash {
+		log.L(ctx).Warnf("idempotencyKey inserted concurrently - resolving batch again: %s", err)
+		txIDs, err = tm.processNewTransactions(ctx, txs, submitMode, true)
+	}
+	return txIDs, err
+}
+
+func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.TransactionInput, submitMode pldapi.SubmitMode, resolveExisting bool) (txIDs []uuid.UUID, err error) {
+
+	var existingIDs map[string]uuid.UUID
+	if resolveExisting {
+		if existingIDs, err = tm.getIdempotencyKeyIDs(ctx, txs); err != nil {
+			return nil, err
+		}
+	}
 
 	// Public transactions need a signing address resolution and nonce allocation trackers
 	// before we open the database transaction
 	var publicTxs []*components.PublicTxSubmission
 	var publicTxSenders []string
-	txis := make([]*components.ValidatedTransaction, len(txs))
+	txis := make([]*components.ValidatedTransaction, 0, len(txs))
+	newTxs := make([]*pldapi.TransactionInput, 0, len(txs))
 	txIDs = make([]uuid.UUID, len(txs))
 	for i, tx := range txs {
+		if existingID, ok := existingIDs[tx.IdempotencyKey]; ok && tx.IdempotencyKey != "" {
+			log.L(ctx).Infof("matched existing idempotencyKey=%s txID=%s", tx.IdempotencyKey, existingID)
+			txIDs[i] = existingID
+			continue
+		}
 		txi, err := tm.resolveNewTransaction(ctx, tm.p.DB() /* no db tx for this part currently */, tx, submitMode)
 		if err != nil {
 			return nil, err
 		}
 		txID := *txi.Transaction.ID
-		txis[i] = txi
+		txis = append(txis, txi)
+		newTxs = append(newTxs, tx)
 		txIDs[i] = txID
+		if existingIDs != nil && tx.IdempotencyKey != "" {
+			// a repeat of the key later in the same batch resolves to this transaction
+			existingIDs[tx.IdempotencyKey] = txID
+		}
 		if tx.Type.V() == pldapi.TransactionTypePublic {
 			publicTxs = append(publicTxs, &components.PublicTxSubmission{
 				// Public transaction bound 1:1 with our parent transaction
@@ -388,11 +477,16 @@ func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.T
 					Data:            txi.PublicTxData,
 					PublicTxOptions: tx.PublicTxOptions,
 				},
+				SignedTransaction: tx.SignedTransaction,
 			})
 			publicTxSenders = append(publicTxSenders, txi.LocalFrom)
 		}
 	}
 
+	if len(txis) == 0 {
+		return txIDs, nil
+	}
+
 	// Need to resolve the addresses for any public senders
 	if len(publicTxs) > 0 {
 		ethAddresses, err := tm.keyManager.ResolveEthAddressBatchNewDatabaseTX(ctx, publicTxSenders)
@@ -418,6 +512,11 @@ func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.T
 		}()
 		// TODO: don't support partial rejection currently - will be important when we introduce the flush writer
 		if len(publicBatch.Rejected()) > 0 {
+			if err := tm.p.DB().Transaction(func(dbTX *gorm.DB) error {
+				return publicBatch.PersistRejected(ctx, dbTX)
+			}); err != nil {
+				return nil, err
+			}
 			return nil, publicBatch.Rejected()[0].RejectedError()
 		}
 	}
@@ -434,7 +533,8 @@ func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.T
 		return err
 	})
 	if err != nil {
-		return nil, tm.checkIdempotencyKeys(ctx, err, insertedOK, txs)
+		// only the keys we tried to insert can have clashed - not those already resolved to existing transactions
+		return nil, tm.checkIdempotencyKeys(ctx, err, insertedOK, newTxs)
 	}
 	// From this point on we're committed, and need to tell the public tx manager as such
 	committed = true
@@ -451,6 +551,35 @@ func (tm *txManager) processNewTransactions(ctx context.Context, txs []*pldapi.T
 	return txIDs, err
 }
 
+func (tm *txManager) getIdempotencyKeyIDs(ctx context.Context, txs []*pldapi.TransactionInput) (map[string]uuid.UUID, error) {
+	existingIDs := make(map[string]uuid.UUID)
+	idempotencyKeys := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		if tx.IdempotencyKey != "" {
+			idempotencyKeys = append(idempotencyKeys, tx.IdempotencyKey)
+		}
+	}
+	if len(idempotencyKeys) == 0 {
+		return existingIDs, nil
+	}
+	var txsInDB []*persistedTransaction
+	err := tm.p.DB().
+		WithContext(ctx).
+		Select("id", "idempotency_key").
+		Where("idempotency_key in (?)", idempotencyKeys).
+		Find(&txsInDB).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	for _, txInDB := range txsInDB {
+		if txInDB.IdempotencyKey != nil {
+			existingIDs[*txInDB.IdempotencyKey] = txInDB.ID
+		}
+	}
+	return existingIDs, nil
+}
+
 // Will either return the original error, or will return a special idempotency key error that can be used by the caller
 // to determine that they need to ask for the existing transactions (rather than fail)
 func (tm *txManager) checkIdempotencyKeys(ctx context.Context, origErr error, insertedOK bool, txis []*pldapi.TransactionInput) error {
@@ -476,11 +605,36 @@ func (tm *txManager) checkIdempotencyKeys(ctx context.Context, origErr error, in
 	return origErr
 }
 
+// A key path override signs a public transaction with a dedicated key derived beneath the sender's
+// identifier in the key hierarchy (such as a hot key), instead of the sender's own key.
+// The node must have a policy configured that allows the path.
+func (tm *txManager) applyKeyPathOverride(ctx context.Context, tx *pldapi.TransactionInput, localFrom string) (string, error) {
+	if tx.Type.V() != pldapi.TransactionTypePublic {
+		return "", i18n.NewError(ctx, msgs.MsgTxMgrKeyPathOverridePublicOnly)
+	}
+	if tm.keyPathOverrides == nil || !tm.keyPathOverrides.MatchString(tx.KeyPathOverride) {
+		return "", i18n.NewError(ctx, msgs.MsgTxMgrKeyPathOverrideNotAllowed, tx.KeyPathOverride)
+	}
+	return localFrom + "." + tx.KeyPathOverride, nil
+}
+
 func (tm *txManager) resolveNewTransaction(ctx context.Context, dbTX *gorm.DB, tx *pldapi.TransactionInput, submitMode pldapi.SubmitMode) (*components.ValidatedTransaction, error) {
 	txID := uuid.New()
 
+	if len(tx.DependsOn) > tm.maxDependencies {
+		return nil, i18n.NewError(ctx, msgs.MsgTxMgrTooManyDependencies, len(tx.DependsOn), tm.maxDependencies)
+	}
+
+	if tx.SignedTransaction != nil && (tx.Type.V() != pldapi.TransactionTypePublic || submitMode != pldapi.SubmitModeAuto) {
+		return nil, i18n.NewError(ctx, msgs.MsgTxMgrSignedTransactionPublicOnly)
+	}
+
 	switch tx.Type.V() {
 	case pldapi.TransactionTypePrivate:
+		if tx.Nonce != nil {
+			// The public transactions of a private transaction are submitted by the sequencer, which assigns the nonces
+			return nil, i18n.NewError(ctx, msgs.MsgTxMgrNonceOverridePublicOnly)
+		}
 	case pldapi.TransactionTypePublic:
 		if submitMode == pldapi.SubmitModeExternal {
 			return nil, i18n.NewError(ctx, msgs.MsgTxMgrPrivateOnlyForPrepare)
@@ -494,7 +648,10 @@ func (tm *txManager) resolveNewTransaction(ctx context.Context, dbTX *gorm.DB, t
 	// and needs to happen before we open the DB transaction that is used by the public TX manager.
 	// Note there is only a DB cost for read if we haven't cached the function, and there
 	// is only a DB cost for write, if it's the first time we've invoked the function.
-	fn, err := tm.resolveFunction(ctx, dbTX, tx.ABI, tx.ABIReference, tx.Function, tx.To)
+	// Transactions are persisted with a reference to their ABI, so it must be stored,
+	// but the ABI for a call is only stored if auto-storage is enabled
+	storeABI := submitMode != pldapi.SubmitModeCall || tm.abiAutoStore
+	fn, err := tm.resolveFunction(ctx, dbTX, tx.ABI, tx.ABIReference, tx.Function, tx.To, storeABI)
 	if err != nil {
 		return nil, err
 	}
@@ -521,6 +678,12 @@ func (tm *txManager) resolveNewTransaction(ctx context.Context, dbTX *gorm.DB, t
 		tx.From = fmt.Sprintf("%s@%s", identifier, node)
 	}
 
+	if tx.KeyPathOverride != "" {
+		if localFrom, err = tm.applyKeyPathOverride(ctx, tx, localFrom); err != nil {
+			return nil, err
+		}
+	}
+
 	return &components.ValidatedTransaction{
 		LocalFrom: localFrom,
 		Transaction: &pldapi.Transaction{
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -589,8 +590,11 @@ func TestInsertTransactionPublicTxPrepareFail(t *testing.T) {
 		mockSubmissionBatch.On("Rejected").Return([]components.PublicTxRejected{
 			rejectedSubmission,
 		})
+		mockSubmissionBatch.On("PersistRejected", mock.Anything, mock.Anything).Return(nil)
 		mockSubmissionBatch.On("Completed", mock.Anything, false).Return(nil)
 		mc.publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.Anything).Return(mockSubmissionBatch, nil)
+		mc.db.ExpectBegin()
+		mc.db.ExpectCommit()
 
 		mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
 			Return([]*tktypes.EthAddress{tktypes.RandAddress()}, nil)
@@ -653,6 +657,127 @@ func TestInsertTransactionOkDefaultConstructor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSendTransactionKeyPathOverride(t *testing.T) {
+	defaultAddr := tktypes.RandAddress()
+	overrideAddr := tktypes.RandAddress()
+	ctx, txm, done := newTestTransactionManager(t, false, mockInsertABI, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		conf.AllowedKeyPathOverrides = confutil.P(`hot\.[0-9]+`)
+		mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+			Return([]*tktypes.EthAddress{defaultAddr}, nil).Maybe()
+		mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1.hot.1"}).
+			Return([]*tktypes.EthAddress{overrideAddr}, nil)
+		mc.publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.MatchedBy(func(ptxs []*components.PublicTxSubmission) bool {
+			return len(ptxs) == 1 && ptxs[0].From.Equals(overrideAddr)
+		})).Return(nil, fmt.Errorf("pop"))
+	})
+	defer done()
+
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	tx := &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			Function: "doIt",
+			From:     "sender1",
+			To:       tktypes.RandAddress(),
+			Data:     tktypes.RawJSON(`[]`),
+		},
+		ABI:             exampleABI,
+		KeyPathOverride: "hot.1",
+	}
+
+	// The override key is the one passed for submission
+	_, err := txm.SendTransaction(ctx, tx)
+	assert.Regexp(t, "pop", err)
+
+	// Rejected by policy
+	tx.KeyPathOverride = "cold.1"
+	_, err = txm.SendTransaction(ctx, tx)
+	assert.Regexp(t, "PD012233", err)
+
+	// The policy must match the whole key path
+	for _, keyPath := range []string{"cold.hot.1", "hot.1.cold"} {
+		tx.KeyPathOverride = keyPath
+		_, err = txm.SendTransaction(ctx, tx)
+		assert.Regexp(t, "PD012233", err)
+	}
+
+	// Not supported for private
+	tx.KeyPathOverride = "hot.1"
+	tx.Type = pldapi.TransactionTypePrivate.Enum()
+	_, err = txm.SendTransaction(ctx, tx)
+	assert.Regexp(t, "PD012234", err)
+}
+
+func TestSendTransactionNonceOverridePrivate(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	_, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePrivate.Enum(),
+			Function: "doIt",
+			From:     "sender1",
+			To:       tktypes.RandAddress(),
+			Data:     tktypes.RawJSON(`[]`),
+			PublicTxOptions: pldapi.PublicTxOptions{
+				Nonce: confutil.P(tktypes.HexUint64(10)),
+			},
+		},
+		ABI: abi.ABI{{Type: abi.Function, Name: "doIt"}},
+	})
+	assert.Regexp(t, "PD012242", err)
+}
+
+func TestSendTransactionSignedTransaction(t *testing.T) {
+	signedTx := tktypes.HexBytes(tktypes.RandBytes(32))
+	ctx, txm, done := newTestTransactionManager(t, false, mockInsertABI, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.keyManager.On("ResolveEthAddressBatchNewDatabaseTX", mock.Anything, []string{"sender1"}).
+			Return([]*tktypes.EthAddress{tktypes.RandAddress()}, nil)
+		mc.publicTxMgr.On("PrepareSubmissionBatch", mock.Anything, mock.MatchedBy(func(ptxs []*components.PublicTxSubmission) bool {
+			return len(ptxs) == 1 && ptxs[0].SignedTransaction.Equals(signedTx) && ptxs[0].Data != nil
+		})).Return(nil, fmt.Errorf("pop"))
+	})
+	defer done()
+
+	tx := &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			Function: "doIt",
+			From:     "sender1",
+			To:       tktypes.RandAddress(),
+			Data:     tktypes.RawJSON(`[]`),
+		},
+		ABI:               abi.ABI{{Type: abi.Function, Name: "doIt"}},
+		SignedTransaction: signedTx,
+	}
+
+	// The signed transaction is passed for submission, along with the data it must match
+	_, err := txm.SendTransaction(ctx, tx)
+	assert.Regexp(t, "pop", err)
+
+	// Not supported for prepare, or for private
+	_, err = txm.PrepareTransaction(ctx, tx)
+	assert.Regexp(t, "PD012243", err)
+	tx.Type = pldapi.TransactionTypePrivate.Enum()
+	_, err = txm.SendTransaction(ctx, tx)
+	assert.Regexp(t, "PD012243", err)
+}
+
+func TestSendTransactionKeyPathOverrideNoPolicy(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false, mockInsertABI)
+	defer done()
+
+	_, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type: pldapi.TransactionTypePublic.Enum(),
+			From: "sender1",
+		},
+		Bytecode:        tktypes.HexBytes(tktypes.RandBytes(1)),
+		KeyPathOverride: "hot.1",
+	})
+	assert.Regexp(t, "PD012233", err)
+}
+
 func TestCheckIdempotencyKeyNoOverrideErrIfFail(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
 		mc.db.ExpectQuery("SELECT.*transactions").WillReturnError(fmt.Errorf("crackle"))
@@ -855,6 +980,88 @@ func TestCallTransactionBadSerializer(t *testing.T) {
 
 }
 
+func TestEstimateGasForPrivateTransactionOk(t *testing.T) {
+	fnDef := &abi.Entry{Name: "transfer", Type: abi.Function,
+		Inputs: abi.ParameterArray{
+			{Name: "amount", Type: "uint256"},
+		},
+	}
+
+	ctx, txm, done := newTestTransactionManager(t, false, mockInsertABI, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.privateTxMgr.On("EstimatePrivateTransactionGas", mock.Anything, mock.MatchedBy(func(txi *components.TransactionInputs) bool {
+			return txi.Domain == "test1" && txi.From == "sender@node1" && txi.Function.Name == "transfer"
+		}), pldapi.PublicTxOptions{Gas: confutil.P(tktypes.HexUint64(50000))}).
+			Return(tktypes.HexUint64(12345), nil)
+	})
+	defer done()
+
+	tx := pldclient.New().ForABI(ctx, abi.ABI{fnDef}).
+		Function("transfer").
+		Private().
+		Domain("test1").
+		From("sender").
+		To(tktypes.RandAddress()).
+		Inputs(map[string]any{"amount": 100}).
+		PublicTxOptions(pldapi.PublicTxOptions{Gas: confutil.P(tktypes.HexUint64(50000))}).
+		BuildTX()
+	require.NoError(t, tx.Error())
+
+	gas, err := txm.EstimateGasForPrivateTransaction(ctx, tx.TX())
+	require.NoError(t, err)
+	assert.Equal(t, tktypes.HexUint64(12345), gas)
+
+}
+
+func TestEstimateGasForPrivateTransactionNotPrivateInvoke(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	_, err := txm.EstimateGasForPrivateTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type: pldapi.TransactionTypePublic.Enum(),
+			To:   tktypes.RandAddress(),
+		},
+	})
+	assert.Regexp(t, "PD012241", err)
+
+	_, err = txm.EstimateGasForPrivateTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type: pldapi.TransactionTypePrivate.Enum(),
+		},
+	})
+	assert.Regexp(t, "PD012241", err)
+
+}
+
+func TestEstimateGasForPrivateTransactionNoFrom(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	_, err := txm.EstimateGasForPrivateTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type: pldapi.TransactionTypePrivate.Enum(),
+			To:   tktypes.RandAddress(),
+		},
+	})
+	assert.Regexp(t, "PD012230", err)
+
+}
+
+func TestEstimateGasForPrivateTransactionBadTX(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false)
+	defer done()
+
+	_, err := txm.EstimateGasForPrivateTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type: pldapi.TransactionTypePrivate.Enum(),
+			From: "sender",
+			To:   tktypes.RandAddress(),
+		},
+	})
+	assert.Regexp(t, "PD012218", err)
+
+}
+
 var testInternalTransactionFn = &abi.Entry{Type: abi.Function, Name: "doStuff"}
 
 func newTestInternalTransaction(idempotencyKey string) *pldapi.TransactionInput {
@@ -907,6 +1114,72 @@ func TestInternalPrivateTXInsertWithIdempotencyKeys(t *testing.T) {
 
 }
 
+func TestSetTransactionCoordinator(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, true)
+	defer done()
+
+	var txi *components.ValidatedTransaction
+	err := txm.p.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		txi, err = txm.PrepareInternalPrivateTransaction(ctx, dbTX, newTestInternalTransaction("tx1"), pldapi.SubmitModeAuto)
+		require.NoError(t, err)
+		return txm.UpsertInternalPrivateTxsFinalizeIDs(ctx, dbTX, []*components.ValidatedTransaction{txi})
+	})
+	require.NoError(t, err)
+	txID := *txi.Transaction.ID
+
+	tx, err := txm.GetTransactionByID(ctx, txID)
+	require.NoError(t, err)
+	assert.Empty(t, tx.Coordinator)
+
+	// Transactions that were delegated to us have no record here, so are ignored
+	err = txm.SetTransactionCoordinator(ctx, txm.p.DB(), "node2", []uuid.UUID{txID, uuid.New()})
+	require.NoError(t, err)
+	err = txm.SetTransactionCoordinator(ctx, txm.p.DB(), "node3", nil)
+	require.NoError(t, err)
+
+	tx, err = txm.GetTransactionByID(ctx, txID)
+	require.NoError(t, err)
+	assert.Equal(t, "node2", tx.Coordinator)
+
+	txs, err := txm.QueryTransactions(ctx, query.NewQueryBuilder().Equal("coordinator", "node2").Limit(1).Query(), false)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, txID, *txs[0].ID)
+}
+
+func TestSetTransactionLabel(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, true)
+	defer done()
+
+	var txi *components.ValidatedTransaction
+	err := txm.p.DB().Transaction(func(dbTX *gorm.DB) (err error) {
+		txi, err = txm.PrepareInternalPrivateTransaction(ctx, dbTX, newTestInternalTransaction("tx1"), pldapi.SubmitModeAuto)
+		require.NoError(t, err)
+		return txm.UpsertInternalPrivateTxsFinalizeIDs(ctx, dbTX, []*components.ValidatedTransaction{txi})
+	})
+	require.NoError(t, err)
+	txID := *txi.Transaction.ID
+
+	tx, err := txm.GetTransactionByID(ctx, txID)
+	require.NoError(t, err)
+	assert.Empty(t, tx.Label)
+
+	err = txm.SetTransactionLabel(ctx, txm.p.DB(), txID, "Transfer 23 FT1 to org2")
+	require.NoError(t, err)
+	// Transactions that were delegated to us have no record here, so are ignored
+	err = txm.SetTransactionLabel(ctx, txm.p.DB(), uuid.New(), "ignored")
+	require.NoError(t, err)
+
+	tx, err = txm.GetTransactionByID(ctx, txID)
+	require.NoError(t, err)
+	assert.Equal(t, "Transfer 23 FT1 to org2", tx.Label)
+
+	txs, err := txm.QueryTransactions(ctx, query.NewQueryBuilder().Equal("label", "Transfer 23 FT1 to org2").Limit(1).Query(), false)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, txID, *txs[0].ID)
+}
+
 func TestPrepareInternalPrivateTransactionNoIdempotencyKey(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false)
 	defer done()
@@ -916,6 +1189,92 @@ func TestPrepareInternalPrivateTransactionNoIdempotencyKey(t *testing.T) {
 
 }
 
+func TestSendTransactionsBatchIdempotencyKeys(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, true, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.privateTxMgr.On("HandleNewTx", mock.Anything, mock.Anything).Return(nil)
+	})
+	defer done()
+
+	tx1ID, err := txm.SendTransaction(ctx, newTestInternalTransaction("tx1"))
+	require.NoError(t, err)
+
+	// A batch with the existing key in the middle, a new key repeated, and a transaction with no key
+	txIDs, err := txm.SendTransactions(ctx, []*pldapi.TransactionInput{
+		newTestInternalTransaction("tx2"),
+		newTestInternalTransaction("tx1"),
+		newTestInternalTransaction("tx2"),
+		newTestInternalTransaction(""),
+	})
+	require.NoError(t, err)
+	require.Len(t, txIDs, 4)
+	assert.Equal(t, *tx1ID, txIDs[1])
+	assert.Equal(t, txIDs[0], txIDs[2])
+	assert.NotEqual(t, *tx1ID, txIDs[0])
+	assert.NotEqual(t, txIDs[0], txIDs[3])
+
+	// Retrying the keyed part of the batch converges, with nothing new to insert
+	retryTxIDs, err := txm.SendTransactions(ctx, []*pldapi.TransactionInput{
+		newTestInternalTransaction("tx2"),
+		newTestInternalTransaction("tx1"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, txIDs[0:2], retryTxIDs)
+
+	// The single path still reports the clash
+	_, err = txm.SendTransaction(ctx, newTestInternalTransaction("tx2"))
+	assert.Regexp(t, "PD012220", err)
+}
+
+func TestSendTransactionsBatchIdempotencyKeyLookupFail(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*transactions").WillReturnError(fmt.Errorf("pop"))
+	})
+	defer done()
+
+	_, err := txm.SendTransactions(ctx, []*pldapi.TransactionInput{newTestInternalTransaction("tx1")})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestSendTransactionsBatchIdempotencyKeyInsertedConcurrently(t *testing.T) {
+	existingID := uuid.New()
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key"}))
+		mc.db.ExpectExec("INSERT.*abis").WillReturnResult(driver.ResultNoRows)
+		mc.db.ExpectExec("INSERT.*abi_entries").WillReturnResult(driver.ResultNoRows)
+		mc.db.ExpectBegin()
+		mc.db.ExpectExec("INSERT.*transactions").WillReturnError(fmt.Errorf("duplicate key"))
+		mc.db.ExpectRollback()
+		mc.db.ExpectQuery("SELECT.*transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key"}).AddRow(existingID, "tx1"))
+		// the second attempt resolves the key to the transaction inserted concurrently
+		mc.db.ExpectQuery("SELECT.*transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key"}).AddRow(existingID, "tx1"))
+	})
+	defer done()
+
+	txIDs, err := txm.SendTransactions(ctx, []*pldapi.TransactionInput{newTestInternalTransaction("tx1")})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{existingID}, txIDs)
+}
+
+func TestSendTransactionsBatchInsertFailNotMaskedByExistingKey(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		mc.db.ExpectQuery("SELECT.*transactions").WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key"}).AddRow(uuid.New(), "tx1"))
+		mc.db.ExpectExec("INSERT.*abis").WillReturnResult(driver.ResultNoRows)
+		mc.db.ExpectExec("INSERT.*abi_entries").WillReturnResult(driver.ResultNoRows)
+		mc.db.ExpectBegin()
+		mc.db.ExpectExec("INSERT.*transactions").WillReturnError(fmt.Errorf("pop"))
+		mc.db.ExpectRollback()
+		// only the key we tried to insert is checked for a clash
+		mc.db.ExpectQuery("SELECT.*transactions").WithArgs("tx2", 1).WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key"}))
+	})
+	defer done()
+
+	_, err := txm.SendTransactions(ctx, []*pldapi.TransactionInput{
+		newTestInternalTransaction("tx1"),
+		newTestInternalTransaction("tx2"),
+	})
+	assert.Regexp(t, "pop", err)
+}
+
 func TestUpsertInternalPrivateTxsFinalizeIDsInsertFail(t *testing.T) {
 	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
 		mc.db.ExpectExec("INSERT.*abis").WillReturnResult(driver.ResultNoRows)
@@ -965,3 +1324,23 @@ func TestUpsertInternalPrivateTxsIdempotencyMisMatch(t *testing.T) {
 	assert.Regexp(t, "PD012224", err)
 
 }
+
+func TestSubmitTooManyDependencies(t *testing.T) {
+	ctx, txm, done := newTestTransactionManager(t, false, func(conf *pldconf.TxManagerConfig, mc *mockComponents) {
+		conf.MaxDependencies = confutil.P(2)
+	})
+	defer done()
+
+	exampleABI := abi.ABI{{Type: abi.Function, Name: "doIt"}}
+	_, err := txm.SendTransaction(ctx, &pldapi.TransactionInput{
+		TransactionBase: pldapi.TransactionBase{
+			Type:     pldapi.TransactionTypePublic.Enum(),
+			Function: "doIt",
+			From:     "sender1",
+			To:       tktypes.MustEthAddress(tktypes.RandHex(20)),
+		},
+		ABI:       exampleABI,
+		DependsOn: []uuid.UUID{uuid.New(), uuid.New(), uuid.New()},
+	})
+	assert.Regexp(t, "PD012231.*3.*2", err)
+}
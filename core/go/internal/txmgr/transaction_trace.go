@@ -0,0 +1,206 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package txmgr
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+)
+
+// ExportTransactionTrace consolidates everything we know about a transaction - the persisted
+// transaction, public transaction submissions and activity, the receipt, and any in-memory
+// private transaction stages and attestations - into a single time ordered trace for debugging.
+func (tm *txManager) ExportTransactionTrace(ctx context.Context, id uuid.UUID) (*pldapi.TransactionTrace, error) {
+	tx, err := tm.GetTransactionByIDFull(ctx, id)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	trace := &pldapi.TransactionTrace{
+		Transaction: tx,
+		Events: []*pldapi.TransactionTraceEvent{{
+			Time: tx.Created,
+			Type: pldapi.TransactionTraceEventCreated,
+		}},
+	}
+
+	for _, ptx := range tx.Public {
+		trace.Events = append(trace.Events, buildPublicTxTraceEvents(ptx)...)
+	}
+
+	if tx.Receipt != nil {
+		if trace.Receipt, err = tm.GetTransactionReceiptByIDFull(ctx, id); err != nil {
+			return nil, err
+		}
+		// The indexed time is not returned on the receipt API, so we read it directly
+		var receipts []*transactionReceipt
+		err = tm.p.DB().
+			WithContext(ctx).
+			Table("transaction_receipts").
+			Where(`"transaction" = ?`, id).
+			Limit(1).
+			Find(&receipts).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		// The receipt can be removed between the two reads, in which case we still return the rest of the trace
+		if len(receipts) > 0 {
+			trace.Events = append(trace.Events, &pldapi.TransactionTraceEvent{
+				Time:    receipts[0].Indexed,
+				Type:    pldapi.TransactionTraceEventReceiptFinalized,
+				Message: tx.Receipt.FailureMessage,
+				Data:    tx.Receipt,
+			})
+			if receipts[0].Corrected != nil {
+				trace.Events = append(trace.Events, &pldapi.TransactionTraceEvent{
+					Time:    *receipts[0].Corrected,
+					Type:    pldapi.TransactionTraceEventReceiptCorrected,
+					Message: tx.Receipt.FailureMessage,
+					Data:    tx.Receipt,
+				})
+			}
+		}
+	}
+
+	if tx.Type.V() == pldapi.TransactionTypePrivate && tx.To != nil {
+		tm.addPrivateTxTrace(ctx, trace, tx.To.String(), id)
+	}
+
+	sort.SliceStable(trace.Events, func(i, j int) bool {
+		return trace.Events[i].Time < trace.Events[j].Time
+	})
+	return trace, nil
+}
+
+// The private transaction manager only holds the stages and attestations of a transaction in memory,
+// so what we can add depends on how far through its lifecycle it is
+func (tm *txManager) addPrivateTxTrace(ctx context.Context, trace *pldapi.TransactionTrace, contractAddress string, id uuid.UUID) {
+	status, err := tm.privateTxMgr.GetTxStatus(ctx, contractAddress, id.String())
+	if err != nil {
+		log.L(ctx).Warnf("Unable to get private transaction status for %s: %s", id, err)
+	} else {
+		trace.PrivateState = &pldapi.TransactionDebugStatus{
+			Status:      status.Status,
+			LatestEvent: status.LatestEvent,
+			LatestError: status.LatestError,
+		}
+		for _, st := range status.StageTimings {
+			trace.Events = append(trace.Events, &pldapi.TransactionTraceEvent{
+				Time:    st.Entered,
+				Type:    pldapi.TransactionTraceEventStageEntered,
+				Message: string(st.Stage),
+			})
+			if st.Exited != nil {
+				trace.Events = append(trace.Events, &pldapi.TransactionTraceEvent{
+					Time:    *st.Exited,
+					Type:    pldapi.TransactionTraceEventStageExited,
+					Message: string(st.Stage),
+				})
+			}
+		}
+	}
+
+	attestations, err := tm.privateTxMgr.GetTxAttestations(ctx, contractAddress, id.String())
+	if err != nil {
+		log.L(ctx).Warnf("Unable to get private transaction attestations for %s: %s", id, err)
+	} else if attestations != nil {
+		trace.Attestations = make([]*pldapi.PrivateTxAttestation, len(attestations.Attestations))
+		for i, a := range attestations.Attestations {
+			attestation := &pldapi.PrivateTxAttestation{
+				Name:            a.Name,
+				AttestationType: a.AttestationType,
+				Algorithm:       a.Algorithm,
+				VerifierType:    a.VerifierType,
+				Parties:         make([]*pldapi.PrivateTxAttestationParty, len(a.Parties)),
+			}
+			for j, p := range a.Parties {
+				attestation.Parties[j] = &pldapi.PrivateTxAttestationParty{
+					Party:    p.Party,
+					Verifier: p.Verifier,
+					Received: p.Received,
+					Result:   p.Result,
+				}
+			}
+			trace.Attestations[i] = attestation
+		}
+	}
+}
+
+func buildPublicTxTraceEvents(ptx *pldapi.PublicTx) []*pldapi.TransactionTraceEvent {
+	ref := &pldapi.PublicTxRef{From: ptx.From, Nonce: ptx.Nonce}
+	events := []*pldapi.TransactionTraceEvent{{
+		Time:     ptx.Created,
+		Type:     pldapi.TransactionTraceEventPublicCreated,
+		PublicTx: ref,
+	}}
+	// Submissions are returned most recent first, so walk them backwards to detect gas price changes
+	var prevPricing *pldapi.PublicTxGasPricing
+	for i := len(ptx.Submissions) - 1; i >= 0; i-- {
+		sub := ptx.Submissions[i]
+		events = append(events, &pldapi.TransactionTraceEvent{
+			Time:     sub.Time,
+			Type:     pldapi.TransactionTraceEventSubmitted,
+			PublicTx: ref,
+			Message:  sub.TransactionHash.String(),
+			Data:     sub.PublicTxGasPricing,
+		})
+		if prevPricing != nil && !gasPricingEqual(prevPricing, &sub.PublicTxGasPricing) {
+			events = append(events, &pldapi.TransactionTraceEvent{
+				Time:     sub.Time,
+				Type:     pldapi.TransactionTraceEventGasPriceChanged,
+				PublicTx: ref,
+				Data: map[string]*pldapi.PublicTxGasPricing{
+					"previous": prevPricing,
+					"new":      &sub.PublicTxGasPricing,
+				},
+			})
+		}
+		prevPricing = &sub.PublicTxGasPricing
+	}
+	for _, a := range ptx.Activity {
+		events = append(events, &pldapi.TransactionTraceEvent{
+			Time:     a.Time,
+			Type:     pldapi.TransactionTraceEventActivity,
+			PublicTx: ref,
+			Message:  a.Message,
+		})
+	}
+	if ptx.CompletedAt != nil {
+		completed := &pldapi.TransactionTraceEvent{
+			Time:     *ptx.CompletedAt,
+			Type:     pldapi.TransactionTraceEventPublicCompleted,
+			PublicTx: ref,
+		}
+		if ptx.TransactionHash != nil {
+			completed.Message = ptx.TransactionHash.String()
+		}
+		if ptx.Success != nil {
+			completed.Data = map[string]bool{"success": *ptx.Success}
+		}
+		events = append(events, completed)
+	}
+	return events
+}
+
+func gasPricingEqual(a, b *pldapi.PublicTxGasPricing) bool {
+	return a.GasPrice.String() == b.GasPrice.String() &&
+		a.MaxFeePerGas.String() == b.MaxFeePerGas.String() &&
+		a.MaxPriorityFeePerGas.String() == b.MaxPriorityFeePerGas.String()
+}
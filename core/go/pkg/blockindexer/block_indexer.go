@@ -591,6 +591,9 @@ func (bi *blockIndexer) writeBatch(ctx context.Context, batch *blockWriterBatch)
 				},
 				RevertReason: tktypes.HexBytes(r.RevertReason),
 			}
+			if r.GasUsed != nil {
+				txn.GasUsed = r.GasUsed.BigInt().Uint64()
+			}
 			notifyTransactions = append(notifyTransactions, &txn)
 			transactions = append(transactions, &txn.IndexedTransaction)
 			for _, l := range r.Logs {
@@ -50,4 +50,5 @@ var IndexedEventFilters filters.FieldSet = filters.FieldMap{
 type IndexedTransactionNotify struct {
 	pldapi.IndexedTransaction
 	RevertReason tktypes.HexBytes
+	GasUsed      uint64
 }
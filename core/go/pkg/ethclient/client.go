@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -148,10 +149,28 @@ type KeyManager interface {
 type ethClient struct {
 	chainID           int64
 	gasEstimateFactor float64
+	timeouts          ethClientTimeouts
 	rpc               rpcclient.Client
 	keymgr            KeyManager
 }
 
+type ethClientTimeouts struct {
+	estimateGas         time.Duration
+	call                time.Duration
+	getTransactionCount time.Duration
+	sendRawTransaction  time.Duration
+}
+
+func newEthClientTimeouts(conf *pldconf.EthClientTimeouts) ethClientTimeouts {
+	defaults := &pldconf.EthClientDefaults.Timeouts
+	return ethClientTimeouts{
+		estimateGas:         confutil.DurationMin(conf.EstimateGas, 0, *defaults.EstimateGas),
+		call:                confutil.DurationMin(conf.Call, 0, *defaults.Call),
+		getTransactionCount: confutil.DurationMin(conf.GetTransactionCount, 0, *defaults.GetTransactionCount),
+		sendRawTransaction:  confutil.DurationMin(conf.SendRawTransaction, 0, *defaults.SendRawTransaction),
+	}
+}
+
 // A direct creation of a dedicated RPC client for things like unit tests outside of Paladin.
 // Within Paladin, use the EthClientFactory instead as passed to your component/manager/engine via the initialization
 func WrapRPCClient(ctx context.Context, keymgr KeyManager, rpc rpcclient.Client, conf *pldconf.EthClientConfig) (EthClient, error) {
@@ -159,6 +178,7 @@ func WrapRPCClient(ctx context.Context, keymgr KeyManager, rpc rpcclient.Client,
 		keymgr:            keymgr,
 		rpc:               rpc,
 		gasEstimateFactor: confutil.Float64Min(conf.EstimateGasFactor, 1.0, *pldconf.EthClientDefaults.EstimateGasFactor),
+		timeouts:          newEthClientTimeouts(&conf.Timeouts),
 	}
 	if err := ec.setupChainID(ctx); err != nil {
 		return nil, err
@@ -172,6 +192,7 @@ func NewUnconnectedRPCClient(ctx context.Context, conf *pldconf.EthClientConfig,
 	return &ethClient{
 		rpc:               &unconnectedRPC{},
 		gasEstimateFactor: confutil.Float64Min(conf.EstimateGasFactor, 1.0, *pldconf.EthClientDefaults.EstimateGasFactor),
+		timeouts:          newEthClientTimeouts(&conf.Timeouts),
 		chainID:           chainID,
 	}
 }
@@ -182,6 +203,23 @@ func (u *unconnectedRPC) CallRPC(ctx context.Context, result interface{}, method
 	return rpcclient.WrapErrorRPC(rpcclient.RPCCodeInternalError, i18n.NewError(ctx, msgs.MsgEthClientNoConnection))
 }
 
+// callRPCWithTimeout bounds a single JSON/RPC operation by its own timeout, so that a slow operation of
+// one type does not hold up the caller for as long as another might. A timeout is reported with a
+// distinct error that maps to ErrorReasonTimeout, rather than whatever the transport returns on cancel.
+// A timeout of zero means the operation is only bounded by the caller's context.
+func (ec *ethClient) callRPCWithTimeout(ctx context.Context, timeout time.Duration, result interface{}, method string, params ...interface{}) rpcclient.ErrorRPC {
+	if timeout <= 0 {
+		return ec.rpc.CallRPC(ctx, result, method, params...)
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	rpcErr := ec.rpc.CallRPC(rpcCtx, result, method, params...)
+	if rpcErr != nil && ctx.Err() == nil && errors.Is(rpcCtx.Err(), context.DeadlineExceeded) {
+		return rpcclient.WrapErrorRPC(rpcclient.RPCCodeInternalError, i18n.NewError(ctx, msgs.MsgEthClientRequestTimeout, method, timeout))
+	}
+	return rpcErr
+}
+
 func (ec *ethClient) Close() {
 	wsRPC, isWS := ec.rpc.(rpcclient.WSClient)
 	if isWS {
@@ -270,7 +308,7 @@ func (ec *ethClient) CallContractNoResolve(ctx context.Context, tx *ethsigner.Tr
 			res.serializer = co.serializer
 		}
 	}
-	if err := ec.rpc.CallRPC(ctx, &res.Data, "eth_call", tx, block); err != nil {
+	if err := ec.callRPCWithTimeout(ctx, ec.timeouts.call, &res.Data, "eth_call", tx, block); err != nil {
 		rpcErr := err.RPCError()
 		log.L(ctx).Errorf("eth_call failed: %+v", rpcErr)
 		if rpcErr.Data != "" {
@@ -379,8 +417,12 @@ func (ec *ethClient) EstimateGas(ctx context.Context, from *string, tx *ethsigne
 }
 
 func (ec *ethClient) EstimateGasNoResolve(ctx context.Context, tx *ethsigner.Transaction, opts ...CallOption) (res EstimateGasResult, err error) {
-	if err = ec.rpc.CallRPC(ctx, &res.GasLimit, "eth_estimateGas", tx); err != nil {
+	if err = ec.callRPCWithTimeout(ctx, ec.timeouts.estimateGas, &res.GasLimit, "eth_estimateGas", tx); err != nil {
 		log.L(ctx).Errorf("eth_estimateGas failed: %+v", err)
+		if MapError(err) == ErrorReasonTimeout {
+			// No point falling back to a call to find revert data, as the node is not responding
+			return res, err
+		}
 		// Fall back to a call, to see if we can get an error
 		callRes, callErr := ec.CallContractNoResolve(ctx, tx, "latest", opts...)
 		if callErr != nil {
@@ -394,7 +436,7 @@ func (ec *ethClient) EstimateGasNoResolve(ctx context.Context, tx *ethsigner.Tra
 
 func (ec *ethClient) GetTransactionCount(ctx context.Context, fromAddr tktypes.EthAddress) (*tktypes.HexUint64, error) {
 	var transactionCount tktypes.HexUint64
-	if rpcErr := ec.rpc.CallRPC(ctx, &transactionCount, "eth_getTransactionCount", fromAddr, "latest"); rpcErr != nil {
+	if rpcErr := ec.callRPCWithTimeout(ctx, ec.timeouts.getTransactionCount, &transactionCount, "eth_getTransactionCount", fromAddr, "latest"); rpcErr != nil {
 		log.L(ctx).Errorf("eth_getTransactionCount(%s) failed: %+v", fromAddr, rpcErr)
 		return nil, rpcErr
 	}
@@ -475,7 +517,7 @@ func (ec *ethClient) SendRawTransaction(ctx context.Context, rawTX tktypes.HexBy
 
 	// Submit
 	var txHash tktypes.Bytes32
-	if rpcErr := ec.rpc.CallRPC(ctx, &txHash, "eth_sendRawTransaction", tktypes.HexBytes(rawTX)); rpcErr != nil {
+	if rpcErr := ec.callRPCWithTimeout(ctx, ec.timeouts.sendRawTransaction, &txHash, "eth_sendRawTransaction", tktypes.HexBytes(rawTX)); rpcErr != nil {
 		addr, decodedTX, err := ethsigner.RecoverRawTransaction(ctx, ethtypes.HexBytes0xPrefix(rawTX), ec.chainID)
 		if err != nil {
 			log.L(ctx).Errorf("Invalid transaction build during signing: %s", err)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
@@ -167,6 +168,50 @@ func TestEstimateGasFail(t *testing.T) {
 	assert.Regexp(t, "pop2", err)
 }
 
+func TestEstimateGasTimeout(t *testing.T) {
+	// a slow eth_estimateGas is bounded by its own timeout, with no fallback call, and the
+	// error is mapped to a timeout that is retriable rather than a rejection of the transaction
+	ctx, ec, done := newTestClientAndServer(t, &mockEth{
+		eth_estimateGas: func(ctx context.Context, tx ethsigner.Transaction) (tktypes.HexUint64, error) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			return 0, fmt.Errorf("too slow")
+		},
+		eth_sendRawTransaction: func(ctx context.Context, rawTX tktypes.HexBytes) (tktypes.HexBytes, error) {
+			time.Sleep(50 * time.Millisecond)
+			return tktypes.RandBytes(32), nil
+		},
+	})
+	defer done()
+
+	httpClient := ec.HTTPClient().(*ethClient)
+	httpClient.timeouts.estimateGas = 10 * time.Millisecond
+	assert.Equal(t, 30*time.Second, httpClient.timeouts.sendRawTransaction)
+
+	_, err := httpClient.EstimateGas(ctx, nil, &ethsigner.Transaction{})
+	assert.Regexp(t, "PD011518.*eth_estimateGas.*10ms", err)
+	assert.Equal(t, ErrorReasonTimeout, MapError(err))
+	assert.False(t, MapSubmissionRejected(err))
+
+	// a submission slower than the gas estimate timeout is unaffected
+	txHash, err := httpClient.SendRawTransaction(ctx, tktypes.RandBytes(100))
+	require.NoError(t, err)
+	assert.NotNil(t, txHash)
+
+	httpClient.timeouts.sendRawTransaction = 10 * time.Millisecond
+	_, err = httpClient.SendRawTransaction(ctx, tktypes.RandBytes(100))
+	assert.Regexp(t, "PD011518.*eth_sendRawTransaction", err)
+	assert.Equal(t, ErrorReasonTimeout, MapError(err))
+
+	// a timeout of zero is disabled, rather than failing immediately
+	httpClient.timeouts.sendRawTransaction = 0
+	txHash, err = httpClient.SendRawTransaction(ctx, tktypes.RandBytes(100))
+	require.NoError(t, err)
+	assert.NotNil(t, txHash)
+}
+
 func TestGetTransactionCount(t *testing.T) {
 	txCountHexUint := (tktypes.HexUint64)(200000)
 	ctx, ec, done := newTestClientAndServer(t, &mockEth{
@@ -20,6 +20,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/kaleido-io/paladin/core/internal/msgs"
 )
 
 // ErrorReason are a set of standard error conditions that a blockchain connector can return
@@ -37,6 +38,8 @@ const (
 	ErrorReasonNonceTooLow ErrorReason = "nonce_too_low"
 	// ErrorReasonTransactionUnderpriced if the transaction is rejected due to too low gas price. Either because it was too low according to the minimum configured on the node, or because it's a rescue transaction without a price bump.
 	ErrorReasonTransactionUnderpriced ErrorReason = "transaction_underpriced"
+	// ErrorReasonReplacementUnderpriced if a transaction replacing one already in the node's pool (same nonce) did not bump the gas price by enough for the node to accept it
+	ErrorReasonReplacementUnderpriced ErrorReason = "replacement_underpriced"
 	// ErrorReasonInsufficientFunds if the transaction is rejected due to not having enough of the underlying network coin (ether etc.) in your wallet
 	ErrorReasonInsufficientFunds ErrorReason = "insufficient_funds"
 	// ErrorReasonNotFound if the requested object (block/receipt etc.) was not found
@@ -45,6 +48,8 @@ const (
 	ErrorKnownTransaction ErrorReason = "known_transaction"
 	// ErrorReasonDownstreamDown if the downstream JSONRPC endpoint is down
 	ErrorReasonDownstreamDown ErrorReason = "downstream_down"
+	// ErrorReasonTimeout if the JSONRPC operation did not complete within its configured timeout (the outcome is unknown, so it is safe only to retry)
+	ErrorReasonTimeout ErrorReason = "timeout"
 )
 
 func MapSubmissionRejected(err error) bool {
@@ -55,6 +60,9 @@ func MapSubmissionRejected(err error) bool {
 		ErrorReasonInsufficientFunds:
 		// These reason codes are considered as rejections of the transaction - see SubmissionError
 		return true
+	case ErrorReasonTimeout:
+		// We do not know whether the node processed the request, so this must not be treated as a rejection
+		return false
 	default:
 		// Everything else is eligible for idempotent retry of submission
 		return false
@@ -65,12 +73,17 @@ func MapError(err error) ErrorReason {
 
 	errString := strings.ToLower(err.Error())
 	switch {
+	case strings.Contains(errString, strings.ToLower(string(msgs.MsgEthClientRequestTimeout))):
+		return ErrorReasonTimeout
 	case strings.Contains(errString, "filter not found"):
 		return ErrorReasonNotFound
 	case strings.Contains(errString, "nonce too low"):
 		return ErrorReasonNonceTooLow
 	case strings.Contains(errString, "insufficient funds"):
 		return ErrorReasonInsufficientFunds
+	case strings.Contains(errString, "replacement transaction underpriced"),
+		strings.Contains(errString, "replacement fee too low"):
+		return ErrorReasonReplacementUnderpriced
 	case strings.Contains(errString, "transaction underpriced"):
 		return ErrorReasonTransactionUnderpriced
 	case strings.Contains(errString, "known transaction"):
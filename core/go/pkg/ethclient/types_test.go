@@ -26,6 +26,8 @@ func TestMappings(t *testing.T) {
 	assert.Equal(t, ErrorReasonNonceTooLow, MapError(fmt.Errorf("nonce too low")))
 	assert.Equal(t, ErrorReasonInsufficientFunds, MapError(fmt.Errorf("insufficient funds")))
 	assert.Equal(t, ErrorReasonTransactionUnderpriced, MapError(fmt.Errorf("transaction underpriced")))
+	assert.Equal(t, ErrorReasonReplacementUnderpriced, MapError(fmt.Errorf("replacement transaction underpriced")))
+	assert.Equal(t, ErrorReasonReplacementUnderpriced, MapError(fmt.Errorf("Replacement fee too low")))
 	assert.Equal(t, ErrorKnownTransaction, MapError(fmt.Errorf("known transaction")))
 	assert.Equal(t, ErrorKnownTransaction, MapError(fmt.Errorf("already known")))
 	assert.Equal(t, ErrorReasonTransactionReverted, MapError(fmt.Errorf("execution reverted")))
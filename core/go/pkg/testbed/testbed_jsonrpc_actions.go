@@ -272,6 +272,16 @@ func (tb *testbed) execPrivateTransaction(ctx context.Context, psc components.Do
 		return err
 	}
 
+	// Let the domain reject the assembly before we go any further (no-op unless the domain opts in)
+	if err := psc.ValidateAssembled(dCtx, tb.c.Persistence().DB(), tx); err != nil {
+		return err
+	}
+
+	// Build any payloads the domain has asked to construct itself
+	if err := psc.BuildAttestationPayloads(dCtx, tb.c.Persistence().DB(), tx); err != nil {
+		return err
+	}
+
 	// Gather signatures
 	if err := tb.gatherSignatures(ctx, tx); err != nil {
 		return err
@@ -74,6 +74,13 @@ func (n *NotoHelper) Transfer(ctx context.Context, to string, amount int64) *Dom
 	}))
 }
 
+func (n *NotoHelper) Burn(ctx context.Context, amount int64) *DomainTransactionHelper {
+	fn := types.NotoABI.Functions()["burn"]
+	return NewDomainTransactionHelper(ctx, n.t, n.rpc, n.Address, fn, toJSON(n.t, &types.BurnParams{
+		Amount: tktypes.Int64ToInt256(amount),
+	}))
+}
+
 func (n *NotoHelper) ApproveTransfer(ctx context.Context, params *types.ApproveParams) *DomainTransactionHelper {
 	fn := types.NotoABI.Functions()["approveTransfer"]
 	return NewDomainTransactionHelper(ctx, n.t, n.rpc, n.Address, fn, toJSON(n.t, params))
@@ -63,4 +63,7 @@ var (
 	MsgNotImplemented              = ffe("PD200022", "Not implemented")
 	MsgInvalidDelegate             = ffe("PD200023", "Invalid delegate: %s")
 	MsgNoDomainReceipt             = ffe("PD200024", "Not implemented. See state receipt for coin transfers")
+	MsgUnknownCoinSelection        = ffe("PD200025", "Unknown coin selection strategy: %s")
+	MsgAmountExceedsUint256        = ffe("PD200026", "Amount for '%s' exceeds the maximum value of a uint256: %s")
+	MsgTransferExceedsLimit        = ffe("PD200027", "Transfer amount %s exceeds the maximum of %s allowed by the notary")
 )
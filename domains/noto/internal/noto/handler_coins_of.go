@@ -0,0 +1,99 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package noto
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/noto/internal/msgs"
+	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+)
+
+// Lists the unspent coins held by an owner, so that a wallet can display a balance
+// without replaying the history of transactions
+type coinsOfHandler struct {
+	noto *Noto
+}
+
+func (h *coinsOfHandler) ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error) {
+	var coinsOfParams types.CoinsOfParams
+	if err := json.Unmarshal([]byte(params), &coinsOfParams); err != nil {
+		return nil, err
+	}
+	if coinsOfParams.Owner == "" {
+		return nil, i18n.NewError(ctx, msgs.MsgParameterRequired, "owner")
+	}
+	return &coinsOfParams, nil
+}
+
+func (h *coinsOfHandler) InitCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
+	params := tx.Params.(*types.CoinsOfParams)
+
+	return &prototk.InitCallResponse{
+		RequiredVerifiers: []*prototk.ResolveVerifierRequest{
+			{
+				Lookup:       params.Owner,
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+			},
+		},
+	}, nil
+}
+
+func (h *coinsOfHandler) ExecCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
+	params := tx.Params.(*types.CoinsOfParams)
+
+	ownerAddress, err := h.noto.findEthAddressVerifier(ctx, "owner", params.Owner, req.ResolvedVerifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	states, coins, total, err := h.noto.findOwnedCoins(ctx, req.StateQueryContext, ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.CoinsOfResult{
+		Coins: make([]*types.OwnedCoin, len(coins)),
+		Total: (*tktypes.HexUint256)(total),
+	}
+	for i, coin := range coins {
+		id, err := tktypes.ParseHexBytes(ctx, states[i].Id)
+		if err != nil {
+			return nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, states[i].Id, err)
+		}
+		result.Coins[i] = &types.OwnedCoin{
+			ID:     id,
+			Salt:   coin.Salt,
+			Owner:  coin.Owner,
+			Amount: coin.Amount,
+		}
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &prototk.ExecCallResponse{
+		ResultJson: string(resultJSON),
+	}, nil
+}
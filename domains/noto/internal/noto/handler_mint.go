@@ -46,6 +46,9 @@ func (h *mintHandler) ValidateParams(ctx context.Context, config *types.NotoPars
 	if mintParams.Amount == nil || mintParams.Amount.Int().Sign() != 1 {
 		return nil, i18n.NewError(ctx, msgs.MsgParameterGreaterThanZero, "amount")
 	}
+	if err := validateUint256(ctx, "amount", mintParams.Amount.Int()); err != nil {
+		return nil, err
+	}
 	return &mintParams, nil
 }
 
@@ -148,6 +151,15 @@ func (h *mintHandler) Endorse(ctx context.Context, tx *types.ParsedTransaction,
 	}, nil
 }
 
+func (h *mintHandler) ValidateAssembled(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ValidateAssembledRequest) error {
+	params := tx.Params.(*types.MintParams)
+	coins, err := h.noto.gatherCoins(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return err
+	}
+	return h.noto.validateMintAmounts(ctx, params, coins)
+}
+
 func (h *mintHandler) baseLedgerMint(ctx context.Context, req *prototk.PrepareTransactionRequest) (*TransactionWrapper, error) {
 	outputs := make([]string, len(req.OutputStates))
 	for i, state := range req.OutputStates {
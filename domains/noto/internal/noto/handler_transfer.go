@@ -47,6 +47,9 @@ func (h *transferHandler) ValidateParams(ctx context.Context, config *types.Noto
 	if transferParams.Amount == nil || transferParams.Amount.Int().Sign() != 1 {
 		return nil, i18n.NewError(ctx, msgs.MsgParameterGreaterThanZero, "amount")
 	}
+	if err := validateUint256(ctx, "amount", transferParams.Amount.Int()); err != nil {
+		return nil, err
+	}
 	return &transferParams, nil
 }
 
@@ -188,6 +191,16 @@ func (h *transferHandler) Endorse(ctx context.Context, tx *types.ParsedTransacti
 	if err := h.noto.validateOwners(ctx, tx, req, coins); err != nil {
 		return nil, err
 	}
+	if req.EndorsementRequest.Name == "notary" {
+		// A transfer over the limit is well-formed, so is rejected with a revert rather than an error
+		if err := h.noto.validateTransferLimits(ctx, tx, req, coins); err != nil {
+			revertReason := err.Error()
+			return &prototk.EndorseTransactionResponse{
+				EndorsementResult: prototk.EndorseTransactionResponse_REVERT,
+				RevertReason:      &revertReason,
+			}, nil
+		}
+	}
 
 	switch tx.DomainConfig.Variant {
 	case types.NotoVariantDefault:
@@ -238,6 +251,14 @@ func (h *transferHandler) Endorse(ctx context.Context, tx *types.ParsedTransacti
 	return nil, i18n.NewError(ctx, msgs.MsgUnrecognizedEndorsement, req.EndorsementRequest.Name)
 }
 
+func (h *transferHandler) ValidateAssembled(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ValidateAssembledRequest) error {
+	coins, err := h.noto.gatherCoins(ctx, req.Inputs, req.Outputs)
+	if err != nil {
+		return err
+	}
+	return h.noto.validateTransferAmounts(ctx, coins)
+}
+
 func (h *transferHandler) baseLedgerTransfer(ctx context.Context, tx *types.ParsedTransaction, req *prototk.PrepareTransactionRequest, withApproval bool) (*TransactionWrapper, error) {
 	inputs := make([]string, len(req.InputStates))
 	for i, state := range req.InputStates {
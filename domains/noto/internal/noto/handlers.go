@@ -19,6 +19,7 @@ import (
 	"context"
 
 	"encoding/json"
+	"math/big"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/abi"
@@ -37,6 +38,8 @@ func (n *Noto) GetHandler(method string) types.DomainHandler {
 		return &mintHandler{noto: n}
 	case "transfer":
 		return &transferHandler{noto: n}
+	case "burn":
+		return &burnHandler{noto: n}
 	case "approveTransfer":
 		return &approveHandler{noto: n}
 	default:
@@ -44,11 +47,39 @@ func (n *Noto) GetHandler(method string) types.DomainHandler {
 	}
 }
 
+// Read-only functions are served via InitCall/ExecCall, rather than the transaction flow
+func (n *Noto) GetCallHandler(method string) callHandler {
+	switch method {
+	case "coinsOf":
+		return &coinsOfHandler{noto: n}
+	default:
+		return nil
+	}
+}
+
+type paramsValidator interface {
+	ValidateParams(ctx context.Context, config *types.NotoParsedConfig, params string) (interface{}, error)
+}
+
+type callHandler interface {
+	paramsValidator
+	InitCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
+	ExecCall(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
+}
+
+// Optionally implemented by handlers that can check an assembled transaction, before endorsement is requested
+type assembledValidator interface {
+	ValidateAssembled(ctx context.Context, tx *types.ParsedTransaction, req *prototk.ValidateAssembledRequest) error
+}
+
 // Check that a mint has no inputs, and an output matching the requested amount
 func (n *Noto) validateMintAmounts(ctx context.Context, params *types.MintParams, coins *gatheredCoins) error {
 	if len(coins.inCoins) > 0 {
 		return i18n.NewError(ctx, msgs.MsgInvalidInputs, "mint", coins.inCoins)
 	}
+	if err := validateUint256(ctx, "mint", coins.outTotal); err != nil {
+		return err
+	}
 	if coins.outTotal.Cmp(params.Amount.Int()) != 0 {
 		return i18n.NewError(ctx, msgs.MsgInvalidAmount, "mint", params.Amount.Int().Text(10), coins.outTotal.Text(10))
 	}
@@ -57,12 +88,57 @@ func (n *Noto) validateMintAmounts(ctx context.Context, params *types.MintParams
 
 // Check that the inputs and outputs of a transfer net out to zero
 func (n *Noto) validateTransferAmounts(ctx context.Context, coins *gatheredCoins) error {
+	if err := validateUint256(ctx, "transfer", coins.outTotal); err != nil {
+		return err
+	}
 	if coins.inTotal.Cmp(coins.outTotal) != 0 {
 		return i18n.NewError(ctx, msgs.MsgInvalidAmount, "transfer", coins.inTotal, coins.outTotal)
 	}
 	return nil
 }
 
+// Check that the inputs of a burn exceed the outputs by exactly the requested amount
+func (n *Noto) validateBurnAmounts(ctx context.Context, params *types.BurnParams, coins *gatheredCoins) error {
+	if err := validateUint256(ctx, "burn", coins.inTotal); err != nil {
+		return err
+	}
+	burned := big.NewInt(0).Sub(coins.inTotal, coins.outTotal)
+	if burned.Cmp(params.Amount.Int()) != 0 {
+		return i18n.NewError(ctx, msgs.MsgInvalidAmount, "burn", params.Amount.Int().Text(10), burned.Text(10))
+	}
+	return nil
+}
+
+// Check that the total sent to parties other than the sender does not exceed the configured maximum
+func (n *Noto) validateTransferLimits(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest, coins *gatheredCoins) error {
+	if n.config.MaxTransferAmount == nil {
+		return nil
+	}
+	fromAddress, err := n.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return err
+	}
+
+	sent := big.NewInt(0)
+	for _, coin := range coins.outCoins {
+		if !coin.Owner.Equals(fromAddress) {
+			sent.Add(sent, coin.Amount.Int())
+		}
+	}
+	if sent.Cmp(n.config.MaxTransferAmount.Int()) == 1 {
+		return i18n.NewError(ctx, msgs.MsgTransferExceedsLimit, sent.Text(10), n.config.MaxTransferAmount.Int().Text(10))
+	}
+	return nil
+}
+
+// Amounts are arbitrary precision here, but must fit in a uint256 on the base ledger
+func validateUint256(ctx context.Context, label string, amount *big.Int) error {
+	if amount.BitLen() > 256 {
+		return i18n.NewError(ctx, msgs.MsgAmountExceedsUint256, label, amount.Text(10))
+	}
+	return nil
+}
+
 // Check that the sender of a transfer provided a signature on the input transaction details
 func (n *Noto) validateTransferSignature(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest, coins *gatheredCoins) error {
 	signature := domain.FindAttestation("sender", req.Signatures)
@@ -117,6 +193,21 @@ func (n *Noto) validateOwners(ctx context.Context, tx *types.ParsedTransaction,
 	return nil
 }
 
+// Check that all output coins are returned to the transaction sender
+func (n *Noto) validateOutputOwners(ctx context.Context, tx *types.ParsedTransaction, req *prototk.EndorseTransactionRequest, coins *gatheredCoins) error {
+	fromAddress, err := n.findEthAddressVerifier(ctx, "from", tx.Transaction.From, req.ResolvedVerifiers)
+	if err != nil {
+		return err
+	}
+
+	for i, coin := range coins.outCoins {
+		if !coin.Owner.Equals(fromAddress) {
+			return i18n.NewError(ctx, msgs.MsgStateWrongOwner, coins.outStates[i].Id, tx.Transaction.From)
+		}
+	}
+	return nil
+}
+
 // Parse a resolved verifier as an eth address
 func (n *Noto) findEthAddressVerifier(ctx context.Context, label, lookup string, verifierList []*prototk.ResolvedVerifier) (*tktypes.EthAddress, error) {
 	verifier := domain.FindVerifier(lookup, algorithms.ECDSA_SECP256K1, verifiers.ETH_ADDRESS, verifierList)
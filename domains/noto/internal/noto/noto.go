@@ -84,6 +84,13 @@ type NotoTransferParams struct {
 	Data      tktypes.HexBytes `json:"data"`
 }
 
+type NotoBurnParams struct {
+	Inputs    []string         `json:"inputs"`
+	Outputs   []string         `json:"outputs"`
+	Signature tktypes.HexBytes `json:"signature"`
+	Data      tktypes.HexBytes `json:"data"`
+}
+
 type NotoApproveTransferParams struct {
 	Delegate  *tktypes.EthAddress `json:"delegate"`
 	TXHash    tktypes.HexBytes    `json:"txhash"`
@@ -135,6 +142,11 @@ func (n *Noto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 	if err != nil {
 		return nil, err
 	}
+	switch n.config.CoinSelection {
+	case "", types.CoinSelectionOldest, types.CoinSelectionLargest, types.CoinSelectionMinimizeDust:
+	default:
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownCoinSelection, n.config.CoinSelection)
+	}
 
 	factory := solutils.MustLoadBuild(notoFactoryJSON)
 	contract := solutils.MustLoadBuild(notoInterfaceJSON)
@@ -177,6 +189,7 @@ func (n *Noto) ConfigureDomain(ctx context.Context, req *prototk.ConfigureDomain
 		DomainConfig: &prototk.DomainConfig{
 			AbiStateSchemasJson: []string{string(coinSchemaJSON), string(infoSchemaJSON)},
 			AbiEventsJson:       string(eventsJSON),
+			ValidateAssembled:   true,
 		},
 	}, nil
 }
@@ -322,6 +335,33 @@ func (n *Noto) EndorseTransaction(ctx context.Context, req *prototk.EndorseTrans
 	return handler.Endorse(ctx, tx, req)
 }
 
+func (n *Noto) ValidateAssembled(ctx context.Context, req *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+	tx, handler, err := n.validateTransaction(ctx, req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	if validator, ok := handler.(assembledValidator); ok {
+		if err := validator.ValidateAssembled(ctx, tx, req); err != nil {
+			return nil, err
+		}
+	}
+	return &prototk.ValidateAssembledResponse{}, nil
+}
+
+// Noto does not register any payload builders, as all payloads are computed during assembly
+func (n *Noto) BuildAttestationPayload(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
+// Noto does not register any state schema migrations, as no schema has yet been superseded
+func (n *Noto) MigrateState(ctx context.Context, req *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
+func (n *Noto) TransactionConfirmed(ctx context.Context, req *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
 func (n *Noto) PrepareTransaction(ctx context.Context, req *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error) {
 	tx, handler, err := n.validateTransaction(ctx, req.Transaction)
 	if err != nil {
@@ -365,42 +405,71 @@ func (n *Noto) validateTransaction(ctx context.Context, tx *prototk.TransactionS
 		return nil, nil, err
 	}
 
-	var domainConfig *types.NotoParsedConfig
-	err = json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+	handler := n.GetHandler(functionABI.Name)
+	if handler == nil {
+		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	parsedTx, err := n.parseTransaction(ctx, tx, &functionABI, handler)
 	if err != nil {
 		return nil, nil, err
 	}
+	return parsedTx, handler, nil
+}
 
-	abi := types.NotoABI.Functions()[functionABI.Name]
-	handler := n.GetHandler(functionABI.Name)
-	if abi == nil || handler == nil {
+func (n *Noto) validateCall(ctx context.Context, tx *prototk.TransactionSpecification) (*types.ParsedTransaction, callHandler, error) {
+	var functionABI abi.Entry
+	err := json.Unmarshal([]byte(tx.FunctionAbiJson), &functionABI)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := n.GetCallHandler(functionABI.Name)
+	if handler == nil {
 		return nil, nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
 	}
-	params, err := handler.ValidateParams(ctx, domainConfig, tx.FunctionParamsJson)
+	parsedTx, err := n.parseTransaction(ctx, tx, &functionABI, handler)
 	if err != nil {
 		return nil, nil, err
 	}
+	return parsedTx, handler, nil
+}
+
+func (n *Noto) parseTransaction(ctx context.Context, tx *prototk.TransactionSpecification, functionABI *abi.Entry, handler paramsValidator) (*types.ParsedTransaction, error) {
+	var domainConfig *types.NotoParsedConfig
+	err := json.Unmarshal([]byte(tx.ContractInfo.ContractConfigJson), &domainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	abi := types.NotoABI.Functions()[functionABI.Name]
+	if abi == nil {
+		return nil, i18n.NewError(ctx, msgs.MsgUnknownFunction, functionABI.Name)
+	}
+	params, err := handler.ValidateParams(ctx, domainConfig, tx.FunctionParamsJson)
+	if err != nil {
+		return nil, err
+	}
 
 	signature, err := abi.SolidityStringCtx(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if tx.FunctionSignature != signature {
-		return nil, nil, i18n.NewError(ctx, msgs.MsgUnexpectedFunctionSignature, functionABI.Name, signature, tx.FunctionSignature)
+		return nil, i18n.NewError(ctx, msgs.MsgUnexpectedFunctionSignature, functionABI.Name, signature, tx.FunctionSignature)
 	}
 
 	contractAddress, err := ethtypes.NewAddress(tx.ContractInfo.ContractAddress)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	return &types.ParsedTransaction{
 		Transaction:     tx,
-		FunctionABI:     &functionABI,
+		FunctionABI:     functionABI,
 		ContractAddress: contractAddress,
 		DomainConfig:    domainConfig,
 		Params:          params,
-	}, handler, nil
+	}, nil
 }
 
 func (n *Noto) recoverSignature(ctx context.Context, payload ethtypes.HexBytes0xPrefix, signature []byte) (*ethtypes.Address0xHex, error) {
@@ -582,11 +651,19 @@ func (n *Noto) ValidateStateHashes(ctx context.Context, req *prototk.ValidateSta
 }
 
 func (n *Noto) InitCall(ctx context.Context, req *prototk.InitCallRequest) (*prototk.InitCallResponse, error) {
-	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+	tx, handler, err := n.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return handler.InitCall(ctx, tx, req)
 }
 
 func (n *Noto) ExecCall(ctx context.Context, req *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error) {
-	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+	tx, handler, err := n.validateCall(ctx, req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return handler.ExecCall(ctx, tx, req)
 }
 
 func (n *Noto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
@@ -17,12 +17,18 @@ package noto
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/kaleido-io/paladin/domains/noto/pkg/types"
+	"github.com/kaleido-io/paladin/toolkit/pkg/algorithms"
+	"github.com/kaleido-io/paladin/toolkit/pkg/plugintk"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -205,3 +211,582 @@ func TestPrepareTransactionBadAbi(t *testing.T) {
 	})
 	assert.ErrorContains(t, err, "invalid character")
 }
+
+func TestValidateAssembledBadAbi(t *testing.T) {
+	n := &Noto{}
+	_, err := n.ValidateAssembled(context.Background(), &prototk.ValidateAssembledRequest{
+		Transaction: &prototk.TransactionSpecification{
+			FunctionAbiJson: "!!wrong",
+		},
+	})
+	assert.ErrorContains(t, err, "invalid character")
+}
+
+func TestValidateAssembledTransfer(t *testing.T) {
+	n := &Noto{
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+	}
+	transferABI := types.NotoABI.Functions()["transfer"]
+	owner := tktypes.RandAddress()
+	coinState := func(id string, amount int64) *prototk.EndorsableState {
+		return &prototk.EndorsableState{
+			Id:            id,
+			SchemaId:      "coin",
+			StateDataJson: fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, id, owner, amount),
+		}
+	}
+	req := &prototk.ValidateAssembledRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractAddress:    tktypes.RandAddress().String(),
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson:    string(tktypes.JSONString(transferABI)),
+			FunctionSignature:  transferABI.SolString(),
+			FunctionParamsJson: `{"to": "recipient", "amount": 10}`,
+		},
+		Inputs:  []*prototk.EndorsableState{coinState("0x01", 15)},
+		Outputs: []*prototk.EndorsableState{coinState("0x02", 10), coinState("0x03", 5)},
+	}
+
+	_, err := n.ValidateAssembled(context.Background(), req)
+	require.NoError(t, err)
+
+	// Unbalanced amounts are rejected before any endorsement is requested
+	req.Outputs = []*prototk.EndorsableState{coinState("0x02", 10), coinState("0x03", 6)}
+	_, err = n.ValidateAssembled(context.Background(), req)
+	assert.ErrorContains(t, err, "PD200013")
+}
+
+type testStateCallbacks struct {
+	plugintk.DomainCallbacks
+	states  []*prototk.StoredState
+	queries []string
+}
+
+func (tc *testStateCallbacks) FindAvailableStates(ctx context.Context, req *prototk.FindAvailableStatesRequest) (*prototk.FindAvailableStatesResponse, error) {
+	// Return the states a page at a time in the order given, so the query must page on from the last state returned
+	tc.queries = append(tc.queries, req.QueryJson)
+	var q query.QueryJSON
+	if err := json.Unmarshal([]byte(req.QueryJson), &q); err != nil {
+		return nil, err
+	}
+	page := tc.states
+	if q.Limit != nil && *q.Limit < len(page) {
+		page = page[:*q.Limit]
+	}
+	tc.states = tc.states[len(page):]
+	return &prototk.FindAvailableStatesResponse{States: page}, nil
+}
+
+func TestConfigureDomainBadCoinSelection(t *testing.T) {
+	n := &Noto{}
+	_, err := n.ConfigureDomain(context.Background(), &prototk.ConfigureDomainRequest{
+		ConfigJson: `{"coinSelection":"random"}`,
+	})
+	assert.ErrorContains(t, err, "PD200025")
+}
+
+func TestPrepareInputsCoinSelection(t *testing.T) {
+	owner := tktypes.RandAddress()
+	amounts := []int64{3, 9, 5, 4, 20}
+
+	prepare := func(strategy string, amount int64) (change int64, selected []int64) {
+		states := make([]*prototk.StoredState, len(amounts))
+		for i, a := range amounts {
+			states[i] = &prototk.StoredState{
+				Id:        fmt.Sprintf("0x%02d", i),
+				SchemaId:  "coin",
+				CreatedAt: int64(i + 1),
+				DataJson:  fmt.Sprintf(`{"salt":"0x%02d","owner":"%s","amount":"%d"}`, i, owner, a),
+			}
+		}
+		n := &Noto{
+			Callbacks:  &testStateCallbacks{states: states},
+			coinSchema: &prototk.StateSchema{Id: "coin"},
+			config:     types.DomainConfig{CoinSelection: strategy},
+		}
+		coins, stateRefs, total, err := n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(uint64(amount)))
+		require.NoError(t, err)
+		require.Len(t, stateRefs, len(coins))
+		for _, c := range coins {
+			selected = append(selected, c.Amount.Int().Int64())
+		}
+		return total.Int64() - amount, selected
+	}
+
+	change, selected := prepare(types.CoinSelectionOldest, 8)
+	assert.Equal(t, int64(4), change)
+	assert.Equal(t, []int64{3, 9}, selected)
+
+	change, selected = prepare(types.CoinSelectionLargest, 8)
+	assert.Equal(t, int64(12), change)
+	assert.Equal(t, []int64{20}, selected)
+
+	change, selected = prepare(types.CoinSelectionMinimizeDust, 8)
+	assert.Equal(t, int64(0), change)
+	assert.ElementsMatch(t, []int64{5, 3}, selected)
+
+	// Equal change prefers fewer coins
+	change, selected = prepare(types.CoinSelectionMinimizeDust, 9)
+	assert.Equal(t, int64(0), change)
+	assert.Equal(t, []int64{9}, selected)
+
+	// Falls back to taking everything when that is the only way to cover the amount
+	change, selected = prepare(types.CoinSelectionMinimizeDust, 41)
+	assert.Equal(t, int64(0), change)
+	assert.Len(t, selected, 5)
+
+	n := &Noto{
+		Callbacks:  &testStateCallbacks{},
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+		config:     types.DomainConfig{CoinSelection: types.CoinSelectionMinimizeDust},
+	}
+	_, _, _, err := n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(1))
+	assert.ErrorContains(t, err, "PD200005")
+}
+
+func TestInitTransactionMintAmountExceedsUint256(t *testing.T) {
+	n := &Noto{}
+	_, err := n.InitTransaction(context.Background(), &prototk.InitTransactionRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson:    `{"name": "mint"}`,
+			FunctionParamsJson: `{"to": "recipient", "amount": "0x10000000000000000000000000000000000000000000000000000000000000000"}`,
+		},
+	})
+	assert.ErrorContains(t, err, "PD200026")
+}
+
+func TestValidateTransferAmountsExceedsUint256(t *testing.T) {
+	n := &Noto{}
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	total := new(big.Int).Add(maxUint256, big.NewInt(1))
+	err := n.validateTransferAmounts(context.Background(), &gatheredCoins{
+		inTotal:  total,
+		outTotal: total,
+	})
+	assert.ErrorContains(t, err, "PD200026")
+
+	err = n.validateTransferAmounts(context.Background(), &gatheredCoins{
+		inTotal:  maxUint256,
+		outTotal: maxUint256,
+	})
+	assert.NoError(t, err)
+}
+
+func TestTransferEndorseExceedsLimit(t *testing.T) {
+	sender := tktypes.RandAddress()
+	recipient := tktypes.RandAddress()
+	n := &Noto{
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+		config:     types.DomainConfig{MaxTransferAmount: tktypes.Uint64ToUint256(10)},
+	}
+	coin := func(id string, owner *tktypes.EthAddress, amount int) *prototk.EndorsableState {
+		return &prototk.EndorsableState{
+			Id:            id,
+			SchemaId:      "coin",
+			StateDataJson: fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, id, owner, amount),
+		}
+	}
+	tx := &types.ParsedTransaction{
+		Transaction:  &prototk.TransactionSpecification{From: "sender"},
+		DomainConfig: &types.NotoParsedConfig{Variant: types.NotoVariantDefault},
+	}
+	endorse := func(name string, sent, change int) (*prototk.EndorseTransactionResponse, error) {
+		h := &transferHandler{noto: n}
+		return h.Endorse(context.Background(), tx, &prototk.EndorseTransactionRequest{
+			ResolvedVerifiers: []*prototk.ResolvedVerifier{{
+				Lookup:       "sender",
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+				Verifier:     sender.String(),
+			}},
+			Inputs:             []*prototk.EndorsableState{coin("0x01", sender, sent+change)},
+			Outputs:            []*prototk.EndorsableState{coin("0x02", recipient, sent), coin("0x03", sender, change)},
+			EndorsementRequest: &prototk.AttestationRequest{Name: name},
+		})
+	}
+
+	// Change returned to the sender does not count towards the limit
+	res, err := endorse("notary", 11, 50)
+	require.NoError(t, err)
+	assert.Equal(t, prototk.EndorseTransactionResponse_REVERT, res.EndorsementResult)
+	assert.Regexp(t, "PD200027.*11.*10", *res.RevertReason)
+
+	// Within the limit, the notary goes on to check the sender's signature
+	_, err = endorse("notary", 10, 50)
+	assert.ErrorContains(t, err, "PD200015")
+
+	// The limit is only enforced by the notary
+	_, err = endorse("sender", 11, 0)
+	assert.ErrorContains(t, err, "PD200019")
+
+	// No limit when not configured
+	n.config.MaxTransferAmount = nil
+	_, err = endorse("notary", 11, 0)
+	assert.ErrorContains(t, err, "PD200015")
+}
+
+func TestInitTransactionBurnMissingAmount(t *testing.T) {
+	n := &Noto{}
+	_, err := n.InitTransaction(context.Background(), &prototk.InitTransactionRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson:    `{"name": "burn"}`,
+			FunctionParamsJson: "{}",
+		},
+	})
+	assert.ErrorContains(t, err, "PD200008")
+}
+
+func TestValidateAssembledBurn(t *testing.T) {
+	n := &Noto{
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+	}
+	burnABI := types.NotoABI.Functions()["burn"]
+	owner := tktypes.RandAddress()
+	coinState := func(id string, amount int64) *prototk.EndorsableState {
+		return &prototk.EndorsableState{
+			Id:            id,
+			SchemaId:      "coin",
+			StateDataJson: fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, id, owner, amount),
+		}
+	}
+	req := &prototk.ValidateAssembledRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractAddress:    tktypes.RandAddress().String(),
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson:    string(tktypes.JSONString(burnABI)),
+			FunctionSignature:  burnABI.SolString(),
+			FunctionParamsJson: `{"amount": 10}`,
+		},
+		Inputs:  []*prototk.EndorsableState{coinState("0x01", 15)},
+		Outputs: []*prototk.EndorsableState{coinState("0x02", 5)},
+	}
+
+	_, err := n.ValidateAssembled(context.Background(), req)
+	require.NoError(t, err)
+
+	// Burning the exact balance produces no outputs
+	req.Inputs = []*prototk.EndorsableState{coinState("0x01", 4), coinState("0x02", 6)}
+	req.Outputs = nil
+	_, err = n.ValidateAssembled(context.Background(), req)
+	require.NoError(t, err)
+
+	// The difference between inputs and outputs must match the burn amount
+	req.Outputs = []*prototk.EndorsableState{coinState("0x03", 1)}
+	_, err = n.ValidateAssembled(context.Background(), req)
+	assert.Regexp(t, "PD200013.*burn.*10.*9", err)
+}
+
+func TestBurnAssemble(t *testing.T) {
+	sender := tktypes.RandAddress()
+	assemble := func(amount uint64, balances ...int64) *prototk.AssembleTransactionResponse {
+		states := make([]*prototk.StoredState, len(balances))
+		for i, a := range balances {
+			states[i] = &prototk.StoredState{
+				Id:        fmt.Sprintf("0x%02d", i),
+				SchemaId:  "coin",
+				CreatedAt: int64(i + 1),
+				DataJson:  fmt.Sprintf(`{"salt":"0x%02d","owner":"%s","amount":"%d"}`, i, sender, a),
+			}
+		}
+		n := &Noto{
+			Callbacks:  &testStateCallbacks{states: states},
+			coinSchema: &prototk.StateSchema{Id: "coin"},
+			dataSchema: &prototk.StateSchema{Id: "data"},
+		}
+		tx := &types.ParsedTransaction{
+			Transaction:  &prototk.TransactionSpecification{From: "sender"},
+			DomainConfig: &types.NotoParsedConfig{NotaryLookup: "notary", Variant: types.NotoVariantSelfSubmit},
+			Params:       &types.BurnParams{Amount: tktypes.Uint64ToUint256(amount)},
+		}
+		h := &burnHandler{noto: n}
+		res, err := h.Assemble(context.Background(), tx, &prototk.AssembleTransactionRequest{
+			Transaction: tx.Transaction,
+			ResolvedVerifiers: []*prototk.ResolvedVerifier{
+				{Lookup: "notary", Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: tktypes.RandAddress().String()},
+				{Lookup: "sender", Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: sender.String()},
+			},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	// Change is returned to the sender
+	res := assemble(8, 3, 9)
+	assert.Len(t, res.AssembledTransaction.InputStates, 2)
+	require.Len(t, res.AssembledTransaction.OutputStates, 1)
+	assert.Contains(t, res.AssembledTransaction.OutputStates[0].StateDataJson, `"amount":"0x04"`)
+	assert.Contains(t, res.AssembledTransaction.OutputStates[0].StateDataJson, sender.String())
+
+	// Burning the exact balance leaves no change coin
+	res = assemble(12, 3, 9)
+	assert.Len(t, res.AssembledTransaction.InputStates, 2)
+	assert.Empty(t, res.AssembledTransaction.OutputStates)
+	assert.Len(t, res.AssembledTransaction.InfoStates, 1)
+}
+
+func TestBurnEndorseOutputOwner(t *testing.T) {
+	sender := tktypes.RandAddress()
+	n := &Noto{
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+	}
+	coin := func(id string, owner *tktypes.EthAddress, amount int) *prototk.EndorsableState {
+		return &prototk.EndorsableState{
+			Id:            id,
+			SchemaId:      "coin",
+			StateDataJson: fmt.Sprintf(`{"salt":"%s","owner":"%s","amount":"%d"}`, id, owner, amount),
+		}
+	}
+	tx := &types.ParsedTransaction{
+		Transaction:  &prototk.TransactionSpecification{From: "sender"},
+		DomainConfig: &types.NotoParsedConfig{Variant: types.NotoVariantDefault},
+		Params:       &types.BurnParams{Amount: tktypes.Uint64ToUint256(10)},
+	}
+	endorse := func(changeOwner *tktypes.EthAddress) error {
+		h := &burnHandler{noto: n}
+		_, err := h.Endorse(context.Background(), tx, &prototk.EndorseTransactionRequest{
+			ResolvedVerifiers: []*prototk.ResolvedVerifier{{
+				Lookup:       "sender",
+				Algorithm:    algorithms.ECDSA_SECP256K1,
+				VerifierType: verifiers.ETH_ADDRESS,
+				Verifier:     sender.String(),
+			}},
+			Inputs:             []*prototk.EndorsableState{coin("0x01", sender, 15)},
+			Outputs:            []*prototk.EndorsableState{coin("0x02", changeOwner, 5)},
+			EndorsementRequest: &prototk.AttestationRequest{Name: "notary"},
+		})
+		return err
+	}
+
+	// Change cannot be diverted to another party as part of a burn
+	err := endorse(tktypes.RandAddress())
+	assert.ErrorContains(t, err, "PD200018")
+
+	// With valid outputs, the notary goes on to check the sender's signature
+	err = endorse(sender)
+	assert.ErrorContains(t, err, "PD200015")
+}
+
+func TestPrepareInputsByAmountPaging(t *testing.T) {
+	// Lots of small coins, so that more than one page is needed to cover the amount
+	owner := tktypes.RandAddress()
+	states := make([]*prototk.StoredState, coinSelectionPageSize+50)
+	for i := range states {
+		states[i] = &prototk.StoredState{
+			Id:        fmt.Sprintf("0x%04d", i),
+			SchemaId:  "coin",
+			CreatedAt: int64(i + 1),
+			DataJson:  fmt.Sprintf(`{"salt":"0x%04d","owner":"%s","amount":"1"}`, i, owner),
+		}
+	}
+	callbacks := &testStateCallbacks{states: states}
+	n := &Noto{
+		Callbacks:  callbacks,
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+		config:     types.DomainConfig{CoinSelection: types.CoinSelectionLargest},
+	}
+	coins, _, total, err := n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(120))
+	require.NoError(t, err)
+	assert.Len(t, coins, 120)
+	assert.Equal(t, int64(120), total.Int64())
+	require.Len(t, callbacks.queries, 2)
+	assert.Contains(t, callbacks.queries[1], fmt.Sprintf(`"value":"0x%04d"`, coinSelectionPageSize-1))
+
+	// Paging stops once there are no more coins
+	callbacks.states = states
+	callbacks.queries = nil
+	_, _, _, err = n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(1000))
+	assert.ErrorContains(t, err, "PD200005")
+	assert.Len(t, callbacks.queries, 2)
+}
+
+func TestPrepareInputsOldestPaging(t *testing.T) {
+	// Coins created together share a timestamp, so the page boundary falls between coins with the same one
+	owner := tktypes.RandAddress()
+	states := make([]*prototk.StoredState, 15)
+	for i := range states {
+		states[i] = &prototk.StoredState{
+			Id:        fmt.Sprintf("0x%02d", i),
+			SchemaId:  "coin",
+			CreatedAt: 1,
+			DataJson:  fmt.Sprintf(`{"salt":"0x%02d","owner":"%s","amount":"1"}`, i, owner),
+		}
+	}
+	callbacks := &testStateCallbacks{states: states}
+	n := &Noto{
+		Callbacks:  callbacks,
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+		config:     types.DomainConfig{CoinSelection: types.CoinSelectionOldest},
+	}
+	coins, _, total, err := n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(12))
+	require.NoError(t, err)
+	assert.Len(t, coins, 12)
+	assert.Equal(t, int64(12), total.Int64())
+	require.Len(t, callbacks.queries, 2)
+	// the second page continues after the last coin of the first, not after its timestamp
+	assert.Contains(t, callbacks.queries[1], `"field":".id","value":"0x09"`)
+}
+
+func TestPrepareInputsDeterministicOrder(t *testing.T) {
+	owner := tktypes.RandAddress()
+	coinStates := func(order []int) []*prototk.StoredState {
+		states := make([]*prototk.StoredState, len(order))
+		for i, o := range order {
+			// Every coin has the same value and creation time, so only the ID distinguishes them
+			states[i] = &prototk.StoredState{
+				Id:        fmt.Sprintf("0x%02d", o),
+				SchemaId:  "coin",
+				CreatedAt: 1,
+				DataJson:  fmt.Sprintf(`{"salt":"0x%02d","owner":"%s","amount":"5"}`, o, owner),
+			}
+		}
+		return states
+	}
+	prepare := func(strategy string, order []int) []string {
+		n := &Noto{
+			Callbacks:  &testStateCallbacks{states: coinStates(order)},
+			coinSchema: &prototk.StateSchema{Id: "coin"},
+			config:     types.DomainConfig{CoinSelection: strategy},
+		}
+		_, stateRefs, _, err := n.prepareInputs(context.Background(), "ctx", owner, tktypes.Uint64ToUint256(12))
+		require.NoError(t, err)
+		ids := make([]string, len(stateRefs))
+		for i, s := range stateRefs {
+			ids[i] = s.Id
+		}
+		return ids
+	}
+
+	for _, strategy := range []string{types.CoinSelectionOldest, types.CoinSelectionLargest, types.CoinSelectionMinimizeDust} {
+		first := prepare(strategy, []int{4, 2, 0, 3, 1})
+		second := prepare(strategy, []int{1, 3, 0, 4, 2})
+		assert.Equal(t, []string{"0x00", "0x01", "0x02"}, first, strategy)
+		assert.Equal(t, first, second, strategy)
+	}
+}
+
+func TestCoinsOf(t *testing.T) {
+	owner := tktypes.RandAddress()
+	states := []*prototk.StoredState{
+		{
+			Id:        "0x02",
+			SchemaId:  "coin",
+			CreatedAt: 2,
+			DataJson:  fmt.Sprintf(`{"salt":"0x%064x","owner":"%s","amount":"7"}`, 2, owner),
+		},
+		{
+			Id:        "0x01",
+			SchemaId:  "coin",
+			CreatedAt: 1,
+			DataJson:  fmt.Sprintf(`{"salt":"0x%064x","owner":"%s","amount":"5"}`, 1, owner),
+		},
+	}
+	n := &Noto{
+		Callbacks:  &testStateCallbacks{states: states},
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+	}
+	coinsOfABI := types.NotoABI.Functions()["coinsOf"]
+	txSpec := &prototk.TransactionSpecification{
+		ContractInfo: &prototk.ContractInfo{
+			ContractAddress:    tktypes.RandAddress().String(),
+			ContractConfigJson: `{"notaryLookup":"notary"}`,
+		},
+		FunctionAbiJson:    string(tktypes.JSONString(coinsOfABI)),
+		FunctionSignature:  coinsOfABI.SolString(),
+		FunctionParamsJson: `{"owner": "wallet1"}`,
+	}
+
+	initRes, err := n.InitCall(context.Background(), &prototk.InitCallRequest{Transaction: txSpec})
+	require.NoError(t, err)
+	require.Len(t, initRes.RequiredVerifiers, 1)
+	assert.Equal(t, "wallet1", initRes.RequiredVerifiers[0].Lookup)
+
+	execRes, err := n.ExecCall(context.Background(), &prototk.ExecCallRequest{
+		Transaction: txSpec,
+		ResolvedVerifiers: []*prototk.ResolvedVerifier{
+			{Lookup: "wallet1", Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: owner.String()},
+		},
+	})
+	require.NoError(t, err)
+
+	var result types.CoinsOfResult
+	err = json.Unmarshal([]byte(execRes.ResultJson), &result)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), result.Total.Int().Int64())
+	require.Len(t, result.Coins, 2)
+	assert.Equal(t, "0x01", result.Coins[0].ID.String())
+	assert.Equal(t, int64(5), result.Coins[0].Amount.Int().Int64())
+	assert.Equal(t, owner, result.Coins[1].Owner)
+
+	// The result must conform to the outputs of the function ABI
+	_, err = coinsOfABI.Outputs.ParseJSON([]byte(execRes.ResultJson))
+	require.NoError(t, err)
+
+	// An owner with no coins has a zero balance
+	execRes, err = n.ExecCall(context.Background(), &prototk.ExecCallRequest{
+		Transaction: txSpec,
+		ResolvedVerifiers: []*prototk.ResolvedVerifier{
+			{Lookup: "wallet1", Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS, Verifier: owner.String()},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"coins":[],"total":"0x00"}`, execRes.ResultJson)
+}
+
+func TestFindOwnedCoinsPaging(t *testing.T) {
+	// Coins created together share a timestamp, so the page boundary falls between coins with the same one
+	owner := tktypes.RandAddress()
+	states := make([]*prototk.StoredState, coinListPageSize+5)
+	for i := range states {
+		states[i] = &prototk.StoredState{
+			Id:        fmt.Sprintf("0x%04d", i),
+			SchemaId:  "coin",
+			CreatedAt: 1,
+			DataJson:  fmt.Sprintf(`{"salt":"0x%04d","owner":"%s","amount":"1"}`, i, owner),
+		}
+	}
+	callbacks := &testStateCallbacks{states: states}
+	n := &Noto{
+		Callbacks:  callbacks,
+		coinSchema: &prototk.StateSchema{Id: "coin"},
+	}
+	_, coins, total, err := n.findOwnedCoins(context.Background(), "ctx", owner)
+	require.NoError(t, err)
+	assert.Len(t, coins, coinListPageSize+5)
+	assert.Equal(t, int64(coinListPageSize+5), total.Int64())
+	require.Len(t, callbacks.queries, 3)
+	// the second page continues after the last coin of the first, not after its timestamp
+	assert.Contains(t, callbacks.queries[1], fmt.Sprintf(`"field":".id","value":"0x%04d"`, coinListPageSize-1))
+}
+
+func TestCoinsOfBadParams(t *testing.T) {
+	n := &Noto{}
+	coinsOfABI := types.NotoABI.Functions()["coinsOf"]
+	_, err := n.InitCall(context.Background(), &prototk.InitCallRequest{
+		Transaction: &prototk.TransactionSpecification{
+			ContractInfo: &prototk.ContractInfo{
+				ContractConfigJson: `{"notaryLookup":"notary"}`,
+			},
+			FunctionAbiJson:    string(tktypes.JSONString(coinsOfABI)),
+			FunctionParamsJson: "{}",
+		},
+	})
+	assert.ErrorContains(t, err, "PD200007")
+
+	// Functions that are submitted as transactions cannot be called
+	_, err = n.InitCall(context.Background(), &prototk.InitCallRequest{
+		Transaction: &prototk.TransactionSpecification{
+			FunctionAbiJson: `{"name": "transfer"}`,
+		},
+	})
+	assert.ErrorContains(t, err, "PD200001")
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"sort"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/eip712"
@@ -96,8 +97,34 @@ func (n *Noto) makeNewInfoState(info *types.TransactionData, distributionList []
 	}, nil
 }
 
+// Number of coins fetched per state query by the amount-based selection strategies, which
+// keep paging until the coins fetched cover the amount
+const coinSelectionPageSize = 100
+
+// Upper bound on the number of combinations explored when minimizing dust
+const coinSelectionMaxSearch = 100000
+
+// Number of coins fetched per state query when listing all the coins held by an owner
+const coinListPageSize = 100
+
+type candidateCoin struct {
+	coin     *types.NotoCoin
+	stateRef *prototk.StateRef
+}
+
+// Coins are always selected in a deterministic order, with ties broken on the state ID, so that
+// every node assembling the same transaction over the same available states selects the same inputs
 func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owner *tktypes.EthAddress, amount *tktypes.HexUint256) ([]*types.NotoCoin, []*prototk.StateRef, *big.Int, error) {
-	var lastStateTimestamp int64
+	switch n.config.CoinSelection {
+	case types.CoinSelectionLargest, types.CoinSelectionMinimizeDust:
+		return n.prepareInputsByAmount(ctx, stateQueryContext, owner, amount)
+	default:
+		return n.prepareInputsOldest(ctx, stateQueryContext, owner, amount)
+	}
+}
+
+func (n *Noto) prepareInputsOldest(ctx context.Context, stateQueryContext string, owner *tktypes.EthAddress, amount *tktypes.HexUint256) ([]*types.NotoCoin, []*prototk.StateRef, *big.Int, error) {
+	var lastState *prototk.StoredState
 	total := big.NewInt(0)
 	stateRefs := []*prototk.StateRef{}
 	coins := []*types.NotoCoin{}
@@ -105,11 +132,15 @@ func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owne
 		// TODO: make this configurable
 		queryBuilder := query.NewQueryBuilder().
 			Limit(10).
-			Sort(".created").
+			Sort(".created", ".id").
 			Equal("owner", owner.String())
 
-		if lastStateTimestamp > 0 {
-			queryBuilder.GreaterThan(".created", lastStateTimestamp)
+		if lastState != nil {
+			// Page on from the last coin, breaking ties on the state ID
+			queryBuilder.Or(
+				query.NewQueryBuilder().GreaterThan(".created", lastState.CreatedAt),
+				query.NewQueryBuilder().Equal(".created", lastState.CreatedAt).GreaterThan(".id", lastState.Id),
+			)
 		}
 
 		log.L(ctx).Debugf("State query: %s", queryBuilder.Query())
@@ -121,8 +152,14 @@ func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owne
 		if len(states) == 0 {
 			return nil, nil, nil, i18n.NewError(ctx, msgs.MsgInsufficientFunds, total.Text(10))
 		}
+		sort.SliceStable(states, func(i, j int) bool {
+			if states[i].CreatedAt != states[j].CreatedAt {
+				return states[i].CreatedAt < states[j].CreatedAt
+			}
+			return states[i].Id < states[j].Id
+		})
 		for _, state := range states {
-			lastStateTimestamp = state.CreatedAt
+			lastState = state
 			coin, err := n.unmarshalCoin(state.DataJson)
 			if err != nil {
 				return nil, nil, nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
@@ -141,6 +178,177 @@ func (n *Noto) prepareInputs(ctx context.Context, stateQueryContext string, owne
 	}
 }
 
+// Returns every available coin held by the owner, oldest first, paging through the state store
+// from the last coin returned
+func (n *Noto) findOwnedCoins(ctx context.Context, stateQueryContext string, owner *tktypes.EthAddress) ([]*prototk.StoredState, []*types.NotoCoin, *big.Int, error) {
+	var lastState *prototk.StoredState
+	total := big.NewInt(0)
+	states := []*prototk.StoredState{}
+	coins := []*types.NotoCoin{}
+	for {
+		queryBuilder := query.NewQueryBuilder().
+			Limit(coinListPageSize).
+			Sort(".created", ".id").
+			Equal("owner", owner.String())
+
+		if lastState != nil {
+			// Page on from the last coin, breaking ties on the state ID
+			queryBuilder.Or(
+				query.NewQueryBuilder().GreaterThan(".created", lastState.CreatedAt),
+				query.NewQueryBuilder().Equal(".created", lastState.CreatedAt).GreaterThan(".id", lastState.Id),
+			)
+		}
+
+		log.L(ctx).Debugf("State query: %s", queryBuilder.Query())
+		page, err := n.findAvailableStates(ctx, stateQueryContext, queryBuilder.Query().String())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(page) == 0 {
+			return states, coins, total, nil
+		}
+		sort.SliceStable(page, func(i, j int) bool {
+			if page[i].CreatedAt != page[j].CreatedAt {
+				return page[i].CreatedAt < page[j].CreatedAt
+			}
+			return page[i].Id < page[j].Id
+		})
+		for _, state := range page {
+			lastState = state
+			coin, err := n.unmarshalCoin(state.DataJson)
+			if err != nil {
+				return nil, nil, nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			total = total.Add(total, coin.Amount.Int())
+			states = append(states, state)
+			coins = append(coins, coin)
+		}
+	}
+}
+
+func (n *Noto) prepareInputsByAmount(ctx context.Context, stateQueryContext string, owner *tktypes.EthAddress, amount *tktypes.HexUint256) ([]*types.NotoCoin, []*prototk.StateRef, *big.Int, error) {
+	candidates := []*candidateCoin{}
+	available := big.NewInt(0)
+	for {
+		queryBuilder := query.NewQueryBuilder().
+			Limit(coinSelectionPageSize).
+			Sort("-amount", ".id").
+			Equal("owner", owner.String())
+
+		if len(candidates) > 0 {
+			// Page on from the smallest coin so far, breaking ties on the state ID
+			last := candidates[len(candidates)-1]
+			queryBuilder.Or(
+				query.NewQueryBuilder().LessThan("amount", last.coin.Amount),
+				query.NewQueryBuilder().Equal("amount", last.coin.Amount).GreaterThan(".id", last.stateRef.Id),
+			)
+		}
+
+		log.L(ctx).Debugf("State query: %s", queryBuilder.Query())
+		states, err := n.findAvailableStates(ctx, stateQueryContext, queryBuilder.Query().String())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, state := range states {
+			coin, err := n.unmarshalCoin(state.DataJson)
+			if err != nil {
+				return nil, nil, nil, i18n.NewError(ctx, msgs.MsgInvalidStateData, state.Id, err)
+			}
+			available = available.Add(available, coin.Amount.Int())
+			candidates = append(candidates, &candidateCoin{
+				coin:     coin,
+				stateRef: &prototk.StateRef{SchemaId: state.SchemaId, Id: state.Id},
+			})
+		}
+		if len(states) < coinSelectionPageSize || available.Cmp(amount.Int()) >= 0 {
+			break
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if cmp := candidates[i].coin.Amount.Int().Cmp(candidates[j].coin.Amount.Int()); cmp != 0 {
+			return cmp > 0
+		}
+		return candidates[i].stateRef.Id < candidates[j].stateRef.Id
+	})
+
+	// Largest-first is always computed, as it is both a strategy in its own right
+	// and the fallback for the dust minimizing search
+	selected, total := selectLargestFirst(candidates, amount.Int())
+	if selected == nil {
+		return nil, nil, nil, i18n.NewError(ctx, msgs.MsgInsufficientFunds, total.Text(10))
+	}
+	if n.config.CoinSelection == types.CoinSelectionMinimizeDust {
+		selected, total = selectMinimizeDust(candidates, amount.Int(), selected, total)
+	}
+
+	coins := make([]*types.NotoCoin, len(selected))
+	stateRefs := make([]*prototk.StateRef, len(selected))
+	for i, c := range selected {
+		coins[i] = c.coin
+		stateRefs[i] = c.stateRef
+		log.L(ctx).Debugf("Selecting coin %s value=%s", c.stateRef.Id, c.coin.Amount.Int().Text(10))
+	}
+	log.L(ctx).Debugf("Selected %d coins strategy=%s total=%s required=%s", len(selected), n.config.CoinSelection, total.Text(10), amount.Int().Text(10))
+	return coins, stateRefs, total, nil
+}
+
+// selectLargestFirst returns the fewest coins (sorted largest first) that cover the amount,
+// or nil along with the total available if the candidates are insufficient
+func selectLargestFirst(candidates []*candidateCoin, amount *big.Int) ([]*candidateCoin, *big.Int) {
+	total := big.NewInt(0)
+	for i, c := range candidates {
+		total = total.Add(total, c.coin.Amount.Int())
+		if total.Cmp(amount) >= 0 {
+			return candidates[0 : i+1], total
+		}
+	}
+	return nil, total
+}
+
+// selectMinimizeDust performs a bounded depth-first search over the candidates (sorted largest first)
+// for the combination that covers the amount with the smallest change, preferring fewer coins when
+// the change is equal. The supplied selection is returned if nothing better is found.
+func selectMinimizeDust(candidates []*candidateCoin, amount *big.Int, best []*candidateCoin, bestTotal *big.Int) ([]*candidateCoin, *big.Int) {
+	bestChange := new(big.Int).Sub(bestTotal, amount)
+
+	// remaining[i] is the sum of all candidates from index i onwards
+	remaining := make([]*big.Int, len(candidates)+1)
+	remaining[len(candidates)] = big.NewInt(0)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		remaining[i] = new(big.Int).Add(remaining[i+1], candidates[i].coin.Amount.Int())
+	}
+
+	searched := 0
+	current := make([]*candidateCoin, 0, len(candidates))
+	var search func(i int, sum *big.Int)
+	search = func(i int, sum *big.Int) {
+		if bestChange.Sign() == 0 || searched >= coinSelectionMaxSearch {
+			return
+		}
+		searched++
+		if sum.Cmp(amount) >= 0 {
+			// Adding further coins can only increase the change
+			change := new(big.Int).Sub(sum, amount)
+			if cmp := change.Cmp(bestChange); cmp < 0 || (cmp == 0 && len(current) < len(best)) {
+				best = append([]*candidateCoin{}, current...)
+				bestTotal = sum
+				bestChange = change
+			}
+			return
+		}
+		if i >= len(candidates) || new(big.Int).Add(sum, remaining[i]).Cmp(amount) < 0 {
+			return
+		}
+		current = append(current, candidates[i])
+		search(i+1, new(big.Int).Add(sum, candidates[i].coin.Amount.Int()))
+		current = current[:len(current)-1]
+		search(i+1, sum)
+	}
+	search(0, big.NewInt(0))
+	return best, bestTotal
+}
+
 func (n *Noto) prepareOutputs(ownerAddress *tktypes.EthAddress, amount *tktypes.HexUint256, distributionList []string) ([]*types.NotoCoin, []*prototk.NewState, error) {
 	// Always produce a single coin for the entire output amount
 	// TODO: make this configurable
@@ -68,6 +68,27 @@ type TransferParams struct {
 	Data   tktypes.HexBytes    `json:"data"`
 }
 
+type BurnParams struct {
+	Amount *tktypes.HexUint256 `json:"amount"`
+	Data   tktypes.HexBytes    `json:"data"`
+}
+
+type CoinsOfParams struct {
+	Owner string `json:"owner"`
+}
+
+type CoinsOfResult struct {
+	Coins []*OwnedCoin        `json:"coins"`
+	Total *tktypes.HexUint256 `json:"total"`
+}
+
+type OwnedCoin struct {
+	ID     tktypes.HexBytes    `json:"id"`
+	Salt   string              `json:"salt"`
+	Owner  *tktypes.EthAddress `json:"owner"`
+	Amount *tktypes.HexUint256 `json:"amount"`
+}
+
 type ApproveParams struct {
 	Inputs   []*pldapi.StateEncoded `json:"inputs"`
 	Outputs  []*pldapi.StateEncoded `json:"outputs"`
@@ -22,9 +22,18 @@ import (
 )
 
 type DomainConfig struct {
-	FactoryAddress string `json:"factoryAddress"`
+	FactoryAddress    string              `json:"factoryAddress"`
+	CoinSelection     string              `json:"coinSelection,omitempty"`
+	MaxTransferAmount *tktypes.HexUint256 `json:"maxTransferAmount,omitempty"` // notary will refuse to endorse transfers sending more than this to another party
 }
 
+// Strategies for choosing which coins to spend as the inputs to a transaction
+const (
+	CoinSelectionOldest       = "oldest"       // spend the oldest coins first (default)
+	CoinSelectionLargest      = "largest"      // spend the largest coins first, minimizing the number of inputs
+	CoinSelectionMinimizeDust = "minimizeDust" // spend the combination of coins that leaves the smallest change
+)
+
 var NotoConfigID_V0 = tktypes.MustParseHexBytes("0x00010000")
 
 type NotoConfig_V0 struct {
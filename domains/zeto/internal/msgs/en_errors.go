@@ -144,4 +144,8 @@ var (
 	MsgNoDomainReceipt                     = ffe("PD210102", "Not implemented. See state receipt for coin transfers")
 	MsgUnknownSignPayload                  = ffe("PD210103", "Sign payload type '%s' not recognized")
 	MsgNullifierGenerationFailed           = ffe("PD210104", "Failed to generate nullifier for coin")
+	MsgErrorNullifierInputsDiffLength      = ffe("PD210105", "values and salts must have the same length to calculate nullifiers (values=%d, salts=%d)")
+	MsgErrorCalcNullifierAtIndex           = ffe("PD210106", "Failed to calculate nullifier at index %d. %s")
+	MsgErrorMerkleTreeDepth                = ffe("PD210107", "Merkle tree depth must be between 1 and %d: %d")
+	MsgErrorMerkleTreeLeafIndex            = ffe("PD210108", "Merkle tree leaf index %d is out of range (leaves=%d)")
 )
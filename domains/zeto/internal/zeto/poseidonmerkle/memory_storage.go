@@ -0,0 +1,102 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+
+package poseidonmerkle
+
+import (
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/core"
+)
+
+// memoryStorage holds the nodes of a tree that is built for a single use, rather than persisted as states
+type memoryStorage struct {
+	root  core.NodeRef
+	nodes map[string]core.Node
+}
+
+// Changes are only applied to the storage when the transaction commits
+type memoryTx struct {
+	s     *memoryStorage
+	root  core.NodeRef
+	nodes map[string]core.Node
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{nodes: map[string]core.Node{}}
+}
+
+func (s *memoryStorage) GetRootNodeRef() (core.NodeRef, error) {
+	if s.root == nil {
+		return nil, core.ErrNotFound
+	}
+	return s.root, nil
+}
+
+func (s *memoryStorage) UpsertRootNodeRef(root core.NodeRef) error {
+	s.root = root
+	return nil
+}
+
+func (s *memoryStorage) GetNode(ref core.NodeRef) (core.Node, error) {
+	n, ok := s.nodes[ref.Hex()]
+	if !ok {
+		return nil, core.ErrNotFound
+	}
+	return n, nil
+}
+
+func (s *memoryStorage) InsertNode(n core.Node) error {
+	s.nodes[n.Ref().Hex()] = n
+	return nil
+}
+
+func (s *memoryStorage) BeginTx() (core.Transaction, error) {
+	return &memoryTx{s: s, nodes: map[string]core.Node{}}, nil
+}
+
+func (s *memoryStorage) Close() {}
+
+func (tx *memoryTx) UpsertRootNodeRef(root core.NodeRef) error {
+	tx.root = root
+	return nil
+}
+
+func (tx *memoryTx) GetNode(ref core.NodeRef) (core.Node, error) {
+	if n, ok := tx.nodes[ref.Hex()]; ok {
+		return n, nil
+	}
+	return tx.s.GetNode(ref)
+}
+
+func (tx *memoryTx) InsertNode(n core.Node) error {
+	tx.nodes[n.Ref().Hex()] = n
+	return nil
+}
+
+func (tx *memoryTx) Commit() error {
+	for ref, n := range tx.nodes {
+		tx.s.nodes[ref] = n
+	}
+	if tx.root != nil {
+		tx.s.root = tx.root
+	}
+	return nil
+}
+
+func (tx *memoryTx) Rollback() error {
+	tx.nodes = map[string]core.Node{}
+	tx.root = nil
+	return nil
+}
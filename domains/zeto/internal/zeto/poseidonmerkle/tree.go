@@ -0,0 +1,112 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+
+package poseidonmerkle
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/core"
+	"github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/node"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
+
+	gosmt "github.com/hyperledger-labs/zeto/go-sdk/pkg/sparse-merkle-tree/smt"
+)
+
+// The maximum height of a sparse merkle tree supported by the Zeto SDK
+const MaxDepth = 256
+
+// Tree is an in-memory sparse merkle tree over coin commitments. It is the same Poseidon sparse merkle
+// tree that the Zeto contracts and circuits verify against, with each commitment added as an index-only
+// leaf in the same way as the outputs indexed from Zeto events. The commitments are also kept in the order
+// they were inserted, so that proofs can be requested by leaf index.
+type Tree struct {
+	depth  int
+	mt     core.SparseMerkleTree
+	leaves []*big.Int
+}
+
+// NewTree creates an empty tree. The UTXO trees of the Zeto contracts have a depth of smt.SMT_HEIGHT_UTXO.
+func NewTree(ctx context.Context, depth int) (*Tree, error) {
+	if depth < 1 || depth > MaxDepth {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMerkleTreeDepth, MaxDepth, depth)
+	}
+	mt, err := gosmt.NewMerkleTree(newMemoryStorage(), depth)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorNewSmt, "poseidonmerkle", err)
+	}
+	return &Tree{depth: depth, mt: mt}, nil
+}
+
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+func (t *Tree) Size() int {
+	return len(t.leaves)
+}
+
+// Insert adds the commitment to the tree, and returns its leaf index
+func (t *Tree) Insert(ctx context.Context, leaf *big.Int) (index int, err error) {
+	if leaf == nil {
+		return -1, i18n.NewError(ctx, msgs.MsgErrorNewNodeIndex, "nil")
+	}
+	idx, err := node.NewNodeIndexFromBigInt(leaf)
+	if err != nil {
+		return -1, i18n.NewError(ctx, msgs.MsgErrorNewNodeIndex, err)
+	}
+	n, err := node.NewLeafNode(node.NewIndexOnly(idx))
+	if err != nil {
+		return -1, i18n.NewError(ctx, msgs.MsgErrorNewLeafNode, err)
+	}
+	if err := t.mt.AddLeaf(n); err != nil {
+		return -1, i18n.NewError(ctx, msgs.MsgErrorAddLeafNode, err)
+	}
+	t.leaves = append(t.leaves, leaf)
+	return len(t.leaves) - 1, nil
+}
+
+// Root returns the root hash, which is zero for an empty tree
+func (t *Tree) Root() *big.Int {
+	return t.mt.Root().BigInt()
+}
+
+// Proof returns the membership proof of the leaf at the index, as the sibling path from the root down to the
+// leaf, padded with zeros to the depth of the tree. This is the form of the merkle proofs in the inputs to the
+// Zeto circuits.
+func (t *Tree) Proof(ctx context.Context, index int) ([]*big.Int, error) {
+	if index < 0 || index >= len(t.leaves) {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorMerkleTreeLeafIndex, index, len(t.leaves))
+	}
+	leaf := t.leaves[index]
+	root := t.mt.Root()
+	proofs, _, err := t.mt.GenerateProofs([]*big.Int{leaf}, root)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorGenerateMTP, err)
+	}
+	cp, err := proofs[0].ToCircomVerifierProof(leaf, leaf, root, t.depth)
+	if err != nil {
+		return nil, i18n.NewError(ctx, msgs.MsgErrorConvertToCircomProof, err)
+	}
+	// The circom proof has one more sibling than the depth of the tree, which is always zero
+	siblings := make([]*big.Int, t.depth)
+	for i, s := range cp.Siblings[:t.depth] {
+		siblings[i] = s.BigInt()
+	}
+	return siblings, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+
+package poseidonmerkle
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hash(t *testing.T, elements ...*big.Int) *big.Int {
+	h, err := poseidon.Hash(elements)
+	require.NoError(t, err)
+	return h
+}
+
+// the reference of an index-only leaf, as the Zeto circuits calculate it
+func leafRef(t *testing.T, leaf *big.Int) *big.Int {
+	return hash(t, leaf, leaf, big.NewInt(1))
+}
+
+func assertSiblings(t *testing.T, expected []*big.Int, siblings []*big.Int) {
+	require.Len(t, siblings, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, e.Text(16), siblings[i].Text(16), "sibling %d", i)
+	}
+}
+
+func TestTreeRootAndProofs(t *testing.T) {
+	ctx := context.Background()
+	tree, err := NewTree(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, tree.Depth())
+	assert.Zero(t, tree.Root().Sign())
+
+	// A single leaf is the root
+	leaf2, leaf3, leaf4 := big.NewInt(2), big.NewInt(3), big.NewInt(4)
+	index, err := tree.Insert(ctx, leaf2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+	assert.Equal(t, leafRef(t, leaf2), tree.Root())
+
+	// Leaves are placed by the bits of the commitment from the least significant, so 2 and 3 split at the root
+	index, err = tree.Insert(ctx, leaf3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+	assert.Equal(t, hash(t, leafRef(t, leaf2), leafRef(t, leaf3)), tree.Root())
+
+	// ... and 4 and 2 split below it
+	index, err = tree.Insert(ctx, leaf4)
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+	assert.Equal(t, 3, tree.Size())
+	assert.Equal(t, hash(t, hash(t, leafRef(t, leaf4), leafRef(t, leaf2)), leafRef(t, leaf3)), tree.Root())
+
+	zero := big.NewInt(0)
+	siblings, err := tree.Proof(ctx, 0)
+	require.NoError(t, err)
+	assertSiblings(t, []*big.Int{leafRef(t, leaf3), leafRef(t, leaf4), zero, zero}, siblings)
+	siblings, err = tree.Proof(ctx, 1)
+	require.NoError(t, err)
+	assertSiblings(t, []*big.Int{hash(t, leafRef(t, leaf4), leafRef(t, leaf2)), zero, zero, zero}, siblings)
+	siblings, err = tree.Proof(ctx, 2)
+	require.NoError(t, err)
+	assertSiblings(t, []*big.Int{leafRef(t, leaf3), leafRef(t, leaf2), zero, zero}, siblings)
+}
+
+func TestTreeErrors(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewTree(ctx, 0)
+	assert.Regexp(t, "PD210107", err)
+	_, err = NewTree(ctx, MaxDepth+1)
+	assert.Regexp(t, "PD210107", err)
+
+	tree, err := NewTree(ctx, 4)
+	require.NoError(t, err)
+
+	_, err = tree.Insert(ctx, nil)
+	assert.Regexp(t, "PD210056", err)
+	tooBig, ok := new(big.Int).SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	assert.True(t, ok)
+	_, err = tree.Insert(ctx, tooBig)
+	assert.Regexp(t, "PD210056", err)
+
+	_, err = tree.Proof(ctx, 0)
+	assert.Regexp(t, "PD210108", err)
+	_, err = tree.Insert(ctx, big.NewInt(1))
+	require.NoError(t, err)
+	_, err = tree.Insert(ctx, big.NewInt(1))
+	assert.Regexp(t, "PD210062", err)
+	_, err = tree.Proof(ctx, -1)
+	assert.Regexp(t, "PD210108", err)
+	_, err = tree.Proof(ctx, 1)
+	assert.Regexp(t, "PD210108", err)
+
+	// Leaves that share more of their path than the depth of the tree cannot both be added
+	tree, err = NewTree(ctx, 1)
+	require.NoError(t, err)
+	_, err = tree.Insert(ctx, big.NewInt(2))
+	require.NoError(t, err)
+	_, err = tree.Insert(ctx, big.NewInt(4))
+	assert.Regexp(t, "PD210062", err)
+	assert.Equal(t, 1, tree.Size())
+}
@@ -16,13 +16,58 @@
 package signer
 
 import (
+	"context"
+	"crypto/subtle"
 	"math/big"
 
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/kaleido-io/paladin/domains/zeto/internal/msgs"
 )
 
 func CalculateNullifier(value, salt *big.Int, privateKeyForZkp *big.Int) (*big.Int, error) {
-	nullifier, err := poseidon.Hash([]*big.Int{value, salt, privateKeyForZkp})
+	return calculateNullifier(make([]*big.Int, 3), value, salt, privateKeyForZkp)
+}
+
+// CalculateNullifiers computes the nullifier for each value and salt pair with the same private key,
+// as needed for the inputs of a multi-input transfer
+func CalculateNullifiers(values, salts []*big.Int, privateKeyForZkp *big.Int) ([]*big.Int, error) {
+	if len(values) != len(salts) {
+		return nil, i18n.NewError(context.Background(), msgs.MsgErrorNullifierInputsDiffLength, len(values), len(salts))
+	}
+	hashInputs := make([]*big.Int, 3)
+	nullifiers := make([]*big.Int, len(values))
+	for i := range values {
+		nullifier, err := calculateNullifier(hashInputs, values[i], salts[i], privateKeyForZkp)
+		if err != nil {
+			return nil, i18n.NewError(context.Background(), msgs.MsgErrorCalcNullifierAtIndex, i, err)
+		}
+		nullifiers[i] = nullifier
+	}
+	return nullifiers, nil
+}
+
+// VerifyNullifier checks that a claimed nullifier was calculated from the value, salt and private key.
+// A nullifier that does not match returns false - an error is only returned if the nullifier cannot be calculated.
+func VerifyNullifier(expected, value, salt *big.Int, privateKeyForZkp *big.Int) (bool, error) {
+	nullifier, err := CalculateNullifier(value, salt, privateKeyForZkp)
+	if err != nil {
+		return false, err
+	}
+	// nullifiers are field elements, so anything that does not fit in 32 bytes cannot match
+	if expected == nil || expected.Sign() < 0 || expected.BitLen() > 256 {
+		return false, nil
+	}
+	var expectedBytes, nullifierBytes [32]byte
+	expected.FillBytes(expectedBytes[:])
+	nullifier.FillBytes(nullifierBytes[:])
+	return subtle.ConstantTimeCompare(expectedBytes[:], nullifierBytes[:]) == 1, nil
+}
+
+// the hash inputs are passed in so the slice can be re-used across a batch
+func calculateNullifier(hashInputs []*big.Int, value, salt *big.Int, privateKeyForZkp *big.Int) (*big.Int, error) {
+	hashInputs[0], hashInputs[1], hashInputs[2] = value, salt, privateKeyForZkp
+	nullifier, err := poseidon.Hash(hashInputs)
 	if err != nil {
 		return nil, err
 	}
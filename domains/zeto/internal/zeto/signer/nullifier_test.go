@@ -42,6 +42,72 @@ func TestCalculateNullifier(t *testing.T) {
 	assert.EqualError(t, err, "inputs values not inside Finite Field")
 }
 
+func TestCalculateNullifiers(t *testing.T) {
+	values := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	salts := []*big.Int{big.NewInt(4), big.NewInt(5), big.NewInt(6)}
+	_, _, privKey := newKeypair()
+
+	nullifiers, err := CalculateNullifiers(values, salts, privKey)
+	assert.NoError(t, err)
+	assert.Len(t, nullifiers, 3)
+	for i := range values {
+		expectedNullifier, err := CalculateNullifier(values[i], salts[i], privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, nullifiers[i].Cmp(expectedNullifier))
+	}
+
+	nullifiers, err = CalculateNullifiers([]*big.Int{}, []*big.Int{}, privKey)
+	assert.NoError(t, err)
+	assert.Empty(t, nullifiers)
+
+	_, err = CalculateNullifiers(values, salts[:2], privKey)
+	assert.Regexp(t, "PD210105.*values=3, salts=2", err)
+
+	tooBig, ok := new(big.Int).SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	assert.True(t, ok)
+	_, err = CalculateNullifiers(values, []*big.Int{big.NewInt(4), tooBig, big.NewInt(6)}, privKey)
+	assert.Regexp(t, "PD210106.*index 1.*inputs values not inside Finite Field", err)
+}
+
+func TestVerifyNullifier(t *testing.T) {
+	value := big.NewInt(123)
+	salt := big.NewInt(456)
+	_, _, privKey := newKeypair()
+
+	nullifier, err := CalculateNullifier(value, salt, privKey)
+	assert.NoError(t, err)
+
+	ok, err := VerifyNullifier(nullifier, value, salt, privKey)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// mismatched inputs do not verify, but are not an error
+	ok, err = VerifyNullifier(nullifier, value, big.NewInt(457), privKey)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = VerifyNullifier(nullifier, big.NewInt(124), salt, privKey)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, otherKey := newKeypair()
+	ok, err = VerifyNullifier(nullifier, value, salt, otherKey)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// nullifiers that cannot be a field element do not verify
+	for _, expected := range []*big.Int{nil, new(big.Int).Neg(nullifier), new(big.Int).Lsh(nullifier, 256)} {
+		ok, err = VerifyNullifier(expected, value, salt, privKey)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	tooBig, ok := new(big.Int).SetString("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	assert.True(t, ok)
+	_, err = VerifyNullifier(nullifier, value, salt, tooBig)
+	assert.EqualError(t, err, "inputs values not inside Finite Field")
+}
+
 func newKeypair() (*babyjub.PrivateKey, *babyjub.PublicKey, *big.Int) {
 	// generate babyJubjub private key randomly
 	babyJubjubPrivKey := babyjub.NewRandPrivKey()
@@ -490,3 +490,19 @@ func (z *Zeto) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptReques
 	// TODO: Event logs for transfers would be great for Noto
 	return nil, i18n.NewError(ctx, msgs.MsgNoDomainReceipt)
 }
+
+func (z *Zeto) ValidateAssembled(ctx context.Context, req *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
+func (z *Zeto) BuildAttestationPayload(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
+func (z *Zeto) MigrateState(ctx context.Context, req *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
+
+func (z *Zeto) TransactionConfirmed(ctx context.Context, req *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+	return nil, i18n.NewError(ctx, msgs.MsgNotImplemented)
+}
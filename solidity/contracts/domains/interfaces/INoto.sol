@@ -38,6 +38,13 @@ interface INoto {
         bytes memory data
     ) external;
 
+    function burn(
+        bytes32[] memory inputs,
+        bytes32[] memory outputs,
+        bytes memory signature,
+        bytes memory data
+    ) external;
+
     function approveTransfer(
         address delegate,
         bytes32 txhash,
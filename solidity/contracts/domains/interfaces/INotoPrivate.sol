@@ -19,6 +19,11 @@ interface INotoPrivate {
         bytes calldata data
     ) external;
 
+    function burn(
+        uint256 amount,
+        bytes calldata data
+    ) external;
+
     function approveTransfer(
         StateEncoded[] calldata inputs,
         StateEncoded[] calldata outputs,
@@ -26,6 +31,10 @@ interface INotoPrivate {
         address delegate
     ) external;
 
+    function coinsOf(
+        string calldata owner
+    ) external view returns (OwnedCoin[] memory coins, uint256 total);
+
     struct StateEncoded {
         bytes id;
         string domain;
@@ -33,4 +42,11 @@ interface INotoPrivate {
         address contractAddress;
         bytes data;
     }
+
+    struct OwnedCoin {
+        bytes32 id;
+        bytes32 salt;
+        address owner;
+        uint256 amount;
+    }
 }
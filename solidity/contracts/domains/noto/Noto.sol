@@ -140,6 +140,20 @@ contract Noto is EIP712Upgradeable, UUPSUpgradeable, INoto {
         _transfer(inputs, outputs, signature, data);
     }
 
+    /**
+     * @dev burn performs a transfer where the outputs (if any) are worth less than the inputs.
+     *      Base implementation is identical to transfer(), but both methods can be overriden
+     *      to provide different constraints.
+     */
+    function burn(
+        bytes32[] calldata inputs,
+        bytes32[] calldata outputs,
+        bytes calldata signature,
+        bytes calldata data
+    ) external virtual onlyNotary {
+        _transfer(inputs, outputs, signature, data);
+    }
+
     function _transfer(
         bytes32[] memory inputs,
         bytes32[] memory outputs,
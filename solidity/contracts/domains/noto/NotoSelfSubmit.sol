@@ -38,6 +38,18 @@ contract NotoSelfSubmit is Noto {
         _transfer(inputs, outputs, signature, data);
     }
 
+    function burn(
+        bytes32[] calldata inputs,
+        bytes32[] calldata outputs,
+        bytes calldata signature,
+        bytes calldata data
+    ) external override {
+        bytes32 txhash = _buildTXHash(inputs, outputs, data);
+        address signer = ECDSA.recover(txhash, signature);
+        requireNotary(signer);
+        _transfer(inputs, outputs, signature, data);
+    }
+
     function approveTransfer(
         address delegate,
         bytes32 txhash,
@@ -90,6 +90,16 @@ contract BondTracker is INotoHooks, ERC20, Ownable {
         emit PenteExternalCall(prepared.contractAddress, prepared.encodedCall);
     }
 
+    function onBurn(
+        address sender,
+        address from,
+        uint256 amount,
+        PreparedTransaction calldata prepared
+    ) external onlyOwner {
+        _burn(from, amount);
+        emit PenteExternalCall(prepared.contractAddress, prepared.encodedCall);
+    }
+
     uint256 approvals;
 
     function onApproveTransfer(
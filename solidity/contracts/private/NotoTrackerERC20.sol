@@ -31,6 +31,16 @@ contract NotoTrackerERC20 is INotoHooks, ERC20 {
         emit PenteExternalCall(prepared.contractAddress, prepared.encodedCall);
     }
 
+    function onBurn(
+        address sender,
+        address from,
+        uint256 amount,
+        PreparedTransaction calldata prepared
+    ) external {
+        _burn(from, amount);
+        emit PenteExternalCall(prepared.contractAddress, prepared.encodedCall);
+    }
+
     function onApproveTransfer(
         address sender,
         address from,
@@ -34,6 +34,16 @@ contract NotoTrackerPublicERC20 is INotoHooks, ERC20 {
         _executeOperation(prepared);
     }
 
+    function onBurn(
+        address sender,
+        address from,
+        uint256 amount,
+        PreparedTransaction calldata prepared
+    ) external {
+        _burn(from, amount);
+        _executeOperation(prepared);
+    }
+
     function onApproveTransfer(
         address sender,
         address from,
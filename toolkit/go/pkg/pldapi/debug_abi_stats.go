@@ -0,0 +1,26 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+// Helps operators understand the growth of ABI storage. The counts other than Stored are since startup.
+type ABIStorageStats struct {
+	AutoStore    bool  `json:"autoStore"`    // whether ABIs supplied inline on calls are stored
+	Stored       int64 `json:"stored"`       // the total number of ABIs stored on this node
+	NewlyStored  int64 `json:"newlyStored"`  // ABIs that were stored for the first time
+	Deduplicated int64 `json:"deduplicated"` // requests to store an ABI, explicitly or implicitly, that had already been stored
+	NotStored    int64 `json:"notStored"`    // ABIs supplied inline on calls that were not stored, as auto-storage is disabled
+}
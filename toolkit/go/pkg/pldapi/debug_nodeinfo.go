@@ -0,0 +1,25 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+type NodeInfo struct {
+	Name                     string   `json:"name"`                     // the name of this node on the network
+	Version                  string   `json:"version"`                  // the software version of the Paladin runtime
+	TransportProtocolVersion int      `json:"transportProtocolVersion"` // the version of the protocol used for messages exchanged with other nodes
+	SigningAlgorithms        []string `json:"signingAlgorithms"`        // signing algorithms supported by this node, including those provided by loaded domains
+	Domains                  []string `json:"domains"`                  // the domains that are loaded and initialized on this node
+}
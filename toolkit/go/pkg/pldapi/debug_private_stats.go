@@ -0,0 +1,33 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+// A one-shot view of the health of the private transaction subsystem of a node, combining the transactions
+// currently in flight in memory with the persisted history over a window
+type PrivateTransactionStats struct {
+	Window                    string         `json:"window"`                              // the period covered by the latencies, and the submitted, delegated out, and receipt counts
+	InFlight                  int            `json:"inFlight"`                            // transactions currently being processed in memory
+	ByStatus                  map[string]int `json:"byStatus"`                            // in flight transactions by their current status
+	AverageAssemblyLatency    string         `json:"averageAssemblyLatency,omitempty"`    // average time to assemble a transaction, for assemblies completed within the window
+	AverageEndorsementLatency string         `json:"averageEndorsementLatency,omitempty"` // average time to gather the endorsements for an assembled transaction, for those completed within the window
+	DelegatedIn               int            `json:"delegatedIn"`                         // transactions other nodes have delegated to this node to coordinate, since startup
+	DelegatedOut              int            `json:"delegatedOut"`                        // transactions submitted within the window that were delegated to another node to coordinate
+	Submitted                 int            `json:"submitted"`                           // private transactions submitted to this node within the window
+	Succeeded                 int            `json:"succeeded"`                           // private transactions with a successful receipt written within the window
+	Failed                    int            `json:"failed"`                              // private transactions with a failure receipt written within the window
+	FailureRate               float64        `json:"failureRate"`                         // the proportion of receipts written within the window that were failures
+}
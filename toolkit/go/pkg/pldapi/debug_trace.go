@@ -0,0 +1,73 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+import (
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+type TransactionTraceEventType string
+
+const (
+	TransactionTraceEventCreated          TransactionTraceEventType = "created"           // the Paladin transaction was accepted
+	TransactionTraceEventPublicCreated    TransactionTraceEventType = "public_created"    // a public transaction was allocated a nonce for submission
+	TransactionTraceEventSubmitted        TransactionTraceEventType = "submitted"         // a public transaction was submitted to the blockchain
+	TransactionTraceEventGasPriceChanged  TransactionTraceEventType = "gas_price_changed" // a resubmission used different gas pricing to the previous submission
+	TransactionTraceEventActivity         TransactionTraceEventType = "activity"          // an in-memory activity record from the public transaction manager
+	TransactionTraceEventPublicCompleted  TransactionTraceEventType = "public_completed"  // a public transaction was confirmed on the blockchain
+	TransactionTraceEventReceiptFinalized TransactionTraceEventType = "receipt"           // the final receipt for the Paladin transaction was written
+	TransactionTraceEventStageEntered     TransactionTraceEventType = "stage_entered"     // a private transaction entered a stage of coordination, such as endorsing
+	TransactionTraceEventStageExited      TransactionTraceEventType = "stage_exited"      // a private transaction left a stage of coordination
+	TransactionTraceEventReceiptCorrected TransactionTraceEventType = "receipt_corrected" // a re-org changed the on-chain outcome, and the receipt was re-written
+)
+
+type TransactionTrace struct {
+	Transaction  *TransactionFull         `json:"transaction"`
+	Receipt      *TransactionReceiptFull  `json:"receipt,omitempty"`
+	PrivateState *TransactionDebugStatus  `json:"privateState,omitempty"` // in-memory status from the private transaction manager, if still in flight
+	Attestations []*PrivateTxAttestation  `json:"attestations,omitempty"` // the signatures and endorsements gathered for a private transaction, if still held in memory
+	Events       []*TransactionTraceEvent `json:"events"`                 // every event we have a record of, in time order
+}
+
+// An attestation in the plan of a private transaction, with the progress of each party that has been asked to attest
+type PrivateTxAttestation struct {
+	Name            string                       `json:"name"`
+	AttestationType string                       `json:"attestationType"`
+	Algorithm       string                       `json:"algorithm"`
+	VerifierType    string                       `json:"verifierType"`
+	Parties         []*PrivateTxAttestationParty `json:"parties"`
+}
+
+type PrivateTxAttestationParty struct {
+	Party    string `json:"party"`
+	Verifier string `json:"verifier,omitempty"` // empty until the verifier of the party has been resolved
+	Received bool   `json:"received"`
+	Result   string `json:"result,omitempty"` // for endorsements, whether the endorser signed (SIGN) or must submit the transaction itself (ENDORSER_SUBMIT)
+}
+
+type TransactionTraceEvent struct {
+	Time     tktypes.Timestamp         `json:"time"`
+	Type     TransactionTraceEventType `json:"type"`
+	PublicTx *PublicTxRef              `json:"publicTx,omitempty"` // set for events relating to a specific public transaction
+	Message  string                    `json:"message,omitempty"`
+	Data     any                       `json:"data,omitempty"` // type-specific detail, such as the gas pricing for a submission
+}
+
+type PublicTxRef struct {
+	From  tktypes.EthAddress `json:"from"`
+	Nonce tktypes.HexUint64  `json:"nonce"`
+}
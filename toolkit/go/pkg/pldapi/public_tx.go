@@ -27,6 +27,8 @@ import (
 type PublicTxOptions struct {
 	Gas                *tktypes.HexUint64  `docstruct:"PublicTxOptions" json:"gas,omitempty"`
 	Value              *tktypes.HexUint256 `docstruct:"PublicTxOptions" json:"value,omitempty"`
+	ResubmitInterval   *string             `docstruct:"PublicTxOptions" json:"resubmitInterval,omitempty"` // overrides the configured resubmission interval for this TX
+	Nonce              *tktypes.HexUint64  `docstruct:"PublicTxOptions" json:"nonce,omitempty"`            // forces the nonce rather than assigning the next one - for recovering from nonce gaps
 	PublicTxGasPricing                     // fixed when any of these are supplied - disabling the gas pricing engine for this TX
 }
 
@@ -82,3 +84,28 @@ type PublicTxWithBinding struct {
 	*PublicTx
 	PublicTxBinding
 }
+
+// Identifies a public transaction that was submitted on behalf of a Paladin transaction,
+// by the signing address and nonce it was assigned, for reconciliation against the chain.
+type PublicTxNonceMapping struct {
+	PublicTxBinding
+	From            tktypes.EthAddress `docstruct:"PublicTxNonceMapping" json:"from"`
+	Nonce           tktypes.HexUint64  `docstruct:"PublicTxNonceMapping" json:"nonce"`
+	TransactionHash *tktypes.Bytes32   `docstruct:"PublicTxNonceMapping" json:"transactionHash,omitempty"` // only once confirmed
+}
+
+// A transaction that was rejected before submission (such as a revert during gas estimation),
+// so was never assigned a nonce or submitted to the chain.
+// Only recorded when the public transaction manager is configured to persist rejections.
+type PublicTxRejection struct {
+	ID           uuid.UUID           `docstruct:"PublicTxRejection" json:"id"`
+	Created      tktypes.Timestamp   `docstruct:"PublicTxRejection" json:"created"`
+	From         tktypes.EthAddress  `docstruct:"PublicTxRejection" json:"from"`
+	To           *tktypes.EthAddress `docstruct:"PublicTxRejection" json:"to,omitempty"`
+	Data         tktypes.HexBytes    `docstruct:"PublicTxRejection" json:"data,omitempty"`
+	Submitted    bool                `docstruct:"PublicTxRejection" json:"submitted"` // always false
+	RejectReason string              `docstruct:"PublicTxRejection" json:"rejectReason"`
+	RevertData   tktypes.HexBytes    `docstruct:"PublicTxRejection" json:"revertData,omitempty"` // if available
+	PublicTxOptions
+	PublicTxBinding
+}
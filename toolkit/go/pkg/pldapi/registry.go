@@ -20,10 +20,11 @@ import "github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 
 // An entity within a registry with its current properties
 type RegistryEntry struct {
-	Registry         string              `docstruct:"RegistryEntry" json:"registry"`           // the registry that maintains this record
-	ID               tktypes.HexBytes    `docstruct:"RegistryEntry" json:"id"`                 // unique within the registry, across all records in the hierarchy
-	Name             string              `docstruct:"RegistryEntry" json:"name"`               // unique across entries with the same parent, within the particular registry
-	ParentID         tktypes.HexBytes    `docstruct:"RegistryEntry" json:"parentId,omitempty"` // nil a root record, otherwise will be a reference to another entity in the same registry
+	Registry         string              `docstruct:"RegistryEntry" json:"registry"`             // the registry that maintains this record
+	ID               tktypes.HexBytes    `docstruct:"RegistryEntry" json:"id"`                   // unique within the registry, across all records in the hierarchy
+	Name             string              `docstruct:"RegistryEntry" json:"name"`                 // unique across entries with the same parent, within the particular registry
+	ParentID         tktypes.HexBytes    `docstruct:"RegistryEntry" json:"parentId,omitempty"`   // nil a root record, otherwise will be a reference to another entity in the same registry
+	ValidUntil       *tktypes.Timestamp  `docstruct:"RegistryEntry" json:"validUntil,omitempty"` // if set, the entry is treated as inactive after this time
 	*OnChainLocation `json:",omitempty"` // only included if the registry uses blockchain indexing
 	*ActiveFlag      `json:",omitempty"` // only returned from queries that explicitly look for inactive entries
 }
@@ -42,9 +43,10 @@ type ActiveFlag struct {
 }
 
 type OnChainLocation struct {
-	BlockNumber      int64 `docstruct:"OnChainLocation" json:"blockNumber"`
-	TransactionIndex int64 `docstruct:"OnChainLocation" json:"transactionIndex"`
-	LogIndex         int64 `docstruct:"OnChainLocation" json:"logIndex"`
+	TransactionHash  *tktypes.Bytes32 `docstruct:"OnChainLocation" json:"transactionHash,omitempty"`
+	BlockNumber      int64            `docstruct:"OnChainLocation" json:"blockNumber"`
+	TransactionIndex int64            `docstruct:"OnChainLocation" json:"transactionIndex"`
+	LogIndex         int64            `docstruct:"OnChainLocation" json:"logIndex"`
 }
 
 // A convenience structure that gives a snapshot of the whole entity, with all it's properties.
@@ -55,6 +57,22 @@ type RegistryEntryWithProperties struct {
 	Properties map[string]string `docstruct:"RegistryEntryWithProperties" json:"properties"`
 }
 
+// A portable copy of every entry and property in a registry, active and inactive, that can be
+// used to back up a node or to load the same registry into another node
+type RegistrySnapshot struct {
+	Registry   string              `docstruct:"RegistrySnapshot" json:"registry"`
+	Entries    []*RegistryEntry    `docstruct:"RegistrySnapshot" json:"entries"`
+	Properties []*RegistryProperty `docstruct:"RegistrySnapshot" json:"properties"`
+}
+
+// A transport this node has published for itself in a registry, as other nodes will resolve it
+type RegistryNodeTransportEntry struct {
+	Node      string `docstruct:"RegistryNodeTransportEntry" json:"node"`
+	Registry  string `docstruct:"RegistryNodeTransportEntry" json:"registry"`
+	Transport string `docstruct:"RegistryNodeTransportEntry" json:"transport"`
+	Details   string `docstruct:"RegistryNodeTransportEntry" json:"details"`
+}
+
 type ActiveFilter string
 
 const (
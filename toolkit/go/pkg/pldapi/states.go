@@ -145,6 +145,13 @@ type TransactionStates struct {
 	Confirmed   []*StateBase       `docstruct:"TransactionStates" json:"confirmed,omitempty"`
 	Info        []*StateBase       `docstruct:"TransactionStates" json:"info,omitempty"`
 	Unavailable *UnavailableStates `docstruct:"TransactionStates" json:"unavailable,omitempty"` // nil if we have the data for all states
+	Consumed    []*StateReference  `docstruct:"TransactionStates" json:"consumed,omitempty"`    // all spent states, including those that are unavailable
+	Produced    []*StateReference  `docstruct:"TransactionStates" json:"produced,omitempty"`    // all confirmed states, including those that are unavailable
+}
+
+type StateReference struct {
+	ID     tktypes.HexBytes `docstruct:"StateReference" json:"id"`
+	Schema *tktypes.Bytes32 `docstruct:"StateReference" json:"schema,omitempty"` // nil if the private data is unavailable
 }
 
 type UnavailableStates struct {
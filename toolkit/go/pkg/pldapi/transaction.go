@@ -82,18 +82,22 @@ type TransactionBase struct {
 
 // The full transaction you query, with input an doutput
 type Transaction struct {
-	ID         *uuid.UUID               `docstruct:"Transaction" json:"id,omitempty"`         // server generated UUID for this transaction (query only)
-	Created    tktypes.Timestamp        `docstruct:"Transaction" json:"created,omitempty"`    // server generated creation timestamp for this transaction (query only)
-	SubmitMode tktypes.Enum[SubmitMode] `docstruct:"Transaction" json:"submitMode,omitempty"` // empty unless submitted via PrepareTransaction route
+	ID          *uuid.UUID               `docstruct:"Transaction" json:"id,omitempty"`          // server generated UUID for this transaction (query only)
+	Created     tktypes.Timestamp        `docstruct:"Transaction" json:"created,omitempty"`     // server generated creation timestamp for this transaction (query only)
+	SubmitMode  tktypes.Enum[SubmitMode] `docstruct:"Transaction" json:"submitMode,omitempty"`  // empty unless submitted via PrepareTransaction route
+	Coordinator string                   `docstruct:"Transaction" json:"coordinator,omitempty"` // the node that coordinated this private transaction (query only)
+	Label       string                   `docstruct:"Transaction" json:"label,omitempty"`       // human-readable label supplied by the domain for this private transaction (query only)
 	TransactionBase
 }
 
 // The input structure, containing the base input/output fields, along with some convenience fields resolved on input
 type TransactionInput struct {
 	TransactionBase
-	DependsOn []uuid.UUID      `docstruct:"TransactionInput" json:"dependsOn,omitempty"` // these transactions must be mined on the blockchain successfully (or deleted) before this transaction submits. Failure of pre-reqs results in failure of this TX
-	ABI       abi.ABI          `docstruct:"TransactionInput" json:"abi,omitempty"`       // required if abiReference not supplied
-	Bytecode  tktypes.HexBytes `docstruct:"TransactionInput" json:"bytecode,omitempty"`  // for deploy this is prepended to the encoded data inputs
+	DependsOn         []uuid.UUID      `docstruct:"TransactionInput" json:"dependsOn,omitempty"`         // these transactions must be mined on the blockchain successfully (or deleted) before this transaction submits. Failure of pre-reqs results in failure of this TX
+	ABI               abi.ABI          `docstruct:"TransactionInput" json:"abi,omitempty"`               // required if abiReference not supplied
+	Bytecode          tktypes.HexBytes `docstruct:"TransactionInput" json:"bytecode,omitempty"`          // for deploy this is prepended to the encoded data inputs
+	KeyPathOverride   string           `docstruct:"TransactionInput" json:"keyPathOverride,omitempty"`   // public only: sign with the key derived at this path beneath the "from" identifier, rather than the "from" key itself
+	SignedTransaction tktypes.HexBytes `docstruct:"TransactionInput" json:"signedTransaction,omitempty"` // public only: submitted exactly as supplied, rather than being signed by Paladin
 }
 
 // Call also provides some options on how to execute the call
@@ -120,10 +124,17 @@ type ABIDecodedData struct {
 }
 
 type TransactionReceipt struct {
-	ID uuid.UUID `docstruct:"TransactionReceipt" json:"id,omitempty"` // transaction ID
+	ID       uuid.UUID `docstruct:"TransactionReceipt" json:"id,omitempty"`       // transaction ID
+	Sequence uint64    `docstruct:"TransactionReceipt" json:"sequence,omitempty"` // increasing order in which receipts were committed on this node - re-assigned on correction
 	TransactionReceiptData
 }
 
+type TransactionReceiptSubscribeOptions struct {
+	From         *string                        `docstruct:"TransactionReceiptSubscribeOptions" json:"from,omitempty"`         // only receipts for transactions submitted locally with this sender
+	Type         *tktypes.Enum[TransactionType] `docstruct:"TransactionReceiptSubscribeOptions" json:"type,omitempty"`         // only receipts for transactions submitted locally with this type
+	FromSequence *uint64                        `docstruct:"TransactionReceiptSubscribeOptions" json:"fromSequence,omitempty"` // resume after this receipt sequence - if unset only receipts written after the subscription are delivered
+}
+
 type TransactionReceiptFull struct {
 	*TransactionReceipt
 	States             *TransactionStates `docstruct:"TransactionReceiptFull" json:"states,omitempty"`
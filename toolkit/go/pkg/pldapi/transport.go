@@ -0,0 +1,36 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pldapi
+
+import (
+	"github.com/google/uuid"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+)
+
+// An inbound message that could not be processed, which was moved aside so the other messages from
+// the sending node could still be delivered
+type TransportDeadLetter struct {
+	ID            uuid.UUID         `docstruct:"TransportDeadLetter" json:"id"            gorm:"column:id;primaryKey"`
+	Created       tktypes.Timestamp `docstruct:"TransportDeadLetter" json:"created"       gorm:"column:created;autoCreateTime:false"`
+	Transport     string            `docstruct:"TransportDeadLetter" json:"transport"     gorm:"column:transport"`
+	Node          string            `docstruct:"TransportDeadLetter" json:"node"          gorm:"column:node"`
+	Component     string            `docstruct:"TransportDeadLetter" json:"component"     gorm:"column:component"`
+	MessageType   string            `docstruct:"TransportDeadLetter" json:"messageType"   gorm:"column:message_type"`
+	CorrelationID *uuid.UUID        `docstruct:"TransportDeadLetter" json:"correlationId" gorm:"column:correlation_id"`
+	Payload       tktypes.HexBytes  `docstruct:"TransportDeadLetter" json:"payload"       gorm:"column:payload"`
+	Reason        string            `docstruct:"TransportDeadLetter" json:"reason"        gorm:"column:reason"`
+}
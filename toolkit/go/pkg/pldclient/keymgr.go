@@ -19,6 +19,7 @@ import (
 	"context"
 
 	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
 )
 
@@ -29,6 +30,7 @@ type KeyManager interface {
 	ResolveKey(ctx context.Context, keyIdentifier, algorithm, verifierType string) (mapping *pldapi.KeyMappingAndVerifier, err error)
 	ResolveEthAddress(ctx context.Context, keyIdentifier string) (ethAddress *tktypes.EthAddress, err error)
 	ReverseKeyLookup(ctx context.Context, algorithm, verifierType, verifier string) (mapping *pldapi.KeyMappingAndVerifier, err error)
+	RotateKey(ctx context.Context, keyIdentifier string) (res *signerapi.RotateKeyResponse, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -51,6 +53,10 @@ var keymgrInfo = &rpcModuleInfo{
 			Inputs: []string{"algorithm", "verifierType", "verifier"},
 			Output: "mapping",
 		},
+		"keymgr_rotateKey": {
+			Inputs: []string{"keyIdentifier"},
+			Output: "result",
+		},
 	},
 }
 
@@ -82,3 +88,8 @@ func (k *keymgr) ReverseKeyLookup(ctx context.Context, algorithm, verifierType,
 	err = k.c.CallRPC(ctx, &mapping, "keymgr_reverseKeyLookup", algorithm, verifierType, verifier)
 	return
 }
+
+func (k *keymgr) RotateKey(ctx context.Context, keyIdentifier string) (res *signerapi.RotateKeyResponse, err error) {
+	err = k.c.CallRPC(ctx, &res, "keymgr_rotateKey", keyIdentifier)
+	return
+}
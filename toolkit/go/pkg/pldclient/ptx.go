@@ -33,6 +33,7 @@ type PTX interface {
 	PrepareTransaction(ctx context.Context, tx *pldapi.TransactionInput) (txID *uuid.UUID, err error)
 	PrepareTransactions(ctx context.Context, txs []*pldapi.TransactionInput) (txIDs []uuid.UUID, err error)
 	Call(ctx context.Context, tx *pldapi.TransactionCall) (data tktypes.RawJSON, err error)
+	EstimateGasForPrivateTransaction(ctx context.Context, tx *pldapi.TransactionInput) (gasLimit tktypes.HexUint64, err error)
 
 	GetTransaction(ctx context.Context, txID uuid.UUID) (receipt *pldapi.Transaction, err error)
 	GetTransactionFull(ctx context.Context, txID uuid.UUID) (receipt *pldapi.TransactionFull, err error)
@@ -82,6 +83,10 @@ var ptxInfo = &rpcModuleInfo{
 			Inputs: []string{"transaction"},
 			Output: "result",
 		},
+		"ptx_estimateGasForPrivateTransaction": {
+			Inputs: []string{"transaction"},
+			Output: "gasLimit",
+		},
 		"ptx_getTransaction": {
 			Inputs: []string{"transactionId"},
 			Output: "transaction",
@@ -195,6 +200,11 @@ func (p *ptx) Call(ctx context.Context, tx *pldapi.TransactionCall) (data tktype
 	return
 }
 
+func (p *ptx) EstimateGasForPrivateTransaction(ctx context.Context, tx *pldapi.TransactionInput) (gasLimit tktypes.HexUint64, err error) {
+	err = p.c.CallRPC(ctx, &gasLimit, "ptx_estimateGasForPrivateTransaction", tx)
+	return
+}
+
 func (p *ptx) GetTransaction(ctx context.Context, txID uuid.UUID) (tx *pldapi.Transaction, err error) {
 	err = p.c.CallRPC(ctx, &tx, "ptx_getTransaction", txID)
 	return
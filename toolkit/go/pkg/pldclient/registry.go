@@ -30,6 +30,9 @@ type Registry interface {
 	QueryEntries(ctx context.Context, registryName string, jq query.QueryJSON, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryEntry, err error)
 	QueryEntriesWithProps(ctx context.Context, registryName string, jq query.QueryJSON, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryEntryWithProperties, err error)
 	GetEntryProperties(ctx context.Context, registryName string, entryID tktypes.HexBytes, activeFilter tktypes.Enum[pldapi.ActiveFilter]) (entries []*pldapi.RegistryProperty, err error)
+	ExportSnapshot(ctx context.Context, registryName string) (snapshot *pldapi.RegistrySnapshot, err error)
+	ImportSnapshot(ctx context.Context, snapshot *pldapi.RegistrySnapshot) (imported bool, err error)
+	GetOwnTransports(ctx context.Context, registryName string) (transports []*pldapi.RegistryNodeTransportEntry, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -52,6 +55,18 @@ var registryInfo = &rpcModuleInfo{
 			Inputs: []string{"registryName", "entryId", "activeFilter"},
 			Output: "properties",
 		},
+		"reg_exportSnapshot": {
+			Inputs: []string{"registryName"},
+			Output: "snapshot",
+		},
+		"reg_importSnapshot": {
+			Inputs: []string{"snapshot"},
+			Output: "imported",
+		},
+		"reg_getOwnTransports": {
+			Inputs: []string{"registryName"},
+			Output: "transports",
+		},
 	},
 }
 
@@ -83,3 +98,18 @@ func (r *registry) GetEntryProperties(ctx context.Context, registryName string,
 	err = r.c.CallRPC(ctx, &properties, "reg_getEntryProperties", registryName, entryID, activeFilter)
 	return
 }
+
+func (r *registry) ExportSnapshot(ctx context.Context, registryName string) (snapshot *pldapi.RegistrySnapshot, err error) {
+	err = r.c.CallRPC(ctx, &snapshot, "reg_exportSnapshot", registryName)
+	return
+}
+
+func (r *registry) ImportSnapshot(ctx context.Context, snapshot *pldapi.RegistrySnapshot) (imported bool, err error) {
+	err = r.c.CallRPC(ctx, &imported, "reg_importSnapshot", snapshot)
+	return
+}
+
+func (r *registry) GetOwnTransports(ctx context.Context, registryName string) (transports []*pldapi.RegistryNodeTransportEntry, err error) {
+	err = r.c.CallRPC(ctx, &transports, "reg_getOwnTransports", registryName)
+	return
+}
@@ -32,6 +32,7 @@ type StateStore interface {
 	QueryContractStates(ctx context.Context, domain string, contractAddress tktypes.EthAddress, schemaRef tktypes.Bytes32, query *query.QueryJSON, qualifier pldapi.StateStatusQualifier) (states []*pldapi.State, err error)
 	QueryNullifiers(ctx context.Context, domain string, schemaRef tktypes.Bytes32, query *query.QueryJSON, status pldapi.StateStatusQualifier) (states []*pldapi.State, err error)
 	QueryContractNullifiers(ctx context.Context, domain string, contractAddress tktypes.EthAddress, schemaRef tktypes.Bytes32, query *query.QueryJSON, status pldapi.StateStatusQualifier) (states []*pldapi.State, err error)
+	MigrateStates(ctx context.Context, domain string) (migrated int, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -62,6 +63,10 @@ var stateStoreInfo = &rpcModuleInfo{
 			Inputs: []string{"domain", "contractAddress", "schemaRef", "query", "qualifier"},
 			Output: "states",
 		},
+		"pstate_migrateStates": {
+			Inputs: []string{"domain"},
+			Output: "migrated",
+		},
 	},
 }
 
@@ -103,3 +108,8 @@ func (r *stateStore) QueryContractNullifiers(ctx context.Context, domain string,
 	err = r.c.CallRPC(ctx, &states, "pstate_queryContractNullifiers", domain, contractAddress, schemaRef, query)
 	return
 }
+
+func (r *stateStore) MigrateStates(ctx context.Context, domain string) (migrated int, err error) {
+	err = r.c.CallRPC(ctx, &migrated, "pstate_migrateStates", domain)
+	return
+}
@@ -17,6 +17,9 @@ package pldclient
 
 import (
 	"context"
+
+	"github.com/kaleido-io/paladin/toolkit/pkg/pldapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/query"
 )
 
 type Transport interface {
@@ -24,6 +27,7 @@ type Transport interface {
 
 	NodeName(ctx context.Context) (nodeName string, err error)
 	LocalTransports(ctx context.Context) (transportNames []string, err error)
+	QueryDeadLetters(ctx context.Context, jq *query.QueryJSON) (deadLetters []*pldapi.TransportDeadLetter, err error)
 }
 
 // This is necessary because there's no way to introspect function parameter names via reflection
@@ -42,6 +46,10 @@ var transportInfo = &rpcModuleInfo{
 			Inputs: []string{"transportName"},
 			Output: "transportDetailsStr",
 		},
+		"transport_queryDeadLetters": {
+			Inputs: []string{"query"},
+			Output: "deadLetters",
+		},
 	},
 }
 
@@ -68,3 +76,8 @@ func (t *transport) LocalTransportDetails(ctx context.Context, transportName str
 	err = t.c.CallRPC(ctx, &transportDetailsStr, "transport_localTransportDetails", transportName)
 	return
 }
+
+func (t *transport) QueryDeadLetters(ctx context.Context, jq *query.QueryJSON) (deadLetters []*pldapi.TransportDeadLetter, err error) {
+	err = t.c.CallRPC(ctx, &deadLetters, "transport_queryDeadLetters", jq)
+	return
+}
@@ -41,6 +41,10 @@ type DomainAPI interface {
 	InitCall(context.Context, *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
 	ExecCall(context.Context, *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
 	BuildReceipt(context.Context, *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error)
+	ValidateAssembled(context.Context, *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error)
+	BuildAttestationPayload(context.Context, *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error)
+	MigrateState(context.Context, *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error)
+	TransactionConfirmed(context.Context, *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error)
 }
 
 type DomainCallbacks interface {
@@ -195,6 +199,22 @@ func (dp *domainHandler) RequestToPlugin(ctx context.Context, iReq PluginMessage
 		resMsg := &prototk.DomainMessage_BuildReceiptRes{}
 		resMsg.BuildReceiptRes, err = dp.api.BuildReceipt(ctx, input.BuildReceipt)
 		res.ResponseFromDomain = resMsg
+	case *prototk.DomainMessage_ValidateAssembled:
+		resMsg := &prototk.DomainMessage_ValidateAssembledRes{}
+		resMsg.ValidateAssembledRes, err = dp.api.ValidateAssembled(ctx, input.ValidateAssembled)
+		res.ResponseFromDomain = resMsg
+	case *prototk.DomainMessage_BuildAttestationPayload:
+		resMsg := &prototk.DomainMessage_BuildAttestationPayloadRes{}
+		resMsg.BuildAttestationPayloadRes, err = dp.api.BuildAttestationPayload(ctx, input.BuildAttestationPayload)
+		res.ResponseFromDomain = resMsg
+	case *prototk.DomainMessage_MigrateState:
+		resMsg := &prototk.DomainMessage_MigrateStateRes{}
+		resMsg.MigrateStateRes, err = dp.api.MigrateState(ctx, input.MigrateState)
+		res.ResponseFromDomain = resMsg
+	case *prototk.DomainMessage_TransactionConfirmed:
+		resMsg := &prototk.DomainMessage_TransactionConfirmedRes{}
+		resMsg.TransactionConfirmedRes, err = dp.api.TransactionConfirmed(ctx, input.TransactionConfirmed)
+		res.ResponseFromDomain = resMsg
 	default:
 		err = i18n.NewError(ctx, tkmsgs.MsgPluginUnsupportedRequest, input)
 	}
@@ -246,22 +266,26 @@ func (dp *domainHandler) RecoverSigner(ctx context.Context, req *prototk.Recover
 }
 
 type DomainAPIFunctions struct {
-	ConfigureDomain     func(context.Context, *prototk.ConfigureDomainRequest) (*prototk.ConfigureDomainResponse, error)
-	InitDomain          func(context.Context, *prototk.InitDomainRequest) (*prototk.InitDomainResponse, error)
-	InitDeploy          func(context.Context, *prototk.InitDeployRequest) (*prototk.InitDeployResponse, error)
-	PrepareDeploy       func(context.Context, *prototk.PrepareDeployRequest) (*prototk.PrepareDeployResponse, error)
-	InitContract        func(context.Context, *prototk.InitContractRequest) (*prototk.InitContractResponse, error)
-	InitTransaction     func(context.Context, *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error)
-	AssembleTransaction func(context.Context, *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error)
-	EndorseTransaction  func(context.Context, *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error)
-	PrepareTransaction  func(context.Context, *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error)
-	HandleEventBatch    func(context.Context, *prototk.HandleEventBatchRequest) (*prototk.HandleEventBatchResponse, error)
-	Sign                func(context.Context, *prototk.SignRequest) (*prototk.SignResponse, error)
-	GetVerifier         func(context.Context, *prototk.GetVerifierRequest) (*prototk.GetVerifierResponse, error)
-	ValidateStateHashes func(context.Context, *prototk.ValidateStateHashesRequest) (*prototk.ValidateStateHashesResponse, error)
-	InitCall            func(context.Context, *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
-	ExecCall            func(context.Context, *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
-	BuildReceipt        func(context.Context, *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error)
+	ConfigureDomain         func(context.Context, *prototk.ConfigureDomainRequest) (*prototk.ConfigureDomainResponse, error)
+	InitDomain              func(context.Context, *prototk.InitDomainRequest) (*prototk.InitDomainResponse, error)
+	InitDeploy              func(context.Context, *prototk.InitDeployRequest) (*prototk.InitDeployResponse, error)
+	PrepareDeploy           func(context.Context, *prototk.PrepareDeployRequest) (*prototk.PrepareDeployResponse, error)
+	InitContract            func(context.Context, *prototk.InitContractRequest) (*prototk.InitContractResponse, error)
+	InitTransaction         func(context.Context, *prototk.InitTransactionRequest) (*prototk.InitTransactionResponse, error)
+	AssembleTransaction     func(context.Context, *prototk.AssembleTransactionRequest) (*prototk.AssembleTransactionResponse, error)
+	EndorseTransaction      func(context.Context, *prototk.EndorseTransactionRequest) (*prototk.EndorseTransactionResponse, error)
+	PrepareTransaction      func(context.Context, *prototk.PrepareTransactionRequest) (*prototk.PrepareTransactionResponse, error)
+	HandleEventBatch        func(context.Context, *prototk.HandleEventBatchRequest) (*prototk.HandleEventBatchResponse, error)
+	Sign                    func(context.Context, *prototk.SignRequest) (*prototk.SignResponse, error)
+	GetVerifier             func(context.Context, *prototk.GetVerifierRequest) (*prototk.GetVerifierResponse, error)
+	ValidateStateHashes     func(context.Context, *prototk.ValidateStateHashesRequest) (*prototk.ValidateStateHashesResponse, error)
+	InitCall                func(context.Context, *prototk.InitCallRequest) (*prototk.InitCallResponse, error)
+	ExecCall                func(context.Context, *prototk.ExecCallRequest) (*prototk.ExecCallResponse, error)
+	BuildReceipt            func(context.Context, *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error)
+	ValidateAssembled       func(context.Context, *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error)
+	BuildAttestationPayload func(context.Context, *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error)
+	MigrateState            func(context.Context, *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error)
+	TransactionConfirmed    func(context.Context, *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error)
 }
 
 type DomainAPIBase struct {
@@ -331,3 +355,19 @@ func (db *DomainAPIBase) ExecCall(ctx context.Context, req *prototk.ExecCallRequ
 func (db *DomainAPIBase) BuildReceipt(ctx context.Context, req *prototk.BuildReceiptRequest) (*prototk.BuildReceiptResponse, error) {
 	return callPluginImpl(ctx, req, db.Functions.BuildReceipt)
 }
+
+func (db *DomainAPIBase) ValidateAssembled(ctx context.Context, req *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+	return callPluginImpl(ctx, req, db.Functions.ValidateAssembled)
+}
+
+func (db *DomainAPIBase) BuildAttestationPayload(ctx context.Context, req *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+	return callPluginImpl(ctx, req, db.Functions.BuildAttestationPayload)
+}
+
+func (db *DomainAPIBase) MigrateState(ctx context.Context, req *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+	return callPluginImpl(ctx, req, db.Functions.MigrateState)
+}
+
+func (db *DomainAPIBase) TransactionConfirmed(ctx context.Context, req *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+	return callPluginImpl(ctx, req, db.Functions.TransactionConfirmed)
+}
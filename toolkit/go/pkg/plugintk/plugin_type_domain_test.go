@@ -386,6 +386,74 @@ func TestDomainFunction_BuildReceipt(t *testing.T) {
 	})
 }
 
+func TestDomainFunction_ValidateAssembled(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupDomainTests(t)
+	defer done()
+
+	// ValidateAssembled - paladin to domain
+	funcs.ValidateAssembled = func(ctx context.Context, cdr *prototk.ValidateAssembledRequest) (*prototk.ValidateAssembledResponse, error) {
+		return &prototk.ValidateAssembledResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.DomainMessage) {
+		req.RequestToDomain = &prototk.DomainMessage_ValidateAssembled{
+			ValidateAssembled: &prototk.ValidateAssembledRequest{},
+		}
+	}, func(res *prototk.DomainMessage) {
+		assert.IsType(t, &prototk.DomainMessage_ValidateAssembledRes{}, res.ResponseFromDomain)
+	})
+}
+
+func TestDomainFunction_BuildAttestationPayload(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupDomainTests(t)
+	defer done()
+
+	// BuildAttestationPayload - paladin to domain
+	funcs.BuildAttestationPayload = func(ctx context.Context, cdr *prototk.BuildAttestationPayloadRequest) (*prototk.BuildAttestationPayloadResponse, error) {
+		return &prototk.BuildAttestationPayloadResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.DomainMessage) {
+		req.RequestToDomain = &prototk.DomainMessage_BuildAttestationPayload{
+			BuildAttestationPayload: &prototk.BuildAttestationPayloadRequest{},
+		}
+	}, func(res *prototk.DomainMessage) {
+		assert.IsType(t, &prototk.DomainMessage_BuildAttestationPayloadRes{}, res.ResponseFromDomain)
+	})
+}
+
+func TestDomainFunction_MigrateState(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupDomainTests(t)
+	defer done()
+
+	// MigrateState - paladin to domain
+	funcs.MigrateState = func(ctx context.Context, cdr *prototk.MigrateStateRequest) (*prototk.MigrateStateResponse, error) {
+		return &prototk.MigrateStateResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.DomainMessage) {
+		req.RequestToDomain = &prototk.DomainMessage_MigrateState{
+			MigrateState: &prototk.MigrateStateRequest{},
+		}
+	}, func(res *prototk.DomainMessage) {
+		assert.IsType(t, &prototk.DomainMessage_MigrateStateRes{}, res.ResponseFromDomain)
+	})
+}
+
+func TestDomainFunction_TransactionConfirmed(t *testing.T) {
+	_, exerciser, funcs, _, _, done := setupDomainTests(t)
+	defer done()
+
+	// TransactionConfirmed - paladin to domain
+	funcs.TransactionConfirmed = func(ctx context.Context, cdr *prototk.TransactionConfirmedRequest) (*prototk.TransactionConfirmedResponse, error) {
+		return &prototk.TransactionConfirmedResponse{}, nil
+	}
+	exerciser.doExchangeToPlugin(func(req *prototk.DomainMessage) {
+		req.RequestToDomain = &prototk.DomainMessage_TransactionConfirmed{
+			TransactionConfirmed: &prototk.TransactionConfirmedRequest{},
+		}
+	}, func(res *prototk.DomainMessage) {
+		assert.IsType(t, &prototk.DomainMessage_TransactionConfirmedRes{}, res.ResponseFromDomain)
+	})
+}
+
 func TestDomainRequestError(t *testing.T) {
 	_, exerciser, _, _, _, done := setupDomainTests(t)
 	defer done()
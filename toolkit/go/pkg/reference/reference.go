@@ -91,6 +91,18 @@ var allTypes = []interface{}{
 		},
 	},
 	pldapi.RegistryProperty{},
+	pldapi.RegistrySnapshot{
+		Entries: []*pldapi.RegistryEntry{{
+			OnChainLocation: &pldapi.OnChainLocation{},
+			ActiveFlag:      &pldapi.ActiveFlag{},
+		}},
+		Properties: []*pldapi.RegistryProperty{{
+			OnChainLocation: &pldapi.OnChainLocation{},
+			ActiveFlag:      &pldapi.ActiveFlag{},
+		}},
+	},
+	pldapi.RegistryNodeTransportEntry{},
+	pldapi.TransportDeadLetter{},
 	pldapi.OnChainLocation{},
 	pldapi.IndexedBlock{},
 	pldapi.IndexedTransaction{},
@@ -76,16 +76,22 @@ func (r *Retry) Do(ctx context.Context, do func(attempt int) (retryable bool, er
 	}
 }
 
+// Delay returns the backoff delay that applies after the given number of failures
+func (r *Retry) Delay(failureCount int) time.Duration {
+	retryDelay := r.initialDelay
+	for i := 0; i < (failureCount - 1); i++ {
+		retryDelay = time.Duration(float64(retryDelay) * r.factor)
+		if retryDelay > r.maxDelay {
+			retryDelay = r.maxDelay
+			break
+		}
+	}
+	return retryDelay
+}
+
 func (r *Retry) WaitDelay(ctx context.Context, failureCount int) error {
 	if failureCount > 0 {
-		retryDelay := r.initialDelay
-		for i := 0; i < (failureCount - 1); i++ {
-			retryDelay = time.Duration(float64(retryDelay) * r.factor)
-			if retryDelay > r.maxDelay {
-				retryDelay = r.maxDelay
-				break
-			}
-		}
+		retryDelay := r.Delay(failureCount)
 		log.L(ctx).Debugf("Retrying after %.2f (failures=%d)", retryDelay.Seconds(), failureCount)
 		select {
 		case <-time.After(retryDelay):
@@ -96,6 +102,11 @@ func (r *Retry) WaitDelay(ctx context.Context, failureCount int) error {
 	return nil
 }
 
+// MaxAttempts returns the attempts after which a limited retry pops (unlimited if zero)
+func (r *Retry) MaxAttempts() int {
+	return r.maxAttempts
+}
+
 // SetMaxAttempts is useful for unit tests
 func (r *Retry) UTSetMaxAttempts(maxAttempts int) {
 	r.maxAttempts = maxAttempts
@@ -112,6 +112,18 @@ func TestDefaultsOverride(t *testing.T) {
 	assert.Equal(t, 1*time.Millisecond, r.initialDelay)
 	assert.Equal(t, 2*time.Millisecond, r.maxDelay)
 	assert.Equal(t, 3.14, r.factor)
-	assert.Equal(t, 42, r.maxAttempts)
+	assert.Equal(t, 42, r.MaxAttempts())
 
 }
+
+func TestRetryDelay(t *testing.T) {
+	r := NewRetryIndefinite(&pldconf.RetryConfig{
+		InitialDelay: confutil.P("1s"),
+		MaxDelay:     confutil.P("5s"),
+		Factor:       confutil.P(2.0),
+	})
+	assert.Equal(t, 1*time.Second, r.Delay(1))
+	assert.Equal(t, 2*time.Second, r.Delay(2))
+	assert.Equal(t, 4*time.Second, r.Delay(3))
+	assert.Equal(t, 5*time.Second, r.Delay(4))
+}
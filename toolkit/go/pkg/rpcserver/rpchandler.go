@@ -58,6 +58,8 @@ func (s *rpcServer) rpcHandler(ctx context.Context, r io.Reader, wsc *webSocketC
 			return s.processSubscribe(ctx, &rpcRequest, wsc)
 		} else if rpcRequest.Method == "eth_unsubscribe" {
 			return s.processUnsubscribe(ctx, &rpcRequest, wsc)
+		} else if res, ok, handled := s.processModuleSubscription(ctx, &rpcRequest, wsc); handled {
+			return res, ok
 		}
 	}
 	return s.processRPC(ctx, &rpcRequest)
@@ -22,14 +22,16 @@ import (
 )
 
 type RPCModule struct {
-	group   string
-	methods map[string]RPCHandler
+	group         string
+	methods       map[string]RPCHandler
+	subscriptions map[string]RPCSubscriptionHandler
 }
 
 func NewRPCModule(prefix string) *RPCModule {
 	return &RPCModule{
-		group:   strings.SplitN(prefix, "_", 2)[0],
-		methods: map[string]RPCHandler{},
+		group:         strings.SplitN(prefix, "_", 2)[0],
+		methods:       map[string]RPCHandler{},
+		subscriptions: map[string]RPCSubscriptionHandler{},
 	}
 }
 
@@ -40,13 +42,37 @@ func NewRPCModule(prefix string) *RPCModule {
 // This is inspired by strong adoption of this convention in the Ethereum ecosystem, although
 // it is not part of the JSON/RPC 2.0 standard.
 func (m *RPCModule) Add(method string, handler RPCHandler) *RPCModule {
+	m.checkMethod(method)
+	m.methods[method] = handler
+	return m
+}
+
+// Subscription methods are only available over WebSockets. Once the subscribe request succeeds, the
+// module streams "group_subscription" notifications to the client until it calls "group_unsubscribe"
+// with the subscription ID returned, or disconnects.
+func (m *RPCModule) AddSubscription(method string, handler RPCSubscriptionHandler) *RPCModule {
+	m.checkMethod(method)
+	if method == m.unsubscribeMethod() {
+		panic(fmt.Sprintf("reserved method: %s", method))
+	}
+	m.subscriptions[method] = handler
+	return m
+}
+
+func (m *RPCModule) checkMethod(method string) {
 	prefix := m.group + "_"
 	if !strings.HasPrefix(method, prefix) {
 		panic(fmt.Sprintf("invalid prefix %s (expected=%s)", method, prefix))
 	}
-	if m.methods[method] != nil {
+	if m.methods[method] != nil || m.subscriptions[method] != nil {
 		panic(fmt.Sprintf("duplicate method: %s", method))
 	}
-	m.methods[method] = handler
-	return m
+}
+
+func (m *RPCModule) notificationMethod() string {
+	return m.group + "_subscription"
+}
+
+func (m *RPCModule) unsubscribeMethod() string {
+	return m.group + "_unsubscribe"
 }
@@ -41,6 +41,10 @@ func (s *rpcServer) processRPC(ctx context.Context, rpcReq *rpcclient.RPCRequest
 	module := s.rpcModules[group]
 	if module != nil {
 		handler = module.methods[rpcReq.Method]
+		if handler == nil && (module.subscriptions[rpcReq.Method] != nil || (len(module.subscriptions) > 0 && rpcReq.Method == module.unsubscribeMethod())) {
+			err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCWebSocketOnly, rpcReq.Method)
+			return rpcclient.NewRPCErrorResponse(err, rpcReq.ID, rpcclient.RPCCodeInvalidRequest), false
+		}
 	}
 	if handler == nil {
 		err := i18n.NewError(ctx, tkmsgs.MsgJSONRPCUnsupportedMethod, rpcReq.Method)
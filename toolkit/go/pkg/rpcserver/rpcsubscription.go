@@ -0,0 +1,211 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+)
+
+// RPCSubscription is passed to the implementation of a subscription method, to stream results to the client
+type RPCSubscription interface {
+	ID() string
+	// Done is closed when the client unsubscribes or disconnects
+	Done() <-chan struct{}
+	// Send blocks until the notification is queued to the client, and fails only if the subscription is closed
+	Send(ctx context.Context, result any) error
+}
+
+// RPCSubscriptionHandler should not be implemented directly - use RPCSubscription0 ... RPCSubscription2 to implement your function
+type RPCSubscriptionHandler interface {
+	Subscribe(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse
+}
+
+func SubscriptionHandlerFunc(fn func(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse) RPCSubscriptionHandler {
+	return &rpcSubscriptionHandlerFunc{fn: fn}
+}
+
+type rpcSubscriptionHandlerFunc struct {
+	fn func(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse
+}
+
+func (hf *rpcSubscriptionHandlerFunc) Subscribe(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse {
+	return hf.fn(ctx, req, sub)
+}
+
+// The implementation is called on the subscribe request, and must start its own routine to stream results
+// until the subscription is done. If it returns an error, the subscription is closed and the error returned.
+// Otherwise the result of the subscribe request is the subscription ID.
+
+func RPCSubscription0(impl func(ctx context.Context, sub RPCSubscription) error) RPCSubscriptionHandler {
+	return SubscriptionHandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse {
+		code, err := parseParams(ctx, req)
+		if err == nil {
+			err = impl(ctx, sub)
+		}
+		return mapResponse(ctx, req, sub.ID(), code, err)
+	})
+}
+
+func RPCSubscription1[P0 any](impl func(ctx context.Context, sub RPCSubscription, param0 P0) error) RPCSubscriptionHandler {
+	return SubscriptionHandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse {
+		param0 := new(P0)
+		code, err := parseParams(ctx, req, param0)
+		if err == nil {
+			err = impl(ctx, sub, *param0)
+		}
+		return mapResponse(ctx, req, sub.ID(), code, err)
+	})
+}
+
+func RPCSubscription2[P0 any, P1 any](impl func(ctx context.Context, sub RPCSubscription, param0 P0, param1 P1) error) RPCSubscriptionHandler {
+	return SubscriptionHandlerFunc(func(ctx context.Context, req *rpcclient.RPCRequest, sub RPCSubscription) *rpcclient.RPCResponse {
+		param0 := new(P0)
+		param1 := new(P1)
+		code, err := parseParams(ctx, req, param0, param1)
+		if err == nil {
+			err = impl(ctx, sub, *param0, *param1)
+		}
+		return mapResponse(ctx, req, sub.ID(), code, err)
+	})
+}
+
+type moduleSubscription struct {
+	c                  *webSocketConnection
+	id                 string
+	notificationMethod string
+	ctx                context.Context
+	cancelCtx          context.CancelFunc
+	ready              chan struct{}
+}
+
+func (ms *moduleSubscription) ID() string {
+	return ms.id
+}
+
+func (ms *moduleSubscription) Done() <-chan struct{} {
+	return ms.ctx.Done()
+}
+
+func (ms *moduleSubscription) Send(ctx context.Context, result any) error {
+	b, err := json.Marshal(&ethPublication{
+		JSONRPC: "2.0",
+		Method:  ms.notificationMethod,
+		Params: ethPublicationParams{
+			Subscription: ms.id,
+			Result:       result,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// Notifications are held until the subscribe response has been queued to the client
+	select {
+	case <-ms.ready:
+	case <-ms.ctx.Done():
+		return i18n.NewError(ctx, tkmsgs.MsgJSONRPCSubscriptionClosed, ms.id)
+	case <-ctx.Done():
+		return i18n.NewError(ctx, tkmsgs.MsgContextCanceled)
+	}
+	select {
+	case ms.c.send <- b:
+		return nil
+	case <-ms.ctx.Done():
+		return i18n.NewError(ctx, tkmsgs.MsgJSONRPCSubscriptionClosed, ms.id)
+	case <-ctx.Done():
+		return i18n.NewError(ctx, tkmsgs.MsgContextCanceled)
+	}
+}
+
+// Returns handled=false if the request is not for a subscription method of a registered module.
+// A successful subscribe response is sent directly, before any notification, so the result is nil.
+func (s *rpcServer) processModuleSubscription(ctx context.Context, rpcReq *rpcclient.RPCRequest, wsc *webSocketConnection) (res interface{}, ok, handled bool) {
+	module, handler := s.lookupSubscription(rpcReq.Method)
+	if module == nil {
+		return nil, false, false
+	}
+	if rpcReq.Method == module.unsubscribeMethod() {
+		res, ok = s.processModuleUnsubscribe(ctx, rpcReq, wsc)
+		return res, ok, true
+	}
+
+	sub := &moduleSubscription{
+		c:                  wsc,
+		id:                 fftypes.NewUUID().String(),
+		notificationMethod: module.notificationMethod(),
+		ready:              make(chan struct{}),
+	}
+	sub.ctx, sub.cancelCtx = context.WithCancel(wsc.ctx)
+	s.wsMux.Lock()
+	wsc.moduleSubscriptions = append(wsc.moduleSubscriptions, sub)
+	s.wsMux.Unlock()
+
+	subRes := handler.Subscribe(sub.ctx, rpcReq, sub)
+	if subRes.Error != nil {
+		s.removeModuleSubscription(wsc, sub.id)
+		return subRes, false, true
+	}
+	wsc.sendMessage(subRes)
+	close(sub.ready)
+	return nil, true, true
+}
+
+func (s *rpcServer) lookupSubscription(method string) (*RPCModule, RPCSubscriptionHandler) {
+	for _, module := range s.rpcModules {
+		if len(module.subscriptions) == 0 {
+			continue
+		}
+		if method == module.unsubscribeMethod() {
+			return module, nil
+		}
+		if handler := module.subscriptions[method]; handler != nil {
+			return module, handler
+		}
+	}
+	return nil, nil
+}
+
+func (s *rpcServer) processModuleUnsubscribe(ctx context.Context, rpcReq *rpcclient.RPCRequest, wsc *webSocketConnection) (*rpcclient.RPCResponse, bool) {
+	var subID string
+	code, err := parseParams(ctx, rpcReq, &subID)
+	if err != nil {
+		return rpcclient.NewRPCErrorResponse(err, rpcReq.ID, code), false
+	}
+	return mapResponse(ctx, rpcReq, s.removeModuleSubscription(wsc, subID), 0, nil), true
+}
+
+func (s *rpcServer) removeModuleSubscription(wsc *webSocketConnection, subID string) (found bool) {
+	s.wsMux.Lock()
+	defer s.wsMux.Unlock()
+
+	var newSubs []*moduleSubscription
+	for _, sub := range wsc.moduleSubscriptions {
+		if sub.id == subID {
+			found = true
+			sub.cancelCtx()
+		} else {
+			newSubs = append(newSubs, sub)
+		}
+	}
+	wsc.moduleSubscriptions = newSubs
+	return found
+}
@@ -0,0 +1,219 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/gorilla/websocket"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/rpcclient"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWSMessage struct {
+	ID     *tktypes.RawJSON `json:"id"`
+	Method string           `json:"method"`
+	Result tktypes.RawJSON  `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       tktypes.RawJSON `json:"result"`
+	} `json:"params"`
+}
+
+func testWSConnect(t *testing.T, url string) (send func(id int, method string, params ...any), recv func() *testWSMessage, done func()) {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	send = func(id int, method string, params ...any) {
+		err := conn.WriteJSON(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"method":  method,
+			"params":  params,
+		})
+		require.NoError(t, err)
+	}
+	recv = func() *testWSMessage {
+		err := conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		require.NoError(t, err)
+		_, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var msg testWSMessage
+		err = json.Unmarshal(b, &msg)
+		require.NoError(t, err)
+		return &msg
+	}
+	return send, recv, func() { _ = conn.Close() }
+}
+
+func TestModuleSubscribeNotifyUnsubscribe(t *testing.T) {
+	url, s, done := newTestServerWebSockets(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	subDone := make(chan struct{})
+	s.Register(NewRPCModule("ut").
+		AddSubscription("ut_subscribeCounter", RPCSubscription1(func(ctx context.Context, sub RPCSubscription, count int) error {
+			go func() {
+				defer close(subDone)
+				for i := 0; i < count; i++ {
+					if err := sub.Send(ctx, i); err != nil {
+						return
+					}
+				}
+				<-sub.Done()
+			}()
+			return nil
+		})))
+
+	send, recv, wsDone := testWSConnect(t, url)
+	defer wsDone()
+
+	// Notifications are always delivered after the subscribe response
+	send(1, "ut_subscribeCounter", 2)
+	res := recv()
+	assert.Nil(t, res.Error)
+	var subID string
+	err := json.Unmarshal(res.Result, &subID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, subID)
+
+	for i := 0; i < 2; i++ {
+		n := recv()
+		assert.Equal(t, "ut_subscription", n.Method)
+		assert.Equal(t, subID, n.Params.Subscription)
+		assert.Equal(t, fmt.Sprintf("%d", i), n.Params.Result.String())
+	}
+
+	send(2, "ut_unsubscribe", subID)
+	res = recv()
+	assert.Nil(t, res.Error)
+	assert.Equal(t, "true", res.Result.String())
+	<-subDone
+
+	send(3, "ut_unsubscribe", subID)
+	res = recv()
+	assert.Nil(t, res.Error)
+	assert.Equal(t, "false", res.Result.String())
+
+	send(4, "ut_unsubscribe")
+	res = recv()
+	assert.Regexp(t, "PD020703", res.Error.Message)
+
+	send(5, "ut_subscribeCounter", "wrong")
+	res = recv()
+	assert.Regexp(t, "PD020704", res.Error.Message)
+}
+
+func TestModuleSubscribeFailAndDisconnect(t *testing.T) {
+	url, s, done := newTestServerWebSockets(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	subs := make(chan RPCSubscription, 1)
+	s.Register(NewRPCModule("ut").
+		AddSubscription("ut_subscribeFail", RPCSubscription0(func(ctx context.Context, sub RPCSubscription) error {
+			return errors.New("pop")
+		})).
+		AddSubscription("ut_subscribeIdle", RPCSubscription0(func(ctx context.Context, sub RPCSubscription) error {
+			subs <- sub
+			return nil
+		})))
+
+	send, recv, wsDone := testWSConnect(t, url)
+
+	send(1, "ut_subscribeFail")
+	res := recv()
+	assert.Regexp(t, "pop", res.Error.Message)
+
+	send(2, "ut_subscribeIdle")
+	res = recv()
+	assert.Nil(t, res.Error)
+	sub := <-subs
+
+	var wsConn *webSocketConnection
+	s.wsMux.Lock()
+	for _, wsConn = range s.wsConnections {
+	}
+	assert.Len(t, wsConn.moduleSubscriptions, 1)
+	s.wsMux.Unlock()
+
+	// Closing the connection closes the subscription
+	wsDone()
+	<-sub.Done()
+	err := sub.Send(context.Background(), "anything")
+	assert.Regexp(t, "PD020707", err)
+}
+
+func TestModuleSubscribeSendContextCancelled(t *testing.T) {
+	sub := &moduleSubscription{
+		c:     &webSocketConnection{send: make(chan []byte)},
+		id:    "sub1",
+		ready: make(chan struct{}),
+	}
+	sub.ctx, sub.cancelCtx = context.WithCancel(context.Background())
+	defer sub.cancelCtx()
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancelCtx()
+	err := sub.Send(ctx, "anything")
+	assert.Regexp(t, "PD020000", err)
+
+	close(sub.ready)
+	err = sub.Send(ctx, "anything")
+	assert.Regexp(t, "PD020000", err)
+
+	err = sub.Send(ctx, map[bool]bool{false: true})
+	assert.Error(t, err)
+}
+
+func TestModuleSubscribeHTTPNotSupported(t *testing.T) {
+	url, s, done := newTestServerHTTP(t, &pldconf.RPCServerConfig{})
+	defer done()
+
+	s.Register(NewRPCModule("ut").
+		AddSubscription("ut_subscribeThings", RPCSubscription2(func(ctx context.Context, sub RPCSubscription, p0, p1 string) error {
+			return nil
+		})))
+
+	c := rpcclient.WrapRestyClient(resty.New().SetBaseURL(url))
+
+	var result tktypes.RawJSON
+	rpcErr := c.CallRPC(context.Background(), &result, "ut_subscribeThings", "a", "b")
+	assert.Regexp(t, "PD020706", rpcErr)
+	rpcErr = c.CallRPC(context.Background(), &result, "ut_unsubscribe", "sub1")
+	assert.Regexp(t, "PD020706", rpcErr)
+}
+
+func TestModuleSubscriptionReservedMethod(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRPCModule("ut").AddSubscription("ut_unsubscribe", nil)
+	})
+	assert.Panics(t, func() {
+		NewRPCModule("ut").Add("ut_subscribeThings", RPCMethod0(func(ctx context.Context) (string, error) { return "", nil })).
+			AddSubscription("ut_subscribeThings", nil)
+	})
+}
@@ -171,6 +171,9 @@ type webSocketConnection struct {
 	subscriptions []*ethSubscription // TODO: Decide JSON/RPC sub model
 	send          chan ([]byte)
 	closing       chan (struct{})
+
+	// subscriptions to methods registered by modules, which are cancelled with the connection context
+	moduleSubscriptions []*moduleSubscription
 }
 
 type ethPublicationParams struct {
@@ -217,7 +220,9 @@ func (c *webSocketConnection) sender() {
 
 func (c *webSocketConnection) handleMessage(payload []byte) {
 	res, _ := c.server.rpcHandler(c.ctx, bytes.NewBuffer(payload), c)
-	c.sendMessage(res)
+	if res != nil {
+		c.sendMessage(res)
+	}
 }
 
 func (c *webSocketConnection) sendMessage(res interface{}) {
@@ -338,3 +338,40 @@ func TestHDInitGenSeed(t *testing.T) {
 	assert.Len(t, generatedSeed, 32)
 	assert.NotEqual(t, make([]byte, 32), generatedSeed) // not zero
 }
+
+func TestHDFilesystemSeedReproducibleOnRestart(t *testing.T) {
+
+	ctx := context.Background()
+	conf := &signerapi.ConfigNoExt{
+		KeyDerivation: pldconf.KeyDerivationConfig{
+			Type: pldconf.KeyDerivationTypeBIP32,
+		},
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path: confutil.P(t.TempDir()),
+			},
+		},
+	}
+	resolve := func() *signerapi.ResolveKeyResponse {
+		sm, err := NewSigningModule(ctx, conf)
+		require.NoError(t, err)
+		defer sm.Close()
+		res, err := sm.Resolve(ctx, &signerapi.ResolveKeyRequest{
+			RequiredIdentifiers: []*signerapi.PublicKeyIdentifierType{{Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS}},
+			Name:                "key1",
+			Index:               5,
+			Path:                []*signerapi.ResolveKeyPathSegment{{Name: "bob", Index: 2}},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	// The seed is generated on first start, and the same keys are derived from it after a restart
+	res1 := resolve()
+	res2 := resolve()
+	assert.Equal(t, "m/44'/60'/2'/5", res1.KeyHandle)
+	assert.Equal(t, res1.KeyHandle, res2.KeyHandle)
+	assert.Equal(t, res1.Identifiers[0].Verifier, res2.Identifiers[0].Verifier)
+
+}
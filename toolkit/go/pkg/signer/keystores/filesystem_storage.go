@@ -17,19 +17,28 @@ package keystores
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"io/fs"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-signer/pkg/keystorev3"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/config/pkg/pldconf"
 	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
 	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
@@ -37,11 +46,27 @@ import (
 
 type filesystemStoreFactory[C signerapi.ExtensibleConfig] struct{}
 
+// Separates a key handle from the version of a previous key material, after the key has been rotated.
+// This cannot clash with a key name, as names are path escaped in the key handle.
+const keyVersionSeparator = "#"
+
+// Key handles are serialized across a fixed set of locks, so that a key and password file pair are never
+// read while a rotation is part way through replacing them
+const keyHandleLockCount = 64
+
+// Identifies a password file that has been encrypted with the master key, rather than holding the plaintext password
+const encryptedPasswordPrefix = "aes256gcm:"
+
 type filesystemStore struct {
-	cache    cache.Cache[string, keystorev3.WalletFile]
-	path     string
-	fileMode os.FileMode
-	dirMode  os.FileMode
+	cache            cache.Cache[string, keystorev3.WalletFile]
+	path             string
+	fileMode         os.FileMode
+	dirMode          os.FileMode
+	masterKey        []byte // when set, password files are encrypted with this key
+	migratePlaintext bool
+
+	// held while reading, creating or rotating the files of a key handle
+	keyHandleLocks [keyHandleLockCount]sync.Mutex
 }
 
 func NewFilesystemStoreFactory[C signerapi.ExtensibleConfig]() signerapi.KeyStoreFactory[C] {
@@ -60,14 +85,119 @@ func (fsf *filesystemStoreFactory[C]) NewKeyStore(ctx context.Context, eConf C)
 	if err != nil || !pathInfo.IsDir() {
 		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleBadPathError, *pldconf.FileSystemDefaults.Path)
 	}
+	masterKey, err := loadMasterKey(ctx, &conf.PasswordEncryption)
+	if err != nil {
+		return nil, err
+	}
 	return &filesystemStore{
-		cache:    cache.NewCache[string, keystorev3.WalletFile](&conf.Cache, &pldconf.FileSystemDefaults.Cache),
-		fileMode: confutil.UnixFileMode(conf.FileMode, *pldconf.FileSystemDefaults.FileMode),
-		dirMode:  confutil.UnixFileMode(conf.DirMode, *pldconf.FileSystemDefaults.DirMode),
-		path:     path,
+		cache:            cache.NewCache[string, keystorev3.WalletFile](&conf.Cache, &pldconf.FileSystemDefaults.Cache),
+		fileMode:         confutil.UnixFileMode(conf.FileMode, *pldconf.FileSystemDefaults.FileMode),
+		dirMode:          confutil.UnixFileMode(conf.DirMode, *pldconf.FileSystemDefaults.DirMode),
+		path:             path,
+		masterKey:        masterKey,
+		migratePlaintext: confutil.Bool(conf.PasswordEncryption.MigratePlaintext, *pldconf.FileSystemDefaults.PasswordEncryption.MigratePlaintext),
 	}, nil
 }
 
+// The master key is optional, and password files are stored in plaintext if it is not configured
+func loadMasterKey(ctx context.Context, conf *pldconf.FileSystemPasswordEncryptionConfig) ([]byte, error) {
+	var hexKey string
+	if envName := confutil.StringOrEmpty(conf.MasterKeyEnv, ""); envName != "" {
+		hexKey = os.Getenv(envName)
+	} else if filename := confutil.StringOrEmpty(conf.MasterKeyFile, ""); filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningMasterKeyLoadFailed)
+		}
+		hexKey = string(data)
+	} else {
+		return nil, nil
+	}
+	masterKey, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil || len(masterKey) != 32 {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningMasterKeyInvalid)
+	}
+	return masterKey, nil
+}
+
+func (fss *filesystemStore) newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(fss.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (fss *filesystemStore) encryptPassword(password []byte) ([]byte, error) {
+	if fss.masterKey == nil {
+		return password, nil
+	}
+	gcm, err := fss.newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, password, nil)
+	return []byte(encryptedPasswordPrefix + hex.EncodeToString(sealed)), nil
+}
+
+// Password files written before encryption was enabled are still plaintext, which is reported so they can be migrated
+func (fss *filesystemStore) decryptPassword(ctx context.Context, passwordFilePath string, passData []byte) (password []byte, plaintext bool, err error) {
+	hexSealed, encrypted := strings.CutPrefix(string(passData), encryptedPasswordPrefix)
+	if !encrypted {
+		return passData, true, nil
+	}
+	if fss.masterKey == nil {
+		return nil, false, i18n.NewError(ctx, tkmsgs.MsgSigningPasswordEncryptedNoMasterKey, passwordFilePath)
+	}
+	gcm, err := fss.newGCM()
+	var sealed []byte
+	if err == nil {
+		sealed, err = hex.DecodeString(hexSealed)
+	}
+	if err == nil && len(sealed) < gcm.NonceSize() {
+		err = fmt.Errorf("truncated")
+	}
+	if err == nil {
+		password, err = gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	}
+	if err != nil {
+		return nil, false, i18n.WrapError(ctx, err, tkmsgs.MsgSigningPasswordDecryptFailed, passwordFilePath)
+	}
+	return password, false, nil
+}
+
+// The password file is replaced by renaming a new file over it, so that the password (and with it the key)
+// cannot be lost if we fail part way through
+func (fss *filesystemStore) migratePasswordFile(ctx context.Context, passwordFilePath string, password []byte) error {
+	passData, err := fss.encryptPassword(password)
+	if err == nil {
+		tmpFilePath := passwordFilePath + ".tmp"
+		err = os.WriteFile(tmpFilePath, passData, fss.fileMode)
+		if err == nil {
+			err = os.Rename(tmpFilePath, passwordFilePath)
+		}
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleFSError)
+	}
+	log.L(ctx).Infof("Encrypted plaintext password file %s", passwordFilePath)
+	return nil
+}
+
+// Previous versions of a key are locked with the key handle they were rotated from
+func (fss *filesystemStore) lockKeyHandle(keyHandle string) func() {
+	keyHandle, _, _ = strings.Cut(keyHandle, keyVersionSeparator)
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(keyHandle))
+	l := &fss.keyHandleLocks[h.Sum32()%keyHandleLockCount]
+	l.Lock()
+	return l.Unlock
+}
+
 func (fss *filesystemStore) validateFilePathKeyHandle(ctx context.Context, keyHandle string, forCreate bool) (absPath string, err error) {
 
 	fullPath := fss.path
@@ -125,7 +255,10 @@ func (fss *filesystemStore) createWalletFile(ctx context.Context, keyFilePath, p
 	// So we use the feature from https://github.com/hyperledger/firefly-signer/pull/70 to remove it entirely
 	wf.Metadata()["address"] = nil
 
-	err = os.WriteFile(passwordFilePath, []byte(password), fss.fileMode)
+	passData, err := fss.encryptPassword([]byte(password))
+	if err == nil {
+		err = os.WriteFile(passwordFilePath, passData, fss.fileMode)
+	}
 	if err == nil {
 		err = os.WriteFile(keyFilePath, wf.JSON(), fss.fileMode)
 	}
@@ -146,8 +279,14 @@ func (fss *filesystemStore) getOrCreateWalletFile(ctx context.Context, keyHandle
 	if cached != nil {
 		return cached, nil
 	}
+	unlock := fss.lockKeyHandle(keyHandle)
+	defer unlock()
+
 	keyFilePath := fmt.Sprintf("%s.key", absPathPrefix)
 	passwordFilePath := fmt.Sprintf("%s.pwd", absPathPrefix)
+	if err := fss.recoverRotation(ctx, keyFilePath, passwordFilePath); err != nil {
+		return nil, err
+	}
 
 	_, checkNotExist := os.Stat(keyFilePath)
 	if os.IsNotExist(checkNotExist) {
@@ -182,7 +321,15 @@ func (fss *filesystemStore) readWalletFile(ctx context.Context, keyFilePath, pas
 		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleBadPassFile, passwordFilePath)
 	}
 
-	return keystorev3.ReadWalletFile(keyData, passData)
+	password, plaintext, err := fss.decryptPassword(ctx, passwordFilePath, passData)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := keystorev3.ReadWalletFile(keyData, password)
+	if err == nil && plaintext && fss.masterKey != nil && fss.migratePlaintext {
+		err = fss.migratePasswordFile(ctx, passwordFilePath, password)
+	}
+	return wf, err
 }
 
 func (fss *filesystemStore) FindOrCreateLoadableKey(ctx context.Context, req *signerapi.ResolveKeyRequest, newKeyMaterial func() ([]byte, error)) (keyMaterial []byte, keyHandle string, err error) {
@@ -212,6 +359,214 @@ func (fss *filesystemStore) LoadKeyMaterial(ctx context.Context, keyHandle strin
 	return wf.PrivateKey(), nil
 }
 
+// ListKeys reconstructs the key handles from the directory tree, with "_" prefixed directories for the
+// path segments and a "-" prefixed key file for the name. Keys are listed in key handle order, and the
+// key handle of the last key in each page is the token to continue from.
+//
+// Only the names are discoverable from the filesystem. Identifiers are not returned, as that would
+// require every key to be decrypted.
+func (fss *filesystemStore) ListKeys(ctx context.Context, req *signerapi.ListKeysRequest) (*signerapi.ListKeysResponse, error) {
+	rootDir := fss.path
+	for _, segment := range req.Path {
+		if len(segment.Name) == 0 {
+			return nil, i18n.NewError(ctx, tkmsgs.MsgSigningModuleBadKeyHandle)
+		}
+		rootDir = path.Join(rootDir, "_"+url.PathEscape(segment.Name))
+	}
+
+	keyHandles := []string{}
+	err := filepath.WalkDir(rootDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if filePath == rootDir && os.IsNotExist(err) {
+				// nothing has been stored under this path
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if filePath != rootDir && !strings.HasPrefix(d.Name(), "_") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, isKeyFile := strings.CutSuffix(d.Name(), ".key")
+		if !isKeyFile || !strings.HasPrefix(name, "-") || strings.Contains(name, keyVersionSeparator) {
+			// previous versions of rotated keys are not listed
+			return nil
+		}
+		relPath, err := filepath.Rel(fss.path, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+		keyHandle := ""
+		if relPath != "." {
+			for _, dir := range strings.Split(filepath.ToSlash(relPath), "/") {
+				keyHandle += strings.TrimPrefix(dir, "_") + "/"
+			}
+		}
+		keyHandles = append(keyHandles, keyHandle+strings.TrimPrefix(name, "-"))
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleFSError)
+	}
+	sort.Strings(keyHandles)
+
+	res := &signerapi.ListKeysResponse{Items: []*signerapi.ListKeyEntry{}}
+	for _, keyHandle := range keyHandles {
+		if req.Continue != "" && keyHandle <= req.Continue {
+			continue
+		}
+		if req.Limit > 0 && len(res.Items) >= req.Limit {
+			res.Next = res.Items[len(res.Items)-1].KeyHandle
+			break
+		}
+		entry, err := fss.listKeyEntry(ctx, keyHandle)
+		if err != nil {
+			return nil, err
+		}
+		res.Items = append(res.Items, entry)
+	}
+	return res, nil
+}
+
+func (fss *filesystemStore) listKeyEntry(ctx context.Context, keyHandle string) (*signerapi.ListKeyEntry, error) {
+	segments := strings.Split(keyHandle, "/")
+	entry := &signerapi.ListKeyEntry{
+		KeyHandle: keyHandle,
+		Path:      make([]*signerapi.ListKeyPathSegment, len(segments)-1),
+	}
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleBadKeyHandle)
+		}
+		if i < len(segments)-1 {
+			entry.Path[i] = &signerapi.ListKeyPathSegment{Name: unescaped}
+		} else {
+			entry.Name = unescaped
+		}
+	}
+	return entry, nil
+}
+
+// RotateKey copies the current key and password files to the next free version of the key handle, and
+// replaces them with a new wallet file. The key handle is locked throughout, so the files are never read
+// part way through being replaced.
+//
+// The new key and password files are written alongside the current files, and the password file is the
+// first to be renamed into place. So if we fail part way through, recoverRotation can always tell on the
+// next load whether to roll the rotation forward, or discard the new files and keep the current key.
+func (fss *filesystemStore) RotateKey(ctx context.Context, keyHandle string, newKeyMaterial func() ([]byte, error)) (string, error) {
+	if strings.Contains(keyHandle, keyVersionSeparator) {
+		return "", i18n.NewError(ctx, tkmsgs.MsgSigningKeyRotateVersionedHandle, keyHandle)
+	}
+	absPathPrefix, err := fss.validateFilePathKeyHandle(ctx, keyHandle, false)
+	if err != nil {
+		return "", err
+	}
+	unlock := fss.lockKeyHandle(keyHandle)
+	defer unlock()
+	// Whatever happens, the next load must read the files again
+	defer fss.cache.Delete(keyHandle)
+
+	keyFilePath := fmt.Sprintf("%s.key", absPathPrefix)
+	passwordFilePath := fmt.Sprintf("%s.pwd", absPathPrefix)
+	if err := fss.recoverRotation(ctx, keyFilePath, passwordFilePath); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(keyFilePath); err != nil {
+		return "", i18n.NewError(ctx, tkmsgs.MsgSigningModuleKeyNotExist, keyHandle)
+	}
+
+	version := 1
+	for {
+		if _, err := os.Stat(fmt.Sprintf("%s%s%d.key", absPathPrefix, keyVersionSeparator, version)); os.IsNotExist(err) {
+			break
+		}
+		version++
+	}
+	previousKeyHandle := fmt.Sprintf("%s%s%d", keyHandle, keyVersionSeparator, version)
+	previousPathPrefix := fmt.Sprintf("%s%s%d", absPathPrefix, keyVersionSeparator, version)
+
+	newKeyFilePath, newPasswordFilePath := keyFilePath+".new", passwordFilePath+".new"
+	if _, err := fss.createWalletFile(ctx, newKeyFilePath, newPasswordFilePath, newKeyMaterial); err != nil {
+		return "", err
+	}
+	// The previous version is copied rather than moved, so the current key is never missing. The key file
+	// is copied last, as it is the existence of the key file that allocates the version.
+	err = fss.copyFile(passwordFilePath, previousPathPrefix+".pwd")
+	if err == nil {
+		err = fss.copyFile(keyFilePath, previousPathPrefix+".key")
+	}
+	if err == nil {
+		err = os.Rename(newPasswordFilePath, passwordFilePath)
+	}
+	if err == nil {
+		err = os.Rename(newKeyFilePath, keyFilePath)
+	}
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleFSError)
+	}
+	log.L(ctx).Infof("Rotated key %s - previous key material is now %s", keyHandle, previousKeyHandle)
+	return previousKeyHandle, nil
+}
+
+// The copy is written to a temporary file and renamed into place, so a partial copy is never visible
+func (fss *filesystemStore) copyFile(srcFilePath, dstFilePath string) error {
+	src, err := os.Open(srcFilePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpFilePath := dstFilePath + ".tmp"
+	dst, err := os.OpenFile(tmpFilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fss.fileMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFilePath, dstFilePath)
+	}
+	return err
+}
+
+// recoverRotation completes or discards a rotation that did not finish, and must be called holding the
+// key handle lock. A new key file without a new password file means the new password file has already
+// been renamed into place, so the new key file must follow it. Otherwise the current files were never
+// replaced, and any new files are discarded.
+func (fss *filesystemStore) recoverRotation(ctx context.Context, keyFilePath, passwordFilePath string) error {
+	newKeyFilePath, newPasswordFilePath := keyFilePath+".new", passwordFilePath+".new"
+	_, newKeyErr := os.Stat(newKeyFilePath)
+	_, newPasswordErr := os.Stat(newPasswordFilePath)
+	var err error
+	switch {
+	case newKeyErr == nil && os.IsNotExist(newPasswordErr):
+		log.L(ctx).Warnf("Completing interrupted rotation of key file %s", keyFilePath)
+		err = os.Rename(newKeyFilePath, keyFilePath)
+	case newKeyErr == nil || newPasswordErr == nil:
+		log.L(ctx).Warnf("Discarding interrupted rotation of key file %s", keyFilePath)
+		if err = os.Remove(newPasswordFilePath); os.IsNotExist(err) {
+			err = nil
+		}
+		if err == nil {
+			if err = os.Remove(newKeyFilePath); os.IsNotExist(err) {
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, tkmsgs.MsgSigningModuleFSError)
+	}
+	return nil
+}
+
 func (fss *filesystemStore) Close() {
 
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
@@ -220,3 +221,378 @@ func TestLoadKeyFail(t *testing.T) {
 	_, err := fs.LoadKeyMaterial(ctx, "wrong")
 	assert.Regexp(t, "PD020806", err)
 }
+
+func TestFileSystemStoreListKeys(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	for _, req := range []*signerapi.ResolveKeyRequest{
+		{Name: "seed"},
+		{Name: "42", Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}, {Name: "blue"}}},
+		{Name: "43", Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}, {Name: "blue"}}},
+		{Name: "key/with spaces", Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}}},
+		{Name: "1", Path: []*signerapi.ResolveKeyPathSegment{{Name: "sally"}}},
+	} {
+		_, _, err := fs.FindOrCreateLoadableKey(ctx, req, func() ([]byte, error) { return []byte("key"), nil })
+		require.NoError(t, err)
+	}
+	// other files in the tree are ignored
+	err := os.WriteFile(path.Join(fs.path, "readme.txt"), []byte{}, fs.fileMode)
+	require.NoError(t, err)
+	err = os.Mkdir(path.Join(fs.path, "other"), fs.dirMode)
+	require.NoError(t, err)
+	err = os.WriteFile(path.Join(fs.path, "other", "-ignored.key"), []byte{}, fs.fileMode)
+	require.NoError(t, err)
+
+	res, err := fs.ListKeys(ctx, &signerapi.ListKeysRequest{})
+	require.NoError(t, err)
+	keyHandles := make([]string, len(res.Items))
+	for i, k := range res.Items {
+		keyHandles[i] = k.KeyHandle
+	}
+	assert.Equal(t, []string{"bob/blue/42", "bob/blue/43", "bob/key%2Fwith%20spaces", "sally/1", "seed"}, keyHandles)
+	assert.Empty(t, res.Next)
+	assert.Equal(t, &signerapi.ListKeyEntry{
+		Name:      "key/with spaces",
+		KeyHandle: "bob/key%2Fwith%20spaces",
+		Path:      []*signerapi.ListKeyPathSegment{{Name: "bob"}},
+	}, res.Items[2])
+
+	// Paginate through the keys under a path
+	res, err = fs.ListKeys(ctx, &signerapi.ListKeysRequest{
+		Limit: 2,
+		Path:  []*signerapi.ListKeyPathSegment{{Name: "bob"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	assert.Equal(t, "bob/blue/42", res.Items[0].KeyHandle)
+	assert.Equal(t, []*signerapi.ListKeyPathSegment{{Name: "bob"}, {Name: "blue"}}, res.Items[0].Path)
+	assert.Equal(t, "42", res.Items[0].Name)
+	assert.Equal(t, "bob/blue/43", res.Next)
+
+	res, err = fs.ListKeys(ctx, &signerapi.ListKeysRequest{
+		Limit:    2,
+		Continue: res.Next,
+		Path:     []*signerapi.ListKeyPathSegment{{Name: "bob"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "bob/key%2Fwith%20spaces", res.Items[0].KeyHandle)
+	assert.Empty(t, res.Next)
+
+	// Nothing stored under the path
+	res, err = fs.ListKeys(ctx, &signerapi.ListKeysRequest{
+		Path: []*signerapi.ListKeyPathSegment{{Name: "missing"}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.Items)
+
+	_, err = fs.ListKeys(ctx, &signerapi.ListKeysRequest{
+		Path: []*signerapi.ListKeyPathSegment{{}},
+	})
+	assert.Regexp(t, "PD020803", err)
+}
+
+func TestFileSystemStoreRotateKey(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	_, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Name: "42",
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}},
+	}, func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, "bob/42", keyHandle)
+
+	previousKeyHandle, err := fs.RotateKey(ctx, keyHandle, func() ([]byte, error) { return []byte("key2"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, "bob/42#1", previousKeyHandle)
+
+	keyMaterial, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key2"), keyMaterial)
+	keyMaterial, err = fs.LoadKeyMaterial(ctx, previousKeyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+
+	// Resolving the key by name returns the new key material
+	keyMaterial, _, err = fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Name: "42",
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob"}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key2"), keyMaterial)
+
+	previousKeyHandle, err = fs.RotateKey(ctx, keyHandle, func() ([]byte, error) { return []byte("key3"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, "bob/42#2", previousKeyHandle)
+	keyMaterial, err = fs.LoadKeyMaterial(ctx, previousKeyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key2"), keyMaterial)
+	keyMaterial, err = fs.LoadKeyMaterial(ctx, "bob/42#1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+
+	// Previous versions are not listed
+	res, err := fs.ListKeys(ctx, &signerapi.ListKeysRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, "bob/42", res.Items[0].KeyHandle)
+}
+
+func TestFileSystemStoreRotateKeyFail(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	_, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+
+	_, err = fs.RotateKey(ctx, "missing", func() ([]byte, error) { return []byte("key2"), nil })
+	assert.Regexp(t, "PD020806", err)
+
+	_, err = fs.RotateKey(ctx, "key1#1", func() ([]byte, error) { return []byte("key2"), nil })
+	assert.Regexp(t, "PD020837", err)
+
+	// The current key is left in place if the new key material cannot be generated
+	_, err = fs.RotateKey(ctx, keyHandle, func() ([]byte, error) { return nil, fmt.Errorf("pop") })
+	assert.Regexp(t, "pop", err)
+	keyMaterial, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+}
+
+func TestFileSystemStoreRotateKeyRecoverInterrupted(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	_, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+	keyFilePath := path.Join(fs.path, "-key1.key")
+	passwordFilePath := path.Join(fs.path, "-key1.pwd")
+
+	// Interrupted before the new password file was renamed into place - the new files are discarded
+	_, err = fs.createWalletFile(ctx, keyFilePath+".new", passwordFilePath+".new", func() ([]byte, error) { return []byte("key2"), nil })
+	require.NoError(t, err)
+	fs.cache.Delete(keyHandle)
+	keyMaterial, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.NoFileExists(t, keyFilePath+".new")
+	assert.NoFileExists(t, passwordFilePath+".new")
+
+	// Interrupted while the new password file was being written - it is discarded
+	err = os.WriteFile(passwordFilePath+".new", []byte("partial"), 0600)
+	require.NoError(t, err)
+	fs.cache.Delete(keyHandle)
+	keyMaterial, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.NoFileExists(t, passwordFilePath+".new")
+
+	// Interrupted after the new password file was renamed into place - the new key file follows it
+	_, err = fs.createWalletFile(ctx, keyFilePath+".new", passwordFilePath+".new", func() ([]byte, error) { return []byte("key2"), nil })
+	require.NoError(t, err)
+	err = os.Rename(passwordFilePath+".new", passwordFilePath)
+	require.NoError(t, err)
+	fs.cache.Delete(keyHandle)
+	keyMaterial, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key2"), keyMaterial)
+	assert.NoFileExists(t, keyFilePath+".new")
+}
+
+func TestFileSystemStoreRotateKeyFailKeepsKeyPair(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	_, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+
+	// Block the copy of the previous version, after the new files have been written
+	err = os.Mkdir(path.Join(fs.path, "-key1#1.pwd.tmp"), 0700)
+	require.NoError(t, err)
+	_, err = fs.RotateKey(ctx, keyHandle, func() ([]byte, error) { return []byte("key2"), nil })
+	assert.Regexp(t, "PD020804", err)
+
+	// The current key is still loaded as a matching pair, and the new files are discarded
+	keyMaterial, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.NoFileExists(t, path.Join(fs.path, "-key1.key.new"))
+}
+
+func TestFileSystemStoreRotateKeyConcurrentLoad(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	_, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			_, err := fs.RotateKey(ctx, keyHandle, func() ([]byte, error) { return []byte(fmt.Sprintf("key%d", i+2)), nil })
+			assert.NoError(t, err)
+		}
+	}()
+	for loading := true; loading; {
+		select {
+		case <-done:
+			loading = false
+		default:
+		}
+		fs.cache.Delete(keyHandle)
+		_, err := fs.LoadKeyMaterial(ctx, keyHandle)
+		require.NoError(t, err)
+	}
+	keyMaterial, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key6"), keyMaterial)
+}
+
+func TestFileSystemStoreListKeysFail(t *testing.T) {
+	ctx, fs := newTestFilesystemStore(t)
+
+	// A key file name that could not have been written by the store
+	err := os.WriteFile(path.Join(fs.path, "-bad%zz.key"), []byte{}, fs.fileMode)
+	require.NoError(t, err)
+	_, err = fs.ListKeys(ctx, &signerapi.ListKeysRequest{})
+	assert.Regexp(t, "PD020803", err)
+}
+
+func newTestFilesystemStoreWithConf(t *testing.T, dir string, pwConf pldconf.FileSystemPasswordEncryptionConfig) (context.Context, *filesystemStore, error) {
+	ctx := context.Background()
+	sf := NewFilesystemStoreFactory[*signerapi.ConfigNoExt]()
+	store, err := sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path:               confutil.P(dir),
+				PasswordEncryption: pwConf,
+			},
+		},
+	})
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, store.(*filesystemStore), nil
+}
+
+func TestFileSystemStoreEncryptedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	masterKeyFile := path.Join(t.TempDir(), "master.key")
+	err := os.WriteFile(masterKeyFile, []byte("0x"+strings.Repeat("ab", 32)+"\n"), 0600)
+	require.NoError(t, err)
+
+	ctx, fs, err := newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyFile: confutil.P(masterKeyFile),
+	})
+	require.NoError(t, err)
+
+	keyBytes, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key material"), nil })
+	require.NoError(t, err)
+
+	// The password file holds ciphertext, which is transparently decrypted when the key is next loaded
+	passData, err := os.ReadFile(path.Join(dir, "-key1.pwd"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(passData), encryptedPasswordPrefix))
+	fs.cache.Delete(keyHandle)
+	loaded, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, keyBytes, loaded)
+
+	// Without the master key the password cannot be read
+	ctx, fs, err = newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{})
+	require.NoError(t, err)
+	_, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD020831", err)
+
+	// Nor with the wrong master key
+	t.Setenv("TEST_MASTER_KEY", strings.Repeat("cd", 32))
+	ctx, fs, err = newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv: confutil.P("TEST_MASTER_KEY"),
+	})
+	require.NoError(t, err)
+	_, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD020832", err)
+
+	err = os.WriteFile(path.Join(dir, "-key1.pwd"), []byte(encryptedPasswordPrefix+"00"), 0600)
+	require.NoError(t, err)
+	_, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD020832", err)
+}
+
+func TestFileSystemStoreMigratePlaintextPasswords(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TEST_MASTER_KEY", strings.Repeat("ab", 32))
+
+	ctx, fs, err := newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{})
+	require.NoError(t, err)
+	keyBytes, keyHandle, err := fs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key material"), nil })
+	require.NoError(t, err)
+	plaintextPassword, err := os.ReadFile(path.Join(dir, "-key1.pwd"))
+	require.NoError(t, err)
+
+	// Plaintext password files are still read when encryption is enabled, but are left as they are
+	ctx, fs, err = newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv: confutil.P("TEST_MASTER_KEY"),
+	})
+	require.NoError(t, err)
+	loaded, err := fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, keyBytes, loaded)
+	passData, err := os.ReadFile(path.Join(dir, "-key1.pwd"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintextPassword, passData)
+
+	// Unless migration is enabled, in which case they are encrypted on first load
+	ctx, fs, err = newTestFilesystemStoreWithConf(t, dir, pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv:     confutil.P("TEST_MASTER_KEY"),
+		MigratePlaintext: confutil.P(true),
+	})
+	require.NoError(t, err)
+	loaded, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, keyBytes, loaded)
+	passData, err = os.ReadFile(path.Join(dir, "-key1.pwd"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(passData), encryptedPasswordPrefix))
+
+	fs.cache.Delete(keyHandle)
+	loaded, err = fs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, keyBytes, loaded)
+}
+
+func TestFileSystemStoreMigratePasswordFail(t *testing.T) {
+	t.Setenv("TEST_MASTER_KEY", strings.Repeat("ab", 32))
+	ctx, fs, err := newTestFilesystemStoreWithConf(t, t.TempDir(), pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv: confutil.P("TEST_MASTER_KEY"),
+	})
+	require.NoError(t, err)
+
+	err = os.MkdirAll(path.Join(fs.path, "clash.pwd.tmp"), fs.dirMode)
+	require.NoError(t, err)
+	err = fs.migratePasswordFile(ctx, path.Join(fs.path, "clash.pwd"), []byte("password"))
+	assert.Regexp(t, "PD020804", err)
+}
+
+func TestFileSystemStoreBadMasterKey(t *testing.T) {
+	t.Setenv("TEST_MASTER_KEY", "wrong")
+	_, _, err := newTestFilesystemStoreWithConf(t, t.TempDir(), pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv: confutil.P("TEST_MASTER_KEY"),
+	})
+	assert.Regexp(t, "PD020829", err)
+
+	t.Setenv("TEST_MASTER_KEY", "abcd")
+	_, _, err = newTestFilesystemStoreWithConf(t, t.TempDir(), pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyEnv: confutil.P("TEST_MASTER_KEY"),
+	})
+	assert.Regexp(t, "PD020829", err)
+
+	_, _, err = newTestFilesystemStoreWithConf(t, t.TempDir(), pldconf.FileSystemPasswordEncryptionConfig{
+		MasterKeyFile: confutil.P(path.Join(t.TempDir(), "missing")),
+	})
+	assert.Regexp(t, "PD020830", err)
+}
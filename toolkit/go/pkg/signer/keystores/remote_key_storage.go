@@ -0,0 +1,146 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package keystores
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/cache"
+	signerproto "github.com/kaleido-io/paladin/toolkit/pkg/prototk/signer"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tlsconf"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type remoteStoreFactory[C signerapi.ExtensibleConfig] struct{}
+
+type remoteStore struct {
+	conn           *grpc.ClientConn
+	client         signerproto.RemoteKeyStoreClient
+	cache          cache.Cache[string, []byte]
+	requestTimeout time.Duration
+}
+
+func NewRemoteStoreFactory[C signerapi.ExtensibleConfig]() signerapi.KeyStoreFactory[C] {
+	return &remoteStoreFactory[C]{}
+}
+
+// The remote store holds the key material, and it is loaded into memory in this module when it is needed
+// for signing - in the same way as it is for keys held on the local filesystem.
+func (rsf *remoteStoreFactory[C]) NewKeyStore(ctx context.Context, eConf C) (signerapi.KeyStore, error) {
+	conf := &eConf.KeyStoreConfig().Remote
+	if conf.Endpoint == "" {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningRemoteEndpointMissing)
+	}
+
+	creds := insecure.NewCredentials()
+	tlsConfig, err := tlsconf.BuildTLSConfig(ctx, &conf.TLS, tlsconf.ClientType)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(conf.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningRemoteKeyStoreFailed)
+	}
+	return &remoteStore{
+		conn:           conn,
+		client:         signerproto.NewRemoteKeyStoreClient(conn),
+		cache:          cache.NewCache[string, []byte](&conf.Cache, &pldconf.RemoteKeyStoreDefaults.Cache),
+		requestTimeout: confutil.DurationMin(conf.RequestTimeout, 0, *pldconf.RemoteKeyStoreDefaults.RequestTimeout),
+	}, nil
+}
+
+func toProtoResolveKeyRequest(req *signerapi.ResolveKeyRequest) *signerproto.ResolveKeyRequest {
+	pReq := &signerproto.ResolveKeyRequest{
+		Name:       req.Name,
+		Index:      req.Index,
+		Attributes: req.Attributes,
+	}
+	for _, p := range req.Path {
+		pReq.Path = append(pReq.Path, &signerproto.ResolveKeyPathSegment{Name: p.Name, Index: p.Index})
+	}
+	for _, ri := range req.RequiredIdentifiers {
+		pReq.RequiredIdentifiers = append(pReq.RequiredIdentifiers, &signerproto.PublicKeyIdentifierType{
+			Algorithm:    ri.Algorithm,
+			VerifierType: ri.VerifierType,
+		})
+	}
+	return pReq
+}
+
+// The key material is only generated if the remote store reports the key does not exist, in which case
+// we call again with the new key material for it to store. If another request created the key in the
+// meantime, the remote store returns the existing key.
+func (rs *remoteStore) FindOrCreateLoadableKey(ctx context.Context, req *signerapi.ResolveKeyRequest, newKeyMaterial func() ([]byte, error)) (keyMaterial []byte, keyHandle string, err error) {
+	reqCtx, cancel := context.WithTimeout(ctx, rs.requestTimeout)
+	defer cancel()
+
+	pReq := &signerproto.FindOrCreateLoadableKeyRequest{Key: toProtoResolveKeyRequest(req)}
+	res, err := rs.client.FindOrCreateLoadableKey(reqCtx, pReq)
+	if status.Code(err) == codes.NotFound && newKeyMaterial != nil {
+		if pReq.NewKeyMaterial, err = newKeyMaterial(); err != nil {
+			return nil, "", err
+		}
+		res, err = rs.client.FindOrCreateLoadableKey(reqCtx, pReq)
+	}
+	if err != nil {
+		return nil, "", i18n.WrapError(ctx, err, tkmsgs.MsgSigningRemoteKeyStoreFailed)
+	}
+	// We fail closed if the remote store does not release the key material, rather than continuing
+	// with a key that cannot be used for signing
+	if res.KeyHandle == "" || len(res.KeyMaterial) == 0 {
+		return nil, "", i18n.NewError(ctx, tkmsgs.MsgSigningRemoteKeyMaterialMissing, req.Name)
+	}
+	rs.cache.Set(res.KeyHandle, res.KeyMaterial)
+	return res.KeyMaterial, res.KeyHandle, nil
+}
+
+func (rs *remoteStore) LoadKeyMaterial(ctx context.Context, keyHandle string) ([]byte, error) {
+	if cached, _ := rs.cache.Get(keyHandle); cached != nil {
+		return cached, nil
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, rs.requestTimeout)
+	defer cancel()
+	res, err := rs.client.LoadKeyMaterial(reqCtx, &signerproto.LoadKeyMaterialRequest{KeyHandle: keyHandle})
+	if status.Code(err) == codes.NotFound {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningModuleKeyNotExist, keyHandle)
+	}
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, tkmsgs.MsgSigningRemoteKeyStoreFailed)
+	}
+	if len(res.KeyMaterial) == 0 {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningRemoteKeyMaterialMissing, keyHandle)
+	}
+	rs.cache.Set(keyHandle, res.KeyMaterial)
+	return res.KeyMaterial, nil
+}
+
+func (rs *remoteStore) Close() {
+	_ = rs.conn.Close()
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package keystores
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	signerproto "github.com/kaleido-io/paladin/toolkit/pkg/prototk/signer"
+	"github.com/kaleido-io/paladin/toolkit/pkg/signerapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testRemoteKeyStore struct {
+	signerproto.UnimplementedRemoteKeyStoreServer
+	lock     sync.Mutex
+	keys     map[string][]byte
+	calls    int
+	withhold bool // simulate a store that does not release key material
+}
+
+func (trs *testRemoteKeyStore) keyHandle(req *signerproto.ResolveKeyRequest) string {
+	segments := []string{}
+	for _, p := range req.Path {
+		segments = append(segments, p.Name)
+	}
+	return strings.Join(append(segments, req.Name), "/")
+}
+
+func (trs *testRemoteKeyStore) FindOrCreateLoadableKey(ctx context.Context, req *signerproto.FindOrCreateLoadableKeyRequest) (*signerproto.FindOrCreateLoadableKeyResponse, error) {
+	trs.lock.Lock()
+	defer trs.lock.Unlock()
+	trs.calls++
+	keyHandle := trs.keyHandle(req.Key)
+	if req.Key.Name == "fail" {
+		return nil, fmt.Errorf("pop")
+	}
+	keyMaterial := trs.keys[keyHandle]
+	if keyMaterial == nil {
+		if len(req.NewKeyMaterial) == 0 {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		keyMaterial = req.NewKeyMaterial
+		trs.keys[keyHandle] = keyMaterial
+	}
+	if trs.withhold {
+		keyMaterial = nil
+	}
+	return &signerproto.FindOrCreateLoadableKeyResponse{KeyHandle: keyHandle, KeyMaterial: keyMaterial}, nil
+}
+
+func (trs *testRemoteKeyStore) LoadKeyMaterial(ctx context.Context, req *signerproto.LoadKeyMaterialRequest) (*signerproto.LoadKeyMaterialResponse, error) {
+	trs.lock.Lock()
+	defer trs.lock.Unlock()
+	trs.calls++
+	if req.KeyHandle == "fail" {
+		return nil, fmt.Errorf("pop")
+	}
+	keyMaterial := trs.keys[req.KeyHandle]
+	if keyMaterial == nil {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	if trs.withhold {
+		keyMaterial = nil
+	}
+	return &signerproto.LoadKeyMaterialResponse{KeyMaterial: keyMaterial}, nil
+}
+
+func newTestRemoteStore(t *testing.T) (context.Context, *remoteStore, *testRemoteKeyStore) {
+	ctx := context.Background()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	trs := &testRemoteKeyStore{keys: map[string][]byte{}}
+	signerproto.RegisterRemoteKeyStoreServer(server, trs)
+	go func() { _ = server.Serve(l) }()
+
+	sf := NewRemoteStoreFactory[*signerapi.ConfigNoExt]()
+	store, err := sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeRemote,
+			Remote: pldconf.RemoteKeyStoreConfig{
+				Endpoint: l.Addr().String(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		store.Close()
+		server.Stop()
+	})
+	return ctx, store.(*remoteStore), trs
+}
+
+func TestRemoteStoreFindOrCreateAndLoad(t *testing.T) {
+	ctx, rs, trs := newTestRemoteStore(t)
+
+	newKeys := 0
+	newKeyMaterial := func() ([]byte, error) {
+		newKeys++
+		return []byte(fmt.Sprintf("key%d", newKeys)), nil
+	}
+
+	keyMaterial, keyHandle, err := rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Name: "42",
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob", Index: 1}},
+		RequiredIdentifiers: []*signerapi.PublicKeyIdentifierType{
+			{Algorithm: "ecdsa:secp256k1", VerifierType: "eth_address"},
+		},
+	}, newKeyMaterial)
+	require.NoError(t, err)
+	assert.Equal(t, "bob/42", keyHandle)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.Equal(t, 1, newKeys)
+	assert.Equal(t, 2, trs.calls) // not found, then created
+
+	// Found the second time, without generating new key material
+	keyMaterial, keyHandle, err = rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{
+		Name: "42",
+		Path: []*signerapi.ResolveKeyPathSegment{{Name: "bob", Index: 1}},
+	}, newKeyMaterial)
+	require.NoError(t, err)
+	assert.Equal(t, "bob/42", keyHandle)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.Equal(t, 1, newKeys)
+	assert.Equal(t, 3, trs.calls)
+
+	// Loading is served from the cache, until it is evicted
+	keyMaterial, err = rs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.Equal(t, 3, trs.calls)
+
+	rs.cache.Delete(keyHandle)
+	keyMaterial, err = rs.LoadKeyMaterial(ctx, keyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key1"), keyMaterial)
+	assert.Equal(t, 4, trs.calls)
+}
+
+func TestRemoteStoreFailClosedWithoutKeyMaterial(t *testing.T) {
+	ctx, rs, trs := newTestRemoteStore(t)
+
+	_, keyHandle, err := rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	require.NoError(t, err)
+	rs.cache.Delete(keyHandle)
+
+	trs.lock.Lock()
+	trs.withhold = true
+	trs.lock.Unlock()
+	_, _, err = rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	assert.Regexp(t, "PD020835", err)
+
+	_, err = rs.LoadKeyMaterial(ctx, keyHandle)
+	assert.Regexp(t, "PD020835", err)
+}
+
+func TestRemoteStoreErrors(t *testing.T) {
+	ctx, rs, _ := newTestRemoteStore(t)
+
+	_, _, err := rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "fail"},
+		func() ([]byte, error) { return []byte("key1"), nil })
+	assert.Regexp(t, "PD020834.*pop", err)
+
+	_, _, err = rs.FindOrCreateLoadableKey(ctx, &signerapi.ResolveKeyRequest{Name: "key1"},
+		func() ([]byte, error) { return nil, fmt.Errorf("pop") })
+	assert.Regexp(t, "pop", err)
+
+	_, err = rs.LoadKeyMaterial(ctx, "missing")
+	assert.Regexp(t, "PD020806", err)
+
+	_, err = rs.LoadKeyMaterial(ctx, "fail")
+	assert.Regexp(t, "PD020834.*pop", err)
+}
+
+func TestRemoteStoreBadConfig(t *testing.T) {
+	ctx := context.Background()
+	sf := NewRemoteStoreFactory[*signerapi.ConfigNoExt]()
+
+	_, err := sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeRemote,
+		},
+	})
+	assert.Regexp(t, "PD020833", err)
+
+	_, err = sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeRemote,
+			Remote: pldconf.RemoteKeyStoreConfig{
+				Endpoint: "localhost:12345",
+				TLS: pldconf.TLSConfig{
+					Enabled: true,
+					CAFile:  "!!!missing",
+				},
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	_, err = sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeRemote,
+			Remote: pldconf.RemoteKeyStoreConfig{
+				Endpoint: "%%%",
+			},
+		},
+	})
+	assert.Regexp(t, "PD020834", err)
+
+	store, err := sf.NewKeyStore(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeRemote,
+			Remote: pldconf.RemoteKeyStoreConfig{
+				Endpoint:       "localhost:12345",
+				RequestTimeout: confutil.P("1s"),
+				TLS:            pldconf.TLSConfig{Enabled: true},
+			},
+		},
+	})
+	require.NoError(t, err)
+	store.Close()
+}
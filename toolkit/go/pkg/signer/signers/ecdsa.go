@@ -27,6 +27,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/signpayloads"
 	"github.com/kaleido-io/paladin/toolkit/pkg/tkmsgs"
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
+	"golang.org/x/crypto/sha3"
 )
 
 type ecdsaSigner struct{}
@@ -68,6 +69,17 @@ func (s *ecdsaSigner) Sign_secp256k1(ctx context.Context, algorithm, payloadType
 			return nil, err
 		}
 		return sig.CompactRSV(), nil
+	case signpayloads.OPAQUE_KECCAK256_TO_RSV:
+		if len(payload) == 0 {
+			return nil, i18n.NewError(ctx, tkmsgs.MsgSigningEmptyPayload)
+		}
+		hash := sha3.NewLegacyKeccak256()
+		hash.Write(payload)
+		sig, err := kp.SignDirect(hash.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		return sig.CompactRSV(), nil
 	default:
 		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningUnsupportedPayloadCombination, payloadType, algorithm)
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/kaleido-io/paladin/toolkit/pkg/verifiers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
 )
 
 func newTestSigner(t *testing.T, privKey []byte) (context.Context, *ecdsaSigner, *secp256k1.KeyPair) {
@@ -93,6 +94,32 @@ func TestECDSASigning_secp256k1(t *testing.T) {
 
 }
 
+func TestECDSASigning_secp256k1_Keccak256(t *testing.T) {
+	ctx, signer, kp := newTestSigner(t, tktypes.RandBytes(32))
+
+	// Two large payloads that only differ after the first 32 bytes
+	testData1 := tktypes.RandBytes(1024)
+	testData2 := append(append([]byte{}, testData1[0:512]...), tktypes.RandBytes(512)...)
+
+	signatureRSV1, err := signer.Sign(ctx, algorithms.ECDSA_SECP256K1, signpayloads.OPAQUE_KECCAK256_TO_RSV, kp.PrivateKeyBytes(), testData1)
+	require.NoError(t, err)
+	signatureRSV2, err := signer.Sign(ctx, algorithms.ECDSA_SECP256K1, signpayloads.OPAQUE_KECCAK256_TO_RSV, kp.PrivateKeyBytes(), testData2)
+	require.NoError(t, err)
+	assert.NotEqual(t, signatureRSV1, signatureRSV2)
+
+	// The verifier recomputes the hash to recover the signer
+	sig, err := secp256k1.DecodeCompactRSV(ctx, signatureRSV1)
+	require.NoError(t, err)
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(testData1)
+	recovered, err := sig.RecoverDirect(hash.Sum(nil), 0)
+	require.NoError(t, err)
+	assert.Equal(t, kp.Address, *recovered)
+
+	_, err = signer.Sign(ctx, algorithms.ECDSA_SECP256K1, signpayloads.OPAQUE_KECCAK256_TO_RSV, kp.PrivateKeyBytes(), nil)
+	assert.Regexp(t, "PD020825", err)
+}
+
 func TestECDSAVerifiers_secp256k1(t *testing.T) {
 	privKey := ethtypes.MustNewHexBytes0xPrefix(
 		"4afef2e65381d2667f0af4347d01d1813f6a7e1824c65c0210a104cc80d3aa15")
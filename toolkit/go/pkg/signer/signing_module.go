@@ -39,6 +39,8 @@ type SigningModule interface {
 	Resolve(ctx context.Context, req *signerapi.ResolveKeyRequest) (res *signerapi.ResolveKeyResponse, err error)
 	Sign(ctx context.Context, req *signerapi.SignRequest) (res *signerapi.SignResponse, err error)
 	List(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.ListKeysResponse, err error)
+	Inventory(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.KeyInventoryResponse, err error)
+	RotateKey(ctx context.Context, keyHandle string) (res *signerapi.RotateKeyResponse, err error)
 	Close()
 }
 
@@ -91,6 +93,7 @@ func NewSigningModule[C signerapi.ExtensibleConfig](ctx context.Context, conf C,
 	keyStoreImplementations := map[string]signerapi.KeyStoreFactory[C]{
 		pldconf.KeyStoreTypeFilesystem: keystores.NewFilesystemStoreFactory[C](),
 		pldconf.KeyStoreTypeStatic:     keystores.NewStaticStoreFactory[C](),
+		pldconf.KeyStoreTypeRemote:     keystores.NewRemoteStoreFactory[C](),
 	}
 
 	for _, e := range extensions {
@@ -268,14 +271,64 @@ func (sm *signingModule[C]) Sign(ctx context.Context, req *signerapi.SignRequest
 	return sm.signInMemory(ctx, req.Algorithm, req.PayloadType, privateKey, req.Payload)
 }
 
+// RotateKey replaces the key material behind a key handle with new random material of the same length.
+// Keys derived from a HD wallet seed, or held in the key store for signing, cannot be rotated.
+func (sm *signingModule[C]) RotateKey(ctx context.Context, keyHandle string) (res *signerapi.RotateKeyResponse, err error) {
+	rotatableStore, isRotatable := sm.keyStore.(signerapi.KeyStoreRotatable)
+	if !isRotatable || sm.hd != nil || sm.keyStoreSigner != nil {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningKeyRotationNotSupported)
+	}
+	currentKeyMaterial, err := sm.keyStore.LoadKeyMaterial(ctx, keyHandle)
+	if err != nil {
+		return nil, err
+	}
+	previousKeyHandle, err := rotatableStore.RotateKey(ctx, keyHandle, func() ([]byte, error) {
+		buff := make([]byte, len(currentKeyMaterial))
+		_, err := rand.Read(buff)
+		return buff, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &signerapi.RotateKeyResponse{
+		KeyHandle:         keyHandle,
+		PreviousKeyHandle: previousKeyHandle,
+	}, nil
+}
+
 func (sm *signingModule[C]) List(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.ListKeysResponse, err error) {
+	if sm.disableKeyListing {
+		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningKeyListingDisabled)
+	}
 	listableStore, isListable := sm.keyStore.(signerapi.KeyStoreListable)
-	if !isListable || sm.disableKeyListing {
+	if !isListable {
 		return nil, i18n.NewError(ctx, tkmsgs.MsgSigningKeyListingNotSupported)
 	}
 	return listableStore.ListKeys(ctx, req)
 }
 
+// Inventory pages through the same listing as List, but returns only the handle and derivation metadata
+// of each key, so it is safe to hand to backup verification tooling
+func (sm *signingModule[C]) Inventory(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.KeyInventoryResponse, err error) {
+	listRes, err := sm.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res = &signerapi.KeyInventoryResponse{
+		Items: make([]*signerapi.KeyInventoryEntry, len(listRes.Items)),
+		Next:  listRes.Next,
+	}
+	for i, key := range listRes.Items {
+		res.Items[i] = &signerapi.KeyInventoryEntry{
+			Name:       key.Name,
+			KeyHandle:  key.KeyHandle,
+			Attributes: key.Attributes,
+			Path:       key.Path,
+		}
+	}
+	return res, nil
+}
+
 func (sm *signingModule[C]) Close() {
 	sm.keyStore.Close()
 }
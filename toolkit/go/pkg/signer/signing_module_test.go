@@ -238,11 +238,57 @@ func TestExtensionKeyStoreListOK(t *testing.T) {
 		Limit:    10,
 		Continue: "key12345",
 	})
-	assert.Regexp(t, "PD020815", err)
+	assert.Regexp(t, "PD020828", err)
 
 	sm.Close()
 }
 
+func TestFilesystemKeyStoreListOK(t *testing.T) {
+
+	ctx := context.Background()
+	sm, err := NewSigningModule(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path: confutil.P(t.TempDir()),
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer sm.Close()
+
+	_, err = sm.Resolve(ctx, &signerapi.ResolveKeyRequest{
+		RequiredIdentifiers: []*signerapi.PublicKeyIdentifierType{{Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS}},
+		Name:                "key1",
+		Path:                []*signerapi.ResolveKeyPathSegment{{Name: "bob"}},
+	})
+	require.NoError(t, err)
+
+	res, err := sm.List(ctx, &signerapi.ListKeysRequest{
+		Path: []*signerapi.ListKeyPathSegment{{Name: "bob"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*signerapi.ListKeyEntry{
+		{Name: "key1", KeyHandle: "bob/key1", Path: []*signerapi.ListKeyPathSegment{{Name: "bob"}}},
+	}, res.Items)
+
+}
+
+func TestStaticKeyStoreListNotSupported(t *testing.T) {
+
+	sm, err := NewSigningModule(context.Background(), &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeStatic,
+		},
+	})
+	require.NoError(t, err)
+	defer sm.Close()
+
+	_, err = sm.List(context.Background(), &signerapi.ListKeysRequest{})
+	assert.Regexp(t, "PD020815", err)
+
+}
+
 func TestExtensionKeyStoreListFail(t *testing.T) {
 
 	tk := &testKeyStoreAll{
@@ -271,6 +317,104 @@ func TestExtensionKeyStoreListFail(t *testing.T) {
 
 }
 
+func TestExtensionKeyStoreInventoryOK(t *testing.T) {
+
+	tk := &testKeyStoreAll{
+		testKeyStoreBase: testKeyStoreBase{
+			loadKeyMaterial: func(ctx context.Context, keyHandle string) ([]byte, error) {
+				assert.Fail(t, "key material must not be loaded for the inventory")
+				return nil, fmt.Errorf("unexpected")
+			},
+		},
+		listKeys: func(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.ListKeysResponse, err error) {
+			assert.Equal(t, 10, req.Limit)
+			assert.Equal(t, "key12345", req.Continue)
+			return &signerapi.ListKeysResponse{
+				Items: []*signerapi.ListKeyEntry{
+					{
+						Name:       "key 23456",
+						KeyHandle:  "key23456",
+						Attributes: map[string]string{"purpose": "endorsement"},
+						Path:       []*signerapi.ListKeyPathSegment{{Name: "root"}, {Name: "bob"}},
+						Identifiers: []*signerapi.PublicKeyIdentifier{
+							{Algorithm: algorithms.ECDSA_SECP256K1, Verifier: "0x93e5a15ce57564278575ff7182b5b3746251e781"},
+						},
+					},
+				},
+				Next: "key23456",
+			}, nil
+		},
+	}
+	te := &signerapi.Extensions[*signerapi.ConfigNoExt]{
+		KeyStoreFactories: map[string]signerapi.KeyStoreFactory[*signerapi.ConfigNoExt]{
+			"ext-store": &testKeyStoreAllFactory{keyStore: tk},
+		},
+	}
+
+	sm, err := NewSigningModule(context.Background(), &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: "ext-store",
+		},
+	}, te)
+	require.NoError(t, err)
+
+	res, err := sm.Inventory(context.Background(), &signerapi.ListKeysRequest{
+		Limit:    10,
+		Continue: "key12345",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &signerapi.KeyInventoryResponse{
+		Items: []*signerapi.KeyInventoryEntry{
+			{
+				Name:       "key 23456",
+				KeyHandle:  "key23456",
+				Attributes: map[string]string{"purpose": "endorsement"},
+				Path:       []*signerapi.ListKeyPathSegment{{Name: "root"}, {Name: "bob"}},
+			},
+		},
+		Next: "key23456",
+	}, res)
+
+	// Nothing derived from the key material is included
+	jsonRes := tktypes.JSONString(res).String()
+	assert.NotContains(t, jsonRes, "identifiers")
+	assert.NotContains(t, jsonRes, "93e5a15ce57564278575ff7182b5b3746251e781")
+
+	sm.(*signingModule[*signerapi.ConfigNoExt]).disableKeyListing = true
+	_, err = sm.Inventory(context.Background(), &signerapi.ListKeysRequest{
+		Limit:    10,
+		Continue: "key12345",
+	})
+	assert.Regexp(t, "PD020828", err)
+
+	sm.Close()
+}
+
+func TestExtensionKeyStoreInventoryFail(t *testing.T) {
+
+	tk := &testKeyStoreAll{
+		listKeys: func(ctx context.Context, req *signerapi.ListKeysRequest) (res *signerapi.ListKeysResponse, err error) {
+			return nil, fmt.Errorf("pop")
+		},
+	}
+	te := &signerapi.Extensions[*signerapi.ConfigNoExt]{
+		KeyStoreFactories: map[string]signerapi.KeyStoreFactory[*signerapi.ConfigNoExt]{
+			"ext-store": &testKeyStoreAllFactory{keyStore: tk},
+		},
+	}
+
+	sm, err := NewSigningModule(context.Background(), &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: "ext-store",
+		},
+	}, te)
+	require.NoError(t, err)
+
+	_, err = sm.Inventory(context.Background(), &signerapi.ListKeysRequest{Limit: 10})
+	assert.Regexp(t, "pop", err)
+
+}
+
 func TestExtensionKeyStoreResolveSignSECP256K1OK(t *testing.T) {
 
 	tk := &testKeyStoreAll{
@@ -442,6 +586,90 @@ func TestResolveSignWithNewKeyCreation(t *testing.T) {
 
 }
 
+func TestFilesystemKeyStoreRotateKey(t *testing.T) {
+
+	ctx := context.Background()
+	sm, err := NewSigningModule(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path: confutil.P(t.TempDir()),
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer sm.Close()
+
+	resolveKey := func() *signerapi.ResolveKeyResponse {
+		resolveRes, err := sm.Resolve(ctx, &signerapi.ResolveKeyRequest{
+			RequiredIdentifiers: []*signerapi.PublicKeyIdentifierType{{Algorithm: algorithms.ECDSA_SECP256K1, VerifierType: verifiers.ETH_ADDRESS}},
+			Name:                "key1",
+		})
+		require.NoError(t, err)
+		return resolveRes
+	}
+	before := resolveKey()
+
+	rotateRes, err := sm.RotateKey(ctx, before.KeyHandle)
+	require.NoError(t, err)
+	assert.Equal(t, "key1", rotateRes.KeyHandle)
+	assert.Equal(t, "key1#1", rotateRes.PreviousKeyHandle)
+
+	after := resolveKey()
+	assert.Equal(t, "key1", after.KeyHandle)
+	assert.NotEqual(t, before.Identifiers[0].Verifier, after.Identifiers[0].Verifier)
+
+	// Both the new and previous key can sign
+	for _, keyHandle := range []string{rotateRes.KeyHandle, rotateRes.PreviousKeyHandle} {
+		signRes, err := sm.Sign(ctx, &signerapi.SignRequest{
+			KeyHandle:   keyHandle,
+			Algorithm:   algorithms.ECDSA_SECP256K1,
+			PayloadType: signpayloads.OPAQUE_TO_RSV,
+			Payload:     ([]byte)("sign me"),
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, signRes.Payload)
+	}
+
+	_, err = sm.RotateKey(ctx, "missing")
+	assert.Regexp(t, "PD020806", err)
+
+}
+
+func TestRotateKeyNotSupported(t *testing.T) {
+
+	ctx := context.Background()
+	sm, err := NewSigningModule(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeStatic,
+		},
+	})
+	require.NoError(t, err)
+	defer sm.Close()
+
+	_, err = sm.RotateKey(ctx, "key1")
+	assert.Regexp(t, "PD020836", err)
+
+	// Keys derived from a HD wallet seed are not rotated, as they are not stored
+	hdsm, err := NewSigningModule(ctx, &signerapi.ConfigNoExt{
+		KeyStore: pldconf.KeyStoreConfig{
+			Type: pldconf.KeyStoreTypeFilesystem,
+			FileSystem: pldconf.FileSystemKeyStoreConfig{
+				Path: confutil.P(t.TempDir()),
+			},
+		},
+		KeyDerivation: pldconf.KeyDerivationConfig{
+			Type: pldconf.KeyDerivationTypeBIP32,
+		},
+	})
+	require.NoError(t, err)
+	defer hdsm.Close()
+
+	_, err = hdsm.RotateKey(ctx, "key1")
+	assert.Regexp(t, "PD020836", err)
+
+}
+
 func TestResolveUnsupportedAlgo(t *testing.T) {
 
 	sm, err := NewSigningModule(context.Background(), &signerapi.ConfigNoExt{
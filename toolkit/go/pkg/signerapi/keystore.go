@@ -54,6 +54,13 @@ type KeyStoreListable interface {
 	ListKeys(ctx context.Context, req *ListKeysRequest) (res *ListKeysResponse, err error)
 }
 
+// Some cryptographic stores can replace the key material behind a key handle, for example when a key
+// has been compromised. The previous material remains loadable under a versioned key handle, so that
+// signatures made with it can still be verified while the transition takes place.
+type KeyStoreRotatable interface {
+	RotateKey(ctx context.Context, keyHandle string, newKeyMaterial func() ([]byte, error)) (previousKeyHandle string, err error)
+}
+
 // Some cryptographic storage systems, in particular Hardware Security Modules (HSMs) and Cloud HSM systems,
 // support signing directly with certain curves.
 //
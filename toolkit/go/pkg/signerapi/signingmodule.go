@@ -63,12 +63,23 @@ type SignResponse struct {
 	Payload tktypes.HexBytes `json:"payload,omitempty"`
 }
 
+type RotateKeyResponse struct {
+	// the key handle that was rotated, which now resolves to the new key material
+	KeyHandle string `json:"keyHandle,omitempty"`
+
+	// a versioned key handle that still resolves to the previous key material
+	PreviousKeyHandle string `json:"previousKeyHandle,omitempty"`
+}
+
 type ListKeysRequest struct {
 	// the maximum number of records to return
 	Limit int `json:"limit,omitempty"`
 
 	// the "next" string from a previous call, or empty
 	Continue string `json:"continue,omitempty"`
+
+	// only list keys under this hierarchical path, or all keys if empty
+	Path []*ListKeyPathSegment `json:"path,omitempty"`
 }
 
 type ListKeysResponse struct {
@@ -79,6 +90,30 @@ type ListKeysResponse struct {
 	Next string `json:"next,omitempty"`
 }
 
+// The inventory of keys is the listing with only the metadata needed to identify and re-derive each key,
+// so that backup tooling can confirm coverage without handling any key material
+type KeyInventoryResponse struct {
+	// any length less than the limit will cause the caller to assume there might be more records
+	Items []*KeyInventoryEntry `json:"items,omitempty"`
+
+	// non empty string to support pagination when the are potentially more records
+	Next string `json:"next,omitempty"`
+}
+
+type KeyInventoryEntry struct {
+	// The part of the key identifier representing this key
+	Name string `json:"name,omitempty"`
+
+	// Maps this internal key representation down to the key material
+	KeyHandle string `json:"keyHandle,omitempty"`
+
+	// Attributes passed to the signing module during key resolution
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Hierarchical path to the key split into segments
+	Path []*ListKeyPathSegment `json:"path,omitempty"`
+}
+
 type ResolveKeyPathSegment struct {
 	// the name of the path segment (folder)
 	Name string `json:"name,omitempty"`
@@ -23,3 +23,11 @@ package signpayloads
 // according to the Bitcoin/Eth standard of 27+recid (27 or 28)
 // denoting an uncompressed public key.
 const OPAQUE_TO_RSV = "opaque:rsv"
+
+// Input:
+// An opaque payload that the signing module hashes with keccak256 before signing, for
+// payloads that are larger than the fixed size input of the algorithm. A verifier
+// recomputes the keccak256 hash of the payload before recovering the signature.
+// Output:
+// A compact 65 byte encoded R,S,V byte string, as per OPAQUE_TO_RSV
+const OPAQUE_KECCAK256_TO_RSV = "opaque:keccak256:rsv"
@@ -57,6 +57,8 @@ var (
 var (
 	PublicTxOptionsGas                     = ffm("PublicTxOptions.gas", "The gas limit for the transaction (optional)")
 	PublicTxOptionsValue                   = ffm("PublicTxOptions.value", "The value transferred in the transaction (optional)")
+	PublicTxOptionsResubmitInterval        = ffm("PublicTxOptions.resubmitInterval", "How long to wait for the transaction to be mined before resubmitting it, overriding the node configuration - such as '30s' (optional)")
+	PublicTxOptionsNonce                   = ffm("PublicTxOptions.nonce", "Forces the nonce of the transaction, rather than assigning the next nonce for the signing address - for filling nonce gaps left by submissions outside of Paladin. The nonce must not already be used, and must be below the next nonce that would be assigned to the signing address (optional)")
 	PublicCallOptionsBlock                 = ffm("PublicCallOptions.block", "The block number or 'latest' when calling a public smart contract (optional)")
 	PublicTxGasPricingMaxPriorityFeePerGas = ffm("PublicTxGasPricing.maxPriorityFeePerGas", "The maximum priority fee per gas (optional)")
 	PublicTxGasPricingMaxFeePerGas         = ffm("PublicTxGasPricing.maxFeePerGas", "The maximum fee per gas (optional)")
@@ -81,6 +83,17 @@ var (
 	PublicTxActivity                       = ffm("PublicTx.activity", "The transaction activity records (optional)")
 	PublicTxBindingTransaction             = ffm("PublicTxBinding.transaction", "The transaction ID")
 	PublicTxBindingTransactionType         = ffm("PublicTxBinding.transactionType", "The transaction type")
+	PublicTxNonceMappingFrom               = ffm("PublicTxNonceMapping.from", "The sender's Ethereum address")
+	PublicTxNonceMappingNonce              = ffm("PublicTxNonceMapping.nonce", "The nonce assigned to the public transaction")
+	PublicTxNonceMappingTransactionHash    = ffm("PublicTxNonceMapping.transactionHash", "The hash of the confirmed transaction (optional)")
+	PublicTxRejectionID                    = ffm("PublicTxRejection.id", "A unique identifier for the rejection record")
+	PublicTxRejectionCreated               = ffm("PublicTxRejection.created", "The time the transaction was rejected")
+	PublicTxRejectionFrom                  = ffm("PublicTxRejection.from", "The sender's Ethereum address")
+	PublicTxRejectionTo                    = ffm("PublicTxRejection.to", "The target contract address (optional)")
+	PublicTxRejectionData                  = ffm("PublicTxRejection.data", "The pre-encoded calldata (optional)")
+	PublicTxRejectionSubmitted             = ffm("PublicTxRejection.submitted", "Always false, as a rejected transaction is never assigned a nonce or submitted to the chain")
+	PublicTxRejectionRejectReason          = ffm("PublicTxRejection.rejectReason", "The reason the transaction was rejected, decoded from the revert data where possible")
+	PublicTxRejectionRevertData            = ffm("PublicTxRejection.revertData", "The revert data returned when estimating gas (optional)")
 )
 
 // pldapi/stored_abi.go
@@ -94,6 +107,8 @@ var (
 	TransactionID                                 = ffm("Transaction.id", "Server-generated UUID for this transaction (query only)")
 	TransactionCreated                            = ffm("Transaction.created", "Server-generated creation timestamp for this transaction (query only)")
 	TransactionSubmitMode                         = ffm("Transaction.submitMode", "Whether the submission of the transaction to the base ledger is to be performed automatically by the node or coordinated externally (query only)")
+	TransactionCoordinator                        = ffm("Transaction.coordinator", "The node that coordinated this private transaction - this node, or the node coordination was delegated to (query only)")
+	TransactionLabel                              = ffm("Transaction.label", "A human-readable label supplied by the domain for this private transaction, such as a summary of a token transfer (query only)")
 	TransactionIdempotencyKey                     = ffm("Transaction.idempotencyKey", "Externally supplied unique identifier for this transaction. 409 Conflict will be returned on attempt to re-submit")
 	TransactionType                               = ffm("Transaction.type", "Type of transaction (public or private)")
 	TransactionDomain                             = ffm("Transaction.domain", "Name of a domain - only required on input for private deploy transactions")
@@ -105,11 +120,14 @@ var (
 	TransactionInputDependsOn                     = ffm("TransactionInput.dependsOn", "Transactions that must be mined on the blockchain successfully before this transaction submits")
 	TransactionInputABI                           = ffm("TransactionInput.abi", "Application Binary Interface (ABI) definition - required if abiReference not supplied")
 	TransactionInputBytecode                      = ffm("TransactionInput.bytecode", "Bytecode prepended to encoded data inputs for deploy transactions")
+	TransactionInputKeyPathOverride               = ffm("TransactionInput.keyPathOverride", "Public transactions only: a dot separated key path beneath the 'from' identifier, to sign this transaction with a dedicated derived key. Must be allowed by the allowedKeyPathOverrides policy of the node")
+	TransactionInputSignedTransaction             = ffm("TransactionInput.signedTransaction", "Public transactions only: a raw transaction signed outside of Paladin by the 'from' identifier, which is submitted exactly as supplied. Its target, data and nonce must match the transaction")
 	TransactionCallDataFormat                     = ffm("TransactionCall.dataFormat", "How call data should be serialized into JSON once decoded using the ABI function definition")
 	TransactionFullDependsOn                      = ffm("TransactionFull.dependsOn", "Transactions registered as dependencies when the transaction was created")
 	TransactionFullReceipt                        = ffm("TransactionFull.receipt", "Transaction receipt data - available if the transaction has reached a final state")
 	TransactionFullPublic                         = ffm("TransactionFull.public", "List of public transactions associated with this transaction")
 	TransactionReceiptID                          = ffm("TransactionReceipt.id", "Transaction ID")
+	TransactionReceiptSequence                    = ffm("TransactionReceipt.sequence", "Increasing sequence number assigned once the receipt is committed on this node, which can be used to resume a receipt subscription. Unset until assigned, and re-assigned if the receipt is corrected")
 	TransactionReceiptDataOnchainTransactionHash  = ffm("TransactionReceiptDataOnchain.transactionHash", "Transaction hash")
 	TransactionReceiptDataOnchainBlockNumber      = ffm("TransactionReceiptDataOnchain.blockNumber", "Block number")
 	TransactionReceiptDataOnchainTransactionIndex = ffm("TransactionReceiptDataOnchain.transactionIndex", "Transaction index")
@@ -138,6 +156,10 @@ var (
 	DecodedSummary                                = ffm("ABIDecodedData.summary", "A string formatted summary - errors only")
 	DecodedDefinition                             = ffm("ABIDecodedData.definition", "The ABI definition entry matched from the dictionary of ABIs")
 	DecodedSignature                              = ffm("ABIDecodedData.signature", "The signature of the matched ABI definition")
+
+	TransactionReceiptSubscribeOptionsFrom         = ffm("TransactionReceiptSubscribeOptions.from", "Only deliver receipts for transactions submitted on this node by this sender")
+	TransactionReceiptSubscribeOptionsType         = ffm("TransactionReceiptSubscribeOptions.type", "Only deliver receipts for transactions submitted on this node with this type (public or private)")
+	TransactionReceiptSubscribeOptionsFromSequence = ffm("TransactionReceiptSubscribeOptions.fromSequence", "Deliver receipts with a sequence after this one. If not set, only receipts written after the subscription is created are delivered")
 )
 
 // query/query_json.go
@@ -204,6 +226,10 @@ var (
 	TransactionStatesConfirmed   = ffm("TransactionStates.confirmed", "Private state data for new states that were confirmed as new unspent states during this transaction")
 	TransactionStatesInfo        = ffm("TransactionStates.info", "Private state data for states that were recorded as part of this transaction, and existed only as reference data during its execution. They were not validated as unspent during execution, or recorded as new unspent states")
 	TransactionStatesUnavailable = ffm("TransactionStates.unavailable", "If present, this contains information about states recorded as used by this transactions when indexing, but for which the private data is unavailable on this node")
+	TransactionStatesConsumed    = ffm("TransactionStates.consumed", "The ID and schema of every input state consumed by this transaction, including those for which the private data is unavailable")
+	TransactionStatesProduced    = ffm("TransactionStates.produced", "The ID and schema of every output state produced by this transaction, including those for which the private data is unavailable")
+	StateReferenceID             = ffm("StateReference.id", "The ID of the state")
+	StateReferenceSchema         = ffm("StateReference.schema", "The ID of the schema for the state, omitted if the private data of the state is unavailable on this node")
 	UnavailableStatesSpent       = ffm("UnavailableStates.spent", "The IDs of spent states consumed by this transaction, for which the private data is unavailable")
 	UnavailableStatesRead        = ffm("UnavailableStates.read", "The IDs of read states used by this transaction, for which the private data is unavailable")
 	UnavailableStatesConfirmed   = ffm("UnavailableStates.confirmed", "The IDs of confirmed states created by this transaction, for which the private data is unavailable")
@@ -216,13 +242,35 @@ var (
 	RegistryEntryID                       = ffm("RegistryEntry.id", "The ID of the entry, which is unique within the registry across all records in the hierarchy")
 	RegistryEntryName                     = ffm("RegistryEntry.name", "The name of the entry, which is unique across entries with the same parent")
 	RegistryParentID                      = ffm("RegistryEntry.parentId", "Unset for a root record, otherwise a reference to another entity in the same registry")
+	RegistryEntryValidUntil               = ffm("RegistryEntry.validUntil", "If set, the entry is treated as inactive after this time, unless the registry updates it before then")
 	RegistryEntryWithPropertiesProperties = ffm("RegistryEntryWithProperties.properties", "A name + value pair map of all the active properties for this entry. Only active properties are listed, even if the query on the entries used an activeFilter to return inactive entries")
 	RegistryPropertyRegistry              = ffm("RegistryProperty.registry", "The registry that maintains this record")
 	RegistryPropertyEntryID               = ffm("RegistryProperty.entryId", "The ID of the entry this property is associated with")
 	RegistryPropertyName                  = ffm("RegistryProperty.name", "The name of the property")
 	RegistryPropertyValue                 = ffm("RegistryProperty.value", "The value of the property")
+	RegistrySnapshotRegistry              = ffm("RegistrySnapshot.registry", "The registry the snapshot was exported from, and that it can be imported into")
+	RegistrySnapshotEntries               = ffm("RegistrySnapshot.entries", "All entries in the registry, active and inactive")
+	RegistrySnapshotProperties            = ffm("RegistrySnapshot.properties", "All properties of all entries in the registry, active and inactive")
+	RegistryNodeTransportEntryNode        = ffm("RegistryNodeTransportEntry.node", "The name of the node")
+	RegistryNodeTransportEntryRegistry    = ffm("RegistryNodeTransportEntry.registry", "The registry the transport details were published in")
+	RegistryNodeTransportEntryTransport   = ffm("RegistryNodeTransportEntry.transport", "The name of the transport, after applying any transport name mapping configured for the registry")
+	RegistryNodeTransportEntryDetails     = ffm("RegistryNodeTransportEntry.details", "The transport details published for the node, which other nodes use to connect to it")
+	OnChainLocationTransactionHash        = ffm("OnChainLocation.transactionHash", "The hash of the transaction that set the registry entry/property")
 	OnChainLocationBlockNumber            = ffm("OnChainLocation.blockNumber", "For Ethereum blockchain backed registries, this is the block number where the registry entry/property was set")
 	OnChainLocationTransactionIndex       = ffm("OnChainLocation.transactionIndex", "The transaction index within the block")
 	OnChainLocationLogIndex               = ffm("OnChainLocation.logIndex", "The log index within the transaction of the event")
 	ActiveFlagActive                      = ffm("ActiveFlag.active", "When querying with an activeFilter of 'any' or 'inactive', this boolean shows if the entry/property is active or not")
 )
+
+// pldapi/transport.go
+var (
+	TransportDeadLetterID            = ffm("TransportDeadLetter.id", "The ID of the message")
+	TransportDeadLetterCreated       = ffm("TransportDeadLetter.created", "The time the message was moved to the dead letters")
+	TransportDeadLetterTransport     = ffm("TransportDeadLetter.transport", "The name of the transport the message was received on")
+	TransportDeadLetterNode          = ffm("TransportDeadLetter.node", "The node that sent the message")
+	TransportDeadLetterComponent     = ffm("TransportDeadLetter.component", "The component on this node the message was addressed to")
+	TransportDeadLetterMessageType   = ffm("TransportDeadLetter.messageType", "The type of the message")
+	TransportDeadLetterCorrelationID = ffm("TransportDeadLetter.correlationId", "The ID of the message this message is a reply to (optional)")
+	TransportDeadLetterPayload       = ffm("TransportDeadLetter.payload", "The payload of the message")
+	TransportDeadLetterReason        = ffm("TransportDeadLetter.reason", "The reason the message could not be processed")
+)
@@ -106,6 +106,8 @@ var (
 	MsgJSONRPCIncorrectParamCount = ffe("PD020703", "method %s requires %d params (supplied=%d)")
 	MsgJSONRPCInvalidParam        = ffe("PD020704", "method %s parameter %d invalid: %s")
 	MsgJSONRPCResultSerialization = ffe("PD020705", "method %s result serialization failed: %s")
+	MsgJSONRPCWebSocketOnly       = ffe("PD020706", "method %s is only available over a WebSocket connection")
+	MsgJSONRPCSubscriptionClosed  = ffe("PD020707", "subscription %s is closed")
 
 	// Signing module PD0208XX
 	MsgSigningModuleBadPathError                = ffe("PD020800", "Path '%s' does not exist, or it is not a directory")
@@ -136,6 +138,16 @@ var (
 	MsgSigningEmptyPayload                      = ffe("PD020825", "No payload supplied for signing")
 	MsgSigningInvalidDomainAlgorithmNoPrefix    = ffe("PD020826", "Invalid domain algorithm (no 'domain:' prefix): %s")
 	MsgSigningNoDomainRegisteredWithModule      = ffe("PD020827", "Domain '%s' has not been registered in this signing module")
+	MsgSigningKeyListingDisabled                = ffe("PD020828", "Listing keys has been disabled in the configuration of this signing module")
+	MsgSigningMasterKeyInvalid                  = ffe("PD020829", "The master key for password encryption must be a hex encoded 32 byte value")
+	MsgSigningMasterKeyLoadFailed               = ffe("PD020830", "Failed to load the master key for password encryption")
+	MsgSigningPasswordEncryptedNoMasterKey      = ffe("PD020831", "Password file '%s' is encrypted, but no master key is configured")
+	MsgSigningPasswordDecryptFailed             = ffe("PD020832", "Failed to decrypt password file '%s'")
+	MsgSigningRemoteEndpointMissing             = ffe("PD020833", "An endpoint must be configured for the remote key store")
+	MsgSigningRemoteKeyStoreFailed              = ffe("PD020834", "Remote key store request failed")
+	MsgSigningRemoteKeyMaterialMissing          = ffe("PD020835", "Remote key store did not return key material for key '%s'")
+	MsgSigningKeyRotationNotSupported           = ffe("PD020836", "Rotating keys is not supported by this signing module")
+	MsgSigningKeyRotateVersionedHandle          = ffe("PD020837", "Key '%s' is a previous version of a rotated key, and cannot itself be rotated")
 
 	// Reference markdown PD0209XX
 	MsgReferenceMarkdownMissing = ffe("PD020900", "Reference markdown file missing: '%s'")
@@ -0,0 +1,94 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package grpctransport
+
+import (
+	"crypto/tls"
+	"os"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/kaleido-io/paladin/toolkit/pkg/log"
+	"github.com/kaleido-io/paladin/transports/grpc/internal/msgs"
+)
+
+// Polls the configured certificate and key files, and replaces the local certificate of the transport
+// when they change. Operators can then rotate certificates without restarting the transport.
+type certWatcher struct {
+	t        *grpcTransport
+	interval time.Duration
+	certFile string
+	keyFile  string
+	lastMod  [2]time.Time
+}
+
+func newCertWatcher(t *grpcTransport, certFile, keyFile string, interval time.Duration) *certWatcher {
+	cw := &certWatcher{t: t, certFile: certFile, keyFile: keyFile, interval: interval}
+	cw.lastMod, _ = cw.modTimes()
+	return cw
+}
+
+func (cw *certWatcher) modTimes() (modTimes [2]time.Time, err error) {
+	for i, f := range []string{cw.certFile, cw.keyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = fi.ModTime()
+	}
+	return modTimes, nil
+}
+
+// An interval of 0 disables reloading, and is checked here as well as in ConfigureTransport because
+// a ticker panics on an interval that is not positive
+func (cw *certWatcher) run() {
+	if cw.interval <= 0 {
+		log.L(cw.t.bgCtx).Debugf("Certificate watcher for plugin %s disabled", cw.t.name)
+		return
+	}
+	ticker := time.NewTicker(cw.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cw.t.serverDone:
+			log.L(cw.t.bgCtx).Debugf("Certificate watcher for plugin %s stopped", cw.t.name)
+			return
+		case <-ticker.C:
+			_ = cw.checkReload()
+		}
+	}
+}
+
+// The files are only recorded as loaded once a valid key pair is read from them, so if the certificate
+// and key are not updated together we retry on the next interval - keeping the previous certificate
+// in use until then.
+func (cw *certWatcher) checkReload() error {
+	ctx := cw.t.bgCtx
+	modTimes, err := cw.modTimes()
+	if err != nil || modTimes == cw.lastMod {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(cw.certFile, cw.keyFile)
+	if err != nil {
+		err = i18n.WrapError(ctx, err, msgs.MsgCertificateReloadFailed, cw.certFile, cw.keyFile)
+		log.L(ctx).Errorf("%s", err)
+		return err
+	}
+	cw.t.localCertificate.Store(&cert)
+	cw.lastMod = modTimes
+	log.L(ctx).Infof("Reloaded TLS certificate for plugin %s from %s", cw.t.name, cw.certFile)
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright © 2024 Kaleido, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package grpctransport
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
+	"github.com/kaleido-io/paladin/config/pkg/pldconf"
+	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
+	"github.com/kaleido-io/paladin/toolkit/pkg/tktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyPairFiles(t *testing.T, dir, cert, key string) (certFile, keyFile string) {
+	certFile, keyFile = path.Join(dir, "tls.crt"), path.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, []byte(cert), 0600))
+	require.NoError(t, os.WriteFile(keyFile, []byte(key), 0600))
+	// ensure the change is detected, regardless of the timestamp granularity of the filesystem
+	modTime := time.Now().Add(time.Duration(len(cert)+len(key)) * time.Second)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+// A registry that can be updated while connections are being verified against it
+type testRegistry struct {
+	lock sync.Mutex
+	ptds map[string]*PublishedTransportDetails
+}
+
+func (r *testRegistry) set(node string, ptd PublishedTransportDetails) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ptds[node] = &ptd
+}
+
+func (r *testRegistry) getTransportDetails(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ptd := r.ptds[gtdr.Node]
+	if ptd == nil {
+		return nil, fmt.Errorf("not found")
+	}
+	return &prototk.GetTransportDetailsResponse{
+		TransportDetails: tktypes.JSONString(ptd).String(),
+	}, nil
+}
+
+func localIssuers(t *testing.T, transport *grpcTransport) string {
+	details, err := transport.GetLocalDetails(context.Background(), &prototk.GetLocalDetailsRequest{})
+	require.NoError(t, err)
+	var pubDetails PublishedTransportDetails
+	require.NoError(t, json.Unmarshal([]byte(details.TransportDetails), &pubDetails))
+	return pubDetails.Issuers
+}
+
+func TestGRPCTransport_CertReloadKeepsExistingConnections(t *testing.T) {
+	ctx := context.Background()
+	certDir := t.TempDir()
+
+	node1CertA, node1KeyA := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	certFile, keyFile := writeKeyPairFiles(t, certDir, node1CertA, node1KeyA)
+	plugin1, transportDetails1, callbacks1, done1 := newTestGRPCTransport(t, node1CertA, node1KeyA, &Config{
+		TLS:                pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+		CertReloadInterval: confutil.P("10ms"),
+	})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	plugin2, transportDetails2, callbacks2, done2 := newTestGRPCTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+
+	registry := &testRegistry{ptds: map[string]*PublishedTransportDetails{}}
+	registry.set("node1", *transportDetails1)
+	registry.set("node2", *transportDetails2)
+	callbacks1.getTransportDetails = registry.getTransportDetails
+	callbacks2.getTransportDetails = registry.getTransportDetails
+
+	received1 := make(chan *prototk.Message, 1)
+	callbacks1.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+		received1 <- rmr.Message
+		return &prototk.ReceiveMessageResponse{}, nil
+	}
+	received2 := make(chan *prototk.Message, 1)
+	callbacks2.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+		received2 <- rmr.Message
+		return &prototk.ReceiveMessageResponse{}, nil
+	}
+	sendMessage := func(from *grpcTransport, fromNode, toNode string) error {
+		_, err := from.SendMessage(ctx, &prototk.SendMessageRequest{
+			Message: &prototk.Message{ReplyTo: fromNode, Component: "to.you", Node: toNode},
+		})
+		return err
+	}
+
+	// Establish a stream from node1 to node2 with the original certificate
+	require.NoError(t, sendMessage(plugin1, "node1", "node2"))
+	<-received2
+	plugin1.connLock.L.Lock()
+	stream := plugin1.outboundConnections["node2"].stream
+	plugin1.connLock.L.Unlock()
+
+	// Swap the certificate files, and wait for the new certificate to be loaded
+	node1CertB, node1KeyB := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	writeKeyPairFiles(t, certDir, node1CertB, node1KeyB)
+	require.Eventually(t, func() bool { return localIssuers(t, plugin1) == node1CertB }, 5*time.Second, 10*time.Millisecond)
+
+	// The existing stream continues to be used
+	require.NoError(t, sendMessage(plugin1, "node1", "node2"))
+	<-received2
+	plugin1.connLock.L.Lock()
+	assert.Equal(t, stream, plugin1.outboundConnections["node2"].stream)
+	plugin1.connLock.L.Unlock()
+
+	// New connections to node1 are presented the new certificate, which is still checked
+	// against the issuers registered for node1
+	err := sendMessage(plugin2, "node2", "node1")
+	assert.Regexp(t, "PD030007", err)
+
+	transportDetails1.Issuers = node1CertB
+	registry.set("node1", *transportDetails1)
+	require.NoError(t, sendMessage(plugin2, "node2", "node1"))
+	<-received1
+}
+
+func TestGRPCTransport_CertReloadDisabled(t *testing.T) {
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	certFile, keyFile := writeKeyPairFiles(t, t.TempDir(), node1Cert, node1Key)
+	plugin, _, _, done := newTestGRPCTransport(t, node1Cert, node1Key, &Config{
+		TLS:                pldconf.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+		CertReloadInterval: confutil.P("0"),
+	})
+	defer done()
+	assert.Nil(t, plugin.certWatcher)
+
+	// A watcher with no interval returns rather than panicking
+	newCertWatcher(plugin, certFile, keyFile, 0).run()
+}
+
+func TestCertWatcherReloadFail(t *testing.T) {
+	certDir := t.TempDir()
+	transport := NewGRPCTransport(&testCallbacks{}).(*grpcTransport)
+
+	certA, keyA := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	certFile, keyFile := writeKeyPairFiles(t, certDir, certA, keyA)
+	cw := newCertWatcher(transport, certFile, keyFile, time.Second)
+
+	// Nothing changed
+	require.NoError(t, cw.checkReload())
+	assert.Nil(t, transport.localCertificate.Load())
+
+	// Only one half of the key pair updated
+	certB, _ := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	writeKeyPairFiles(t, certDir, certB, keyA)
+	assert.Regexp(t, "PD030014", cw.checkReload())
+	assert.Nil(t, transport.localCertificate.Load())
+
+	// Files removed
+	require.NoError(t, os.Remove(keyFile))
+	assert.Error(t, cw.checkReload())
+
+	// Watcher exits when the server stops
+	transport.serverDone = make(chan struct{})
+	close(transport.serverDone)
+	cw.interval = time.Millisecond
+	cw.run()
+}
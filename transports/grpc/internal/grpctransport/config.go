@@ -23,6 +23,9 @@ import (
 type Config struct {
 	// optional remote hostname to return in local transport details
 	ExternalHostname *string `json:"externalHostname"`
+	// optional additional GRPC target strings to return in local transport details, for a node that can be reached
+	// at more than one address (such as in an HA deployment). Peers try these in order, after the external hostname.
+	ExternalEndpoints []string `json:"externalEndpoints,omitempty"`
 	// TLS configuration details
 	TLS pldconf.TLSConfig `json:"tls"`
 	// address to listen on
@@ -36,11 +39,39 @@ type Config struct {
 	// By default directCertVerification will expect the CN of the subject to be the exact registered node name.
 	// Optionally certSubjectMatcher can supply a regexp containing a SINGLE CAPTURE GROUP that can be used to extract the name from the subject string
 	CertSubjectMatcher *string `json:"certSubjectMatcher,omitempty"`
+	// When the certificate and key are configured as files, they are checked for changes on this interval.
+	// A changed key pair is used for new connections, without dropping existing connections. Set to 0 to disable.
+	CertReloadInterval *string `json:"certReloadInterval,omitempty"`
+	// Established connections to peers are checked on this interval, and any that have been lost (such as when the
+	// peer restarts) are re-established in the background so the next send does not fail. Set to 0 to disable.
+	HealthCheckInterval *string `json:"healthCheckInterval,omitempty"`
+	// How many health checks in a row we try to re-establish a lost connection for, before waiting for the next send
+	MaxReconnectAttempts *int `json:"maxReconnectAttempts,omitempty"`
+	// Connections to peers are pinged after they have been idle for this long, so a peer that has gone away without
+	// closing the connection is detected and the health check can re-establish it. Peers close connections that ping
+	// more often than their keepaliveMinTime allows, so this must not be less than the keepaliveMinTime of any peer.
+	// Set to 0 to disable.
+	KeepaliveTime *string `json:"keepaliveTime,omitempty"`
+	// How long to wait for a peer to acknowledge a ping, before the connection is treated as lost
+	KeepaliveTimeout *string `json:"keepaliveTimeout,omitempty"`
+	// The most often peers are allowed to ping connections to this node. Defaults to the gRPC default, which all
+	// peers allow, so the default keepaliveTime can only be reduced once every peer allows it.
+	KeepaliveMinTime *string `json:"keepaliveMinTime,omitempty"`
+	// The largest message that can be sent to, or received from, a peer. Both sides should be configured with the same limit.
+	// A larger message received from a peer is dropped, unless it is so large that gRPC rejects it and closes the stream.
+	MaxMessageSize *string `json:"maxMessageSize,omitempty"`
 }
 
 var ConfigDefaults = &Config{
 	Address:                confutil.P("0.0.0.0"), // public connectivity
 	DirectCertVerification: confutil.P(true),      // with self-signed certificates
+	CertReloadInterval:     confutil.P("30s"),
+	HealthCheckInterval:    confutil.P("5s"),
+	MaxReconnectAttempts:   confutil.P(12),
+	KeepaliveTime:          confutil.P("5m"),
+	KeepaliveTimeout:       confutil.P("20s"),
+	KeepaliveMinTime:       confutil.P("5m"),
+	MaxMessageSize:         confutil.P("4Mb"), // the gRPC default
 }
 
 // This is the JSON structure that any node in the network must share to be connectable
@@ -48,6 +79,9 @@ var ConfigDefaults = &Config{
 // time otherwise we cannot start up.
 type PublishedTransportDetails struct {
 	Endpoint string `json:"endpoint"` // a GRPC target string that other nodes can use to connect to this node
+	// Additional GRPC target strings for a node that can be reached at more than one address, such as in an HA deployment.
+	// Other nodes try the endpoint first, then each of these in order, until they connect.
+	Endpoints []string `json:"endpoints,omitempty"`
 	// A node specific PEM certificate/certificate-set to use to validate the certificate provided by a node
 	// - used in direct certificate validation mode only
 	// - can be the certificate itself for self-signed
@@ -23,8 +23,11 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/kaleido-io/paladin/config/pkg/confutil"
@@ -36,9 +39,16 @@ import (
 	"github.com/kaleido-io/paladin/transports/grpc/internal/msgs"
 	"github.com/kaleido-io/paladin/transports/grpc/pkg/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
+// Allowance above the maximum message size before gRPC rejects a received message
+const maxMessageSizeHeadroom = 1024
+
 type Server interface {
 	Start() error
 	Stop()
@@ -56,11 +66,15 @@ type grpcTransport struct {
 	serverDone       chan struct{}
 	peerVerifier     *tlsVerifier
 	externalHostname string
-	localCertificate *tls.Certificate
+	localCertificate atomic.Pointer[tls.Certificate]
+	maxMessageSize   int
+	certWatcher      *certWatcher
+	keepalive        *keepalive.ClientParameters // nil when disabled
 
 	conf                Config
 	connLock            sync.Cond
 	outboundConnections map[string]*outboundConn
+	reconnects          map[string]int // lost connections being re-established by the health check, with the attempts so far
 }
 
 type outboundConn struct {
@@ -69,7 +83,9 @@ type outboundConn struct {
 	sendLock   sync.Mutex
 	waiting    int
 	connError  error
+	conn       *grpc.ClientConn
 	stream     grpc.ClientStreamingClient[proto.Message, proto.Empty]
+	streamDone chan struct{}
 }
 
 func NewPlugin(ctx context.Context) plugintk.PluginBase {
@@ -82,6 +98,7 @@ func NewGRPCTransport(callbacks plugintk.TransportCallbacks) plugintk.TransportA
 		callbacks:           callbacks,
 		connLock:            *sync.NewCond(new(sync.Mutex)),
 		outboundConnections: make(map[string]*outboundConn),
+		reconnects:          make(map[string]int),
 	}
 }
 
@@ -104,6 +121,14 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 	listenAddr := fmt.Sprintf("%s:%d", listenAddrNoPort, *t.conf.Port)
 
 	t.externalHostname = confutil.StringNotEmpty(t.conf.ExternalHostname, listenAddrNoPort)
+	t.maxMessageSize = int(confutil.ByteSize(t.conf.MaxMessageSize, 1024, *ConfigDefaults.MaxMessageSize))
+	t.keepalive = nil
+	if keepaliveTime := confutil.DurationMin(t.conf.KeepaliveTime, 0, *ConfigDefaults.KeepaliveTime); keepaliveTime > 0 {
+		t.keepalive = &keepalive.ClientParameters{
+			Time:    keepaliveTime,
+			Timeout: confutil.DurationMin(t.conf.KeepaliveTimeout, 0, *ConfigDefaults.KeepaliveTimeout),
+		}
+	}
 
 	var subjectMatchRegex *regexp.Regexp
 	certSubjectMatcher := confutil.StringOrEmpty(t.conf.CertSubjectMatcher, "")
@@ -121,7 +146,17 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 		return nil, err
 	}
 	baseTLSConfig := tlsDetail.TLSConfig
-	t.localCertificate = tlsDetail.Certificate
+	t.localCertificate.Store(tlsDetail.Certificate)
+	if tlsDetail.Certificate != nil {
+		// The certificate is looked up on each handshake, so that a reloaded certificate is used
+		// for new connections (in both directions) while existing connections are unaffected
+		baseTLSConfig.GetCertificate = func(chi *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return t.localCertificate.Load(), nil
+		}
+		baseTLSConfig.GetClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return t.localCertificate.Load(), nil
+		}
+	}
 
 	directCertVerification := confutil.Bool(t.conf.DirectCertVerification, *ConfigDefaults.DirectCertVerification)
 	baseTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
@@ -149,13 +184,30 @@ func (t *grpcTransport) ConfigureTransport(ctx context.Context, req *prototk.Con
 		},
 		baseTLSConfig: baseTLSConfig,
 	}
-	t.grpcServer = grpc.NewServer(grpc.Creds(t.peerVerifier))
+	t.grpcServer = grpc.NewServer(grpc.Creds(t.peerVerifier),
+		// Senders check the size of each message before sending it, so gRPC only needs to protect us from a peer
+		// that does not. The headroom means a message that is only just over the limit reaches our own check.
+		grpc.MaxRecvMsgSize(t.maxMessageSize+maxMessageSizeHeadroom),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: confutil.DurationMin(t.conf.KeepaliveMinTime, 0, *ConfigDefaults.KeepaliveMinTime),
+		}),
+	)
 	proto.RegisterPaladinGRPCTransportServer(t.grpcServer, t)
 
-	// Kick off the gRPC listener
+	// Kick off the gRPC listener, and the health check of our connections to peers
 	if t.serverDone == nil {
 		t.serverDone = make(chan struct{})
 		go t.serve()
+		if healthCheckInterval := confutil.DurationMin(t.conf.HealthCheckInterval, 0, *ConfigDefaults.HealthCheckInterval); healthCheckInterval > 0 {
+			go t.healthCheckLoop(healthCheckInterval)
+		}
+	}
+
+	// Watch the key pair files for changes, until the server stops
+	certReloadInterval := confutil.DurationMin(t.conf.CertReloadInterval, 0, *ConfigDefaults.CertReloadInterval)
+	if t.conf.TLS.CertFile != "" && t.conf.TLS.KeyFile != "" && certReloadInterval > 0 && t.certWatcher == nil {
+		t.certWatcher = newCertWatcher(t, t.conf.TLS.CertFile, t.conf.TLS.KeyFile, certReloadInterval)
+		go t.certWatcher.run()
 	}
 
 	return &prototk.ConfigureTransportResponse{}, nil
@@ -191,6 +243,10 @@ func (t *grpcTransport) ConnectSendStream(stream grpc.ClientStreamingServer[prot
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
+			if status.Code(err) == codes.ResourceExhausted {
+				// gRPC cannot skip a message beyond its own limit, so the stream cannot continue
+				log.L(ctx).Error(i18n.WrapError(ctx, err, msgs.MsgMessageSizeExceededStream, ai.verifiedNodeName, t.maxMessageSize+maxMessageSizeHeadroom))
+			}
 			log.L(ctx).Infof("GRPC message stream from %s closing (err=%v)", ai.verifiedNodeName, err)
 			return err
 		}
@@ -205,16 +261,23 @@ func (t *grpcTransport) ConnectSendStream(stream grpc.ClientStreamingServer[prot
 			return i18n.NewError(ctx, msgs.MsgInvalidReplyToNode)
 		}
 
+		// An oversized message is dropped on its own, so the other messages on the stream are still delivered
+		if size := protobuf.Size(msg); size > t.maxMessageSize {
+			log.L(ctx).Error(i18n.NewError(ctx, msgs.MsgMessageSizeExceeded, msg.MessageId, ai.verifiedNodeName, size, t.maxMessageSize))
+			continue
+		}
+
 		// Deliver it to Paladin
 		_, err = t.callbacks.ReceiveMessage(ctx, &prototk.ReceiveMessageRequest{
 			Message: &prototk.Message{
-				MessageId:     msg.MessageId,
-				CorrelationId: msg.CorrelationId,
-				Component:     msg.Component,
-				Node:          msg.Node,
-				ReplyTo:       msg.ReplyTo,
-				MessageType:   msg.MessageType,
-				Payload:       msg.Payload,
+				MessageId:       msg.MessageId,
+				CorrelationId:   msg.CorrelationId,
+				Component:       msg.Component,
+				Node:            msg.Node,
+				ReplyTo:         msg.ReplyTo,
+				MessageType:     msg.MessageType,
+				Payload:         msg.Payload,
+				ProtocolVersion: msg.ProtocolVersion,
 			},
 		})
 		if err != nil {
@@ -242,10 +305,57 @@ func (t *grpcTransport) getTransportDetails(ctx context.Context, node string) (t
 	return transportDetails, nil
 }
 
+// The server never replies on the stream, so a receive only returns once the stream has been terminated.
+// That happens when the connection to the peer is lost - for example because the peer has restarted, or
+// has stopped acknowledging keepalive pings.
+func (oc *outboundConn) watchStream() {
+	defer close(oc.streamDone)
+	_ = oc.stream.RecvMsg(&proto.Empty{})
+}
+
+func (oc *outboundConn) isLost() bool {
+	if oc.streamDone == nil {
+		return false
+	}
+	select {
+	case <-oc.streamDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// Must be called holding the connLock
+func (t *grpcTransport) removeConnLocked(oc *outboundConn) {
+	if t.outboundConnections[oc.nodeName] == oc {
+		delete(t.outboundConnections, oc.nodeName)
+	}
+	if oc.conn != nil {
+		_ = oc.conn.Close()
+	}
+}
+
+// The addresses to try for a peer in order, starting with the primary endpoint
+func (ptd *PublishedTransportDetails) endpoints() []string {
+	endpoints := make([]string, 0, 1+len(ptd.Endpoints))
+	for _, endpoint := range append([]string{ptd.Endpoint}, ptd.Endpoints...) {
+		if endpoint != "" && !slices.Contains(endpoints, endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
 func (t *grpcTransport) waitExistingOrNewConn(nodeName string) (bool, *outboundConn, error) {
 	t.connLock.L.Lock()
 	defer t.connLock.L.Unlock()
 	existing := t.outboundConnections[nodeName]
+	if existing != nil && !existing.connecting && existing.isLost() {
+		// Replace a lost connection now, rather than failing the send and waiting for the health check
+		log.L(t.bgCtx).Infof("GRPC connection to %s lost - reconnecting", nodeName)
+		t.removeConnLocked(existing)
+		existing = nil
+	}
 	if existing != nil {
 		// Multiple routines might try to connect concurrently, so we have a condition
 		existing.waiting++
@@ -271,7 +381,7 @@ func (t *grpcTransport) send(ctx context.Context, oc *outboundConn, message *pro
 			oc.sendLock.Unlock()
 			t.connLock.L.Lock()
 			defer t.connLock.L.Unlock()
-			delete(t.outboundConnections, oc.nodeName)
+			t.removeConnLocked(oc)
 		} else {
 			// Just drop the lock and return
 			oc.sendLock.Unlock()
@@ -297,7 +407,7 @@ func (t *grpcTransport) getConnection(ctx context.Context, nodeName string) (*ou
 			// copy our error to anyone queuing - everybody fails
 			oc.connError = err
 			// remove this entry, so the next one will try again
-			delete(t.outboundConnections, nodeName)
+			t.removeConnLocked(oc)
 		}
 		t.connLock.Broadcast()
 		t.connLock.L.Unlock()
@@ -309,38 +419,123 @@ func (t *grpcTransport) getConnection(ctx context.Context, nodeName string) (*ou
 		return nil, err
 	}
 
-	// Ok - try connecting
-	log.L(ctx).Infof("GRPC connecting to new peer %s (endpoint=%s)", nodeName, transportDetails.Endpoint)
+	// Ok - try connecting to each endpoint in turn, until we find one that is available and presents
+	// the TLS identity of the node
 	individualNodeVerifier := t.peerVerifier.Clone().(*tlsVerifier)
 	individualNodeVerifier.expectedNode = nodeName
-	conn, err := grpc.NewClient(transportDetails.Endpoint,
-		grpc.WithTransportCredentials(individualNodeVerifier),
-	)
-	if err == nil {
-		client := proto.NewPaladinGRPCTransportClient(conn)
-		oc.stream, err = client.ConnectSendStream(ctx)
+	endpoints := transportDetails.endpoints()
+	validEndpoints := 0
+	for i, endpoint := range endpoints {
+		log.L(ctx).Infof("GRPC connecting to new peer %s (endpoint=%s %d/%d)", nodeName, endpoint, i+1, len(endpoints))
+		dialOptions := []grpc.DialOption{
+			grpc.WithTransportCredentials(individualNodeVerifier),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(t.maxMessageSize)),
+		}
+		if t.keepalive != nil {
+			// A ping that is not acknowledged terminates the stream, so the connection is seen as lost
+			dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*t.keepalive))
+		}
+		oc.conn, err = grpc.NewClient(endpoint, dialOptions...)
+		if err != nil {
+			log.L(ctx).Warnf("GRPC invalid endpoint for peer %s (endpoint=%s): %s", nodeName, endpoint, err)
+			continue
+		}
+		validEndpoints++
+		client := proto.NewPaladinGRPCTransportClient(oc.conn)
+		if oc.stream, err = client.ConnectSendStream(ctx); err == nil {
+			oc.streamDone = make(chan struct{})
+			go oc.watchStream()
+			return oc, nil
+		}
+		log.L(ctx).Warnf("GRPC failed to connect to peer %s (endpoint=%s): %s", nodeName, endpoint, err)
+		_ = oc.conn.Close()
+		oc.conn = nil
+	}
+	if validEndpoints == 0 {
+		if err != nil {
+			err = i18n.WrapError(ctx, err, msgs.MsgPeerTransportDetailsInvalid, nodeName)
+		} else {
+			err = i18n.NewError(ctx, msgs.MsgPeerTransportDetailsInvalid, nodeName)
+		}
 	}
 	return oc, err
 }
 
+func (t *grpcTransport) healthCheckLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.serverDone:
+			log.L(t.bgCtx).Debugf("Connection health check for plugin %s stopped", t.name)
+			return
+		case <-ticker.C:
+			t.checkConnections()
+		}
+	}
+}
+
+// Removes any connections that have been lost, and attempts to re-establish them. This goes through
+// the same verification of the peer's TLS identity as a connection established for a send.
+func (t *grpcTransport) checkConnections() {
+	ctx := t.bgCtx
+
+	t.connLock.L.Lock()
+	maxAttempts := confutil.IntMin(t.conf.MaxReconnectAttempts, 0, *ConfigDefaults.MaxReconnectAttempts)
+	for nodeName, oc := range t.outboundConnections {
+		if !oc.connecting && oc.isLost() {
+			log.L(ctx).Infof("GRPC connection to %s lost", nodeName)
+			t.removeConnLocked(oc)
+			t.reconnects[nodeName] = 0
+		}
+	}
+	reconnects := make([]string, 0, len(t.reconnects))
+	for nodeName := range t.reconnects {
+		reconnects = append(reconnects, nodeName)
+	}
+	t.connLock.L.Unlock()
+
+	for _, nodeName := range reconnects {
+		_, err := t.getConnection(ctx, nodeName)
+		t.connLock.L.Lock()
+		if err == nil {
+			log.L(ctx).Infof("GRPC connection to %s re-established", nodeName)
+			delete(t.reconnects, nodeName)
+		} else {
+			t.reconnects[nodeName]++
+			log.L(ctx).Warnf("GRPC reconnect to %s failed (attempt=%d/%d): %s", nodeName, t.reconnects[nodeName], maxAttempts, err)
+			if t.reconnects[nodeName] >= maxAttempts {
+				delete(t.reconnects, nodeName)
+			}
+		}
+		t.connLock.L.Unlock()
+	}
+}
+
 func (t *grpcTransport) SendMessage(ctx context.Context, req *prototk.SendMessageRequest) (*prototk.SendMessageResponse, error) {
 	msg := req.Message
 	if req.Message.Node == "" {
 		return nil, i18n.NewError(ctx, msgs.MsgErrorNoTargetNode)
 	}
+	pMsg := &proto.Message{
+		MessageId:       msg.MessageId,
+		CorrelationId:   msg.CorrelationId,
+		Component:       msg.Component,
+		Node:            msg.Node,
+		ReplyTo:         msg.ReplyTo,
+		MessageType:     msg.MessageType,
+		Payload:         msg.Payload,
+		ProtocolVersion: msg.ProtocolVersion,
+	}
+	// Check the size before sending, as gRPC would fail the stream
+	if size := protobuf.Size(pMsg); size > t.maxMessageSize {
+		return nil, i18n.NewError(ctx, msgs.MsgMessageSizeExceeded, msg.MessageId, msg.Node, size, t.maxMessageSize)
+	}
 	oc, err := t.getConnection(ctx, msg.Node)
 	if err == nil {
 		log.L(ctx).Infof("GRPC sending message id=%s cid=%v component=%s messageType=%s replyTo=%s to peer %s",
 			msg.MessageId, msg.CorrelationId, msg.Component, msg.MessageType, msg.ReplyTo, msg.Node)
-		err = t.send(ctx, oc, &proto.Message{
-			MessageId:     msg.MessageId,
-			CorrelationId: msg.CorrelationId,
-			Component:     msg.Component,
-			Node:          msg.Node,
-			ReplyTo:       msg.ReplyTo,
-			MessageType:   msg.MessageType,
-			Payload:       msg.Payload,
-		})
+		err = t.send(ctx, oc, pMsg)
 	}
 	if err != nil {
 		return nil, err
@@ -350,7 +545,7 @@ func (t *grpcTransport) SendMessage(ctx context.Context, req *prototk.SendMessag
 
 func (t *grpcTransport) GetLocalDetails(ctx context.Context, req *prototk.GetLocalDetailsRequest) (*prototk.GetLocalDetailsResponse, error) {
 
-	certList := t.localCertificate.Certificate
+	certList := t.localCertificate.Load().Certificate
 	issuersText := new(strings.Builder)
 	for _, cert := range certList {
 		_ = pem.Encode(issuersText, &pem.Block{
@@ -360,8 +555,9 @@ func (t *grpcTransport) GetLocalDetails(ctx context.Context, req *prototk.GetLoc
 	}
 
 	localDetails := &PublishedTransportDetails{
-		Endpoint: fmt.Sprintf("dns:///%s:%d", t.externalHostname, *t.conf.Port),
-		Issuers:  issuersText.String(),
+		Endpoint:  fmt.Sprintf("dns:///%s:%d", t.externalHostname, *t.conf.Port),
+		Endpoints: t.conf.ExternalEndpoints,
+		Issuers:   issuersText.String(),
 	}
 	jsonDetails, _ := json.Marshal(&localDetails)
 
@@ -17,16 +17,20 @@ package grpctransport
 
 import (
 	"context"
+	"crypto/x509/pkix"
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
 	"time"
 
+	"github.com/kaleido-io/paladin/config/pkg/confutil"
 	"github.com/kaleido-io/paladin/toolkit/pkg/prototk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/kaleido-io/paladin/transports/grpc/pkg/proto"
 )
@@ -299,3 +303,309 @@ func TestWaitNewConn(t *testing.T) {
 	assert.Regexp(t, "pop", <-bgError)
 
 }
+
+func TestHealthCheckReconnectsToRestartedPeer(t *testing.T) {
+	ctx := context.Background()
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, callbacks1, done1 := newTestGRPCTransport(t, node1Cert, node1Key, &Config{
+		HealthCheckInterval:  confutil.P("10ms"),
+		MaxReconnectAttempts: confutil.P(1000),
+	})
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	plugin2, transportDetails2, callbacks2, done2 := newTestGRPCTransport(t, node2Cert, node2Key, &Config{})
+	defer done2()
+
+	registry := &testRegistry{ptds: map[string]*PublishedTransportDetails{}}
+	registry.set("node1", *transportDetails1)
+	registry.set("node2", *transportDetails2)
+	callbacks1.getTransportDetails = registry.getTransportDetails
+	received := make(chan *prototk.Message, 1)
+	setupNode2 := func(callbacks *testCallbacks) {
+		callbacks.getTransportDetails = registry.getTransportDetails
+		callbacks.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	}
+	setupNode2(callbacks2)
+	sendMessage := func() error {
+		_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+			Message: &prototk.Message{ReplyTo: "node1", Component: "to.you", Node: "node2", ProtocolVersion: 1},
+		})
+		return err
+	}
+	connected := func() *outboundConn {
+		plugin1.connLock.L.Lock()
+		defer plugin1.connLock.L.Unlock()
+		oc := plugin1.outboundConnections["node2"]
+		if oc == nil || oc.connecting || oc.isLost() {
+			return nil
+		}
+		return oc
+	}
+
+	require.NoError(t, sendMessage())
+	// The protocol version is carried across for Paladin to check
+	assert.Equal(t, int32(1), (<-received).ProtocolVersion)
+	firstConn := connected()
+	require.NotNil(t, firstConn)
+
+	// Stop node2, and wait for the health check to notice the connection is lost
+	plugin2.grpcServer.Stop()
+	<-plugin2.serverDone
+	require.Eventually(t, func() bool { return connected() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	// Restart node2 on the same port - the health check reconnects before we send again
+	plugin2b, _, _, done2b := newTestGRPCTransport(t, node2Cert, node2Key, &Config{Port: plugin2.conf.Port}, setupNode2)
+	defer done2b()
+	require.Eventually(t, func() bool { return connected() != nil }, 5*time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, firstConn, connected())
+
+	require.NoError(t, sendMessage())
+	<-received
+
+	// Replace node2 with an impostor on the same port, which the reconnect must not accept
+	plugin2b.grpcServer.Stop()
+	<-plugin2b.serverDone
+	impostorCert, impostorKey := buildTestCertificate(t, pkix.Name{CommonName: "node3"}, nil, nil)
+	_, _, _, done3 := newTestGRPCTransport(t, impostorCert, impostorKey, &Config{Port: plugin2.conf.Port}, setupNode2)
+	defer done3()
+	require.Eventually(t, func() bool {
+		plugin1.connLock.L.Lock()
+		defer plugin1.connLock.L.Unlock()
+		return plugin1.reconnects["node2"] > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, connected())
+
+	err := sendMessage()
+	assert.Regexp(t, "PD030011", err)
+}
+
+func TestHealthCheckGivesUpReconnecting(t *testing.T) {
+
+	plugin, _, callbacks, done := newTestGRPCTransport(t, "", "", &Config{
+		HealthCheckInterval:  confutil.P("0"),
+		MaxReconnectAttempts: confutil.P(2),
+	})
+	defer done()
+	callbacks.getTransportDetails = func(ctx context.Context, gtdr *prototk.GetTransportDetailsRequest) (*prototk.GetTransportDetailsResponse, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	plugin.reconnects["node2"] = 0
+	plugin.checkConnections()
+	assert.Equal(t, 1, plugin.reconnects["node2"])
+	plugin.checkConnections()
+	assert.NotContains(t, plugin.reconnects, "node2")
+}
+
+func TestConnectFailoverAcrossEndpoints(t *testing.T) {
+	ctx := context.Background()
+
+	registry := &testRegistry{ptds: map[string]*PublishedTransportDetails{}}
+	received := make(chan *prototk.Message, 1)
+	setup := func(callbacks *testCallbacks) {
+		callbacks.getTransportDetails = registry.getTransportDetails
+		callbacks.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	}
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, _, done1 := newTestGRPCTransport(t, node1Cert, node1Key, &Config{}, setup)
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, _, done2 := newTestGRPCTransport(t, node2Cert, node2Key, &Config{}, setup)
+	defer done2()
+
+	// An impostor that presents a valid certificate for a different node
+	impostorCert, impostorKey := buildTestCertificate(t, pkix.Name{CommonName: "node3"}, nil, nil)
+	_, transportDetails3, _, done3 := newTestGRPCTransport(t, impostorCert, impostorKey, &Config{}, setup)
+	defer done3()
+
+	// An address nothing is listening on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unavailableEndpoint := "dns:///" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	sendMessage := func() error {
+		_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+			Message: &prototk.Message{ReplyTo: "node1", Component: "to.you", Node: "node2"},
+		})
+		return err
+	}
+
+	registry.set("node1", *transportDetails1)
+	registry.set("node3", *transportDetails3)
+
+	// Invalid, unavailable and impostor endpoints are skipped, to reach node2
+	registry.set("node2", PublishedTransportDetails{
+		Endpoint:  "%%%",
+		Endpoints: []string{unavailableEndpoint, transportDetails3.Endpoint, "%%%", transportDetails2.Endpoint},
+		Issuers:   transportDetails2.Issuers,
+	})
+	require.NoError(t, sendMessage())
+	<-received
+
+	// No endpoint that accepts a connection as node2
+	plugin1.connLock.L.Lock()
+	plugin1.removeConnLocked(plugin1.outboundConnections["node2"])
+	plugin1.connLock.L.Unlock()
+	registry.set("node2", PublishedTransportDetails{
+		Endpoint:  unavailableEndpoint,
+		Endpoints: []string{transportDetails3.Endpoint},
+		Issuers:   transportDetails2.Issuers,
+	})
+	assert.Regexp(t, "PD030011", sendMessage())
+
+	// No valid endpoints at all
+	registry.set("node2", PublishedTransportDetails{
+		Endpoint:  "%%%",
+		Endpoints: []string{"", "%%%"},
+		Issuers:   transportDetails2.Issuers,
+	})
+	assert.Regexp(t, "PD030006", sendMessage())
+	registry.set("node2", PublishedTransportDetails{Issuers: transportDetails2.Issuers})
+	assert.Regexp(t, "PD030006", sendMessage())
+}
+
+func TestKeepalive(t *testing.T) {
+	ctx := context.Background()
+
+	registry := &testRegistry{ptds: map[string]*PublishedTransportDetails{}}
+	received := make(chan *prototk.Message, 1)
+	setup := func(callbacks *testCallbacks) {
+		callbacks.getTransportDetails = registry.getTransportDetails
+		callbacks.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	}
+
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, _, done1 := newTestGRPCTransport(t, node1Cert, node1Key, &Config{
+		KeepaliveTime:    confutil.P("10s"),
+		KeepaliveTimeout: confutil.P("1s"),
+	}, setup)
+	defer done1()
+	assert.Equal(t, &keepalive.ClientParameters{Time: 10 * time.Second, Timeout: 1 * time.Second}, plugin1.keepalive)
+
+	// The default is no more often than the gRPC default minimum, which every peer allows
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	plugin2, transportDetails2, _, done2 := newTestGRPCTransport(t, node2Cert, node2Key, &Config{
+		KeepaliveMinTime: confutil.P("10s"),
+	}, setup)
+	defer done2()
+	assert.Equal(t, 5*time.Minute, plugin2.keepalive.Time)
+
+	node3Cert, node3Key := buildTestCertificate(t, pkix.Name{CommonName: "node3"}, nil, nil)
+	plugin3, _, _, done3 := newTestGRPCTransport(t, node3Cert, node3Key, &Config{
+		KeepaliveTime: confutil.P("0"),
+	}, setup)
+	defer done3()
+	assert.Nil(t, plugin3.keepalive)
+
+	registry.set("node1", *transportDetails1)
+	registry.set("node2", *transportDetails2)
+
+	_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+		Message: &prototk.Message{MessageId: "msg1", ReplyTo: "node1", Component: "to.you", Node: "node2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "msg1", (<-received).MessageId)
+}
+
+func TestLocalDetailsExternalEndpoints(t *testing.T) {
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin, _, _, done := newTestGRPCTransport(t, node1Cert, node1Key, &Config{
+		ExternalHostname:  confutil.P("node1a.example.com"),
+		ExternalEndpoints: []string{"dns:///node1b.example.com:8080", "dns:///node1c.example.com:8080"},
+	})
+	defer done()
+
+	details, err := plugin.GetLocalDetails(context.Background(), &prototk.GetLocalDetailsRequest{})
+	require.NoError(t, err)
+	var pubDetails PublishedTransportDetails
+	err = json.Unmarshal([]byte(details.TransportDetails), &pubDetails)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		fmt.Sprintf("dns:///node1a.example.com:%d", *plugin.conf.Port),
+		"dns:///node1b.example.com:8080",
+		"dns:///node1c.example.com:8080",
+	}, pubDetails.endpoints())
+}
+
+func TestPublishedTransportDetailsEndpoints(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, (&PublishedTransportDetails{
+		Endpoint:  "a",
+		Endpoints: []string{"b", "a", "", "c", "b"},
+	}).endpoints())
+	assert.Empty(t, (&PublishedTransportDetails{}).endpoints())
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	ctx := context.Background()
+
+	registry := &testRegistry{ptds: map[string]*PublishedTransportDetails{}}
+	received := make(chan *prototk.Message, 1)
+	setup := func(callbacks *testCallbacks) {
+		callbacks.getTransportDetails = registry.getTransportDetails
+		callbacks.receiveMessage = func(ctx context.Context, rmr *prototk.ReceiveMessageRequest) (*prototk.ReceiveMessageResponse, error) {
+			received <- rmr.Message
+			return &prototk.ReceiveMessageResponse{}, nil
+		}
+	}
+
+	// node1 sends with a larger limit than node2 receives
+	node1Cert, node1Key := buildTestCertificate(t, pkix.Name{CommonName: "node1"}, nil, nil)
+	plugin1, transportDetails1, _, done1 := newTestGRPCTransport(t, node1Cert, node1Key, &Config{
+		MaxMessageSize: confutil.P("4Kb"),
+	}, setup)
+	defer done1()
+
+	node2Cert, node2Key := buildTestCertificate(t, pkix.Name{CommonName: "node2"}, nil, nil)
+	_, transportDetails2, _, done2 := newTestGRPCTransport(t, node2Cert, node2Key, &Config{
+		MaxMessageSize: confutil.P("1Kb"),
+	}, setup)
+	defer done2()
+
+	registry.set("node1", *transportDetails1)
+	registry.set("node2", *transportDetails2)
+
+	sendMessage := func(id string, payloadSize int) error {
+		_, err := plugin1.SendMessage(ctx, &prototk.SendMessageRequest{
+			Message: &prototk.Message{MessageId: id, ReplyTo: "node1", Component: "to.you", Node: "node2", Payload: make([]byte, payloadSize)},
+		})
+		return err
+	}
+
+	// Rejected before sending, without connecting
+	err := sendMessage("msg1", 8192)
+	assert.Regexp(t, "PD030015.*msg1.*node2.*4,096", err)
+	assert.Empty(t, plugin1.outboundConnections)
+
+	require.NoError(t, sendMessage("msg2", 10))
+	assert.Equal(t, "msg2", (<-received).MessageId)
+
+	oc := plugin1.outboundConnections["node2"]
+
+	// Dropped by node2 on receipt, without closing the stream
+	require.NoError(t, sendMessage("msg3", 1100))
+	require.NoError(t, sendMessage("msg4", 10))
+	assert.Equal(t, "msg4", (<-received).MessageId)
+	assert.Same(t, oc, plugin1.outboundConnections["node2"])
+	assert.False(t, oc.isLost())
+
+	// Beyond the headroom gRPC allows, the stream is closed - and the next send reconnects
+	require.NoError(t, sendMessage("msg5", 3072))
+	<-oc.streamDone
+	require.NoError(t, sendMessage("msg6", 10))
+	assert.Equal(t, "msg6", (<-received).MessageId)
+	assert.NotSame(t, oc, plugin1.outboundConnections["node2"])
+}
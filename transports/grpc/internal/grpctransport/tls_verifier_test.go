@@ -84,14 +84,16 @@ func buildTestCertificate(t *testing.T, subject pkix.Name, ca *x509.Certificate,
 	return publicKeyPEM.String(), privateKeyPEM.String()
 }
 
-func newTestGRPCTransport(t *testing.T, nodeCert, nodeKey string, conf *Config) (*grpcTransport, *PublishedTransportDetails, *testCallbacks, func()) {
-	// Grab a localhost port to use and put that in config
-	portGrabber, err := net.Listen("tcp", "127.0.0.1:0")
-	assert.NoError(t, err)
-	port := portGrabber.Addr().(*net.TCPAddr).Port
-	err = portGrabber.Close()
-	assert.NoError(t, err)
-	conf.Port = &port
+func newTestGRPCTransport(t *testing.T, nodeCert, nodeKey string, conf *Config, setup ...func(callbacks *testCallbacks)) (*grpcTransport, *PublishedTransportDetails, *testCallbacks, func()) {
+	// Grab a localhost port to use and put that in config, unless we are restarting on a known port
+	if conf.Port == nil {
+		portGrabber, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		port := portGrabber.Addr().(*net.TCPAddr).Port
+		err = portGrabber.Close()
+		assert.NoError(t, err)
+		conf.Port = &port
+	}
 	conf.Address = confutil.P("127.0.0.1")
 
 	// Put the certs in the config
@@ -104,6 +106,9 @@ func newTestGRPCTransport(t *testing.T, nodeCert, nodeKey string, conf *Config)
 
 	//  construct the plugin
 	callbacks := &testCallbacks{}
+	for _, fn := range setup {
+		fn(callbacks)
+	}
 	transport := NewGRPCTransport(callbacks).(*grpcTransport)
 	res, err := transport.ConfigureTransport(transport.bgCtx, &prototk.ConfigureTransportRequest{
 		Name:       "grpc",
@@ -47,4 +47,7 @@ var (
 	MsgConnectionToWrongNode                = ffe("PD030011", "the TLS identity of the node '%s' does not match the expected node '%s'")
 	MsgPEMCertificateInvalid                = ffe("PD030012", "invalid PEM encoded x509 certificate")
 	MsgErrorNoTargetNode                    = ffe("PD030013", "request to send message but no target node specified")
+	MsgCertificateReloadFailed              = ffe("PD030014", "failed to reload TLS key pair from certFile='%s' keyFile='%s'")
+	MsgMessageSizeExceeded                  = ffe("PD030015", "message id=%s for node '%s' is %d bytes which exceeds the maximum message size of %d bytes", 413)
+	MsgMessageSizeExceededStream            = ffe("PD030016", "message stream from node '%s' closed on a message larger than the receive limit of %d bytes", 413)
 )